@@ -76,6 +76,7 @@ type Client interface {
 	PostLifecycle(ctx context.Context, state agentsdk.PostLifecycleRequest) error
 	PostAppHealth(ctx context.Context, req agentsdk.PostAppHealthsRequest) error
 	PostStartup(ctx context.Context, req agentsdk.PostStartupRequest) error
+	PostConnection(ctx context.Context, req agentsdk.PostConnectionRequest) error
 }
 
 func New(options Options) io.Closer {
@@ -113,6 +114,7 @@ func New(options Options) io.Closer {
 		tempDir:                options.TempDir,
 		lifecycleUpdate:        make(chan struct{}, 1),
 		connStatsChan:          make(chan *agentsdk.Stats, 1),
+		connectionReports:      make(chan agentsdk.PostConnectionRequest, 256),
 	}
 	a.init(ctx)
 	return a
@@ -146,6 +148,10 @@ type agent struct {
 
 	network       *tailnet.Conn
 	connStatsChan chan *agentsdk.Stats
+
+	// connectionReports are the SSH sessions and port forwards waiting to be
+	// reported to coderd for auditing.
+	connectionReports chan agentsdk.PostConnectionRequest
 }
 
 // runLoop attempts to start the agent in a retry loop.
@@ -154,6 +160,7 @@ type agent struct {
 // failure, you'll want the agent to reconnect.
 func (a *agent) runLoop(ctx context.Context) {
 	go a.reportLifecycleLoop(ctx)
+	go a.reportConnectionsLoop(ctx)
 
 	for retrier := retry.New(100*time.Millisecond, 10*time.Second); retrier.Wait(ctx); {
 		a.logger.Info(ctx, "connecting to coderd")
@@ -215,6 +222,67 @@ func (a *agent) reportLifecycleLoop(ctx context.Context) {
 	}
 }
 
+// reportConnection queues a report of a connection to the agent, and returns
+// a function that queues the report of its disconnect.
+func (a *agent) reportConnection(connectionType codersdk.ConnectionType, remoteAddr net.Addr, detail string) func() {
+	req := agentsdk.PostConnectionRequest{
+		ID:     uuid.New(),
+		Action: codersdk.AuditActionConnect,
+		Type:   connectionType,
+		Detail: detail,
+	}
+	if remoteAddr != nil {
+		req.RemoteAddr = remoteAddr.String()
+	}
+	a.queueConnectionReport(req)
+
+	start := time.Now()
+	return func() {
+		req.Action = codersdk.AuditActionDisconnect
+		req.DurationMS = time.Since(start).Milliseconds()
+		a.queueConnectionReport(req)
+	}
+}
+
+func (a *agent) queueConnectionReport(req agentsdk.PostConnectionRequest) {
+	select {
+	case a.connectionReports <- req:
+	default:
+		a.logger.Warn(context.Background(), "too many connections waiting to be reported, dropping report", slog.F("connection", req))
+	}
+}
+
+// reportConnectionsLoop reports queued connections to coderd in order. Reports
+// are retried while coderd is unavailable.
+func (a *agent) reportConnectionsLoop(ctx context.Context) {
+	for {
+		var req agentsdk.PostConnectionRequest
+		select {
+		case req = <-a.connectionReports:
+		case <-ctx.Done():
+			return
+		}
+
+		for r := retry.New(time.Second, 15*time.Second); r.Wait(ctx); {
+			err := a.client.PostConnection(ctx, req)
+			if err == nil {
+				break
+			}
+			if xerrors.Is(err, context.Canceled) || xerrors.Is(err, context.DeadlineExceeded) {
+				return
+			}
+			var sdkErr *codersdk.Error
+			if xerrors.As(err, &sdkErr) && sdkErr.StatusCode() < http.StatusInternalServerError {
+				// Retrying won't help, e.g. if coderd is too old to audit
+				// connections.
+				a.logger.Warn(ctx, "connection report rejected", slog.F("connection", req), slog.Error(err))
+				break
+			}
+			a.logger.Error(ctx, "post connection", slog.Error(err))
+		}
+	}
+}
+
 func (a *agent) setLifecycle(ctx context.Context, state codersdk.WorkspaceAgentLifecycle) {
 	a.lifecycleMu.Lock()
 	defer a.lifecycleMu.Unlock()
@@ -664,9 +732,9 @@ func (a *agent) init(ctx context.Context) {
 
 	a.sshServer = &ssh.Server{
 		ChannelHandlers: map[string]ssh.ChannelHandler{
-			"direct-tcpip":                   ssh.DirectTCPIPHandler,
-			"direct-streamlocal@openssh.com": directStreamLocalHandler,
-			"session":                        ssh.DefaultSessionHandler,
+			"direct-tcpip":                   a.directTCPIPHandler,
+			"direct-streamlocal@openssh.com": a.directStreamLocalHandler,
+			"session":                        a.sessionHandler,
 		},
 		ConnectionFailedCallback: func(conn net.Conn, err error) {
 			sshLogger.Info(ctx, "ssh connection ended", slog.Error(err))
@@ -768,11 +836,15 @@ func (a *agent) init(ctx context.Context) {
 func convertAgentStats(counts map[netlogtype.Connection]netlogtype.Counts) *agentsdk.Stats {
 	stats := &agentsdk.Stats{
 		ConnectionsByProto: map[string]int64{},
+		ConnectionsByPort:  map[string]int64{},
 		ConnectionCount:    int64(len(counts)),
 	}
 
 	for conn, count := range counts {
 		stats.ConnectionsByProto[conn.Proto.String()]++
+		// Connection stats are recorded from the agent's side, so the source
+		// is the agent port the connection was made to.
+		stats.ConnectionsByPort[strconv.Itoa(int(conn.Src.Port()))]++
 		stats.RxPackets += int64(count.RxPackets)
 		stats.RxBytes += int64(count.RxBytes)
 		stats.TxPackets += int64(count.TxPackets)
@@ -920,6 +992,10 @@ func (a *agent) handleSSHSession(session ssh.Session) (retErr error) {
 				if err != nil {
 					a.logger.Error(ctx, "show MOTD", slog.Error(err))
 				}
+				err = showTemplateDeprecation(session, metadata.TemplateDeprecationMessage)
+				if err != nil {
+					a.logger.Error(ctx, "show template deprecation", slog.Error(err))
+				}
 			} else {
 				a.logger.Warn(ctx, "metadata lookup failed, unable to show MOTD")
 			}
@@ -1326,6 +1402,19 @@ func showMOTD(dest io.Writer, filename string) error {
 	return nil
 }
 
+// showTemplateDeprecation tells the user that the template of the workspace
+// is deprecated, if the message is not empty.
+func showTemplateDeprecation(dest io.Writer, message string) error {
+	if message == "" {
+		return nil
+	}
+	_, err := fmt.Fprint(dest, "This workspace uses a deprecated template: "+strings.ReplaceAll(message, "\n", "\r\n")+"\r\n")
+	if err != nil {
+		return xerrors.Errorf("write template deprecation: %w", err)
+	}
+	return nil
+}
+
 // userHomeDir returns the home directory of the current user, giving
 // priority to the $HOME environment variable.
 func userHomeDir() (string, error) {
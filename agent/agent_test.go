@@ -226,6 +226,39 @@ func TestAgent_Session_TTY_MOTD(t *testing.T) {
 	require.Contains(t, stdout.String(), wantMOTD, "should show motd")
 }
 
+//nolint:paralleltest // This test sets an environment variable.
+func TestAgent_Session_TTY_TemplateDeprecated(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		// This might be our implementation, or ConPTY itself.
+		// It's difficult to find extensive tests for it, so
+		// it seems like it could be either.
+		t.Skip("ConPTY appears to be inconsistent on Windows.")
+	}
+
+	// Set HOME so we can ensure no ~/.hushlogin is present.
+	t.Setenv("HOME", t.TempDir())
+
+	session := setupSSHSession(t, agentsdk.Metadata{
+		TemplateDeprecationMessage: "Use the new template.",
+	})
+	err := session.RequestPty("xterm", 128, 128, ssh.TerminalModes{})
+	require.NoError(t, err)
+
+	ptty := ptytest.New(t)
+	var stdout bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = ptty.Output()
+	session.Stdin = ptty.Input()
+	err = session.Shell()
+	require.NoError(t, err)
+
+	ptty.WriteLine("exit 0")
+	err = session.Wait()
+	require.NoError(t, err)
+
+	require.Contains(t, stdout.String(), "This workspace uses a deprecated template: Use the new template.")
+}
+
 //nolint:paralleltest // This test sets an environment variable.
 func TestAgent_Session_TTY_Hushlogin(t *testing.T) {
 	if runtime.GOOS == "windows" {
@@ -697,6 +730,61 @@ func TestAgent_StartupScript(t *testing.T) {
 	require.Equal(t, content, strings.TrimSpace(gotContent))
 }
 
+func TestAgent_Connections(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	//nolint:dogsled
+	conn, client, _, _ := setupAgent(t, agentsdk.Metadata{}, 0)
+	sshClient, err := conn.SSHClient(ctx)
+	require.NoError(t, err)
+	defer sshClient.Close()
+
+	session, err := sshClient.NewSession()
+	require.NoError(t, err)
+	command := "true"
+	if runtime.GOOS == "windows" {
+		command = "cmd.exe /c exit 0"
+	}
+	require.NoError(t, session.Run(command))
+	_ = session.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			_ = conn.Close()
+		}
+	}()
+	forwarded, err := sshClient.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, forwarded)
+	_ = forwarded.Close()
+
+	var connections []agentsdk.PostConnectionRequest
+	require.Eventually(t, func() bool {
+		connections = client.getConnections()
+		return len(connections) == 4
+	}, testutil.WaitLong, testutil.IntervalFast)
+
+	byType := map[codersdk.ConnectionType][]agentsdk.PostConnectionRequest{}
+	for _, connection := range connections {
+		byType[connection.Type] = append(byType[connection.Type], connection)
+	}
+	for _, connectionType := range []codersdk.ConnectionType{codersdk.ConnectionTypeSSH, codersdk.ConnectionTypePortForward} {
+		reports := byType[connectionType]
+		require.Len(t, reports, 2, connectionType)
+		require.Equal(t, codersdk.AuditActionConnect, reports[0].Action)
+		require.Equal(t, codersdk.AuditActionDisconnect, reports[1].Action)
+		require.Equal(t, reports[0].ID, reports[1].ID)
+		require.NotEmpty(t, reports[0].RemoteAddr)
+	}
+	require.Equal(t, listener.Addr().String(), byType[codersdk.ConnectionTypePortForward][0].Detail)
+}
+
 func TestAgent_Lifecycle(t *testing.T) {
 	t.Parallel()
 
@@ -1238,6 +1326,7 @@ type client struct {
 	mu              sync.Mutex // Protects following.
 	lifecycleStates []codersdk.WorkspaceAgentLifecycle
 	startup         agentsdk.PostStartupRequest
+	connections     []agentsdk.PostConnectionRequest
 }
 
 func (c *client) Metadata(_ context.Context) (agentsdk.Metadata, error) {
@@ -1322,6 +1411,19 @@ func (c *client) PostStartup(_ context.Context, startup agentsdk.PostStartupRequ
 	return nil
 }
 
+func (c *client) getConnections() []agentsdk.PostConnectionRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]agentsdk.PostConnectionRequest{}, c.connections...)
+}
+
+func (c *client) PostConnection(_ context.Context, req agentsdk.PostConnectionRequest) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.connections = append(c.connections, req)
+	return nil
+}
+
 // tempDirUnixSocket returns a temporary directory that can safely hold unix
 // sockets (probably).
 //
@@ -6,6 +6,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"strconv"
 	"sync"
 
 	"github.com/gliderlabs/ssh"
@@ -13,6 +14,7 @@ import (
 	"golang.org/x/xerrors"
 
 	"cdr.dev/slog"
+	"github.com/coder/coder/codersdk"
 )
 
 // streamLocalForwardPayload describes the extra data sent in a
@@ -177,7 +179,7 @@ type directStreamLocalPayload struct {
 	Reserved2 uint32
 }
 
-func directStreamLocalHandler(_ *ssh.Server, _ *gossh.ServerConn, newChan gossh.NewChannel, ctx ssh.Context) {
+func (a *agent) directStreamLocalHandler(_ *ssh.Server, conn *gossh.ServerConn, newChan gossh.NewChannel, ctx ssh.Context) {
 	var reqPayload directStreamLocalPayload
 	err := gossh.Unmarshal(newChan.ExtraData(), &reqPayload)
 	if err != nil {
@@ -199,5 +201,58 @@ func directStreamLocalHandler(_ *ssh.Server, _ *gossh.ServerConn, newChan gossh.
 	}
 	go gossh.DiscardRequests(reqs)
 
+	disconnect := a.reportConnection(codersdk.ConnectionTypePortForward, conn.RemoteAddr(), reqPayload.SocketPath)
+	defer disconnect()
 	Bicopy(ctx, ch, dconn)
 }
+
+// directTCPIPPayload describes the extra data sent in a direct-tcpip channel
+// request containing the address to connect to.
+type directTCPIPPayload struct {
+	DestAddr   string
+	DestPort   uint32
+	OriginAddr string
+	OriginPort uint32
+}
+
+// directTCPIPHandler is a clone of ssh.DirectTCPIPHandler that reports the
+// forwarded connection to coderd until it's closed.
+func (a *agent) directTCPIPHandler(srv *ssh.Server, conn *gossh.ServerConn, newChan gossh.NewChannel, ctx ssh.Context) {
+	var reqPayload directTCPIPPayload
+	err := gossh.Unmarshal(newChan.ExtraData(), &reqPayload)
+	if err != nil {
+		_ = newChan.Reject(gossh.ConnectionFailed, "error parsing forward data: "+err.Error())
+		return
+	}
+
+	if srv.LocalPortForwardingCallback == nil || !srv.LocalPortForwardingCallback(ctx, reqPayload.DestAddr, reqPayload.DestPort) {
+		_ = newChan.Reject(gossh.Prohibited, "port forwarding is disabled")
+		return
+	}
+
+	dest := net.JoinHostPort(reqPayload.DestAddr, strconv.FormatUint(uint64(reqPayload.DestPort), 10))
+	var dialer net.Dialer
+	dconn, err := dialer.DialContext(ctx, "tcp", dest)
+	if err != nil {
+		_ = newChan.Reject(gossh.ConnectionFailed, err.Error())
+		return
+	}
+
+	ch, reqs, err := newChan.Accept()
+	if err != nil {
+		_ = dconn.Close()
+		return
+	}
+	go gossh.DiscardRequests(reqs)
+
+	disconnect := a.reportConnection(codersdk.ConnectionTypePortForward, conn.RemoteAddr(), dest)
+	defer disconnect()
+	Bicopy(ctx, ch, dconn)
+}
+
+// sessionHandler reports SSH sessions to coderd while they're open.
+func (a *agent) sessionHandler(srv *ssh.Server, conn *gossh.ServerConn, newChan gossh.NewChannel, ctx ssh.Context) {
+	disconnect := a.reportConnection(codersdk.ConnectionTypeSSH, conn.RemoteAddr(), "")
+	defer disconnect()
+	ssh.DefaultSessionHandler(srv, conn, newChan, ctx)
+}
@@ -53,6 +53,11 @@ func ProvisionerJob(ctx context.Context, writer io.Writer, opts ProvisionerJobOp
 		currentStage          = "Queued"
 		currentStageStartedAt = time.Now().UTC()
 		didLogBetweenStage    = false
+		// queueStatus is shown next to the stage while the job is queued.
+		queueStatus = ""
+		// queueWarning is the last warning shown about why the job isn't
+		// acquired.
+		queueWarning = ""
 
 		errChan  = make(chan error, 1)
 		job      codersdk.ProvisionerJob
@@ -60,7 +65,7 @@ func ProvisionerJob(ctx context.Context, writer io.Writer, opts ProvisionerJobOp
 	)
 
 	printStage := func() {
-		_, _ = fmt.Fprintf(writer, Styles.Prompt.Render("⧗")+"%s\n", Styles.Field.Render(currentStage))
+		_, _ = fmt.Fprintf(writer, Styles.Prompt.Render("⧗")+"%s%s\n", Styles.Field.Render(currentStage), Styles.Placeholder.Render(queueStatus))
 	}
 
 	updateStage := func(stage string, startedAt time.Time) {
@@ -94,6 +99,18 @@ func ProvisionerJob(ctx context.Context, writer io.Writer, opts ProvisionerJobOp
 			return
 		}
 		if job.StartedAt == nil {
+			status := provisionerJobQueueStatus(job.Queue)
+			if currentStage == "Queued" && !didLogBetweenStage && status != queueStatus {
+				// Replace the stage line with the new position.
+				queueStatus = status
+				_, _ = fmt.Fprint(writer, "\033[1A\r\033[2K")
+				printStage()
+			}
+			if job.Queue != nil && job.Queue.Warning != "" && job.Queue.Warning != queueWarning {
+				queueWarning = job.Queue.Warning
+				_, _ = fmt.Fprintln(writer, Styles.Warn.Render("⚠ "+queueWarning))
+				didLogBetweenStage = true
+			}
 			return
 		}
 		if currentStage != "Queued" {
@@ -216,3 +233,17 @@ func ProvisionerJob(ctx context.Context, writer io.Writer, opts ProvisionerJobOp
 		}
 	}
 }
+
+// provisionerJobQueueStatus describes the position of a queued job, and how
+// long it's expected to wait.
+func provisionerJobQueueStatus(queue *codersdk.ProvisionerJobQueue) string {
+	if queue == nil {
+		return ""
+	}
+	status := fmt.Sprintf(" (position %d of %d", queue.Position, queue.Size)
+	if queue.EstimatedWaitMillis != nil {
+		wait := (time.Duration(*queue.EstimatedWaitMillis) * time.Millisecond).Round(time.Second)
+		status += fmt.Sprintf(", estimated wait %s", wait)
+	}
+	return status + ")"
+}
@@ -79,6 +79,67 @@ func TestProvisionerJob(t *testing.T) {
 		test.PTY.ExpectMatch("Something")
 	})
 
+	t.Run("Queue", func(t *testing.T) {
+		t.Parallel()
+
+		test := newProvisionerJob(t)
+		go func() {
+			<-test.Next
+			test.JobMutex.Lock()
+			wait := int64(90 * time.Second / time.Millisecond)
+			test.Job.Queue = &codersdk.ProvisionerJobQueue{
+				Position:            2,
+				Size:                5,
+				EstimatedWaitMillis: &wait,
+			}
+			test.JobMutex.Unlock()
+			<-test.Next
+			test.JobMutex.Lock()
+			test.Job.Status = codersdk.ProvisionerJobSucceeded
+			now := database.Now()
+			test.Job.StartedAt = &now
+			test.Job.CompletedAt = &now
+			test.Job.Queue = nil
+			close(test.Logs)
+			test.JobMutex.Unlock()
+		}()
+		test.PTY.ExpectMatch("Queued")
+		test.Next <- struct{}{}
+		test.PTY.ExpectMatch("position 2 of 5, estimated wait 1m30s")
+		test.Next <- struct{}{}
+		test.PTY.ExpectMatch("Running")
+	})
+
+	t.Run("QueueWarning", func(t *testing.T) {
+		t.Parallel()
+
+		test := newProvisionerJob(t)
+		go func() {
+			<-test.Next
+			test.JobMutex.Lock()
+			test.Job.Queue = &codersdk.ProvisionerJobQueue{
+				Position: 1,
+				Size:     1,
+				Warning:  "No provisioner daemons are online, so the job won't start until one connects.",
+			}
+			test.JobMutex.Unlock()
+			<-test.Next
+			test.JobMutex.Lock()
+			test.Job.Status = codersdk.ProvisionerJobSucceeded
+			now := database.Now()
+			test.Job.StartedAt = &now
+			test.Job.CompletedAt = &now
+			test.Job.Queue = nil
+			close(test.Logs)
+			test.JobMutex.Unlock()
+		}()
+		test.PTY.ExpectMatch("Queued")
+		test.Next <- struct{}{}
+		test.PTY.ExpectMatch("No provisioner daemons are online")
+		test.Next <- struct{}{}
+		test.PTY.ExpectMatch("Running")
+	})
+
 	// This cannot be ran in parallel because it uses a signal.
 	// nolint:paralleltest
 	t.Run("Cancel", func(t *testing.T) {
@@ -24,12 +24,20 @@ func create() *cobra.Command {
 		startAt           string
 		stopAfter         time.Duration
 		workspaceName     string
+		copyFrom          string
+		pinVersion        bool
+		labelArgs         []string
 	)
 	cmd := &cobra.Command{
 		Annotations: workspaceCommand,
 		Use:         "create [name]",
 		Short:       "Create a workspace",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			labels, err := parseWorkspaceLabels(labelArgs)
+			if err != nil {
+				return err
+			}
+
 			client, err := CreateClient(cmd)
 			if err != nil {
 				return err
@@ -65,11 +73,31 @@ func create() *cobra.Command {
 				return xerrors.Errorf("A workspace already exists named %q!", workspaceName)
 			}
 
+			if copyFrom != "" {
+				if templateName != "" {
+					return xerrors.New("--template can't be used with --copy-from")
+				}
+				return createFromCopy(cmd, client, createFromCopyArgs{
+					Source:             copyFrom,
+					NewWorkspaceName:   workspaceName,
+					ParameterFile:      parameterFile,
+					RichParameterFile:  richParameterFile,
+					PinTemplateVersion: pinVersion,
+					Labels:             labels,
+				})
+			}
+			if pinVersion {
+				return xerrors.New("--pin-template-version can only be used with --copy-from")
+			}
+
 			var template codersdk.Template
 			if templateName == "" {
 				_, _ = fmt.Fprintln(cmd.OutOrStdout(), cliui.Styles.Wrap.Render("Select a template below to preview the provisioned infrastructure:"))
 
-				templates, err := client.TemplatesByOrganization(cmd.Context(), organization.ID)
+				// Deprecated templates can't be used to create workspaces.
+				templates, err := client.TemplatesByOrganizationWithFilter(cmd.Context(), organization.ID, codersdk.TemplateFilter{
+					SearchQuery: "deprecated:false",
+				})
 				if err != nil {
 					return err
 				}
@@ -112,6 +140,9 @@ func create() *cobra.Command {
 				if err != nil {
 					return xerrors.Errorf("get template by name: %w", err)
 				}
+				if template.Deprecated {
+					return xerrors.Errorf("template %q is deprecated: %s", template.Name, template.DeprecationMessage)
+				}
 			}
 
 			var schedSpec *string
@@ -149,6 +180,7 @@ func create() *cobra.Command {
 				TTLMillis:           ptr.Ref(stopAfter.Milliseconds()),
 				ParameterValues:     buildParams.parameters,
 				RichParameterValues: buildParams.richParameters,
+				Labels:              labels,
 			})
 			if err != nil {
 				return err
@@ -170,9 +202,100 @@ func create() *cobra.Command {
 	cliflag.StringVarP(cmd.Flags(), &richParameterFile, "rich-parameter-file", "", "CODER_RICH_PARAMETER_FILE", "", "Specify a file path with values for rich parameters defined in the template.")
 	cliflag.StringVarP(cmd.Flags(), &startAt, "start-at", "", "CODER_WORKSPACE_START_AT", "", "Specify the workspace autostart schedule. Check `coder schedule start --help` for the syntax.")
 	cliflag.DurationVarP(cmd.Flags(), &stopAfter, "stop-after", "", "CODER_WORKSPACE_STOP_AFTER", 8*time.Hour, "Specify a duration after which the workspace should shut down (e.g. 8h).")
+	cmd.Flags().StringVar(&copyFrom, "copy-from", "", "Create the workspace with the template, schedule and parameter values of another workspace. Values in the parameter files override the copied ones.")
+	cmd.Flags().BoolVar(&pinVersion, "pin-template-version", false, "Use the template version of the workspace given with --copy-from instead of the active version.")
+	cmd.Flags().StringArrayVar(&labelArgs, "label", nil, "Add a label to the workspace in the format key=value, can be specified multiple times.")
 	return cmd
 }
 
+type createFromCopyArgs struct {
+	Source             string
+	NewWorkspaceName   string
+	ParameterFile      string
+	RichParameterFile  string
+	PinTemplateVersion bool
+	Labels             map[string]string
+}
+
+// createFromCopy clones the source workspace. Unlike a regular create, it
+// only prompts for confirmation since the parameter values are copied.
+func createFromCopy(cmd *cobra.Command, client *codersdk.Client, args createFromCopyArgs) error {
+	ctx := cmd.Context()
+	source, err := namedWorkspace(cmd, client, args.Source)
+	if err != nil {
+		return xerrors.Errorf("get workspace: %w", err)
+	}
+
+	req := codersdk.CloneWorkspaceRequest{
+		Name:               args.NewWorkspaceName,
+		PinTemplateVersion: args.PinTemplateVersion,
+		Labels:             args.Labels,
+	}
+	if args.RichParameterFile != "" {
+		values, err := createParameterMapFromFile(args.RichParameterFile)
+		if err != nil {
+			return err
+		}
+		for name, value := range values {
+			req.RichParameterValues = append(req.RichParameterValues, codersdk.WorkspaceBuildParameter{
+				Name:  name,
+				Value: value,
+			})
+		}
+	}
+	if args.ParameterFile != "" {
+		values, err := createParameterMapFromFile(args.ParameterFile)
+		if err != nil {
+			return err
+		}
+		templateVersionID := source.LatestBuild.TemplateVersionID
+		if !args.PinTemplateVersion {
+			template, err := client.Template(ctx, source.TemplateID)
+			if err != nil {
+				return xerrors.Errorf("get template: %w", err)
+			}
+			templateVersionID = template.ActiveVersionID
+		}
+		parameterSchemas, err := client.TemplateVersionSchema(ctx, templateVersionID)
+		if err != nil {
+			return err
+		}
+		for _, parameterSchema := range parameterSchemas {
+			value, ok := values[parameterSchema.Name]
+			if !ok || !parameterSchema.AllowOverrideSource {
+				continue
+			}
+			req.ParameterValues = append(req.ParameterValues, codersdk.CreateParameterRequest{
+				Name:              parameterSchema.Name,
+				SourceValue:       value,
+				SourceScheme:      codersdk.ParameterSourceSchemeData,
+				DestinationScheme: parameterSchema.DefaultDestinationScheme,
+			})
+		}
+	}
+
+	_, err = cliui.Prompt(cmd, cliui.PromptOptions{
+		Text:      fmt.Sprintf("Confirm create a copy of %s?", source.Name),
+		IsConfirm: true,
+	})
+	if err != nil {
+		return err
+	}
+
+	workspace, err := client.CloneWorkspace(ctx, source.ID, req)
+	if err != nil {
+		return err
+	}
+
+	err = cliui.WorkspaceBuild(ctx, cmd.OutOrStdout(), client, workspace.LatestBuild.ID)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "\nThe %s workspace has been created at %s!\n", cliui.Styles.Keyword.Render(workspace.Name), cliui.Styles.DateTimeStamp.Render(time.Now().Format(time.Stamp)))
+	return nil
+}
+
 type prepWorkspaceBuildArgs struct {
 	Template           codersdk.Template
 	ExistingParams     []codersdk.Parameter
@@ -44,6 +44,7 @@ func TestCreate(t *testing.T) {
 			"--template", template.Name,
 			"--start-at", "9:30AM Mon-Fri US/Central",
 			"--stop-after", "8h",
+			"--label", "project=coder",
 		}
 		cmd, root := clitest.New(t, args...)
 		clitest.SetupConfig(t, client, root)
@@ -75,6 +76,7 @@ func TestCreate(t *testing.T) {
 		ws, err := client.WorkspaceByOwnerAndName(context.Background(), "testuser", "my-workspace", codersdk.WorkspaceOptions{})
 		if assert.NoError(t, err, "expected workspace to be created") {
 			assert.Equal(t, ws.TemplateName, template.Name)
+			assert.Equal(t, map[string]string{"project": "coder"}, ws.Labels)
 			if assert.NotNil(t, ws.AutostartSchedule) {
 				assert.Equal(t, *ws.AutostartSchedule, "CRON_TZ=US/Central 30 9 * * Mon-Fri")
 			}
@@ -444,6 +446,60 @@ func TestCreateWithRichParameters(t *testing.T) {
 		}
 		<-doneChan
 	})
+
+	t.Run("CopyFrom", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, echoResponses)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		source := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.RichParameterValues = []codersdk.WorkspaceBuildParameter{
+				{Name: firstParameterName, Value: firstParameterValue},
+				{Name: secondParameterName, Value: secondParameterValue},
+				{Name: immutableParameterName, Value: immutableParameterValue},
+			}
+		})
+		coderdtest.AwaitWorkspaceBuildJob(t, client, source.LatestBuild.ID)
+
+		// Values in the file override the copied ones.
+		tempDir := t.TempDir()
+		removeTmpDirUntilSuccessAfterTest(t, tempDir)
+		parameterFile, _ := os.CreateTemp(tempDir, "testParameterFile*.yaml")
+		_, _ = parameterFile.WriteString(secondParameterName + ": 4")
+		cmd, root := clitest.New(t, "create", "my-copy", "--copy-from", source.Name, "--rich-parameter-file", parameterFile.Name())
+		clitest.SetupConfig(t, client, root)
+
+		doneChan := make(chan struct{})
+		pty := ptytest.New(t)
+		cmd.SetIn(pty.Input())
+		cmd.SetOut(pty.Output())
+		go func() {
+			defer close(doneChan)
+			err := cmd.Execute()
+			assert.NoError(t, err)
+		}()
+
+		pty.ExpectMatch("Confirm create a copy")
+		pty.WriteLine("yes")
+		pty.ExpectMatch("has been created")
+		<-doneChan
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+		workspace, err := client.WorkspaceByOwnerAndName(ctx, codersdk.Me, "my-copy", codersdk.WorkspaceOptions{})
+		require.NoError(t, err)
+		parameters, err := client.WorkspaceBuildParameters(ctx, workspace.LatestBuild.ID)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []codersdk.WorkspaceBuildParameter{
+			{Name: firstParameterName, Value: firstParameterValue},
+			{Name: secondParameterName, Value: "4"},
+			{Name: immutableParameterName, Value: immutableParameterValue},
+		}, parameters)
+	})
 }
 
 func TestCreateValidateRichParameters(t *testing.T) {
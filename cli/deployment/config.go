@@ -422,6 +422,108 @@ func newConfig() *codersdk.DeploymentConfig {
 			Default:    true,
 			Enterprise: true,
 		},
+		AuditStreaming: &codersdk.AuditStreamingConfig{
+			SyslogAddress: &codersdk.DeploymentConfigField[string]{
+				Name:       "Audit Streaming Syslog Address",
+				Usage:      "Address of a syslog server to send audit logs to as RFC 5424 messages, e.g. \"udp://localhost:514\", \"tcp://localhost:601\" or \"tls://siem.example.com:6514\".",
+				Flag:       "audit-streaming-syslog-address",
+				Enterprise: true,
+			},
+			SyslogFilter: &codersdk.DeploymentConfigField[[]string]{
+				Name:       "Audit Streaming Syslog Filter",
+				Usage:      "Filters of the audit logs sent to syslog. Audit logs that match any of the filters are sent, e.g. \"workspace\" for every workspace audit log, or \"user:delete\". Either side can be \"*\". Every audit log is sent if empty.",
+				Flag:       "audit-streaming-syslog-filter",
+				Enterprise: true,
+			},
+			WebhookURL: &codersdk.DeploymentConfigField[string]{
+				Name:       "Audit Streaming Webhook URL",
+				Usage:      "URL to POST batches of audit logs to as a JSON array.",
+				Flag:       "audit-streaming-webhook-url",
+				Enterprise: true,
+			},
+			WebhookSecret: &codersdk.DeploymentConfigField[string]{
+				Name:       "Audit Streaming Webhook Secret",
+				Usage:      "Secret to sign the audit log webhook body with. The HMAC-SHA256 signature is sent in the X-Coder-Signature header.",
+				Flag:       "audit-streaming-webhook-secret",
+				Enterprise: true,
+				Secret:     true,
+			},
+			WebhookBatchSize: &codersdk.DeploymentConfigField[int]{
+				Name:       "Audit Streaming Webhook Batch Size",
+				Usage:      "The most audit logs sent in a webhook request.",
+				Flag:       "audit-streaming-webhook-batch-size",
+				Default:    100,
+				Enterprise: true,
+			},
+			WebhookFlushInterval: &codersdk.DeploymentConfigField[time.Duration]{
+				Name:       "Audit Streaming Webhook Flush Interval",
+				Usage:      "How long to wait for a batch of audit logs to fill up before sending it to the webhook.",
+				Flag:       "audit-streaming-webhook-flush-interval",
+				Default:    time.Second,
+				Enterprise: true,
+			},
+			WebhookFilter: &codersdk.DeploymentConfigField[[]string]{
+				Name:       "Audit Streaming Webhook Filter",
+				Usage:      "Filters of the audit logs sent to the webhook. Audit logs that match any of the filters are sent, e.g. \"workspace\" for every workspace audit log, or \"user:delete\". Either side can be \"*\". Every audit log is sent if empty.",
+				Flag:       "audit-streaming-webhook-filter",
+				Enterprise: true,
+			},
+			FilePath: &codersdk.DeploymentConfigField[string]{
+				Name:       "Audit Streaming File Path",
+				Usage:      "Path of a file to append audit logs to as JSON lines.",
+				Flag:       "audit-streaming-file-path",
+				Enterprise: true,
+			},
+			FileMaxSize: &codersdk.DeploymentConfigField[int]{
+				Name:       "Audit Streaming File Max Size",
+				Usage:      "Size in megabytes after which the audit log file is rotated.",
+				Flag:       "audit-streaming-file-max-size",
+				Default:    100,
+				Enterprise: true,
+			},
+			FileMaxBackups: &codersdk.DeploymentConfigField[int]{
+				Name:       "Audit Streaming File Max Backups",
+				Usage:      "The number of rotated audit log files to keep. Every rotated file is kept if 0.",
+				Flag:       "audit-streaming-file-max-backups",
+				Default:    10,
+				Enterprise: true,
+			},
+			FileFilter: &codersdk.DeploymentConfigField[[]string]{
+				Name:       "Audit Streaming File Filter",
+				Usage:      "Filters of the audit logs appended to the file. Audit logs that match any of the filters are sent, e.g. \"workspace\" for every workspace audit log, or \"user:delete\". Either side can be \"*\". Every audit log is sent if empty.",
+				Flag:       "audit-streaming-file-filter",
+				Enterprise: true,
+			},
+			BufferSize: &codersdk.DeploymentConfigField[int]{
+				Name:       "Audit Streaming Buffer Size",
+				Usage:      "The most audit logs kept for each streaming backend while it's unavailable. They're retried until the backend accepts them. Newer audit logs aren't streamed while the buffer is full.",
+				Flag:       "audit-streaming-buffer-size",
+				Default:    10000,
+				Enterprise: true,
+			},
+		},
+		AuditLogRetention: &codersdk.AuditLogRetentionConfig{
+			Period: &codersdk.DeploymentConfigField[time.Duration]{
+				Name:       "Audit Log Retention Period",
+				Usage:      "How long to keep audit logs. Older audit logs are deleted in batches. Audit logs are kept forever if 0.",
+				Flag:       "audit-log-retention-period",
+				Default:    0,
+				Enterprise: true,
+			},
+			Archive: &codersdk.DeploymentConfigField[bool]{
+				Name:       "Audit Log Retention Archive",
+				Usage:      "Whether to archive audit logs to the file storage as gzipped JSON lines before they're deleted. Archived audit logs can still be exported with \"coder audit export\". Requires a file storage backend other than \"database\".",
+				Flag:       "audit-log-retention-archive",
+				Enterprise: true,
+			},
+			BatchSize: &codersdk.DeploymentConfigField[int]{
+				Name:       "Audit Log Retention Batch Size",
+				Usage:      "How many audit logs to archive and delete at once.",
+				Flag:       "audit-log-retention-batch-size",
+				Default:    1000,
+				Enterprise: true,
+			},
+		},
 		BrowserOnly: &codersdk.DeploymentConfigField[bool]{
 			Name:       "Browser Only",
 			Usage:      "Whether Coder only allows connections to workspaces via the browser.",
@@ -460,6 +562,24 @@ func newConfig() *codersdk.DeploymentConfig {
 				Flag:    "provisioner-force-cancel-interval",
 				Default: 10 * time.Minute,
 			},
+			JobHangTimeout: &codersdk.DeploymentConfigField[time.Duration]{
+				Name:    "Job Hang Timeout",
+				Usage:   "Time after which a running provisioner job is marked as failed if its provisioner daemon hasn't sent an update, e.g. because the daemon crashed. Canceled jobs are given the force cancel interval on top of this. Set to 0 to disable.",
+				Flag:    "provisioner-job-hang-timeout",
+				Default: 5 * time.Minute,
+			},
+			JobMaxRuntime: &codersdk.DeploymentConfigField[time.Duration]{
+				Name:    "Job Max Runtime",
+				Usage:   "Maximum time a provisioner job can run before it's canceled. Jobs that don't stop within the force cancel interval of being canceled are marked as failed. Set to 0 for no limit.",
+				Flag:    "provisioner-job-max-runtime",
+				Default: 0,
+			},
+			JobLogRetention: &codersdk.DeploymentConfigField[time.Duration]{
+				Name:    "Job Log Retention",
+				Usage:   "How long to keep the provisioner job logs of workspace builds after they complete. The logs of the latest build of each workspace are always kept. Logs are kept forever if 0.",
+				Flag:    "provisioner-job-log-retention",
+				Default: 0,
+			},
 		},
 		RateLimit: &codersdk.RateLimitConfig{
 			DisableAll: &codersdk.DeploymentConfigField[bool]{
@@ -570,6 +690,81 @@ func newConfig() *codersdk.DeploymentConfig {
 			Flag:    "disable-password-auth",
 			Default: false,
 		},
+		Email: &codersdk.EmailConfig{
+			From: &codersdk.DeploymentConfigField[string]{
+				Name:  "Email From Address",
+				Usage: "The address email notifications are sent from, e.g. \"Coder <coder@example.com>\".",
+				Flag:  "email-from",
+			},
+			Smarthost: &codersdk.DeploymentConfigField[string]{
+				Name:  "Email Smarthost",
+				Usage: "The host:port of the SMTP server email notifications are sent through. Email notifications are disabled if unset.",
+				Flag:  "email-smarthost",
+			},
+			Username: &codersdk.DeploymentConfigField[string]{
+				Name:  "Email Username",
+				Usage: "Username to authenticate with the SMTP server.",
+				Flag:  "email-username",
+			},
+			Password: &codersdk.DeploymentConfigField[string]{
+				Name:   "Email Password",
+				Usage:  "Password to authenticate with the SMTP server.",
+				Flag:   "email-password",
+				Secret: true,
+			},
+		},
+		TemplateVersionGCInterval: &codersdk.DeploymentConfigField[time.Duration]{
+			Name:    "Template Version GC Interval",
+			Usage:   "How often to delete the files and provisioner job logs that are only used by archived template versions, to reclaim database space. Archived template versions whose files were deleted can't be unarchived. Garbage collection is disabled if 0.",
+			Flag:    "template-version-gc-interval",
+			Default: 0,
+		},
+		TemplateGitSyncInterval: &codersdk.DeploymentConfigField[time.Duration]{
+			Name:    "Template Git Sync Interval",
+			Usage:   "How often to check the branches of templates that are synced with git for new commits. Push webhooks trigger a sync immediately. Git syncs are disabled if 0.",
+			Flag:    "template-git-sync-interval",
+			Default: time.Minute,
+		},
+		FileStorage: &codersdk.FileStorageConfig{
+			Backend: &codersdk.DeploymentConfigField[string]{
+				Name:    "File Storage Backend",
+				Usage:   "Where the contents of uploaded files and template source archives are stored. Accepted values are \"database\", \"fs\" and \"s3\". Files that are already stored in the database can be moved with \"coder server migrate-files\".",
+				Flag:    "file-storage-backend",
+				Default: "database",
+			},
+			Directory: &codersdk.DeploymentConfigField[string]{
+				Name:  "File Storage Directory",
+				Usage: "The directory files are stored in with the \"fs\" backend. It must be shared by all replicas.",
+				Flag:  "file-storage-directory",
+			},
+			S3Endpoint: &codersdk.DeploymentConfigField[string]{
+				Name:  "File Storage S3 Endpoint",
+				Usage: "The URL of the S3 compatible API files are stored in with the \"s3\" backend, e.g. a MinIO server. Defaults to the AWS endpoint of the region.",
+				Flag:  "file-storage-s3-endpoint",
+			},
+			S3Region: &codersdk.DeploymentConfigField[string]{
+				Name:    "File Storage S3 Region",
+				Usage:   "The region of the S3 bucket.",
+				Flag:    "file-storage-s3-region",
+				Default: "us-east-1",
+			},
+			S3Bucket: &codersdk.DeploymentConfigField[string]{
+				Name:  "File Storage S3 Bucket",
+				Usage: "The S3 bucket files are stored in with the \"s3\" backend.",
+				Flag:  "file-storage-s3-bucket",
+			},
+			S3AccessKeyID: &codersdk.DeploymentConfigField[string]{
+				Name:  "File Storage S3 Access Key ID",
+				Usage: "The access key ID to authenticate with the S3 compatible API.",
+				Flag:  "file-storage-s3-access-key-id",
+			},
+			S3SecretAccessKey: &codersdk.DeploymentConfigField[string]{
+				Name:   "File Storage S3 Secret Access Key",
+				Usage:  "The secret access key to authenticate with the S3 compatible API.",
+				Flag:   "file-storage-s3-secret-access-key",
+				Secret: true,
+			},
+		},
 		Support: &codersdk.SupportConfig{
 			Links: &codersdk.DeploymentConfigField[[]codersdk.LinkConfig]{
 				Name:       "Support links",
@@ -794,6 +989,12 @@ func AttachFlags(flagset *pflag.FlagSet, vip *viper.Viper, enterprise bool) {
 	setFlags("", flagset, vip, newConfig(), enterprise)
 }
 
+// AttachFileStorageFlags attaches the flags that configure where files are
+// stored, for commands that access files without starting the server.
+func AttachFileStorageFlags(flagset *pflag.FlagSet, vip *viper.Viper) {
+	setFlags("file_storage", flagset, vip, newConfig().FileStorage, false)
+}
+
 //nolint:revive
 func setFlags(prefix string, flagset *pflag.FlagSet, vip *viper.Viper, target interface{}, enterprise bool) {
 	val := reflect.Indirect(reflect.ValueOf(target))
@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func insights() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "insights",
+		Short: "Report on the usage of the deployment",
+		Example: formatExamples(
+			example{
+				Description: "Export the activity of every user in March as CSV",
+				Command:     "coder insights users --start-date 2023-03-01 --end-date 2023-03-31 -o csv > users.csv",
+			},
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(
+		insightsUsers(),
+	)
+	return cmd
+}
+
+// userInsightsRow is the type provided to the OutputFormatter.
+type userInsightsRow struct {
+	// For JSON and CSV formats:
+	codersdk.UserInsights `table:"-"`
+
+	// For table format:
+	Username       string `json:"-" table:"username,default_sort"`
+	Email          string `json:"-" table:"email"`
+	Status         string `json:"-" table:"status"`
+	Workspaces     int64  `json:"-" table:"workspaces"`
+	Builds         int64  `json:"-" table:"builds"`
+	HoursConnected string `json:"-" table:"hours connected"`
+	CostAccrued    string `json:"-" table:"cost accrued"`
+	LastSeen       string `json:"-" table:"last seen"`
+}
+
+func insightsUsers() *cobra.Command {
+	var (
+		startDate string
+		endDate   string
+		formatter = cliui.NewOutputFormatter(
+			cliui.TableFormat([]userInsightsRow{}, nil),
+			cliui.JSONFormat(),
+			&userInsightsCSVFormat{},
+		)
+	)
+
+	cmd := &cobra.Command{
+		Use:   "users",
+		Short: "Report the activity of every user",
+		Long: "Report the hours connected, workspaces owned, builds run, cost accrued and last activity of every user. " +
+			"Connected time is only kept for the last 30 days.",
+		Example: formatExamples(
+			example{
+				Description: "Report the activity of every user over the last 30 days",
+				Command:     "coder insights users",
+			},
+			example{
+				Description: "Export the activity of every user in March as CSV",
+				Command:     "coder insights users --start-date 2023-03-01 --end-date 2023-03-31 -o csv > users.csv",
+			},
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var req codersdk.UserInsightsRequest
+			if startDate != "" {
+				start, err := time.Parse(insightsDateFormat, startDate)
+				if err != nil {
+					return xerrors.Errorf("parse start date: %w", err)
+				}
+				req.StartTime = start
+			}
+			if endDate != "" {
+				end, err := time.Parse(insightsDateFormat, endDate)
+				if err != nil {
+					return xerrors.Errorf("parse end date: %w", err)
+				}
+				// The end date is included in the range.
+				req.EndTime = end.Add(24 * time.Hour)
+			}
+
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create client: %w", err)
+			}
+			res, err := client.UserInsights(cmd.Context(), req)
+			if err != nil {
+				return xerrors.Errorf("get user insights: %w", err)
+			}
+
+			rows := make([]userInsightsRow, 0, len(res.Users))
+			for _, user := range res.Users {
+				lastSeen := "never"
+				if !user.LastSeenAt.IsZero() {
+					lastSeen = user.LastSeenAt.Format(insightsDateFormat)
+				}
+				rows = append(rows, userInsightsRow{
+					UserInsights:   user,
+					Username:       user.Username,
+					Email:          user.Email,
+					Status:         string(user.Status),
+					Workspaces:     user.WorkspacesOwned,
+					Builds:         user.Builds,
+					HoursConnected: fmt.Sprintf("%.1f", float64(user.ConnectedSeconds)/3600),
+					CostAccrued:    fmt.Sprintf("%.2f", user.CostAccrued),
+					LastSeen:       lastSeen,
+				})
+			}
+
+			out, err := formatter.Format(cmd.Context(), rows)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), out)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&startDate, "start-date", "", "The first day to report on, as YYYY-MM-DD. Defaults to 30 days ago.")
+	cmd.Flags().StringVar(&endDate, "end-date", "", "The last day to report on, as YYYY-MM-DD. Defaults to today.")
+	formatter.AttachFlags(cmd)
+	return cmd
+}
+
+type userInsightsCSVFormat struct{}
+
+var _ cliui.OutputFormat = &userInsightsCSVFormat{}
+
+// ID implements OutputFormat.
+func (*userInsightsCSVFormat) ID() string {
+	return "csv"
+}
+
+// AttachFlags implements OutputFormat.
+func (*userInsightsCSVFormat) AttachFlags(_ *cobra.Command) {}
+
+// Format implements OutputFormat.
+func (*userInsightsCSVFormat) Format(_ context.Context, out interface{}) (string, error) {
+	rows, ok := out.([]userInsightsRow)
+	if !ok {
+		return "", xerrors.Errorf("expected type %T, got %T", rows, out)
+	}
+
+	var res codersdk.UserInsightsResponse
+	for _, row := range rows {
+		res.Users = append(res.Users, row.UserInsights)
+	}
+	var sb strings.Builder
+	err := res.WriteCSV(&sb)
+	if err != nil {
+		return "", err
+	}
+	// The output is printed with a trailing newline.
+	return strings.TrimSuffix(sb.String(), "\n"), nil
+}
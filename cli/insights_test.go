@@ -0,0 +1,101 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/pty/ptytest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestInsightsUsers(t *testing.T) {
+	t.Parallel()
+
+	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+	user := coderdtest.CreateFirstUser(t, client)
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	t.Run("Table", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		cmd, root := clitest.New(t, "insights", "users")
+		clitest.SetupConfig(t, client, root)
+		pty := ptytest.New(t)
+		cmd.SetOut(pty.Output())
+		err := cmd.ExecuteContext(ctx)
+		require.NoError(t, err)
+		pty.ExpectMatch("HOURS CONNECTED")
+		pty.ExpectMatch("testuser")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		cmd, root := clitest.New(t, "insights", "users", "-o", "json")
+		clitest.SetupConfig(t, client, root)
+		out := bytes.NewBuffer(nil)
+		cmd.SetOut(out)
+		err := cmd.ExecuteContext(ctx)
+		require.NoError(t, err)
+
+		var users []codersdk.UserInsights
+		require.NoError(t, json.Unmarshal(out.Bytes(), &users))
+		require.Len(t, users, 1)
+		require.Equal(t, user.UserID, users[0].UserID)
+		require.EqualValues(t, 1, users[0].WorkspacesOwned)
+		require.EqualValues(t, 1, users[0].Builds)
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		cmd, root := clitest.New(t, "insights", "users", "-o", "csv")
+		clitest.SetupConfig(t, client, root)
+		out := bytes.NewBuffer(nil)
+		cmd.SetOut(out)
+		err := cmd.ExecuteContext(ctx)
+		require.NoError(t, err)
+
+		records, err := csv.NewReader(out).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		require.Equal(t, []string{
+			"username", "email", "status", "workspaces_owned", "builds",
+			"hours_connected", "cost_accrued", "last_seen_at",
+		}, records[0])
+		require.Equal(t, "1", records[1][3])
+	})
+
+	t.Run("InvalidDate", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		cmd, root := clitest.New(t, "insights", "users", "--start-date", "yesterday")
+		clitest.SetupConfig(t, client, root)
+		err := cmd.ExecuteContext(ctx)
+		require.ErrorContains(t, err, "parse start date")
+	})
+}
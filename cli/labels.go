@@ -0,0 +1,185 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func labels() *cobra.Command {
+	cmd := &cobra.Command{
+		Annotations: workspaceCommand,
+		Use:         "labels",
+		Short:       "Organize workspaces with key/value labels",
+		Long: "Labels can be used to search workspaces with \"label:key=value\", and are passed to " +
+			"templates that declare the \"coder_workspace_labels\" variable.",
+		Aliases: []string{"label"},
+		Example: formatExamples(
+			example{
+				Description: "Label a workspace with the project and ticket it is used for",
+				Command:     "coder labels set my-workspace project=coder ticket=eng-1234",
+			},
+			example{
+				Description: "Remove a label from a workspace",
+				Command:     "coder labels remove my-workspace ticket",
+			},
+			example{
+				Description: "List the workspaces with a label",
+				Command:     "coder list --search \"label:project=coder\"",
+			},
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(
+		listLabels(),
+		removeLabels(),
+		setLabels(),
+	)
+
+	return cmd
+}
+
+func setLabels() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <workspace> <key=value>...",
+		Short: "Add or change labels of a workspace",
+		Args:  cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			set, err := parseWorkspaceLabels(args[1:])
+			if err != nil {
+				return err
+			}
+			return updateLabels(cmd, args[0], func(labels map[string]string) error {
+				for key, value := range set {
+					labels[key] = value
+				}
+				return nil
+			})
+		},
+	}
+}
+
+func removeLabels() *cobra.Command {
+	return &cobra.Command{
+		Use:     "remove <workspace> <key>...",
+		Aliases: []string{"rm"},
+		Short:   "Remove labels from a workspace",
+		Args:    cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateLabels(cmd, args[0], func(labels map[string]string) error {
+				for _, key := range args[1:] {
+					if _, ok := labels[key]; !ok {
+						return xerrors.Errorf("workspace has no label %q", key)
+					}
+					delete(labels, key)
+				}
+				return nil
+			})
+		},
+	}
+}
+
+// updateLabels applies the change to the current labels of the workspace and
+// saves them.
+func updateLabels(cmd *cobra.Command, identifier string, change func(labels map[string]string) error) error {
+	client, err := CreateClient(cmd)
+	if err != nil {
+		return xerrors.Errorf("create codersdk client: %w", err)
+	}
+	workspace, err := namedWorkspace(cmd, client, identifier)
+	if err != nil {
+		return xerrors.Errorf("get workspace: %w", err)
+	}
+
+	labels := make(map[string]string, len(workspace.Labels))
+	for key, value := range workspace.Labels {
+		labels[key] = value
+	}
+	err = change(labels)
+	if err != nil {
+		return err
+	}
+
+	err = client.UpdateWorkspace(cmd.Context(), workspace.ID, codersdk.UpdateWorkspaceRequest{
+		Labels: labels,
+	})
+	if err != nil {
+		return xerrors.Errorf("update workspace labels: %w", err)
+	}
+
+	cmd.Printf("Updated the labels of %s.\n", cliui.Styles.Keyword.Render(workspace.Name))
+	return nil
+}
+
+// labelListRow is the type provided to the OutputFormatter.
+type labelListRow struct {
+	Key   string `json:"key" table:"key,default_sort"`
+	Value string `json:"value" table:"value"`
+}
+
+func listLabels() *cobra.Command {
+	formatter := cliui.NewOutputFormatter(
+		cliui.TableFormat([]labelListRow{}, []string{"key", "value"}),
+		cliui.JSONFormat(),
+	)
+	cmd := &cobra.Command{
+		Use:     "list <workspace>",
+		Aliases: []string{"ls"},
+		Short:   "List the labels of a workspace",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create codersdk client: %w", err)
+			}
+			workspace, err := namedWorkspace(cmd, client, args[0])
+			if err != nil {
+				return xerrors.Errorf("get workspace: %w", err)
+			}
+
+			if len(workspace.Labels) == 0 {
+				cmd.Println(cliui.Styles.Wrap.Render(
+					fmt.Sprintf("%s has no labels.", workspace.Name),
+				))
+				return nil
+			}
+
+			rows := make([]labelListRow, 0, len(workspace.Labels))
+			for key, value := range workspace.Labels {
+				rows = append(rows, labelListRow{Key: key, Value: value})
+			}
+
+			out, err := formatter.Format(cmd.Context(), rows)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), out)
+			return err
+		},
+	}
+
+	formatter.AttachFlags(cmd)
+	return cmd
+}
+
+// parseWorkspaceLabels parses labels in the format key=value. The labels are
+// validated by the server.
+func parseWorkspaceLabels(args []string) (map[string]string, error) {
+	labels := make(map[string]string, len(args))
+	for _, arg := range args {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return nil, xerrors.Errorf("label %q must be in the format key=value", arg)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
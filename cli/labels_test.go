@@ -0,0 +1,78 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestLabels(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+	user := coderdtest.CreateFirstUser(t, client)
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancelFunc()
+
+	// helpful empty response
+	cmd, root := clitest.New(t, "labels", "ls", workspace.Name)
+	clitest.SetupConfig(t, client, root)
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	err := cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "has no labels")
+
+	cmd, root = clitest.New(t, "labels", "set", workspace.Name, "project=coder", "ticket=eng-1")
+	clitest.SetupConfig(t, client, root)
+	err = cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+
+	cmd, root = clitest.New(t, "labels", "set", workspace.Name, "ticket=eng-2")
+	clitest.SetupConfig(t, client, root)
+	err = cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+
+	workspace, err = client.Workspace(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"project": "coder", "ticket": "eng-2"}, workspace.Labels)
+
+	cmd, root = clitest.New(t, "labels", "ls", workspace.Name)
+	clitest.SetupConfig(t, client, root)
+	buf = new(bytes.Buffer)
+	cmd.SetOut(buf)
+	err = cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "project")
+	require.Contains(t, buf.String(), "eng-2")
+
+	cmd, root = clitest.New(t, "labels", "set", workspace.Name, "invalid")
+	clitest.SetupConfig(t, client, root)
+	err = cmd.ExecuteContext(ctx)
+	require.ErrorContains(t, err, "key=value")
+
+	cmd, root = clitest.New(t, "labels", "rm", workspace.Name, "missing")
+	clitest.SetupConfig(t, client, root)
+	err = cmd.ExecuteContext(ctx)
+	require.ErrorContains(t, err, "has no label")
+
+	cmd, root = clitest.New(t, "labels", "rm", workspace.Name, "project", "ticket")
+	clitest.SetupConfig(t, client, root)
+	err = cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+
+	workspace, err = client.Workspace(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.Empty(t, workspace.Labels)
+}
@@ -81,6 +81,8 @@ func Core() []*cobra.Command {
 		deleteWorkspace(),
 		dotfiles(),
 		gitssh(),
+		insights(),
+		labels(),
 		list(),
 		login(),
 		logout(),
@@ -93,6 +95,7 @@ func Core() []*cobra.Command {
 		restart(),
 		scaletest(),
 		schedules(),
+		sharing(),
 		show(),
 		speedtest(),
 		ssh(),
@@ -101,10 +104,12 @@ func Core() []*cobra.Command {
 		stop(),
 		templates(),
 		tokens(),
+		transfer(),
 		update(),
 		users(),
 		versionCmd(),
 		vscodeSSH(),
+		webhooks(),
 		workspaceAgent(),
 	}
 }
@@ -263,18 +268,7 @@ func isTest() bool {
 // It reads from global configuration files if flags are not set.
 func CreateClient(cmd *cobra.Command) (*codersdk.Client, error) {
 	root := createConfig(cmd)
-	rawURL, err := cmd.Flags().GetString(varURL)
-	if err != nil || rawURL == "" {
-		rawURL, err = root.URL().Read()
-		if err != nil {
-			// If the configuration files are absent, the user is logged out
-			if os.IsNotExist(err) {
-				return nil, errUnauthenticated
-			}
-			return nil, err
-		}
-	}
-	serverURL, err := url.Parse(strings.TrimSpace(rawURL))
+	serverURL, err := readServerURL(cmd, root)
 	if err != nil {
 		return nil, err
 	}
@@ -328,6 +322,33 @@ func CreateClient(cmd *cobra.Command) (*codersdk.Client, error) {
 	return client, nil
 }
 
+// CreateUnauthenticatedClient returns a new client from the command context
+// without a session token, for commands that authenticate another way.
+func CreateUnauthenticatedClient(cmd *cobra.Command) (*codersdk.Client, error) {
+	serverURL, err := readServerURL(cmd, createConfig(cmd))
+	if err != nil {
+		return nil, err
+	}
+	return createUnauthenticatedClient(cmd, serverURL)
+}
+
+// readServerURL returns the URL of the deployment from the flag, or from the
+// config if the flag isn't set.
+func readServerURL(cmd *cobra.Command, root config.Root) (*url.URL, error) {
+	rawURL, err := cmd.Flags().GetString(varURL)
+	if err != nil || rawURL == "" {
+		rawURL, err = root.URL().Read()
+		if err != nil {
+			// If the configuration files are absent, the user is logged out
+			if os.IsNotExist(err) {
+				return nil, errUnauthenticated
+			}
+			return nil, err
+		}
+	}
+	return url.Parse(strings.TrimSpace(rawURL))
+}
+
 func createUnauthenticatedClient(cmd *cobra.Command, serverURL *url.URL) (*codersdk.Client, error) {
 	client := codersdk.New(serverURL)
 	headers, err := cmd.Flags().GetStringArray(varHeader)
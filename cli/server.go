@@ -64,13 +64,16 @@ import (
 	"github.com/coder/coder/coderd/database/dbfake"
 	"github.com/coder/coder/coderd/database/migrations"
 	"github.com/coder/coder/coderd/devtunnel"
+	"github.com/coder/coder/coderd/filestore"
 	"github.com/coder/coder/coderd/gitauth"
 	"github.com/coder/coder/coderd/gitsshkey"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/notifications"
 	"github.com/coder/coder/coderd/prometheusmetrics"
 	"github.com/coder/coder/coderd/telemetry"
 	"github.com/coder/coder/coderd/tracing"
+	"github.com/coder/coder/coderd/unhanger"
 	"github.com/coder/coder/coderd/updatecheck"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/cryptorand"
@@ -474,6 +477,9 @@ func Server(vip *viper.Viper, newAPI func(context.Context, *coderd.Options) (*co
 				Telemetry:                   telemetry.NewNoop(),
 				MetricsCacheRefreshInterval: cfg.MetricsCacheRefreshInterval.Value,
 				AgentStatsRefreshInterval:   cfg.AgentStatRefreshInterval.Value,
+				TemplateVersionGCInterval:   cfg.TemplateVersionGCInterval.Value,
+				TemplateGitSyncInterval:     cfg.TemplateGitSyncInterval.Value,
+				ProvisionerJobLogRetention:  cfg.Provisioner.JobLogRetention.Value,
 				DeploymentConfig:            cfg,
 				PrometheusRegistry:          prometheus.NewRegistry(),
 				APIRateLimit:                cfg.RateLimit.API.Value,
@@ -674,6 +680,29 @@ func Server(vip *viper.Viper, newAPI func(context.Context, *coderd.Options) (*co
 				options.SwaggerEndpoint = cfg.Swagger.Enable.Value
 			}
 
+			if cfg.Email.Smarthost.Value != "" {
+				sender, err := notifications.NewSMTPSender(notifications.SMTPOptions{
+					Smarthost: cfg.Email.Smarthost.Value,
+					From:      cfg.Email.From.Value,
+					Username:  cfg.Email.Username.Value,
+					Password:  cfg.Email.Password.Value,
+				})
+				if err != nil {
+					return xerrors.Errorf("configure email notifications: %w", err)
+				}
+				options.Notifier = notifications.New(notifications.Options{
+					Database:  options.Database,
+					Logger:    logger.Named("notifications"),
+					Sender:    sender,
+					AccessURL: accessURLParsed,
+				})
+			}
+
+			options.FileStore, err = newFileStore(cfg.FileStorage)
+			if err != nil {
+				return xerrors.Errorf("configure file storage: %w", err)
+			}
+
 			// We use a separate coderAPICloser so the Enterprise API
 			// can have it's own close functions. This is cleaner
 			// than abstracting the Coder API itself.
@@ -811,9 +840,22 @@ func Server(vip *viper.Viper, newAPI func(context.Context, *coderd.Options) (*co
 
 			autobuildPoller := time.NewTicker(cfg.AutobuildPollInterval.Value)
 			defer autobuildPoller.Stop()
-			autobuildExecutor := executor.New(ctx, options.Database, logger, autobuildPoller.C)
+			autobuildExecutor := executor.New(ctx, options.Database, logger, autobuildPoller.C).WithNotifier(coderAPI.Notifier)
 			autobuildExecutor.Run()
 
+			hangDetectorTicker := time.NewTicker(unhanger.Interval)
+			defer hangDetectorTicker.Stop()
+			hangDetector := unhanger.New(unhanger.Options{
+				Database:            options.Database,
+				Pubsub:              options.Pubsub,
+				Logger:              logger.Named("unhanger"),
+				Tick:                hangDetectorTicker.C,
+				HangTimeout:         cfg.Provisioner.JobHangTimeout.Value,
+				MaxRuntime:          cfg.Provisioner.JobMaxRuntime.Value,
+				ForceCancelInterval: cfg.Provisioner.ForceCancelInterval.Value,
+			})
+			defer hangDetector.Close()
+
 			// Currently there is no way to ask the server to shut
 			// itself down, so any exit signal will result in a non-zero
 			// exit of the server.
@@ -967,7 +1009,8 @@ func Server(vip *viper.Viper, newAPI func(context.Context, *coderd.Options) (*co
 	postgresBuiltinServeCmd.Flags().BoolVar(&pgRawURL, "raw-url", false, "Output the raw connection URL instead of a psql command.")
 
 	createAdminUserCommand := newCreateAdminUserCommand()
-	root.AddCommand(postgresBuiltinURLCmd, postgresBuiltinServeCmd, createAdminUserCommand)
+	migrateFilesCommand := newMigrateFilesCommand()
+	root.AddCommand(postgresBuiltinURLCmd, postgresBuiltinServeCmd, createAdminUserCommand, migrateFilesCommand)
 
 	deployment.AttachFlags(root.Flags(), vip, false)
 
@@ -1366,6 +1409,30 @@ func configureGithubOAuth2(accessURL *url.URL, clientID, clientSecret string, al
 	}, nil
 }
 
+// newFileStore returns the store configured for the contents of files, which
+// is nil if they're stored in the database.
+func newFileStore(cfg *codersdk.FileStorageConfig) (filestore.Store, error) {
+	switch cfg.Backend.Value {
+	case filestore.BackendDatabase:
+		return nil, nil
+	case filestore.BackendFS:
+		if cfg.Directory.Value == "" {
+			return nil, xerrors.New("a directory is required to store files in, use --file-storage-directory")
+		}
+		return filestore.NewFS(cfg.Directory.Value)
+	case filestore.BackendS3:
+		return filestore.NewS3(filestore.S3Options{
+			Endpoint:        cfg.S3Endpoint.Value,
+			Region:          cfg.S3Region.Value,
+			Bucket:          cfg.S3Bucket.Value,
+			AccessKeyID:     cfg.S3AccessKeyID.Value,
+			SecretAccessKey: cfg.S3SecretAccessKey.Value,
+		})
+	default:
+		return nil, xerrors.Errorf("unknown file storage backend %q, must be one of \"database\", \"fs\" or \"s3\"", cfg.Backend.Value)
+	}
+}
+
 // embeddedPostgresURL returns the URL for the embedded PostgreSQL deployment.
 func embeddedPostgresURL(cfg config.Root) (string, error) {
 	pgPassword, err := cfg.PostgresPassword().Read()
@@ -0,0 +1,91 @@
+//go:build !slim
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	"cdr.dev/slog/sloggers/sloghuman"
+	"github.com/coder/coder/cli/deployment"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/filestore"
+)
+
+func newMigrateFilesCommand() *cobra.Command {
+	var (
+		postgresURL string
+		vip         = deployment.NewViper()
+	)
+	migrateFilesCommand := &cobra.Command{
+		Use:   "migrate-files",
+		Short: "Move the contents of files stored in the database to the configured file storage backend.",
+		Long:  "Move the contents of files stored in the database to the configured file storage backend. Files are moved one at a time, so Coder can keep running while they're moved. Configure the backend the same way as the server, which reads files from both the database and the backend.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if val, exists := os.LookupEnv("CODER_POSTGRES_URL"); exists {
+				postgresURL = val
+			}
+
+			dc, err := deployment.Config(cmd.Flags(), vip)
+			if err != nil {
+				return xerrors.Errorf("get file storage config: %w", err)
+			}
+			store, err := newFileStore(dc.FileStorage)
+			if err != nil {
+				return xerrors.Errorf("configure file storage: %w", err)
+			}
+			if store == nil {
+				return xerrors.New("files are already stored in the database, configure another backend with --file-storage-backend")
+			}
+
+			cfg := createConfig(cmd)
+			logger := slog.Make(sloghuman.Sink(cmd.ErrOrStderr()))
+			if ok, _ := cmd.Flags().GetBool(varVerbose); ok {
+				logger = logger.Leveled(slog.LevelDebug)
+			}
+
+			ctx, cancel := signal.NotifyContext(ctx, InterruptSignals...)
+			defer cancel()
+
+			if postgresURL == "" {
+				cmd.Printf("Using built-in PostgreSQL (%s)\n", cfg.PostgresPath())
+				url, closePg, err := startBuiltinPostgres(ctx, cfg, logger)
+				if err != nil {
+					return err
+				}
+				defer func() {
+					_ = closePg()
+				}()
+				postgresURL = url
+			}
+
+			sqlDB, err := connectToPostgres(ctx, logger, "postgres", postgresURL)
+			if err != nil {
+				return xerrors.Errorf("connect to postgres: %w", err)
+			}
+			defer func() {
+				_ = sqlDB.Close()
+			}()
+			db := database.New(sqlDB)
+
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Moving files to the %q backend...\n", store.Backend())
+			moved, err := filestore.Migrate(ctx, db, store)
+			if err != nil {
+				return xerrors.Errorf("migrate files after moving %d: %w", moved, err)
+			}
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Moved %d files.\n", moved)
+			return nil
+		},
+	}
+	migrateFilesCommand.Flags().StringVar(&postgresURL, "postgres-url", "", "URL of a PostgreSQL database. If empty, the built-in PostgreSQL deployment will be used (Coder must not be already running in this case). Consumes $CODER_POSTGRES_URL.")
+	deployment.AttachFileStorageFlags(migrateFilesCommand.Flags(), vip)
+
+	return migrateFilesCommand
+}
@@ -0,0 +1,85 @@
+package cli_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"runtime"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/postgres"
+	"github.com/coder/coder/coderd/filestore"
+	"github.com/coder/coder/testutil"
+)
+
+func TestServerMigrateFiles(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		if runtime.GOOS != "linux" || testing.Short() {
+			// Skip on non-Linux because it spawns a PostgreSQL instance.
+			t.SkipNow()
+		}
+		connectionURL, closeFunc, err := postgres.Open()
+		require.NoError(t, err)
+		defer closeFunc()
+
+		sqlDB, err := sql.Open("postgres", connectionURL)
+		require.NoError(t, err)
+		defer sqlDB.Close()
+		db := database.New(sqlDB)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		data := []byte("template")
+		hash := sha256.Sum256(data)
+		file, err := filestore.Insert(ctx, db, nil, database.InsertFileParams{
+			ID:        uuid.New(),
+			Hash:      hex.EncodeToString(hash[:]),
+			CreatedAt: database.Now(),
+			CreatedBy: uuid.New(),
+			Mimetype:  "application/x-tar",
+			Data:      data,
+		})
+		require.NoError(t, err)
+
+		dir := t.TempDir()
+		cmd, _ := clitest.New(t,
+			"server", "migrate-files",
+			"--postgres-url", connectionURL,
+			"--file-storage-backend", "fs",
+			"--file-storage-directory", dir,
+		)
+		err = cmd.ExecuteContext(ctx)
+		require.NoError(t, err)
+
+		file, err = db.GetFileByID(ctx, file.ID)
+		require.NoError(t, err)
+		require.Equal(t, filestore.BackendFS, file.Storage)
+		store, err := filestore.NewFS(dir)
+		require.NoError(t, err)
+		got, err := filestore.Read(ctx, store, file)
+		require.NoError(t, err)
+		require.Equal(t, data, got)
+	})
+
+	t.Run("Database", func(t *testing.T) {
+		t.Parallel()
+
+		cmd, _ := clitest.New(t,
+			"server", "migrate-files",
+			"--file-storage-backend", "database",
+		)
+		err := cmd.Execute()
+		require.ErrorContains(t, err, "already stored in the database")
+	})
+}
@@ -63,6 +63,16 @@ func Server(vip *viper.Viper, _ func(context.Context, *coderd.Options) (*coderd.
 			return nil
 		},
 	}
+	var migrateFilesDBURL string
+	migrateFilesCommand := &cobra.Command{
+		Use:    "migrate-files",
+		Short:  "Move the contents of files stored in the database to the configured file storage backend.",
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			serverUnsupported(cmd.ErrOrStderr())
+			return nil
+		},
+	}
 
 	// We still have to attach the flags to the commands so users don't get
 	// an error when they try to use them.
@@ -74,7 +84,10 @@ func Server(vip *viper.Viper, _ func(context.Context, *coderd.Options) (*coderd.
 	createAdminUserCommand.Flags().StringVar(&newUserEmail, "email", "", "The email of the new user. If not specified, you will be prompted via stdin. Consumes $CODER_EMAIL.")
 	createAdminUserCommand.Flags().StringVar(&newUserPassword, "password", "", "The password of the new user. If not specified, you will be prompted via stdin. Consumes $CODER_PASSWORD.")
 
-	root.AddCommand(postgresBuiltinURLCmd, postgresBuiltinServeCmd, createAdminUserCommand)
+	migrateFilesCommand.Flags().StringVar(&migrateFilesDBURL, "postgres-url", "", "URL of a PostgreSQL database. If empty, the built-in PostgreSQL deployment will be used (Coder must not be already running in this case). Consumes $CODER_POSTGRES_URL.")
+	deployment.AttachFileStorageFlags(migrateFilesCommand.Flags(), deployment.NewViper())
+
+	root.AddCommand(postgresBuiltinURLCmd, postgresBuiltinServeCmd, createAdminUserCommand, migrateFilesCommand)
 
 	deployment.AttachFlags(root.Flags(), vip, false)
 
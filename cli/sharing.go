@@ -0,0 +1,208 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func sharing() *cobra.Command {
+	cmd := &cobra.Command{
+		Annotations: workspaceCommand,
+		Use:         "sharing",
+		Short:       "Share workspaces with other users and groups",
+		Long: "Users and groups a workspace is shared with may connect to it with the \"use\" role, " +
+			"and may also start, stop and update it with the \"admin\" role.",
+		Aliases: []string{"share"},
+		Example: formatExamples(
+			example{
+				Description: "Let a user connect to your workspace",
+				Command:     "coder sharing add my-workspace --user alice",
+			},
+			example{
+				Description: "Let a group start and stop your workspace",
+				Command:     "coder sharing add my-workspace --group platform --role admin",
+			},
+			example{
+				Description: "Stop sharing your workspace with a user",
+				Command:     "coder sharing remove my-workspace --user alice",
+			},
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(
+		addSharing(),
+		listSharing(),
+		removeSharing(),
+	)
+
+	return cmd
+}
+
+func addSharing() *cobra.Command {
+	var (
+		users  []string
+		groups []string
+		role   string
+	)
+	cmd := &cobra.Command{
+		Use:   "add <workspace>",
+		Short: "Share a workspace with users or groups",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			workspaceRole := codersdk.WorkspaceRole(role)
+			if workspaceRole != codersdk.WorkspaceRoleUse && workspaceRole != codersdk.WorkspaceRoleAdmin {
+				return xerrors.Errorf("role must be %q or %q", codersdk.WorkspaceRoleUse, codersdk.WorkspaceRoleAdmin)
+			}
+			return updateSharing(cmd, args[0], users, groups, workspaceRole)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&users, "user", nil, "A username or ID to share the workspace with, can be specified multiple times.")
+	cmd.Flags().StringArrayVar(&groups, "group", nil, "A group name to share the workspace with, can be specified multiple times.")
+	cmd.Flags().StringVar(&role, "role", string(codersdk.WorkspaceRoleUse), fmt.Sprintf("The role to grant, %q or %q.", codersdk.WorkspaceRoleUse, codersdk.WorkspaceRoleAdmin))
+	return cmd
+}
+
+func removeSharing() *cobra.Command {
+	var (
+		users  []string
+		groups []string
+	)
+	cmd := &cobra.Command{
+		Use:     "remove <workspace>",
+		Aliases: []string{"rm"},
+		Short:   "Stop sharing a workspace with users or groups",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateSharing(cmd, args[0], users, groups, codersdk.WorkspaceRoleDeleted)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&users, "user", nil, "A username or ID to stop sharing the workspace with, can be specified multiple times.")
+	cmd.Flags().StringArrayVar(&groups, "group", nil, "A group name to stop sharing the workspace with, can be specified multiple times.")
+	return cmd
+}
+
+// updateSharing sets the role of the users and groups on the workspace. An
+// empty role removes them from the ACL.
+func updateSharing(cmd *cobra.Command, identifier string, users, groups []string, role codersdk.WorkspaceRole) error {
+	if len(users) == 0 && len(groups) == 0 {
+		return xerrors.New("at least one --user or --group must be specified")
+	}
+
+	client, err := CreateClient(cmd)
+	if err != nil {
+		return xerrors.Errorf("create codersdk client: %w", err)
+	}
+	workspace, err := namedWorkspace(cmd, client, identifier)
+	if err != nil {
+		return xerrors.Errorf("get workspace: %w", err)
+	}
+
+	req := codersdk.UpdateWorkspaceACL{
+		UserPerms:  map[string]codersdk.WorkspaceRole{},
+		GroupPerms: map[string]codersdk.WorkspaceRole{},
+	}
+	for _, name := range users {
+		user, err := client.User(cmd.Context(), name)
+		if err != nil {
+			return xerrors.Errorf("get user %q: %w", name, err)
+		}
+		req.UserPerms[user.ID.String()] = role
+	}
+	if len(groups) > 0 {
+		// Groups are looked up in the organization of the workspace.
+		template, err := client.Template(cmd.Context(), workspace.TemplateID)
+		if err != nil {
+			return xerrors.Errorf("get template: %w", err)
+		}
+		for _, name := range groups {
+			group, err := client.GroupByOrgAndName(cmd.Context(), template.OrganizationID, name)
+			if err != nil {
+				return xerrors.Errorf("get group %q: %w", name, err)
+			}
+			req.GroupPerms[group.ID.String()] = role
+		}
+	}
+
+	err = client.UpdateWorkspaceACL(cmd.Context(), workspace.ID, req)
+	if err != nil {
+		return xerrors.Errorf("update workspace ACL: %w", err)
+	}
+
+	names := append(append([]string{}, users...), groups...)
+	if role == codersdk.WorkspaceRoleDeleted {
+		cmd.Printf("Stopped sharing %s with %s.\n", cliui.Styles.Keyword.Render(workspace.Name), strings.Join(names, ", "))
+	} else {
+		cmd.Printf("Shared %s with %s as %s.\n", cliui.Styles.Keyword.Render(workspace.Name), strings.Join(names, ", "), role)
+	}
+	return nil
+}
+
+// sharingListRow is the type provided to the OutputFormatter.
+type sharingListRow struct {
+	Name string                 `json:"name" table:"name,default_sort"`
+	Type string                 `json:"type" table:"type"`
+	Role codersdk.WorkspaceRole `json:"role" table:"role"`
+}
+
+func listSharing() *cobra.Command {
+	formatter := cliui.NewOutputFormatter(
+		cliui.TableFormat([]sharingListRow{}, []string{"name", "type", "role"}),
+		cliui.JSONFormat(),
+	)
+	cmd := &cobra.Command{
+		Use:     "list <workspace>",
+		Aliases: []string{"ls"},
+		Short:   "List the users and groups a workspace is shared with",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create codersdk client: %w", err)
+			}
+			workspace, err := namedWorkspace(cmd, client, args[0])
+			if err != nil {
+				return xerrors.Errorf("get workspace: %w", err)
+			}
+			acl, err := client.WorkspaceACL(cmd.Context(), workspace.ID)
+			if err != nil {
+				return xerrors.Errorf("get workspace ACL: %w", err)
+			}
+
+			if len(acl.Users) == 0 && len(acl.Groups) == 0 {
+				cmd.Println(cliui.Styles.Wrap.Render(
+					fmt.Sprintf("%s is not shared with anyone.", workspace.Name),
+				))
+				return nil
+			}
+
+			rows := make([]sharingListRow, 0, len(acl.Users)+len(acl.Groups))
+			for _, user := range acl.Users {
+				rows = append(rows, sharingListRow{Name: user.Username, Type: "user", Role: user.Role})
+			}
+			for _, group := range acl.Groups {
+				rows = append(rows, sharingListRow{Name: group.Name, Type: "group", Role: group.Role})
+			}
+
+			out, err := formatter.Format(cmd.Context(), rows)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), out)
+			return err
+		},
+	}
+
+	formatter.AttachFlags(cmd)
+	return cmd
+}
@@ -0,0 +1,75 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestSharing(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+	user := coderdtest.CreateFirstUser(t, client)
+	_, member := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancelFunc()
+
+	// helpful empty response
+	cmd, root := clitest.New(t, "sharing", "ls", workspace.Name)
+	clitest.SetupConfig(t, client, root)
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	err := cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "is not shared with anyone")
+
+	cmd, root = clitest.New(t, "sharing", "add", workspace.Name, "--user", member.Username, "--role", "admin")
+	clitest.SetupConfig(t, client, root)
+	buf = new(bytes.Buffer)
+	cmd.SetOut(buf)
+	err = cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "Shared")
+
+	acl, err := client.WorkspaceACL(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.Len(t, acl.Users, 1)
+	require.Equal(t, member.ID, acl.Users[0].ID)
+	require.Equal(t, codersdk.WorkspaceRoleAdmin, acl.Users[0].Role)
+
+	cmd, root = clitest.New(t, "sharing", "ls", workspace.Name)
+	clitest.SetupConfig(t, client, root)
+	buf = new(bytes.Buffer)
+	cmd.SetOut(buf)
+	err = cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), member.Username)
+	require.Contains(t, buf.String(), "admin")
+
+	cmd, root = clitest.New(t, "sharing", "add", workspace.Name, "--user", member.Username, "--role", "owner")
+	clitest.SetupConfig(t, client, root)
+	err = cmd.ExecuteContext(ctx)
+	require.Error(t, err)
+
+	cmd, root = clitest.New(t, "sharing", "rm", workspace.Name, "--user", member.Username)
+	clitest.SetupConfig(t, client, root)
+	err = cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+
+	acl, err = client.WorkspaceACL(ctx, workspace.ID)
+	require.NoError(t, err)
+	require.Empty(t, acl.Users)
+}
@@ -83,6 +83,9 @@ func ssh() *cobra.Command {
 			if outdated && isTTYErr(cmd) {
 				_, _ = fmt.Fprintln(cmd.ErrOrStderr(), updateWorkspaceBanner)
 			}
+			if workspace.TemplateDeprecationMessage != "" && isTTYErr(cmd) {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "⚠️ The template %q of your workspace is deprecated: %s\n", workspace.TemplateName, workspace.TemplateDeprecationMessage)
+			}
 
 			// OpenSSH passes stderr directly to the calling TTY.
 			// This is required in "stdio" mode so a connecting indicator can be displayed.
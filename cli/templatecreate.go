@@ -60,10 +60,16 @@ func templateCreate() *cobra.Command {
 				return xerrors.Errorf("A template already exists named %q!", templateName)
 			}
 
-			// Confirm upload of the directory.
-			resp, err := uploadFlags.upload(cmd, client)
-			if err != nil {
-				return err
+			// Confirm upload of the directory, unless Coder creates the
+			// template from a git repository.
+			var fileID uuid.UUID
+			gitSource := uploadFlags.gitSource()
+			if gitSource == nil {
+				resp, err := uploadFlags.upload(cmd, client)
+				if err != nil {
+					return err
+				}
+				fileID = resp.ID
 			}
 
 			tags, err := ParseProvisionerTags(provisionerTags)
@@ -75,7 +81,8 @@ func templateCreate() *cobra.Command {
 				Client:          client,
 				Organization:    organization,
 				Provisioner:     database.ProvisionerType(provisioner),
-				FileID:          resp.ID,
+				FileID:          fileID,
+				GitSource:       gitSource,
 				ParameterFile:   parameterFile,
 				ProvisionerTags: tags,
 				VariablesFile:   variablesFile,
@@ -138,6 +145,7 @@ type createValidTemplateVersionArgs struct {
 	Organization  codersdk.Organization
 	Provisioner   database.ProvisionerType
 	FileID        uuid.UUID
+	GitSource     *codersdk.TemplateVersionGitSource
 	ParameterFile string
 
 	VariablesFile string
@@ -170,6 +178,7 @@ func createValidTemplateVersion(cmd *cobra.Command, args createValidTemplateVers
 		Name:               args.Name,
 		StorageMethod:      codersdk.ProvisionerStorageMethodFile,
 		FileID:             args.FileID,
+		GitSource:          args.GitSource,
 		Provisioner:        codersdk.ProvisionerType(args.Provisioner),
 		ParameterValues:    parameters,
 		ProvisionerTags:    args.ProvisionerTags,
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func templateDeprecate() *cobra.Command {
+	var message string
+
+	cmd := &cobra.Command{
+		Use:   "deprecate <template> --message <message>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Deprecate a template so no new workspaces can be created from it",
+		Long:  "Deprecate a template so no new workspaces can be created from it. Existing workspaces keep working, and show the message to their owners.",
+		Example: formatExamples(
+			example{
+				Description: "Deprecate a template in favor of another",
+				Command:     `coder templates deprecate docker --message "Use the docker-v2 template instead."`,
+			},
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if message == "" {
+				return xerrors.New("a message is required to deprecate a template, use --message")
+			}
+			return updateTemplateDeprecation(cmd, args[0], codersdk.UpdateTemplateDeprecation{
+				Deprecated: true,
+				Message:    message,
+			})
+		},
+	}
+
+	cmd.Flags().StringVarP(&message, "message", "m", "", "Tell users why the template is deprecated and what to use instead.")
+	return cmd
+}
+
+func templateUndeprecate() *cobra.Command {
+	return &cobra.Command{
+		Use:   "undeprecate <template>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Allow workspaces to be created from a deprecated template again",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return updateTemplateDeprecation(cmd, args[0], codersdk.UpdateTemplateDeprecation{
+				Deprecated: false,
+			})
+		},
+	}
+}
+
+func updateTemplateDeprecation(cmd *cobra.Command, templateName string, req codersdk.UpdateTemplateDeprecation) error {
+	client, err := CreateClient(cmd)
+	if err != nil {
+		return xerrors.Errorf("create client: %w", err)
+	}
+	organization, err := CurrentOrganization(cmd, client)
+	if err != nil {
+		return xerrors.Errorf("get current organization: %w", err)
+	}
+	template, err := client.TemplateByName(cmd.Context(), organization.ID, templateName)
+	if err != nil {
+		return xerrors.Errorf("get template by name: %w", err)
+	}
+
+	template, err = client.UpdateTemplateDeprecation(cmd.Context(), template.ID, req)
+	if err != nil {
+		return xerrors.Errorf("update template deprecation: %w", err)
+	}
+
+	action := "Deprecated"
+	if !template.Deprecated {
+		action = "Undeprecated"
+	}
+	_, _ = fmt.Fprintln(cmd.OutOrStdout(), action+" template "+cliui.Styles.Code.Render(template.Name)+" at "+cliui.Styles.DateTimeStamp.Render(time.Now().Format(time.Stamp))+"!")
+	return nil
+}
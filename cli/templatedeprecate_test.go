@@ -0,0 +1,84 @@
+package cli_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/pty/ptytest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateDeprecate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("DeprecateAndUndeprecate", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		cmd, root := clitest.New(t, "templates", "deprecate", template.Name, "--message", "Use the new template.")
+		clitest.SetupConfig(t, client, root)
+		pty := ptytest.New(t)
+		cmd.SetOut(pty.Output())
+		err := cmd.ExecuteContext(ctx)
+		require.NoError(t, err)
+		pty.ExpectMatch("Deprecated template")
+
+		updated, err := client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		require.True(t, updated.Deprecated)
+		require.Equal(t, "Use the new template.", updated.DeprecationMessage)
+
+		cmd, root = clitest.New(t, "templates", "undeprecate", template.Name)
+		clitest.SetupConfig(t, client, root)
+		err = cmd.ExecuteContext(ctx)
+		require.NoError(t, err)
+
+		updated, err = client.Template(ctx, template.ID)
+		require.NoError(t, err)
+		require.False(t, updated.Deprecated)
+	})
+
+	t.Run("RequiresMessage", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		cmd, root := clitest.New(t, "templates", "deprecate", template.Name)
+		clitest.SetupConfig(t, client, root)
+		err := cmd.Execute()
+		require.ErrorContains(t, err, "--message")
+	})
+
+	t.Run("CreateFails", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		cmd, root := clitest.New(t, "templates", "deprecate", template.Name, "--message", "Use the new template.")
+		clitest.SetupConfig(t, client, root)
+		require.NoError(t, cmd.Execute())
+
+		cmd, root = clitest.New(t, "create", "my-workspace", "--template", template.Name, "-y")
+		clitest.SetupConfig(t, client, root)
+		err := cmd.Execute()
+		require.ErrorContains(t, err, "Use the new template.")
+	})
+}
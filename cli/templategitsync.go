@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func templateGitSync() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "git-sync",
+		Short: "Keep a template in sync with a git branch",
+		Long:  "Keep a template in sync with a git branch. A template version is created for every new commit of the branch, and promoted to the active version if it imports successfully.",
+		Example: formatExamples(
+			example{
+				Description: "Sync a template with the main branch of a repository",
+				Command:     "coder templates git-sync set my-template --git-url https://github.com/coder/templates --git-branch main --git-directory docker",
+			},
+			example{
+				Description: "Show whether the last commit was synced",
+				Command:     "coder templates git-sync show my-template",
+			},
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(
+		templateGitSyncSet(),
+		templateGitSyncShow(),
+		templateGitSyncRemove(),
+	)
+
+	return cmd
+}
+
+func templateGitSyncSet() *cobra.Command {
+	var req codersdk.UpdateTemplateGitSyncRequest
+
+	cmd := &cobra.Command{
+		Use:   "set <template> --git-url <url>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Sync a template with a git branch",
+		Long:  "Sync a template with a git branch. The branch is checked for new commits periodically. To sync as soon as the branch is pushed to, add a push webhook to the repository with the printed URL and secret.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if req.URL == "" {
+				return xerrors.New("a repository is required, use --git-url")
+			}
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create client: %w", err)
+			}
+			organization, err := CurrentOrganization(cmd, client)
+			if err != nil {
+				return xerrors.Errorf("get current organization: %w", err)
+			}
+			template, err := client.TemplateByName(cmd.Context(), organization.ID, args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+
+			gitSync, err := client.UpdateTemplateGitSync(cmd.Context(), template.ID, req)
+			if err != nil {
+				return xerrors.Errorf("update template git sync: %w", err)
+			}
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Syncing template "+cliui.Styles.Code.Render(template.Name)+" with "+cliui.Styles.Code.Render(gitSync.URL)+" at "+cliui.Styles.DateTimeStamp.Render(time.Now().Format(time.Stamp))+"!")
+			_, _ = fmt.Fprintln(cmd.OutOrStdout())
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "To sync on every push, add a push webhook to the repository:")
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "  URL:    "+cliui.Styles.Code.Render(gitSync.WebhookURL))
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "  Secret: "+cliui.Styles.Code.Render(gitSync.WebhookSecret))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&req.URL, "git-url", "", "The HTTP(S) URL of the git repository.")
+	cmd.Flags().StringVar(&req.Branch, "git-branch", "", "The branch to sync with. Defaults to the default branch of the repository.")
+	cmd.Flags().StringVar(&req.Directory, "git-directory", "", "The path of the template in the repository. Defaults to the root of the repository.")
+	return cmd
+}
+
+func templateGitSyncShow() *cobra.Command {
+	formatter := cliui.NewOutputFormatter(
+		cliui.TableFormat([]templateGitSyncRow{}, nil),
+		cliui.JSONFormat(),
+	)
+
+	cmd := &cobra.Command{
+		Use:   "show <template>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Show the git sync of a template",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create client: %w", err)
+			}
+			organization, err := CurrentOrganization(cmd, client)
+			if err != nil {
+				return xerrors.Errorf("get current organization: %w", err)
+			}
+			template, err := client.TemplateByName(cmd.Context(), organization.ID, args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+
+			gitSync, err := client.TemplateGitSync(cmd.Context(), template.ID)
+			if err != nil {
+				return xerrors.Errorf("get template git sync: %w", err)
+			}
+			row := templateGitSyncRow{
+				TemplateGitSync: gitSync,
+				URL:             gitSync.URL,
+				Branch:          gitSync.Branch,
+				Directory:       gitSync.Directory,
+				CommitSHA:       gitSync.CommitSHA,
+				Status:          string(gitSync.Status),
+				Error:           gitSync.Error,
+			}
+			if gitSync.CheckedAt != nil {
+				row.CheckedAt = gitSync.CheckedAt.Format(time.Stamp)
+			}
+			out, err := formatter.Format(cmd.Context(), []templateGitSyncRow{row})
+			if err != nil {
+				return xerrors.Errorf("render table: %w", err)
+			}
+
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), out)
+			return err
+		},
+	}
+
+	formatter.AttachFlags(cmd)
+	return cmd
+}
+
+type templateGitSyncRow struct {
+	// For json format:
+	TemplateGitSync codersdk.TemplateGitSync `table:"-"`
+
+	// For table format:
+	URL       string `json:"-" table:"url,default_sort"`
+	Branch    string `json:"-" table:"branch"`
+	Directory string `json:"-" table:"directory"`
+	CommitSHA string `json:"-" table:"commit"`
+	Status    string `json:"-" table:"status"`
+	CheckedAt string `json:"-" table:"checked at"`
+	Error     string `json:"-" table:"error"`
+}
+
+func templateGitSyncRemove() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <template>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Stop syncing a template with git",
+		Long:  "Stop syncing a template with git. The versions that were already created are kept.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create client: %w", err)
+			}
+			organization, err := CurrentOrganization(cmd, client)
+			if err != nil {
+				return xerrors.Errorf("get current organization: %w", err)
+			}
+			template, err := client.TemplateByName(cmd.Context(), organization.ID, args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+
+			err = client.DeleteTemplateGitSync(cmd.Context(), template.ID)
+			if err != nil {
+				return xerrors.Errorf("delete template git sync: %w", err)
+			}
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Stopped syncing template "+cliui.Styles.Code.Render(template.Name)+" with git at "+cliui.Styles.DateTimeStamp.Render(time.Now().Format(time.Stamp))+"!")
+			return nil
+		},
+	}
+}
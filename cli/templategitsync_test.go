@@ -0,0 +1,67 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/gitsource/gitsourcetest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/pty/ptytest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateGitSync(t *testing.T) {
+	t.Parallel()
+
+	repo, _ := gitsourcetest.ServeRepo(t, gitsourcetest.EchoFiles(t, "docker", nil))
+	client := coderdtest.New(t, &coderdtest.Options{
+		IncludeProvisionerDaemon: true,
+		TemplateGitSyncInterval:  testutil.IntervalFast,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	cmd, root := clitest.New(t, "templates", "git-sync", "set", template.Name, "--git-url", repo.URL, "--git-branch", "main", "--git-directory", "docker")
+	clitest.SetupConfig(t, client, root)
+	pty := ptytest.New(t)
+	cmd.SetOut(pty.Output())
+	err := cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+	pty.ExpectMatch("Syncing template")
+	pty.ExpectMatch("/git-sync/webhook")
+
+	require.Eventually(t, func() bool {
+		gitSync, err := client.TemplateGitSync(ctx, template.ID)
+		return err == nil && gitSync.Status == codersdk.TemplateGitSyncStatusSucceeded
+	}, testutil.WaitLong, testutil.IntervalFast)
+
+	cmd, root = clitest.New(t, "templates", "git-sync", "show", template.Name)
+	clitest.SetupConfig(t, client, root)
+	out := bytes.NewBuffer(nil)
+	cmd.SetOut(out)
+	err = cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+	require.Contains(t, out.String(), repo.URL)
+	require.Contains(t, out.String(), string(codersdk.TemplateGitSyncStatusSucceeded))
+
+	cmd, root = clitest.New(t, "templates", "git-sync", "remove", template.Name)
+	clitest.SetupConfig(t, client, root)
+	err = cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+
+	_, err = client.TemplateGitSync(ctx, template.ID)
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+}
@@ -0,0 +1,187 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+const insightsDateFormat = "2006-01-02"
+
+func templateInsights() *cobra.Command {
+	var (
+		startDate string
+		endDate   string
+		formatter = cliui.NewOutputFormatter(
+			&templateInsightsFormat{},
+			cliui.JSONFormat(),
+		)
+	)
+
+	cmd := &cobra.Command{
+		Use:   "insights <template>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Show build times, failure rates and usage of a template",
+		Long:  "Show build times, failure rates and usage of a template. Insights cover at most the last 30 days, and are refreshed in the background.",
+		Example: formatExamples(
+			example{
+				Description: "Show the insights of a template for the last 30 days",
+				Command:     "coder templates insights my-template",
+			},
+			example{
+				Description: "Show the insights of a template for the first week of March",
+				Command:     "coder templates insights my-template --start-date 2023-03-01 --end-date 2023-03-07",
+			},
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var req codersdk.TemplateInsightsRequest
+			if startDate != "" {
+				start, err := time.Parse(insightsDateFormat, startDate)
+				if err != nil {
+					return xerrors.Errorf("parse start date: %w", err)
+				}
+				req.StartTime = start
+			}
+			if endDate != "" {
+				end, err := time.Parse(insightsDateFormat, endDate)
+				if err != nil {
+					return xerrors.Errorf("parse end date: %w", err)
+				}
+				// The end date is included in the range.
+				req.EndTime = end.Add(24 * time.Hour)
+			}
+
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create client: %w", err)
+			}
+			organization, err := CurrentOrganization(cmd, client)
+			if err != nil {
+				return xerrors.Errorf("get current organization: %w", err)
+			}
+			template, err := client.TemplateByName(cmd.Context(), organization.ID, args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+
+			insights, err := client.TemplateInsights(cmd.Context(), template.ID, req)
+			if err != nil {
+				return xerrors.Errorf("get template insights: %w", err)
+			}
+
+			out, err := formatter.Format(cmd.Context(), insights)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), out)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&startDate, "start-date", "", "The first day to show insights for, as YYYY-MM-DD. Defaults to 30 days ago.")
+	cmd.Flags().StringVar(&endDate, "end-date", "", "The last day to show insights for, as YYYY-MM-DD. Defaults to today.")
+	formatter.AttachFlags(cmd)
+	return cmd
+}
+
+type templateInsightsFormat struct{}
+
+var _ cliui.OutputFormat = &templateInsightsFormat{}
+
+// ID implements OutputFormat.
+func (*templateInsightsFormat) ID() string {
+	return "table"
+}
+
+// AttachFlags implements OutputFormat.
+func (*templateInsightsFormat) AttachFlags(_ *cobra.Command) {}
+
+// Format implements OutputFormat.
+func (*templateInsightsFormat) Format(_ context.Context, out interface{}) (string, error) {
+	insights, ok := out.(codersdk.TemplateInsightsResponse)
+	if !ok {
+		return "", xerrors.Errorf("expected type %T, got %T", insights, out)
+	}
+
+	tw := cliui.Table()
+	addSection := func(name string, values []string) {
+		if len(values) == 0 {
+			values = []string{"(none)"}
+		}
+		for i, value := range values {
+			key := ""
+			if i == 0 && name != "" {
+				key = name + ":"
+			}
+			tw.AppendRow(table.Row{key, value})
+		}
+		tw.AppendRow(table.Row{"", ""})
+	}
+
+	// The end of the range is exclusive, so the last day shown is the one
+	// before it.
+	addSection("Range", []string{fmt.Sprintf("%s to %s",
+		insights.StartTime.Format(insightsDateFormat),
+		insights.EndTime.Add(-24*time.Hour).Format(insightsDateFormat),
+	)})
+
+	builds := make([]string, 0, len(insights.Builds))
+	for _, build := range insights.Builds {
+		builds = append(builds, fmt.Sprintf("%s: %d builds, %d failed (%.1f%%), p50 %s, p95 %s",
+			build.Transition, build.Total, build.Failed, build.FailureRate*100,
+			formatBuildTime(build.P50), formatBuildTime(build.P95),
+		))
+	}
+	addSection("Builds", builds)
+
+	connections := make([]string, 0, len(insights.ConnectionTypes))
+	for _, conn := range insights.ConnectionTypes {
+		connections = append(connections, fmt.Sprintf("%s: %d", conn.Type, conn.Connections))
+	}
+	addSection("Connections", connections)
+
+	apps := make([]string, 0, len(insights.Apps))
+	for _, app := range insights.Apps {
+		apps = append(apps, fmt.Sprintf("%s: %d", app.DisplayName, app.Connections))
+	}
+	addSection("Apps", apps)
+
+	var peak, total int
+	for _, entry := range insights.ActiveWorkspaces {
+		total += entry.Amount
+		if entry.Amount > peak {
+			peak = entry.Amount
+		}
+	}
+	average := 0.0
+	if len(insights.ActiveWorkspaces) > 0 {
+		average = float64(total) / float64(len(insights.ActiveWorkspaces))
+	}
+	addSection("Active workspaces", []string{fmt.Sprintf("%.1f per day on average, %d at most", average, peak)})
+
+	versions := make([]string, 0, len(insights.VersionAdoption))
+	for _, version := range insights.VersionAdoption {
+		name := version.Name
+		if version.Active {
+			name += " (active)"
+		}
+		versions = append(versions, fmt.Sprintf("%s: %d workspaces", name, version.Workspaces))
+	}
+	addSection("Versions", versions)
+
+	return tw.Render(), nil
+}
+
+func formatBuildTime(ms *int64) string {
+	if ms == nil {
+		return "-"
+	}
+	return (time.Duration(*ms) * time.Millisecond).Round(time.Second).String()
+}
@@ -0,0 +1,90 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/pty/ptytest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateInsights(t *testing.T) {
+	t.Parallel()
+
+	client := coderdtest.New(t, &coderdtest.Options{
+		IncludeProvisionerDaemon:    true,
+		MetricsCacheRefreshInterval: time.Millisecond * 100,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	// Wait for the build to be aggregated.
+	require.Eventually(t, func() bool {
+		insights, err := client.TemplateInsights(ctx, template.ID, codersdk.TemplateInsightsRequest{})
+		require.NoError(t, err)
+		return len(insights.Builds) > 0 && insights.Builds[0].Total > 0
+	}, testutil.WaitShort, testutil.IntervalFast)
+
+	t.Run("Table", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		cmd, root := clitest.New(t, "templates", "insights", template.Name)
+		clitest.SetupConfig(t, client, root)
+		pty := ptytest.New(t)
+		cmd.SetOut(pty.Output())
+		err := cmd.ExecuteContext(ctx)
+		require.NoError(t, err)
+		pty.ExpectMatch("start: 1 builds, 0 failed")
+		pty.ExpectMatch(version.Name + " (active): 1 workspaces")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		today := time.Now().UTC().Format("2006-01-02")
+		cmd, root := clitest.New(t, "templates", "insights", template.Name, "--start-date", today, "--end-date", today, "-o", "json")
+		clitest.SetupConfig(t, client, root)
+		out := bytes.NewBuffer(nil)
+		cmd.SetOut(out)
+		err := cmd.ExecuteContext(ctx)
+		require.NoError(t, err)
+
+		var insights codersdk.TemplateInsightsResponse
+		require.NoError(t, json.Unmarshal(out.Bytes(), &insights))
+		require.Len(t, insights.ActiveWorkspaces, 1)
+		require.EqualValues(t, 1, insights.Builds[0].Total)
+	})
+
+	t.Run("InvalidDate", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		cmd, root := clitest.New(t, "templates", "insights", template.Name, "--start-date", "yesterday")
+		clitest.SetupConfig(t, client, root)
+		err := cmd.ExecuteContext(ctx)
+		require.ErrorContains(t, err, "parse start date")
+	})
+}
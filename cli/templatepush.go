@@ -4,11 +4,15 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"golang.org/x/xerrors"
@@ -22,11 +26,31 @@ import (
 // templateUploadFlags is shared by `templates create` and `templates push`.
 type templateUploadFlags struct {
 	directory string
+
+	gitURL       string
+	gitRef       string
+	gitDirectory string
 }
 
 func (pf *templateUploadFlags) register(f *pflag.FlagSet) {
 	currentDirectory, _ := os.Getwd()
 	f.StringVarP(&pf.directory, "directory", "d", currentDirectory, "Specify the directory to create from, use '-' to read tar from stdin")
+	f.StringVar(&pf.gitURL, "git-url", "", "Specify an HTTP(S) git repository for Coder to create from instead of uploading a directory.")
+	f.StringVar(&pf.gitRef, "git-ref", "", "Specify the branch, tag or commit of the git repository. Defaults to the default branch.")
+	f.StringVar(&pf.gitDirectory, "git-directory", "", "Specify the directory of the template in the git repository. Defaults to the root.")
+}
+
+// gitSource returns the git repository to create from, or nil if a
+// directory should be uploaded instead.
+func (pf *templateUploadFlags) gitSource() *codersdk.TemplateVersionGitSource {
+	if pf.gitURL == "" {
+		return nil
+	}
+	return &codersdk.TemplateVersionGitSource{
+		URL:       pf.gitURL,
+		Ref:       pf.gitRef,
+		Directory: pf.gitDirectory,
+	}
 }
 
 func (pf *templateUploadFlags) stdin() bool {
@@ -71,6 +95,22 @@ func (pf *templateUploadFlags) upload(cmd *cobra.Command, client *codersdk.Clien
 }
 
 func (pf *templateUploadFlags) templateName(args []string) (string, error) {
+	if len(args) == 0 && pf.gitURL != "" {
+		// Infer the name from the template directory, or the repository if
+		// the template is at its root.
+		name := path.Base(strings.Trim(pf.gitDirectory, "/"))
+		if name == "." || name == "/" {
+			u, err := url.Parse(pf.gitURL)
+			if err != nil {
+				return "", xerrors.Errorf("parse git url: %w", err)
+			}
+			name = strings.TrimSuffix(path.Base(u.Path), ".git")
+		}
+		if name == "" || name == "." || name == "/" {
+			return "", xerrors.New("template name argument must be provided")
+		}
+		return name, nil
+	}
 	if pf.stdin() {
 		// Can't infer name from directory if none provided.
 		if len(args) == 0 {
@@ -122,9 +162,14 @@ func templatePush() *cobra.Command {
 				return err
 			}
 
-			resp, err := uploadFlags.upload(cmd, client)
-			if err != nil {
-				return err
+			var fileID uuid.UUID
+			gitSource := uploadFlags.gitSource()
+			if gitSource == nil {
+				resp, err := uploadFlags.upload(cmd, client)
+				if err != nil {
+					return err
+				}
+				fileID = resp.ID
 			}
 
 			tags, err := ParseProvisionerTags(provisionerTags)
@@ -137,7 +182,8 @@ func templatePush() *cobra.Command {
 				Client:          client,
 				Organization:    organization,
 				Provisioner:     database.ProvisionerType(provisioner),
-				FileID:          resp.ID,
+				FileID:          fileID,
+				GitSource:       gitSource,
 				ParameterFile:   parameterFile,
 				VariablesFile:   variablesFile,
 				Variables:       variables,
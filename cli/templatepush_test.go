@@ -14,6 +14,7 @@ import (
 	"github.com/coder/coder/cli/clitest"
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/gitsource/gitsourcetest"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/provisioner/echo"
 	"github.com/coder/coder/provisionersdk/proto"
@@ -253,6 +254,47 @@ func TestTemplatePush(t *testing.T) {
 		assert.NotEqual(t, template.ActiveVersionID, templateVersions[1].ID)
 	})
 
+	t.Run("GitSource", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+
+		repo, sha := gitsourcetest.ServeRepo(t, gitsourcetest.EchoFiles(t, "docker", &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionApply: echo.ProvisionComplete,
+		}))
+
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID,
+			func(r *codersdk.CreateTemplateRequest) {
+				r.Name = "docker"
+			})
+
+		// Don't pass the name of the template, it should use the
+		// directory in the repository.
+		cmd, root := clitest.New(t, "templates", "push",
+			"--git-url", repo.URL, "--git-ref", "main", "--git-directory", "docker",
+			"--test.provisioner", string(database.ProvisionerTypeEcho),
+		)
+		clitest.SetupConfig(t, client, root)
+		pty := ptytest.New(t)
+		cmd.SetIn(pty.Input())
+		cmd.SetOut(pty.Output())
+
+		// Nothing is uploaded, so there's nothing to confirm.
+		err := cmd.Execute()
+		require.NoError(t, err)
+
+		template, err = client.Template(context.Background(), template.ID)
+		require.NoError(t, err)
+		activeVersion, err := client.TemplateVersion(context.Background(), template.ActiveVersionID)
+		require.NoError(t, err)
+		require.NotEqual(t, version.ID, activeVersion.ID)
+		require.NotNil(t, activeVersion.GitSource)
+		require.Equal(t, sha, activeVersion.GitSource.CommitSHA)
+	})
+
 	t.Run("Variables", func(t *testing.T) {
 		t.Parallel()
 
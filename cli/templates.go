@@ -36,14 +36,18 @@ func templates() *cobra.Command {
 	}
 	cmd.AddCommand(
 		templateCreate(),
+		templateDeprecate(),
 		templateEdit(),
+		templateGitSync(),
 		templateInit(),
+		templateInsights(),
 		templateList(),
 		templatePlan(),
 		templatePush(),
 		templateVersions(),
 		templateDelete(),
 		templatePull(),
+		templateUndeprecate(),
 	)
 
 	return cmd
@@ -62,6 +66,7 @@ type templateTableRow struct {
 	ActiveVersionID uuid.UUID                `json:"-" table:"active version id"`
 	UsedBy          string                   `json:"-" table:"used by"`
 	DefaultTTL      time.Duration            `json:"-" table:"default ttl"`
+	Deprecated      string                   `json:"-" table:"deprecated"`
 }
 
 // templateToRows converts a list of templates to a list of templateTableRow for
@@ -79,6 +84,7 @@ func templatesToRows(templates ...codersdk.Template) []templateTableRow {
 			ActiveVersionID: template.ActiveVersionID,
 			UsedBy:          cliui.Styles.Fuchsia.Render(formatActiveDevelopers(template.ActiveUserCount)),
 			DefaultTTL:      (time.Duration(template.DefaultTTLMillis) * time.Millisecond),
+			Deprecated:      template.DeprecationMessage,
 		}
 	}
 
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliui"
+)
+
+func templateVersionsArchive() *cobra.Command {
+	var unused bool
+
+	cmd := &cobra.Command{
+		Use:   "archive <template> [versions...]",
+		Args:  cobra.MinimumNArgs(1),
+		Short: "Archive template versions so they can't be used for new workspace builds",
+		Long:  "Archive template versions so they can't be used for new workspace builds. The active version, versions used by the latest build of a workspace, and versions that are still importing can't be archived. Archived versions are hidden from \"coder templates versions list\" unless --include-archived is passed.",
+		Example: formatExamples(
+			example{
+				Description: "Archive specific versions of a template",
+				Command:     "coder templates versions archive my-template version-1 version-2",
+			},
+			example{
+				Description: "Archive every version of a template that no workspace uses",
+				Command:     "coder templates versions archive my-template --unused",
+			},
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			versionNames := args[1:]
+			if unused && len(versionNames) > 0 {
+				return xerrors.New("specify either versions or --unused, not both")
+			}
+			if !unused && len(versionNames) == 0 {
+				return xerrors.New("specify the versions to archive, or --unused")
+			}
+
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create client: %w", err)
+			}
+			organization, err := CurrentOrganization(cmd, client)
+			if err != nil {
+				return xerrors.Errorf("get current organization: %w", err)
+			}
+			template, err := client.TemplateByName(cmd.Context(), organization.ID, args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+
+			if unused {
+				res, err := client.ArchiveUnusedTemplateVersions(cmd.Context(), template.ID)
+				if err != nil {
+					return xerrors.Errorf("archive unused template versions: %w", err)
+				}
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Archived %d unused versions of template %s at %s!\n",
+					len(res.ArchivedIDs), cliui.Styles.Code.Render(template.Name), cliui.Styles.DateTimeStamp.Render(time.Now().Format(time.Stamp)))
+				return nil
+			}
+
+			for _, versionName := range versionNames {
+				version, err := client.TemplateVersionByName(cmd.Context(), template.ID, versionName)
+				if err != nil {
+					return xerrors.Errorf("get template version %q: %w", versionName, err)
+				}
+				err = client.ArchiveTemplateVersion(cmd.Context(), version.ID)
+				if err != nil {
+					return xerrors.Errorf("archive template version %q: %w", versionName, err)
+				}
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Archived version "+cliui.Styles.Code.Render(version.Name)+" of template "+cliui.Styles.Code.Render(template.Name)+" at "+cliui.Styles.DateTimeStamp.Render(time.Now().Format(time.Stamp))+"!")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&unused, "unused", false, "Archive every version that isn't the active version, and isn't used by the latest build of a workspace.")
+	return cmd
+}
+
+func templateVersionsUnarchive() *cobra.Command {
+	return &cobra.Command{
+		Use:   "unarchive <template> <version>",
+		Args:  cobra.ExactArgs(2),
+		Short: "Allow an archived template version to be used for workspace builds again",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create client: %w", err)
+			}
+			organization, err := CurrentOrganization(cmd, client)
+			if err != nil {
+				return xerrors.Errorf("get current organization: %w", err)
+			}
+			template, err := client.TemplateByName(cmd.Context(), organization.ID, args[0])
+			if err != nil {
+				return xerrors.Errorf("get template by name: %w", err)
+			}
+			version, err := client.TemplateVersionByName(cmd.Context(), template.ID, args[1])
+			if err != nil {
+				return xerrors.Errorf("get template version: %w", err)
+			}
+
+			err = client.UnarchiveTemplateVersion(cmd.Context(), version.ID)
+			if err != nil {
+				return xerrors.Errorf("unarchive template version: %w", err)
+			}
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Unarchived version "+cliui.Styles.Code.Render(version.Name)+" of template "+cliui.Styles.Code.Render(template.Name)+" at "+cliui.Styles.DateTimeStamp.Render(time.Now().Format(time.Stamp))+"!")
+			return nil
+		},
+	}
+}
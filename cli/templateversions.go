@@ -23,6 +23,10 @@ func templateVersions() *cobra.Command {
 				Description: "List versions of a specific template",
 				Command:     "coder templates versions list my-template",
 			},
+			example{
+				Description: "Archive every version of a template that no workspace uses",
+				Command:     "coder templates versions archive my-template --unused",
+			},
 		),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return cmd.Help()
@@ -30,12 +34,15 @@ func templateVersions() *cobra.Command {
 	}
 	cmd.AddCommand(
 		templateVersionsList(),
+		templateVersionsArchive(),
+		templateVersionsUnarchive(),
 	)
 
 	return cmd
 }
 
 func templateVersionsList() *cobra.Command {
+	var includeArchived bool
 	formatter := cliui.NewOutputFormatter(
 		cliui.TableFormat([]templateVersionRow{}, nil),
 		cliui.JSONFormat(),
@@ -59,7 +66,8 @@ func templateVersionsList() *cobra.Command {
 				return xerrors.Errorf("get template by name: %w", err)
 			}
 			req := codersdk.TemplateVersionsByTemplateRequest{
-				TemplateID: template.ID,
+				TemplateID:      template.ID,
+				IncludeArchived: includeArchived,
 			}
 
 			versions, err := client.TemplateVersionsByTemplate(cmd.Context(), req)
@@ -78,6 +86,7 @@ func templateVersionsList() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVar(&includeArchived, "include-archived", false, "Include archived versions in the list.")
 	formatter.AttachFlags(cmd)
 	return cmd
 }
@@ -92,6 +101,7 @@ type templateVersionRow struct {
 	CreatedBy string    `json:"-" table:"created by"`
 	Status    string    `json:"-" table:"status"`
 	Active    string    `json:"-" table:"active"`
+	Archived  string    `json:"-" table:"archived"`
 }
 
 // templateVersionsToRows converts a list of template versions to a list of rows
@@ -103,6 +113,10 @@ func templateVersionsToRows(activeVersionID uuid.UUID, templateVersions ...coder
 		if templateVersion.ID == activeVersionID {
 			activeStatus = cliui.Styles.Code.Render(cliui.Styles.Keyword.Render("Active"))
 		}
+		archivedStatus := ""
+		if templateVersion.Archived {
+			archivedStatus = cliui.Styles.Warn.Render("Archived")
+		}
 
 		rows[i] = templateVersionRow{
 			Name:      templateVersion.Name,
@@ -110,6 +124,7 @@ func templateVersionsToRows(activeVersionID uuid.UUID, templateVersions ...coder
 			CreatedBy: templateVersion.CreatedBy.Username,
 			Status:    strings.Title(string(templateVersion.Job.Status)),
 			Active:    activeStatus,
+			Archived:  archivedStatus,
 		}
 	}
 
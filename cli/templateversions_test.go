@@ -1,13 +1,16 @@
 package cli_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/cli/clitest"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/pty/ptytest"
+	"github.com/coder/coder/testutil"
 )
 
 func TestTemplateVersions(t *testing.T) {
@@ -39,3 +42,87 @@ func TestTemplateVersions(t *testing.T) {
 		pty.ExpectMatch("Active")
 	})
 }
+
+func TestTemplateVersionsArchive(t *testing.T) {
+	t.Parallel()
+	t.Run("ArchiveAndUnarchive", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		unused := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, unused.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		cmd, root := clitest.New(t, "templates", "versions", "archive", template.Name, unused.Name)
+		clitest.SetupConfig(t, client, root)
+		pty := ptytest.New(t)
+		cmd.SetOut(pty.Output())
+		require.NoError(t, cmd.ExecuteContext(ctx))
+		pty.ExpectMatch("Archived version")
+
+		archived, err := client.TemplateVersion(ctx, unused.ID)
+		require.NoError(t, err)
+		require.True(t, archived.Archived)
+
+		cmd, root = clitest.New(t, "templates", "versions", "list", template.Name, "--include-archived")
+		clitest.SetupConfig(t, client, root)
+		pty = ptytest.New(t)
+		cmd.SetOut(pty.Output())
+		require.NoError(t, cmd.ExecuteContext(ctx))
+		pty.ExpectMatch("Archived")
+
+		cmd, root = clitest.New(t, "templates", "versions", "unarchive", template.Name, unused.Name)
+		clitest.SetupConfig(t, client, root)
+		require.NoError(t, cmd.ExecuteContext(ctx))
+
+		unarchived, err := client.TemplateVersion(ctx, unused.ID)
+		require.NoError(t, err)
+		require.False(t, unarchived.Archived)
+	})
+
+	t.Run("Unused", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		unused := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		_ = coderdtest.AwaitTemplateVersionJob(t, client, unused.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		cmd, root := clitest.New(t, "templates", "versions", "archive", template.Name, "--unused")
+		clitest.SetupConfig(t, client, root)
+		pty := ptytest.New(t)
+		cmd.SetOut(pty.Output())
+		require.NoError(t, cmd.ExecuteContext(ctx))
+		pty.ExpectMatch("Archived 1 unused versions")
+
+		versions, err := client.TemplateVersionsByTemplate(ctx, codersdk.TemplateVersionsByTemplateRequest{
+			TemplateID: template.ID,
+		})
+		require.NoError(t, err)
+		require.Len(t, versions, 1)
+		require.Equal(t, version.ID, versions[0].ID)
+	})
+
+	t.Run("RequiresVersionsOrUnused", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		cmd, root := clitest.New(t, "templates", "versions", "archive", template.Name)
+		clitest.SetupConfig(t, client, root)
+		err := cmd.Execute()
+		require.ErrorContains(t, err, "--unused")
+	})
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func transfer() *cobra.Command {
+	var name string
+	cmd := &cobra.Command{
+		Annotations: workspaceCommand,
+		Use:         "transfer <workspace> <new-owner>",
+		Short:       "Transfer a workspace to another user",
+		Long: "The workspace keeps its resources and is started for the new owner. " +
+			"It is no longer shared with anyone, and gets a numeric suffix if the new owner " +
+			"already has a workspace with its name.",
+		Args: cobra.ExactArgs(2),
+		Example: formatExamples(
+			example{
+				Description: "Give a workspace of a user who left to a colleague",
+				Command:     "coder transfer alice/dev bob",
+			},
+			example{
+				Description: "Transfer a workspace and rename it",
+				Command:     "coder transfer alice/dev bob --name alice-dev",
+			},
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create codersdk client: %w", err)
+			}
+			workspace, err := namedWorkspace(cmd, client, args[0])
+			if err != nil {
+				return xerrors.Errorf("get workspace: %w", err)
+			}
+
+			_, err = cliui.Prompt(cmd, cliui.PromptOptions{
+				Text:      fmt.Sprintf("Transfer %s/%s to %s?", workspace.OwnerName, workspace.Name, args[1]),
+				IsConfirm: true,
+				Default:   cliui.ConfirmNo,
+			})
+			if err != nil {
+				return err
+			}
+
+			workspace, err = client.TransferWorkspace(cmd.Context(), workspace.ID, codersdk.TransferWorkspaceRequest{
+				Owner: args[1],
+				Name:  name,
+			})
+			if err != nil {
+				return xerrors.Errorf("transfer workspace: %w", err)
+			}
+
+			err = cliui.WorkspaceBuild(cmd.Context(), cmd.OutOrStdout(), client, workspace.LatestBuild.ID)
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "\nThe workspace has been transferred to %s!\n",
+				cliui.Styles.Keyword.Render(workspace.OwnerName+"/"+workspace.Name))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "Rename the workspace for the new owner.")
+	cliui.AllowSkipPrompt(cmd)
+	return cmd
+}
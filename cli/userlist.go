@@ -19,15 +19,25 @@ func userList() *cobra.Command {
 		cliui.JSONFormat(),
 	)
 
+	var searchQuery string
+
 	cmd := &cobra.Command{
 		Use:     "list",
 		Aliases: []string{"ls"},
+		Example: formatExamples(
+			example{
+				Description: "List the users that logged in with OIDC and have not been seen this year",
+				Command:     `coder users list --search "login_type:oidc last_seen_before:2023-01-01"`,
+			},
+		),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			client, err := CreateClient(cmd)
 			if err != nil {
 				return err
 			}
-			res, err := client.Users(cmd.Context(), codersdk.UsersRequest{})
+			res, err := client.Users(cmd.Context(), codersdk.UsersRequest{
+				SearchQuery: searchQuery,
+			})
 			if err != nil {
 				return err
 			}
@@ -42,6 +52,7 @@ func userList() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().StringVar(&searchQuery, "search", "", "Search for users with a query.")
 	formatter.AttachFlags(cmd)
 	return cmd
 }
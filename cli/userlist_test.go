@@ -58,6 +58,26 @@ func TestUserList(t *testing.T) {
 		require.Len(t, users, 1)
 		require.Contains(t, users[0].Email, "coder.com")
 	})
+	t.Run("Search", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		first := coderdtest.CreateFirstUser(t, client)
+		_, other := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+		cmd, root := clitest.New(t, "users", "list", "-o", "json", "--search", "login_type:password group:everyone organization:"+first.OrganizationID.String()+" "+other.Username)
+		clitest.SetupConfig(t, client, root)
+
+		buf := bytes.NewBuffer(nil)
+		cmd.SetOut(buf)
+		err := cmd.Execute()
+		require.NoError(t, err)
+
+		var users []codersdk.User
+		err = json.Unmarshal(buf.Bytes(), &users)
+		require.NoError(t, err, "unmarshal JSON output")
+		require.Len(t, users, 1)
+		require.Equal(t, other.ID, users[0].ID)
+	})
 	t.Run("NoURLFileErrorHasHelperText", func(t *testing.T) {
 		t.Parallel()
 
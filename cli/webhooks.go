@@ -0,0 +1,370 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func webhooks() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "webhooks",
+		Short:   "Manage outbound webhooks",
+		Long:    "Webhooks send signed HTTP requests when workspaces, builds, templates or users change.",
+		Aliases: []string{"webhook"},
+		Example: formatExamples(
+			example{
+				Description: "Notify a URL when a workspace build fails",
+				Command:     "coder webhooks create builds --endpoint https://example.com/hook --event build.failed",
+			},
+			example{
+				Description: "List the webhooks of your organization",
+				Command:     "coder webhooks ls --org",
+			},
+			example{
+				Description: "Show the recent deliveries of a webhook",
+				Command:     "coder webhooks deliveries 5a7cbdcf-07b6-4e3d-8b0e-0a4c6e4e4b3a",
+			},
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(
+		createWebhook(),
+		listWebhooks(),
+		editWebhook(),
+		deleteWebhook(),
+		webhookDeliveries(),
+	)
+
+	return cmd
+}
+
+func createWebhook() *cobra.Command {
+	var (
+		endpoint string
+		events   []string
+		secret   string
+		org      bool
+	)
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a webhook",
+		Long: "Create a webhook. Deliveries are signed with the webhook secret, " +
+			"a random secret is generated and printed if --secret is omitted.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create codersdk client: %w", err)
+			}
+
+			req := codersdk.CreateWebhookRequest{
+				Name:   args[0],
+				URL:    endpoint,
+				Events: webhookEvents(events),
+				Secret: secret,
+			}
+			var webhook codersdk.Webhook
+			if org {
+				organization, err := CurrentOrganization(cmd, client)
+				if err != nil {
+					return xerrors.Errorf("get current organization: %w", err)
+				}
+				webhook, err = client.CreateOrganizationWebhook(cmd.Context(), organization.ID, req)
+				if err != nil {
+					return xerrors.Errorf("create webhook: %w", err)
+				}
+			} else {
+				webhook, err = client.CreateWebhook(cmd.Context(), req)
+				if err != nil {
+					return xerrors.Errorf("create webhook: %w", err)
+				}
+			}
+
+			cmd.Printf("Created webhook %s (%s).\n", cliui.Styles.Keyword.Render(webhook.Name), webhook.ID)
+			if secret == "" {
+				cmd.Println()
+				cmd.Println(cliui.Styles.Wrap.Render(
+					fmt.Sprintf("Deliveries are signed with this secret in the %q header. It will not be shown again.", codersdk.WebhookSignatureHeader),
+				))
+				cmd.Println()
+				cmd.Println(cliui.Styles.Code.Render(webhook.Secret))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&endpoint, "endpoint", "", "The URL events are sent to.")
+	cmd.Flags().StringArrayVarP(&events, "event", "e", nil,
+		fmt.Sprintf("An event to subscribe to, can be specified multiple times. Available events: %s.", joinWebhookEvents()))
+	cmd.Flags().StringVar(&secret, "secret", "", "The secret deliveries are signed with.")
+	cmd.Flags().BoolVar(&org, "org", false, "Only receive events from your current organization.")
+	_ = cmd.MarkFlagRequired("endpoint")
+	_ = cmd.MarkFlagRequired("event")
+	return cmd
+}
+
+// webhookListRow is the type provided to the OutputFormatter.
+type webhookListRow struct {
+	// For JSON format:
+	codersdk.Webhook `table:"-"`
+
+	// For table format:
+	ID      string `json:"-" table:"id"`
+	Name    string `json:"-" table:"name,default_sort"`
+	URL     string `json:"-" table:"url"`
+	Events  string `json:"-" table:"events"`
+	Enabled bool   `json:"-" table:"enabled"`
+}
+
+func listWebhooks() *cobra.Command {
+	var (
+		org       bool
+		formatter = cliui.NewOutputFormatter(
+			cliui.TableFormat([]webhookListRow{}, []string{"id", "name", "url", "events", "enabled"}),
+			cliui.JSONFormat(),
+		)
+	)
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List webhooks",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create codersdk client: %w", err)
+			}
+
+			var webhooks []codersdk.Webhook
+			if org {
+				organization, err := CurrentOrganization(cmd, client)
+				if err != nil {
+					return xerrors.Errorf("get current organization: %w", err)
+				}
+				webhooks, err = client.OrganizationWebhooks(cmd.Context(), organization.ID)
+				if err != nil {
+					return xerrors.Errorf("list webhooks: %w", err)
+				}
+			} else {
+				webhooks, err = client.Webhooks(cmd.Context())
+				if err != nil {
+					return xerrors.Errorf("list webhooks: %w", err)
+				}
+			}
+
+			if len(webhooks) == 0 {
+				cmd.Println(cliui.Styles.Wrap.Render(
+					"No webhooks found.",
+				))
+				return nil
+			}
+
+			rows := make([]webhookListRow, 0, len(webhooks))
+			for _, webhook := range webhooks {
+				events := make([]string, 0, len(webhook.Events))
+				for _, event := range webhook.Events {
+					events = append(events, string(event))
+				}
+				rows = append(rows, webhookListRow{
+					Webhook: webhook,
+					ID:      webhook.ID.String(),
+					Name:    webhook.Name,
+					URL:     webhook.URL,
+					Events:  strings.Join(events, ", "),
+					Enabled: webhook.Enabled,
+				})
+			}
+
+			out, err := formatter.Format(cmd.Context(), rows)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), out)
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVar(&org, "org", false, "List the webhooks of your current organization instead of the deployment.")
+	formatter.AttachFlags(cmd)
+	return cmd
+}
+
+func editWebhook() *cobra.Command {
+	var (
+		name     string
+		endpoint string
+		events   []string
+		enabled  bool
+	)
+	cmd := &cobra.Command{
+		Use:   "edit <id>",
+		Short: "Edit a webhook",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create codersdk client: %w", err)
+			}
+			id, err := uuid.Parse(args[0])
+			if err != nil {
+				return xerrors.Errorf("parse webhook id: %w", err)
+			}
+
+			req := codersdk.UpdateWebhookRequest{
+				Name:   name,
+				URL:    endpoint,
+				Events: webhookEvents(events),
+			}
+			if cmd.Flags().Changed("enabled") {
+				req.Enabled = &enabled
+			}
+			webhook, err := client.UpdateWebhook(cmd.Context(), id, req)
+			if err != nil {
+				return xerrors.Errorf("update webhook: %w", err)
+			}
+
+			cmd.Printf("Updated webhook %s.\n", cliui.Styles.Keyword.Render(webhook.Name))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "Rename the webhook.")
+	cmd.Flags().StringVar(&endpoint, "endpoint", "", "Change the URL events are sent to.")
+	cmd.Flags().StringArrayVarP(&events, "event", "e", nil,
+		"Replace the subscribed events, can be specified multiple times.")
+	cmd.Flags().BoolVar(&enabled, "enabled", true, "Enable or disable deliveries, e.g. --enabled=false.")
+	return cmd
+}
+
+func deleteWebhook() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "delete <id>",
+		Aliases: []string{"rm"},
+		Short:   "Delete a webhook",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create codersdk client: %w", err)
+			}
+			id, err := uuid.Parse(args[0])
+			if err != nil {
+				return xerrors.Errorf("parse webhook id: %w", err)
+			}
+
+			err = client.DeleteWebhook(cmd.Context(), id)
+			if err != nil {
+				return xerrors.Errorf("delete webhook: %w", err)
+			}
+
+			cmd.Println(cliui.Styles.Wrap.Render(
+				"Webhook has been deleted.",
+			))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+// webhookDeliveryRow is the type provided to the OutputFormatter.
+type webhookDeliveryRow struct {
+	// For JSON format:
+	codersdk.WebhookDelivery `table:"-"`
+
+	// For table format:
+	CreatedAt  time.Time `json:"-" table:"created at,default_sort"`
+	Event      string    `json:"-" table:"event"`
+	EventID    string    `json:"-" table:"event id"`
+	Attempt    int       `json:"-" table:"attempt"`
+	StatusCode int       `json:"-" table:"status"`
+	Error      string    `json:"-" table:"error"`
+}
+
+func webhookDeliveries() *cobra.Command {
+	var (
+		limit     int
+		formatter = cliui.NewOutputFormatter(
+			cliui.TableFormat([]webhookDeliveryRow{}, []string{"created at", "event", "attempt", "status", "error"}),
+			cliui.JSONFormat(),
+		)
+	)
+	cmd := &cobra.Command{
+		Use:   "deliveries <id>",
+		Short: "List the most recent delivery attempts of a webhook",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create codersdk client: %w", err)
+			}
+			id, err := uuid.Parse(args[0])
+			if err != nil {
+				return xerrors.Errorf("parse webhook id: %w", err)
+			}
+
+			deliveries, err := client.WebhookDeliveries(cmd.Context(), id, limit)
+			if err != nil {
+				return xerrors.Errorf("list webhook deliveries: %w", err)
+			}
+
+			if len(deliveries) == 0 {
+				cmd.Println(cliui.Styles.Wrap.Render(
+					"No deliveries found.",
+				))
+				return nil
+			}
+
+			rows := make([]webhookDeliveryRow, 0, len(deliveries))
+			for _, delivery := range deliveries {
+				rows = append(rows, webhookDeliveryRow{
+					WebhookDelivery: delivery,
+					CreatedAt:       delivery.CreatedAt,
+					Event:           string(delivery.Event),
+					EventID:         delivery.Payload.ID.String(),
+					Attempt:         delivery.Attempt,
+					StatusCode:      delivery.StatusCode,
+					Error:           delivery.Error,
+				})
+			}
+
+			out, err := formatter.Format(cmd.Context(), rows)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), out)
+			return err
+		},
+	}
+
+	cmd.Flags().IntVar(&limit, "limit", 25, "The maximum number of deliveries to show, 0 shows all.")
+	formatter.AttachFlags(cmd)
+	return cmd
+}
+
+func webhookEvents(events []string) []codersdk.WebhookEvent {
+	converted := make([]codersdk.WebhookEvent, 0, len(events))
+	for _, event := range events {
+		converted = append(converted, codersdk.WebhookEvent(event))
+	}
+	return converted
+}
+
+func joinWebhookEvents() string {
+	events := make([]string, 0, len(codersdk.WebhookEvents))
+	for _, event := range codersdk.WebhookEvents {
+		events = append(events, string(event))
+	}
+	return strings.Join(events, ", ")
+}
@@ -0,0 +1,87 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWebhooks(t *testing.T) {
+	t.Parallel()
+	client := coderdtest.New(t, nil)
+	_ = coderdtest.CreateFirstUser(t, client)
+
+	ctx, cancelFunc := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancelFunc()
+
+	// helpful empty response
+	cmd, root := clitest.New(t, "webhooks", "ls")
+	clitest.SetupConfig(t, client, root)
+	buf := new(bytes.Buffer)
+	cmd.SetOut(buf)
+	err := cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "No webhooks found")
+
+	cmd, root = clitest.New(t, "webhooks", "create", "builds",
+		"--endpoint", "https://example.com/hook",
+		"--event", string(codersdk.WebhookEventBuildFailed),
+	)
+	clitest.SetupConfig(t, client, root)
+	buf = new(bytes.Buffer)
+	cmd.SetOut(buf)
+	err = cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "Created webhook")
+	require.Contains(t, buf.String(), codersdk.WebhookSignatureHeader)
+
+	webhooks, err := client.Webhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+	id := webhooks[0].ID.String()
+
+	cmd, root = clitest.New(t, "webhooks", "edit", id, "--enabled=false", "-e", string(codersdk.WebhookEventBuildSucceeded))
+	clitest.SetupConfig(t, client, root)
+	err = cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+
+	cmd, root = clitest.New(t, "webhooks", "ls", "--output=json")
+	clitest.SetupConfig(t, client, root)
+	buf = new(bytes.Buffer)
+	cmd.SetOut(buf)
+	err = cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+	var listed []codersdk.Webhook
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &listed))
+	require.Len(t, listed, 1)
+	require.False(t, listed[0].Enabled)
+	require.Equal(t, []codersdk.WebhookEvent{codersdk.WebhookEventBuildSucceeded}, listed[0].Events)
+
+	cmd, root = clitest.New(t, "webhooks", "deliveries", id)
+	clitest.SetupConfig(t, client, root)
+	buf = new(bytes.Buffer)
+	cmd.SetOut(buf)
+	err = cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "No deliveries found")
+
+	cmd, root = clitest.New(t, "webhooks", "rm", id)
+	clitest.SetupConfig(t, client, root)
+	buf = new(bytes.Buffer)
+	cmd.SetOut(buf)
+	err = cmd.ExecuteContext(ctx)
+	require.NoError(t, err)
+	require.Contains(t, buf.String(), "deleted")
+
+	webhooks, err = client.Webhooks(ctx)
+	require.NoError(t, err)
+	require.Empty(t, webhooks)
+}
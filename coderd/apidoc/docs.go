@@ -212,6 +212,76 @@ const docTemplate = `{
                 }
             }
         },
+        "/audit/csv": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "description": "Returns every audit log that matches the search query as CSV, newest first.",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Export audit logs as CSV",
+                "operationId": "export-audit-logs-as-csv",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/audit/export": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "description": "Returns the audit logs in a time range as JSON lines, in the order they happened.\nAudit logs that were archived after the retention period are included.",
+                "produces": [
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Export audit logs",
+                "operationId": "export-audit-logs",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Time of the oldest audit log, in RFC 3339 format",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Time before which audit logs are exported, in RFC 3339 format. Defaults to now.",
+                        "name": "until",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
         "/audit/testgenerate": {
             "post": {
                 "security": [
@@ -673,6 +743,57 @@ const docTemplate = `{
                 }
             }
         },
+        "/insights/users": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Insights"
+                ],
+                "summary": "Get user activity insights",
+                "operationId": "get-user-activity-insights",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Start of the range, defaults to 30 days ago",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "End of the range, defaults to now",
+                        "name": "end_time",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv"
+                        ],
+                        "type": "string",
+                        "description": "Response format, csv returns text/csv",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UserInsightsResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/licenses": {
             "get": {
                 "security": [
@@ -1180,6 +1301,131 @@ const docTemplate = `{
                 }
             }
         },
+        "/organizations/{organization}/provisionerkeys": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "List provisioner keys",
+                "operationId": "list-provisioner-keys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.ProvisionerKey"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Create provisioner key",
+                "operationId": "create-provisioner-key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Create provisioner key request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateProvisionerKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateProvisionerKeyResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{organization}/provisionerkeys/{provisionerkey}": {
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Delete provisioner key",
+                "operationId": "delete-provisioner-key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Provisioner key name",
+                        "name": "provisionerkey",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            }
+        },
         "/organizations/{organization}/templates": {
             "get": {
                 "security": [
@@ -1203,6 +1449,24 @@ const docTemplate = `{
                         "name": "organization",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Search query",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page offset",
+                        "name": "offset",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1487,7 +1751,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/parameters/{scope}/{id}": {
+        "/organizations/{organization}/webhooks": {
             "get": {
                 "security": [
                     {
@@ -1498,28 +1762,16 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Parameters"
+                    "Webhooks"
                 ],
-                "summary": "Get parameters",
-                "operationId": "get-parameters",
+                "summary": "Get organization webhooks",
+                "operationId": "get-organization-webhooks",
                 "parameters": [
-                    {
-                        "enum": [
-                            "template",
-                            "workspace",
-                            "import_job"
-                        ],
-                        "type": "string",
-                        "description": "Scope",
-                        "name": "scope",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "ID",
-                        "name": "id",
+                        "description": "Organization ID",
+                        "name": "organization",
                         "in": "path",
                         "required": true
                     }
@@ -1530,7 +1782,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/codersdk.Parameter"
+                                "$ref": "#/definitions/codersdk.Webhook"
                             }
                         }
                     }
@@ -1549,19 +1801,114 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Parameters"
+                    "Webhooks"
                 ],
-                "summary": "Create parameter",
-                "operationId": "create-parameter",
+                "summary": "Create organization webhook",
+                "operationId": "create-organization-webhook",
                 "parameters": [
                     {
-                        "description": "Parameter request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.CreateParameterRequest"
-                        }
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Organization ID",
+                        "name": "organization",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Create webhook request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Webhook"
+                        }
+                    }
+                }
+            }
+        },
+        "/parameters/{scope}/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Parameters"
+                ],
+                "summary": "Get parameters",
+                "operationId": "get-parameters",
+                "parameters": [
+                    {
+                        "enum": [
+                            "template",
+                            "workspace",
+                            "import_job"
+                        ],
+                        "type": "string",
+                        "description": "Scope",
+                        "name": "scope",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.Parameter"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Parameters"
+                ],
+                "summary": "Create parameter",
+                "operationId": "create-parameter",
+                "parameters": [
+                    {
+                        "description": "Parameter request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateParameterRequest"
+                        }
                     },
                     {
                         "enum": [
@@ -1648,6 +1995,29 @@ const docTemplate = `{
                 }
             }
         },
+        "/provisionerdaemons/serve": {
+            "get": {
+                "tags": [
+                    "Enterprise"
+                ],
+                "summary": "Serve provisioner daemon with a provisioner key",
+                "operationId": "serve-provisioner-daemon-with-a-provisioner-key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provisioner key",
+                        "name": "Coder-Provisioner-Key",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    }
+                }
+            }
+        },
         "/replicas": {
             "get": {
                 "security": [
@@ -2025,21 +2395,24 @@ const docTemplate = `{
                 }
             }
         },
-        "/templates/{template}/versions": {
-            "get": {
+        "/templates/{template}/deprecation": {
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Templates"
                 ],
-                "summary": "List template versions by template ID",
-                "operationId": "list-template-versions-by-template-id",
+                "summary": "Update template deprecation by ID",
+                "operationId": "update-template-deprecation-by-id",
                 "parameters": [
                     {
                         "type": "string",
@@ -2050,64 +2423,41 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "After ID",
-                        "name": "after_id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page limit",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page offset",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Deprecation request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateTemplateDeprecation"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.TemplateVersion"
-                            }
+                            "$ref": "#/definitions/codersdk.Template"
                         }
                     }
                 }
-            },
-            "patch": {
+            }
+        },
+        "/templates/{template}/git-sync": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Templates"
                 ],
-                "summary": "Update active template version by template ID",
-                "operationId": "update-active-template-version-by-template-id",
+                "summary": "Get template git sync by template ID",
+                "operationId": "get-template-git-sync-by-template-id",
                 "parameters": [
-                    {
-                        "description": "Modified template version",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.UpdateActiveTemplateVersion"
-                        }
-                    },
                     {
                         "type": "string",
                         "format": "uuid",
@@ -2121,27 +2471,29 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.Response"
+                            "$ref": "#/definitions/codersdk.TemplateGitSync"
                         }
                     }
                 }
-            }
-        },
-        "/templates/{template}/versions/{templateversionname}": {
-            "get": {
+            },
+            "put": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
+                "description": "Sets up the template to be synced with a git branch. The webhook secret is only returned by this endpoint.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Templates"
                 ],
-                "summary": "Get template version by template ID and name",
-                "operationId": "get-template-version-by-template-id-and-name",
+                "summary": "Update template git sync by template ID",
+                "operationId": "update-template-git-sync-by-template-id",
                 "parameters": [
                     {
                         "type": "string",
@@ -2152,28 +2504,25 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Template version name",
-                        "name": "templateversionname",
-                        "in": "path",
-                        "required": true
+                        "description": "Git sync request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateTemplateGitSyncRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/codersdk.TemplateVersion"
-                            }
+                            "$ref": "#/definitions/codersdk.TemplateGitSync"
                         }
                     }
                 }
-            }
-        },
-        "/templateversions/{templateversion}": {
-            "get": {
+            },
+            "delete": {
                 "security": [
                     {
                         "CoderSessionToken": []
@@ -2185,14 +2534,14 @@ const docTemplate = `{
                 "tags": [
                     "Templates"
                 ],
-                "summary": "Get template version by ID",
-                "operationId": "get-template-version-by-id",
+                "summary": "Delete template git sync by template ID",
+                "operationId": "delete-template-git-sync-by-template-id",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "Template ID",
+                        "name": "template",
                         "in": "path",
                         "required": true
                     }
@@ -2201,40 +2550,47 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.TemplateVersion"
+                            "$ref": "#/definitions/codersdk.Response"
                         }
                     }
                 }
             }
         },
-        "/templateversions/{templateversion}/cancel": {
-            "patch": {
-                "security": [
-                    {
-                        "CoderSessionToken": []
-                    }
-                ],
+        "/templates/{template}/git-sync/webhook": {
+            "post": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Templates"
                 ],
-                "summary": "Cancel template version by ID",
-                "operationId": "cancel-template-version-by-id",
+                "summary": "Trigger template git sync",
+                "operationId": "trigger-template-git-sync",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "Template ID",
+                        "name": "template",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "HMAC-SHA256 of the payload, signed with the webhook secret",
+                        "name": "X-Hub-Signature-256",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Webhook secret",
+                        "name": "X-Gitlab-Token",
+                        "in": "header"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
                             "$ref": "#/definitions/codersdk.Response"
                         }
@@ -2242,43 +2598,385 @@ const docTemplate = `{
                 }
             }
         },
-        "/templateversions/{templateversion}/dry-run": {
-            "post": {
+        "/templates/{template}/insights": {
+            "get": {
                 "security": [
                     {
                         "CoderSessionToken": []
                     }
                 ],
-                "consumes": [
-                    "application/json"
-                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Templates"
+                    "Insights"
                 ],
-                "summary": "Create template version dry-run",
-                "operationId": "create-template-version-dry-run",
+                "summary": "Get template insights",
+                "operationId": "get-template-insights",
                 "parameters": [
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Template version ID",
-                        "name": "templateversion",
+                        "description": "Template ID",
+                        "name": "template",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Dry-run request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/codersdk.CreateTemplateVersionDryRunRequest"
-                        }
-                    }
-                ],
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "Start of the range, rounded down to the day",
+                        "name": "start_time",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "format": "date-time",
+                        "description": "End of the range, rounded up to the day",
+                        "name": "end_time",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TemplateInsightsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{template}/versions": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "List template versions by template ID",
+                "operationId": "list-template-versions-by-template-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "After ID",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page limit",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page offset",
+                        "name": "offset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include archived versions in the list",
+                        "name": "include_archived",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateVersion"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Update active template version by template ID",
+                "operationId": "update-active-template-version-by-template-id",
+                "parameters": [
+                    {
+                        "description": "Modified template version",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateActiveTemplateVersion"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{template}/versions/archive": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Archive unused template versions by template ID",
+                "operationId": "archive-unused-template-versions-by-template-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.ArchiveTemplateVersionsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/templates/{template}/versions/{templateversionname}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get template version by template ID and name",
+                "operationId": "get-template-version-by-template-id-and-name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template ID",
+                        "name": "template",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Template version name",
+                        "name": "templateversionname",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.TemplateVersion"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Get template version by ID",
+                "operationId": "get-template-version-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TemplateVersion"
+                        }
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}/archive": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Archive template version",
+                "operationId": "archive-template-version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}/cancel": {
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Cancel template version by ID",
+                "operationId": "cancel-template-version-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/templateversions/{templateversion}/dry-run": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Create template version dry-run",
+                "operationId": "create-template-version-dry-run",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Dry-run request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateTemplateVersionDryRunRequest"
+                        }
+                    }
+                ],
                 "responses": {
                     "201": {
                         "description": "Created",
@@ -2424,6 +3122,42 @@ const docTemplate = `{
                         "description": "Follow log stream",
                         "name": "follow",
                         "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "trace",
+                            "debug",
+                            "info",
+                            "warn",
+                            "error"
+                        ],
+                        "type": "string",
+                        "description": "Lowest log level to return",
+                        "name": "level",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return logs of this stage",
+                        "name": "stage",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return logs whose output contains this, ignoring case",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "text",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Response format, text and ndjson are returned as attachments",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -2564,6 +3298,42 @@ const docTemplate = `{
                         "description": "Follow log stream",
                         "name": "follow",
                         "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "trace",
+                            "debug",
+                            "info",
+                            "warn",
+                            "error"
+                        ],
+                        "type": "string",
+                        "description": "Lowest log level to return",
+                        "name": "level",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return logs of this stage",
+                        "name": "stage",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return logs whose output contains this, ignoring case",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "text",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Response format, text and ndjson are returned as attachments",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -2731,6 +3501,41 @@ const docTemplate = `{
                 }
             }
         },
+        "/templateversions/{templateversion}/unarchive": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Templates"
+                ],
+                "summary": "Unarchive template version",
+                "operationId": "unarchive-template-version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Template version ID",
+                        "name": "templateversion",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            }
+        },
         "/templateversions/{templateversion}/variables": {
             "get": {
                 "security": [
@@ -3413,6 +4218,90 @@ const docTemplate = `{
                 }
             }
         },
+        "/users/{user}/notifications/preferences": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get user notification preferences",
+                "operationId": "get-user-notification-preferences",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.NotificationPreference"
+                            }
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Update user notification preferences",
+                "operationId": "update-user-notification-preferences",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID, name, or me",
+                        "name": "user",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Notification preferences",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateNotificationPreferencesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.NotificationPreference"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/users/{user}/organizations": {
             "get": {
                 "security": [
@@ -3813,7 +4702,229 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/codersdk.WorkspaceBuild"
+                            "$ref": "#/definitions/codersdk.WorkspaceBuild"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get deployment webhooks",
+                "operationId": "get-deployment-webhooks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.Webhook"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Create deployment webhook",
+                "operationId": "create-deployment-webhook",
+                "parameters": [
+                    {
+                        "description": "Create webhook request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Webhook"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{webhook}": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get webhook by ID",
+                "operationId": "get-webhook-by-id",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Webhook ID",
+                        "name": "webhook",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Webhook"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Delete webhook",
+                "operationId": "delete-webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Webhook ID",
+                        "name": "webhook",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Update webhook",
+                "operationId": "update-webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Webhook ID",
+                        "name": "webhook",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update webhook request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Webhook"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/{webhook}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhooks"
+                ],
+                "summary": "Get webhook deliveries",
+                "operationId": "get-webhook-deliveries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Webhook ID",
+                        "name": "webhook",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of deliveries to return",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/codersdk.WebhookDelivery"
+                            }
                         }
                     }
                 }
@@ -4117,6 +5228,42 @@ const docTemplate = `{
                 }
             }
         },
+        "/workspaceagents/me/report-connection": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Submit workspace agent connection",
+                "operationId": "submit-workspace-agent-connection",
+                "parameters": [
+                    {
+                        "description": "Workspace agent connection request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/agentsdk.PostConnectionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Success"
+                    }
+                },
+                "x-apidocgen": {
+                    "skip": true
+                }
+            }
+        },
         "/workspaceagents/me/report-lifecycle": {
             "post": {
                 "security": [
@@ -4502,6 +5649,42 @@ const docTemplate = `{
                         "description": "Follow log stream",
                         "name": "follow",
                         "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "trace",
+                            "debug",
+                            "info",
+                            "warn",
+                            "error"
+                        ],
+                        "type": "string",
+                        "description": "Lowest log level to return",
+                        "name": "level",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return logs of this stage",
+                        "name": "stage",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return logs whose output contains this, ignoring case",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "text",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Response format, text and ndjson are returned as attachments",
+                        "name": "format",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -4641,6 +5824,12 @@ const docTemplate = `{
                 "summary": "List workspaces",
                 "operationId": "list-workspaces",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query of key:value terms, terms can be negated with - and grouped with OR",
+                        "name": "q",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Filter by owner username",
@@ -4779,6 +5968,86 @@ const docTemplate = `{
                 }
             }
         },
+        "/workspaces/{workspace}/acl": {
+            "get": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Get workspace ACL",
+                "operationId": "get-workspace-acl",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.WorkspaceACL"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Update workspace ACL",
+                "operationId": "update-workspace-acl",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Update workspace ACL request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.UpdateWorkspaceACL"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Response"
+                        }
+                    }
+                }
+            }
+        },
         "/workspaces/{workspace}/autostart": {
             "put": {
                 "security": [
@@ -4929,6 +6198,54 @@ const docTemplate = `{
                 }
             }
         },
+        "/workspaces/{workspace}/clone": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "description": "The new workspace belongs to the current user and is created\nwith the parameter values of the latest build and the labels\nof the source.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Clone workspace",
+                "operationId": "clone-workspace",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Clone workspace request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.CloneWorkspaceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Workspace"
+                        }
+                    }
+                }
+            }
+        },
         "/workspaces/{workspace}/extend": {
             "put": {
                 "security": [
@@ -4976,6 +6293,54 @@ const docTemplate = `{
                 }
             }
         },
+        "/workspaces/{workspace}/transfer": {
+            "post": {
+                "security": [
+                    {
+                        "CoderSessionToken": []
+                    }
+                ],
+                "description": "The workspace is no longer shared with anyone after the\ntransfer. A start build is queued so the template can apply\nthe new owner.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Workspaces"
+                ],
+                "summary": "Transfer workspace to another user",
+                "operationId": "transfer-workspace-to-another-user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Workspace ID",
+                        "name": "workspace",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Transfer workspace request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.TransferWorkspaceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/codersdk.Workspace"
+                        }
+                    }
+                }
+            }
+        },
         "/workspaces/{workspace}/ttl": {
             "put": {
                 "security": [
@@ -5159,23 +6524,70 @@ const docTemplate = `{
                 "startup_script": {
                     "type": "string"
                 },
-                "startup_script_timeout": {
+                "startup_script_timeout": {
+                    "type": "integer"
+                },
+                "template_deprecation_message": {
+                    "description": "TemplateDeprecationMessage is shown after the MOTD when the template\nof the workspace is deprecated.",
+                    "type": "string"
+                },
+                "vscode_port_proxy_uri": {
+                    "type": "string"
+                }
+            }
+        },
+        "agentsdk.PostAppHealthsRequest": {
+            "type": "object",
+            "properties": {
+                "healths": {
+                    "description": "Healths is a map of the workspace app name and the health of the app.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/codersdk.WorkspaceAppHealth"
+                    }
+                }
+            }
+        },
+        "agentsdk.PostConnectionRequest": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "enum": [
+                        "connect",
+                        "disconnect"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.AuditAction"
+                        }
+                    ]
+                },
+                "detail": {
+                    "description": "Detail describes what was connected to, e.g. the address of a\nforwarded port.",
+                    "type": "string"
+                },
+                "duration_ms": {
+                    "description": "DurationMS is how long the connection lasted. It's only set on\ndisconnects.",
                     "type": "integer"
                 },
-                "vscode_port_proxy_uri": {
+                "id": {
+                    "description": "ID identifies the connection. The disconnect of a connection must have\nthe same ID as its connect.",
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "remote_addr": {
                     "type": "string"
-                }
-            }
-        },
-        "agentsdk.PostAppHealthsRequest": {
-            "type": "object",
-            "properties": {
-                "healths": {
-                    "description": "Healths is a map of the workspace app name and the health of the app.",
-                    "type": "object",
-                    "additionalProperties": {
-                        "$ref": "#/definitions/codersdk.WorkspaceAppHealth"
-                    }
+                },
+                "type": {
+                    "enum": [
+                        "ssh",
+                        "port_forward"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.ConnectionType"
+                        }
+                    ]
                 }
             }
         },
@@ -5201,6 +6613,13 @@ const docTemplate = `{
         "agentsdk.Stats": {
             "type": "object",
             "properties": {
+                "conns_by_port": {
+                    "description": "ConnectionsByPort is a count of connections by the agent port they\nwere made to.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
                 "conns_by_proto": {
                     "description": "ConnectionsByProto is a count of connections by protocol.",
                     "type": "object",
@@ -5426,6 +6845,21 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.ArchiveTemplateVersionsResponse": {
+            "type": "object",
+            "properties": {
+                "archived_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "template_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
         "codersdk.AssignableRoles": {
             "type": "object",
             "properties": {
@@ -5449,7 +6883,9 @@ const docTemplate = `{
                 "start",
                 "stop",
                 "login",
-                "logout"
+                "logout",
+                "connect",
+                "disconnect"
             ],
             "x-enum-varnames": [
                 "AuditActionCreate",
@@ -5458,7 +6894,9 @@ const docTemplate = `{
                 "AuditActionStart",
                 "AuditActionStop",
                 "AuditActionLogin",
-                "AuditActionLogout"
+                "AuditActionLogout",
+                "AuditActionConnect",
+                "AuditActionDisconnect"
             ]
         },
         "codersdk.AuditDiff": {
@@ -5559,6 +6997,61 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.AuditLogRetentionConfig": {
+            "type": "object",
+            "properties": {
+                "archive": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-bool"
+                },
+                "batch_size": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-int"
+                },
+                "period": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-time_Duration"
+                }
+            }
+        },
+        "codersdk.AuditStreamingConfig": {
+            "type": "object",
+            "properties": {
+                "buffer_size": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-int"
+                },
+                "file_filter": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-array_string"
+                },
+                "file_max_backups": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-int"
+                },
+                "file_max_size": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-int"
+                },
+                "file_path": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-string"
+                },
+                "syslog_address": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-string"
+                },
+                "syslog_filter": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-array_string"
+                },
+                "webhook_batch_size": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-int"
+                },
+                "webhook_filter": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-array_string"
+                },
+                "webhook_flush_interval": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-time_Duration"
+                },
+                "webhook_secret": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-string"
+                },
+                "webhook_url": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-string"
+                }
+            }
+        },
         "codersdk.AuthMethod": {
             "type": "object",
             "properties": {
@@ -5670,6 +7163,59 @@ const docTemplate = `{
                 "BuildReasonAutostop"
             ]
         },
+        "codersdk.CloneWorkspaceRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "labels": {
+                    "description": "Labels are added to the labels of the source workspace, replacing\nlabels with the same key.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parameter_values": {
+                    "description": "ParameterValues override the legacy parameter values of the source\nworkspace by name.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.CreateParameterRequest"
+                    }
+                },
+                "pin_template_version": {
+                    "description": "PinTemplateVersion creates the workspace with the template version of\nthe latest build of the source workspace, instead of the active version.",
+                    "type": "boolean"
+                },
+                "rich_parameter_values": {
+                    "description": "RichParameterValues override the build parameter values of the source\nworkspace by name.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceBuildParameter"
+                    }
+                }
+            }
+        },
+        "codersdk.ConnectionType": {
+            "type": "string",
+            "enum": [
+                "ssh",
+                "port_forward",
+                "reconnecting_pty",
+                "workspace_app",
+                "tailnet"
+            ],
+            "x-enum-varnames": [
+                "ConnectionTypeSSH",
+                "ConnectionTypePortForward",
+                "ConnectionTypeReconnectingPTY",
+                "ConnectionTypeWorkspaceApp",
+                "ConnectionTypeTailnet"
+            ]
+        },
         "codersdk.CreateFirstUserRequest": {
             "type": "object",
             "required": [
@@ -5776,6 +7322,31 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.CreateProvisionerKeyRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "codersdk.CreateProvisionerKeyResponse": {
+            "type": "object",
+            "properties": {
+                "key": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.CreateTemplateRequest": {
             "type": "object",
             "required": [
@@ -5951,6 +7522,33 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.CreateWebhookRequest": {
+            "type": "object",
+            "required": [
+                "events",
+                "name",
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WebhookEvent"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "secret": {
+                    "description": "Secret signs delivery payloads. A random secret is generated if\nit is omitted.",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.CreateWorkspaceBuildRequest": {
             "type": "object",
             "required": [
@@ -5971,6 +7569,19 @@ const docTemplate = `{
                         "$ref": "#/definitions/codersdk.CreateParameterRequest"
                     }
                 },
+                "priority": {
+                    "description": "Priority of the build's provisioner job, which defaults to interactive.\nScripts that queue many builds should use background, so users don't\nwait behind them.",
+                    "enum": [
+                        "interactive",
+                        "automatic",
+                        "background"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.ProvisionerJobPriority"
+                        }
+                    ]
+                },
                 "rich_parameter_values": {
                     "type": "array",
                     "items": {
@@ -6012,6 +7623,13 @@ const docTemplate = `{
                 "autostart_schedule": {
                     "type": "string"
                 },
+                "labels": {
+                    "description": "Labels are user defined key/value pairs to organize the workspace.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "name": {
                     "type": "string"
                 },
@@ -6022,6 +7640,19 @@ const docTemplate = `{
                         "$ref": "#/definitions/codersdk.CreateParameterRequest"
                     }
                 },
+                "priority": {
+                    "description": "Priority of the initial build's provisioner job, which defaults to\ninteractive. Scripts that create many workspaces should use background,\nso users don't wait behind them.",
+                    "enum": [
+                        "interactive",
+                        "automatic",
+                        "background"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.ProvisionerJobPriority"
+                        }
+                    ]
+                },
                 "rich_parameter_values": {
                     "type": "array",
                     "items": {
@@ -6032,6 +7663,11 @@ const docTemplate = `{
                     "type": "string",
                     "format": "uuid"
                 },
+                "template_version_id": {
+                    "description": "TemplateVersionID creates the workspace with a version of the template\nother than the active one.",
+                    "type": "string",
+                    "format": "uuid"
+                },
                 "ttl_ms": {
                     "type": "integer"
                 }
@@ -6136,9 +7772,15 @@ const docTemplate = `{
                 "agent_stat_refresh_interval": {
                     "$ref": "#/definitions/codersdk.DeploymentConfigField-time_Duration"
                 },
+                "audit_log_retention": {
+                    "$ref": "#/definitions/codersdk.AuditLogRetentionConfig"
+                },
                 "audit_logging": {
                     "$ref": "#/definitions/codersdk.DeploymentConfigField-bool"
                 },
+                "audit_streaming": {
+                    "$ref": "#/definitions/codersdk.AuditStreamingConfig"
+                },
                 "autobuild_poll_interval": {
                     "$ref": "#/definitions/codersdk.DeploymentConfigField-time_Duration"
                 },
@@ -6163,6 +7805,9 @@ const docTemplate = `{
                 "disable_session_expiry_refresh": {
                     "$ref": "#/definitions/codersdk.DeploymentConfigField-bool"
                 },
+                "email": {
+                    "$ref": "#/definitions/codersdk.EmailConfig"
+                },
                 "experimental": {
                     "description": "DEPRECATED: Use Experiments instead.",
                     "allOf": [
@@ -6174,6 +7819,9 @@ const docTemplate = `{
                 "experiments": {
                     "$ref": "#/definitions/codersdk.DeploymentConfigField-array_string"
                 },
+                "file_storage": {
+                    "$ref": "#/definitions/codersdk.FileStorageConfig"
+                },
                 "gitauth": {
                     "$ref": "#/definitions/codersdk.DeploymentConfigField-array_codersdk_GitAuthConfig"
                 },
@@ -6249,6 +7897,12 @@ const docTemplate = `{
                 "telemetry": {
                     "$ref": "#/definitions/codersdk.TelemetryConfig"
                 },
+                "template_git_sync_interval": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-time_Duration"
+                },
+                "template_version_gc_interval": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-time_Duration"
+                },
                 "tls": {
                     "$ref": "#/definitions/codersdk.TLSConfig"
                 },
@@ -6516,6 +8170,23 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.EmailConfig": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-string"
+                },
+                "password": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-string"
+                },
+                "smarthost": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-string"
+                },
+                "username": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-string"
+                }
+            }
+        },
         "codersdk.Entitlement": {
             "type": "string",
             "enum": [
@@ -6593,6 +8264,32 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.FileStorageConfig": {
+            "type": "object",
+            "properties": {
+                "backend": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-string"
+                },
+                "directory": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-string"
+                },
+                "s3_access_key_id": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-string"
+                },
+                "s3_bucket": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-string"
+                },
+                "s3_endpoint": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-string"
+                },
+                "s3_region": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-string"
+                },
+                "s3_secret_access_key": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-string"
+                }
+            }
+        },
         "codersdk.GenerateAPIKeyResponse": {
             "type": "object",
             "properties": {
@@ -6849,6 +8546,40 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.NotificationKind": {
+            "type": "string",
+            "enum": [
+                "workspace_autostop",
+                "workspace_build_failed",
+                "template_deprecated",
+                "account_created",
+                "account_suspended",
+                "account_activated"
+            ],
+            "x-enum-varnames": [
+                "NotificationKindWorkspaceAutostop",
+                "NotificationKindWorkspaceBuildFailed",
+                "NotificationKindTemplateDeprecated",
+                "NotificationKindAccountCreated",
+                "NotificationKindAccountSuspended",
+                "NotificationKindAccountActivated"
+            ]
+        },
+        "codersdk.NotificationPreference": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "kind": {
+                    "$ref": "#/definitions/codersdk.NotificationKind"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
         "codersdk.OAuth2Config": {
             "type": "object",
             "properties": {
@@ -7198,6 +8929,15 @@ const docTemplate = `{
                 },
                 "force_cancel_interval": {
                     "$ref": "#/definitions/codersdk.DeploymentConfigField-time_Duration"
+                },
+                "job_hang_timeout": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-time_Duration"
+                },
+                "job_log_retention": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-time_Duration"
+                },
+                "job_max_runtime": {
+                    "$ref": "#/definitions/codersdk.DeploymentConfigField-time_Duration"
                 }
             }
         },
@@ -7208,9 +8948,19 @@ const docTemplate = `{
                     "type": "string",
                     "format": "date-time"
                 },
+                "current_job_id": {
+                    "description": "CurrentJobID is the job the daemon is running, if any.",
+                    "type": "string",
+                    "format": "uuid"
+                },
                 "id": {
                     "type": "string",
-                    "format": "uuid"
+                    "format": "uuid"
+                },
+                "last_seen_at": {
+                    "description": "LastSeenAt is when the daemon last polled for or updated a job.",
+                    "type": "string",
+                    "format": "date-time"
                 },
                 "name": {
                     "type": "string"
@@ -7221,6 +8971,17 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "status": {
+                    "enum": [
+                        "online",
+                        "offline"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.ProvisionerDaemonStatus"
+                        }
+                    ]
+                },
                 "tags": {
                     "type": "object",
                     "additionalProperties": {
@@ -7234,9 +8995,24 @@ const docTemplate = `{
                             "$ref": "#/definitions/sql.NullTime"
                         }
                     ]
+                },
+                "version": {
+                    "description": "Version is empty for daemons that don't report it.",
+                    "type": "string"
                 }
             }
         },
+        "codersdk.ProvisionerDaemonStatus": {
+            "type": "string",
+            "enum": [
+                "online",
+                "offline"
+            ],
+            "x-enum-varnames": [
+                "ProvisionerDaemonOnline",
+                "ProvisionerDaemonOffline"
+            ]
+        },
         "codersdk.ProvisionerJob": {
             "type": "object",
             "properties": {
@@ -7263,6 +9039,27 @@ const docTemplate = `{
                     "type": "string",
                     "format": "uuid"
                 },
+                "priority": {
+                    "description": "Priority is the order the job is acquired in. Within a priority, jobs\nof the users and organizations with the fewest running jobs are\nacquired first.",
+                    "enum": [
+                        "interactive",
+                        "automatic",
+                        "background"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.ProvisionerJobPriority"
+                        }
+                    ]
+                },
+                "queue": {
+                    "description": "Queue is only set while the job is pending.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.ProvisionerJobQueue"
+                        }
+                    ]
+                },
                 "started_at": {
                     "type": "string",
                     "format": "date-time"
@@ -7329,6 +9126,39 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.ProvisionerJobPriority": {
+            "type": "string",
+            "enum": [
+                "interactive",
+                "automatic",
+                "background"
+            ],
+            "x-enum-varnames": [
+                "ProvisionerJobPriorityInteractive",
+                "ProvisionerJobPriorityAutomatic",
+                "ProvisionerJobPriorityBackground"
+            ]
+        },
+        "codersdk.ProvisionerJobQueue": {
+            "type": "object",
+            "properties": {
+                "estimated_wait_ms": {
+                    "description": "EstimatedWaitMillis is based on the duration of recent jobs in the\nqueue. It's omitted if no jobs completed recently.",
+                    "type": "integer"
+                },
+                "position": {
+                    "description": "Position starts at 1 for the next job to be acquired.",
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "warning": {
+                    "description": "Warning is set when no online provisioner daemon can acquire the job,\ne.g. because none of them have its tags.",
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.ProvisionerJobStatus": {
             "type": "string",
             "enum": [
@@ -7348,6 +9178,32 @@ const docTemplate = `{
                 "ProvisionerJobFailed"
             ]
         },
+        "codersdk.ProvisionerKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "tags": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "codersdk.PutExtendWorkspaceRequest": {
             "type": "object",
             "required": [
@@ -7417,7 +9273,10 @@ const docTemplate = `{
                 "git_ssh_key",
                 "api_key",
                 "group",
-                "license"
+                "license",
+                "organization",
+                "organization_member",
+                "appearance"
             ],
             "x-enum-varnames": [
                 "ResourceTypeTemplate",
@@ -7428,7 +9287,10 @@ const docTemplate = `{
                 "ResourceTypeGitSSHKey",
                 "ResourceTypeAPIKey",
                 "ResourceTypeGroup",
-                "ResourceTypeLicense"
+                "ResourceTypeLicense",
+                "ResourceTypeOrganization",
+                "ResourceTypeOrganizationMember",
+                "ResourceTypeAppearance"
             ]
         },
         "codersdk.Response": {
@@ -7572,12 +9434,173 @@ const docTemplate = `{
                 "default_ttl_ms": {
                     "type": "integer"
                 },
+                "deprecated": {
+                    "description": "Deprecated templates cannot be used to create new workspaces. The\nDeprecationMessage is shown to the owners of existing workspaces.",
+                    "type": "boolean"
+                },
+                "deprecation_message": {
+                    "type": "string"
+                },
                 "description": {
                     "type": "string"
                 },
                 "display_name": {
                     "type": "string"
                 },
+                "git_sync_error": {
+                    "description": "GitSyncError is the error of the last sync with git, empty if it\nsucceeded.",
+                    "type": "string"
+                },
+                "git_sync_status": {
+                    "description": "GitSyncStatus is the status of the version created for the last commit\nof the git branch the template is synced with. It's empty if the\ntemplate isn't synced with git.",
+                    "enum": [
+                        "pending",
+                        "succeeded",
+                        "failed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.TemplateGitSyncStatus"
+                        }
+                    ]
+                },
+                "icon": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "provisioner": {
+                    "type": "string",
+                    "enum": [
+                        "terraform"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                }
+            }
+        },
+        "codersdk.TemplateAppUsage": {
+            "type": "object",
+            "properties": {
+                "connections": {
+                    "type": "integer"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "icon": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.TemplateBuildInsights": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer"
+                },
+                "failure_rate": {
+                    "type": "number"
+                },
+                "p50": {
+                    "description": "P50 and P95 are the build times of successful builds in milliseconds.\nThey are null when there were no successful builds.",
+                    "type": "integer",
+                    "example": 123
+                },
+                "p95": {
+                    "type": "integer",
+                    "example": 146
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "transition": {
+                    "enum": [
+                        "start",
+                        "stop",
+                        "delete"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceTransition"
+                        }
+                    ]
+                }
+            }
+        },
+        "codersdk.TemplateBuildTimeStats": {
+            "type": "object",
+            "additionalProperties": {
+                "$ref": "#/definitions/codersdk.TransitionStats"
+            }
+        },
+        "codersdk.TemplateConnectionType": {
+            "type": "string",
+            "enum": [
+                "ssh",
+                "web_terminal",
+                "app",
+                "port_forward"
+            ],
+            "x-enum-varnames": [
+                "TemplateConnectionTypeSSH",
+                "TemplateConnectionTypeWebTerminal",
+                "TemplateConnectionTypeApp",
+                "TemplateConnectionTypePortForward"
+            ]
+        },
+        "codersdk.TemplateConnectionUsage": {
+            "type": "object",
+            "properties": {
+                "connections": {
+                    "type": "integer"
+                },
+                "type": {
+                    "enum": [
+                        "ssh",
+                        "web_terminal",
+                        "app",
+                        "port_forward"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.TemplateConnectionType"
+                        }
+                    ]
+                }
+            }
+        },
+        "codersdk.TemplateDAUsResponse": {
+            "type": "object",
+            "properties": {
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.DAUEntry"
+                    }
+                }
+            }
+        },
+        "codersdk.TemplateExample": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
                 "icon": {
                     "type": "string"
                 },
@@ -7585,69 +9608,144 @@ const docTemplate = `{
                     "type": "string",
                     "format": "uuid"
                 },
-                "name": {
+                "markdown": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.TemplateGitSync": {
+            "type": "object",
+            "properties": {
+                "branch": {
+                    "description": "Branch defaults to the default branch of the repository.",
+                    "type": "string"
+                },
+                "checked_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "commit_sha": {
+                    "description": "CommitSHA is the last commit a version was created for.",
+                    "type": "string"
+                },
+                "directory": {
+                    "description": "Directory is the path of the template in the repository. Defaults to\nthe root of the repository.",
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error is the error of the last sync, empty if it succeeded.",
+                    "type": "string"
+                },
+                "status": {
+                    "enum": [
+                        "pending",
+                        "succeeded",
+                        "failed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.TemplateGitSyncStatus"
+                        }
+                    ]
+                },
+                "template_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "template_version_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "url": {
+                    "description": "URL is the HTTP(S) URL of the repository.",
                     "type": "string"
                 },
-                "organization_id": {
+                "user_id": {
+                    "description": "UserID is the user that set up the sync. Versions are created with\ntheir permissions and git auth credentials.",
                     "type": "string",
                     "format": "uuid"
                 },
-                "provisioner": {
-                    "type": "string",
-                    "enum": [
-                        "terraform"
-                    ]
+                "webhook_secret": {
+                    "description": "WebhookSecret verifies push webhooks. It's only returned when the sync\nis set up.",
+                    "type": "string"
                 },
-                "updated_at": {
-                    "type": "string",
-                    "format": "date-time"
+                "webhook_url": {
+                    "description": "WebhookURL triggers a sync when a push webhook of the repository is\nsent to it.",
+                    "type": "string"
                 }
             }
         },
-        "codersdk.TemplateBuildTimeStats": {
-            "type": "object",
-            "additionalProperties": {
-                "$ref": "#/definitions/codersdk.TransitionStats"
-            }
+        "codersdk.TemplateGitSyncStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "TemplateGitSyncStatusPending",
+                "TemplateGitSyncStatusSucceeded",
+                "TemplateGitSyncStatusFailed"
+            ]
         },
-        "codersdk.TemplateDAUsResponse": {
+        "codersdk.TemplateInsightsResponse": {
             "type": "object",
             "properties": {
-                "entries": {
+                "active_workspaces": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/codersdk.DAUEntry"
                     }
-                }
-            }
-        },
-        "codersdk.TemplateExample": {
-            "type": "object",
-            "properties": {
-                "description": {
-                    "type": "string"
                 },
-                "icon": {
-                    "type": "string"
+                "apps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateAppUsage"
+                    }
                 },
-                "id": {
-                    "type": "string",
-                    "format": "uuid"
+                "builds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateBuildInsights"
+                    }
                 },
-                "markdown": {
-                    "type": "string"
+                "connection_types": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.TemplateConnectionUsage"
+                    }
                 },
-                "name": {
-                    "type": "string"
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time"
                 },
-                "tags": {
+                "start_time": {
+                    "description": "StartTime and EndTime are the range the insights were computed over,\nafter being rounded to whole days.",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "version_adoption": {
+                    "description": "VersionAdoption is the number of workspaces on each version of the\ntemplate right now, regardless of the range.",
                     "type": "array",
                     "items": {
-                        "type": "string"
+                        "$ref": "#/definitions/codersdk.TemplateVersionAdoption"
                     }
-                },
-                "url": {
-                    "type": "string"
                 }
             }
         },
@@ -7736,6 +9834,9 @@ const docTemplate = `{
         "codersdk.TemplateVersion": {
             "type": "object",
             "properties": {
+                "archived": {
+                    "type": "boolean"
+                },
                 "created_at": {
                     "type": "string",
                     "format": "date-time"
@@ -7743,6 +9844,14 @@ const docTemplate = `{
                 "created_by": {
                     "$ref": "#/definitions/codersdk.User"
                 },
+                "git_source": {
+                    "description": "GitSource is set when the version was created from a git repository.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.TemplateVersionGitSource"
+                        }
+                    ]
+                },
                 "id": {
                     "type": "string",
                     "format": "uuid"
@@ -7770,6 +9879,24 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.TemplateVersionAdoption": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "template_version_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "workspaces": {
+                    "type": "integer"
+                }
+            }
+        },
         "codersdk.TemplateVersionGitAuth": {
             "type": "object",
             "properties": {
@@ -7787,6 +9914,30 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.TemplateVersionGitSource": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "commit_sha": {
+                    "description": "CommitSHA is the commit the ref resolved to. It's set by the server.",
+                    "type": "string"
+                },
+                "directory": {
+                    "description": "Directory is the path of the template in the repository. Defaults to\nthe root of the repository.",
+                    "type": "string"
+                },
+                "ref": {
+                    "description": "Ref is a branch, tag or commit SHA. Defaults to the default branch.",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "URL is the HTTP(S) URL of the repository.",
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.TemplateVersionParameter": {
             "type": "object",
             "properties": {
@@ -7909,6 +10060,22 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.TransferWorkspaceRequest": {
+            "type": "object",
+            "required": [
+                "owner"
+            ],
+            "properties": {
+                "name": {
+                    "description": "Name renames the workspace. If empty, the workspace keeps its name, or\ngets a numeric suffix if the new owner has a workspace with that name.",
+                    "type": "string"
+                },
+                "owner": {
+                    "description": "Owner is the username or ID of the new owner.",
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.TransitionStats": {
             "type": "object",
             "properties": {
@@ -7962,6 +10129,21 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UpdateNotificationPreferencesRequest": {
+            "type": "object",
+            "required": [
+                "preferences"
+            ],
+            "properties": {
+                "preferences": {
+                    "description": "Preferences only needs to contain the kinds that change.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.NotificationPreference"
+                    }
+                }
+            }
+        },
         "codersdk.UpdateRoles": {
             "type": "object",
             "properties": {
@@ -7990,6 +10172,35 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UpdateTemplateDeprecation": {
+            "type": "object",
+            "properties": {
+                "deprecated": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "description": "Message tells users why the template is deprecated and what to use\ninstead. It is required to deprecate a template.",
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.UpdateTemplateGitSyncRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "branch": {
+                    "type": "string"
+                },
+                "directory": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.UpdateUserPasswordRequest": {
             "type": "object",
             "required": [
@@ -8015,6 +10226,43 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.UpdateWebhookRequest": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WebhookEvent"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.UpdateWorkspaceACL": {
+            "type": "object",
+            "properties": {
+                "group_perms": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/codersdk.WorkspaceRole"
+                    }
+                },
+                "user_perms": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/codersdk.WorkspaceRole"
+                    }
+                }
+            }
+        },
         "codersdk.UpdateWorkspaceAutostartRequest": {
             "type": "object",
             "properties": {
@@ -8026,6 +10274,13 @@ const docTemplate = `{
         "codersdk.UpdateWorkspaceRequest": {
             "type": "object",
             "properties": {
+                "labels": {
+                    "description": "Labels replace the labels of the workspace. They are not changed when\nomitted, an empty object removes all labels.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "name": {
                     "type": "string"
                 }
@@ -8084,25 +10339,90 @@ const docTemplate = `{
                         "format": "uuid"
                     }
                 },
-                "roles": {
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.Role"
+                    }
+                },
+                "status": {
+                    "enum": [
+                        "active",
+                        "suspended"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.UserStatus"
+                        }
+                    ]
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.UserInsights": {
+            "type": "object",
+            "properties": {
+                "builds": {
+                    "description": "Builds is the number of workspace builds the user started, including\nthose started on their behalf by autostart and autostop.",
+                    "type": "integer"
+                },
+                "connected_seconds": {
+                    "description": "ConnectedSeconds is how long the user had connections open to any of\ntheir workspaces, at the granularity of the agent stats interval.",
+                    "type": "integer"
+                },
+                "cost_accrued": {
+                    "description": "CostAccrued is the daily cost of the user's workspaces, prorated over\nthe time they were running.",
+                    "type": "number"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "status": {
+                    "enum": [
+                        "active",
+                        "suspended"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.UserStatus"
+                        }
+                    ]
+                },
+                "user_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "username": {
+                    "type": "string"
+                },
+                "workspaces_owned": {
+                    "description": "WorkspacesOwned is the number of workspaces the user owns right now,\nregardless of the range.",
+                    "type": "integer"
+                }
+            }
+        },
+        "codersdk.UserInsightsResponse": {
+            "type": "object",
+            "properties": {
+                "end_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "start_time": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "users": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/codersdk.Role"
+                        "$ref": "#/definitions/codersdk.UserInsights"
                     }
-                },
-                "status": {
-                    "enum": [
-                        "active",
-                        "suspended"
-                    ],
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/codersdk.UserStatus"
-                        }
-                    ]
-                },
-                "username": {
-                    "type": "string"
                 }
             }
         },
@@ -8154,6 +10474,147 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.Webhook": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "created_by": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WebhookEvent"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "secret": {
+                    "description": "Secret is only returned when the webhook is created.",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "codersdk.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempt": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "delivered_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "event": {
+                    "$ref": "#/definitions/codersdk.WebhookEvent"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "payload": {
+                    "$ref": "#/definitions/codersdk.WebhookEventPayload"
+                },
+                "response_body": {
+                    "type": "string"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "webhook_id": {
+                    "type": "string",
+                    "format": "uuid"
+                }
+            }
+        },
+        "codersdk.WebhookEvent": {
+            "type": "string",
+            "enum": [
+                "build.started",
+                "build.succeeded",
+                "build.failed",
+                "workspace.created",
+                "workspace.deleted",
+                "template_version.promoted",
+                "user.created",
+                "user.suspended"
+            ],
+            "x-enum-varnames": [
+                "WebhookEventBuildStarted",
+                "WebhookEventBuildSucceeded",
+                "WebhookEventBuildFailed",
+                "WebhookEventWorkspaceCreated",
+                "WebhookEventWorkspaceDeleted",
+                "WebhookEventTemplateVersionPromoted",
+                "WebhookEventUserCreated",
+                "WebhookEventUserSuspended"
+            ]
+        },
+        "codersdk.WebhookEventPayload": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "data": {
+                    "description": "Data describes the resource the event is about, e.g. \"workspace_id\"\nand \"workspace_name\" for workspace events.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "event": {
+                    "$ref": "#/definitions/codersdk.WebhookEvent"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "organization_ids": {
+                    "description": "OrganizationIDs are the organizations of the user for user events,\nwhich aren't scoped to one organization. OrganizationID is empty for\nthem.",
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                }
+            }
+        },
         "codersdk.Workspace": {
             "type": "object",
             "properties": {
@@ -8168,6 +10629,13 @@ const docTemplate = `{
                     "type": "string",
                     "format": "uuid"
                 },
+                "labels": {
+                    "description": "Labels are user defined key/value pairs to organize workspaces. They\nare passed to the template as the coder_workspace_labels variable.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "last_used_at": {
                     "type": "string",
                     "format": "date-time"
@@ -8191,6 +10659,9 @@ const docTemplate = `{
                 "template_allow_user_cancel_workspace_jobs": {
                     "type": "boolean"
                 },
+                "template_deprecation_message": {
+                    "type": "string"
+                },
                 "template_display_name": {
                     "type": "string"
                 },
@@ -8213,6 +10684,23 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceACL": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceGroup"
+                    }
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.WorkspaceUser"
+                    }
+                }
+            }
+        },
         "codersdk.WorkspaceAgent": {
             "type": "object",
             "properties": {
@@ -8582,6 +11070,45 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceGroup": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.User"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "quota_allowance": {
+                    "type": "integer"
+                },
+                "role": {
+                    "enum": [
+                        "admin",
+                        "use"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceRole"
+                        }
+                    ]
+                }
+            }
+        },
         "codersdk.WorkspaceQuota": {
             "type": "object",
             "properties": {
@@ -8663,6 +11190,19 @@ const docTemplate = `{
                 }
             }
         },
+        "codersdk.WorkspaceRole": {
+            "type": "string",
+            "enum": [
+                "admin",
+                "use",
+                ""
+            ],
+            "x-enum-varnames": [
+                "WorkspaceRoleAdmin",
+                "WorkspaceRoleUse",
+                "WorkspaceRoleDeleted"
+            ]
+        },
         "codersdk.WorkspaceStatus": {
             "type": "string",
             "enum": [
@@ -8703,6 +11243,75 @@ const docTemplate = `{
                 "WorkspaceTransitionDelete"
             ]
         },
+        "codersdk.WorkspaceUser": {
+            "type": "object",
+            "required": [
+                "created_at",
+                "email",
+                "id",
+                "username"
+            ],
+            "properties": {
+                "avatar_url": {
+                    "type": "string",
+                    "format": "uri"
+                },
+                "created_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "email": {
+                    "type": "string",
+                    "format": "email"
+                },
+                "id": {
+                    "type": "string",
+                    "format": "uuid"
+                },
+                "last_seen_at": {
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "organization_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "format": "uuid"
+                    }
+                },
+                "role": {
+                    "enum": [
+                        "admin",
+                        "use"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.WorkspaceRole"
+                        }
+                    ]
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/codersdk.Role"
+                    }
+                },
+                "status": {
+                    "enum": [
+                        "active",
+                        "suspended"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/codersdk.UserStatus"
+                        }
+                    ]
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
         "codersdk.WorkspacesResponse": {
             "type": "object",
             "properties": {
@@ -1,13 +1,17 @@
 package coderd
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"net"
 	"net/http"
 	"net/netip"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -164,6 +168,244 @@ func (api *API) generateFakeAuditLog(rw http.ResponseWriter, r *http.Request) {
 	rw.WriteHeader(http.StatusNoContent)
 }
 
+// auditLogExportBatchSize is how many audit logs are read from the database
+// at once while exporting.
+const auditLogExportBatchSize = 1000
+
+// @Summary Export audit logs
+// @Description Returns the audit logs in a time range as JSON lines, in the order they happened.
+// @Description Audit logs that were archived after the retention period are included.
+// @ID export-audit-logs
+// @Security CoderSessionToken
+// @Produce application/x-ndjson
+// @Tags Audit
+// @Param since query string false "Time of the oldest audit log, in RFC 3339 format" format(date-time)
+// @Param until query string false "Time before which audit logs are exported, in RFC 3339 format. Defaults to now." format(date-time)
+// @Success 200
+// @Router /audit/export [get]
+func (api *API) exportAuditLogs(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceAuditLog) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	parser := httpapi.NewQueryParamParser()
+	since := parser.Time(r.URL.Query(), time.Time{}, "since", time.RFC3339)
+	until := parser.Time(r.URL.Query(), database.Now(), "until", time.RFC3339)
+	if len(parser.Errors) == 0 && !until.After(since) {
+		parser.Errors = append(parser.Errors, codersdk.ValidationError{
+			Field:  "until",
+			Detail: "Query param \"until\" must be after \"since\".",
+		})
+	}
+	if len(parser.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: parser.Errors,
+		})
+		return
+	}
+
+	archives, err := api.Database.GetAuditLogArchivesByTimeRange(ctx, database.GetAuditLogArchivesByTimeRangeParams{
+		Since: since,
+		Until: until,
+	})
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+	// Nothing has been written yet, so the export fails with a proper error
+	// if an archive can't be read.
+	for _, archive := range archives {
+		if api.FileStore == nil || archive.Storage != api.FileStore.Backend() {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "An audit log archive is stored in a file storage backend that isn't configured.",
+				Detail:  fmt.Sprintf("Archive %s is stored in the %q backend.", archive.ID, archive.Storage),
+			})
+			return
+		}
+	}
+
+	rw.Header().Set("Content-Type", "application/x-ndjson")
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "audit-logs.ndjson"))
+	rw.WriteHeader(http.StatusOK)
+	logger := api.Logger.With(slog.F("since", since), slog.F("until", until))
+
+	// Archived audit logs are older than the ones in the database.
+	for _, archive := range archives {
+		data, err := api.FileStore.Get(ctx, archive.Hash)
+		if err != nil {
+			logger.Error(ctx, "get audit log archive", slog.F("archive_id", archive.ID), slog.Error(err))
+			return
+		}
+		err = audit.ReadArchive(bytes.NewReader(data), func(line []byte, log audit.ExportedLog) error {
+			if log.Time.Before(since) || !log.Time.Before(until) {
+				return nil
+			}
+			_, err := rw.Write(append(line, '\n'))
+			return err
+		})
+		if err != nil {
+			logger.Error(ctx, "export audit log archive", slog.F("archive_id", archive.ID), slog.Error(err))
+			return
+		}
+	}
+
+	after := database.AuditLog{Time: since}
+	for {
+		logs, err := api.Database.GetAuditLogsByTimeRange(ctx, database.GetAuditLogsByTimeRangeParams{
+			AfterTime: after.Time,
+			AfterID:   after.ID,
+			Until:     until,
+			RowLimit:  auditLogExportBatchSize,
+		})
+		if err != nil {
+			logger.Error(ctx, "get audit logs", slog.Error(err))
+			return
+		}
+		for _, alog := range logs {
+			data, err := audit.MarshalLog(alog)
+			if err != nil {
+				logger.Error(ctx, "marshal audit log", slog.F("audit_log_id", alog.ID), slog.Error(err))
+				return
+			}
+			_, err = rw.Write(append(data, '\n'))
+			if err != nil {
+				return
+			}
+		}
+		if len(logs) < auditLogExportBatchSize {
+			return
+		}
+		after = logs[len(logs)-1]
+	}
+}
+
+// auditLogCSVHeader is the header row of audit logs exported as CSV.
+var auditLogCSVHeader = []string{
+	"time",
+	"id",
+	"request_id",
+	"user_id",
+	"username",
+	"email",
+	"ip",
+	"user_agent",
+	"action",
+	"resource_type",
+	"resource_id",
+	"resource_target",
+	"status_code",
+	"diff",
+	"additional_fields",
+}
+
+// @Summary Export audit logs as CSV
+// @Description Returns every audit log that matches the search query as CSV, newest first.
+// @ID export-audit-logs-as-csv
+// @Security CoderSessionToken
+// @Produce text/csv
+// @Tags Audit
+// @Param q query string false "Search query"
+// @Success 200
+// @Router /audit/csv [get]
+func (api *API) auditLogsCSV(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceAuditLog) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	filter, errs := searchquery.AuditLogs(r.URL.Query().Get("q"))
+	if len(errs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid audit search query.",
+			Validations: errs,
+		})
+		return
+	}
+	params := database.GetAuditLogsForExportParams{
+		ResourceType:   filter.ResourceType,
+		ResourceID:     filter.ResourceID,
+		ResourceTarget: filter.ResourceTarget,
+		Action:         filter.Action,
+		Username:       filter.Username,
+		Email:          filter.Email,
+		DateFrom:       filter.DateFrom,
+		DateTo:         filter.DateTo,
+		BuildReason:    filter.BuildReason,
+		Ip:             filter.Ip,
+		RequestID:      filter.RequestID,
+		Search:         filter.Search,
+		RowLimit:       auditLogExportBatchSize,
+	}
+
+	rw.Header().Set("Content-Type", "text/csv")
+	rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "audit-logs.csv"))
+	rw.WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(rw)
+	err := w.Write(auditLogCSVHeader)
+	if err != nil {
+		return
+	}
+	for {
+		logs, err := api.Database.GetAuditLogsForExport(ctx, params)
+		if err != nil {
+			api.Logger.Error(ctx, "get audit logs", slog.Error(err))
+			return
+		}
+		for _, alog := range logs {
+			var ip string
+			if alog.Ip.Valid {
+				ip = alog.Ip.IPNet.IP.String()
+			}
+			err = w.Write([]string{
+				alog.Time.Format(time.RFC3339Nano),
+				alog.ID.String(),
+				alog.RequestID.String(),
+				alog.UserID.String(),
+				csvCell(alog.UserUsername.String),
+				csvCell(alog.UserEmail.String),
+				ip,
+				csvCell(alog.UserAgent.String),
+				string(alog.Action),
+				string(alog.ResourceType),
+				alog.ResourceID.String(),
+				csvCell(alog.ResourceTarget),
+				strconv.Itoa(int(alog.StatusCode)),
+				csvCell(string(alog.Diff)),
+				csvCell(string(alog.AdditionalFields)),
+			})
+			if err != nil {
+				return
+			}
+		}
+		w.Flush()
+		if w.Error() != nil {
+			return
+		}
+		if len(logs) < auditLogExportBatchSize {
+			return
+		}
+		// Page by the last audit log instead of an offset, so audit logs
+		// inserted while the export is running don't shift the pages.
+		last := logs[len(logs)-1]
+		params.BeforeTime = last.Time
+		params.BeforeID = last.ID
+	}
+}
+
+// csvCell prefixes values that spreadsheet applications would evaluate as a
+// formula with a single quote, so exported audit logs can't inject formulas.
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
 func (api *API) convertAuditLogs(ctx context.Context, dblogs []database.GetAuditLogsOffsetRow) []codersdk.AuditLog {
 	alogs := make([]codersdk.AuditLog, 0, len(dblogs))
 
@@ -261,8 +503,9 @@ func auditLogDescription(alog database.GetAuditLogsOffsetRow) string {
 	}
 
 	// We don't display the name (target) for git ssh keys. It's fairly long and doesn't
-	// make too much sense to display.
-	if alog.ResourceType == database.ResourceTypeGitSshKey {
+	// make too much sense to display. Appearance is a deployment-wide setting, so
+	// it has no target.
+	if alog.ResourceType == database.ResourceTypeGitSshKey || alog.ResourceType == database.ResourceTypeAppearance {
 		str += fmt.Sprintf(" the %s",
 			codersdk.ResourceType(alog.ResourceType).FriendlyString())
 		return str
@@ -333,10 +576,13 @@ func (api *API) auditLogResourceLink(ctx context.Context, alog database.GetAudit
 		return fmt.Sprintf("/templates/%s",
 			alog.ResourceTarget)
 
-	case database.ResourceTypeUser:
+	case database.ResourceTypeUser, database.ResourceTypeOrganizationMember:
 		return fmt.Sprintf("/users?filter=%s",
 			alog.ResourceTarget)
 
+	case database.ResourceTypeAppearance:
+		return "/settings/deployment/appearance"
+
 	case database.ResourceTypeWorkspace:
 		workspace, getWorkspaceErr := api.Database.GetWorkspaceByID(ctx, alog.ResourceID)
 		if getWorkspaceErr != nil {
@@ -0,0 +1,117 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+)
+
+// ExportedLog is the JSON representation of an audit log outside of the
+// database. Audit logs are streamed, archived and exported as ExportedLogs.
+type ExportedLog struct {
+	ID               uuid.UUID             `json:"id"`
+	Time             time.Time             `json:"time"`
+	UserID           uuid.UUID             `json:"user_id"`
+	OrganizationID   uuid.UUID             `json:"organization_id"`
+	IP               string                `json:"ip"`
+	UserAgent        string                `json:"user_agent"`
+	ResourceType     database.ResourceType `json:"resource_type"`
+	ResourceID       uuid.UUID             `json:"resource_id"`
+	ResourceTarget   string                `json:"resource_target"`
+	Action           database.AuditAction  `json:"action"`
+	Diff             json.RawMessage       `json:"diff"`
+	StatusCode       int32                 `json:"status_code"`
+	AdditionalFields json.RawMessage       `json:"additional_fields"`
+	RequestID        uuid.UUID             `json:"request_id"`
+}
+
+// MarshalLog returns the JSON of the ExportedLog of an audit log.
+func MarshalLog(alog database.AuditLog) ([]byte, error) {
+	log := ExportedLog{
+		ID:               alog.ID,
+		Time:             alog.Time,
+		UserID:           alog.UserID,
+		OrganizationID:   alog.OrganizationID,
+		UserAgent:        alog.UserAgent.String,
+		ResourceType:     alog.ResourceType,
+		ResourceID:       alog.ResourceID,
+		ResourceTarget:   alog.ResourceTarget,
+		Action:           alog.Action,
+		Diff:             alog.Diff,
+		StatusCode:       alog.StatusCode,
+		AdditionalFields: alog.AdditionalFields,
+		RequestID:        alog.RequestID,
+	}
+	if alog.Ip.Valid {
+		log.IP = alog.Ip.IPNet.IP.String()
+	}
+	if len(log.Diff) == 0 {
+		log.Diff = json.RawMessage("{}")
+	}
+	if len(log.AdditionalFields) == 0 {
+		log.AdditionalFields = json.RawMessage("{}")
+	}
+	return json.Marshal(log)
+}
+
+// ArchiveLogs returns the gzipped JSON lines of the ExportedLogs of audit logs.
+func ArchiveLogs(logs []database.AuditLog) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	for _, alog := range logs {
+		data, err := MarshalLog(alog)
+		if err != nil {
+			return nil, xerrors.Errorf("marshal audit log %s: %w", alog.ID, err)
+		}
+		_, err = writer.Write(append(data, '\n'))
+		if err != nil {
+			return nil, xerrors.Errorf("write audit log %s: %w", alog.ID, err)
+		}
+	}
+	err := writer.Close()
+	if err != nil {
+		return nil, xerrors.Errorf("close gzip writer: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ReadArchive calls fn with the JSON line and ExportedLog of every audit log
+// in an archive returned by ArchiveLogs, in order.
+func ReadArchive(archive io.Reader, fn func(line []byte, log ExportedLog) error) error {
+	reader, err := gzip.NewReader(archive)
+	if err != nil {
+		return xerrors.Errorf("open gzip reader: %w", err)
+	}
+	defer reader.Close()
+	scanner := bufio.NewScanner(reader)
+	// Diffs can make audit logs much longer than the default token size.
+	scanner.Buffer(make([]byte, 64<<10), 16<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var log ExportedLog
+		err = json.Unmarshal(line, &log)
+		if err != nil {
+			return xerrors.Errorf("unmarshal audit log: %w", err)
+		}
+		err = fn(line, log)
+		if err != nil {
+			return err
+		}
+	}
+	err = scanner.Err()
+	if err != nil {
+		return xerrors.Errorf("read archive: %w", err)
+	}
+	return nil
+}
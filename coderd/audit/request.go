@@ -16,6 +16,7 @@ import (
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/httpmw"
 	"github.com/coder/coder/coderd/tracing"
+	"github.com/coder/coder/codersdk"
 )
 
 type RequestParams struct {
@@ -52,6 +53,26 @@ type BuildAuditParams[T Auditable] struct {
 	Old T
 }
 
+// ConnectionAuditParams are the parameters of a connect or disconnect audit
+// log for a workspace.
+type ConnectionAuditParams struct {
+	Audit Auditor
+	Log   slog.Logger
+
+	// Action must be database.AuditActionConnect or
+	// database.AuditActionDisconnect.
+	Action database.AuditAction
+	// ConnectionID is stored as the request ID, so a disconnect can be
+	// matched with its connect.
+	ConnectionID uuid.UUID
+	UserID       uuid.UUID
+	Workspace    database.Workspace
+	// RemoteAddr is the address of the client, with or without a port.
+	RemoteAddr string
+	UserAgent  string
+	Fields     codersdk.ConnectionAuditFields
+}
+
 func ResourceTarget[T Auditable](tgt T) string {
 	switch typed := any(tgt).(type) {
 	case database.Template:
@@ -74,6 +95,13 @@ func ResourceTarget[T Auditable](tgt T) string {
 		return ""
 	case database.License:
 		return strconv.Itoa(int(typed.ID))
+	case database.Organization:
+		return typed.Name
+	case database.AuditableOrganizationMember:
+		return typed.Username
+	case database.AuditableAppearance:
+		// this isn't used
+		return ""
 	default:
 		panic(fmt.Sprintf("unknown resource %T", tgt))
 	}
@@ -99,6 +127,12 @@ func ResourceID[T Auditable](tgt T) uuid.UUID {
 		return typed.UserID
 	case database.License:
 		return typed.UUID
+	case database.Organization:
+		return typed.ID
+	case database.AuditableOrganizationMember:
+		return typed.UserID
+	case database.AuditableAppearance:
+		return typed.DeploymentID
 	default:
 		panic(fmt.Sprintf("unknown resource %T", tgt))
 	}
@@ -124,6 +158,12 @@ func ResourceType[T Auditable](tgt T) database.ResourceType {
 		return database.ResourceTypeApiKey
 	case database.License:
 		return database.ResourceTypeLicense
+	case database.Organization:
+		return database.ResourceTypeOrganization
+	case database.AuditableOrganizationMember:
+		return database.ResourceTypeOrganizationMember
+	case database.AuditableAppearance:
+		return database.ResourceTypeAppearance
 	default:
 		panic(fmt.Sprintf("unknown resource %T", tgt))
 	}
@@ -254,6 +294,46 @@ func BuildAudit[T Auditable](ctx context.Context, p *BuildAuditParams[T]) {
 	}
 }
 
+// ConnectionAudit creates an audit log for a connection to a workspace.
+// The audit log is committed upon invocation.
+func ConnectionAudit(ctx context.Context, p *ConnectionAuditParams) {
+	additionalFields, err := json.Marshal(p.Fields)
+	if err != nil {
+		p.Log.Warn(ctx, "marshal connection fields", slog.Error(err))
+		additionalFields = []byte("{}")
+	}
+
+	remoteAddr := p.RemoteAddr
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		remoteAddr = host
+	}
+
+	auditLog := database.AuditLog{
+		ID:               uuid.New(),
+		Time:             database.Now(),
+		UserID:           p.UserID,
+		OrganizationID:   p.Workspace.OrganizationID,
+		Ip:               parseIP(remoteAddr),
+		UserAgent:        sql.NullString{String: p.UserAgent, Valid: p.UserAgent != ""},
+		ResourceType:     database.ResourceTypeWorkspace,
+		ResourceID:       p.Workspace.ID,
+		ResourceTarget:   p.Workspace.Name,
+		Action:           p.Action,
+		Diff:             []byte("{}"),
+		StatusCode:       http.StatusOK,
+		RequestID:        p.ConnectionID,
+		AdditionalFields: additionalFields,
+	}
+	err = p.Audit.Export(ctx, auditLog)
+	if err != nil {
+		p.Log.Error(ctx, "export audit log",
+			slog.F("audit_log", auditLog),
+			slog.Error(err),
+		)
+		return
+	}
+}
+
 func either[T Auditable, R any](old, new T, fn func(T) R, auditAction database.AuditAction) R {
 	if ResourceID(new) != uuid.Nil {
 		return fn(new)
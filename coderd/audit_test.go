@@ -2,18 +2,29 @@ package coderd_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
 	"strconv"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"github.com/tabbed/pqtype"
 
 	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/coderd/database/dbtestutil"
+	"github.com/coder/coder/coderd/filestore"
 	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
 )
 
 func TestAuditLogs(t *testing.T) {
@@ -86,6 +97,216 @@ func TestAuditLogs(t *testing.T) {
 	})
 }
 
+func TestExportAuditLogs(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	db, pubsub := dbtestutil.NewDB(t)
+	store, err := filestore.NewFS(t.TempDir())
+	require.NoError(t, err)
+	client := coderdtest.New(t, &coderdtest.Options{
+		Database:  db,
+		Pubsub:    pubsub,
+		FileStore: store,
+	})
+	_ = coderdtest.CreateFirstUser(t, client)
+
+	start := database.Now().Add(-time.Hour)
+	archived := []database.AuditLog{
+		{ID: uuid.New(), Time: start},
+		{ID: uuid.New(), Time: start.Add(time.Minute)},
+	}
+	data, err := audit.ArchiveLogs(archived)
+	require.NoError(t, err)
+	hash := sha256.Sum256(data)
+	err = store.Put(ctx, hex.EncodeToString(hash[:]), data)
+	require.NoError(t, err)
+	_, err = db.InsertAuditLogArchive(ctx, database.InsertAuditLogArchiveParams{
+		ID:        uuid.New(),
+		CreatedAt: database.Now(),
+		Oldest:    archived[0].Time,
+		Newest:    archived[1].Time,
+		LogCount:  int32(len(archived)),
+		Storage:   store.Backend(),
+		Hash:      hex.EncodeToString(hash[:]),
+		Size:      int64(len(data)),
+	})
+	require.NoError(t, err)
+	stored := dbgen.AuditLog(t, db, database.AuditLog{Time: start.Add(2 * time.Minute)})
+
+	export := func(since, until time.Time) []uuid.UUID {
+		t.Helper()
+		body, err := client.ExportAuditLogs(ctx, since, until)
+		require.NoError(t, err)
+		defer body.Close()
+		ids := []uuid.UUID{}
+		decoder := json.NewDecoder(body)
+		for decoder.More() {
+			var log audit.ExportedLog
+			require.NoError(t, decoder.Decode(&log))
+			ids = append(ids, log.ID)
+		}
+		return ids
+	}
+
+	// Archived audit logs are exported before the ones in the database.
+	ids := export(time.Time{}, time.Time{})
+	require.Equal(t, []uuid.UUID{archived[0].ID, archived[1].ID, stored.ID}, ids)
+	// The time range applies to archived audit logs too.
+	ids = export(start.Add(30*time.Second), start.Add(2*time.Minute))
+	require.Equal(t, []uuid.UUID{archived[1].ID}, ids)
+
+	_, err = client.ExportAuditLogs(ctx, start, start)
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+}
+
+func TestAuditLogsCSV(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	db, pubsub := dbtestutil.NewDB(t)
+	client := coderdtest.New(t, &coderdtest.Options{
+		Database: db,
+		Pubsub:   pubsub,
+	})
+	_ = coderdtest.CreateFirstUser(t, client)
+
+	inet := func(ip string) pqtype.Inet {
+		return pqtype.Inet{
+			IPNet: net.IPNet{IP: net.ParseIP(ip).To4(), Mask: net.CIDRMask(32, 32)},
+			Valid: true,
+		}
+	}
+	now := database.Now()
+	internal := dbgen.AuditLog(t, db, database.AuditLog{
+		Time: now.Add(-2 * time.Minute),
+		Ip:   inet("10.1.2.3"),
+		Diff: []byte(`{"name":{"old":"before","new":"after"}}`),
+	})
+	other := dbgen.AuditLog(t, db, database.AuditLog{
+		Time: now.Add(-time.Minute),
+		Ip:   inet("10.4.5.6"),
+	})
+	_ = dbgen.AuditLog(t, db, database.AuditLog{
+		Time: now.Add(-time.Minute),
+		Ip:   inet("192.168.0.1"),
+	})
+	formula := dbgen.AuditLog(t, db, database.AuditLog{
+		Time:           now.Add(-3 * time.Minute),
+		ResourceTarget: "=1+1",
+		Diff:           []byte(`{"name":{"old":"a_c","new":"d"}}`),
+	})
+	_ = dbgen.AuditLog(t, db, database.AuditLog{
+		Time: now.Add(-3 * time.Minute),
+		Diff: []byte(`{"name":{"old":"abc","new":"d"}}`),
+	})
+	mixedCase := dbgen.AuditLog(t, db, database.AuditLog{
+		Time:           now.Add(-4 * time.Minute),
+		ResourceTarget: "MyTemplate",
+	})
+
+	exportCSV := func(q string) [][]string {
+		t.Helper()
+		body, err := client.AuditLogsCSV(ctx, q)
+		require.NoError(t, err)
+		defer body.Close()
+		records, err := csv.NewReader(body).ReadAll()
+		require.NoError(t, err)
+		require.NotEmpty(t, records)
+		require.Equal(t, "time", records[0][0])
+		return records[1:]
+	}
+
+	// Newest first, and only the range.
+	records := exportCSV("ip:10.0.0.0/8")
+	require.Len(t, records, 2)
+	require.Equal(t, other.ID.String(), records[0][1])
+	require.Equal(t, internal.ID.String(), records[1][1])
+	require.Equal(t, "10.1.2.3", records[1][6])
+
+	records = exportCSV("request_id:" + internal.RequestID.String())
+	require.Len(t, records, 1)
+	require.Equal(t, internal.ID.String(), records[0][1])
+
+	records = exportCSV("before")
+	require.Len(t, records, 1)
+	require.Equal(t, internal.ID.String(), records[0][1])
+
+	// Cells that would be evaluated as a formula are escaped.
+	records = exportCSV("request_id:" + formula.RequestID.String())
+	require.Len(t, records, 1)
+	require.Equal(t, "'=1+1", records[0][11])
+
+	// Wildcards in the search are matched literally.
+	records = exportCSV("a_c")
+	require.Len(t, records, 1)
+	require.Equal(t, formula.ID.String(), records[0][1])
+
+	// Resource targets match regardless of their case.
+	records = exportCSV("resource_target:MyTemplate")
+	require.Len(t, records, 1)
+	require.Equal(t, mixedCase.ID.String(), records[0][1])
+	res, err := client.AuditLogs(ctx, codersdk.AuditLogsRequest{
+		SearchQuery: "resource_target:MyTemplate",
+		Pagination:  codersdk.Pagination{Limit: 10},
+	})
+	require.NoError(t, err)
+	require.Len(t, res.AuditLogs, 1)
+	require.Equal(t, mixedCase.ID, res.AuditLogs[0].ID)
+
+	_, err = client.AuditLogsCSV(ctx, "ip:10.0.0.0/64")
+	var apiErr *codersdk.Error
+	require.ErrorAs(t, err, &apiErr)
+	require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+}
+
+func TestAuditLogsCSVPaging(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	db, pubsub := dbtestutil.NewDB(t)
+	client := coderdtest.New(t, &coderdtest.Options{
+		Database: db,
+		Pubsub:   pubsub,
+	})
+	_ = coderdtest.CreateFirstUser(t, client)
+
+	// More audit logs than fit in a single batch of the export, all with the
+	// same time, so they must be paged through by their ID.
+	now := database.Now()
+	ids := map[string]bool{}
+	for i := 0; i < 1001; i++ {
+		alog := dbgen.AuditLog(t, db, database.AuditLog{
+			Time: now,
+		})
+		ids[alog.ID.String()] = true
+	}
+
+	body, err := client.AuditLogsCSV(ctx, "")
+	require.NoError(t, err)
+	defer body.Close()
+	records, err := csv.NewReader(body).ReadAll()
+	require.NoError(t, err)
+
+	exported := map[string]bool{}
+	for _, record := range records[1:] {
+		if !ids[record[1]] {
+			continue
+		}
+		require.False(t, exported[record[1]], "audit log exported twice")
+		exported[record[1]] = true
+	}
+	require.Len(t, exported, len(ids))
+}
+
 func TestAuditLogsFilter(t *testing.T) {
 	t.Parallel()
 
@@ -189,8 +410,28 @@ func TestAuditLogsFilter(t *testing.T) {
 				ExpectedResult: 2,
 			},
 			{
-				Name:          "FilterInvalidSingleValue",
-				SearchQuery:   "invalid",
+				Name:           "FilterByResourceTarget",
+				SearchQuery:    "resource_target:" + coderdtest.FirstUserParams.Username,
+				ExpectedResult: 5,
+			},
+			{
+				Name:           "FilterByFreeText",
+				SearchQuery:    "baz",
+				ExpectedResult: 5,
+			},
+			{
+				Name:           "FilterByFreeTextNoMatch",
+				SearchQuery:    "invalid",
+				ExpectedResult: 0,
+			},
+			{
+				Name:           "FilterByFreeTextAndResourceType",
+				SearchQuery:    "user baz",
+				ExpectedResult: 2,
+			},
+			{
+				Name:          "FilterWithInvalidIP",
+				SearchQuery:   "ip:invalid",
 				ExpectedError: true,
 			},
 			{
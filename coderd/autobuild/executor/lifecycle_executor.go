@@ -13,16 +13,23 @@ import (
 	"github.com/coder/coder/coderd/autobuild/schedule"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/notifications"
 	"github.com/coder/coder/coderd/provisionerdserver"
+	"github.com/coder/coder/codersdk"
 )
 
+// autostopNotifyBefore is how long before its deadline the owner of a
+// workspace is warned that it will be stopped.
+const autostopNotifyBefore = 30 * time.Minute
+
 // Executor automatically starts or stops workspaces.
 type Executor struct {
-	ctx     context.Context
-	db      database.Store
-	log     slog.Logger
-	tick    <-chan time.Time
-	statsCh chan<- Stats
+	ctx      context.Context
+	db       database.Store
+	log      slog.Logger
+	tick     <-chan time.Time
+	statsCh  chan<- Stats
+	notifier *notifications.Notifier
 }
 
 // Stats contains information about one run of Executor.
@@ -51,6 +58,13 @@ func (e *Executor) WithStatsChannel(ch chan<- Stats) *Executor {
 	return e
 }
 
+// WithNotifier will cause Executor to email workspace owners before their
+// workspaces are stopped.
+func (e *Executor) WithNotifier(n *notifications.Notifier) *Executor {
+	e.notifier = n
+	return e
+}
+
 // Run will cause executor to start or stop workspaces on every
 // tick from its channel. It will stop when its context is Done, or when
 // its channel is closed.
@@ -161,6 +175,9 @@ func (e *Executor) runOnce(t time.Time) Stats {
 				}
 
 				if currentTick.Before(nextTransition) {
+					if validTransition == database.WorkspaceTransitionStop && nextTransition.Sub(currentTick) <= autostopNotifyBefore {
+						e.notifyAutostop(ws, priorHistory)
+					}
 					log.Debug(e.ctx, "skipping workspace: too early",
 						slog.F("next_transition_at", nextTransition),
 						slog.F("transition", validTransition),
@@ -198,6 +215,29 @@ func (e *Executor) runOnce(t time.Time) Stats {
 	return stats
 }
 
+// notifyAutostop warns the owner that the workspace will be stopped at the
+// deadline of the build. The owner is only warned once per build, even if
+// the deadline is extended. The deadline is shown in the time zone of the
+// autostart schedule, which is the owner's, or in UTC without a schedule.
+func (e *Executor) notifyAutostop(ws database.Workspace, build database.WorkspaceBuild) {
+	if e.notifier == nil {
+		return
+	}
+	location := time.UTC
+	if sched, err := schedule.Weekly(ws.AutostartSchedule.String); err == nil {
+		location = sched.Location()
+	}
+	e.notifier.Enqueue(notifications.Notification{
+		UserID:    ws.OwnerID,
+		Kind:      codersdk.NotificationKindWorkspaceAutostop,
+		DedupeKey: build.ID.String(),
+		Data: map[string]string{
+			"workspace_name": ws.Name,
+			"deadline":       build.Deadline.In(location).Format("Jan 2, 15:04 MST"),
+		},
+	})
+}
+
 func isEligibleForAutoStartStop(ws database.Workspace) bool {
 	return !ws.Deleted && (ws.AutostartSchedule.String != "" || ws.Ttl.Int64 > 0)
 }
@@ -287,6 +327,7 @@ func build(ctx context.Context, store database.Store, workspace database.Workspa
 			FileID:         priorJob.FileID,
 			Tags:           priorJob.Tags,
 			Input:          input,
+			Priority:       database.ProvisionerJobPriorityAutomatic,
 		})
 		if err != nil {
 			return xerrors.Errorf("insert provisioner job: %w", err)
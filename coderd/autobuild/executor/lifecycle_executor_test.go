@@ -14,10 +14,13 @@ import (
 	"github.com/coder/coder/coderd/autobuild/schedule"
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/notifications"
+	"github.com/coder/coder/coderd/notifications/notificationstest"
 	"github.com/coder/coder/coderd/util/ptr"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/provisioner/echo"
 	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -429,6 +432,61 @@ func TestExecutorWorkspaceAutostopBeforeDeadline(t *testing.T) {
 	assert.Len(t, stats.Transitions, 0)
 }
 
+func TestExecutorWorkspaceAutostopWarning(t *testing.T) {
+	t.Parallel()
+
+	smtp := notificationstest.NewSMTPServer(t)
+	sender, err := notifications.NewSMTPSender(notifications.SMTPOptions{
+		Smarthost: smtp.Addr(),
+		From:      "notifications@coder.com",
+	})
+	require.NoError(t, err)
+
+	var (
+		tickCh  = make(chan time.Time)
+		statsCh = make(chan executor.Stats)
+		client  = coderdtest.New(t, &coderdtest.Options{
+			AutobuildTicker:          tickCh,
+			IncludeProvisionerDaemon: true,
+			AutobuildStats:           statsCh,
+			NotificationSender:       sender,
+		})
+		// Given: we have a user with a workspace that starts in their time zone
+		sched     = mustSchedule(t, "CRON_TZ=Asia/Tokyo 0 9 * * 1-5")
+		workspace = mustProvisionWorkspace(t, client, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.AutostartSchedule = ptr.Ref(sched.String())
+		})
+		deadline = workspace.LatestBuild.Deadline.Time
+	)
+
+	// When: the autobuild executor ticks twice shortly before the TTL
+	go func() {
+		tickCh <- deadline.Add(-20 * time.Minute)
+		tickCh <- deadline.Add(-10 * time.Minute)
+		close(tickCh)
+	}()
+
+	// Then: the workspace is not stopped
+	for i := 0; i < 2; i++ {
+		stats := <-statsCh
+		assert.NoError(t, stats.Error)
+		assert.Len(t, stats.Transitions, 0)
+	}
+
+	// And: the owner is warned once
+	select {
+	case email := <-smtp.Emails():
+		require.Equal(t, "Workspace "+workspace.Name+" will stop at "+deadline.In(sched.Location()).Format("Jan 2, 15:04 MST"), email.Subject)
+	case <-time.After(testutil.WaitShort):
+		t.Fatal("timed out waiting for autostop warning")
+	}
+	select {
+	case email := <-smtp.Emails():
+		t.Fatalf("unexpected email %q", email.Subject)
+	case <-time.After(testutil.IntervalMedium):
+	}
+}
+
 func TestExecutorWorkspaceAutostopNoWaitChangedMyMind(t *testing.T) {
 	t.Parallel()
 
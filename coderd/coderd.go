@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -44,17 +45,23 @@ import (
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/database/dbtype"
+	"github.com/coder/coder/coderd/dbpurge"
+	"github.com/coder/coder/coderd/filestore"
 	"github.com/coder/coder/coderd/gitauth"
+	"github.com/coder/coder/coderd/gitsource"
 	"github.com/coder/coder/coderd/gitsshkey"
+	"github.com/coder/coder/coderd/gitsync"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
 	"github.com/coder/coder/coderd/metricscache"
+	"github.com/coder/coder/coderd/notifications"
 	"github.com/coder/coder/coderd/provisionerdserver"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/telemetry"
 	"github.com/coder/coder/coderd/tracing"
 	"github.com/coder/coder/coderd/updatecheck"
 	"github.com/coder/coder/coderd/util/slice"
+	"github.com/coder/coder/coderd/webhooks"
 	"github.com/coder/coder/coderd/wsconncache"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/provisionerd/proto"
@@ -133,6 +140,30 @@ type Options struct {
 	UpdateCheckOptions          *updatecheck.Options // Set non-nil to enable update checking.
 
 	HTTPClient *http.Client
+	// Notifier emails users. If nil, email notifications are disabled.
+	Notifier *notifications.Notifier
+	// TemplateVersionGCInterval is how often the files and job logs of
+	// archived template versions are deleted. Zero disables it.
+	TemplateVersionGCInterval time.Duration
+	// ProvisionerJobLogRetention is how long the logs of workspace builds
+	// that aren't the latest build of their workspace are kept. Zero keeps
+	// them forever.
+	ProvisionerJobLogRetention time.Duration
+	// AuditLogRetention is how long audit logs are kept. Zero keeps them
+	// forever.
+	AuditLogRetention time.Duration
+	// AuditLogArchive archives audit logs to the FileStore before they're
+	// deleted after the retention.
+	AuditLogArchive bool
+	// AuditLogPurgeBatchSize is how many audit logs are archived and deleted
+	// at once.
+	AuditLogPurgeBatchSize int
+	// TemplateGitSyncInterval is how often templates that are synced with
+	// a git branch are checked for new commits. Zero disables git syncs.
+	TemplateGitSyncInterval time.Duration
+	// FileStore stores the contents of uploaded files and template archives.
+	// If nil, contents are stored in the database.
+	FileStore filestore.Store
 }
 
 // @title Coder API
@@ -206,6 +237,13 @@ func New(options *Options) *API {
 			options.Logger.Named("authz_querier"),
 		)
 	}
+	if options.Notifier == nil {
+		options.Notifier = notifications.New(notifications.Options{
+			Database:  options.Database,
+			Logger:    options.Logger.Named("notifications"),
+			AccessURL: options.AccessURL,
+		})
+	}
 	if options.SetUserGroups == nil {
 		options.SetUserGroups = func(context.Context, database.Store, uuid.UUID, []string) error { return nil }
 	}
@@ -245,9 +283,11 @@ func New(options *Options) *API {
 			Authorizer: options.Authorizer,
 			Logger:     options.Logger,
 		},
-		metricsCache: metricsCache,
-		Auditor:      atomic.Pointer[audit.Auditor]{},
-		Experiments:  experiments,
+		metricsCache:   metricsCache,
+		Auditor:        atomic.Pointer[audit.Auditor]{},
+		Experiments:    experiments,
+		appConnections: newAppConnectionTracker(),
+		tailnetClients: newTailnetClientTracker(),
 	}
 	if options.UpdateCheckOptions != nil {
 		api.updateChecker = updatecheck.New(
@@ -256,7 +296,44 @@ func New(options *Options) *API {
 			*options.UpdateCheckOptions,
 		)
 	}
+	var auditLogArchive filestore.Store
+	if options.AuditLogArchive {
+		auditLogArchive = options.FileStore
+	}
+	api.purger = dbpurge.New(dbpurge.Options{
+		Database:          options.Database,
+		FileStore:         options.FileStore,
+		Logger:            options.Logger.Named("dbpurge"),
+		Interval:          options.TemplateVersionGCInterval,
+		JobLogRetention:   options.ProvisionerJobLogRetention,
+		AuditLogRetention: options.AuditLogRetention,
+		AuditLogArchive:   auditLogArchive,
+		AuditLogBatchSize: options.AuditLogPurgeBatchSize,
+	})
 	api.Auditor.Store(&options.Auditor)
+	if options.TemplateGitSyncInterval > 0 {
+		api.gitSyncer = gitsync.New(gitsync.Options{
+			Database:  options.Database,
+			Pubsub:    options.Pubsub,
+			Auditor:   &api.Auditor,
+			FileStore: options.FileStore,
+			Logger:    options.Logger.Named("gitsync"),
+			Interval:  options.TemplateGitSyncInterval,
+			Credentials: func(ctx context.Context, userID uuid.UUID, opts *gitsource.Options) error {
+				_, err := api.gitSourceCredentials(ctx, userID, opts)
+				return err
+			},
+		})
+	}
+	api.webhookDispatcher, err = webhooks.New(webhooks.Options{
+		Database:   options.Database,
+		Pubsub:     options.Pubsub,
+		Logger:     options.Logger.Named("webhooks"),
+		HTTPClient: options.HTTPClient,
+	})
+	if err != nil {
+		panic(xerrors.Errorf("start webhook dispatcher: %w", err))
+	}
 	api.workspaceAgentCache = wsconncache.New(api.dialWorkspaceAgentTailnet, 0)
 	api.TailnetCoordinator.Store(&options.TailnetCoordinator)
 	oauthConfigs := &httpmw.OAuth2Configs{
@@ -413,6 +490,8 @@ func New(options *Options) *API {
 			)
 
 			r.Get("/", api.auditLogs)
+			r.Get("/export", api.exportAuditLogs)
+			r.Get("/csv", api.auditLogsCSV)
 			r.Post("/testgenerate", api.generateFakeAuditLog)
 		})
 		r.Route("/files", func(r chi.Router) {
@@ -457,6 +536,10 @@ func New(options *Options) *API {
 						r.Post("/workspaces", api.postWorkspacesByOrganization)
 					})
 				})
+				r.Route("/webhooks", func(r chi.Router) {
+					r.Get("/", api.organizationWebhooks)
+					r.Post("/", api.postOrganizationWebhook)
+				})
 			})
 		})
 		r.Route("/parameters/{scope}/{id}", func(r chi.Router) {
@@ -468,18 +551,29 @@ func New(options *Options) *API {
 			})
 		})
 		r.Route("/templates/{template}", func(r chi.Router) {
-			r.Use(
-				apiKeyMiddleware,
-				httpmw.ExtractTemplateParam(options.Database),
-			)
-			r.Get("/daus", api.templateDAUs)
-			r.Get("/", api.template)
-			r.Delete("/", api.deleteTemplate)
-			r.Patch("/", api.patchTemplateMeta)
-			r.Route("/versions", func(r chi.Router) {
-				r.Get("/", api.templateVersionsByTemplate)
-				r.Patch("/", api.patchActiveTemplateVersion)
-				r.Get("/{templateversionname}", api.templateVersionByName)
+			// Git push webhooks are authenticated with the secret of the
+			// template's git sync.
+			r.Post("/git-sync/webhook", api.postTemplateGitSyncWebhook)
+			r.Group(func(r chi.Router) {
+				r.Use(
+					apiKeyMiddleware,
+					httpmw.ExtractTemplateParam(options.Database),
+				)
+				r.Get("/daus", api.templateDAUs)
+				r.Get("/insights", api.templateInsights)
+				r.Get("/", api.template)
+				r.Delete("/", api.deleteTemplate)
+				r.Patch("/", api.patchTemplateMeta)
+				r.Put("/deprecation", api.putTemplateDeprecation)
+				r.Get("/git-sync", api.templateGitSync)
+				r.Put("/git-sync", api.putTemplateGitSync)
+				r.Delete("/git-sync", api.deleteTemplateGitSync)
+				r.Route("/versions", func(r chi.Router) {
+					r.Get("/", api.templateVersionsByTemplate)
+					r.Patch("/", api.patchActiveTemplateVersion)
+					r.Post("/archive", api.postArchiveTemplateVersions)
+					r.Get("/{templateversionname}", api.templateVersionByName)
+				})
 			})
 		})
 		r.Route("/templateversions/{templateversion}", func(r chi.Router) {
@@ -489,6 +583,8 @@ func New(options *Options) *API {
 			)
 			r.Get("/", api.templateVersion)
 			r.Patch("/cancel", api.patchCancelTemplateVersion)
+			r.Post("/archive", api.postArchiveTemplateVersion)
+			r.Post("/unarchive", api.postUnarchiveTemplateVersion)
 			r.Get("/schema", api.templateVersionSchema)
 			r.Get("/parameters", api.templateVersionParameters)
 			r.Get("/rich-parameters", api.templateVersionRichParameters)
@@ -576,6 +672,10 @@ func New(options *Options) *API {
 					})
 					r.Get("/gitsshkey", api.gitSSHKey)
 					r.Put("/gitsshkey", api.regenerateGitSSHKey)
+					r.Route("/notifications/preferences", func(r chi.Router) {
+						r.Get("/", api.notificationPreferences)
+						r.Put("/", api.putNotificationPreferences)
+					})
 				})
 			})
 		})
@@ -593,6 +693,7 @@ func New(options *Options) *API {
 				r.Get("/coordinate", api.workspaceAgentCoordinate)
 				r.Post("/report-stats", api.workspaceAgentReportStats)
 				r.Post("/report-lifecycle", api.workspaceAgentReportLifecycle)
+				r.Post("/report-connection", api.workspaceAgentReportConnection)
 			})
 			r.Route("/{workspaceagent}", func(r chi.Router) {
 				r.Use(
@@ -630,6 +731,24 @@ func New(options *Options) *API {
 				})
 				r.Get("/watch", api.watchWorkspace)
 				r.Put("/extend", api.putExtendWorkspace)
+				r.Post("/transfer", api.postWorkspaceTransfer)
+				r.Post("/clone", api.postWorkspaceClone)
+				r.Route("/acl", func(r chi.Router) {
+					r.Get("/", api.workspaceACL)
+					r.Patch("/", api.patchWorkspaceACL)
+				})
+			})
+		})
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Use(apiKeyMiddleware)
+			r.Get("/", api.deploymentWebhooks)
+			r.Post("/", api.postDeploymentWebhook)
+			r.Route("/{webhook}", func(r chi.Router) {
+				r.Use(httpmw.ExtractWebhookParam(options.Database))
+				r.Get("/", api.webhook)
+				r.Patch("/", api.patchWebhook)
+				r.Delete("/", api.deleteWebhook)
+				r.Get("/deliveries", api.webhookDeliveries)
 			})
 		})
 		r.Route("/workspacebuilds/{workspacebuild}", func(r chi.Router) {
@@ -667,6 +786,7 @@ func New(options *Options) *API {
 		r.Route("/insights", func(r chi.Router) {
 			r.Use(apiKeyMiddleware)
 			r.Get("/daus", api.deploymentDAUs)
+			r.Get("/users", api.userInsights)
 		})
 		r.Route("/debug", func(r chi.Router) {
 			r.Use(
@@ -734,6 +854,11 @@ type API struct {
 	metricsCache        *metricscache.Cache
 	workspaceAgentCache *wsconncache.Cache
 	updateChecker       *updatecheck.Checker
+	webhookDispatcher   *webhooks.Dispatcher
+	purger              *dbpurge.Purger
+	gitSyncer           *gitsync.Syncer
+	appConnections      *appConnectionTracker
+	tailnetClients      *tailnetClientTracker
 
 	// Experiments contains the list of experiments currently enabled.
 	// This is used to gate features that are not yet ready for production.
@@ -753,6 +878,14 @@ func (api *API) Close() error {
 	if api.updateChecker != nil {
 		api.updateChecker.Close()
 	}
+	if api.purger != nil {
+		_ = api.purger.Close()
+	}
+	if api.gitSyncer != nil {
+		_ = api.gitSyncer.Close()
+	}
+	_ = api.webhookDispatcher.Close()
+	_ = api.Notifier.Close()
 	coordinator := api.TailnetCoordinator.Load()
 	if coordinator != nil {
 		_ = (*coordinator).Close()
@@ -792,14 +925,17 @@ func (api *API) CreateInMemoryProvisionerDaemon(ctx context.Context, debounce ti
 	}()
 
 	name := namesgenerator.GetRandomName(1)
+	now := database.Now()
 	daemon, err := api.Database.InsertProvisionerDaemon(ctx, database.InsertProvisionerDaemonParams{
 		ID:           uuid.New(),
-		CreatedAt:    database.Now(),
+		CreatedAt:    now,
 		Name:         name,
 		Provisioners: []database.ProvisionerType{database.ProvisionerTypeEcho, database.ProvisionerTypeTerraform},
 		Tags: dbtype.StringMap{
 			provisionerdserver.TagScope: provisionerdserver.ScopeOrganization,
 		},
+		LastSeenAt: sql.NullTime{Time: now, Valid: true},
+		Version:    buildinfo.Version(),
 	})
 	if err != nil {
 		return nil, xerrors.Errorf("insert provisioner daemon %q: %w", name, err)
@@ -827,6 +963,8 @@ func (api *API) CreateInMemoryProvisionerDaemon(ctx context.Context, debounce ti
 		Tags:               tags,
 		QuotaCommitter:     &api.QuotaCommitter,
 		Auditor:            &api.Auditor,
+		Notifier:           api.Notifier,
+		FileStore:          api.FileStore,
 		AcquireJobDebounce: debounce,
 		Logger:             api.Logger.Named(fmt.Sprintf("provisionerd-%s", daemon.Name)),
 	})
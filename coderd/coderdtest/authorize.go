@@ -35,6 +35,7 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 	workspaceExecObj := rbac.ResourceWorkspaceExecution.WithID(a.Workspace.ID).InOrg(a.Organization.ID).WithOwner(a.Workspace.OwnerID.String())
 	applicationConnectObj := rbac.ResourceWorkspaceApplicationConnect.WithID(a.Workspace.ID).InOrg(a.Organization.ID).WithOwner(a.Workspace.OwnerID.String())
 	templateObj := rbac.ResourceTemplate.WithID(a.Template.ID).InOrg(a.Template.OrganizationID)
+	webhookObj := rbac.ResourceWebhook.WithID(a.Webhook.ID).InOrg(a.Organization.ID)
 
 	// skipRoutes allows skipping routes from being checked.
 	skipRoutes := map[string]string{
@@ -74,6 +75,10 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 		"POST:/api/v2/workspaceagents/me/app-health":            {NoAuthorize: true},
 		"POST:/api/v2/workspaceagents/me/report-stats":          {NoAuthorize: true},
 		"POST:/api/v2/workspaceagents/me/report-lifecycle":      {NoAuthorize: true},
+		"POST:/api/v2/workspaceagents/me/report-connection":     {NoAuthorize: true},
+
+		// Git push webhooks are authenticated with the secret of the sync.
+		"POST:/api/v2/templates/{template}/git-sync/webhook": {NoAuthorize: true},
 
 		// These endpoints have more assertions. This is good, add more endpoints to assert if you can!
 		"GET:/api/v2/organizations/{organization}": {AssertObject: rbac.ResourceOrganization.WithID(a.Admin.OrganizationID).InOrg(a.Admin.OrganizationID)},
@@ -119,6 +124,22 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 			AssertAction: rbac.ActionUpdate,
 			AssertObject: workspaceRBACObj,
 		},
+		"GET:/api/v2/workspaces/{workspace}/acl": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: workspaceRBACObj,
+		},
+		"PATCH:/api/v2/workspaces/{workspace}/acl": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: workspaceRBACObj,
+		},
+		"POST:/api/v2/workspaces/{workspace}/transfer": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: workspaceRBACObj,
+		},
+		"POST:/api/v2/workspaces/{workspace}/clone": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: workspaceRBACObj,
+		},
 		"PATCH:/api/v2/workspacebuilds/{workspacebuild}/cancel": {
 			AssertAction: rbac.ActionUpdate,
 			AssertObject: workspaceRBACObj,
@@ -155,6 +176,26 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 			AssertAction: rbac.ActionRead,
 			AssertObject: templateObj,
 		},
+		"PUT:/api/v2/templates/{template}/deprecation": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: templateObj,
+		},
+		"GET:/api/v2/templates/{template}/insights": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: templateObj,
+		},
+		"GET:/api/v2/templates/{template}/git-sync": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: templateObj,
+		},
+		"PUT:/api/v2/templates/{template}/git-sync": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: templateObj,
+		},
+		"DELETE:/api/v2/templates/{template}/git-sync": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: templateObj,
+		},
 		"POST:/api/v2/files": {AssertAction: rbac.ActionCreate, AssertObject: rbac.ResourceFile},
 		"GET:/api/v2/files/{fileID}": {
 			AssertAction: rbac.ActionRead,
@@ -168,6 +209,10 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 			AssertAction: rbac.ActionUpdate,
 			AssertObject: templateObj,
 		},
+		"POST:/api/v2/templates/{template}/versions/archive": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: templateObj,
+		},
 		"GET:/api/v2/templates/{template}/versions/{templateversionname}": {
 			AssertAction: rbac.ActionRead,
 			AssertObject: templateObj,
@@ -180,6 +225,14 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 			AssertAction: rbac.ActionUpdate,
 			AssertObject: templateObj,
 		},
+		"POST:/api/v2/templateversions/{templateversion}/archive": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: templateObj,
+		},
+		"POST:/api/v2/templateversions/{templateversion}/unarchive": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: templateObj,
+		},
 		"GET:/api/v2/templateversions/{templateversion}/logs": {
 			AssertAction: rbac.ActionRead,
 			AssertObject: templateObj,
@@ -282,6 +335,33 @@ func AGPLRoutes(a *AuthTester) (map[string]string, map[string]RouteCheck) {
 			AssertAction: rbac.ActionRead,
 			AssertObject: rbac.ResourceDebugInfo,
 		},
+
+		"GET:/api/v2/webhooks":  {AssertAction: rbac.ActionRead, AssertObject: rbac.ResourceWebhook},
+		"POST:/api/v2/webhooks": {AssertAction: rbac.ActionCreate, AssertObject: rbac.ResourceWebhook},
+		"GET:/api/v2/organizations/{organization}/webhooks": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: rbac.ResourceWebhook.InOrg(a.Organization.ID),
+		},
+		"POST:/api/v2/organizations/{organization}/webhooks": {
+			AssertAction: rbac.ActionCreate,
+			AssertObject: rbac.ResourceWebhook.InOrg(a.Organization.ID),
+		},
+		"GET:/api/v2/webhooks/{webhook}": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: webhookObj,
+		},
+		"PATCH:/api/v2/webhooks/{webhook}": {
+			AssertAction: rbac.ActionUpdate,
+			AssertObject: webhookObj,
+		},
+		"DELETE:/api/v2/webhooks/{webhook}": {
+			AssertAction: rbac.ActionDelete,
+			AssertObject: webhookObj,
+		},
+		"GET:/api/v2/webhooks/{webhook}/deliveries": {
+			AssertAction: rbac.ActionRead,
+			AssertObject: webhookObj,
+		},
 	}
 
 	// Routes like proxy routes support all HTTP methods. A helper func to expand
@@ -333,6 +413,7 @@ type AuthTester struct {
 	File                  codersdk.UploadResponse
 	TemplateVersionDryRun codersdk.ProvisionerJob
 	TemplateParam         codersdk.Parameter
+	Webhook               codersdk.Webhook
 	URLParams             map[string]string
 }
 
@@ -401,6 +482,12 @@ func NewAuthTester(ctx context.Context, t *testing.T, client *codersdk.Client, a
 		DestinationScheme: codersdk.ParameterDestinationSchemeProvisionerVariable,
 	})
 	require.NoError(t, err, "create template param")
+	webhook, err := client.CreateOrganizationWebhook(ctx, admin.OrganizationID, codersdk.CreateWebhookRequest{
+		Name:   "test-webhook",
+		URL:    "http://localhost:3000",
+		Events: []codersdk.WebhookEvent{codersdk.WebhookEventBuildFailed},
+	})
+	require.NoError(t, err, "create webhook")
 	urlParameters := map[string]string{
 		"{organization}":        admin.OrganizationID.String(),
 		"{user}":                admin.UserID.String(),
@@ -419,6 +506,7 @@ func NewAuthTester(ctx context.Context, t *testing.T, client *codersdk.Client, a
 		"{templatename}":        template.Name,
 		"{workspace_and_agent}": workspace.Name + "." + workspace.LatestBuild.Resources[0].Agents[0].Name,
 		"{keyid}":               apiKey.ID,
+		"{webhook}":             webhook.ID.String(),
 		// Only checking template scoped params here
 		"parameters/{scope}/{id}": fmt.Sprintf("parameters/%s/%s",
 			string(templateParam.Scope), templateParam.ScopeID.String()),
@@ -438,6 +526,7 @@ func NewAuthTester(ctx context.Context, t *testing.T, client *codersdk.Client, a
 		File:                  file,
 		TemplateVersionDryRun: templateVersionDryRun,
 		TemplateParam:         templateParam,
+		Webhook:               webhook,
 		URLParams:             urlParameters,
 	}
 }
@@ -777,6 +866,7 @@ func randomRBACType() string {
 		rbac.ResourceGroup.Type,
 		rbac.ResourceFile.Type,
 		rbac.ResourceProvisionerDaemon.Type,
+		rbac.ResourceProvisionerKey.Type,
 		rbac.ResourceOrganization.Type,
 		rbac.ResourceRoleAssignment.Type,
 		rbac.ResourceOrgRoleAssignment.Type,
@@ -789,6 +879,7 @@ func randomRBACType() string {
 		rbac.ResourceDeploymentConfig.Type,
 		rbac.ResourceReplicas.Type,
 		rbac.ResourceDebugInfo.Type,
+		rbac.ResourceWebhook.Type,
 	}
 	return all[must(cryptorand.Intn(len(all)))]
 }
@@ -61,10 +61,12 @@ import (
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/database/dbtestutil"
+	"github.com/coder/coder/coderd/filestore"
 	"github.com/coder/coder/coderd/gitauth"
 	"github.com/coder/coder/coderd/gitsshkey"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/notifications"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/telemetry"
 	"github.com/coder/coder/coderd/updatecheck"
@@ -111,6 +113,8 @@ type Options struct {
 	IncludeProvisionerDaemon    bool
 	MetricsCacheRefreshInterval time.Duration
 	AgentStatsRefreshInterval   time.Duration
+	TemplateGitSyncInterval     time.Duration
+	FileStore                   filestore.Store
 	DeploymentConfig            *codersdk.DeploymentConfig
 
 	// Set update check options to enable update check.
@@ -123,6 +127,10 @@ type Options struct {
 	Pubsub   database.Pubsub
 
 	SwaggerEndpoint bool
+
+	// NotificationSender enables email notifications, e.g. an SMTP sender
+	// for a notificationstest.SMTPServer.
+	NotificationSender notifications.Sender
 }
 
 // New constructs a codersdk client connected to an in-memory API instance.
@@ -205,13 +213,6 @@ func NewOptions(t *testing.T, options *Options) (func(http.Handler), context.Can
 	}
 
 	ctx, cancelFunc := context.WithCancel(context.Background())
-	lifecycleExecutor := executor.New(
-		ctx,
-		options.Database,
-		slogtest.Make(t, nil).Named("autobuild.executor").Leveled(slog.LevelDebug),
-		options.AutobuildTicker,
-	).WithStatsChannel(options.AutobuildStats)
-	lifecycleExecutor.Run()
 
 	var mutex sync.RWMutex
 	var handler http.Handler
@@ -251,6 +252,20 @@ func NewOptions(t *testing.T, options *Options) (func(http.Handler), context.Can
 		accessURL = serverURL
 	}
 
+	notifier := notifications.New(notifications.Options{
+		Database:  options.Database,
+		Logger:    slogtest.Make(t, nil).Named("notifications").Leveled(slog.LevelDebug),
+		Sender:    options.NotificationSender,
+		AccessURL: accessURL,
+	})
+	lifecycleExecutor := executor.New(
+		ctx,
+		options.Database,
+		slogtest.Make(t, nil).Named("autobuild.executor").Leveled(slog.LevelDebug),
+		options.AutobuildTicker,
+	).WithStatsChannel(options.AutobuildStats).WithNotifier(notifier)
+	lifecycleExecutor.Run()
+
 	stunAddr, stunCleanup := stuntest.ServeWithPacketListener(t, nettype.Std{})
 	t.Cleanup(stunCleanup)
 
@@ -324,9 +339,12 @@ func NewOptions(t *testing.T, options *Options) (func(http.Handler), context.Can
 			},
 			MetricsCacheRefreshInterval: options.MetricsCacheRefreshInterval,
 			AgentStatsRefreshInterval:   options.AgentStatsRefreshInterval,
+			TemplateGitSyncInterval:     options.TemplateGitSyncInterval,
+			FileStore:                   options.FileStore,
 			DeploymentConfig:            options.DeploymentConfig,
 			UpdateCheckOptions:          options.UpdateCheckOptions,
 			SwaggerEndpoint:             options.SwaggerEndpoint,
+			Notifier:                    notifier,
 		}
 }
 
@@ -413,7 +431,11 @@ func NewExternalProvisionerDaemon(t *testing.T, client *codersdk.Client, org uui
 	}()
 
 	closer := provisionerd.New(func(ctx context.Context) (provisionerdproto.DRPCProvisionerDaemonClient, error) {
-		return client.ServeProvisionerDaemon(ctx, org, []codersdk.ProvisionerType{codersdk.ProvisionerTypeEcho}, tags)
+		return client.ServeProvisionerDaemon(ctx, codersdk.ServeProvisionerDaemonRequest{
+			Organization: org,
+			Provisioners: []codersdk.ProvisionerType{codersdk.ProvisionerTypeEcho},
+			Tags:         tags,
+		})
 	}, &provisionerd.Options{
 		Filesystem:          fs,
 		Logger:              slogtest.Make(t, nil).Named("provisionerd").Leveled(slog.LevelDebug),
@@ -28,6 +28,16 @@ func (q *querier) InTx(function func(querier database.Store) error, txOpts *sql.
 	}, txOpts)
 }
 
+// TryAcquireLock doesn't read or write any data, so it isn't authorized.
+func (q *querier) TryAcquireLock(ctx context.Context, id int64) (bool, error) {
+	return q.db.TryAcquireLock(ctx, id)
+}
+
+// AcquireFileHashLock doesn't read or write any data, so it isn't authorized.
+func (q *querier) AcquireFileHashLock(ctx context.Context, hash string) error {
+	return q.db.AcquireFileHashLock(ctx, hash)
+}
+
 func (q *querier) DeleteAPIKeyByID(ctx context.Context, id string) error {
 	return deleteQ(q.log, q.auth, q.db.GetAPIKeyByID, q.db.DeleteAPIKeyByID)(ctx, id)
 }
@@ -75,6 +85,33 @@ func (q *querier) GetAuditLogsOffset(ctx context.Context, arg database.GetAuditL
 	return q.db.GetAuditLogsOffset(ctx, arg)
 }
 
+func (q *querier) GetAuditLogsForExport(ctx context.Context, arg database.GetAuditLogsForExportParams) ([]database.GetAuditLogsForExportRow, error) {
+	// Like GetAuditLogsOffset, only the global audit log permission is
+	// checked.
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceAuditLog); err != nil {
+		return nil, err
+	}
+	return q.db.GetAuditLogsForExport(ctx, arg)
+}
+
+func (q *querier) GetAuditLogsByTimeRange(ctx context.Context, arg database.GetAuditLogsByTimeRangeParams) ([]database.AuditLog, error) {
+	// Like GetAuditLogsOffset, only the global audit log permission is
+	// checked.
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceAuditLog); err != nil {
+		return nil, err
+	}
+	return q.db.GetAuditLogsByTimeRange(ctx, arg)
+}
+
+func (q *querier) GetAuditLogArchivesByTimeRange(ctx context.Context, arg database.GetAuditLogArchivesByTimeRangeParams) ([]database.AuditLogArchive, error) {
+	// Archives contain audit logs, so reading them requires the same
+	// permission.
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceAuditLog); err != nil {
+		return nil, err
+	}
+	return q.db.GetAuditLogArchivesByTimeRange(ctx, arg)
+}
+
 func (q *querier) GetFileByHashAndCreator(ctx context.Context, arg database.GetFileByHashAndCreatorParams) (database.File, error) {
 	return fetch(q.log, q.auth, q.db.GetFileByHashAndCreator)(ctx, arg)
 }
@@ -814,6 +851,43 @@ func (q *querier) UpdateTemplateDeletedByID(ctx context.Context, arg database.Up
 	return q.SoftDeleteTemplateByID(ctx, arg.ID)
 }
 
+func (q *querier) UpdateTemplateDeprecatedByID(ctx context.Context, arg database.UpdateTemplateDeprecatedByIDParams) (database.Template, error) {
+	fetch := func(ctx context.Context, arg database.UpdateTemplateDeprecatedByIDParams) (database.Template, error) {
+		return q.db.GetTemplateByID(ctx, arg.ID)
+	}
+	return updateWithReturn(q.log, q.auth, fetch, q.db.UpdateTemplateDeprecatedByID)(ctx, arg)
+}
+
+func (q *querier) GetTemplateGitSyncByTemplateID(ctx context.Context, templateID uuid.UUID) (database.TemplateGitSync, error) {
+	// Reading the git sync of a template is the same as reading the template.
+	_, err := q.GetTemplateByID(ctx, templateID)
+	if err != nil {
+		return database.TemplateGitSync{}, err
+	}
+	return q.db.GetTemplateGitSyncByTemplateID(ctx, templateID)
+}
+
+func (q *querier) UpsertTemplateGitSync(ctx context.Context, arg database.UpsertTemplateGitSyncParams) (database.TemplateGitSync, error) {
+	// Setting up the git sync of a template is the same as updating the
+	// template.
+	template, err := q.db.GetTemplateByID(ctx, arg.TemplateID)
+	if err != nil {
+		return database.TemplateGitSync{}, err
+	}
+	err = q.authorizeContext(ctx, rbac.ActionUpdate, template)
+	if err != nil {
+		return database.TemplateGitSync{}, err
+	}
+	return q.db.UpsertTemplateGitSync(ctx, arg)
+}
+
+func (q *querier) DeleteTemplateGitSyncByTemplateID(ctx context.Context, templateID uuid.UUID) error {
+	fetch := func(ctx context.Context, templateID uuid.UUID) (database.Template, error) {
+		return q.db.GetTemplateByID(ctx, templateID)
+	}
+	return update(q.log, q.auth, fetch, q.db.DeleteTemplateGitSyncByTemplateID)(ctx, templateID)
+}
+
 func (q *querier) UpdateTemplateMetaByID(ctx context.Context, arg database.UpdateTemplateMetaByIDParams) (database.Template, error) {
 	fetch := func(ctx context.Context, arg database.UpdateTemplateMetaByIDParams) (database.Template, error) {
 		return q.db.GetTemplateByID(ctx, arg.ID)
@@ -821,6 +895,33 @@ func (q *querier) UpdateTemplateMetaByID(ctx context.Context, arg database.Updat
 	return updateWithReturn(q.log, q.auth, fetch, q.db.UpdateTemplateMetaByID)(ctx, arg)
 }
 
+func (q *querier) ArchiveUnusedTemplateVersions(ctx context.Context, arg database.ArchiveUnusedTemplateVersionsParams) ([]uuid.UUID, error) {
+	// An actor is allowed to archive template versions if they are authorized to update the template.
+	template, err := q.db.GetTemplateByID(ctx, arg.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, template); err != nil {
+		return nil, err
+	}
+	return q.db.ArchiveUnusedTemplateVersions(ctx, arg)
+}
+
+func (q *querier) UnarchiveTemplateVersion(ctx context.Context, arg database.UnarchiveTemplateVersionParams) error {
+	tv, err := q.db.GetTemplateVersionByID(ctx, arg.TemplateVersionID)
+	if err != nil {
+		return err
+	}
+	template, err := q.db.GetTemplateByID(ctx, tv.TemplateID.UUID)
+	if err != nil {
+		return err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, template); err != nil {
+		return err
+	}
+	return q.db.UnarchiveTemplateVersion(ctx, arg)
+}
+
 func (q *querier) UpdateTemplateVersionByID(ctx context.Context, arg database.UpdateTemplateVersionByIDParams) error {
 	template, err := q.db.GetTemplateByID(ctx, arg.TemplateID.UUID)
 	if err != nil {
@@ -900,6 +1001,30 @@ func (q *querier) GetTemplateUserRoles(ctx context.Context, id uuid.UUID) ([]dat
 	return q.db.GetTemplateUserRoles(ctx, id)
 }
 
+func (q *querier) GetWorkspaceGroupRoles(ctx context.Context, id uuid.UUID) ([]database.WorkspaceGroup, error) {
+	// An actor is authorized to read workspace group roles if they are authorized to read the workspace.
+	workspace, err := q.db.GetWorkspaceByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionRead, workspace); err != nil {
+		return nil, err
+	}
+	return q.db.GetWorkspaceGroupRoles(ctx, id)
+}
+
+func (q *querier) GetWorkspaceUserRoles(ctx context.Context, id uuid.UUID) ([]database.WorkspaceUser, error) {
+	// An actor is authorized to read workspace user roles if they are authorized to read the workspace.
+	workspace, err := q.db.GetWorkspaceByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionRead, workspace); err != nil {
+		return nil, err
+	}
+	return q.db.GetWorkspaceUserRoles(ctx, id)
+}
+
 func (q *querier) DeleteAPIKeysByUserID(ctx context.Context, userID uuid.UUID) error {
 	// TODO: This is not 100% correct because it omits apikey IDs.
 	err := q.authorizeContext(ctx, rbac.ActionDelete,
@@ -934,6 +1059,18 @@ func (q *querier) GetUserByID(ctx context.Context, id uuid.UUID) (database.User,
 	return fetch(q.log, q.auth, q.db.GetUserByID)(ctx, id)
 }
 
+func (q *querier) GetUserActivityInsights(ctx context.Context, arg database.GetUserActivityInsightsParams) ([]database.GetUserActivityInsightsRow, error) {
+	// The report covers every user and their workspaces, so the actor must be
+	// able to read all of them.
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceUser); err != nil {
+		return nil, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceWorkspace.All()); err != nil {
+		return nil, err
+	}
+	return q.db.GetUserActivityInsights(ctx, arg)
+}
+
 func (q *querier) GetAuthorizedUserCount(ctx context.Context, arg database.GetFilteredUserCountParams, prepared rbac.PreparedAuthorized) (int64, error) {
 	return q.db.GetAuthorizedUserCount(ctx, arg, prepared)
 }
@@ -1130,6 +1267,14 @@ func (q *querier) GetAuthorizedWorkspaces(ctx context.Context, arg database.GetW
 	return q.GetWorkspaces(ctx, arg)
 }
 
+func (q *querier) GetAuthorizedWorkspacesUnion(ctx context.Context, args []database.GetWorkspacesParams, _ rbac.PreparedAuthorized) ([]database.GetWorkspacesRow, error) {
+	prep, err := prepareSQLFilter(ctx, q.auth, rbac.ActionRead, rbac.ResourceWorkspace.Type)
+	if err != nil {
+		return nil, xerrors.Errorf("(dev error) prepare sql filter: %w", err)
+	}
+	return q.db.GetAuthorizedWorkspacesUnion(ctx, args, prep)
+}
+
 func (q *querier) GetWorkspaces(ctx context.Context, arg database.GetWorkspacesParams) ([]database.GetWorkspacesRow, error) {
 	prep, err := prepareSQLFilter(ctx, q.auth, rbac.ActionRead, rbac.ResourceWorkspace.Type)
 	if err != nil {
@@ -1405,6 +1550,26 @@ func (q *querier) UpdateWorkspace(ctx context.Context, arg database.UpdateWorksp
 	return updateWithReturn(q.log, q.auth, fetch, q.db.UpdateWorkspace)(ctx, arg)
 }
 
+func (q *querier) UpdateWorkspaceACLByID(ctx context.Context, arg database.UpdateWorkspaceACLByIDParams) (database.Workspace, error) {
+	// Users the workspace is shared with may not share it further, so the
+	// ACL is not considered.
+	workspace, err := q.db.GetWorkspaceByID(ctx, arg.ID)
+	if err != nil {
+		return database.Workspace{}, err
+	}
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, workspace.RBACObjectNoACL()); err != nil {
+		return database.Workspace{}, err
+	}
+	return q.db.UpdateWorkspaceACLByID(ctx, arg)
+}
+
+func (q *querier) UpdateWorkspaceAgentAuthTokensByWorkspaceID(ctx context.Context, arg database.UpdateWorkspaceAgentAuthTokensByWorkspaceIDParams) error {
+	fetch := func(ctx context.Context, arg database.UpdateWorkspaceAgentAuthTokensByWorkspaceIDParams) (database.Workspace, error) {
+		return q.db.GetWorkspaceByID(ctx, arg.WorkspaceID)
+	}
+	return update(q.log, q.auth, fetch, q.db.UpdateWorkspaceAgentAuthTokensByWorkspaceID)(ctx, arg)
+}
+
 func (q *querier) UpdateWorkspaceAgentConnectionByID(ctx context.Context, arg database.UpdateWorkspaceAgentConnectionByIDParams) error {
 	// TODO: This is a workspace agent operation. Should users be able to query this?
 	fetch := func(ctx context.Context, arg database.UpdateWorkspaceAgentConnectionByIDParams) (database.Workspace, error) {
@@ -1485,6 +1650,13 @@ func (q *querier) UpdateWorkspaceDeletedByID(ctx context.Context, arg database.U
 	return deleteQ(q.log, q.auth, fetch, q.db.UpdateWorkspaceDeletedByID)(ctx, arg)
 }
 
+func (q *querier) UpdateWorkspaceLabelsByID(ctx context.Context, arg database.UpdateWorkspaceLabelsByIDParams) (database.Workspace, error) {
+	fetch := func(ctx context.Context, arg database.UpdateWorkspaceLabelsByIDParams) (database.Workspace, error) {
+		return q.db.GetWorkspaceByID(ctx, arg.ID)
+	}
+	return updateWithReturn(q.log, q.auth, fetch, q.db.UpdateWorkspaceLabelsByID)(ctx, arg)
+}
+
 func (q *querier) UpdateWorkspaceLastUsedAt(ctx context.Context, arg database.UpdateWorkspaceLastUsedAtParams) error {
 	fetch := func(ctx context.Context, arg database.UpdateWorkspaceLastUsedAtParams) (database.Workspace, error) {
 		return q.db.GetWorkspaceByID(ctx, arg.ID)
@@ -1492,6 +1664,24 @@ func (q *querier) UpdateWorkspaceLastUsedAt(ctx context.Context, arg database.Up
 	return update(q.log, q.auth, fetch, q.db.UpdateWorkspaceLastUsedAt)(ctx, arg)
 }
 
+func (q *querier) UpdateWorkspaceOwnerByID(ctx context.Context, arg database.UpdateWorkspaceOwnerByIDParams) (database.Workspace, error) {
+	workspace, err := q.db.GetWorkspaceByID(ctx, arg.ID)
+	if err != nil {
+		return database.Workspace{}, err
+	}
+	// Users the workspace is shared with may not give it away.
+	err = q.authorizeContext(ctx, rbac.ActionUpdate, workspace.RBACObjectNoACL())
+	if err != nil {
+		return database.Workspace{}, err
+	}
+	// The new owner receives the workspace as if it was created for them.
+	err = q.authorizeContext(ctx, rbac.ActionCreate, rbac.ResourceWorkspace.WithOwner(arg.OwnerID.String()).InOrg(workspace.OrganizationID))
+	if err != nil {
+		return database.Workspace{}, err
+	}
+	return q.db.UpdateWorkspaceOwnerByID(ctx, arg)
+}
+
 func (q *querier) UpdateWorkspaceTTL(ctx context.Context, arg database.UpdateWorkspaceTTLParams) error {
 	fetch := func(ctx context.Context, arg database.UpdateWorkspaceTTLParams) (database.Workspace, error) {
 		return q.db.GetWorkspaceByID(ctx, arg.ID)
@@ -1532,3 +1722,90 @@ func authorizedTemplateVersionFromJob(ctx context.Context, q *querier, job datab
 		return database.TemplateVersion{}, xerrors.Errorf("unknown job type: %q", job.Type)
 	}
 }
+
+func (q *querier) InsertWebhook(ctx context.Context, arg database.InsertWebhookParams) (database.Webhook, error) {
+	obj := rbac.ResourceWebhook
+	if arg.OrganizationID.Valid {
+		obj = obj.InOrg(arg.OrganizationID.UUID)
+	}
+	return insert(q.log, q.auth, obj, q.db.InsertWebhook)(ctx, arg)
+}
+
+func (q *querier) GetWebhookByID(ctx context.Context, id uuid.UUID) (database.Webhook, error) {
+	return fetch(q.log, q.auth, q.db.GetWebhookByID)(ctx, id)
+}
+
+func (q *querier) GetDeploymentWebhooks(ctx context.Context) ([]database.Webhook, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceWebhook); err != nil {
+		return nil, err
+	}
+	return q.db.GetDeploymentWebhooks(ctx)
+}
+
+func (q *querier) GetWebhooksByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]database.Webhook, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceWebhook.InOrg(organizationID)); err != nil {
+		return nil, err
+	}
+	return q.db.GetWebhooksByOrganizationID(ctx, organizationID)
+}
+
+func (q *querier) GetEnabledWebhooksByEvent(ctx context.Context, arg database.GetEnabledWebhooksByEventParams) ([]database.Webhook, error) {
+	return fetchWithPostFilter(q.auth, q.db.GetEnabledWebhooksByEvent)(ctx, arg)
+}
+
+func (q *querier) UpdateWebhookByID(ctx context.Context, arg database.UpdateWebhookByIDParams) (database.Webhook, error) {
+	fetch := func(ctx context.Context, arg database.UpdateWebhookByIDParams) (database.Webhook, error) {
+		return q.db.GetWebhookByID(ctx, arg.ID)
+	}
+	return updateWithReturn(q.log, q.auth, fetch, q.db.UpdateWebhookByID)(ctx, arg)
+}
+
+func (q *querier) DeleteWebhookByID(ctx context.Context, id uuid.UUID) error {
+	return deleteQ(q.log, q.auth, q.db.GetWebhookByID, q.db.DeleteWebhookByID)(ctx, id)
+}
+
+func (q *querier) GetWebhookDeliveriesByWebhookID(ctx context.Context, arg database.GetWebhookDeliveriesByWebhookIDParams) ([]database.WebhookDelivery, error) {
+	// Authorized read on the webhook lets the actor also read its deliveries.
+	_, err := q.GetWebhookByID(ctx, arg.WebhookID)
+	if err != nil {
+		return nil, err
+	}
+	return q.db.GetWebhookDeliveriesByWebhookID(ctx, arg)
+}
+
+func (q *querier) InsertProvisionerKey(ctx context.Context, arg database.InsertProvisionerKeyParams) (database.ProvisionerKey, error) {
+	return insert(q.log, q.auth, rbac.ResourceProvisionerKey.InOrg(arg.OrganizationID), q.db.InsertProvisionerKey)(ctx, arg)
+}
+
+func (q *querier) GetProvisionerKeyByID(ctx context.Context, id uuid.UUID) (database.ProvisionerKey, error) {
+	return fetch(q.log, q.auth, q.db.GetProvisionerKeyByID)(ctx, id)
+}
+
+func (q *querier) GetProvisionerKeyByOrganizationIDAndName(ctx context.Context, arg database.GetProvisionerKeyByOrganizationIDAndNameParams) (database.ProvisionerKey, error) {
+	return fetch(q.log, q.auth, q.db.GetProvisionerKeyByOrganizationIDAndName)(ctx, arg)
+}
+
+func (q *querier) GetProvisionerKeysByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]database.ProvisionerKey, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceProvisionerKey.InOrg(organizationID)); err != nil {
+		return nil, err
+	}
+	return q.db.GetProvisionerKeysByOrganizationID(ctx, organizationID)
+}
+
+func (q *querier) DeleteProvisionerKeyByID(ctx context.Context, id uuid.UUID) error {
+	return deleteQ(q.log, q.auth, q.db.GetProvisionerKeyByID, q.db.DeleteProvisionerKeyByID)(ctx, id)
+}
+
+func (q *querier) GetNotificationPreferencesByUserID(ctx context.Context, userID uuid.UUID) ([]database.NotificationPreference, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionRead, rbac.ResourceUserData.WithOwner(userID.String()).WithID(userID)); err != nil {
+		return nil, err
+	}
+	return q.db.GetNotificationPreferencesByUserID(ctx, userID)
+}
+
+func (q *querier) UpsertNotificationPreference(ctx context.Context, arg database.UpsertNotificationPreferenceParams) (database.NotificationPreference, error) {
+	if err := q.authorizeContext(ctx, rbac.ActionUpdate, rbac.ResourceUserData.WithOwner(arg.UserID.String()).WithID(arg.UserID)); err != nil {
+		return database.NotificationPreference{}, err
+	}
+	return q.db.UpsertNotificationPreference(ctx, arg)
+}
@@ -81,6 +81,24 @@ func (s *MethodTestSuite) TestAuditLogs() {
 			Limit: 10,
 		}).Asserts(rbac.ResourceAuditLog, rbac.ActionRead)
 	}))
+	s.Run("GetAuditLogsForExport", s.Subtest(func(db database.Store, check *expects) {
+		_ = dbgen.AuditLog(s.T(), db, database.AuditLog{})
+		check.Args(database.GetAuditLogsForExportParams{
+			RowLimit: 10,
+		}).Asserts(rbac.ResourceAuditLog, rbac.ActionRead)
+	}))
+	s.Run("GetAuditLogsByTimeRange", s.Subtest(func(db database.Store, check *expects) {
+		_ = dbgen.AuditLog(s.T(), db, database.AuditLog{})
+		check.Args(database.GetAuditLogsByTimeRangeParams{
+			Until:    time.Now().Add(time.Hour),
+			RowLimit: 10,
+		}).Asserts(rbac.ResourceAuditLog, rbac.ActionRead)
+	}))
+	s.Run("GetAuditLogArchivesByTimeRange", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.GetAuditLogArchivesByTimeRangeParams{
+			Until: time.Now(),
+		}).Asserts(rbac.ResourceAuditLog, rbac.ActionRead)
+	}))
 }
 
 func (s *MethodTestSuite) TestFile() {
@@ -708,6 +726,30 @@ func (s *MethodTestSuite) TestTemplate() {
 			Deleted: true,
 		}).Asserts(t1, rbac.ActionDelete).Returns()
 	}))
+	s.Run("UpdateTemplateDeprecatedByID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		check.Args(database.UpdateTemplateDeprecatedByIDParams{
+			ID:         t1.ID,
+			Deprecated: "Use the new template.",
+		}).Asserts(t1, rbac.ActionUpdate)
+	}))
+	s.Run("GetTemplateGitSyncByTemplateID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		gs := dbgen.TemplateGitSync(s.T(), db, database.TemplateGitSync{TemplateID: t1.ID})
+		check.Args(t1.ID).Asserts(t1, rbac.ActionRead).Returns(gs)
+	}))
+	s.Run("UpsertTemplateGitSync", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		check.Args(database.UpsertTemplateGitSyncParams{
+			TemplateID: t1.ID,
+			UserID:     uuid.New(),
+		}).Asserts(t1, rbac.ActionUpdate)
+	}))
+	s.Run("DeleteTemplateGitSyncByTemplateID", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		_ = dbgen.TemplateGitSync(s.T(), db, database.TemplateGitSync{TemplateID: t1.ID})
+		check.Args(t1.ID).Asserts(t1, rbac.ActionUpdate)
+	}))
 	s.Run("UpdateTemplateMetaByID", s.Subtest(func(db database.Store, check *expects) {
 		t1 := dbgen.Template(s.T(), db, database.Template{})
 		check.Args(database.UpdateTemplateMetaByIDParams{
@@ -748,6 +790,23 @@ func (s *MethodTestSuite) TestTemplate() {
 			GitAuthProviders: []string{},
 		}).Asserts(t1, rbac.ActionUpdate).Returns()
 	}))
+	s.Run("ArchiveUnusedTemplateVersions", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		check.Args(database.ArchiveUnusedTemplateVersionsParams{
+			UpdatedAt:  time.Now(),
+			TemplateID: t1.ID,
+		}).Asserts(t1, rbac.ActionUpdate)
+	}))
+	s.Run("UnarchiveTemplateVersion", s.Subtest(func(db database.Store, check *expects) {
+		t1 := dbgen.Template(s.T(), db, database.Template{})
+		tv := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{UUID: t1.ID, Valid: true},
+		})
+		check.Args(database.UnarchiveTemplateVersionParams{
+			UpdatedAt:         time.Now(),
+			TemplateVersionID: tv.ID,
+		}).Asserts(t1, rbac.ActionUpdate).Returns()
+	}))
 }
 
 func (s *MethodTestSuite) TestUser() {
@@ -774,6 +833,14 @@ func (s *MethodTestSuite) TestUser() {
 		u := dbgen.User(s.T(), db, database.User{})
 		check.Args(u.ID).Asserts(u, rbac.ActionRead).Returns(u)
 	}))
+	s.Run("GetUserActivityInsights", s.Subtest(func(db database.Store, check *expects) {
+		_ = dbgen.User(s.T(), db, database.User{})
+		check.Args(database.GetUserActivityInsightsParams{
+			ReportIntervalSeconds: 300,
+			StartTime:             time.Now().Add(-time.Hour),
+			EndTime:               time.Now(),
+		}).Asserts(rbac.ResourceUser, rbac.ActionRead, rbac.ResourceWorkspace.All(), rbac.ActionRead)
+	}))
 	s.Run("GetAuthorizedUserCount", s.Subtest(func(db database.Store, check *expects) {
 		_ = dbgen.User(s.T(), db, database.User{})
 		check.Args(database.GetFilteredUserCountParams{}, emptyPreparedAuthorized{}).Asserts().Returns(int64(1))
@@ -931,6 +998,14 @@ func (s *MethodTestSuite) TestWorkspace() {
 		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
 		check.Args(ws.ID).Asserts(ws, rbac.ActionRead)
 	}))
+	s.Run("GetWorkspaceGroupRoles", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		check.Args(ws.ID).Asserts(ws, rbac.ActionRead)
+	}))
+	s.Run("GetWorkspaceUserRoles", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		check.Args(ws.ID).Asserts(ws, rbac.ActionRead)
+	}))
 	s.Run("GetWorkspaces", s.Subtest(func(db database.Store, check *expects) {
 		_ = dbgen.Workspace(s.T(), db, database.Workspace{})
 		_ = dbgen.Workspace(s.T(), db, database.Workspace{})
@@ -943,6 +1018,12 @@ func (s *MethodTestSuite) TestWorkspace() {
 		// No asserts here because SQLFilter.
 		check.Args(database.GetWorkspacesParams{}, emptyPreparedAuthorized{}).Asserts()
 	}))
+	s.Run("GetAuthorizedWorkspacesUnion", s.Subtest(func(db database.Store, check *expects) {
+		_ = dbgen.Workspace(s.T(), db, database.Workspace{})
+		_ = dbgen.Workspace(s.T(), db, database.Workspace{})
+		// No asserts here because SQLFilter.
+		check.Args([]database.GetWorkspacesParams{{}, {}}, emptyPreparedAuthorized{}).Asserts()
+	}))
 	s.Run("GetLatestWorkspaceBuildByWorkspaceID", s.Subtest(func(db database.Store, check *expects) {
 		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
 		b := dbgen.WorkspaceBuild(s.T(), db, database.WorkspaceBuild{WorkspaceID: ws.ID})
@@ -1187,6 +1268,18 @@ func (s *MethodTestSuite) TestWorkspace() {
 			Health: database.WorkspaceAppHealthDisabled,
 		}).Asserts(ws, rbac.ActionUpdate).Returns()
 	}))
+	s.Run("UpdateWorkspaceACLByID", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		check.Args(database.UpdateWorkspaceACLByIDParams{
+			ID: ws.ID,
+		}).Asserts(ws.RBACObjectNoACL(), rbac.ActionUpdate)
+	}))
+	s.Run("UpdateWorkspaceAgentAuthTokensByWorkspaceID", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		check.Args(database.UpdateWorkspaceAgentAuthTokensByWorkspaceIDParams{
+			WorkspaceID: ws.ID,
+		}).Asserts(ws, rbac.ActionUpdate).Returns()
+	}))
 	s.Run("UpdateWorkspaceAutostart", s.Subtest(func(db database.Store, check *expects) {
 		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
 		check.Args(database.UpdateWorkspaceAutostartParams{
@@ -1214,12 +1307,31 @@ func (s *MethodTestSuite) TestWorkspace() {
 			Deleted: true,
 		}).Asserts(ws, rbac.ActionDelete).Returns()
 	}))
+	s.Run("UpdateWorkspaceLabelsByID", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		check.Args(database.UpdateWorkspaceLabelsByIDParams{
+			ID:     ws.ID,
+			Labels: database.WorkspaceLabels{"project": "coder"},
+		}).Asserts(ws, rbac.ActionUpdate)
+	}))
 	s.Run("UpdateWorkspaceLastUsedAt", s.Subtest(func(db database.Store, check *expects) {
 		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
 		check.Args(database.UpdateWorkspaceLastUsedAtParams{
 			ID: ws.ID,
 		}).Asserts(ws, rbac.ActionUpdate).Returns()
 	}))
+	s.Run("UpdateWorkspaceOwnerByID", s.Subtest(func(db database.Store, check *expects) {
+		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
+		u := dbgen.User(s.T(), db, database.User{})
+		check.Args(database.UpdateWorkspaceOwnerByIDParams{
+			ID:      ws.ID,
+			OwnerID: u.ID,
+			Name:    ws.Name,
+		}).Asserts(
+			ws.RBACObjectNoACL(), rbac.ActionUpdate,
+			rbac.ResourceWorkspace.WithOwner(u.ID.String()).InOrg(ws.OrganizationID), rbac.ActionCreate,
+		)
+	}))
 	s.Run("UpdateWorkspaceTTL", s.Subtest(func(db database.Store, check *expects) {
 		ws := dbgen.Workspace(s.T(), db, database.Workspace{})
 		check.Args(database.UpdateWorkspaceTTLParams{
@@ -1236,6 +1348,112 @@ func (s *MethodTestSuite) TestWorkspace() {
 	}))
 }
 
+func (s *MethodTestSuite) TestWebhook() {
+	s.Run("InsertWebhook", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.InsertWebhookParams{
+			ID: uuid.New(),
+		}).Asserts(rbac.ResourceWebhook, rbac.ActionCreate)
+	}))
+	s.Run("Organization/InsertWebhook", s.Subtest(func(db database.Store, check *expects) {
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		check.Args(database.InsertWebhookParams{
+			ID:             uuid.New(),
+			OrganizationID: uuid.NullUUID{UUID: o.ID, Valid: true},
+		}).Asserts(rbac.ResourceWebhook.InOrg(o.ID), rbac.ActionCreate)
+	}))
+	s.Run("GetWebhookByID", s.Subtest(func(db database.Store, check *expects) {
+		w := dbgen.Webhook(s.T(), db, database.Webhook{})
+		check.Args(w.ID).Asserts(w, rbac.ActionRead).Returns(w)
+	}))
+	s.Run("GetDeploymentWebhooks", s.Subtest(func(db database.Store, check *expects) {
+		w := dbgen.Webhook(s.T(), db, database.Webhook{})
+		check.Args().Asserts(rbac.ResourceWebhook, rbac.ActionRead).Returns([]database.Webhook{w})
+	}))
+	s.Run("GetWebhooksByOrganizationID", s.Subtest(func(db database.Store, check *expects) {
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		w := dbgen.Webhook(s.T(), db, database.Webhook{
+			OrganizationID: uuid.NullUUID{UUID: o.ID, Valid: true},
+		})
+		check.Args(o.ID).Asserts(rbac.ResourceWebhook.InOrg(o.ID), rbac.ActionRead).Returns([]database.Webhook{w})
+	}))
+	s.Run("GetEnabledWebhooksByEvent", s.Subtest(func(db database.Store, check *expects) {
+		w := dbgen.Webhook(s.T(), db, database.Webhook{
+			Events:  []string{"workspace.created"},
+			Enabled: true,
+		})
+		check.Args(database.GetEnabledWebhooksByEventParams{
+			Event: "workspace.created",
+		}).Asserts(w, rbac.ActionRead).Returns([]database.Webhook{w})
+	}))
+	s.Run("UpdateWebhookByID", s.Subtest(func(db database.Store, check *expects) {
+		w := dbgen.Webhook(s.T(), db, database.Webhook{})
+		check.Args(database.UpdateWebhookByIDParams{
+			ID: w.ID,
+		}).Asserts(w, rbac.ActionUpdate)
+	}))
+	s.Run("DeleteWebhookByID", s.Subtest(func(db database.Store, check *expects) {
+		w := dbgen.Webhook(s.T(), db, database.Webhook{})
+		check.Args(w.ID).Asserts(w, rbac.ActionDelete).Returns()
+	}))
+	s.Run("GetWebhookDeliveriesByWebhookID", s.Subtest(func(db database.Store, check *expects) {
+		w := dbgen.Webhook(s.T(), db, database.Webhook{})
+		d := dbgen.WebhookDelivery(s.T(), db, database.WebhookDelivery{WebhookID: w.ID})
+		check.Args(database.GetWebhookDeliveriesByWebhookIDParams{
+			WebhookID: w.ID,
+		}).Asserts(w, rbac.ActionRead).Returns([]database.WebhookDelivery{d})
+	}))
+}
+
+func (s *MethodTestSuite) TestProvisionerKey() {
+	s.Run("InsertProvisionerKey", s.Subtest(func(db database.Store, check *expects) {
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		check.Args(database.InsertProvisionerKeyParams{
+			ID:             uuid.New(),
+			OrganizationID: o.ID,
+		}).Asserts(rbac.ResourceProvisionerKey.InOrg(o.ID), rbac.ActionCreate)
+	}))
+	s.Run("GetProvisionerKeyByID", s.Subtest(func(db database.Store, check *expects) {
+		k := dbgen.ProvisionerKey(s.T(), db, database.ProvisionerKey{})
+		check.Args(k.ID).Asserts(k, rbac.ActionRead).Returns(k)
+	}))
+	s.Run("GetProvisionerKeyByOrganizationIDAndName", s.Subtest(func(db database.Store, check *expects) {
+		k := dbgen.ProvisionerKey(s.T(), db, database.ProvisionerKey{})
+		check.Args(database.GetProvisionerKeyByOrganizationIDAndNameParams{
+			OrganizationID: k.OrganizationID,
+			Name:           k.Name,
+		}).Asserts(k, rbac.ActionRead).Returns(k)
+	}))
+	s.Run("GetProvisionerKeysByOrganizationID", s.Subtest(func(db database.Store, check *expects) {
+		o := dbgen.Organization(s.T(), db, database.Organization{})
+		k := dbgen.ProvisionerKey(s.T(), db, database.ProvisionerKey{OrganizationID: o.ID})
+		check.Args(o.ID).Asserts(rbac.ResourceProvisionerKey.InOrg(o.ID), rbac.ActionRead).Returns([]database.ProvisionerKey{k})
+	}))
+	s.Run("DeleteProvisionerKeyByID", s.Subtest(func(db database.Store, check *expects) {
+		k := dbgen.ProvisionerKey(s.T(), db, database.ProvisionerKey{})
+		check.Args(k.ID).Asserts(k, rbac.ActionDelete).Returns()
+	}))
+}
+
+func (s *MethodTestSuite) TestNotificationPreference() {
+	s.Run("GetNotificationPreferencesByUserID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		p, err := db.UpsertNotificationPreference(context.Background(), database.UpsertNotificationPreferenceParams{
+			UserID:   u.ID,
+			Kind:     "workspace_autostop",
+			Disabled: true,
+		})
+		s.NoError(err, "upsert notification preference")
+		check.Args(u.ID).Asserts(rbac.ResourceUserData.WithOwner(u.ID.String()).WithID(u.ID), rbac.ActionRead).Returns([]database.NotificationPreference{p})
+	}))
+	s.Run("UpsertNotificationPreference", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		check.Args(database.UpsertNotificationPreferenceParams{
+			UserID: u.ID,
+			Kind:   "workspace_autostop",
+		}).Asserts(rbac.ResourceUserData.WithOwner(u.ID.String()).WithID(u.ID), rbac.ActionUpdate)
+	}))
+}
+
 func (s *MethodTestSuite) TestExtraMethods() {
 	s.Run("GetProvisionerDaemons", s.Subtest(func(db database.Store, check *expects) {
 		d, err := db.InsertProvisionerDaemon(context.Background(), database.InsertProvisionerDaemonParams{
@@ -28,6 +28,9 @@ import (
 var skipMethods = map[string]string{
 	"InTx": "Not relevant",
 	"Ping": "Not relevant",
+	// Locks must be acquired in a transaction.
+	"AcquireFileHashLock": "Not relevant",
+	"TryAcquireLock":      "Not relevant",
 }
 
 // TestMethodTestSuite runs MethodTestSuite.
@@ -147,6 +150,7 @@ func (s *MethodTestSuite) Subtest(testCaseF func(db database.Store, check *expec
 		if len(testCase.assertions) > 0 ||
 			slice.Contains([]string{
 				"GetAuthorizedWorkspaces",
+				"GetAuthorizedWorkspacesUnion",
 				"GetAuthorizedTemplates",
 			}, methodName) {
 			// Some methods do not make RBAC assertions because they use
@@ -50,6 +50,12 @@ func (q *querier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUID)
 	return q.db.GetProvisionerJobsByIDs(ctx, ids)
 }
 
+// GetProvisionerJobQueuePositionsByIDs is only used to show the position of
+// jobs that were already fetched in the queue.
+func (q *querier) GetProvisionerJobQueuePositionsByIDs(ctx context.Context, ids []uuid.UUID) ([]database.GetProvisionerJobQueuePositionsByIDsRow, error) {
+	return q.db.GetProvisionerJobQueuePositionsByIDs(ctx, ids)
+}
+
 // GetTemplateVersionsByIDs is only used for workspace build data.
 // The workspace is already fetched.
 // TODO: Find a way to replace this with proper authz.
@@ -161,6 +167,31 @@ func (q *querier) GetDeploymentDAUs(ctx context.Context) ([]database.GetDeployme
 	return q.db.GetDeploymentDAUs(ctx)
 }
 
+// Only used by metrics cache.
+func (q *querier) GetTemplateActiveWorkspaces(ctx context.Context, startTime time.Time) ([]database.GetTemplateActiveWorkspacesRow, error) {
+	return q.db.GetTemplateActiveWorkspaces(ctx, startTime)
+}
+
+// Only used by metrics cache.
+func (q *querier) GetTemplateBuildInsights(ctx context.Context, startTime time.Time) ([]database.GetTemplateBuildInsightsRow, error) {
+	return q.db.GetTemplateBuildInsights(ctx, startTime)
+}
+
+// Only used by metrics cache.
+func (q *querier) GetTemplateConnectionInsights(ctx context.Context, startTime time.Time) ([]database.GetTemplateConnectionInsightsRow, error) {
+	return q.db.GetTemplateConnectionInsights(ctx, startTime)
+}
+
+// Only used by metrics cache.
+func (q *querier) GetTemplateInsightsApps(ctx context.Context, startTime time.Time) ([]database.GetTemplateInsightsAppsRow, error) {
+	return q.db.GetTemplateInsightsApps(ctx, startTime)
+}
+
+// Only used by metrics cache.
+func (q *querier) GetTemplateVersionAdoption(ctx context.Context) ([]database.GetTemplateVersionAdoptionRow, error) {
+	return q.db.GetTemplateVersionAdoption(ctx)
+}
+
 // UpdateWorkspaceBuildCostByID is used by the provisioning system to update the cost of a workspace build.
 func (q *querier) UpdateWorkspaceBuildCostByID(ctx context.Context, arg database.UpdateWorkspaceBuildCostByIDParams) (database.WorkspaceBuild, error) {
 	return q.db.UpdateWorkspaceBuildCostByID(ctx, arg)
@@ -209,6 +240,10 @@ func (q *querier) GetProvisionerJobsCreatedAfter(ctx context.Context, createdAt
 	return q.db.GetProvisionerJobsCreatedAfter(ctx, createdAt)
 }
 
+func (q *querier) GetRunningProvisionerJobs(ctx context.Context) ([]database.ProvisionerJob, error) {
+	return q.db.GetRunningProvisionerJobs(ctx)
+}
+
 // Provisionerd server functions
 
 func (q *querier) InsertWorkspaceAgent(ctx context.Context, arg database.InsertWorkspaceAgentParams) (database.WorkspaceAgent, error) {
@@ -247,6 +282,24 @@ func (q *querier) InsertProvisionerDaemon(ctx context.Context, arg database.Inse
 	return q.db.InsertProvisionerDaemon(ctx, arg)
 }
 
+// GetProvisionerKeyByHashedSecret is used to authenticate provisioner daemons
+// that connect with a provisioner key.
+func (q *querier) GetProvisionerKeyByHashedSecret(ctx context.Context, hashedSecret []byte) (database.ProvisionerKey, error) {
+	return q.db.GetProvisionerKeyByHashedSecret(ctx, hashedSecret)
+}
+
+func (q *querier) DeleteOldProvisionerDaemons(ctx context.Context, before time.Time) error {
+	return q.db.DeleteOldProvisionerDaemons(ctx, before)
+}
+
+func (q *querier) DeleteOldWebhookDeliveries(ctx context.Context, before time.Time) error {
+	return q.db.DeleteOldWebhookDeliveries(ctx, before)
+}
+
+func (q *querier) UpdateProvisionerDaemonLastSeenAt(ctx context.Context, arg database.UpdateProvisionerDaemonLastSeenAtParams) error {
+	return q.db.UpdateProvisionerDaemonLastSeenAt(ctx, arg)
+}
+
 func (q *querier) InsertTemplateVersionParameter(ctx context.Context, arg database.InsertTemplateVersionParameterParams) (database.TemplateVersionParameter, error) {
 	return q.db.InsertTemplateVersionParameter(ctx, arg)
 }
@@ -262,3 +315,108 @@ func (q *querier) InsertWorkspaceResource(ctx context.Context, arg database.Inse
 func (q *querier) InsertParameterSchema(ctx context.Context, arg database.InsertParameterSchemaParams) (database.ParameterSchema, error) {
 	return q.db.InsertParameterSchema(ctx, arg)
 }
+
+// InsertWebhookDelivery is only used by the webhook dispatcher, which has
+// already fetched the webhook.
+func (q *querier) InsertWebhookDelivery(ctx context.Context, arg database.InsertWebhookDeliveryParams) (database.WebhookDelivery, error) {
+	return q.db.InsertWebhookDelivery(ctx, arg)
+}
+
+// UpdateWebhookDeliveryByID is only used by the webhook dispatcher to record
+// the outcome of a delivery attempt.
+func (q *querier) UpdateWebhookDeliveryByID(ctx context.Context, arg database.UpdateWebhookDeliveryByIDParams) (database.WebhookDelivery, error) {
+	return q.db.UpdateWebhookDeliveryByID(ctx, arg)
+}
+
+// InsertNotificationMessage is only used by the notifier to claim a message
+// before it is sent.
+func (q *querier) InsertNotificationMessage(ctx context.Context, arg database.InsertNotificationMessageParams) (database.NotificationMessage, error) {
+	return q.db.InsertNotificationMessage(ctx, arg)
+}
+
+// DeleteNotificationMessageByID is only used by the notifier to release the
+// claim on a message that could not be sent.
+func (q *querier) DeleteNotificationMessageByID(ctx context.Context, id uuid.UUID) error {
+	return q.db.DeleteNotificationMessageByID(ctx, id)
+}
+
+func (q *querier) DeleteOldNotificationMessages(ctx context.Context, before time.Time) error {
+	return q.db.DeleteOldNotificationMessages(ctx, before)
+}
+
+// DeleteUnusedFiles and DeleteArchivedTemplateVersionJobLogs are only used
+// by the garbage collector of archived template versions.
+func (q *querier) DeleteUnusedFiles(ctx context.Context, createdBefore time.Time) ([]database.DeleteUnusedFilesRow, error) {
+	return q.db.DeleteUnusedFiles(ctx, createdBefore)
+}
+
+func (q *querier) DeleteArchivedTemplateVersionJobLogs(ctx context.Context) error {
+	return q.db.DeleteArchivedTemplateVersionJobLogs(ctx)
+}
+
+// DeleteOldWorkspaceBuildJobLogs is only used by the purger to enforce the
+// retention of provisioner job logs.
+func (q *querier) DeleteOldWorkspaceBuildJobLogs(ctx context.Context, arg database.DeleteOldWorkspaceBuildJobLogsParams) (int64, error) {
+	return q.db.DeleteOldWorkspaceBuildJobLogs(ctx, arg)
+}
+
+// GetAuditLogsForPurge, InsertAuditLogArchive and DeleteAuditLogsByIDs are
+// only used by the purger to archive and delete audit logs after their
+// retention.
+func (q *querier) GetAuditLogsForPurge(ctx context.Context, arg database.GetAuditLogsForPurgeParams) ([]database.AuditLog, error) {
+	return q.db.GetAuditLogsForPurge(ctx, arg)
+}
+
+func (q *querier) InsertAuditLogArchive(ctx context.Context, arg database.InsertAuditLogArchiveParams) (database.AuditLogArchive, error) {
+	return q.db.InsertAuditLogArchive(ctx, arg)
+}
+
+func (q *querier) DeleteAuditLogsByIDs(ctx context.Context, ids []uuid.UUID) error {
+	return q.db.DeleteAuditLogsByIDs(ctx, ids)
+}
+
+// GetTemplateGitSyncsByTemplateIDs
+// The templates are already fetched.
+// TODO: This function should be removed/replaced with something with proper auth.
+func (q *querier) GetTemplateGitSyncsByTemplateIDs(ctx context.Context, ids []uuid.UUID) ([]database.TemplateGitSync, error) {
+	return q.db.GetTemplateGitSyncsByTemplateIDs(ctx, ids)
+}
+
+// GetTemplateGitSyncs, UpdateTemplateGitSyncCommitByTemplateID,
+// UpdateTemplateGitSyncDryRunJobByTemplateID and
+// UpdateTemplateGitSyncStatusByTemplateID are only used by the git syncer to
+// record its progress. Template versions are created as the user that set up
+// the sync.
+func (q *querier) GetTemplateGitSyncs(ctx context.Context) ([]database.TemplateGitSync, error) {
+	return q.db.GetTemplateGitSyncs(ctx)
+}
+
+func (q *querier) UpdateTemplateGitSyncCommitByTemplateID(ctx context.Context, arg database.UpdateTemplateGitSyncCommitByTemplateIDParams) (database.TemplateGitSync, error) {
+	return q.db.UpdateTemplateGitSyncCommitByTemplateID(ctx, arg)
+}
+
+func (q *querier) UpdateTemplateGitSyncDryRunJobByTemplateID(ctx context.Context, arg database.UpdateTemplateGitSyncDryRunJobByTemplateIDParams) (database.TemplateGitSync, error) {
+	return q.db.UpdateTemplateGitSyncDryRunJobByTemplateID(ctx, arg)
+}
+
+func (q *querier) UpdateTemplateGitSyncStatusByTemplateID(ctx context.Context, arg database.UpdateTemplateGitSyncStatusByTemplateIDParams) (database.TemplateGitSync, error) {
+	return q.db.UpdateTemplateGitSyncStatusByTemplateID(ctx, arg)
+}
+
+// GetFileHashInUse, GetFileIDsByStorage and UpdateFileStorageByID are only
+// used to manage the contents of files stored outside of the database.
+func (q *querier) GetFileHashInUse(ctx context.Context, hash string) (bool, error) {
+	return q.db.GetFileHashInUse(ctx, hash)
+}
+
+func (q *querier) LockFileByID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	return q.db.LockFileByID(ctx, id)
+}
+
+func (q *querier) GetFileIDsByStorage(ctx context.Context, storage string) ([]uuid.UUID, error) {
+	return q.db.GetFileIDsByStorage(ctx, storage)
+}
+
+func (q *querier) UpdateFileStorageByID(ctx context.Context, arg database.UpdateFileStorageByIDParams) error {
+	return q.db.UpdateFileStorageByID(ctx, arg)
+}
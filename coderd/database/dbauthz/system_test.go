@@ -131,6 +131,21 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 	s.Run("DeleteOldWorkspaceAgentStats", s.Subtest(func(db database.Store, check *expects) {
 		check.Args().Asserts()
 	}))
+	s.Run("GetTemplateActiveWorkspaces", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(time.Now()).Asserts()
+	}))
+	s.Run("GetTemplateBuildInsights", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(time.Now()).Asserts()
+	}))
+	s.Run("GetTemplateConnectionInsights", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(time.Now()).Asserts()
+	}))
+	s.Run("GetTemplateInsightsApps", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(time.Now()).Asserts()
+	}))
+	s.Run("GetTemplateVersionAdoption", s.Subtest(func(db database.Store, check *expects) {
+		check.Args().Asserts()
+	}))
 	s.Run("GetParameterSchemasCreatedAfter", s.Subtest(func(db database.Store, check *expects) {
 		_ = dbgen.ParameterSchema(s.T(), db, database.ParameterSchema{CreatedAt: time.Now().Add(-time.Hour)})
 		check.Args(time.Now()).Asserts()
@@ -139,6 +154,15 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 		_ = dbgen.ProvisionerJob(s.T(), db, database.ProvisionerJob{CreatedAt: time.Now().Add(-time.Hour)})
 		check.Args(time.Now()).Asserts()
 	}))
+	s.Run("GetRunningProvisionerJobs", s.Subtest(func(db database.Store, check *expects) {
+		j := dbgen.ProvisionerJob(s.T(), db, database.ProvisionerJob{})
+		j, err := db.AcquireProvisionerJob(context.Background(), database.AcquireProvisionerJobParams{
+			StartedAt: sql.NullTime{Time: database.Now(), Valid: true},
+			Types:     []database.ProvisionerType{j.Provisioner},
+		})
+		require.NoError(s.T(), err)
+		check.Args().Asserts().Returns([]database.ProvisionerJob{j})
+	}))
 	s.Run("InsertWorkspaceAgent", s.Subtest(func(db database.Store, check *expects) {
 		check.Args(database.InsertWorkspaceAgentParams{
 			ID: uuid.New(),
@@ -163,6 +187,16 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 		check.Args(database.AcquireProvisionerJobParams{Types: []database.ProvisionerType{j.Provisioner}}).
 			Asserts()
 	}))
+	s.Run("GetProvisionerJobQueuePositionsByIDs", s.Subtest(func(db database.Store, check *expects) {
+		j := dbgen.ProvisionerJob(s.T(), db, database.ProvisionerJob{})
+		check.Args([]uuid.UUID{j.ID}).Asserts().Returns([]database.GetProvisionerJobQueuePositionsByIDsRow{{
+			ID:            j.ID,
+			Provisioner:   j.Provisioner,
+			Tags:          j.Tags,
+			QueuePosition: 1,
+			QueueSize:     1,
+		}})
+	}))
 	s.Run("UpdateProvisionerJobWithCompleteByID", s.Subtest(func(db database.Store, check *expects) {
 		j := dbgen.ProvisionerJob(s.T(), db, database.ProvisionerJob{})
 		check.Args(database.UpdateProvisionerJobWithCompleteByIDParams{
@@ -182,6 +216,7 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 			Provisioner:   database.ProvisionerTypeEcho,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeWorkspaceBuild,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		}).Asserts()
 	}))
 	s.Run("InsertProvisionerJobLogs", s.Subtest(func(db database.Store, check *expects) {
@@ -195,6 +230,26 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 			ID: uuid.New(),
 		}).Asserts()
 	}))
+	s.Run("DeleteOldProvisionerDaemons", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.Now()).Asserts()
+	}))
+	s.Run("DeleteOldWebhookDeliveries", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.Now()).Asserts()
+	}))
+	s.Run("GetProvisionerKeyByHashedSecret", s.Subtest(func(db database.Store, check *expects) {
+		k := dbgen.ProvisionerKey(s.T(), db, database.ProvisionerKey{})
+		check.Args(k.HashedSecret).Asserts().Returns(k)
+	}))
+	s.Run("UpdateProvisionerDaemonLastSeenAt", s.Subtest(func(db database.Store, check *expects) {
+		d, err := db.InsertProvisionerDaemon(context.Background(), database.InsertProvisionerDaemonParams{
+			ID: uuid.New(),
+		})
+		require.NoError(s.T(), err)
+		check.Args(database.UpdateProvisionerDaemonLastSeenAtParams{
+			ID:         d.ID,
+			LastSeenAt: sql.NullTime{Time: database.Now(), Valid: true},
+		}).Asserts()
+	}))
 	s.Run("InsertTemplateVersionParameter", s.Subtest(func(db database.Store, check *expects) {
 		v := dbgen.TemplateVersion(s.T(), db, database.TemplateVersion{})
 		check.Args(database.InsertTemplateVersionParameterParams{
@@ -216,4 +271,118 @@ func (s *MethodTestSuite) TestSystemFunctions() {
 			ValidationTypeSystem:     database.ParameterTypeSystemNone,
 		}).Asserts()
 	}))
+	s.Run("InsertWebhookDelivery", s.Subtest(func(db database.Store, check *expects) {
+		w := dbgen.Webhook(s.T(), db, database.Webhook{})
+		check.Args(database.InsertWebhookDeliveryParams{
+			ID:        uuid.New(),
+			WebhookID: w.ID,
+			EventID:   uuid.New(),
+			Payload:   []byte("{}"),
+			Attempt:   1,
+		}).Asserts()
+	}))
+	s.Run("UpdateWebhookDeliveryByID", s.Subtest(func(db database.Store, check *expects) {
+		d := dbgen.WebhookDelivery(s.T(), db, database.WebhookDelivery{})
+		check.Args(database.UpdateWebhookDeliveryByIDParams{
+			ID: d.ID,
+		}).Asserts()
+	}))
+	s.Run("InsertNotificationMessage", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		check.Args(database.InsertNotificationMessageParams{
+			ID:        uuid.New(),
+			UserID:    u.ID,
+			Kind:      "workspace_autostop",
+			DedupeKey: uuid.NewString(),
+		}).Asserts()
+	}))
+	s.Run("DeleteNotificationMessageByID", s.Subtest(func(db database.Store, check *expects) {
+		u := dbgen.User(s.T(), db, database.User{})
+		m, err := db.InsertNotificationMessage(context.Background(), database.InsertNotificationMessageParams{
+			ID:        uuid.New(),
+			UserID:    u.ID,
+			Kind:      "workspace_autostop",
+			DedupeKey: uuid.NewString(),
+		})
+		s.NoError(err, "insert notification message")
+		check.Args(m.ID).Asserts()
+	}))
+	s.Run("DeleteOldNotificationMessages", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.Now()).Asserts()
+	}))
+	s.Run("DeleteUnusedFiles", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(time.Now()).Asserts()
+	}))
+	s.Run("DeleteArchivedTemplateVersionJobLogs", s.Subtest(func(db database.Store, check *expects) {
+		check.Args().Asserts()
+	}))
+	s.Run("DeleteOldWorkspaceBuildJobLogs", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.DeleteOldWorkspaceBuildJobLogsParams{CompletedBefore: time.Now(), RowLimit: 10}).Asserts()
+	}))
+	s.Run("GetAuditLogsForPurge", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.GetAuditLogsForPurgeParams{Before: time.Now(), RowLimit: 10}).Asserts()
+	}))
+	s.Run("InsertAuditLogArchive", s.Subtest(func(db database.Store, check *expects) {
+		check.Args(database.InsertAuditLogArchiveParams{ID: uuid.New()}).Asserts()
+	}))
+	s.Run("DeleteAuditLogsByIDs", s.Subtest(func(db database.Store, check *expects) {
+		check.Args([]uuid.UUID{uuid.New()}).Asserts()
+	}))
+	s.Run("GetFileHashInUse", s.Subtest(func(db database.Store, check *expects) {
+		f := dbgen.File(s.T(), db, database.File{})
+		check.Args(f.Hash).Asserts().Returns(true)
+	}))
+	s.Run("LockFileByID", s.Subtest(func(db database.Store, check *expects) {
+		f := dbgen.File(s.T(), db, database.File{})
+		check.Args(f.ID).Asserts().Returns(f.ID)
+	}))
+	s.Run("GetFileIDsByStorage", s.Subtest(func(db database.Store, check *expects) {
+		f := dbgen.File(s.T(), db, database.File{})
+		check.Args("database").Asserts().Returns([]uuid.UUID{f.ID})
+	}))
+	s.Run("UpdateFileStorageByID", s.Subtest(func(db database.Store, check *expects) {
+		f := dbgen.File(s.T(), db, database.File{})
+		check.Args(database.UpdateFileStorageByIDParams{
+			ID:      f.ID,
+			Storage: "fs",
+			Data:    []byte{},
+		}).Asserts()
+	}))
+	s.Run("GetTemplateGitSyncs", s.Subtest(func(db database.Store, check *expects) {
+		check.Args().Asserts()
+	}))
+	s.Run("UpdateTemplateGitSyncCommitByTemplateID", s.Subtest(func(db database.Store, check *expects) {
+		gs := dbgen.TemplateGitSync(s.T(), db, database.TemplateGitSync{})
+		check.Args(database.UpdateTemplateGitSyncCommitByTemplateIDParams{
+			TemplateID: gs.TemplateID,
+			CommitSHA:  "a1b2c3",
+		}).Asserts()
+	}))
+	s.Run("GetTemplateGitSyncsByTemplateIDs", s.Subtest(func(db database.Store, check *expects) {
+		gs := dbgen.TemplateGitSync(s.T(), db, database.TemplateGitSync{})
+		check.Args([]uuid.UUID{gs.TemplateID}).Asserts().Returns([]database.TemplateGitSync{gs})
+	}))
+	s.Run("UpdateTemplateGitSyncDryRunJobByTemplateID", s.Subtest(func(db database.Store, check *expects) {
+		gs := dbgen.TemplateGitSync(s.T(), db, database.TemplateGitSync{})
+		versionID := uuid.NullUUID{UUID: uuid.New(), Valid: true}
+		_, err := db.UpdateTemplateGitSyncStatusByTemplateID(context.Background(), database.UpdateTemplateGitSyncStatusByTemplateIDParams{
+			TemplateID:        gs.TemplateID,
+			CommitSHA:         gs.CommitSHA,
+			TemplateVersionID: versionID,
+			Status:            "pending",
+		})
+		require.NoError(s.T(), err)
+		check.Args(database.UpdateTemplateGitSyncDryRunJobByTemplateIDParams{
+			TemplateID:        gs.TemplateID,
+			TemplateVersionID: versionID,
+			DryRunJobID:       uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		}).Asserts()
+	}))
+	s.Run("UpdateTemplateGitSyncStatusByTemplateID", s.Subtest(func(db database.Store, check *expects) {
+		gs := dbgen.TemplateGitSync(s.T(), db, database.TemplateGitSync{})
+		check.Args(database.UpdateTemplateGitSyncStatusByTemplateIDParams{
+			TemplateID: gs.TemplateID,
+			Status:     "succeeded",
+		}).Asserts()
+	}))
 }
@@ -1,6 +1,7 @@
 package dbfake
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
@@ -64,6 +65,10 @@ func New() database.Store {
 			workspaceApps:             make([]database.WorkspaceApp, 0),
 			workspaces:                make([]database.Workspace, 0),
 			licenses:                  make([]database.License, 0),
+			webhooks:                  make([]database.Webhook, 0),
+			webhookDeliveries:         make([]database.WebhookDelivery, 0),
+			notificationPreferences:   make([]database.NotificationPreference, 0),
+			notificationMessages:      make([]database.NotificationMessage, 0),
 		},
 	}
 }
@@ -100,6 +105,7 @@ type data struct {
 	// New tables
 	workspaceAgentStats       []database.WorkspaceAgentStat
 	auditLogs                 []database.AuditLog
+	auditLogArchives          []database.AuditLogArchive
 	files                     []database.File
 	gitAuthLinks              []database.GitAuthLink
 	gitSSHKey                 []database.GitSSHKey
@@ -109,6 +115,7 @@ type data struct {
 	parameterSchemas          []database.ParameterSchema
 	parameterValues           []database.ParameterValue
 	provisionerDaemons        []database.ProvisionerDaemon
+	provisionerKeys           []database.ProvisionerKey
 	provisionerJobLogs        []database.ProvisionerJobLog
 	provisionerJobs           []database.ProvisionerJob
 	replicas                  []database.Replica
@@ -123,6 +130,11 @@ type data struct {
 	workspaceResourceMetadata []database.WorkspaceResourceMetadatum
 	workspaceResources        []database.WorkspaceResource
 	workspaces                []database.Workspace
+	webhooks                  []database.Webhook
+	webhookDeliveries         []database.WebhookDelivery
+	templateGitSyncs          []database.TemplateGitSync
+	notificationPreferences   []database.NotificationPreference
+	notificationMessages      []database.NotificationMessage
 
 	deploymentID    string
 	derpMeshKey     string
@@ -211,10 +223,36 @@ func (q *fakeQuerier) AcquireProvisionerJob(_ context.Context, arg database.Acqu
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
+	runningByInitiator := map[uuid.UUID]int{}
+	runningByOrganization := map[uuid.UUID]int{}
+	for _, provisionerJob := range q.provisionerJobs {
+		if provisionerJob.StartedAt.Valid && !provisionerJob.CompletedAt.Valid {
+			runningByInitiator[provisionerJob.InitiatorID]++
+			runningByOrganization[provisionerJob.OrganizationID]++
+		}
+	}
+	// before reports whether a is acquired before b.
+	before := func(a, b database.ProvisionerJob) bool {
+		if a.Priority != b.Priority {
+			return provisionerJobPriorityRank(a.Priority) < provisionerJobPriorityRank(b.Priority)
+		}
+		if runningByInitiator[a.InitiatorID] != runningByInitiator[b.InitiatorID] {
+			return runningByInitiator[a.InitiatorID] < runningByInitiator[b.InitiatorID]
+		}
+		if runningByOrganization[a.OrganizationID] != runningByOrganization[b.OrganizationID] {
+			return runningByOrganization[a.OrganizationID] < runningByOrganization[b.OrganizationID]
+		}
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+
+	acquire := -1
 	for index, provisionerJob := range q.provisionerJobs {
 		if provisionerJob.StartedAt.Valid {
 			continue
 		}
+		if arg.OrganizationID != uuid.Nil && provisionerJob.OrganizationID != arg.OrganizationID {
+			continue
+		}
 		found := false
 		for _, provisionerType := range arg.Types {
 			if provisionerJob.Provisioner != provisionerType {
@@ -249,13 +287,31 @@ func (q *fakeQuerier) AcquireProvisionerJob(_ context.Context, arg database.Acqu
 		if missing {
 			continue
 		}
-		provisionerJob.StartedAt = arg.StartedAt
-		provisionerJob.UpdatedAt = arg.StartedAt.Time
-		provisionerJob.WorkerID = arg.WorkerID
-		q.provisionerJobs[index] = provisionerJob
-		return provisionerJob, nil
+		if acquire == -1 || before(provisionerJob, q.provisionerJobs[acquire]) {
+			acquire = index
+		}
 	}
-	return database.ProvisionerJob{}, sql.ErrNoRows
+	if acquire == -1 {
+		return database.ProvisionerJob{}, sql.ErrNoRows
+	}
+	provisionerJob := q.provisionerJobs[acquire]
+	provisionerJob.StartedAt = arg.StartedAt
+	provisionerJob.UpdatedAt = arg.StartedAt.Time
+	provisionerJob.LastHeartbeatAt = arg.StartedAt
+	provisionerJob.WorkerID = arg.WorkerID
+	q.provisionerJobs[acquire] = provisionerJob
+	return provisionerJob, nil
+}
+
+// provisionerJobPriorityRank returns the position of the priority in the
+// enum, which is the order jobs are acquired in.
+func provisionerJobPriorityRank(priority database.ProvisionerJobPriority) int {
+	for rank, value := range database.AllProvisionerJobPriorityValues() {
+		if value == priority {
+			return rank
+		}
+	}
+	return len(database.AllProvisionerJobPriorityValues())
 }
 
 func (*fakeQuerier) DeleteOldWorkspaceAgentStats(_ context.Context) error {
@@ -284,6 +340,7 @@ func (q *fakeQuerier) InsertWorkspaceAgentStat(_ context.Context, p database.Ins
 		TxPackets:          p.TxPackets,
 		TxBytes:            p.TxBytes,
 		TemplateID:         p.TemplateID,
+		ConnectionsByPort:  p.ConnectionsByPort,
 	}
 	q.workspaceAgentStats = append(q.workspaceAgentStats, stat)
 	return stat, nil
@@ -419,6 +476,342 @@ func (q *fakeQuerier) GetTemplateAverageBuildTime(ctx context.Context, arg datab
 	return row, nil
 }
 
+func (q *fakeQuerier) GetTemplateActiveWorkspaces(_ context.Context, startTime time.Time) ([]database.GetTemplateActiveWorkspacesRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	type key struct {
+		templateID uuid.UUID
+		date       time.Time
+	}
+	seen := make(map[key]map[uuid.UUID]struct{})
+	var keys []key
+	for _, as := range q.workspaceAgentStats {
+		if as.CreatedAt.Before(startTime) {
+			continue
+		}
+		k := key{templateID: as.TemplateID, date: as.CreatedAt.UTC().Truncate(time.Hour * 24)}
+		if seen[k] == nil {
+			seen[k] = make(map[uuid.UUID]struct{})
+			keys = append(keys, k)
+		}
+		seen[k][as.WorkspaceID] = struct{}{}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].date.Before(keys[j].date)
+	})
+
+	rows := make([]database.GetTemplateActiveWorkspacesRow, 0, len(keys))
+	for _, k := range keys {
+		rows = append(rows, database.GetTemplateActiveWorkspacesRow{
+			TemplateID: k.templateID,
+			Date:       k.date,
+			Workspaces: int64(len(seen[k])),
+		})
+	}
+	return rows, nil
+}
+
+func (q *fakeQuerier) GetTemplateBuildInsights(ctx context.Context, startTime time.Time) ([]database.GetTemplateBuildInsightsRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var rows []database.GetTemplateBuildInsightsRow
+	for _, wb := range q.workspaceBuilds {
+		if wb.CreatedAt.Before(startTime) {
+			continue
+		}
+		version, err := q.getTemplateVersionByIDNoLock(ctx, wb.TemplateVersionID)
+		if err != nil {
+			return nil, err
+		}
+		if !version.TemplateID.Valid {
+			continue
+		}
+		job, err := q.getProvisionerJobByIDNoLock(ctx, wb.JobID)
+		if err != nil {
+			return nil, err
+		}
+		if !job.StartedAt.Valid || !job.CompletedAt.Valid || job.CanceledAt.Valid {
+			continue
+		}
+		rows = append(rows, database.GetTemplateBuildInsightsRow{
+			TemplateID:  version.TemplateID,
+			Date:        wb.CreatedAt.UTC().Truncate(time.Hour * 24),
+			Transition:  wb.Transition,
+			ExecTimeSec: job.CompletedAt.Time.Sub(job.StartedAt.Time).Seconds(),
+			Failed:      job.Error.Valid && job.Error.String != "",
+		})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Date.Before(rows[j].Date)
+	})
+	return rows, nil
+}
+
+func (q *fakeQuerier) GetTemplateConnectionInsights(_ context.Context, startTime time.Time) ([]database.GetTemplateConnectionInsightsRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	type key struct {
+		templateID uuid.UUID
+		date       time.Time
+		port       string
+	}
+	connections := make(map[key]int64)
+	var keys []key
+	for _, as := range q.workspaceAgentStats {
+		if as.CreatedAt.Before(startTime) || len(as.ConnectionsByPort) == 0 {
+			continue
+		}
+		var byPort map[string]int64
+		err := json.Unmarshal(as.ConnectionsByPort, &byPort)
+		if err != nil {
+			return nil, err
+		}
+		for port, count := range byPort {
+			k := key{templateID: as.TemplateID, date: as.CreatedAt.UTC().Truncate(time.Hour * 24), port: port}
+			if _, ok := connections[k]; !ok {
+				keys = append(keys, k)
+			}
+			connections[k] += count
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].date.Before(keys[j].date)
+	})
+
+	rows := make([]database.GetTemplateConnectionInsightsRow, 0, len(keys))
+	for _, k := range keys {
+		rows = append(rows, database.GetTemplateConnectionInsightsRow{
+			TemplateID:  k.templateID,
+			Date:        k.date,
+			Port:        k.port,
+			Connections: connections[k],
+		})
+	}
+	return rows, nil
+}
+
+func (q *fakeQuerier) GetTemplateInsightsApps(_ context.Context, startTime time.Time) ([]database.GetTemplateInsightsAppsRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	type key struct {
+		templateID uuid.UUID
+		slug       string
+	}
+	latest := make(map[key]database.WorkspaceApp)
+	for _, app := range q.workspaceApps {
+		if !app.Url.Valid || app.External {
+			continue
+		}
+		templateID, ok := q.templateIDOfAgentBuiltAfterNoLock(app.AgentID, startTime)
+		if !ok {
+			continue
+		}
+		k := key{templateID: templateID, slug: app.Slug}
+		if existing, ok := latest[k]; ok && existing.CreatedAt.After(app.CreatedAt) {
+			continue
+		}
+		latest[k] = app
+	}
+
+	rows := make([]database.GetTemplateInsightsAppsRow, 0, len(latest))
+	for k, app := range latest {
+		rows = append(rows, database.GetTemplateInsightsAppsRow{
+			TemplateID:  k.templateID,
+			Slug:        app.Slug,
+			DisplayName: app.DisplayName,
+			Icon:        app.Icon,
+			Url:         app.Url,
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].TemplateID != rows[j].TemplateID {
+			return rows[i].TemplateID.String() < rows[j].TemplateID.String()
+		}
+		return rows[i].Slug < rows[j].Slug
+	})
+	return rows, nil
+}
+
+// templateIDOfAgentBuiltAfterNoLock returns the template of the workspace
+// whose build created the agent, if that build was created after the given
+// time.
+func (q *fakeQuerier) templateIDOfAgentBuiltAfterNoLock(agentID uuid.UUID, after time.Time) (uuid.UUID, bool) {
+	for _, agent := range q.workspaceAgents {
+		if agent.ID != agentID {
+			continue
+		}
+		for _, resource := range q.workspaceResources {
+			if resource.ID != agent.ResourceID {
+				continue
+			}
+			for _, build := range q.workspaceBuilds {
+				if build.JobID != resource.JobID || build.CreatedAt.Before(after) {
+					continue
+				}
+				for _, workspace := range q.workspaces {
+					if workspace.ID == build.WorkspaceID {
+						return workspace.TemplateID, true
+					}
+				}
+			}
+		}
+	}
+	return uuid.Nil, false
+}
+
+func (q *fakeQuerier) GetTemplateVersionAdoption(ctx context.Context) ([]database.GetTemplateVersionAdoptionRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	latest := make(map[uuid.UUID]database.WorkspaceBuild)
+	for _, build := range q.workspaceBuilds {
+		if existing, ok := latest[build.WorkspaceID]; ok && existing.BuildNumber > build.BuildNumber {
+			continue
+		}
+		latest[build.WorkspaceID] = build
+	}
+
+	workspaces := make(map[uuid.UUID]int64)
+	var versionIDs []uuid.UUID
+	for _, workspace := range q.workspaces {
+		if workspace.Deleted {
+			continue
+		}
+		build, ok := latest[workspace.ID]
+		if !ok {
+			continue
+		}
+		if _, ok := workspaces[build.TemplateVersionID]; !ok {
+			versionIDs = append(versionIDs, build.TemplateVersionID)
+		}
+		workspaces[build.TemplateVersionID]++
+	}
+
+	rows := make([]database.GetTemplateVersionAdoptionRow, 0, len(versionIDs))
+	for _, versionID := range versionIDs {
+		version, err := q.getTemplateVersionByIDNoLock(ctx, versionID)
+		if err != nil {
+			return nil, err
+		}
+		if !version.TemplateID.Valid {
+			continue
+		}
+		rows = append(rows, database.GetTemplateVersionAdoptionRow{
+			TemplateID:        version.TemplateID,
+			TemplateVersionID: version.ID,
+			Name:              version.Name,
+			Workspaces:        workspaces[versionID],
+		})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Workspaces > rows[j].Workspaces
+	})
+	return rows, nil
+}
+
+func (q *fakeQuerier) GetUserActivityInsights(ctx context.Context, arg database.GetUserActivityInsightsParams) ([]database.GetUserActivityInsightsRow, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	inRange := func(t time.Time) bool {
+		return !t.Before(arg.StartTime) && t.Before(arg.EndTime)
+	}
+
+	buckets := make(map[uuid.UUID]map[int64]struct{})
+	for _, as := range q.workspaceAgentStats {
+		if as.ConnectionCount <= 0 || !inRange(as.CreatedAt) {
+			continue
+		}
+		if buckets[as.UserID] == nil {
+			buckets[as.UserID] = make(map[int64]struct{})
+		}
+		buckets[as.UserID][as.CreatedAt.Unix()/arg.ReportIntervalSeconds] = struct{}{}
+	}
+
+	owners := make(map[uuid.UUID]uuid.UUID)
+	owned := make(map[uuid.UUID]int64)
+	for _, workspace := range q.workspaces {
+		owners[workspace.ID] = workspace.OwnerID
+		if !workspace.Deleted {
+			owned[workspace.OwnerID]++
+		}
+	}
+
+	builds := make(map[uuid.UUID]int64)
+	byWorkspace := make(map[uuid.UUID][]database.WorkspaceBuild)
+	for _, build := range q.workspaceBuilds {
+		if inRange(build.CreatedAt) {
+			builds[build.InitiatorID]++
+		}
+		byWorkspace[build.WorkspaceID] = append(byWorkspace[build.WorkspaceID], build)
+	}
+
+	costs := make(map[uuid.UUID]float64)
+	now := time.Now()
+	for workspaceID, workspaceBuilds := range byWorkspace {
+		sort.Slice(workspaceBuilds, func(i, j int) bool {
+			return workspaceBuilds[i].BuildNumber < workspaceBuilds[j].BuildNumber
+		})
+		for i, build := range workspaceBuilds {
+			if build.Transition != database.WorkspaceTransitionStart || build.DailyCost <= 0 {
+				continue
+			}
+			job, err := q.getProvisionerJobByIDNoLock(ctx, build.JobID)
+			if err != nil {
+				return nil, err
+			}
+			if !job.CompletedAt.Valid || (job.Error.Valid && job.Error.String != "") {
+				continue
+			}
+			stoppedAt := now
+			if i+1 < len(workspaceBuilds) {
+				stoppedAt = workspaceBuilds[i+1].CreatedAt
+			}
+			startedAt := job.CompletedAt.Time
+			if startedAt.Before(arg.StartTime) {
+				startedAt = arg.StartTime
+			}
+			if stoppedAt.After(arg.EndTime) {
+				stoppedAt = arg.EndTime
+			}
+			if !startedAt.Before(stoppedAt) {
+				continue
+			}
+			costs[owners[workspaceID]] += float64(build.DailyCost) * stoppedAt.Sub(startedAt).Hours() / 24
+		}
+	}
+
+	rows := make([]database.GetUserActivityInsightsRow, 0, len(q.users))
+	for _, user := range q.users {
+		if user.Deleted {
+			continue
+		}
+		rows = append(rows, database.GetUserActivityInsightsRow{
+			UserID:           user.ID,
+			Username:         user.Username,
+			Email:            user.Email,
+			Status:           user.Status,
+			LastSeenAt:       user.LastSeenAt,
+			Workspaces:       owned[user.ID],
+			Builds:           builds[user.ID],
+			ConnectedSeconds: int64(len(buckets[user.ID])) * arg.ReportIntervalSeconds,
+			Cost:             costs[user.ID],
+		})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Username < rows[j].Username
+	})
+	return rows, nil
+}
+
 func (q *fakeQuerier) ParameterValue(_ context.Context, id uuid.UUID) (database.ParameterValue, error) {
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
@@ -799,6 +1192,79 @@ func (q *fakeQuerier) GetUsers(_ context.Context, params database.GetUsersParams
 		users = usersFilteredByRole
 	}
 
+	if len(params.LoginType) > 0 {
+		usersFilteredByLoginType := make([]database.User, 0, len(users))
+		for i, user := range users {
+			if slices.Contains(params.LoginType, user.LoginType) {
+				usersFilteredByLoginType = append(usersFilteredByLoginType, users[i])
+			}
+		}
+		users = usersFilteredByLoginType
+	}
+
+	if !params.LastSeenBefore.IsZero() {
+		usersFilteredByLastSeen := make([]database.User, 0, len(users))
+		for i, user := range users {
+			if user.LastSeenAt.Before(params.LastSeenBefore) {
+				usersFilteredByLastSeen = append(usersFilteredByLastSeen, users[i])
+			}
+		}
+		users = usersFilteredByLastSeen
+	}
+
+	if !params.CreatedAfter.IsZero() {
+		usersFilteredByCreatedAt := make([]database.User, 0, len(users))
+		for i, user := range users {
+			if !user.CreatedAt.Before(params.CreatedAfter) {
+				usersFilteredByCreatedAt = append(usersFilteredByCreatedAt, users[i])
+			}
+		}
+		users = usersFilteredByCreatedAt
+	}
+
+	if params.GroupName != "" {
+		members := make(map[uuid.UUID]bool)
+		for _, group := range q.groups {
+			if !strings.EqualFold(group.Name, params.GroupName) || group.OrganizationID != params.OrganizationID {
+				continue
+			}
+			for _, member := range q.groupMembers {
+				if member.GroupID == group.ID {
+					members[member.UserID] = true
+				}
+			}
+			// The "Everyone" group shares the ID of its organization.
+			for _, member := range q.organizationMembers {
+				if member.OrganizationID == group.ID {
+					members[member.UserID] = true
+				}
+			}
+		}
+		usersFilteredByGroup := make([]database.User, 0, len(users))
+		for i, user := range users {
+			if members[user.ID] {
+				usersFilteredByGroup = append(usersFilteredByGroup, users[i])
+			}
+		}
+		users = usersFilteredByGroup
+	}
+
+	if params.OrganizationID != uuid.Nil {
+		members := make(map[uuid.UUID]bool)
+		for _, member := range q.organizationMembers {
+			if member.OrganizationID == params.OrganizationID {
+				members[member.UserID] = true
+			}
+		}
+		usersFilteredByOrganization := make([]database.User, 0, len(users))
+		for i, user := range users {
+			if members[user.ID] {
+				usersFilteredByOrganization = append(usersFilteredByOrganization, users[i])
+			}
+		}
+		users = usersFilteredByOrganization
+	}
+
 	beforePageCount := len(users)
 
 	if params.OffsetOpt > 0 {
@@ -921,28 +1387,34 @@ func (q *fakeQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg database.
 
 	if prepared != nil {
 		// Call this to match the same function calls as the SQL implementation.
-		_, err := prepared.CompileToSQL(ctx, rbac.ConfigWithoutACL())
+		_, err := prepared.CompileToSQL(ctx, rbac.ConfigWithACL())
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	// skip reports whether a workspace should be filtered out, given whether
+	// it matched the filter for key.
+	skip := func(key string, matched bool) bool {
+		return matched == slices.Contains(arg.NegatedFilters, key)
+	}
+
 	workspaces := make([]database.Workspace, 0)
 	for _, workspace := range q.workspaces {
-		if arg.OwnerID != uuid.Nil && workspace.OwnerID != arg.OwnerID {
+		if arg.OwnerID != uuid.Nil && skip("owner", workspace.OwnerID == arg.OwnerID) {
 			continue
 		}
 
 		if arg.OwnerUsername != "" {
 			owner, err := q.getUserByIDNoLock(workspace.OwnerID)
-			if err == nil && !strings.EqualFold(arg.OwnerUsername, owner.Username) {
+			if skip("owner", err != nil || strings.EqualFold(arg.OwnerUsername, owner.Username)) {
 				continue
 			}
 		}
 
 		if arg.TemplateName != "" {
 			template, err := q.getTemplateByIDNoLock(ctx, workspace.TemplateID)
-			if err == nil && !strings.EqualFold(arg.TemplateName, template.Name) {
+			if skip("template", err != nil || strings.EqualFold(arg.TemplateName, template.Name)) {
 				continue
 			}
 		}
@@ -951,10 +1423,27 @@ func (q *fakeQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg database.
 			continue
 		}
 
-		if arg.Name != "" && !strings.Contains(strings.ToLower(workspace.Name), strings.ToLower(arg.Name)) {
+		if arg.Name != "" && skip("name", strings.Contains(strings.ToLower(workspace.Name), strings.ToLower(arg.Name))) {
 			continue
 		}
 
+		if len(arg.Labels) > 0 {
+			var labels map[string]string
+			if err := json.Unmarshal(arg.Labels, &labels); err != nil {
+				return nil, xerrors.Errorf("unmarshal labels: %w", err)
+			}
+			matches := true
+			for key, value := range labels {
+				if v, ok := workspace.Labels[key]; !ok || v != value {
+					matches = false
+					break
+				}
+			}
+			if skip("label", matches) {
+				continue
+			}
+		}
+
 		if arg.Status != "" {
 			build, err := q.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
 			if err != nil {
@@ -966,82 +1455,66 @@ func (q *fakeQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg database.
 				return nil, xerrors.Errorf("get provisioner job: %w", err)
 			}
 
+			var matched bool
 			switch database.WorkspaceStatus(arg.Status) {
 			case database.WorkspaceStatusPending:
-				if !job.StartedAt.Valid {
-					continue
-				}
+				matched = job.StartedAt.Valid
 
 			case database.WorkspaceStatusStarting:
-				if !job.StartedAt.Valid &&
+				matched = !(!job.StartedAt.Valid &&
 					!job.CanceledAt.Valid &&
 					job.CompletedAt.Valid &&
 					time.Since(job.UpdatedAt) > 30*time.Second ||
-					build.Transition != database.WorkspaceTransitionStart {
-					continue
-				}
+					build.Transition != database.WorkspaceTransitionStart)
 
 			case database.WorkspaceStatusRunning:
-				if !job.CompletedAt.Valid &&
+				matched = !(!job.CompletedAt.Valid &&
 					job.CanceledAt.Valid &&
 					job.Error.Valid ||
-					build.Transition != database.WorkspaceTransitionStart {
-					continue
-				}
+					build.Transition != database.WorkspaceTransitionStart)
 
 			case database.WorkspaceStatusStopping:
-				if !job.StartedAt.Valid &&
+				matched = !(!job.StartedAt.Valid &&
 					!job.CanceledAt.Valid &&
 					job.CompletedAt.Valid &&
 					time.Since(job.UpdatedAt) > 30*time.Second ||
-					build.Transition != database.WorkspaceTransitionStop {
-					continue
-				}
+					build.Transition != database.WorkspaceTransitionStop)
 
 			case database.WorkspaceStatusStopped:
-				if !job.CompletedAt.Valid &&
+				matched = !(!job.CompletedAt.Valid &&
 					job.CanceledAt.Valid &&
 					job.Error.Valid ||
-					build.Transition != database.WorkspaceTransitionStop {
-					continue
-				}
+					build.Transition != database.WorkspaceTransitionStop)
 
 			case database.WorkspaceStatusFailed:
-				if (!job.CanceledAt.Valid && !job.Error.Valid) ||
-					(!job.CompletedAt.Valid && !job.Error.Valid) {
-					continue
-				}
+				matched = !((!job.CanceledAt.Valid && !job.Error.Valid) ||
+					(!job.CompletedAt.Valid && !job.Error.Valid))
 
 			case database.WorkspaceStatusCanceling:
-				if !job.CanceledAt.Valid && job.CompletedAt.Valid {
-					continue
-				}
+				matched = !(!job.CanceledAt.Valid && job.CompletedAt.Valid)
 
 			case database.WorkspaceStatusCanceled:
-				if !job.CanceledAt.Valid && !job.CompletedAt.Valid {
-					continue
-				}
+				matched = !(!job.CanceledAt.Valid && !job.CompletedAt.Valid)
 
 			case database.WorkspaceStatusDeleted:
-				if !job.StartedAt.Valid &&
+				matched = !(!job.StartedAt.Valid &&
 					job.CanceledAt.Valid &&
 					!job.CompletedAt.Valid &&
 					time.Since(job.UpdatedAt) > 30*time.Second ||
-					build.Transition != database.WorkspaceTransitionDelete {
-					continue
-				}
+					build.Transition != database.WorkspaceTransitionDelete)
 
 			case database.WorkspaceStatusDeleting:
-				if !job.CompletedAt.Valid &&
+				matched = !(!job.CompletedAt.Valid &&
 					job.CanceledAt.Valid &&
 					job.Error.Valid &&
-					build.Transition != database.WorkspaceTransitionDelete {
-					continue
-				}
+					build.Transition != database.WorkspaceTransitionDelete)
 
 			default:
 				return nil, xerrors.Errorf("unknown workspace status in filter: %q", arg.Status)
 			}
+			if skip("status", matched) {
+				continue
+			}
 		}
 
 		if arg.HasAgent != "" {
@@ -1077,14 +1550,66 @@ func (q *fakeQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg database.
 				}
 			}
 
-			if !hasAgentMatched {
+			if skip("agent_status", hasAgentMatched) {
 				continue
 			}
 		}
 
-		if len(arg.TemplateIds) > 0 {
-			match := false
-			for _, id := range arg.TemplateIds {
+		if arg.BuildReason != "" {
+			build, err := q.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+			if err != nil {
+				return nil, xerrors.Errorf("get latest build: %w", err)
+			}
+			if skip("build_reason", string(build.Reason) == arg.BuildReason) {
+				continue
+			}
+		}
+
+		if arg.Outdated {
+			build, err := q.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+			if err != nil {
+				return nil, xerrors.Errorf("get latest build: %w", err)
+			}
+			template, err := q.getTemplateByIDNoLock(ctx, workspace.TemplateID)
+			if err != nil {
+				return nil, xerrors.Errorf("get template: %w", err)
+			}
+			if skip("outdated", build.TemplateVersionID != template.ActiveVersionID) {
+				continue
+			}
+		}
+
+		if arg.HasFailedBuild {
+			var hasFailedBuild bool
+			for _, build := range q.workspaceBuilds {
+				if build.WorkspaceID != workspace.ID {
+					continue
+				}
+				job, err := q.getProvisionerJobByIDNoLock(ctx, build.JobID)
+				if err != nil {
+					return nil, xerrors.Errorf("get provisioner job: %w", err)
+				}
+				if job.CompletedAt.Valid && job.Error.String != "" {
+					hasFailedBuild = true
+					break
+				}
+			}
+			if skip("has_failed_build", hasFailedBuild) {
+				continue
+			}
+		}
+
+		if !arg.LastUsedBefore.IsZero() && skip("last_used_before", workspace.LastUsedAt.Before(arg.LastUsedBefore)) {
+			continue
+		}
+
+		if !arg.LastUsedAfter.IsZero() && skip("last_used_after", !workspace.LastUsedAt.Before(arg.LastUsedAfter)) {
+			continue
+		}
+
+		if len(arg.TemplateIds) > 0 {
+			match := false
+			for _, id := range arg.TemplateIds {
 				if workspace.TemplateID == id {
 					match = true
 					break
@@ -1120,6 +1645,46 @@ func (q *fakeQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg database.
 	return convertToWorkspaceRows(workspaces, int64(beforePageCount)), nil
 }
 
+func (q *fakeQuerier) GetAuthorizedWorkspacesUnion(ctx context.Context, args []database.GetWorkspacesParams, prepared rbac.PreparedAuthorized) ([]database.GetWorkspacesRow, error) {
+	if len(args) == 0 {
+		return nil, xerrors.New("at least one filter is required")
+	}
+	matched := make(map[uuid.UUID]bool)
+	for _, arg := range args {
+		arg.Offset = 0
+		arg.Limit = 0
+		rows, err := q.GetAuthorizedWorkspaces(ctx, arg, prepared)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			matched[row.ID] = true
+		}
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	// Workspaces are kept in the order GetAuthorizedWorkspaces returns them.
+	workspaces := make([]database.Workspace, 0, len(matched))
+	for _, workspace := range q.workspaces {
+		if matched[workspace.ID] {
+			workspaces = append(workspaces, workspace)
+		}
+	}
+	beforePageCount := len(workspaces)
+	if args[0].Offset > 0 {
+		if int(args[0].Offset) > len(workspaces) {
+			return []database.GetWorkspacesRow{}, nil
+		}
+		workspaces = workspaces[args[0].Offset:]
+	}
+	if args[0].Limit > 0 && int(args[0].Limit) < len(workspaces) {
+		workspaces = workspaces[:args[0].Limit]
+	}
+	return convertToWorkspaceRows(workspaces, int64(beforePageCount)), nil
+}
+
 // mapAgentStatus determines the agent status based on different timestamps like created_at, last_connected_at, disconnected_at, etc.
 // The function must be in sync with: coderd/workspaceagents.go:convertWorkspaceAgent.
 func mapAgentStatus(dbAgent database.WorkspaceAgent, agentInactiveDisconnectTimeoutSeconds int64) string {
@@ -1169,6 +1734,8 @@ func convertToWorkspaceRows(workspaces []database.Workspace, count int64) []data
 			AutostartSchedule: w.AutostartSchedule,
 			Ttl:               w.Ttl,
 			LastUsedAt:        w.LastUsedAt,
+			UserACL:           w.UserACL,
+			GroupACL:          w.GroupACL,
 			Count:             count,
 		}
 	}
@@ -1661,6 +2228,27 @@ func (q *fakeQuerier) GetTemplateByOrganizationAndName(_ context.Context, arg da
 	return database.Template{}, sql.ErrNoRows
 }
 
+func (q *fakeQuerier) UpdateTemplateDeprecatedByID(_ context.Context, arg database.UpdateTemplateDeprecatedByIDParams) (database.Template, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.Template{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for idx, tpl := range q.templates {
+		if tpl.ID != arg.ID {
+			continue
+		}
+		tpl.UpdatedAt = arg.UpdatedAt
+		tpl.Deprecated = arg.Deprecated
+		q.templates[idx] = tpl
+		return tpl, nil
+	}
+
+	return database.Template{}, sql.ErrNoRows
+}
+
 func (q *fakeQuerier) UpdateTemplateMetaByID(_ context.Context, arg database.UpdateTemplateMetaByIDParams) (database.Template, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.Template{}, err
@@ -1739,6 +2327,42 @@ func (q *fakeQuerier) GetAuthorizedTemplates(ctx context.Context, arg database.G
 				continue
 			}
 		}
+
+		if arg.FuzzyName != "" && !strings.Contains(strings.ToLower(template.Name), strings.ToLower(arg.FuzzyName)) {
+			continue
+		}
+
+		if arg.Provisioner != "" && string(template.Provisioner) != arg.Provisioner {
+			continue
+		}
+
+		if arg.CreatedBy != uuid.Nil && template.CreatedBy != arg.CreatedBy {
+			continue
+		}
+
+		if arg.CreatedByUsername != "" {
+			creator, err := q.getUserByIDNoLock(template.CreatedBy)
+			if err != nil || creator.Deleted || !strings.EqualFold(creator.Username, arg.CreatedByUsername) {
+				continue
+			}
+		}
+
+		if arg.HasActiveWorkspaces.Valid {
+			hasActiveWorkspaces := slices.ContainsFunc(q.workspaces, func(workspace database.Workspace) bool {
+				return workspace.TemplateID == template.ID && !workspace.Deleted
+			})
+			if hasActiveWorkspaces != arg.HasActiveWorkspaces.Bool {
+				continue
+			}
+		}
+
+		if arg.Deprecated.Valid && (template.Deprecated != "") != arg.Deprecated.Bool {
+			continue
+		}
+
+		if arg.Search != "" && !templateMatchesSearch(template, arg.Search) {
+			continue
+		}
 		templates = append(templates, template)
 	}
 	if len(templates) > 0 {
@@ -1748,12 +2372,39 @@ func (q *fakeQuerier) GetAuthorizedTemplates(ctx context.Context, arg database.G
 			}
 			return i.ID.String() < j.ID.String()
 		})
+		if arg.OffsetOpt > 0 {
+			if int(arg.OffsetOpt) >= len(templates) {
+				return nil, sql.ErrNoRows
+			}
+			templates = templates[arg.OffsetOpt:]
+		}
+		if arg.LimitOpt > 0 && int(arg.LimitOpt) < len(templates) {
+			templates = templates[:arg.LimitOpt]
+		}
 		return templates, nil
 	}
 
 	return nil, sql.ErrNoRows
 }
 
+// templateMatchesSearch approximates the search of GetTemplatesWithFilter. The
+// full-text search of the description is emulated by requiring every word of
+// the search to be in the description.
+func templateMatchesSearch(template database.Template, search string) bool {
+	search = strings.ToLower(search)
+	if strings.Contains(strings.ToLower(template.Name), search) ||
+		strings.Contains(strings.ToLower(template.DisplayName), search) {
+		return true
+	}
+	description := strings.ToLower(template.Description)
+	for _, word := range strings.Fields(search) {
+		if !strings.Contains(description, word) {
+			return false
+		}
+	}
+	return true
+}
+
 func (q *fakeQuerier) GetTemplateVersionsByTemplateID(_ context.Context, arg database.GetTemplateVersionsByTemplateIDParams) (version []database.TemplateVersion, err error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return version, err
@@ -1766,6 +2417,9 @@ func (q *fakeQuerier) GetTemplateVersionsByTemplateID(_ context.Context, arg dat
 		if templateVersion.TemplateID.UUID != arg.TemplateID {
 			continue
 		}
+		if arg.Archived.Valid && arg.Archived.Bool != templateVersion.Archived {
+			continue
+		}
 		version = append(version, templateVersion)
 	}
 
@@ -2128,6 +2782,84 @@ func (q *fakeQuerier) GetTemplateGroupRoles(_ context.Context, id uuid.UUID) ([]
 	return groups, nil
 }
 
+func (q *fakeQuerier) GetWorkspaceUserRoles(_ context.Context, id uuid.UUID) ([]database.WorkspaceUser, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var workspace database.Workspace
+	for _, w := range q.workspaces {
+		if w.ID == id {
+			workspace = w
+			break
+		}
+	}
+
+	if workspace.ID == uuid.Nil {
+		return nil, sql.ErrNoRows
+	}
+
+	users := make([]database.WorkspaceUser, 0, len(workspace.UserACL))
+	for k, v := range workspace.UserACL {
+		user, err := q.getUserByIDNoLock(uuid.MustParse(k))
+		if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+			return nil, xerrors.Errorf("get user by ID: %w", err)
+		}
+		// We don't delete users from the map if they
+		// get deleted so just skip.
+		if xerrors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+
+		if user.Deleted || user.Status == database.UserStatusSuspended {
+			continue
+		}
+
+		users = append(users, database.WorkspaceUser{
+			User:    user,
+			Actions: v,
+		})
+	}
+
+	return users, nil
+}
+
+func (q *fakeQuerier) GetWorkspaceGroupRoles(_ context.Context, id uuid.UUID) ([]database.WorkspaceGroup, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	var workspace database.Workspace
+	for _, w := range q.workspaces {
+		if w.ID == id {
+			workspace = w
+			break
+		}
+	}
+
+	if workspace.ID == uuid.Nil {
+		return nil, sql.ErrNoRows
+	}
+
+	groups := make([]database.WorkspaceGroup, 0, len(workspace.GroupACL))
+	for k, v := range workspace.GroupACL {
+		group, err := q.GetGroupByID(context.Background(), uuid.MustParse(k))
+		if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+			return nil, xerrors.Errorf("get group by ID: %w", err)
+		}
+		// We don't delete groups from the map if they
+		// get deleted so just skip.
+		if xerrors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+
+		groups = append(groups, database.WorkspaceGroup{
+			Group:   group,
+			Actions: v,
+		})
+	}
+
+	return groups, nil
+}
+
 func (q *fakeQuerier) GetOrganizationMemberByUserID(_ context.Context, arg database.GetOrganizationMemberByUserIDParams) (database.OrganizationMember, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return database.OrganizationMember{}, err
@@ -2531,6 +3263,7 @@ func (q *fakeQuerier) InsertFile(_ context.Context, arg database.InsertFileParam
 		CreatedBy: arg.CreatedBy,
 		Mimetype:  arg.Mimetype,
 		Data:      arg.Data,
+		Storage:   arg.Storage,
 	}
 	q.files = append(q.files, file)
 	return file, nil
@@ -2647,6 +3380,10 @@ func (q *fakeQuerier) InsertTemplateVersion(_ context.Context, arg database.Inse
 		Readme:         arg.Readme,
 		JobID:          arg.JobID,
 		CreatedBy:      arg.CreatedBy,
+		GitURL:         arg.GitURL,
+		GitRef:         arg.GitRef,
+		GitDirectory:   arg.GitDirectory,
+		GitCommitSHA:   arg.GitCommitSHA,
 	}
 	q.templateVersions = append(q.templateVersions, version)
 	return version, nil
@@ -2778,6 +3515,8 @@ func (q *fakeQuerier) InsertProvisionerDaemon(_ context.Context, arg database.In
 		Name:         arg.Name,
 		Provisioners: arg.Provisioners,
 		Tags:         arg.Tags,
+		LastSeenAt:   arg.LastSeenAt,
+		Version:      arg.Version,
 	}
 	q.provisionerDaemons = append(q.provisionerDaemons, daemon)
 	return daemon, nil
@@ -2803,6 +3542,7 @@ func (q *fakeQuerier) InsertProvisionerJob(_ context.Context, arg database.Inser
 		Type:           arg.Type,
 		Input:          arg.Input,
 		Tags:           arg.Tags,
+		Priority:       arg.Priority,
 	}
 	q.provisionerJobs = append(q.provisionerJobs, job)
 	return job, nil
@@ -3058,6 +3798,12 @@ func (q *fakeQuerier) InsertWorkspace(_ context.Context, arg database.InsertWork
 		Name:              arg.Name,
 		AutostartSchedule: arg.AutostartSchedule,
 		Ttl:               arg.Ttl,
+		UserACL:           database.WorkspaceACL{},
+		GroupACL:          database.WorkspaceACL{},
+		Labels:            arg.Labels,
+	}
+	if workspace.Labels == nil {
+		workspace.Labels = database.WorkspaceLabels{}
 	}
 	q.workspaces = append(q.workspaces, workspace)
 	return workspace, nil
@@ -3242,6 +3988,27 @@ func (q *fakeQuerier) UpdateTemplateACLByID(_ context.Context, arg database.Upda
 	return database.Template{}, sql.ErrNoRows
 }
 
+func (q *fakeQuerier) UpdateWorkspaceACLByID(_ context.Context, arg database.UpdateWorkspaceACLByIDParams) (database.Workspace, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.Workspace{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, workspace := range q.workspaces {
+		if workspace.ID == arg.ID {
+			workspace.GroupACL = arg.GroupACL
+			workspace.UserACL = arg.UserACL
+
+			q.workspaces[i] = workspace
+			return workspace, nil
+		}
+	}
+
+	return database.Workspace{}, sql.ErrNoRows
+}
+
 func (q *fakeQuerier) UpdateTemplateVersionByID(_ context.Context, arg database.UpdateTemplateVersionByIDParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -3302,6 +4069,37 @@ func (q *fakeQuerier) UpdateTemplateVersionGitAuthProvidersByJobID(_ context.Con
 	return sql.ErrNoRows
 }
 
+func (q *fakeQuerier) UpdateWorkspaceAgentAuthTokensByWorkspaceID(_ context.Context, arg database.UpdateWorkspaceAgentAuthTokensByWorkspaceIDParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	jobIDs := map[uuid.UUID]struct{}{}
+	for _, build := range q.workspaceBuilds {
+		if build.WorkspaceID == arg.WorkspaceID {
+			jobIDs[build.JobID] = struct{}{}
+		}
+	}
+	resourceIDs := map[uuid.UUID]struct{}{}
+	for _, resource := range q.workspaceResources {
+		if _, ok := jobIDs[resource.JobID]; ok {
+			resourceIDs[resource.ID] = struct{}{}
+		}
+	}
+	for index, agent := range q.workspaceAgents {
+		if _, ok := resourceIDs[agent.ResourceID]; !ok {
+			continue
+		}
+		agent.AuthToken = uuid.New()
+		agent.UpdatedAt = arg.UpdatedAt
+		q.workspaceAgents[index] = agent
+	}
+	return nil
+}
+
 func (q *fakeQuerier) UpdateWorkspaceAgentConnectionByID(_ context.Context, arg database.UpdateWorkspaceAgentConnectionByIDParams) error {
 	if err := validateDatabaseType(arg); err != nil {
 		return err
@@ -3358,6 +4156,7 @@ func (q *fakeQuerier) UpdateProvisionerJobByID(_ context.Context, arg database.U
 			continue
 		}
 		job.UpdatedAt = arg.UpdatedAt
+		job.LastHeartbeatAt = sql.NullTime{Time: arg.UpdatedAt, Valid: true}
 		q.provisionerJobs[index] = job
 		return nil
 	}
@@ -3475,62 +4274,119 @@ func (q *fakeQuerier) UpdateWorkspaceTTL(_ context.Context, arg database.UpdateW
 	return sql.ErrNoRows
 }
 
-func (q *fakeQuerier) UpdateWorkspaceLastUsedAt(_ context.Context, arg database.UpdateWorkspaceLastUsedAtParams) error {
+func (q *fakeQuerier) UpdateWorkspaceLabelsByID(_ context.Context, arg database.UpdateWorkspaceLabelsByIDParams) (database.Workspace, error) {
 	if err := validateDatabaseType(arg); err != nil {
-		return err
+		return database.Workspace{}, err
 	}
 
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
-	for index, workspace := range q.workspaces {
-		if workspace.ID != arg.ID {
+	for i, workspace := range q.workspaces {
+		if workspace.Deleted || workspace.ID != arg.ID {
 			continue
 		}
-		workspace.LastUsedAt = arg.LastUsedAt
-		q.workspaces[index] = workspace
-		return nil
+		workspace.Labels = arg.Labels
+		if workspace.Labels == nil {
+			workspace.Labels = database.WorkspaceLabels{}
+		}
+		q.workspaces[i] = workspace
+		return workspace, nil
 	}
 
-	return sql.ErrNoRows
+	return database.Workspace{}, sql.ErrNoRows
 }
 
-func (q *fakeQuerier) UpdateWorkspaceBuildByID(_ context.Context, arg database.UpdateWorkspaceBuildByIDParams) (database.WorkspaceBuild, error) {
+func (q *fakeQuerier) UpdateWorkspaceLastUsedAt(_ context.Context, arg database.UpdateWorkspaceLastUsedAtParams) error {
 	if err := validateDatabaseType(arg); err != nil {
-		return database.WorkspaceBuild{}, err
+		return err
 	}
 
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
-	for index, workspaceBuild := range q.workspaceBuilds {
-		if workspaceBuild.ID != arg.ID {
+	for index, workspace := range q.workspaces {
+		if workspace.ID != arg.ID {
 			continue
 		}
-		workspaceBuild.UpdatedAt = arg.UpdatedAt
-		workspaceBuild.ProvisionerState = arg.ProvisionerState
-		workspaceBuild.Deadline = arg.Deadline
-		q.workspaceBuilds[index] = workspaceBuild
-		return workspaceBuild, nil
+		workspace.LastUsedAt = arg.LastUsedAt
+		q.workspaces[index] = workspace
+		return nil
 	}
-	return database.WorkspaceBuild{}, sql.ErrNoRows
+
+	return sql.ErrNoRows
 }
 
-func (q *fakeQuerier) UpdateWorkspaceBuildCostByID(_ context.Context, arg database.UpdateWorkspaceBuildCostByIDParams) (database.WorkspaceBuild, error) {
+func (q *fakeQuerier) UpdateWorkspaceOwnerByID(_ context.Context, arg database.UpdateWorkspaceOwnerByIDParams) (database.Workspace, error) {
 	if err := validateDatabaseType(arg); err != nil {
-		return database.WorkspaceBuild{}, err
+		return database.Workspace{}, err
 	}
 
 	q.mutex.Lock()
 	defer q.mutex.Unlock()
 
-	for index, workspaceBuild := range q.workspaceBuilds {
-		if workspaceBuild.ID != arg.ID {
+	for i, workspace := range q.workspaces {
+		if workspace.Deleted || workspace.ID != arg.ID {
 			continue
 		}
-		workspaceBuild.DailyCost = arg.DailyCost
-		q.workspaceBuilds[index] = workspaceBuild
-		return workspaceBuild, nil
+		for _, other := range q.workspaces {
+			if other.Deleted || other.ID == workspace.ID || other.OwnerID != arg.OwnerID {
+				continue
+			}
+			if strings.EqualFold(other.Name, arg.Name) {
+				return database.Workspace{}, errDuplicateKey
+			}
+		}
+
+		workspace.OwnerID = arg.OwnerID
+		workspace.Name = arg.Name
+		workspace.UpdatedAt = arg.UpdatedAt
+		workspace.UserACL = database.WorkspaceACL{}
+		workspace.GroupACL = database.WorkspaceACL{}
+		q.workspaces[i] = workspace
+
+		return workspace, nil
+	}
+
+	return database.Workspace{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateWorkspaceBuildByID(_ context.Context, arg database.UpdateWorkspaceBuildByIDParams) (database.WorkspaceBuild, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.WorkspaceBuild{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, workspaceBuild := range q.workspaceBuilds {
+		if workspaceBuild.ID != arg.ID {
+			continue
+		}
+		workspaceBuild.UpdatedAt = arg.UpdatedAt
+		workspaceBuild.ProvisionerState = arg.ProvisionerState
+		workspaceBuild.Deadline = arg.Deadline
+		q.workspaceBuilds[index] = workspaceBuild
+		return workspaceBuild, nil
+	}
+	return database.WorkspaceBuild{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateWorkspaceBuildCostByID(_ context.Context, arg database.UpdateWorkspaceBuildCostByIDParams) (database.WorkspaceBuild, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.WorkspaceBuild{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, workspaceBuild := range q.workspaceBuilds {
+		if workspaceBuild.ID != arg.ID {
+			continue
+		}
+		workspaceBuild.DailyCost = arg.DailyCost
+		q.workspaceBuilds[index] = workspaceBuild
+		return workspaceBuild, nil
 	}
 	return database.WorkspaceBuild{}, sql.ErrNoRows
 }
@@ -3722,6 +4578,64 @@ func (q *fakeQuerier) DeleteGitSSHKey(_ context.Context, userID uuid.UUID) error
 	return sql.ErrNoRows
 }
 
+func (q *fakeQuerier) GetAuditLogsForExport(_ context.Context, arg database.GetAuditLogsForExportParams) ([]database.GetAuditLogsForExportRow, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	matching, err := q.getAuditLogsOffsetNoLock(database.GetAuditLogsOffsetParams{
+		Limit:          int32(len(q.auditLogs)),
+		ResourceType:   arg.ResourceType,
+		ResourceID:     arg.ResourceID,
+		ResourceTarget: arg.ResourceTarget,
+		Action:         arg.Action,
+		Username:       arg.Username,
+		Email:          arg.Email,
+		DateFrom:       arg.DateFrom,
+		DateTo:         arg.DateTo,
+		BuildReason:    arg.BuildReason,
+		Ip:             arg.Ip,
+		RequestID:      arg.RequestID,
+		Search:         arg.Search,
+	})
+	if err != nil {
+		return nil, err
+	}
+	logs := make([]database.GetAuditLogsForExportRow, 0, arg.RowLimit)
+	for _, alog := range matching {
+		if len(logs) >= int(arg.RowLimit) {
+			break
+		}
+		if arg.BeforeID != uuid.Nil && !alog.Time.Before(arg.BeforeTime) &&
+			(!alog.Time.Equal(arg.BeforeTime) || bytes.Compare(alog.ID[:], arg.BeforeID[:]) >= 0) {
+			continue
+		}
+		logs = append(logs, database.GetAuditLogsForExportRow{
+			ID:               alog.ID,
+			Time:             alog.Time,
+			UserID:           alog.UserID,
+			OrganizationID:   alog.OrganizationID,
+			Ip:               alog.Ip,
+			UserAgent:        alog.UserAgent,
+			ResourceType:     alog.ResourceType,
+			ResourceID:       alog.ResourceID,
+			ResourceTarget:   alog.ResourceTarget,
+			Action:           alog.Action,
+			Diff:             alog.Diff,
+			StatusCode:       alog.StatusCode,
+			AdditionalFields: alog.AdditionalFields,
+			RequestID:        alog.RequestID,
+			ResourceIcon:     alog.ResourceIcon,
+			UserUsername:     alog.UserUsername,
+			UserEmail:        alog.UserEmail,
+		})
+	}
+	return logs, nil
+}
+
 func (q *fakeQuerier) GetAuditLogsOffset(_ context.Context, arg database.GetAuditLogsOffsetParams) ([]database.GetAuditLogsOffsetRow, error) {
 	if err := validateDatabaseType(arg); err != nil {
 		return nil, err
@@ -3730,14 +4644,16 @@ func (q *fakeQuerier) GetAuditLogsOffset(_ context.Context, arg database.GetAudi
 	q.mutex.RLock()
 	defer q.mutex.RUnlock()
 
+	return q.getAuditLogsOffsetNoLock(arg)
+}
+
+func (q *fakeQuerier) getAuditLogsOffsetNoLock(arg database.GetAuditLogsOffsetParams) ([]database.GetAuditLogsOffsetRow, error) {
 	logs := make([]database.GetAuditLogsOffsetRow, 0, arg.Limit)
 
-	// q.auditLogs are already sorted by time DESC, so no need to sort after the fact.
-	for _, alog := range q.auditLogs {
-		if arg.Offset > 0 {
-			arg.Offset--
-			continue
-		}
+	// q.auditLogs are already sorted by time and ID ASC, so iterate backwards
+	// to return them by time and ID DESC.
+	for i := len(q.auditLogs) - 1; i >= 0; i-- {
+		alog := q.auditLogs[i]
 		if arg.Action != "" && !strings.Contains(string(alog.Action), arg.Action) {
 			continue
 		}
@@ -3747,6 +4663,9 @@ func (q *fakeQuerier) GetAuditLogsOffset(_ context.Context, arg database.GetAudi
 		if arg.ResourceID != uuid.Nil && alog.ResourceID != arg.ResourceID {
 			continue
 		}
+		if arg.ResourceTarget != "" && !strings.EqualFold(alog.ResourceTarget, arg.ResourceTarget) {
+			continue
+		}
 		if arg.Username != "" {
 			user, err := q.getUserByIDNoLock(alog.UserID)
 			if err == nil && !strings.EqualFold(arg.Username, user.Username) {
@@ -3775,6 +4694,19 @@ func (q *fakeQuerier) GetAuditLogsOffset(_ context.Context, arg database.GetAudi
 				continue
 			}
 		}
+		if arg.Ip.Valid && (!alog.Ip.Valid || !arg.Ip.IPNet.Contains(alog.Ip.IPNet.IP)) {
+			continue
+		}
+		if arg.RequestID != uuid.Nil && alog.RequestID != arg.RequestID {
+			continue
+		}
+		if arg.Search != "" && !strings.Contains(strings.ToLower(string(alog.Diff)), strings.ToLower(arg.Search)) {
+			continue
+		}
+		if arg.Offset > 0 {
+			arg.Offset--
+			continue
+		}
 
 		user, err := q.getUserByIDNoLock(alog.UserID)
 		userValid := err == nil
@@ -3782,6 +4714,7 @@ func (q *fakeQuerier) GetAuditLogsOffset(_ context.Context, arg database.GetAudi
 		logs = append(logs, database.GetAuditLogsOffsetRow{
 			ID:               alog.ID,
 			RequestID:        alog.RequestID,
+			Time:             alog.Time,
 			OrganizationID:   alog.OrganizationID,
 			Ip:               alog.Ip,
 			UserAgent:        alog.UserAgent,
@@ -3827,6 +4760,9 @@ func (q *fakeQuerier) InsertAuditLog(_ context.Context, arg database.InsertAudit
 
 	q.auditLogs = append(q.auditLogs, alog)
 	slices.SortFunc(q.auditLogs, func(a, b database.AuditLog) bool {
+		if a.Time.Equal(b.Time) {
+			return bytes.Compare(a.ID[:], b.ID[:]) < 0
+		}
 		return a.Time.Before(b.Time)
 	})
 
@@ -4400,3 +5336,1083 @@ func (q *fakeQuerier) UpdateWorkspaceAgentLifecycleStateByID(_ context.Context,
 	}
 	return sql.ErrNoRows
 }
+
+func (q *fakeQuerier) InsertWebhook(_ context.Context, arg database.InsertWebhookParams) (database.Webhook, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.Webhook{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	//nolint:gosimple
+	webhook := database.Webhook{
+		ID:             arg.ID,
+		OrganizationID: arg.OrganizationID,
+		CreatedBy:      arg.CreatedBy,
+		CreatedAt:      arg.CreatedAt,
+		UpdatedAt:      arg.UpdatedAt,
+		Name:           arg.Name,
+		Url:            arg.Url,
+		Secret:         arg.Secret,
+		Events:         arg.Events,
+		Enabled:        arg.Enabled,
+	}
+	q.webhooks = append(q.webhooks, webhook)
+	return webhook, nil
+}
+
+func (q *fakeQuerier) GetWebhookByID(_ context.Context, id uuid.UUID) (database.Webhook, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, webhook := range q.webhooks {
+		if webhook.ID == id {
+			return webhook, nil
+		}
+	}
+	return database.Webhook{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetDeploymentWebhooks(_ context.Context) ([]database.Webhook, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	webhooks := make([]database.Webhook, 0)
+	for _, webhook := range q.webhooks {
+		if !webhook.OrganizationID.Valid {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].Name < webhooks[j].Name })
+	return webhooks, nil
+}
+
+func (q *fakeQuerier) GetWebhooksByOrganizationID(_ context.Context, organizationID uuid.UUID) ([]database.Webhook, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	webhooks := make([]database.Webhook, 0)
+	for _, webhook := range q.webhooks {
+		if webhook.OrganizationID.Valid && webhook.OrganizationID.UUID == organizationID {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].Name < webhooks[j].Name })
+	return webhooks, nil
+}
+
+func (q *fakeQuerier) GetEnabledWebhooksByEvent(_ context.Context, arg database.GetEnabledWebhooksByEventParams) ([]database.Webhook, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	webhooks := make([]database.Webhook, 0)
+	for _, webhook := range q.webhooks {
+		if !webhook.Enabled {
+			continue
+		}
+		if !slices.Contains(webhook.Events, arg.Event) {
+			continue
+		}
+		if webhook.OrganizationID.Valid && !slices.Contains(arg.OrganizationIDs, webhook.OrganizationID.UUID) {
+			continue
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, nil
+}
+
+func (q *fakeQuerier) UpdateWebhookByID(_ context.Context, arg database.UpdateWebhookByIDParams) (database.Webhook, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.Webhook{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, webhook := range q.webhooks {
+		if webhook.ID != arg.ID {
+			continue
+		}
+		webhook.UpdatedAt = arg.UpdatedAt
+		webhook.Name = arg.Name
+		webhook.Url = arg.Url
+		webhook.Events = arg.Events
+		webhook.Enabled = arg.Enabled
+		q.webhooks[i] = webhook
+		return webhook, nil
+	}
+	return database.Webhook{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) DeleteWebhookByID(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, webhook := range q.webhooks {
+		if webhook.ID != id {
+			continue
+		}
+		q.webhooks = append(q.webhooks[:i], q.webhooks[i+1:]...)
+		deliveries := make([]database.WebhookDelivery, 0, len(q.webhookDeliveries))
+		for _, delivery := range q.webhookDeliveries {
+			if delivery.WebhookID != id {
+				deliveries = append(deliveries, delivery)
+			}
+		}
+		q.webhookDeliveries = deliveries
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) InsertWebhookDelivery(_ context.Context, arg database.InsertWebhookDeliveryParams) (database.WebhookDelivery, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.WebhookDelivery{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, delivery := range q.webhookDeliveries {
+		// Mirrors ON CONFLICT DO NOTHING, which returns no rows.
+		if delivery.WebhookID == arg.WebhookID && delivery.EventID == arg.EventID && delivery.Attempt == arg.Attempt {
+			return database.WebhookDelivery{}, sql.ErrNoRows
+		}
+	}
+
+	delivery := database.WebhookDelivery{
+		ID:        arg.ID,
+		WebhookID: arg.WebhookID,
+		EventID:   arg.EventID,
+		Event:     arg.Event,
+		Payload:   arg.Payload,
+		Attempt:   arg.Attempt,
+		CreatedAt: arg.CreatedAt,
+	}
+	q.webhookDeliveries = append(q.webhookDeliveries, delivery)
+	return delivery, nil
+}
+
+func (q *fakeQuerier) UpdateWebhookDeliveryByID(_ context.Context, arg database.UpdateWebhookDeliveryByIDParams) (database.WebhookDelivery, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.WebhookDelivery{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, delivery := range q.webhookDeliveries {
+		if delivery.ID != arg.ID {
+			continue
+		}
+		delivery.DeliveredAt = arg.DeliveredAt
+		delivery.StatusCode = arg.StatusCode
+		delivery.ResponseBody = arg.ResponseBody
+		delivery.Error = arg.Error
+		q.webhookDeliveries[i] = delivery
+		return delivery, nil
+	}
+	return database.WebhookDelivery{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetWebhookDeliveriesByWebhookID(_ context.Context, arg database.GetWebhookDeliveriesByWebhookIDParams) ([]database.WebhookDelivery, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	deliveries := make([]database.WebhookDelivery, 0)
+	for _, delivery := range q.webhookDeliveries {
+		if delivery.WebhookID == arg.WebhookID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+	if arg.LimitOpt > 0 && len(deliveries) > int(arg.LimitOpt) {
+		deliveries = deliveries[:arg.LimitOpt]
+	}
+	return deliveries, nil
+}
+
+func (q *fakeQuerier) GetNotificationPreferencesByUserID(_ context.Context, userID uuid.UUID) ([]database.NotificationPreference, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	preferences := make([]database.NotificationPreference, 0)
+	for _, preference := range q.notificationPreferences {
+		if preference.UserID == userID {
+			preferences = append(preferences, preference)
+		}
+	}
+	slices.SortFunc(preferences, func(a, b database.NotificationPreference) bool {
+		return a.Kind < b.Kind
+	})
+	return preferences, nil
+}
+
+func (q *fakeQuerier) UpsertNotificationPreference(_ context.Context, arg database.UpsertNotificationPreferenceParams) (database.NotificationPreference, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.NotificationPreference{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	preference := database.NotificationPreference{
+		UserID:    arg.UserID,
+		Kind:      arg.Kind,
+		Disabled:  arg.Disabled,
+		UpdatedAt: arg.UpdatedAt,
+	}
+	for i, existing := range q.notificationPreferences {
+		if existing.UserID == arg.UserID && existing.Kind == arg.Kind {
+			q.notificationPreferences[i] = preference
+			return preference, nil
+		}
+	}
+	q.notificationPreferences = append(q.notificationPreferences, preference)
+	return preference, nil
+}
+
+func (q *fakeQuerier) InsertNotificationMessage(_ context.Context, arg database.InsertNotificationMessageParams) (database.NotificationMessage, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.NotificationMessage{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, message := range q.notificationMessages {
+		// Mirrors ON CONFLICT DO NOTHING, which returns no rows.
+		if message.DedupeKey == arg.DedupeKey {
+			return database.NotificationMessage{}, sql.ErrNoRows
+		}
+	}
+
+	message := database.NotificationMessage{
+		ID:        arg.ID,
+		UserID:    arg.UserID,
+		Kind:      arg.Kind,
+		DedupeKey: arg.DedupeKey,
+		Subject:   arg.Subject,
+		CreatedAt: arg.CreatedAt,
+	}
+	q.notificationMessages = append(q.notificationMessages, message)
+	return message, nil
+}
+
+func (q *fakeQuerier) DeleteNotificationMessageByID(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, message := range q.notificationMessages {
+		if message.ID == id {
+			q.notificationMessages = append(q.notificationMessages[:i], q.notificationMessages[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) ArchiveUnusedTemplateVersions(_ context.Context, arg database.ArchiveUnusedTemplateVersionsParams) ([]uuid.UUID, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	// Find the active versions of templates, and the versions used by the
+	// latest build of each workspace that hasn't been deleted.
+	used := make(map[uuid.UUID]bool)
+	for _, template := range q.templates {
+		if !template.Deleted {
+			used[template.ActiveVersionID] = true
+		}
+	}
+	deleted := make(map[uuid.UUID]bool)
+	for _, workspace := range q.workspaces {
+		deleted[workspace.ID] = workspace.Deleted
+	}
+	latestBuilds := make(map[uuid.UUID]database.WorkspaceBuild)
+	for _, build := range q.workspaceBuilds {
+		if latest, ok := latestBuilds[build.WorkspaceID]; !ok || build.BuildNumber > latest.BuildNumber {
+			latestBuilds[build.WorkspaceID] = build
+		}
+	}
+	for workspaceID, build := range latestBuilds {
+		if !deleted[workspaceID] {
+			used[build.TemplateVersionID] = true
+		}
+	}
+
+	completed := make(map[uuid.UUID]bool)
+	for _, job := range q.provisionerJobs {
+		completed[job.ID] = job.CompletedAt.Valid
+	}
+
+	var archived []uuid.UUID
+	for i, version := range q.templateVersions {
+		if version.TemplateID.UUID != arg.TemplateID || version.Archived {
+			continue
+		}
+		if arg.TemplateVersionID != uuid.Nil && version.ID != arg.TemplateVersionID {
+			continue
+		}
+		if used[version.ID] || !completed[version.JobID] {
+			continue
+		}
+		version.Archived = true
+		version.UpdatedAt = arg.UpdatedAt
+		q.templateVersions[i] = version
+		archived = append(archived, version.ID)
+	}
+	return archived, nil
+}
+
+func (q *fakeQuerier) UnarchiveTemplateVersion(_ context.Context, arg database.UnarchiveTemplateVersionParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, version := range q.templateVersions {
+		if version.ID != arg.TemplateVersionID {
+			continue
+		}
+		version.Archived = false
+		version.UpdatedAt = arg.UpdatedAt
+		q.templateVersions[i] = version
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) DeleteUnusedFiles(_ context.Context, createdBefore time.Time) ([]database.DeleteUnusedFilesRow, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	needed := make(map[uuid.UUID]bool)
+	jobs := make(map[uuid.UUID]database.ProvisionerJob)
+	for _, job := range q.provisionerJobs {
+		jobs[job.ID] = job
+		if !job.CompletedAt.Valid {
+			needed[job.FileID] = true
+		}
+	}
+	for _, version := range q.templateVersions {
+		if version.Archived {
+			continue
+		}
+		if job, ok := jobs[version.JobID]; ok {
+			needed[job.FileID] = true
+		}
+	}
+
+	var deleted []database.DeleteUnusedFilesRow
+	files := make([]database.File, 0, len(q.files))
+	for _, file := range q.files {
+		if file.CreatedAt.Before(createdBefore) && !needed[file.ID] {
+			deleted = append(deleted, database.DeleteUnusedFilesRow{
+				ID:      file.ID,
+				Hash:    file.Hash,
+				Storage: file.Storage,
+			})
+			continue
+		}
+		files = append(files, file)
+	}
+	q.files = files
+	return deleted, nil
+}
+
+func (q *fakeQuerier) DeleteArchivedTemplateVersionJobLogs(_ context.Context) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	archived := make(map[uuid.UUID]bool)
+	archivedJobs := make(map[uuid.UUID]bool)
+	for _, version := range q.templateVersions {
+		if version.Archived {
+			archived[version.ID] = true
+			archivedJobs[version.JobID] = true
+		}
+	}
+	for _, job := range q.provisionerJobs {
+		if job.Type != database.ProvisionerJobTypeTemplateVersionDryRun {
+			continue
+		}
+		var input struct {
+			TemplateVersionID uuid.UUID `json:"template_version_id"`
+		}
+		if err := json.Unmarshal(job.Input, &input); err != nil {
+			continue
+		}
+		if archived[input.TemplateVersionID] {
+			archivedJobs[job.ID] = true
+		}
+	}
+
+	logs := make([]database.ProvisionerJobLog, 0, len(q.provisionerJobLogs))
+	for _, log := range q.provisionerJobLogs {
+		if archivedJobs[log.JobID] {
+			continue
+		}
+		logs = append(logs, log)
+	}
+	q.provisionerJobLogs = logs
+	return nil
+}
+
+func (q *fakeQuerier) GetTemplateGitSyncByTemplateID(_ context.Context, templateID uuid.UUID) (database.TemplateGitSync, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, gitSync := range q.templateGitSyncs {
+		if gitSync.TemplateID == templateID {
+			return gitSync, nil
+		}
+	}
+	return database.TemplateGitSync{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetTemplateGitSyncs(_ context.Context) ([]database.TemplateGitSync, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	deleted := make(map[uuid.UUID]bool)
+	for _, template := range q.templates {
+		deleted[template.ID] = template.Deleted
+	}
+	gitSyncs := make([]database.TemplateGitSync, 0)
+	for _, gitSync := range q.templateGitSyncs {
+		if deleted[gitSync.TemplateID] {
+			continue
+		}
+		gitSyncs = append(gitSyncs, gitSync)
+	}
+	sort.SliceStable(gitSyncs, func(i, j int) bool {
+		return gitSyncs[i].CreatedAt.Before(gitSyncs[j].CreatedAt)
+	})
+	return gitSyncs, nil
+}
+
+func (q *fakeQuerier) GetTemplateGitSyncsByTemplateIDs(_ context.Context, ids []uuid.UUID) ([]database.TemplateGitSync, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	gitSyncs := make([]database.TemplateGitSync, 0)
+	for _, gitSync := range q.templateGitSyncs {
+		if slices.Contains(ids, gitSync.TemplateID) {
+			gitSyncs = append(gitSyncs, gitSync)
+		}
+	}
+	return gitSyncs, nil
+}
+
+func (q *fakeQuerier) UpsertTemplateGitSync(_ context.Context, arg database.UpsertTemplateGitSyncParams) (database.TemplateGitSync, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TemplateGitSync{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, gitSync := range q.templateGitSyncs {
+		if gitSync.TemplateID != arg.TemplateID {
+			continue
+		}
+		gitSync.UpdatedAt = arg.UpdatedAt
+		gitSync.UserID = arg.UserID
+		gitSync.GitURL = arg.GitURL
+		gitSync.GitBranch = arg.GitBranch
+		gitSync.GitDirectory = arg.GitDirectory
+		gitSync.CommitSHA = ""
+		gitSync.TemplateVersionID = uuid.NullUUID{}
+		gitSync.DryRunJobID = uuid.NullUUID{}
+		gitSync.Status = "pending"
+		gitSync.Error = ""
+		q.templateGitSyncs[i] = gitSync
+		return gitSync, nil
+	}
+
+	gitSync := database.TemplateGitSync{
+		TemplateID:    arg.TemplateID,
+		CreatedAt:     arg.CreatedAt,
+		UpdatedAt:     arg.UpdatedAt,
+		UserID:        arg.UserID,
+		GitURL:        arg.GitURL,
+		GitBranch:     arg.GitBranch,
+		GitDirectory:  arg.GitDirectory,
+		WebhookSecret: arg.WebhookSecret,
+		Status:        "pending",
+	}
+	q.templateGitSyncs = append(q.templateGitSyncs, gitSync)
+	return gitSync, nil
+}
+
+func (q *fakeQuerier) UpdateTemplateGitSyncCommitByTemplateID(_ context.Context, arg database.UpdateTemplateGitSyncCommitByTemplateIDParams) (database.TemplateGitSync, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TemplateGitSync{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, gitSync := range q.templateGitSyncs {
+		if gitSync.TemplateID != arg.TemplateID || gitSync.CommitSHA != arg.PreviousCommitSHA {
+			continue
+		}
+		stale := !gitSync.TemplateVersionID.Valid && gitSync.CheckedAt.Valid && gitSync.CheckedAt.Time.Before(arg.StaleBefore)
+		if gitSync.CommitSHA == arg.CommitSHA && !stale {
+			continue
+		}
+		gitSync.CommitSHA = arg.CommitSHA
+		gitSync.CheckedAt = arg.CheckedAt
+		gitSync.TemplateVersionID = uuid.NullUUID{}
+		gitSync.DryRunJobID = uuid.NullUUID{}
+		gitSync.Status = "pending"
+		gitSync.Error = ""
+		q.templateGitSyncs[i] = gitSync
+		return gitSync, nil
+	}
+	return database.TemplateGitSync{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateTemplateGitSyncDryRunJobByTemplateID(_ context.Context, arg database.UpdateTemplateGitSyncDryRunJobByTemplateIDParams) (database.TemplateGitSync, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TemplateGitSync{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, gitSync := range q.templateGitSyncs {
+		if gitSync.TemplateID != arg.TemplateID || !gitSync.TemplateVersionID.Valid || gitSync.TemplateVersionID != arg.TemplateVersionID || gitSync.DryRunJobID.Valid {
+			continue
+		}
+		gitSync.DryRunJobID = arg.DryRunJobID
+		gitSync.CheckedAt = arg.CheckedAt
+		q.templateGitSyncs[i] = gitSync
+		return gitSync, nil
+	}
+	return database.TemplateGitSync{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) UpdateTemplateGitSyncStatusByTemplateID(_ context.Context, arg database.UpdateTemplateGitSyncStatusByTemplateIDParams) (database.TemplateGitSync, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.TemplateGitSync{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, gitSync := range q.templateGitSyncs {
+		if gitSync.TemplateID != arg.TemplateID {
+			continue
+		}
+		gitSync.CommitSHA = arg.CommitSHA
+		gitSync.TemplateVersionID = arg.TemplateVersionID
+		gitSync.Status = arg.Status
+		gitSync.Error = arg.Error
+		gitSync.CheckedAt = arg.CheckedAt
+		q.templateGitSyncs[i] = gitSync
+		return gitSync, nil
+	}
+	return database.TemplateGitSync{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) DeleteTemplateGitSyncByTemplateID(_ context.Context, templateID uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, gitSync := range q.templateGitSyncs {
+		if gitSync.TemplateID != templateID {
+			continue
+		}
+		q.templateGitSyncs = append(q.templateGitSyncs[:i], q.templateGitSyncs[i+1:]...)
+		return nil
+	}
+	return nil
+}
+
+func (q *fakeQuerier) GetFileHashInUse(_ context.Context, hash string) (bool, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, file := range q.files {
+		if file.Hash == hash {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (q *fakeQuerier) GetFileIDsByStorage(_ context.Context, storage string) ([]uuid.UUID, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	files := make([]database.File, 0)
+	for _, file := range q.files {
+		if file.Storage == storage {
+			files = append(files, file)
+		}
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].CreatedAt.Before(files[j].CreatedAt)
+	})
+	ids := make([]uuid.UUID, 0, len(files))
+	for _, file := range files {
+		ids = append(ids, file.ID)
+	}
+	return ids, nil
+}
+
+func (q *fakeQuerier) UpdateFileStorageByID(_ context.Context, arg database.UpdateFileStorageByIDParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for i, file := range q.files {
+		if file.ID != arg.ID {
+			continue
+		}
+		file.Storage = arg.Storage
+		file.Data = arg.Data
+		q.files[i] = file
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetProvisionerJobQueuePositionsByIDs(_ context.Context, ids []uuid.UUID) ([]database.GetProvisionerJobQueuePositionsByIDsRow, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	// sameQueue reports whether the jobs are acquired by the same provisioner
+	// daemons.
+	sameQueue := func(a, b database.ProvisionerJob) bool {
+		if a.Provisioner != b.Provisioner || len(a.Tags) != len(b.Tags) {
+			return false
+		}
+		for key, value := range a.Tags {
+			if other, ok := b.Tags[key]; !ok || other != value {
+				return false
+			}
+		}
+		return true
+	}
+	pending := func(job database.ProvisionerJob) bool {
+		return !job.StartedAt.Valid && !job.CompletedAt.Valid
+	}
+
+	rows := make([]database.GetProvisionerJobQueuePositionsByIDsRow, 0)
+	for _, job := range q.provisionerJobs {
+		if !pending(job) || !slices.Contains(ids, job.ID) {
+			continue
+		}
+		row := database.GetProvisionerJobQueuePositionsByIDsRow{
+			ID:            job.ID,
+			Provisioner:   job.Provisioner,
+			Tags:          job.Tags,
+			QueuePosition: 1,
+		}
+		var durations, completed int64
+		for _, other := range q.provisionerJobs {
+			if !sameQueue(job, other) {
+				continue
+			}
+			switch {
+			case pending(other):
+				row.QueueSize++
+				if other.ID == job.ID {
+					continue
+				}
+				if other.Priority != job.Priority {
+					if provisionerJobPriorityRank(other.Priority) < provisionerJobPriorityRank(job.Priority) {
+						row.QueuePosition++
+					}
+				} else if other.CreatedAt.Before(job.CreatedAt) {
+					row.QueuePosition++
+				}
+			case !other.CompletedAt.Valid:
+				row.RunningJobs++
+			case other.StartedAt.Valid && other.CompletedAt.Time.After(database.Now().Add(-24*time.Hour)):
+				durations += other.CompletedAt.Time.Sub(other.StartedAt.Time).Milliseconds()
+				completed++
+			}
+		}
+		if completed > 0 {
+			row.AverageDurationMs = durations / completed
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func (q *fakeQuerier) GetRunningProvisionerJobs(_ context.Context) ([]database.ProvisionerJob, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	jobs := make([]database.ProvisionerJob, 0)
+	for _, job := range q.provisionerJobs {
+		if job.StartedAt.Valid && !job.CompletedAt.Valid {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (q *fakeQuerier) AcquireFileHashLock(_ context.Context, _ string) error {
+	if _, ok := q.mutex.(inTxMutex); !ok {
+		return xerrors.New("AcquireFileHashLock must be called in a transaction")
+	}
+	// Transactions are serialized, so the lock is always free.
+	return nil
+}
+
+func (q *fakeQuerier) TryAcquireLock(_ context.Context, _ int64) (bool, error) {
+	if _, ok := q.mutex.(inTxMutex); !ok {
+		return false, xerrors.New("TryAcquireLock must be called in a transaction")
+	}
+	// Transactions are serialized, so the lock is always free.
+	return true, nil
+}
+
+func (q *fakeQuerier) UpdateProvisionerDaemonLastSeenAt(_ context.Context, arg database.UpdateProvisionerDaemonLastSeenAtParams) error {
+	if err := validateDatabaseType(arg); err != nil {
+		return err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, daemon := range q.provisionerDaemons {
+		if daemon.ID != arg.ID {
+			continue
+		}
+		daemon.LastSeenAt = arg.LastSeenAt
+		q.provisionerDaemons[index] = daemon
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) DeleteOldProvisionerDaemons(_ context.Context, before time.Time) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	daemons := make([]database.ProvisionerDaemon, 0, len(q.provisionerDaemons))
+	for _, daemon := range q.provisionerDaemons {
+		lastSeen := daemon.CreatedAt
+		if daemon.LastSeenAt.Valid {
+			lastSeen = daemon.LastSeenAt.Time
+		}
+		if lastSeen.Before(before) {
+			continue
+		}
+		daemons = append(daemons, daemon)
+	}
+	q.provisionerDaemons = daemons
+	return nil
+}
+
+func (q *fakeQuerier) InsertProvisionerKey(_ context.Context, arg database.InsertProvisionerKeyParams) (database.ProvisionerKey, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.ProvisionerKey{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, key := range q.provisionerKeys {
+		if (key.OrganizationID == arg.OrganizationID && key.Name == arg.Name) ||
+			bytes.Equal(key.HashedSecret, arg.HashedSecret) {
+			return database.ProvisionerKey{}, errDuplicateKey
+		}
+	}
+
+	//nolint:gosimple
+	key := database.ProvisionerKey{
+		ID:             arg.ID,
+		CreatedAt:      arg.CreatedAt,
+		OrganizationID: arg.OrganizationID,
+		Name:           arg.Name,
+		HashedSecret:   arg.HashedSecret,
+		Tags:           arg.Tags,
+	}
+	q.provisionerKeys = append(q.provisionerKeys, key)
+	return key, nil
+}
+
+func (q *fakeQuerier) GetProvisionerKeyByID(_ context.Context, id uuid.UUID) (database.ProvisionerKey, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, key := range q.provisionerKeys {
+		if key.ID == id {
+			return key, nil
+		}
+	}
+	return database.ProvisionerKey{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetProvisionerKeyByHashedSecret(_ context.Context, hashedSecret []byte) (database.ProvisionerKey, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, key := range q.provisionerKeys {
+		if bytes.Equal(key.HashedSecret, hashedSecret) {
+			return key, nil
+		}
+	}
+	return database.ProvisionerKey{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetProvisionerKeyByOrganizationIDAndName(_ context.Context, arg database.GetProvisionerKeyByOrganizationIDAndNameParams) (database.ProvisionerKey, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.ProvisionerKey{}, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, key := range q.provisionerKeys {
+		if key.OrganizationID == arg.OrganizationID && key.Name == arg.Name {
+			return key, nil
+		}
+	}
+	return database.ProvisionerKey{}, sql.ErrNoRows
+}
+
+func (q *fakeQuerier) GetProvisionerKeysByOrganizationID(_ context.Context, organizationID uuid.UUID) ([]database.ProvisionerKey, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	keys := make([]database.ProvisionerKey, 0)
+	for _, key := range q.provisionerKeys {
+		if key.OrganizationID == organizationID {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, func(a, b database.ProvisionerKey) bool {
+		return a.Name < b.Name
+	})
+	return keys, nil
+}
+
+func (q *fakeQuerier) DeleteProvisionerKeyByID(_ context.Context, id uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for index, key := range q.provisionerKeys {
+		if key.ID != id {
+			continue
+		}
+		q.provisionerKeys[index] = q.provisionerKeys[len(q.provisionerKeys)-1]
+		q.provisionerKeys = q.provisionerKeys[:len(q.provisionerKeys)-1]
+		return nil
+	}
+	return sql.ErrNoRows
+}
+
+func (q *fakeQuerier) DeleteOldWorkspaceBuildJobLogs(_ context.Context, arg database.DeleteOldWorkspaceBuildJobLogsParams) (int64, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return 0, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	latestBuilds := make(map[uuid.UUID]int32)
+	for _, build := range q.workspaceBuilds {
+		if build.BuildNumber > latestBuilds[build.WorkspaceID] {
+			latestBuilds[build.WorkspaceID] = build.BuildNumber
+		}
+	}
+	oldJobs := make(map[uuid.UUID]bool)
+	for _, build := range q.workspaceBuilds {
+		if build.BuildNumber >= latestBuilds[build.WorkspaceID] {
+			continue
+		}
+		for _, job := range q.provisionerJobs {
+			if job.ID == build.JobID && job.CompletedAt.Valid && job.CompletedAt.Time.Before(arg.CompletedBefore) {
+				oldJobs[job.ID] = true
+				break
+			}
+		}
+	}
+
+	var deleted int64
+	logs := make([]database.ProvisionerJobLog, 0, len(q.provisionerJobLogs))
+	for _, log := range q.provisionerJobLogs {
+		if oldJobs[log.JobID] && deleted < int64(arg.RowLimit) {
+			deleted++
+			continue
+		}
+		logs = append(logs, log)
+	}
+	q.provisionerJobLogs = logs
+	return deleted, nil
+}
+
+// auditLogLess orders audit logs by time, and then ID like Postgres compares
+// UUIDs.
+func auditLogLess(a, b database.AuditLog) bool {
+	if !a.Time.Equal(b.Time) {
+		return a.Time.Before(b.Time)
+	}
+	return bytes.Compare(a.ID[:], b.ID[:]) < 0
+}
+
+func (q *fakeQuerier) GetAuditLogsByTimeRange(_ context.Context, arg database.GetAuditLogsByTimeRangeParams) ([]database.AuditLog, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	after := database.AuditLog{ID: arg.AfterID, Time: arg.AfterTime}
+	logs := make([]database.AuditLog, 0)
+	for _, alog := range q.auditLogs {
+		if !auditLogLess(after, alog) || !alog.Time.Before(arg.Until) {
+			continue
+		}
+		logs = append(logs, alog)
+	}
+	slices.SortFunc(logs, auditLogLess)
+	if len(logs) > int(arg.RowLimit) {
+		logs = logs[:arg.RowLimit]
+	}
+	return logs, nil
+}
+
+func (q *fakeQuerier) GetAuditLogsForPurge(_ context.Context, arg database.GetAuditLogsForPurgeParams) ([]database.AuditLog, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	logs := make([]database.AuditLog, 0)
+	for _, alog := range q.auditLogs {
+		if alog.Time.Before(arg.Before) {
+			logs = append(logs, alog)
+		}
+	}
+	slices.SortFunc(logs, auditLogLess)
+	if len(logs) > int(arg.RowLimit) {
+		logs = logs[:arg.RowLimit]
+	}
+	return logs, nil
+}
+
+func (q *fakeQuerier) DeleteAuditLogsByIDs(_ context.Context, ids []uuid.UUID) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	logs := make([]database.AuditLog, 0, len(q.auditLogs))
+	for _, alog := range q.auditLogs {
+		if slices.Contains(ids, alog.ID) {
+			continue
+		}
+		logs = append(logs, alog)
+	}
+	q.auditLogs = logs
+	return nil
+}
+
+func (q *fakeQuerier) InsertAuditLogArchive(_ context.Context, arg database.InsertAuditLogArchiveParams) (database.AuditLogArchive, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return database.AuditLogArchive{}, err
+	}
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	archive := database.AuditLogArchive(arg)
+	q.auditLogArchives = append(q.auditLogArchives, archive)
+	return archive, nil
+}
+
+func (q *fakeQuerier) GetAuditLogArchivesByTimeRange(_ context.Context, arg database.GetAuditLogArchivesByTimeRangeParams) ([]database.AuditLogArchive, error) {
+	if err := validateDatabaseType(arg); err != nil {
+		return nil, err
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	archives := make([]database.AuditLogArchive, 0)
+	for _, archive := range q.auditLogArchives {
+		if archive.Newest.Before(arg.Since) || !archive.Oldest.Before(arg.Until) {
+			continue
+		}
+		archives = append(archives, archive)
+	}
+	slices.SortFunc(archives, func(a, b database.AuditLogArchive) bool {
+		if !a.Oldest.Equal(b.Oldest) {
+			return a.Oldest.Before(b.Oldest)
+		}
+		return bytes.Compare(a.ID[:], b.ID[:]) < 0
+	})
+	return archives, nil
+}
+
+func (q *fakeQuerier) DeleteOldWebhookDeliveries(_ context.Context, before time.Time) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	deliveries := make([]database.WebhookDelivery, 0, len(q.webhookDeliveries))
+	for _, delivery := range q.webhookDeliveries {
+		if delivery.CreatedAt.Before(before) {
+			continue
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	q.webhookDeliveries = deliveries
+	return nil
+}
+
+func (q *fakeQuerier) DeleteOldNotificationMessages(_ context.Context, before time.Time) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	messages := make([]database.NotificationMessage, 0, len(q.notificationMessages))
+	for _, message := range q.notificationMessages {
+		if message.CreatedAt.Before(before) {
+			continue
+		}
+		messages = append(messages, message)
+	}
+	q.notificationMessages = messages
+	return nil
+}
+
+func (q *fakeQuerier) LockFileByID(_ context.Context, id uuid.UUID) (uuid.UUID, error) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	for _, file := range q.files {
+		if file.ID == id {
+			return file.ID, nil
+		}
+	}
+	return uuid.Nil, sql.ErrNoRows
+}
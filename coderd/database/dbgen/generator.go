@@ -147,6 +147,7 @@ func Workspace(t testing.TB, db database.Store, orig database.Workspace) databas
 		Name:              takeFirst(orig.Name, namesgenerator.GetRandomName(1)),
 		AutostartSchedule: orig.AutostartSchedule,
 		Ttl:               orig.Ttl,
+		Labels:            orig.Labels,
 	})
 	require.NoError(t, err, "insert workspace")
 	return workspace
@@ -248,6 +249,35 @@ func GroupMember(t testing.TB, db database.Store, orig database.GroupMember) dat
 	return member
 }
 
+func ProvisionerDaemon(t testing.TB, db database.Store, orig database.ProvisionerDaemon) database.ProvisionerDaemon {
+	daemon, err := db.InsertProvisionerDaemon(context.Background(), database.InsertProvisionerDaemonParams{
+		ID:           takeFirst(orig.ID, uuid.New()),
+		CreatedAt:    takeFirst(orig.CreatedAt, database.Now()),
+		Name:         takeFirst(orig.Name, namesgenerator.GetRandomName(1)),
+		Provisioners: takeFirstSlice(orig.Provisioners, []database.ProvisionerType{database.ProvisionerTypeEcho}),
+		Tags:         orig.Tags,
+		LastSeenAt:   takeFirst(orig.LastSeenAt, sql.NullTime{Time: database.Now(), Valid: true}),
+		Version:      orig.Version,
+	})
+	require.NoError(t, err, "insert provisioner daemon")
+	return daemon
+}
+
+func ProvisionerKey(t testing.TB, db database.Store, orig database.ProvisionerKey) database.ProvisionerKey {
+	secret, _ := cryptorand.String(32)
+	hashed := sha256.Sum256([]byte(secret))
+	key, err := db.InsertProvisionerKey(context.Background(), database.InsertProvisionerKeyParams{
+		ID:             takeFirst(orig.ID, uuid.New()),
+		CreatedAt:      takeFirst(orig.CreatedAt, database.Now()),
+		OrganizationID: takeFirst(orig.OrganizationID, uuid.New()),
+		Name:           takeFirst(orig.Name, namesgenerator.GetRandomName(1)),
+		HashedSecret:   takeFirstSlice(orig.HashedSecret, hashed[:]),
+		Tags:           orig.Tags,
+	})
+	require.NoError(t, err, "insert provisioner key")
+	return key
+}
+
 func ProvisionerJob(t testing.TB, db database.Store, orig database.ProvisionerJob) database.ProvisionerJob {
 	job, err := db.InsertProvisionerJob(context.Background(), database.InsertProvisionerJobParams{
 		ID:             takeFirst(orig.ID, uuid.New()),
@@ -261,6 +291,7 @@ func ProvisionerJob(t testing.TB, db database.Store, orig database.ProvisionerJo
 		Type:           takeFirst(orig.Type, database.ProvisionerJobTypeWorkspaceBuild),
 		Input:          takeFirstSlice(orig.Input, []byte("{}")),
 		Tags:           orig.Tags,
+		Priority:       takeFirst(orig.Priority, database.ProvisionerJobPriorityInteractive),
 	})
 	require.NoError(t, err, "insert job")
 	return job
@@ -333,6 +364,7 @@ func File(t testing.TB, db database.Store, orig database.File) database.File {
 		CreatedBy: takeFirst(orig.CreatedBy, uuid.New()),
 		Mimetype:  takeFirst(orig.Mimetype, "application/x-tar"),
 		Data:      takeFirstSlice(orig.Data, []byte{}),
+		Storage:   takeFirst(orig.Storage, "database"),
 	})
 	require.NoError(t, err, "insert file")
 	return file
@@ -378,6 +410,10 @@ func TemplateVersion(t testing.TB, db database.Store, orig database.TemplateVers
 		Readme:         takeFirst(orig.Readme, namesgenerator.GetRandomName(1)),
 		JobID:          takeFirst(orig.JobID, uuid.New()),
 		CreatedBy:      takeFirst(orig.CreatedBy, uuid.New()),
+		GitURL:         orig.GitURL,
+		GitRef:         orig.GitRef,
+		GitDirectory:   orig.GitDirectory,
+		GitCommitSHA:   orig.GitCommitSHA,
 	})
 	require.NoError(t, err, "insert template version")
 	return version
@@ -437,3 +473,51 @@ func ParameterValue(t testing.TB, db database.Store, seed database.ParameterValu
 	require.NoError(t, err, "insert parameter value")
 	return scheme
 }
+
+func TemplateGitSync(t testing.TB, db database.Store, orig database.TemplateGitSync) database.TemplateGitSync {
+	secret, _ := cryptorand.String(32)
+	gitSync, err := db.UpsertTemplateGitSync(context.Background(), database.UpsertTemplateGitSyncParams{
+		TemplateID:    takeFirst(orig.TemplateID, uuid.New()),
+		CreatedAt:     takeFirst(orig.CreatedAt, database.Now()),
+		UpdatedAt:     takeFirst(orig.UpdatedAt, database.Now()),
+		UserID:        takeFirst(orig.UserID, uuid.New()),
+		GitURL:        takeFirst(orig.GitURL, "https://git.example.com/templates.git"),
+		GitBranch:     takeFirst(orig.GitBranch, "main"),
+		GitDirectory:  orig.GitDirectory,
+		WebhookSecret: takeFirst(orig.WebhookSecret, secret),
+	})
+	require.NoError(t, err, "insert template git sync")
+	return gitSync
+}
+
+func Webhook(t testing.TB, db database.Store, orig database.Webhook) database.Webhook {
+	secret, _ := cryptorand.String(32)
+	webhook, err := db.InsertWebhook(context.Background(), database.InsertWebhookParams{
+		ID:             takeFirst(orig.ID, uuid.New()),
+		OrganizationID: orig.OrganizationID,
+		CreatedBy:      takeFirst(orig.CreatedBy, uuid.New()),
+		CreatedAt:      takeFirst(orig.CreatedAt, database.Now()),
+		UpdatedAt:      takeFirst(orig.UpdatedAt, database.Now()),
+		Name:           takeFirst(orig.Name, namesgenerator.GetRandomName(1)),
+		Url:            takeFirst(orig.Url, "https://hooks.example.com"),
+		Secret:         takeFirst(orig.Secret, secret),
+		Events:         takeFirstSlice(orig.Events, []string{}),
+		Enabled:        orig.Enabled,
+	})
+	require.NoError(t, err, "insert webhook")
+	return webhook
+}
+
+func WebhookDelivery(t testing.TB, db database.Store, orig database.WebhookDelivery) database.WebhookDelivery {
+	delivery, err := db.InsertWebhookDelivery(context.Background(), database.InsertWebhookDeliveryParams{
+		ID:        takeFirst(orig.ID, uuid.New()),
+		WebhookID: takeFirst(orig.WebhookID, uuid.New()),
+		EventID:   takeFirst(orig.EventID, uuid.New()),
+		Event:     takeFirst(orig.Event, "workspace.created"),
+		Payload:   takeFirstSlice(orig.Payload, []byte("{}")),
+		Attempt:   takeFirst(orig.Attempt, 1),
+		CreatedAt: takeFirst(orig.CreatedAt, database.Now()),
+	})
+	require.NoError(t, err, "insert webhook delivery")
+	return delivery
+}
@@ -54,6 +54,23 @@ func TestGenerator(t *testing.T) {
 		})))
 	})
 
+	t.Run("Webhook", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		exp := dbgen.Webhook(t, db, database.Webhook{})
+		require.Equal(t, exp, must(db.GetWebhookByID(context.Background(), exp.ID)))
+	})
+
+	t.Run("WebhookDelivery", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		webhook := dbgen.Webhook(t, db, database.Webhook{})
+		exp := dbgen.WebhookDelivery(t, db, database.WebhookDelivery{WebhookID: webhook.ID})
+		require.Equal(t, []database.WebhookDelivery{exp}, must(db.GetWebhookDeliveriesByWebhookID(context.Background(), database.GetWebhookDeliveriesByWebhookIDParams{
+			WebhookID: webhook.ID,
+		})))
+	})
+
 	t.Run("WorkspaceResource", func(t *testing.T) {
 		t.Parallel()
 		db := dbfake.New()
@@ -75,6 +92,20 @@ func TestGenerator(t *testing.T) {
 		require.Equal(t, exp, must(db.GetWorkspaceResourceMetadataByResourceIDs(context.Background(), []uuid.UUID{exp[0].WorkspaceResourceID})))
 	})
 
+	t.Run("ProvisionerDaemon", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		exp := dbgen.ProvisionerDaemon(t, db, database.ProvisionerDaemon{})
+		require.Equal(t, []database.ProvisionerDaemon{exp}, must(db.GetProvisionerDaemons(context.Background())))
+	})
+
+	t.Run("ProvisionerKey", func(t *testing.T) {
+		t.Parallel()
+		db := dbfake.New()
+		exp := dbgen.ProvisionerKey(t, db, database.ProvisionerKey{})
+		require.Equal(t, exp, must(db.GetProvisionerKeyByID(context.Background(), exp.ID)))
+	})
+
 	t.Run("Job", func(t *testing.T) {
 		t.Parallel()
 		db := dbfake.New()
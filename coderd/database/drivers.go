@@ -43,3 +43,45 @@ func (t *TemplateACL) Scan(src interface{}) error {
 func (t TemplateACL) Value() (driver.Value, error) {
 	return json.Marshal(t)
 }
+
+// WorkspaceACL is a map of ids to permissions.
+type WorkspaceACL map[string][]rbac.Action
+
+func (t *WorkspaceACL) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), &t)
+	case []byte, json.RawMessage:
+		//nolint
+		return json.Unmarshal(v.([]byte), &t)
+	}
+
+	return xerrors.Errorf("unexpected type %T", src)
+}
+
+func (t WorkspaceACL) Value() (driver.Value, error) {
+	return json.Marshal(t)
+}
+
+// WorkspaceLabels is a map of user defined label keys to values.
+type WorkspaceLabels map[string]string
+
+func (t *WorkspaceLabels) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return json.Unmarshal([]byte(v), &t)
+	case []byte, json.RawMessage:
+		//nolint
+		return json.Unmarshal(v.([]byte), &t)
+	}
+
+	return xerrors.Errorf("unexpected type %T", src)
+}
+
+func (t WorkspaceLabels) Value() (driver.Value, error) {
+	// The column is NOT NULL, store an empty object for nil maps.
+	if t == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(t)
+}
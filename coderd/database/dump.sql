@@ -18,7 +18,9 @@ CREATE TYPE audit_action AS ENUM (
     'start',
     'stop',
     'login',
-    'logout'
+    'logout',
+    'connect',
+    'disconnect'
 );
 
 CREATE TYPE build_reason AS ENUM (
@@ -69,6 +71,12 @@ CREATE TYPE parameter_type_system AS ENUM (
     'hcl'
 );
 
+CREATE TYPE provisioner_job_priority AS ENUM (
+    'interactive',
+    'automatic',
+    'background'
+);
+
 CREATE TYPE provisioner_job_type AS ENUM (
     'template_version_import',
     'workspace_build',
@@ -94,7 +102,9 @@ CREATE TYPE resource_type AS ENUM (
     'api_key',
     'group',
     'workspace_build',
-    'license'
+    'license',
+    'organization_member',
+    'appearance'
 );
 
 CREATE TYPE user_status AS ENUM (
@@ -139,6 +149,23 @@ CREATE TABLE api_keys (
 
 COMMENT ON COLUMN api_keys.hashed_secret IS 'hashed_secret contains a SHA256 hash of the key secret. This is considered a secret and MUST NOT be returned from the API as it is used for API key encryption in app proxying code.';
 
+CREATE TABLE audit_log_archives (
+    id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    oldest timestamp with time zone NOT NULL,
+    newest timestamp with time zone NOT NULL,
+    log_count integer NOT NULL,
+    storage text NOT NULL,
+    hash character varying(64) NOT NULL,
+    size bigint NOT NULL
+);
+
+COMMENT ON COLUMN audit_log_archives.oldest IS 'The time of the oldest audit log in the archive.';
+
+COMMENT ON COLUMN audit_log_archives.newest IS 'The time of the newest audit log in the archive.';
+
+COMMENT ON COLUMN audit_log_archives.hash IS 'The SHA256 hash of the gzipped JSON lines of the audit logs, which the archive is stored by in the file store.';
+
 CREATE TABLE audit_logs (
     id uuid NOT NULL,
     "time" timestamp with time zone NOT NULL,
@@ -163,9 +190,12 @@ CREATE TABLE files (
     created_by uuid NOT NULL,
     mimetype character varying(64) NOT NULL,
     data bytea NOT NULL,
-    id uuid DEFAULT gen_random_uuid() NOT NULL
+    id uuid DEFAULT gen_random_uuid() NOT NULL,
+    storage text DEFAULT 'database'::text NOT NULL
 );
 
+COMMENT ON COLUMN files.storage IS 'The backend the contents of the file are stored in: database, fs or s3. Contents stored in other backends are keyed by the hash of the file.';
+
 CREATE TABLE git_auth_links (
     provider_id text NOT NULL,
     user_id uuid NOT NULL,
@@ -217,6 +247,22 @@ CREATE SEQUENCE licenses_id_seq
 
 ALTER SEQUENCE licenses_id_seq OWNED BY licenses.id;
 
+CREATE TABLE notification_messages (
+    id uuid NOT NULL,
+    user_id uuid NOT NULL,
+    kind text NOT NULL,
+    dedupe_key text NOT NULL,
+    subject text NOT NULL,
+    created_at timestamp with time zone NOT NULL
+);
+
+CREATE TABLE notification_preferences (
+    user_id uuid NOT NULL,
+    kind text NOT NULL,
+    disabled boolean DEFAULT false NOT NULL,
+    updated_at timestamp with time zone NOT NULL
+);
+
 CREATE TABLE organization_members (
     user_id uuid NOT NULL,
     organization_id uuid NOT NULL,
@@ -272,9 +318,15 @@ CREATE TABLE provisioner_daemons (
     name character varying(64) NOT NULL,
     provisioners provisioner_type[] NOT NULL,
     replica_id uuid,
-    tags jsonb DEFAULT '{}'::jsonb NOT NULL
+    tags jsonb DEFAULT '{}'::jsonb NOT NULL,
+    last_seen_at timestamp with time zone,
+    version text DEFAULT ''::text NOT NULL
 );
 
+COMMENT ON COLUMN provisioner_daemons.last_seen_at IS 'When the daemon last polled for or updated a job. Daemons that haven''t been seen recently are offline.';
+
+COMMENT ON COLUMN provisioner_daemons.version IS 'The version of Coder the daemon runs.';
+
 CREATE TABLE provisioner_job_logs (
     job_id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -310,9 +362,28 @@ CREATE TABLE provisioner_jobs (
     input jsonb NOT NULL,
     worker_id uuid,
     file_id uuid NOT NULL,
-    tags jsonb DEFAULT '{"scope": "organization"}'::jsonb NOT NULL
+    tags jsonb DEFAULT '{"scope": "organization"}'::jsonb NOT NULL,
+    priority provisioner_job_priority DEFAULT 'interactive'::provisioner_job_priority NOT NULL,
+    last_heartbeat_at timestamp with time zone
 );
 
+COMMENT ON COLUMN provisioner_jobs.priority IS 'Jobs are acquired by priority: interactive jobs started by users first, then automatic jobs such as autostart and autostop, then background jobs such as bulk builds and template imports.';
+
+COMMENT ON COLUMN provisioner_jobs.last_heartbeat_at IS 'When the provisioner daemon running the job last sent an update. Running jobs without a recent heartbeat are marked as failed.';
+
+CREATE TABLE provisioner_keys (
+    id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    organization_id uuid NOT NULL,
+    name character varying(64) NOT NULL,
+    hashed_secret bytea NOT NULL,
+    tags jsonb DEFAULT '{}'::jsonb NOT NULL
+);
+
+COMMENT ON COLUMN provisioner_keys.hashed_secret IS 'The SHA256 hash of the key secret.';
+
+COMMENT ON COLUMN provisioner_keys.tags IS 'The tags daemons using the key are given. Daemons can''t claim tags outside of this set.';
+
 CREATE TABLE replicas (
     id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -332,6 +403,33 @@ CREATE TABLE site_configs (
     value character varying(8192) NOT NULL
 );
 
+CREATE TABLE template_git_syncs (
+    template_id uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    user_id uuid NOT NULL,
+    git_url text NOT NULL,
+    git_branch text NOT NULL,
+    git_directory text NOT NULL,
+    webhook_secret text NOT NULL,
+    commit_sha text DEFAULT ''::text NOT NULL,
+    template_version_id uuid,
+    status text DEFAULT 'pending'::text NOT NULL,
+    error text DEFAULT ''::text NOT NULL,
+    checked_at timestamp with time zone,
+    dry_run_job_id uuid
+);
+
+COMMENT ON COLUMN template_git_syncs.webhook_secret IS 'The secret push webhooks are verified with.';
+
+COMMENT ON COLUMN template_git_syncs.commit_sha IS 'The last commit of the branch a template version was created for.';
+
+COMMENT ON COLUMN template_git_syncs.status IS 'The status of the version created for the last commit: pending, succeeded or failed.';
+
+COMMENT ON COLUMN template_git_syncs.error IS 'The error of the last sync, empty if it succeeded.';
+
+COMMENT ON COLUMN template_git_syncs.dry_run_job_id IS 'The dry-run of the version created for the last commit, with the default parameters. The version is only promoted if it succeeds.';
+
 CREATE TABLE template_version_parameters (
     template_version_id uuid NOT NULL,
     name text NOT NULL,
@@ -408,11 +506,20 @@ CREATE TABLE template_versions (
     readme character varying(1048576) NOT NULL,
     job_id uuid NOT NULL,
     created_by uuid NOT NULL,
-    git_auth_providers text[]
+    git_auth_providers text[],
+    archived boolean DEFAULT false NOT NULL,
+    git_url text DEFAULT ''::text NOT NULL,
+    git_ref text DEFAULT ''::text NOT NULL,
+    git_directory text DEFAULT ''::text NOT NULL,
+    git_commit_sha text DEFAULT ''::text NOT NULL
 );
 
 COMMENT ON COLUMN template_versions.git_auth_providers IS 'IDs of Git auth providers for a specific template version';
 
+COMMENT ON COLUMN template_versions.git_url IS 'The git repository the version was created from, if any';
+
+COMMENT ON COLUMN template_versions.git_commit_sha IS 'The commit the ref resolved to when the version was created';
+
 CREATE TABLE templates (
     id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -429,7 +536,8 @@ CREATE TABLE templates (
     user_acl jsonb DEFAULT '{}'::jsonb NOT NULL,
     group_acl jsonb DEFAULT '{}'::jsonb NOT NULL,
     display_name character varying(64) DEFAULT ''::character varying NOT NULL,
-    allow_user_cancel_workspace_jobs boolean DEFAULT true NOT NULL
+    allow_user_cancel_workspace_jobs boolean DEFAULT true NOT NULL,
+    deprecated text DEFAULT ''::text NOT NULL
 );
 
 COMMENT ON COLUMN templates.default_ttl IS 'The default duration for auto-stop for workspaces created from this template.';
@@ -438,6 +546,8 @@ COMMENT ON COLUMN templates.display_name IS 'Display name is a custom, human-fri
 
 COMMENT ON COLUMN templates.allow_user_cancel_workspace_jobs IS 'Allow users to cancel in-progress workspace jobs.';
 
+COMMENT ON COLUMN templates.deprecated IS 'If set to a non empty string, the template will no longer be able to be used. The message will be displayed to the user.';
+
 CREATE TABLE user_links (
     user_id uuid NOT NULL,
     login_type login_type NOT NULL,
@@ -462,6 +572,35 @@ CREATE TABLE users (
     last_seen_at timestamp without time zone DEFAULT '0001-01-01 00:00:00'::timestamp without time zone NOT NULL
 );
 
+CREATE TABLE webhook_deliveries (
+    id uuid NOT NULL,
+    webhook_id uuid NOT NULL,
+    event_id uuid NOT NULL,
+    event text NOT NULL,
+    payload jsonb NOT NULL,
+    attempt integer NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    delivered_at timestamp with time zone,
+    status_code integer,
+    response_body text DEFAULT ''::text NOT NULL,
+    error text DEFAULT ''::text NOT NULL
+);
+
+CREATE TABLE webhooks (
+    id uuid NOT NULL,
+    organization_id uuid,
+    created_by uuid NOT NULL,
+    created_at timestamp with time zone NOT NULL,
+    updated_at timestamp with time zone NOT NULL,
+    name text NOT NULL,
+    url text NOT NULL,
+    secret text NOT NULL,
+    events text[] DEFAULT '{}'::text[] NOT NULL,
+    enabled boolean DEFAULT true NOT NULL
+);
+
+COMMENT ON COLUMN webhooks.secret IS 'The secret used to sign delivery payloads with HMAC-SHA256.';
+
 CREATE TABLE workspace_agent_stats (
     id uuid NOT NULL,
     created_at timestamp with time zone NOT NULL,
@@ -474,7 +613,8 @@ CREATE TABLE workspace_agent_stats (
     rx_packets bigint DEFAULT 0 NOT NULL,
     rx_bytes bigint DEFAULT 0 NOT NULL,
     tx_packets bigint DEFAULT 0 NOT NULL,
-    tx_bytes bigint DEFAULT 0 NOT NULL
+    tx_bytes bigint DEFAULT 0 NOT NULL,
+    connections_by_port jsonb DEFAULT '{}'::jsonb NOT NULL
 );
 
 CREATE TABLE workspace_agents (
@@ -607,7 +747,10 @@ CREATE TABLE workspaces (
     name character varying(64) NOT NULL,
     autostart_schedule text,
     ttl bigint,
-    last_used_at timestamp without time zone DEFAULT '0001-01-01 00:00:00'::timestamp without time zone NOT NULL
+    last_used_at timestamp without time zone DEFAULT '0001-01-01 00:00:00'::timestamp without time zone NOT NULL,
+    user_acl jsonb DEFAULT '{}'::jsonb NOT NULL,
+    group_acl jsonb DEFAULT '{}'::jsonb NOT NULL,
+    labels jsonb DEFAULT '{}'::jsonb NOT NULL
 );
 
 ALTER TABLE ONLY licenses ALTER COLUMN id SET DEFAULT nextval('licenses_id_seq'::regclass);
@@ -622,6 +765,9 @@ ALTER TABLE ONLY workspace_agent_stats
 ALTER TABLE ONLY api_keys
     ADD CONSTRAINT api_keys_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY audit_log_archives
+    ADD CONSTRAINT audit_log_archives_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY audit_logs
     ADD CONSTRAINT audit_logs_pkey PRIMARY KEY (id);
 
@@ -652,6 +798,15 @@ ALTER TABLE ONLY licenses
 ALTER TABLE ONLY licenses
     ADD CONSTRAINT licenses_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY notification_messages
+    ADD CONSTRAINT notification_messages_dedupe_key_key UNIQUE (dedupe_key);
+
+ALTER TABLE ONLY notification_messages
+    ADD CONSTRAINT notification_messages_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY notification_preferences
+    ADD CONSTRAINT notification_preferences_pkey PRIMARY KEY (user_id, kind);
+
 ALTER TABLE ONLY organization_members
     ADD CONSTRAINT organization_members_pkey PRIMARY KEY (organization_id, user_id);
 
@@ -682,9 +837,21 @@ ALTER TABLE ONLY provisioner_job_logs
 ALTER TABLE ONLY provisioner_jobs
     ADD CONSTRAINT provisioner_jobs_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY provisioner_keys
+    ADD CONSTRAINT provisioner_keys_hashed_secret_key UNIQUE (hashed_secret);
+
+ALTER TABLE ONLY provisioner_keys
+    ADD CONSTRAINT provisioner_keys_organization_id_name_key UNIQUE (organization_id, name);
+
+ALTER TABLE ONLY provisioner_keys
+    ADD CONSTRAINT provisioner_keys_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY site_configs
     ADD CONSTRAINT site_configs_key_key UNIQUE (key);
 
+ALTER TABLE ONLY template_git_syncs
+    ADD CONSTRAINT template_git_syncs_pkey PRIMARY KEY (template_id);
+
 ALTER TABLE ONLY template_version_parameters
     ADD CONSTRAINT template_version_parameters_template_version_id_name_key UNIQUE (template_version_id, name);
 
@@ -706,6 +873,15 @@ ALTER TABLE ONLY user_links
 ALTER TABLE ONLY users
     ADD CONSTRAINT users_pkey PRIMARY KEY (id);
 
+ALTER TABLE ONLY webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_pkey PRIMARY KEY (id);
+
+ALTER TABLE ONLY webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_webhook_id_event_id_attempt_key UNIQUE (webhook_id, event_id, attempt);
+
+ALTER TABLE ONLY webhooks
+    ADD CONSTRAINT webhooks_pkey PRIMARY KEY (id);
+
 ALTER TABLE ONLY workspace_agents
     ADD CONSTRAINT workspace_agents_pkey PRIMARY KEY (id);
 
@@ -745,6 +921,8 @@ CREATE INDEX idx_agent_stats_user_id ON workspace_agent_stats USING btree (user_
 
 CREATE INDEX idx_api_keys_user ON api_keys USING btree (user_id);
 
+CREATE INDEX idx_audit_log_archives_oldest_newest ON audit_log_archives USING btree (oldest, newest);
+
 CREATE INDEX idx_audit_log_organization_id ON audit_logs USING btree (organization_id);
 
 CREATE INDEX idx_audit_log_resource_id ON audit_logs USING btree (resource_id);
@@ -753,6 +931,8 @@ CREATE INDEX idx_audit_log_user_id ON audit_logs USING btree (user_id);
 
 CREATE INDEX idx_audit_logs_time_desc ON audit_logs USING btree ("time" DESC);
 
+CREATE INDEX idx_notification_messages_created_at ON notification_messages USING btree (created_at);
+
 CREATE INDEX idx_organization_member_organization_id_uuid ON organization_members USING btree (organization_id);
 
 CREATE INDEX idx_organization_member_user_id_uuid ON organization_members USING btree (user_id);
@@ -765,8 +945,18 @@ CREATE UNIQUE INDEX idx_users_email ON users USING btree (email) WHERE (deleted
 
 CREATE UNIQUE INDEX idx_users_username ON users USING btree (username) WHERE (deleted = false);
 
+CREATE INDEX idx_webhook_deliveries_created_at ON webhook_deliveries USING btree (created_at);
+
+CREATE INDEX idx_workspace_builds_created_at ON workspace_builds USING btree (created_at);
+
+CREATE INDEX notification_messages_user_id_created_at_idx ON notification_messages USING btree (user_id, created_at DESC);
+
 CREATE INDEX provisioner_job_logs_id_job_id_idx ON provisioner_job_logs USING btree (job_id, id);
 
+CREATE INDEX provisioner_jobs_running_initiator_id_idx ON provisioner_jobs USING btree (initiator_id) WHERE ((started_at IS NOT NULL) AND (completed_at IS NULL));
+
+CREATE INDEX provisioner_jobs_running_organization_id_idx ON provisioner_jobs USING btree (organization_id) WHERE ((started_at IS NOT NULL) AND (completed_at IS NULL));
+
 CREATE INDEX provisioner_jobs_started_at_idx ON provisioner_jobs USING btree (started_at) WHERE (started_at IS NULL);
 
 CREATE UNIQUE INDEX templates_organization_id_name_idx ON templates USING btree (organization_id, lower((name)::text)) WHERE (deleted = false);
@@ -775,12 +965,16 @@ CREATE UNIQUE INDEX users_email_lower_idx ON users USING btree (lower(email)) WH
 
 CREATE UNIQUE INDEX users_username_lower_idx ON users USING btree (lower(username)) WHERE (deleted = false);
 
+CREATE INDEX webhook_deliveries_webhook_id_created_at_idx ON webhook_deliveries USING btree (webhook_id, created_at DESC);
+
 CREATE INDEX workspace_agents_auth_token_idx ON workspace_agents USING btree (auth_token);
 
 CREATE INDEX workspace_agents_resource_id_idx ON workspace_agents USING btree (resource_id);
 
 CREATE INDEX workspace_resources_job_id_idx ON workspace_resources USING btree (job_id);
 
+CREATE INDEX workspaces_labels_idx ON workspaces USING gin (labels);
+
 CREATE UNIQUE INDEX workspaces_owner_id_lower_idx ON workspaces USING btree (owner_id, lower((name)::text)) WHERE (deleted = false);
 
 ALTER TABLE ONLY api_keys
@@ -798,6 +992,12 @@ ALTER TABLE ONLY group_members
 ALTER TABLE ONLY groups
     ADD CONSTRAINT groups_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY notification_messages
+    ADD CONSTRAINT notification_messages_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY notification_preferences
+    ADD CONSTRAINT notification_preferences_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY organization_members
     ADD CONSTRAINT organization_members_organization_id_uuid_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
 
@@ -813,6 +1013,21 @@ ALTER TABLE ONLY provisioner_job_logs
 ALTER TABLE ONLY provisioner_jobs
     ADD CONSTRAINT provisioner_jobs_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY provisioner_keys
+    ADD CONSTRAINT provisioner_keys_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_git_syncs
+    ADD CONSTRAINT template_git_syncs_dry_run_job_id_fkey FOREIGN KEY (dry_run_job_id) REFERENCES provisioner_jobs(id) ON DELETE SET NULL;
+
+ALTER TABLE ONLY template_git_syncs
+    ADD CONSTRAINT template_git_syncs_template_id_fkey FOREIGN KEY (template_id) REFERENCES templates(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY template_git_syncs
+    ADD CONSTRAINT template_git_syncs_template_version_id_fkey FOREIGN KEY (template_version_id) REFERENCES template_versions(id) ON DELETE SET NULL;
+
+ALTER TABLE ONLY template_git_syncs
+    ADD CONSTRAINT template_git_syncs_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY template_version_parameters
     ADD CONSTRAINT template_version_parameters_template_version_id_fkey FOREIGN KEY (template_version_id) REFERENCES template_versions(id) ON DELETE CASCADE;
 
@@ -837,6 +1052,15 @@ ALTER TABLE ONLY templates
 ALTER TABLE ONLY user_links
     ADD CONSTRAINT user_links_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
 
+ALTER TABLE ONLY webhook_deliveries
+    ADD CONSTRAINT webhook_deliveries_webhook_id_fkey FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY webhooks
+    ADD CONSTRAINT webhooks_created_by_fkey FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE ONLY webhooks
+    ADD CONSTRAINT webhooks_organization_id_fkey FOREIGN KEY (organization_id) REFERENCES organizations(id) ON DELETE CASCADE;
+
 ALTER TABLE ONLY workspace_agents
     ADD CONSTRAINT workspace_agents_resource_id_fkey FOREIGN KEY (resource_id) REFERENCES workspace_resources(id) ON DELETE CASCADE;
 
@@ -0,0 +1,9 @@
+package database
+
+// IDs of the locks acquired with TryAcquireLock. IDs must never be reused,
+// so replicas running different versions don't share a lock by accident.
+const (
+	LockIDProvisionerJobHangDetector = iota + 1
+	LockIDDBPurge
+	LockIDTemplateGitSyncPromote
+)
@@ -0,0 +1,37 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+	id uuid NOT NULL,
+	-- A NULL organization_id means the webhook is deployment-wide and
+	-- receives events from every organization.
+	organization_id uuid REFERENCES organizations (id) ON DELETE CASCADE,
+	created_by uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	created_at timestamp with time zone NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	name text NOT NULL,
+	url text NOT NULL,
+	secret text NOT NULL,
+	events text[] NOT NULL DEFAULT '{}'::text[],
+	enabled boolean NOT NULL DEFAULT true,
+	PRIMARY KEY (id)
+);
+
+COMMENT ON COLUMN webhooks.secret IS 'The secret used to sign delivery payloads with HMAC-SHA256.';
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+	id uuid NOT NULL,
+	webhook_id uuid NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+	event_id uuid NOT NULL,
+	event text NOT NULL,
+	payload jsonb NOT NULL,
+	attempt integer NOT NULL,
+	created_at timestamp with time zone NOT NULL,
+	delivered_at timestamp with time zone,
+	status_code integer,
+	response_body text NOT NULL DEFAULT '',
+	error text NOT NULL DEFAULT '',
+	PRIMARY KEY (id),
+	-- Each replica receives every event over pubsub. Inserting an attempt
+	-- claims it, so only one replica delivers it.
+	UNIQUE (webhook_id, event_id, attempt)
+);
+
+CREATE INDEX webhook_deliveries_webhook_id_created_at_idx ON webhook_deliveries USING btree (webhook_id, created_at DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS notification_messages;
+DROP TABLE IF EXISTS notification_preferences;
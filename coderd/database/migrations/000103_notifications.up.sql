@@ -0,0 +1,24 @@
+-- Notifications are sent unless the user has opted out of the kind.
+CREATE TABLE IF NOT EXISTS notification_preferences (
+	user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	kind text NOT NULL,
+	disabled boolean NOT NULL DEFAULT false,
+	updated_at timestamp with time zone NOT NULL,
+	PRIMARY KEY (user_id, kind)
+);
+
+CREATE TABLE IF NOT EXISTS notification_messages (
+	id uuid NOT NULL,
+	user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	kind text NOT NULL,
+	-- Every replica may decide to send the same notification, e.g. the
+	-- autostop warning for a build. Inserting the message claims it, so
+	-- only one replica sends it.
+	dedupe_key text NOT NULL,
+	subject text NOT NULL,
+	created_at timestamp with time zone NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE (dedupe_key)
+);
+
+CREATE INDEX notification_messages_user_id_created_at_idx ON notification_messages USING btree (user_id, created_at DESC);
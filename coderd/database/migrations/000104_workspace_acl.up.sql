@@ -0,0 +1,8 @@
+BEGIN;
+
+-- Maps user and group IDs to the actions they may perform on a workspace,
+-- like templates.user_acl and templates.group_acl.
+ALTER TABLE workspaces ADD COLUMN user_acl jsonb NOT NULL default '{}';
+ALTER TABLE workspaces ADD COLUMN group_acl jsonb NOT NULL default '{}';
+
+COMMIT;
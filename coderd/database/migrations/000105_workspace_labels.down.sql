@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX workspaces_labels_idx;
+ALTER TABLE workspaces DROP COLUMN labels;
+
+COMMIT;
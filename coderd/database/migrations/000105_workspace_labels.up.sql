@@ -0,0 +1,9 @@
+BEGIN;
+
+-- Key/value pairs set by users to organize workspaces, e.g. by project or
+-- cost center. The GIN index serves the label:key=value search filter.
+ALTER TABLE workspaces ADD COLUMN labels jsonb NOT NULL default '{}';
+
+CREATE INDEX workspaces_labels_idx ON workspaces USING gin (labels);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE templates DROP COLUMN deprecated;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+-- A template is deprecated while the column is not empty. No new workspaces
+-- can be created from it, and the message is shown to the owners of its
+-- existing workspaces.
+ALTER TABLE templates ADD COLUMN deprecated text NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN templates.deprecated IS 'If set to a non empty string, the template will no longer be able to be used. The message will be displayed to the user.';
+
+COMMIT;
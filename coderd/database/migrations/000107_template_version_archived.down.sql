@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE template_versions DROP COLUMN archived;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- Archived template versions can't be used for new workspace builds, and are
+-- hidden from version listings unless asked for. Their files and job logs
+-- may be garbage collected.
+ALTER TABLE template_versions ADD COLUMN archived boolean NOT NULL DEFAULT false;
+
+COMMIT;
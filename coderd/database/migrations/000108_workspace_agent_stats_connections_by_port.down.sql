@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE workspace_agent_stats DROP COLUMN connections_by_port;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Connections are also counted by the agent port they were made to, so
+-- template insights can tell SSH, web terminal and app usage apart.
+ALTER TABLE workspace_agent_stats ADD COLUMN connections_by_port jsonb NOT NULL DEFAULT '{}'::jsonb;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE template_versions
+	DROP COLUMN git_url,
+	DROP COLUMN git_ref,
+	DROP COLUMN git_directory,
+	DROP COLUMN git_commit_sha;
+
+COMMIT;
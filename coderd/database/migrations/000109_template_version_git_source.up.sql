@@ -0,0 +1,15 @@
+BEGIN;
+
+-- Template versions can be created from a git repository instead of an
+-- uploaded archive. The source is kept so the provenance of the version can
+-- be audited.
+ALTER TABLE template_versions
+	ADD COLUMN git_url text NOT NULL DEFAULT '',
+	ADD COLUMN git_ref text NOT NULL DEFAULT '',
+	ADD COLUMN git_directory text NOT NULL DEFAULT '',
+	ADD COLUMN git_commit_sha text NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN template_versions.git_url IS 'The git repository the version was created from, if any';
+COMMENT ON COLUMN template_versions.git_commit_sha IS 'The commit the ref resolved to when the version was created';
+
+COMMIT;
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS template_git_syncs (
+	template_id uuid NOT NULL REFERENCES templates (id) ON DELETE CASCADE,
+	created_at timestamp with time zone NOT NULL,
+	updated_at timestamp with time zone NOT NULL,
+	-- Versions are created with the permissions and git auth credentials of
+	-- the user that set up the sync.
+	user_id uuid NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	git_url text NOT NULL,
+	git_branch text NOT NULL,
+	git_directory text NOT NULL,
+	webhook_secret text NOT NULL,
+	commit_sha text NOT NULL DEFAULT '',
+	template_version_id uuid REFERENCES template_versions (id) ON DELETE SET NULL,
+	status text NOT NULL DEFAULT 'pending',
+	error text NOT NULL DEFAULT '',
+	checked_at timestamp with time zone,
+	PRIMARY KEY (template_id)
+);
+
+COMMENT ON COLUMN template_git_syncs.commit_sha IS 'The last commit of the branch a template version was created for.';
+COMMENT ON COLUMN template_git_syncs.status IS 'The status of the version created for the last commit: pending, succeeded or failed.';
+COMMENT ON COLUMN template_git_syncs.error IS 'The error of the last sync, empty if it succeeded.';
+COMMENT ON COLUMN template_git_syncs.webhook_secret IS 'The secret push webhooks are verified with.';
@@ -0,0 +1,4 @@
+-- Files whose contents are stored outside of the database have empty data.
+ALTER TABLE files ADD COLUMN storage text NOT NULL DEFAULT 'database';
+
+COMMENT ON COLUMN files.storage IS 'The backend the contents of the file are stored in: database, fs or s3. Contents stored in other backends are keyed by the hash of the file.';
@@ -0,0 +1,4 @@
+DROP INDEX provisioner_jobs_running_organization_id_idx;
+DROP INDEX provisioner_jobs_running_initiator_id_idx;
+ALTER TABLE provisioner_jobs DROP COLUMN priority;
+DROP TYPE provisioner_job_priority;
@@ -0,0 +1,15 @@
+-- The order of the values is the order jobs are acquired in.
+CREATE TYPE provisioner_job_priority AS ENUM (
+    'interactive',
+    'automatic',
+    'background'
+);
+
+ALTER TABLE provisioner_jobs ADD COLUMN priority provisioner_job_priority NOT NULL DEFAULT 'interactive';
+
+COMMENT ON COLUMN provisioner_jobs.priority IS 'Jobs are acquired by priority: interactive jobs started by users first, then automatic jobs such as autostart and autostop, then background jobs such as bulk builds and template imports.';
+
+-- Acquiring a job counts the running jobs of its initiator and organization,
+-- so no single user or organization starves the queue.
+CREATE INDEX provisioner_jobs_running_initiator_id_idx ON provisioner_jobs USING btree (initiator_id) WHERE (started_at IS NOT NULL AND completed_at IS NULL);
+CREATE INDEX provisioner_jobs_running_organization_id_idx ON provisioner_jobs USING btree (organization_id) WHERE (started_at IS NOT NULL AND completed_at IS NULL);
@@ -0,0 +1 @@
+ALTER TABLE provisioner_jobs DROP COLUMN last_heartbeat_at;
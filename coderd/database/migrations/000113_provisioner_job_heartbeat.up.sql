@@ -0,0 +1,6 @@
+ALTER TABLE provisioner_jobs ADD COLUMN last_heartbeat_at timestamp with time zone;
+
+COMMENT ON COLUMN provisioner_jobs.last_heartbeat_at IS 'When the provisioner daemon running the job last sent an update. Running jobs without a recent heartbeat are marked as failed.';
+
+-- Running jobs have been updated by their daemon until now.
+UPDATE provisioner_jobs SET last_heartbeat_at = updated_at WHERE started_at IS NOT NULL;
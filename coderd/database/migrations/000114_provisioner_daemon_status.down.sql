@@ -0,0 +1,3 @@
+ALTER TABLE provisioner_daemons
+	DROP COLUMN last_seen_at,
+	DROP COLUMN version;
@@ -0,0 +1,7 @@
+ALTER TABLE provisioner_daemons
+	ADD COLUMN last_seen_at timestamp with time zone,
+	ADD COLUMN version text DEFAULT ''::text NOT NULL;
+
+COMMENT ON COLUMN provisioner_daemons.last_seen_at IS 'When the daemon last polled for or updated a job. Daemons that haven''t been seen recently are offline.';
+
+COMMENT ON COLUMN provisioner_daemons.version IS 'The version of Coder the daemon runs.';
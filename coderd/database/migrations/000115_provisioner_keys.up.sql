@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS provisioner_keys (
+	id uuid NOT NULL,
+	created_at timestamp with time zone NOT NULL,
+	organization_id uuid NOT NULL REFERENCES organizations (id) ON DELETE CASCADE,
+	name character varying(64) NOT NULL,
+	hashed_secret bytea NOT NULL,
+	tags jsonb DEFAULT '{}'::jsonb NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE (organization_id, name),
+	UNIQUE (hashed_secret)
+);
+
+COMMENT ON COLUMN provisioner_keys.hashed_secret IS 'The SHA256 hash of the key secret.';
+
+COMMENT ON COLUMN provisioner_keys.tags IS 'The tags daemons using the key are given. Daemons can''t claim tags outside of this set.';
@@ -0,0 +1,7 @@
+-- It's not possible to drop enum values from enum types, so the UP has "IF NOT
+-- EXISTS".
+ALTER TYPE audit_action
+  ADD VALUE IF NOT EXISTS 'connect';
+
+ALTER TYPE audit_action
+  ADD VALUE IF NOT EXISTS 'disconnect';
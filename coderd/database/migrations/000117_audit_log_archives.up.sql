@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS audit_log_archives (
+	id uuid NOT NULL,
+	created_at timestamp with time zone NOT NULL,
+	oldest timestamp with time zone NOT NULL,
+	newest timestamp with time zone NOT NULL,
+	log_count integer NOT NULL,
+	storage text NOT NULL,
+	hash character varying(64) NOT NULL,
+	size bigint NOT NULL,
+	PRIMARY KEY (id)
+);
+
+COMMENT ON COLUMN audit_log_archives.oldest IS 'The time of the oldest audit log in the archive.';
+
+COMMENT ON COLUMN audit_log_archives.newest IS 'The time of the newest audit log in the archive.';
+
+COMMENT ON COLUMN audit_log_archives.hash IS 'The SHA256 hash of the gzipped JSON lines of the audit logs, which the archive is stored by in the file store.';
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_archives_oldest_newest ON audit_log_archives USING btree (oldest, newest);
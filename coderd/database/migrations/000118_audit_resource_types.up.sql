@@ -0,0 +1,7 @@
+-- It's not possible to drop enum values from enum types, so the UP has "IF NOT
+-- EXISTS".
+ALTER TYPE resource_type
+  ADD VALUE IF NOT EXISTS 'organization_member';
+
+ALTER TYPE resource_type
+  ADD VALUE IF NOT EXISTS 'appearance';
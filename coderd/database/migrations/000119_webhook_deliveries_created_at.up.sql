@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_created_at ON webhook_deliveries USING btree (created_at);
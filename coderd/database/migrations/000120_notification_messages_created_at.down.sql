@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_notification_messages_created_at;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_notification_messages_created_at ON notification_messages USING btree (created_at);
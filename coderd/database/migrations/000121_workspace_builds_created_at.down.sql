@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_workspace_builds_created_at;
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_workspace_builds_created_at ON workspace_builds USING btree (created_at);
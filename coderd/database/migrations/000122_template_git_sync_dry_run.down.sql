@@ -0,0 +1 @@
+ALTER TABLE template_git_syncs DROP COLUMN IF EXISTS dry_run_job_id;
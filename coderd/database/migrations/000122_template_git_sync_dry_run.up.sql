@@ -0,0 +1,3 @@
+ALTER TABLE template_git_syncs ADD COLUMN dry_run_job_id uuid REFERENCES provisioner_jobs (id) ON DELETE SET NULL;
+
+COMMENT ON COLUMN template_git_syncs.dry_run_job_id IS 'The dry-run of the version created for the last commit, with the default parameters. The version is only promoted if it succeeds.';
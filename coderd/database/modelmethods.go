@@ -4,6 +4,8 @@ import (
 	"sort"
 	"strconv"
 
+	"github.com/google/uuid"
+
 	"github.com/coder/coder/coderd/rbac"
 )
 
@@ -61,6 +63,31 @@ func (g Group) Auditable(users []User) AuditableGroup {
 	}
 }
 
+// AuditableOrganizationMember is an organization membership as it is
+// recorded in audit logs. The username is kept alongside the membership so
+// the log has a readable target.
+type AuditableOrganizationMember struct {
+	OrganizationMember
+	Username string `json:"username"`
+}
+
+// Auditable returns an object that can be used in audit logs.
+func (m OrganizationMember) Auditable(username string) AuditableOrganizationMember {
+	return AuditableOrganizationMember{
+		OrganizationMember: m,
+		Username:           username,
+	}
+}
+
+// AuditableAppearance is the deployment-wide appearance configuration as it
+// is recorded in audit logs. There is only one per deployment, so it is
+// identified by the deployment ID.
+type AuditableAppearance struct {
+	DeploymentID  uuid.UUID `json:"deployment_id"`
+	LogoURL       string    `json:"logo_url"`
+	ServiceBanner string    `json:"service_banner"`
+}
+
 const AllUsersGroup = "Everyone"
 
 func (s APIKeyScope) ToRBAC() rbac.ScopeName {
@@ -102,6 +129,15 @@ func (g Group) RBACObject() rbac.Object {
 }
 
 func (w Workspace) RBACObject() rbac.Object {
+	return w.RBACObjectNoACL().
+		WithACLUserList(w.UserACL).
+		WithGroupACL(w.GroupACL)
+}
+
+// RBACObjectNoACL ignores the users and groups the workspace is shared
+// with. It is used for actions only the owner and admins may perform, like
+// sharing the workspace.
+func (w Workspace) RBACObjectNoACL() rbac.Object {
 	return rbac.ResourceWorkspace.WithID(w.ID).
 		InOrg(w.OrganizationID).
 		WithOwner(w.OwnerID.String())
@@ -111,14 +147,28 @@ func (w Workspace) ExecutionRBAC() rbac.Object {
 	return rbac.ResourceWorkspaceExecution.
 		WithID(w.ID).
 		InOrg(w.OrganizationID).
-		WithOwner(w.OwnerID.String())
+		WithOwner(w.OwnerID.String()).
+		WithACLUserList(workspaceConnectACL(w.UserACL)).
+		WithGroupACL(workspaceConnectACL(w.GroupACL))
 }
 
 func (w Workspace) ApplicationConnectRBAC() rbac.Object {
 	return rbac.ResourceWorkspaceApplicationConnect.
 		WithID(w.ID).
 		InOrg(w.OrganizationID).
-		WithOwner(w.OwnerID.String())
+		WithOwner(w.OwnerID.String()).
+		WithACLUserList(workspaceConnectACL(w.UserACL)).
+		WithGroupACL(workspaceConnectACL(w.GroupACL))
+}
+
+// workspaceConnectACL allows everyone the workspace is shared with to use
+// its terminal, SSH, port-forwarding and apps, whatever their role.
+func workspaceConnectACL(acl WorkspaceACL) map[string][]rbac.Action {
+	connect := make(map[string][]rbac.Action, len(acl))
+	for id := range acl {
+		connect[id] = []rbac.Action{rbac.ActionCreate}
+	}
+	return connect
 }
 
 func (m OrganizationMember) RBACObject() rbac.Object {
@@ -179,10 +229,28 @@ func (u UserLink) RBACObject() rbac.Object {
 	return rbac.ResourceUserData.WithOwner(u.UserID.String()).WithID(u.UserID)
 }
 
+func (p NotificationPreference) RBACObject() rbac.Object {
+	return rbac.ResourceUserData.WithID(p.UserID).WithOwner(p.UserID.String())
+}
+
 func (l License) RBACObject() rbac.Object {
 	return rbac.ResourceLicense.WithIDString(strconv.FormatInt(int64(l.ID), 10))
 }
 
+// RBACObject returns the RBAC object for the webhook. Deployment-wide
+// webhooks are not in an organization, so only site-wide roles can manage them.
+func (w Webhook) RBACObject() rbac.Object {
+	obj := rbac.ResourceWebhook.WithID(w.ID)
+	if w.OrganizationID.Valid {
+		obj = obj.InOrg(w.OrganizationID.UUID)
+	}
+	return obj
+}
+
+func (k ProvisionerKey) RBACObject() rbac.Object {
+	return rbac.ResourceProvisionerKey.WithID(k.ID).InOrg(k.OrganizationID)
+}
+
 func ConvertUserRows(rows []GetUsersRow) []User {
 	users := make([]User, len(rows))
 	for i, r := range rows {
@@ -220,6 +288,9 @@ func ConvertWorkspaceRows(rows []GetWorkspacesRow) []Workspace {
 			AutostartSchedule: r.AutostartSchedule,
 			Ttl:               r.Ttl,
 			LastUsedAt:        r.LastUsedAt,
+			UserACL:           r.UserACL,
+			GroupACL:          r.GroupACL,
+			Labels:            r.Labels,
 		}
 	}
 
@@ -2,7 +2,10 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
@@ -52,6 +55,15 @@ func (q *sqlQuerier) GetAuthorizedTemplates(ctx context.Context, arg GetTemplate
 		arg.OrganizationID,
 		arg.ExactName,
 		pq.Array(arg.IDs),
+		arg.FuzzyName,
+		arg.Provisioner,
+		arg.CreatedBy,
+		arg.CreatedByUsername,
+		arg.HasActiveWorkspaces,
+		arg.Deprecated,
+		arg.Search,
+		arg.OffsetOpt,
+		arg.LimitOpt,
 	)
 	if err != nil {
 		return nil, err
@@ -77,6 +89,7 @@ func (q *sqlQuerier) GetAuthorizedTemplates(ctx context.Context, arg GetTemplate
 			&i.GroupACL,
 			&i.DisplayName,
 			&i.AllowUserCancelWorkspaceJobs,
+			&i.Deprecated,
 		); err != nil {
 			return nil, err
 		}
@@ -177,13 +190,16 @@ func (q *sqlQuerier) GetTemplateGroupRoles(ctx context.Context, id uuid.UUID) ([
 
 type workspaceQuerier interface {
 	GetAuthorizedWorkspaces(ctx context.Context, arg GetWorkspacesParams, prepared rbac.PreparedAuthorized) ([]GetWorkspacesRow, error)
+	GetAuthorizedWorkspacesUnion(ctx context.Context, args []GetWorkspacesParams, prepared rbac.PreparedAuthorized) ([]GetWorkspacesRow, error)
+	GetWorkspaceGroupRoles(ctx context.Context, id uuid.UUID) ([]WorkspaceGroup, error)
+	GetWorkspaceUserRoles(ctx context.Context, id uuid.UUID) ([]WorkspaceUser, error)
 }
 
 // GetAuthorizedWorkspaces returns all workspaces that the user is authorized to access.
 // This code is copied from `GetWorkspaces` and adds the authorized filter WHERE
 // clause.
 func (q *sqlQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg GetWorkspacesParams, prepared rbac.PreparedAuthorized) ([]GetWorkspacesRow, error) {
-	authorizedFilter, err := prepared.CompileToSQL(ctx, rbac.ConfigWithoutACL())
+	authorizedFilter, err := prepared.CompileToSQL(ctx, rbac.ConfigWithACL())
 	if err != nil {
 		return nil, xerrors.Errorf("compile authorized filter: %w", err)
 	}
@@ -197,22 +213,111 @@ func (q *sqlQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg GetWorkspa
 
 	// The name comment is for metric tracking
 	query := fmt.Sprintf("-- name: GetAuthorizedWorkspaces :many\n%s", filtered)
-	rows, err := q.db.QueryContext(ctx, query,
+	rows, err := q.db.QueryContext(ctx, query, append(workspaceFilterArgs(arg), arg.Offset, arg.Limit)...)
+	if err != nil {
+		return nil, xerrors.Errorf("get authorized workspaces: %w", err)
+	}
+	return scanWorkspaceRows(rows)
+}
+
+// workspaceFilterParams is the number of parameters of GetWorkspaces before
+// the offset and limit.
+const workspaceFilterParams = 16
+
+var queryParamRegex = regexp.MustCompile(`\$(\d+)`)
+
+// GetAuthorizedWorkspacesUnion returns the workspaces that the user is
+// authorized to access and that match any of the filters. The WHERE clause of
+// `GetWorkspaces` is repeated for every filter and joined with OR, so the
+// workspaces are counted, ordered and paginated like GetAuthorizedWorkspaces.
+// The offset and limit of the first filter are used.
+func (q *sqlQuerier) GetAuthorizedWorkspacesUnion(ctx context.Context, args []GetWorkspacesParams, prepared rbac.PreparedAuthorized) ([]GetWorkspacesRow, error) {
+	if len(args) == 0 {
+		return nil, xerrors.New("at least one filter is required")
+	}
+	authorizedFilter, err := prepared.CompileToSQL(ctx, rbac.ConfigWithACL())
+	if err != nil {
+		return nil, xerrors.Errorf("compile authorized filter: %w", err)
+	}
+
+	query, err := workspacesUnionQuery(len(args), authorizedFilter)
+	if err != nil {
+		return nil, err
+	}
+	queryArgs := make([]interface{}, 0, len(args)*workspaceFilterParams+2)
+	for _, arg := range args {
+		queryArgs = append(queryArgs, workspaceFilterArgs(arg)...)
+	}
+	queryArgs = append(queryArgs, args[0].Offset, args[0].Limit)
+
+	rows, err := q.db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, xerrors.Errorf("get authorized workspaces union: %w", err)
+	}
+	return scanWorkspaceRows(rows)
+}
+
+// workspacesUnionQuery returns the GetWorkspaces query with its WHERE clause
+// repeated for every filter. The parameters of the nth filter are shifted by
+// n*workspaceFilterParams, and the offset and limit follow the last filter.
+func workspacesUnionQuery(filters int, authorizedFilter string) (string, error) {
+	const whereStartMarker = ") latest_build ON TRUE\nWHERE\n"
+	whereStart := strings.Index(getWorkspaces, whereStartMarker)
+	whereEnd := strings.Index(getWorkspaces, authorizedQueryPlaceholder)
+	if whereStart < 0 || whereEnd < 0 {
+		return "", xerrors.New("unexpected GetWorkspaces query")
+	}
+	whereStart += len(whereStartMarker)
+	renumber := func(clause string, offset int) string {
+		return queryParamRegex.ReplaceAllStringFunc(clause, func(param string) string {
+			n, _ := strconv.Atoi(param[1:])
+			return "$" + strconv.Itoa(n+offset)
+		})
+	}
+
+	var query strings.Builder
+	_, _ = query.WriteString("-- name: GetAuthorizedWorkspacesUnion :many\n")
+	_, _ = query.WriteString(getWorkspaces[:whereStart])
+	_, _ = query.WriteString("(\n")
+	for i := 0; i < filters; i++ {
+		if i > 0 {
+			_, _ = query.WriteString("\tOR ")
+		}
+		// The clause ends with comments, so parentheses go on their own line.
+		_, _ = query.WriteString("(\n")
+		_, _ = query.WriteString(renumber(getWorkspaces[whereStart:whereEnd], i*workspaceFilterParams))
+		_, _ = query.WriteString("\n\t)\n")
+	}
+	_, _ = fmt.Fprintf(&query, ")\n\tAND %s\n", authorizedFilter)
+	tail := getWorkspaces[whereEnd+len(authorizedQueryPlaceholder):]
+	_, _ = query.WriteString(renumber(tail, (filters-1)*workspaceFilterParams))
+	return query.String(), nil
+}
+
+// workspaceFilterArgs returns the arguments of GetWorkspaces before the
+// offset and limit.
+func workspaceFilterArgs(arg GetWorkspacesParams) []interface{} {
+	return []interface{}{
 		arg.Deleted,
 		arg.Status,
+		pq.Array(arg.NegatedFilters),
 		arg.OwnerID,
 		arg.OwnerUsername,
 		arg.TemplateName,
 		pq.Array(arg.TemplateIds),
 		arg.Name,
+		arg.Labels,
 		arg.HasAgent,
 		arg.AgentInactiveDisconnectTimeoutSeconds,
-		arg.Offset,
-		arg.Limit,
-	)
-	if err != nil {
-		return nil, xerrors.Errorf("get authorized workspaces: %w", err)
+		arg.BuildReason,
+		arg.Outdated,
+		arg.HasFailedBuild,
+		arg.LastUsedBefore,
+		arg.LastUsedAfter,
 	}
+}
+
+func scanWorkspaceRows(rows *sql.Rows) ([]GetWorkspacesRow, error) {
 	defer rows.Close()
 	var items []GetWorkspacesRow
 	for rows.Next() {
@@ -229,6 +334,9 @@ func (q *sqlQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg GetWorkspa
 			&i.AutostartSchedule,
 			&i.Ttl,
 			&i.LastUsedAt,
+			&i.UserACL,
+			&i.GroupACL,
+			&i.Labels,
 			&i.Count,
 		); err != nil {
 			return nil, err
@@ -244,6 +352,90 @@ func (q *sqlQuerier) GetAuthorizedWorkspaces(ctx context.Context, arg GetWorkspa
 	return items, nil
 }
 
+type WorkspaceUser struct {
+	User
+	Actions Actions `db:"actions"`
+}
+
+func (q *sqlQuerier) GetWorkspaceUserRoles(ctx context.Context, id uuid.UUID) ([]WorkspaceUser, error) {
+	const query = `
+	SELECT
+		perms.value as actions, users.*
+	FROM
+		users
+	JOIN
+		(
+			SELECT
+				*
+			FROM
+				jsonb_each_text(
+					(
+						SELECT
+							workspaces.user_acl
+						FROM
+							workspaces
+						WHERE
+							id = $1
+					)
+				)
+		) AS perms
+	ON
+		users.id::text = perms.key
+	WHERE
+		users.deleted = false
+	AND
+		users.status = 'active';
+	`
+
+	var wus []WorkspaceUser
+	err := q.db.SelectContext(ctx, &wus, query, id.String())
+	if err != nil {
+		return nil, xerrors.Errorf("select user actions: %w", err)
+	}
+
+	return wus, nil
+}
+
+type WorkspaceGroup struct {
+	Group
+	Actions Actions `db:"actions"`
+}
+
+func (q *sqlQuerier) GetWorkspaceGroupRoles(ctx context.Context, id uuid.UUID) ([]WorkspaceGroup, error) {
+	const query = `
+	SELECT
+		perms.value as actions, groups.*
+	FROM
+		groups
+	JOIN
+		(
+			SELECT
+				*
+			FROM
+				jsonb_each_text(
+					(
+						SELECT
+							workspaces.group_acl
+						FROM
+							workspaces
+						WHERE
+							id = $1
+					)
+				)
+		) AS perms
+	ON
+		groups.id::text = perms.key;
+	`
+
+	var wgs []WorkspaceGroup
+	err := q.db.SelectContext(ctx, &wgs, query, id.String())
+	if err != nil {
+		return nil, xerrors.Errorf("select group roles: %w", err)
+	}
+
+	return wgs, nil
+}
+
 type userQuerier interface {
 	GetAuthorizedUserCount(ctx context.Context, arg GetFilteredUserCountParams, prepared rbac.PreparedAuthorized) (int64, error)
 }
@@ -13,3 +13,20 @@ func TestIsAuthorizedQuery(t *testing.T) {
 	_, err := insertAuthorizedFilter(query, "")
 	require.ErrorContains(t, err, "does not contain authorized replace string", "ensure replace string")
 }
+
+func TestWorkspacesUnionQuery(t *testing.T) {
+	t.Parallel()
+
+	query, err := workspacesUnionQuery(2, "true")
+	require.NoError(t, err)
+	require.NotContains(t, query, authorizedQueryPlaceholder)
+	// Both filters have their own parameters, and the offset and limit
+	// follow them.
+	params := make(map[string]bool)
+	for _, param := range queryParamRegex.FindAllString(query, -1) {
+		params[param] = true
+	}
+	require.Len(t, params, 2*workspaceFilterParams+2)
+	require.True(t, params["$34"])
+	require.Regexp(t, `OFFSET\s+\$33\s*$`, query)
+}
@@ -139,13 +139,15 @@ func AllAppSharingLevelValues() []AppSharingLevel {
 type AuditAction string
 
 const (
-	AuditActionCreate AuditAction = "create"
-	AuditActionWrite  AuditAction = "write"
-	AuditActionDelete AuditAction = "delete"
-	AuditActionStart  AuditAction = "start"
-	AuditActionStop   AuditAction = "stop"
-	AuditActionLogin  AuditAction = "login"
-	AuditActionLogout AuditAction = "logout"
+	AuditActionCreate     AuditAction = "create"
+	AuditActionWrite      AuditAction = "write"
+	AuditActionDelete     AuditAction = "delete"
+	AuditActionStart      AuditAction = "start"
+	AuditActionStop       AuditAction = "stop"
+	AuditActionLogin      AuditAction = "login"
+	AuditActionLogout     AuditAction = "logout"
+	AuditActionConnect    AuditAction = "connect"
+	AuditActionDisconnect AuditAction = "disconnect"
 )
 
 func (e *AuditAction) Scan(src interface{}) error {
@@ -191,7 +193,9 @@ func (e AuditAction) Valid() bool {
 		AuditActionStart,
 		AuditActionStop,
 		AuditActionLogin,
-		AuditActionLogout:
+		AuditActionLogout,
+		AuditActionConnect,
+		AuditActionDisconnect:
 		return true
 	}
 	return false
@@ -206,6 +210,8 @@ func AllAuditActionValues() []AuditAction {
 		AuditActionStop,
 		AuditActionLogin,
 		AuditActionLogout,
+		AuditActionConnect,
+		AuditActionDisconnect,
 	}
 }
 
@@ -697,6 +703,67 @@ func AllParameterTypeSystemValues() []ParameterTypeSystem {
 	}
 }
 
+type ProvisionerJobPriority string
+
+const (
+	ProvisionerJobPriorityInteractive ProvisionerJobPriority = "interactive"
+	ProvisionerJobPriorityAutomatic   ProvisionerJobPriority = "automatic"
+	ProvisionerJobPriorityBackground  ProvisionerJobPriority = "background"
+)
+
+func (e *ProvisionerJobPriority) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ProvisionerJobPriority(s)
+	case string:
+		*e = ProvisionerJobPriority(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ProvisionerJobPriority: %T", src)
+	}
+	return nil
+}
+
+type NullProvisionerJobPriority struct {
+	ProvisionerJobPriority ProvisionerJobPriority
+	Valid                  bool // Valid is true if ProvisionerJobPriority is not NULL
+}
+
+// Scan implements the Scanner interface.
+func (ns *NullProvisionerJobPriority) Scan(value interface{}) error {
+	if value == nil {
+		ns.ProvisionerJobPriority, ns.Valid = "", false
+		return nil
+	}
+	ns.Valid = true
+	return ns.ProvisionerJobPriority.Scan(value)
+}
+
+// Value implements the driver Valuer interface.
+func (ns NullProvisionerJobPriority) Value() (driver.Value, error) {
+	if !ns.Valid {
+		return nil, nil
+	}
+	return ns.ProvisionerJobPriority, nil
+}
+
+func (e ProvisionerJobPriority) Valid() bool {
+	switch e {
+	case ProvisionerJobPriorityInteractive,
+		ProvisionerJobPriorityAutomatic,
+		ProvisionerJobPriorityBackground:
+		return true
+	}
+	return false
+}
+
+func AllProvisionerJobPriorityValues() []ProvisionerJobPriority {
+	return []ProvisionerJobPriority{
+		ProvisionerJobPriorityInteractive,
+		ProvisionerJobPriorityAutomatic,
+		ProvisionerJobPriorityBackground,
+	}
+}
+
 type ProvisionerJobType string
 
 const (
@@ -874,16 +941,18 @@ func AllProvisionerTypeValues() []ProvisionerType {
 type ResourceType string
 
 const (
-	ResourceTypeOrganization    ResourceType = "organization"
-	ResourceTypeTemplate        ResourceType = "template"
-	ResourceTypeTemplateVersion ResourceType = "template_version"
-	ResourceTypeUser            ResourceType = "user"
-	ResourceTypeWorkspace       ResourceType = "workspace"
-	ResourceTypeGitSshKey       ResourceType = "git_ssh_key"
-	ResourceTypeApiKey          ResourceType = "api_key"
-	ResourceTypeGroup           ResourceType = "group"
-	ResourceTypeWorkspaceBuild  ResourceType = "workspace_build"
-	ResourceTypeLicense         ResourceType = "license"
+	ResourceTypeOrganization       ResourceType = "organization"
+	ResourceTypeTemplate           ResourceType = "template"
+	ResourceTypeTemplateVersion    ResourceType = "template_version"
+	ResourceTypeUser               ResourceType = "user"
+	ResourceTypeWorkspace          ResourceType = "workspace"
+	ResourceTypeGitSshKey          ResourceType = "git_ssh_key"
+	ResourceTypeApiKey             ResourceType = "api_key"
+	ResourceTypeGroup              ResourceType = "group"
+	ResourceTypeWorkspaceBuild     ResourceType = "workspace_build"
+	ResourceTypeLicense            ResourceType = "license"
+	ResourceTypeOrganizationMember ResourceType = "organization_member"
+	ResourceTypeAppearance         ResourceType = "appearance"
 )
 
 func (e *ResourceType) Scan(src interface{}) error {
@@ -932,7 +1001,9 @@ func (e ResourceType) Valid() bool {
 		ResourceTypeApiKey,
 		ResourceTypeGroup,
 		ResourceTypeWorkspaceBuild,
-		ResourceTypeLicense:
+		ResourceTypeLicense,
+		ResourceTypeOrganizationMember,
+		ResourceTypeAppearance:
 		return true
 	}
 	return false
@@ -950,6 +1021,8 @@ func AllResourceTypeValues() []ResourceType {
 		ResourceTypeGroup,
 		ResourceTypeWorkspaceBuild,
 		ResourceTypeLicense,
+		ResourceTypeOrganizationMember,
+		ResourceTypeAppearance,
 	}
 }
 
@@ -1218,6 +1291,20 @@ type APIKey struct {
 	Scope           APIKeyScope `db:"scope" json:"scope"`
 }
 
+type AuditLogArchive struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	// The time of the oldest audit log in the archive.
+	Oldest time.Time `db:"oldest" json:"oldest"`
+	// The time of the newest audit log in the archive.
+	Newest   time.Time `db:"newest" json:"newest"`
+	LogCount int32     `db:"log_count" json:"log_count"`
+	Storage  string    `db:"storage" json:"storage"`
+	// The SHA256 hash of the gzipped JSON lines of the audit logs, which the archive is stored by in the file store.
+	Hash string `db:"hash" json:"hash"`
+	Size int64  `db:"size" json:"size"`
+}
+
 type AuditLog struct {
 	ID               uuid.UUID       `db:"id" json:"id"`
 	Time             time.Time       `db:"time" json:"time"`
@@ -1243,6 +1330,8 @@ type File struct {
 	Mimetype  string    `db:"mimetype" json:"mimetype"`
 	Data      []byte    `db:"data" json:"data"`
 	ID        uuid.UUID `db:"id" json:"id"`
+	// The backend the contents of the file are stored in: database, fs or s3. Contents stored in other backends are keyed by the hash of the file.
+	Storage string `db:"storage" json:"storage"`
 }
 
 type GitAuthLink struct {
@@ -1285,6 +1374,22 @@ type License struct {
 	UUID uuid.UUID `db:"uuid" json:"uuid"`
 }
 
+type NotificationMessage struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	Kind      string    `db:"kind" json:"kind"`
+	DedupeKey string    `db:"dedupe_key" json:"dedupe_key"`
+	Subject   string    `db:"subject" json:"subject"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+type NotificationPreference struct {
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	Kind      string    `db:"kind" json:"kind"`
+	Disabled  bool      `db:"disabled" json:"disabled"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
 type Organization struct {
 	ID          uuid.UUID `db:"id" json:"id"`
 	Name        string    `db:"name" json:"name"`
@@ -1341,6 +1446,10 @@ type ProvisionerDaemon struct {
 	Provisioners []ProvisionerType `db:"provisioners" json:"provisioners"`
 	ReplicaID    uuid.NullUUID     `db:"replica_id" json:"replica_id"`
 	Tags         dbtype.StringMap  `db:"tags" json:"tags"`
+	// When the daemon last polled for or updated a job. Daemons that haven't been seen recently are offline.
+	LastSeenAt sql.NullTime `db:"last_seen_at" json:"last_seen_at"`
+	// The version of Coder the daemon runs.
+	Version string `db:"version" json:"version"`
 }
 
 type ProvisionerJob struct {
@@ -1360,6 +1469,10 @@ type ProvisionerJob struct {
 	WorkerID       uuid.NullUUID            `db:"worker_id" json:"worker_id"`
 	FileID         uuid.UUID                `db:"file_id" json:"file_id"`
 	Tags           dbtype.StringMap         `db:"tags" json:"tags"`
+	// Jobs are acquired by priority: interactive jobs started by users first, then automatic jobs such as autostart and autostop, then background jobs such as bulk builds and template imports.
+	Priority ProvisionerJobPriority `db:"priority" json:"priority"`
+	// When the provisioner daemon running the job last sent an update. Running jobs without a recent heartbeat are marked as failed.
+	LastHeartbeatAt sql.NullTime `db:"last_heartbeat_at" json:"last_heartbeat_at"`
 }
 
 type ProvisionerJobLog struct {
@@ -1372,6 +1485,17 @@ type ProvisionerJobLog struct {
 	ID        int64     `db:"id" json:"id"`
 }
 
+type ProvisionerKey struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Name           string    `db:"name" json:"name"`
+	// The SHA256 hash of the key secret.
+	HashedSecret []byte `db:"hashed_secret" json:"hashed_secret"`
+	// The tags daemons using the key are given. Daemons can't claim tags outside of this set.
+	Tags dbtype.StringMap `db:"tags" json:"tags"`
+}
+
 type Replica struct {
 	ID              uuid.UUID    `db:"id" json:"id"`
 	CreatedAt       time.Time    `db:"created_at" json:"created_at"`
@@ -1411,6 +1535,30 @@ type Template struct {
 	DisplayName string `db:"display_name" json:"display_name"`
 	// Allow users to cancel in-progress workspace jobs.
 	AllowUserCancelWorkspaceJobs bool `db:"allow_user_cancel_workspace_jobs" json:"allow_user_cancel_workspace_jobs"`
+	// If set to a non empty string, the template will no longer be able to be used. The message will be displayed to the user.
+	Deprecated string `db:"deprecated" json:"deprecated"`
+}
+
+type TemplateGitSync struct {
+	TemplateID   uuid.UUID `db:"template_id" json:"template_id"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+	UserID       uuid.UUID `db:"user_id" json:"user_id"`
+	GitURL       string    `db:"git_url" json:"git_url"`
+	GitBranch    string    `db:"git_branch" json:"git_branch"`
+	GitDirectory string    `db:"git_directory" json:"git_directory"`
+	// The secret push webhooks are verified with.
+	WebhookSecret string `db:"webhook_secret" json:"webhook_secret"`
+	// The last commit of the branch a template version was created for.
+	CommitSHA         string        `db:"commit_sha" json:"commit_sha"`
+	TemplateVersionID uuid.NullUUID `db:"template_version_id" json:"template_version_id"`
+	// The status of the version created for the last commit: pending, succeeded or failed.
+	Status string `db:"status" json:"status"`
+	// The error of the last sync, empty if it succeeded.
+	Error     string       `db:"error" json:"error"`
+	CheckedAt sql.NullTime `db:"checked_at" json:"checked_at"`
+	// The dry-run of the version created for the last commit, with the default parameters. The version is only promoted if it succeeds.
+	DryRunJobID uuid.NullUUID `db:"dry_run_job_id" json:"dry_run_job_id"`
 }
 
 type TemplateVersion struct {
@@ -1425,6 +1573,13 @@ type TemplateVersion struct {
 	CreatedBy      uuid.UUID     `db:"created_by" json:"created_by"`
 	// IDs of Git auth providers for a specific template version
 	GitAuthProviders []string `db:"git_auth_providers" json:"git_auth_providers"`
+	Archived         bool     `db:"archived" json:"archived"`
+	// The git repository the version was created from, if any
+	GitURL       string `db:"git_url" json:"git_url"`
+	GitRef       string `db:"git_ref" json:"git_ref"`
+	GitDirectory string `db:"git_directory" json:"git_directory"`
+	// The commit the ref resolved to when the version was created
+	GitCommitSHA string `db:"git_commit_sha" json:"git_commit_sha"`
 }
 
 type TemplateVersionParameter struct {
@@ -1497,18 +1652,49 @@ type UserLink struct {
 	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
 }
 
+type Webhook struct {
+	ID             uuid.UUID     `db:"id" json:"id"`
+	OrganizationID uuid.NullUUID `db:"organization_id" json:"organization_id"`
+	CreatedBy      uuid.UUID     `db:"created_by" json:"created_by"`
+	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time     `db:"updated_at" json:"updated_at"`
+	Name           string        `db:"name" json:"name"`
+	Url            string        `db:"url" json:"url"`
+	// The secret used to sign delivery payloads with HMAC-SHA256.
+	Secret  string   `db:"secret" json:"secret"`
+	Events  []string `db:"events" json:"events"`
+	Enabled bool     `db:"enabled" json:"enabled"`
+}
+
+type WebhookDelivery struct {
+	ID           uuid.UUID       `db:"id" json:"id"`
+	WebhookID    uuid.UUID       `db:"webhook_id" json:"webhook_id"`
+	EventID      uuid.UUID       `db:"event_id" json:"event_id"`
+	Event        string          `db:"event" json:"event"`
+	Payload      json.RawMessage `db:"payload" json:"payload"`
+	Attempt      int32           `db:"attempt" json:"attempt"`
+	CreatedAt    time.Time       `db:"created_at" json:"created_at"`
+	DeliveredAt  sql.NullTime    `db:"delivered_at" json:"delivered_at"`
+	StatusCode   sql.NullInt32   `db:"status_code" json:"status_code"`
+	ResponseBody string          `db:"response_body" json:"response_body"`
+	Error        string          `db:"error" json:"error"`
+}
+
 type Workspace struct {
-	ID                uuid.UUID      `db:"id" json:"id"`
-	CreatedAt         time.Time      `db:"created_at" json:"created_at"`
-	UpdatedAt         time.Time      `db:"updated_at" json:"updated_at"`
-	OwnerID           uuid.UUID      `db:"owner_id" json:"owner_id"`
-	OrganizationID    uuid.UUID      `db:"organization_id" json:"organization_id"`
-	TemplateID        uuid.UUID      `db:"template_id" json:"template_id"`
-	Deleted           bool           `db:"deleted" json:"deleted"`
-	Name              string         `db:"name" json:"name"`
-	AutostartSchedule sql.NullString `db:"autostart_schedule" json:"autostart_schedule"`
-	Ttl               sql.NullInt64  `db:"ttl" json:"ttl"`
-	LastUsedAt        time.Time      `db:"last_used_at" json:"last_used_at"`
+	ID                uuid.UUID       `db:"id" json:"id"`
+	CreatedAt         time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time       `db:"updated_at" json:"updated_at"`
+	OwnerID           uuid.UUID       `db:"owner_id" json:"owner_id"`
+	OrganizationID    uuid.UUID       `db:"organization_id" json:"organization_id"`
+	TemplateID        uuid.UUID       `db:"template_id" json:"template_id"`
+	Deleted           bool            `db:"deleted" json:"deleted"`
+	Name              string          `db:"name" json:"name"`
+	AutostartSchedule sql.NullString  `db:"autostart_schedule" json:"autostart_schedule"`
+	Ttl               sql.NullInt64   `db:"ttl" json:"ttl"`
+	LastUsedAt        time.Time       `db:"last_used_at" json:"last_used_at"`
+	UserACL           WorkspaceACL    `db:"user_acl" json:"user_acl"`
+	GroupACL          WorkspaceACL    `db:"group_acl" json:"group_acl"`
+	Labels            WorkspaceLabels `db:"labels" json:"labels"`
 }
 
 type WorkspaceAgent struct {
@@ -1561,6 +1747,7 @@ type WorkspaceAgentStat struct {
 	RxBytes            int64           `db:"rx_bytes" json:"rx_bytes"`
 	TxPackets          int64           `db:"tx_packets" json:"tx_packets"`
 	TxBytes            int64           `db:"tx_bytes" json:"tx_bytes"`
+	ConnectionsByPort  json.RawMessage `db:"connections_by_port" json:"connections_by_port"`
 }
 
 type WorkspaceApp struct {
@@ -12,28 +12,89 @@ import (
 )
 
 type sqlcQuerier interface {
+	// Acquires a lock on the contents stored for a hash, which is released when
+	// the transaction ends. Files are inserted, and their contents deleted, while
+	// holding it, so contents aren't deleted while a file that refers to them is
+	// being inserted. The two key form doesn't share IDs with TryAcquireLock.
+	AcquireFileHashLock(ctx context.Context, hash string) error
 	// Acquires the lock for a single job that isn't started, completed,
 	// canceled, and that matches an array of provisioner types.
 	//
+	// Jobs are acquired by priority. Within a priority, jobs of the initiators and
+	// organizations with the fewest running jobs are acquired first, so a single
+	// user or organization can't starve the queue, and then the oldest jobs.
+	//
 	// SKIP LOCKED is used to jump over locked rows. This prevents
 	// multiple provisioners from acquiring the same jobs. See:
 	// https://www.postgresql.org/docs/9.5/sql-select.html#SQL-FOR-UPDATE-SHARE
 	AcquireProvisionerJob(ctx context.Context, arg AcquireProvisionerJobParams) (ProvisionerJob, error)
+	// Archives the versions of a template that no workspace uses, and returns the
+	// IDs of the versions that were archived. A version is in use while it is the
+	// active version of a template, or the version of the latest build of a
+	// workspace that hasn't been deleted. Versions that are still importing are
+	// never archived. Pass a template version ID to archive just that version.
+	ArchiveUnusedTemplateVersions(ctx context.Context, arg ArchiveUnusedTemplateVersionsParams) ([]uuid.UUID, error)
 	DeleteAPIKeyByID(ctx context.Context, id string) error
 	DeleteAPIKeysByUserID(ctx context.Context, userID uuid.UUID) error
+	// Deletes the logs of the import and dry-run jobs of archived template
+	// versions.
+	DeleteArchivedTemplateVersionJobLogs(ctx context.Context) error
+	DeleteAuditLogsByIDs(ctx context.Context, ids []uuid.UUID) error
 	DeleteGitSSHKey(ctx context.Context, userID uuid.UUID) error
 	DeleteGroupByID(ctx context.Context, id uuid.UUID) error
 	DeleteGroupMemberFromGroup(ctx context.Context, arg DeleteGroupMemberFromGroupParams) error
 	DeleteGroupMembersByOrgAndUser(ctx context.Context, arg DeleteGroupMembersByOrgAndUserParams) error
 	DeleteLicense(ctx context.Context, id int32) (int32, error)
+	// Releases the claim on a message that could not be sent, so it is
+	// retried.
+	DeleteNotificationMessageByID(ctx context.Context, id uuid.UUID) error
+	// Messages are only kept to dedupe notifications that several replicas
+	// decide to send at about the same time.
+	DeleteOldNotificationMessages(ctx context.Context, before time.Time) error
+	// Daemons are inserted every time they connect, so the daemons that haven't
+	// been seen for a while are deleted to keep the list of daemons readable.
+	DeleteOldProvisionerDaemons(ctx context.Context, before time.Time) error
+	// The delivery log is only kept for debugging recent deliveries.
+	DeleteOldWebhookDeliveries(ctx context.Context, before time.Time) error
 	DeleteOldWorkspaceAgentStats(ctx context.Context) error
+	// Deletes up to a limit of the logs of workspace build jobs that completed
+	// before the given time, except for the latest build of each workspace.
+	// Returns how many logs were deleted, so large backlogs are deleted in batches
+	// that don't hold a long transaction.
+	DeleteOldWorkspaceBuildJobLogs(ctx context.Context, arg DeleteOldWorkspaceBuildJobLogsParams) (int64, error)
 	DeleteParameterValueByID(ctx context.Context, id uuid.UUID) error
+	DeleteProvisionerKeyByID(ctx context.Context, id uuid.UUID) error
 	DeleteReplicasUpdatedBefore(ctx context.Context, updatedAt time.Time) error
+	DeleteTemplateGitSyncByTemplateID(ctx context.Context, templateID uuid.UUID) error
+	// Deletes the files created before the cutoff that no future build can use,
+	// and returns them so their contents can be deleted from the backend they're
+	// stored in. A file is needed while it's referenced by the import job of a
+	// template version that isn't archived, or by any provisioner job that hasn't
+	// completed. Files locked by LockFileByID are being used to unarchive a
+	// template version, so they're skipped.
+	DeleteUnusedFiles(ctx context.Context, createdBefore time.Time) ([]DeleteUnusedFilesRow, error)
+	DeleteWebhookByID(ctx context.Context, id uuid.UUID) error
 	GetAPIKeyByID(ctx context.Context, id string) (APIKey, error)
 	GetAPIKeysByLoginType(ctx context.Context, loginType LoginType) ([]APIKey, error)
 	GetAPIKeysByUserID(ctx context.Context, arg GetAPIKeysByUserIDParams) ([]APIKey, error)
 	GetAPIKeysLastUsedAfter(ctx context.Context, lastUsed time.Time) ([]APIKey, error)
 	GetActiveUserCount(ctx context.Context) (int64, error)
+	// GetAuditLogArchivesByTimeRange returns the archives that contain audit logs
+	// from `since` until before `until`.
+	GetAuditLogArchivesByTimeRange(ctx context.Context, arg GetAuditLogArchivesByTimeRangeParams) ([]AuditLogArchive, error)
+	// GetAuditLogsByTimeRange returns `row_limit` audit logs before `until`, in
+	// the order they happened, after the audit log at `after_time` with
+	// `after_id`. It's used to page through the audit logs of a time range for
+	// export.
+	GetAuditLogsByTimeRange(ctx context.Context, arg GetAuditLogsByTimeRangeParams) ([]AuditLog, error)
+	// GetAuditLogsForExport returns `row_limit` audit logs that match the filters,
+	// newest first, older than the audit log at `before_time` with `before_id`.
+	// It's used to page through the audit logs for export, so unlike
+	// GetAuditLogsOffset it doesn't count the matching audit logs.
+	GetAuditLogsForExport(ctx context.Context, arg GetAuditLogsForExportParams) ([]GetAuditLogsForExportRow, error)
+	// GetAuditLogsForPurge returns the `row_limit` oldest audit logs before
+	// `before`, locking them so replicas purging at the same time skip them.
+	GetAuditLogsForPurge(ctx context.Context, arg GetAuditLogsForPurgeParams) ([]AuditLog, error)
 	// GetAuditLogsBefore retrieves `row_limit` number of audit logs before the provided
 	// ID.
 	GetAuditLogsOffset(ctx context.Context, arg GetAuditLogsOffsetParams) ([]GetAuditLogsOffsetRow, error)
@@ -43,8 +104,18 @@ type sqlcQuerier interface {
 	GetDERPMeshKey(ctx context.Context) (string, error)
 	GetDeploymentDAUs(ctx context.Context) ([]GetDeploymentDAUsRow, error)
 	GetDeploymentID(ctx context.Context) (string, error)
+	GetDeploymentWebhooks(ctx context.Context) ([]Webhook, error)
+	// Returns the webhooks subscribed to the event. Deployment-wide webhooks
+	// receive events from every organization. Events about users are in every
+	// organization of the user, but each webhook is only returned once.
+	GetEnabledWebhooksByEvent(ctx context.Context, arg GetEnabledWebhooksByEventParams) ([]Webhook, error)
 	GetFileByHashAndCreator(ctx context.Context, arg GetFileByHashAndCreatorParams) (File, error)
 	GetFileByID(ctx context.Context, id uuid.UUID) (File, error)
+	// Returns whether any file has the hash, regardless of the backend its
+	// contents are stored in. Contents are only deleted from a backend once no
+	// file refers to them, so files that are being migrated keep their contents.
+	GetFileHashInUse(ctx context.Context, hash string) (bool, error)
+	GetFileIDsByStorage(ctx context.Context, storage string) ([]uuid.UUID, error)
 	// This will never count deleted users.
 	GetFilteredUserCount(ctx context.Context, arg GetFilteredUserCountParams) (int64, error)
 	GetGitAuthLink(ctx context.Context, arg GetGitAuthLinkParams) (GitAuthLink, error)
@@ -60,6 +131,7 @@ type sqlcQuerier interface {
 	GetLicenseByID(ctx context.Context, id int32) (License, error)
 	GetLicenses(ctx context.Context) ([]License, error)
 	GetLogoURL(ctx context.Context) (string, error)
+	GetNotificationPreferencesByUserID(ctx context.Context, userID uuid.UUID) ([]NotificationPreference, error)
 	GetOrganizationByID(ctx context.Context, id uuid.UUID) (Organization, error)
 	GetOrganizationByName(ctx context.Context, name string) (Organization, error)
 	GetOrganizationIDsByMemberIDs(ctx context.Context, ids []uuid.UUID) ([]GetOrganizationIDsByMemberIDsRow, error)
@@ -73,17 +145,49 @@ type sqlcQuerier interface {
 	GetPreviousTemplateVersion(ctx context.Context, arg GetPreviousTemplateVersionParams) (TemplateVersion, error)
 	GetProvisionerDaemons(ctx context.Context) ([]ProvisionerDaemon, error)
 	GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (ProvisionerJob, error)
+	// Returns the position of pending jobs in the queue of pending jobs with the
+	// same provisioner and tags, which are acquired by the same provisioner
+	// daemons. The position accounts for priorities but not for fairness between
+	// users and organizations, so it's an estimate. The running jobs and average
+	// duration of recent jobs in the queue are returned to estimate the wait.
+	GetProvisionerJobQueuePositionsByIDs(ctx context.Context, ids []uuid.UUID) ([]GetProvisionerJobQueuePositionsByIDsRow, error)
 	GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]ProvisionerJob, error)
 	GetProvisionerJobsCreatedAfter(ctx context.Context, createdAt time.Time) ([]ProvisionerJob, error)
+	GetProvisionerKeyByHashedSecret(ctx context.Context, hashedSecret []byte) (ProvisionerKey, error)
+	GetProvisionerKeyByID(ctx context.Context, id uuid.UUID) (ProvisionerKey, error)
+	GetProvisionerKeyByOrganizationIDAndName(ctx context.Context, arg GetProvisionerKeyByOrganizationIDAndNameParams) (ProvisionerKey, error)
+	GetProvisionerKeysByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]ProvisionerKey, error)
 	GetProvisionerLogsByIDBetween(ctx context.Context, arg GetProvisionerLogsByIDBetweenParams) ([]ProvisionerJobLog, error)
 	GetQuotaAllowanceForUser(ctx context.Context, userID uuid.UUID) (int64, error)
 	GetQuotaConsumedForUser(ctx context.Context, ownerID uuid.UUID) (int64, error)
 	GetReplicasUpdatedAfter(ctx context.Context, updatedAt time.Time) ([]Replica, error)
+	GetRunningProvisionerJobs(ctx context.Context) ([]ProvisionerJob, error)
 	GetServiceBanner(ctx context.Context) (string, error)
+	// Returns the number of workspaces of each template that reported agent stats
+	// on each day since the given time.
+	GetTemplateActiveWorkspaces(ctx context.Context, startTime time.Time) ([]GetTemplateActiveWorkspacesRow, error)
 	GetTemplateAverageBuildTime(ctx context.Context, arg GetTemplateAverageBuildTimeParams) (GetTemplateAverageBuildTimeRow, error)
+	// Returns the workspace builds of every template that ran to completion since
+	// the given time, with how long they took and whether they failed. Canceled
+	// builds are left out.
+	GetTemplateBuildInsights(ctx context.Context, startTime time.Time) ([]GetTemplateBuildInsightsRow, error)
 	GetTemplateByID(ctx context.Context, id uuid.UUID) (Template, error)
 	GetTemplateByOrganizationAndName(ctx context.Context, arg GetTemplateByOrganizationAndNameParams) (Template, error)
+	// Sums the connections reported by the agents of every template on each day
+	// since the given time, by the agent port they were made to.
+	GetTemplateConnectionInsights(ctx context.Context, startTime time.Time) ([]GetTemplateConnectionInsightsRow, error)
 	GetTemplateDAUs(ctx context.Context, templateID uuid.UUID) ([]GetTemplateDAUsRow, error)
+	GetTemplateGitSyncByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateGitSync, error)
+	// Returns the git syncs of templates that haven't been deleted.
+	GetTemplateGitSyncs(ctx context.Context) ([]TemplateGitSync, error)
+	GetTemplateGitSyncsByTemplateIDs(ctx context.Context, ids []uuid.UUID) ([]TemplateGitSync, error)
+	// Returns the apps served by the agents of every template's workspaces built
+	// since the given time, so connections can be matched to apps by port. Only
+	// the latest definition of each app slug is returned.
+	GetTemplateInsightsApps(ctx context.Context, startTime time.Time) ([]GetTemplateInsightsAppsRow, error)
+	// Returns how many non-deleted workspaces of every template have their latest
+	// build on each template version.
+	GetTemplateVersionAdoption(ctx context.Context) ([]GetTemplateVersionAdoptionRow, error)
 	GetTemplateVersionByID(ctx context.Context, id uuid.UUID) (TemplateVersion, error)
 	GetTemplateVersionByJobID(ctx context.Context, jobID uuid.UUID) (TemplateVersion, error)
 	GetTemplateVersionByTemplateIDAndName(ctx context.Context, arg GetTemplateVersionByTemplateIDAndNameParams) (TemplateVersion, error)
@@ -95,6 +199,11 @@ type sqlcQuerier interface {
 	GetTemplates(ctx context.Context) ([]Template, error)
 	GetTemplatesWithFilter(ctx context.Context, arg GetTemplatesWithFilterParams) ([]Template, error)
 	GetUnexpiredLicenses(ctx context.Context) ([]License, error)
+	// Returns the activity of every user between the given times. Connected time
+	// is counted in buckets of the agent stats report interval, so connections to
+	// several workspaces at once are only counted once. The cost accrued is the
+	// daily cost of each workspace prorated over the time it was running.
+	GetUserActivityInsights(ctx context.Context, arg GetUserActivityInsightsParams) ([]GetUserActivityInsightsRow, error)
 	GetUserByEmailOrUsername(ctx context.Context, arg GetUserByEmailOrUsernameParams) (User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (User, error)
 	GetUserCount(ctx context.Context) (int64, error)
@@ -106,6 +215,9 @@ type sqlcQuerier interface {
 	// to look up references to actions. eg. a user could build a workspace
 	// for another user, then be deleted... we still want them to appear!
 	GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]User, error)
+	GetWebhookByID(ctx context.Context, id uuid.UUID) (Webhook, error)
+	GetWebhookDeliveriesByWebhookID(ctx context.Context, arg GetWebhookDeliveriesByWebhookIDParams) ([]WebhookDelivery, error)
+	GetWebhooksByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]Webhook, error)
 	GetWorkspaceAgentByAuthToken(ctx context.Context, authToken uuid.UUID) (WorkspaceAgent, error)
 	GetWorkspaceAgentByID(ctx context.Context, id uuid.UUID) (WorkspaceAgent, error)
 	GetWorkspaceAgentByInstanceID(ctx context.Context, authInstanceID string) (WorkspaceAgent, error)
@@ -138,6 +250,7 @@ type sqlcQuerier interface {
 	// every member of the org.
 	InsertAllUsersGroup(ctx context.Context, organizationID uuid.UUID) (Group, error)
 	InsertAuditLog(ctx context.Context, arg InsertAuditLogParams) (AuditLog, error)
+	InsertAuditLogArchive(ctx context.Context, arg InsertAuditLogArchiveParams) (AuditLogArchive, error)
 	InsertDERPMeshKey(ctx context.Context, value string) error
 	InsertDeploymentID(ctx context.Context, value string) error
 	InsertFile(ctx context.Context, arg InsertFileParams) (File, error)
@@ -146,6 +259,9 @@ type sqlcQuerier interface {
 	InsertGroup(ctx context.Context, arg InsertGroupParams) (Group, error)
 	InsertGroupMember(ctx context.Context, arg InsertGroupMemberParams) error
 	InsertLicense(ctx context.Context, arg InsertLicenseParams) (License, error)
+	// Inserting a message claims it. If another replica has already claimed
+	// the dedupe key, no rows are returned.
+	InsertNotificationMessage(ctx context.Context, arg InsertNotificationMessageParams) (NotificationMessage, error)
 	InsertOrUpdateLastUpdateCheck(ctx context.Context, value string) error
 	InsertOrUpdateLogoURL(ctx context.Context, value string) error
 	InsertOrUpdateServiceBanner(ctx context.Context, value string) error
@@ -156,6 +272,7 @@ type sqlcQuerier interface {
 	InsertProvisionerDaemon(ctx context.Context, arg InsertProvisionerDaemonParams) (ProvisionerDaemon, error)
 	InsertProvisionerJob(ctx context.Context, arg InsertProvisionerJobParams) (ProvisionerJob, error)
 	InsertProvisionerJobLogs(ctx context.Context, arg InsertProvisionerJobLogsParams) ([]ProvisionerJobLog, error)
+	InsertProvisionerKey(ctx context.Context, arg InsertProvisionerKeyParams) (ProvisionerKey, error)
 	InsertReplica(ctx context.Context, arg InsertReplicaParams) (Replica, error)
 	InsertTemplate(ctx context.Context, arg InsertTemplateParams) (Template, error)
 	InsertTemplateVersion(ctx context.Context, arg InsertTemplateVersionParams) (TemplateVersion, error)
@@ -165,6 +282,10 @@ type sqlcQuerier interface {
 	// InsertUserGroupsByName adds a user to all provided groups, if they exist.
 	InsertUserGroupsByName(ctx context.Context, arg InsertUserGroupsByNameParams) error
 	InsertUserLink(ctx context.Context, arg InsertUserLinkParams) (UserLink, error)
+	InsertWebhook(ctx context.Context, arg InsertWebhookParams) (Webhook, error)
+	// Inserting a delivery claims the attempt. If another replica has already
+	// claimed it, no rows are returned.
+	InsertWebhookDelivery(ctx context.Context, arg InsertWebhookDeliveryParams) (WebhookDelivery, error)
 	InsertWorkspace(ctx context.Context, arg InsertWorkspaceParams) (Workspace, error)
 	InsertWorkspaceAgent(ctx context.Context, arg InsertWorkspaceAgentParams) (WorkspaceAgent, error)
 	InsertWorkspaceAgentStat(ctx context.Context, arg InsertWorkspaceAgentStatParams) (WorkspaceAgentStat, error)
@@ -173,13 +294,25 @@ type sqlcQuerier interface {
 	InsertWorkspaceBuildParameters(ctx context.Context, arg InsertWorkspaceBuildParametersParams) error
 	InsertWorkspaceResource(ctx context.Context, arg InsertWorkspaceResourceParams) (WorkspaceResource, error)
 	InsertWorkspaceResourceMetadata(ctx context.Context, arg InsertWorkspaceResourceMetadataParams) ([]WorkspaceResourceMetadatum, error)
+	// Locks a file until the end of the transaction, so it isn't deleted by
+	// DeleteUnusedFiles before the template version that uses it is unarchived.
+	LockFileByID(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
 	ParameterValue(ctx context.Context, id uuid.UUID) (ParameterValue, error)
 	ParameterValues(ctx context.Context, arg ParameterValuesParams) ([]ParameterValue, error)
+	// Acquires a lock that's released when the transaction ends, without
+	// blocking. Returns whether the lock was acquired. Replicas use it to
+	// ensure only one of them runs a background task at a time.
+	TryAcquireLock(ctx context.Context, pgTryAdvisoryXactLock int64) (bool, error)
+	UnarchiveTemplateVersion(ctx context.Context, arg UnarchiveTemplateVersionParams) error
 	UpdateAPIKeyByID(ctx context.Context, arg UpdateAPIKeyByIDParams) error
+	UpdateFileStorageByID(ctx context.Context, arg UpdateFileStorageByIDParams) error
 	UpdateGitAuthLink(ctx context.Context, arg UpdateGitAuthLinkParams) (GitAuthLink, error)
 	UpdateGitSSHKey(ctx context.Context, arg UpdateGitSSHKeyParams) (GitSSHKey, error)
 	UpdateGroupByID(ctx context.Context, arg UpdateGroupByIDParams) (Group, error)
 	UpdateMemberRoles(ctx context.Context, arg UpdateMemberRolesParams) (OrganizationMember, error)
+	UpdateProvisionerDaemonLastSeenAt(ctx context.Context, arg UpdateProvisionerDaemonLastSeenAtParams) error
+	// Records an update from the provisioner daemon running the job, which is
+	// the heartbeat of the job.
 	UpdateProvisionerJobByID(ctx context.Context, arg UpdateProvisionerJobByIDParams) error
 	UpdateProvisionerJobWithCancelByID(ctx context.Context, arg UpdateProvisionerJobWithCancelByIDParams) error
 	UpdateProvisionerJobWithCompleteByID(ctx context.Context, arg UpdateProvisionerJobWithCompleteByIDParams) error
@@ -187,6 +320,18 @@ type sqlcQuerier interface {
 	UpdateTemplateACLByID(ctx context.Context, arg UpdateTemplateACLByIDParams) (Template, error)
 	UpdateTemplateActiveVersionByID(ctx context.Context, arg UpdateTemplateActiveVersionByIDParams) error
 	UpdateTemplateDeletedByID(ctx context.Context, arg UpdateTemplateDeletedByIDParams) error
+	UpdateTemplateDeprecatedByID(ctx context.Context, arg UpdateTemplateDeprecatedByIDParams) (Template, error)
+	// Updating the commit claims it, so only one replica creates a version for
+	// it. If another replica has already claimed it, no rows are returned. A
+	// claim of the same commit without a version is stale once it was checked
+	// before the cutoff, e.g. because the replica that claimed it was stopped, and
+	// can be claimed again.
+	UpdateTemplateGitSyncCommitByTemplateID(ctx context.Context, arg UpdateTemplateGitSyncCommitByTemplateIDParams) (TemplateGitSync, error)
+	// Setting the dry-run job claims the dry-run of the version, so only one
+	// replica creates it. If another replica has already claimed it, or a version
+	// was created for a newer commit, no rows are returned.
+	UpdateTemplateGitSyncDryRunJobByTemplateID(ctx context.Context, arg UpdateTemplateGitSyncDryRunJobByTemplateIDParams) (TemplateGitSync, error)
+	UpdateTemplateGitSyncStatusByTemplateID(ctx context.Context, arg UpdateTemplateGitSyncStatusByTemplateIDParams) (TemplateGitSync, error)
 	UpdateTemplateMetaByID(ctx context.Context, arg UpdateTemplateMetaByIDParams) (Template, error)
 	UpdateTemplateVersionByID(ctx context.Context, arg UpdateTemplateVersionByIDParams) error
 	UpdateTemplateVersionDescriptionByJobID(ctx context.Context, arg UpdateTemplateVersionDescriptionByJobIDParams) error
@@ -199,7 +344,13 @@ type sqlcQuerier interface {
 	UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error)
 	UpdateUserRoles(ctx context.Context, arg UpdateUserRolesParams) (User, error)
 	UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (User, error)
+	UpdateWebhookByID(ctx context.Context, arg UpdateWebhookByIDParams) (Webhook, error)
+	UpdateWebhookDeliveryByID(ctx context.Context, arg UpdateWebhookDeliveryByIDParams) (WebhookDelivery, error)
 	UpdateWorkspace(ctx context.Context, arg UpdateWorkspaceParams) (Workspace, error)
+	UpdateWorkspaceACLByID(ctx context.Context, arg UpdateWorkspaceACLByIDParams) (Workspace, error)
+	// Invalidates the tokens of the agents of every build of the workspace, e.g.
+	// when it is transferred to another user.
+	UpdateWorkspaceAgentAuthTokensByWorkspaceID(ctx context.Context, arg UpdateWorkspaceAgentAuthTokensByWorkspaceIDParams) error
 	UpdateWorkspaceAgentConnectionByID(ctx context.Context, arg UpdateWorkspaceAgentConnectionByIDParams) error
 	UpdateWorkspaceAgentLifecycleStateByID(ctx context.Context, arg UpdateWorkspaceAgentLifecycleStateByIDParams) error
 	UpdateWorkspaceAgentStartupByID(ctx context.Context, arg UpdateWorkspaceAgentStartupByIDParams) error
@@ -208,8 +359,16 @@ type sqlcQuerier interface {
 	UpdateWorkspaceBuildByID(ctx context.Context, arg UpdateWorkspaceBuildByIDParams) (WorkspaceBuild, error)
 	UpdateWorkspaceBuildCostByID(ctx context.Context, arg UpdateWorkspaceBuildCostByIDParams) (WorkspaceBuild, error)
 	UpdateWorkspaceDeletedByID(ctx context.Context, arg UpdateWorkspaceDeletedByIDParams) error
+	UpdateWorkspaceLabelsByID(ctx context.Context, arg UpdateWorkspaceLabelsByIDParams) (Workspace, error)
 	UpdateWorkspaceLastUsedAt(ctx context.Context, arg UpdateWorkspaceLastUsedAtParams) error
+	// The workspace is no longer shared, the new owner decides who to share it
+	// with.
+	UpdateWorkspaceOwnerByID(ctx context.Context, arg UpdateWorkspaceOwnerByIDParams) (Workspace, error)
 	UpdateWorkspaceTTL(ctx context.Context, arg UpdateWorkspaceTTLParams) error
+	UpsertNotificationPreference(ctx context.Context, arg UpsertNotificationPreferenceParams) (NotificationPreference, error)
+	// Changing the repository of a sync resets it, so a version is created for
+	// the latest commit of the new branch. The webhook secret is kept.
+	UpsertTemplateGitSync(ctx context.Context, arg UpsertTemplateGitSyncParams) (TemplateGitSync, error)
 }
 
 var _ sqlcQuerier = (*sqlQuerier)(nil)
@@ -291,16 +291,195 @@ func (q *sqlQuerier) UpdateAPIKeyByID(ctx context.Context, arg UpdateAPIKeyByIDP
 	return err
 }
 
-const getAuditLogsOffset = `-- name: GetAuditLogsOffset :many
+const getAuditLogArchivesByTimeRange = `-- name: GetAuditLogArchivesByTimeRange :many
+SELECT
+	id, created_at, oldest, newest, log_count, storage, hash, size
+FROM
+	audit_log_archives
+WHERE
+	newest >= $1 :: timestamp with time zone
+	AND oldest < $2 :: timestamp with time zone
+ORDER BY
+	oldest, id
+`
+
+type GetAuditLogArchivesByTimeRangeParams struct {
+	Since time.Time `db:"since" json:"since"`
+	Until time.Time `db:"until" json:"until"`
+}
+
+// GetAuditLogArchivesByTimeRange returns the archives that contain audit logs
+// from `since` until before `until`.
+func (q *sqlQuerier) GetAuditLogArchivesByTimeRange(ctx context.Context, arg GetAuditLogArchivesByTimeRangeParams) ([]AuditLogArchive, error) {
+	rows, err := q.db.QueryContext(ctx, getAuditLogArchivesByTimeRange, arg.Since, arg.Until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLogArchive
+	for rows.Next() {
+		var i AuditLogArchive
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.Oldest,
+			&i.Newest,
+			&i.LogCount,
+			&i.Storage,
+			&i.Hash,
+			&i.Size,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertAuditLogArchive = `-- name: InsertAuditLogArchive :one
+INSERT INTO
+	audit_log_archives (
+		id,
+		created_at,
+		oldest,
+		newest,
+		log_count,
+		storage,
+		hash,
+		size
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at, oldest, newest, log_count, storage, hash, size
+`
+
+type InsertAuditLogArchiveParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	Oldest    time.Time `db:"oldest" json:"oldest"`
+	Newest    time.Time `db:"newest" json:"newest"`
+	LogCount  int32     `db:"log_count" json:"log_count"`
+	Storage   string    `db:"storage" json:"storage"`
+	Hash      string    `db:"hash" json:"hash"`
+	Size      int64     `db:"size" json:"size"`
+}
+
+func (q *sqlQuerier) InsertAuditLogArchive(ctx context.Context, arg InsertAuditLogArchiveParams) (AuditLogArchive, error) {
+	row := q.db.QueryRowContext(ctx, insertAuditLogArchive,
+		arg.ID,
+		arg.CreatedAt,
+		arg.Oldest,
+		arg.Newest,
+		arg.LogCount,
+		arg.Storage,
+		arg.Hash,
+		arg.Size,
+	)
+	var i AuditLogArchive
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.Oldest,
+		&i.Newest,
+		&i.LogCount,
+		&i.Storage,
+		&i.Hash,
+		&i.Size,
+	)
+	return i, err
+}
+
+const deleteAuditLogsByIDs = `-- name: DeleteAuditLogsByIDs :exec
+DELETE FROM
+	audit_logs
+WHERE
+	id = ANY($1 :: uuid[])
+`
+
+func (q *sqlQuerier) DeleteAuditLogsByIDs(ctx context.Context, ids []uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteAuditLogsByIDs, pq.Array(ids))
+	return err
+}
+
+const getAuditLogsByTimeRange = `-- name: GetAuditLogsByTimeRange :many
+SELECT
+	id, time, user_id, organization_id, ip, user_agent, resource_type, resource_id, resource_target, action, diff, status_code, additional_fields, request_id, resource_icon
+FROM
+	audit_logs
+WHERE
+	("time", id) > ($1 :: timestamp with time zone, $2 :: uuid)
+	AND "time" < $3 :: timestamp with time zone
+ORDER BY
+	"time", id
+LIMIT
+	$4 :: int
+`
+
+type GetAuditLogsByTimeRangeParams struct {
+	AfterTime time.Time `db:"after_time" json:"after_time"`
+	AfterID   uuid.UUID `db:"after_id" json:"after_id"`
+	Until     time.Time `db:"until" json:"until"`
+	RowLimit  int32     `db:"row_limit" json:"row_limit"`
+}
+
+// GetAuditLogsByTimeRange returns `row_limit` audit logs before `until`, in
+// the order they happened, after the audit log at `after_time` with
+// `after_id`. It's used to page through the audit logs of a time range for
+// export.
+func (q *sqlQuerier) GetAuditLogsByTimeRange(ctx context.Context, arg GetAuditLogsByTimeRangeParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, getAuditLogsByTimeRange,
+		arg.AfterTime,
+		arg.AfterID,
+		arg.Until,
+		arg.RowLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Time,
+			&i.UserID,
+			&i.OrganizationID,
+			&i.Ip,
+			&i.UserAgent,
+			&i.ResourceType,
+			&i.ResourceID,
+			&i.ResourceTarget,
+			&i.Action,
+			&i.Diff,
+			&i.StatusCode,
+			&i.AdditionalFields,
+			&i.RequestID,
+			&i.ResourceIcon,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAuditLogsForExport = `-- name: GetAuditLogsForExport :many
 SELECT
     audit_logs.id, audit_logs.time, audit_logs.user_id, audit_logs.organization_id, audit_logs.ip, audit_logs.user_agent, audit_logs.resource_type, audit_logs.resource_id, audit_logs.resource_target, audit_logs.action, audit_logs.diff, audit_logs.status_code, audit_logs.additional_fields, audit_logs.request_id, audit_logs.resource_icon,
     users.username AS user_username,
-    users.email AS user_email,
-    users.created_at AS user_created_at,
-    users.status AS user_status,
-    users.rbac_roles AS user_roles,
-    users.avatar_url AS user_avatar_url,
-    COUNT(audit_logs.*) OVER () AS count
+    users.email AS user_email
 FROM
     audit_logs
     LEFT JOIN users ON audit_logs.user_id = users.id
@@ -331,81 +510,109 @@ FROM
 WHERE
     -- Filter resource_type
 	CASE
-		WHEN $3 :: text != '' THEN
-			resource_type = $3 :: resource_type
+		WHEN $1 :: text != '' THEN
+			resource_type = $1 :: resource_type
 		ELSE true
 	END
 	-- Filter resource_id
 	AND CASE
-		WHEN $4 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
-			resource_id = $4
+		WHEN $2 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			resource_id = $2
 		ELSE true
 	END
 	-- Filter by resource_target
 	AND CASE
-		WHEN $5 :: text != '' THEN
-			resource_target = $5
+		WHEN $3 :: text != '' THEN
+			lower(resource_target) = lower($3)
 		ELSE true
 	END
 	-- Filter action
 	AND CASE
-		WHEN $6 :: text != '' THEN
-			action = $6 :: audit_action
+		WHEN $4 :: text != '' THEN
+			action = $4 :: audit_action
 		ELSE true
 	END
 	-- Filter by username
 	AND CASE
-		WHEN $7 :: text != '' THEN
-			users.username = $7
+		WHEN $5 :: text != '' THEN
+			users.username = $5
 		ELSE true
 	END
 	-- Filter by user_email
 	AND CASE
-		WHEN $8 :: text != '' THEN
-			users.email = $8
+		WHEN $6 :: text != '' THEN
+			users.email = $6
 		ELSE true
 	END
 	-- Filter by date_from
 	AND CASE
-		WHEN $9 :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
-			"time" >= $9
+		WHEN $7 :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			"time" >= $7
 		ELSE true
 	END
 	-- Filter by date_to
 	AND CASE
-		WHEN $10 :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
-			"time" <= $10
+		WHEN $8 :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			"time" <= $8
 		ELSE true
 	END
     -- Filter by build_reason
     AND CASE
-	    WHEN $11::text != '' THEN
-            workspace_builds.reason::text = $11
+	    WHEN $9::text != '' THEN
+            workspace_builds.reason::text = $9
         ELSE true
     END
+	-- Filter by ip, which may be a CIDR range
+	AND CASE
+		WHEN $10 :: inet IS NOT NULL THEN
+			audit_logs.ip <<= $10 :: inet
+		ELSE true
+	END
+	-- Filter by request_id
+	AND CASE
+		WHEN $11 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			audit_logs.request_id = $11
+		ELSE true
+	END
+	-- Search the diff for free text, matching % and _ literally
+	AND CASE
+		WHEN $12 :: text != '' THEN
+			audit_logs.diff :: text ILIKE concat('%', replace(replace(replace($12 :: text, '\', '\\'), '%', '\%'), '_', '\_'), '%')
+		ELSE true
+	END
+	-- Page through the audit logs older than the last one of the previous
+	-- page, so new audit logs don't shift the pages like an offset would
+	AND CASE
+		WHEN $13 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			(audit_logs."time", audit_logs.id) < ($14 :: timestamp with time zone, $13)
+		ELSE true
+	END
 ORDER BY
-    "time" DESC
+    "time" DESC,
+    audit_logs.id DESC
 LIMIT
-    $1
-OFFSET
-    $2
-`
-
-type GetAuditLogsOffsetParams struct {
-	Limit          int32     `db:"limit" json:"limit"`
-	Offset         int32     `db:"offset" json:"offset"`
-	ResourceType   string    `db:"resource_type" json:"resource_type"`
-	ResourceID     uuid.UUID `db:"resource_id" json:"resource_id"`
-	ResourceTarget string    `db:"resource_target" json:"resource_target"`
-	Action         string    `db:"action" json:"action"`
-	Username       string    `db:"username" json:"username"`
-	Email          string    `db:"email" json:"email"`
-	DateFrom       time.Time `db:"date_from" json:"date_from"`
-	DateTo         time.Time `db:"date_to" json:"date_to"`
-	BuildReason    string    `db:"build_reason" json:"build_reason"`
-}
-
-type GetAuditLogsOffsetRow struct {
+    $15 :: int
+`
+
+type GetAuditLogsForExportParams struct {
+	ResourceType   string      `db:"resource_type" json:"resource_type"`
+	ResourceID     uuid.UUID   `db:"resource_id" json:"resource_id"`
+	ResourceTarget string      `db:"resource_target" json:"resource_target"`
+	Action         string      `db:"action" json:"action"`
+	Username       string      `db:"username" json:"username"`
+	Email          string      `db:"email" json:"email"`
+	DateFrom       time.Time   `db:"date_from" json:"date_from"`
+	DateTo         time.Time   `db:"date_to" json:"date_to"`
+	BuildReason    string      `db:"build_reason" json:"build_reason"`
+	Ip             pqtype.Inet `db:"ip" json:"ip"`
+	RequestID      uuid.UUID   `db:"request_id" json:"request_id"`
+	Search         string      `db:"search" json:"search"`
+	BeforeID       uuid.UUID   `db:"before_id" json:"before_id"`
+	BeforeTime     time.Time   `db:"before_time" json:"before_time"`
+	RowLimit       int32       `db:"row_limit" json:"row_limit"`
+}
+
+type GetAuditLogsForExportRow struct {
 	ID               uuid.UUID       `db:"id" json:"id"`
 	Time             time.Time       `db:"time" json:"time"`
 	UserID           uuid.UUID       `db:"user_id" json:"user_id"`
@@ -423,19 +630,14 @@ type GetAuditLogsOffsetRow struct {
 	ResourceIcon     string          `db:"resource_icon" json:"resource_icon"`
 	UserUsername     sql.NullString  `db:"user_username" json:"user_username"`
 	UserEmail        sql.NullString  `db:"user_email" json:"user_email"`
-	UserCreatedAt    sql.NullTime    `db:"user_created_at" json:"user_created_at"`
-	UserStatus       NullUserStatus  `db:"user_status" json:"user_status"`
-	UserRoles        []string        `db:"user_roles" json:"user_roles"`
-	UserAvatarUrl    sql.NullString  `db:"user_avatar_url" json:"user_avatar_url"`
-	Count            int64           `db:"count" json:"count"`
 }
 
-// GetAuditLogsBefore retrieves `row_limit` number of audit logs before the provided
-// ID.
-func (q *sqlQuerier) GetAuditLogsOffset(ctx context.Context, arg GetAuditLogsOffsetParams) ([]GetAuditLogsOffsetRow, error) {
-	rows, err := q.db.QueryContext(ctx, getAuditLogsOffset,
-		arg.Limit,
-		arg.Offset,
+// GetAuditLogsForExport returns `row_limit` audit logs that match the filters,
+// newest first, older than the audit log at `before_time` with `before_id`.
+// It's used to page through the audit logs for export, so unlike
+// GetAuditLogsOffset it doesn't count the matching audit logs.
+func (q *sqlQuerier) GetAuditLogsForExport(ctx context.Context, arg GetAuditLogsForExportParams) ([]GetAuditLogsForExportRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAuditLogsForExport,
 		arg.ResourceType,
 		arg.ResourceID,
 		arg.ResourceTarget,
@@ -445,14 +647,20 @@ func (q *sqlQuerier) GetAuditLogsOffset(ctx context.Context, arg GetAuditLogsOff
 		arg.DateFrom,
 		arg.DateTo,
 		arg.BuildReason,
+		arg.Ip,
+		arg.RequestID,
+		arg.Search,
+		arg.BeforeID,
+		arg.BeforeTime,
+		arg.RowLimit,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetAuditLogsOffsetRow
+	var items []GetAuditLogsForExportRow
 	for rows.Next() {
-		var i GetAuditLogsOffsetRow
+		var i GetAuditLogsForExportRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Time,
@@ -471,11 +679,6 @@ func (q *sqlQuerier) GetAuditLogsOffset(ctx context.Context, arg GetAuditLogsOff
 			&i.ResourceIcon,
 			&i.UserUsername,
 			&i.UserEmail,
-			&i.UserCreatedAt,
-			&i.UserStatus,
-			pq.Array(&i.UserRoles),
-			&i.UserAvatarUrl,
-			&i.Count,
 		); err != nil {
 			return nil, err
 		}
@@ -490,490 +693,2423 @@ func (q *sqlQuerier) GetAuditLogsOffset(ctx context.Context, arg GetAuditLogsOff
 	return items, nil
 }
 
-const insertAuditLog = `-- name: InsertAuditLog :one
-INSERT INTO
-	audit_logs (
-        id,
-        "time",
-        user_id,
-        organization_id,
-        ip,
-        user_agent,
-        resource_type,
-        resource_id,
-        resource_target,
-        action,
-        diff,
-        status_code,
-        additional_fields,
-        request_id,
-        resource_icon
-    )
-VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id, time, user_id, organization_id, ip, user_agent, resource_type, resource_id, resource_target, action, diff, status_code, additional_fields, request_id, resource_icon
+const getAuditLogsForPurge = `-- name: GetAuditLogsForPurge :many
+SELECT
+	id, time, user_id, organization_id, ip, user_agent, resource_type, resource_id, resource_target, action, diff, status_code, additional_fields, request_id, resource_icon
+FROM
+	audit_logs
+WHERE
+	"time" < $1 :: timestamp with time zone
+ORDER BY
+	"time", id
+LIMIT
+	$2 :: int
+FOR UPDATE SKIP LOCKED
 `
 
-type InsertAuditLogParams struct {
-	ID               uuid.UUID       `db:"id" json:"id"`
-	Time             time.Time       `db:"time" json:"time"`
-	UserID           uuid.UUID       `db:"user_id" json:"user_id"`
-	OrganizationID   uuid.UUID       `db:"organization_id" json:"organization_id"`
-	Ip               pqtype.Inet     `db:"ip" json:"ip"`
-	UserAgent        sql.NullString  `db:"user_agent" json:"user_agent"`
-	ResourceType     ResourceType    `db:"resource_type" json:"resource_type"`
-	ResourceID       uuid.UUID       `db:"resource_id" json:"resource_id"`
-	ResourceTarget   string          `db:"resource_target" json:"resource_target"`
-	Action           AuditAction     `db:"action" json:"action"`
-	Diff             json.RawMessage `db:"diff" json:"diff"`
-	StatusCode       int32           `db:"status_code" json:"status_code"`
-	AdditionalFields json.RawMessage `db:"additional_fields" json:"additional_fields"`
-	RequestID        uuid.UUID       `db:"request_id" json:"request_id"`
-	ResourceIcon     string          `db:"resource_icon" json:"resource_icon"`
+type GetAuditLogsForPurgeParams struct {
+	Before   time.Time `db:"before" json:"before"`
+	RowLimit int32     `db:"row_limit" json:"row_limit"`
 }
 
-func (q *sqlQuerier) InsertAuditLog(ctx context.Context, arg InsertAuditLogParams) (AuditLog, error) {
-	row := q.db.QueryRowContext(ctx, insertAuditLog,
-		arg.ID,
-		arg.Time,
-		arg.UserID,
-		arg.OrganizationID,
-		arg.Ip,
-		arg.UserAgent,
-		arg.ResourceType,
-		arg.ResourceID,
-		arg.ResourceTarget,
-		arg.Action,
-		arg.Diff,
-		arg.StatusCode,
-		arg.AdditionalFields,
-		arg.RequestID,
-		arg.ResourceIcon,
-	)
-	var i AuditLog
-	err := row.Scan(
-		&i.ID,
-		&i.Time,
-		&i.UserID,
-		&i.OrganizationID,
-		&i.Ip,
-		&i.UserAgent,
-		&i.ResourceType,
-		&i.ResourceID,
-		&i.ResourceTarget,
-		&i.Action,
-		&i.Diff,
-		&i.StatusCode,
-		&i.AdditionalFields,
-		&i.RequestID,
-		&i.ResourceIcon,
-	)
+// GetAuditLogsForPurge returns the `row_limit` oldest audit logs before
+// `before`, locking them so replicas purging at the same time skip them.
+func (q *sqlQuerier) GetAuditLogsForPurge(ctx context.Context, arg GetAuditLogsForPurgeParams) ([]AuditLog, error) {
+	rows, err := q.db.QueryContext(ctx, getAuditLogsForPurge, arg.Before, arg.RowLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []AuditLog
+	for rows.Next() {
+		var i AuditLog
+		if err := rows.Scan(
+			&i.ID,
+			&i.Time,
+			&i.UserID,
+			&i.OrganizationID,
+			&i.Ip,
+			&i.UserAgent,
+			&i.ResourceType,
+			&i.ResourceID,
+			&i.ResourceTarget,
+			&i.Action,
+			&i.Diff,
+			&i.StatusCode,
+			&i.AdditionalFields,
+			&i.RequestID,
+			&i.ResourceIcon,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getAuditLogsOffset = `-- name: GetAuditLogsOffset :many
+SELECT
+    audit_logs.id, audit_logs.time, audit_logs.user_id, audit_logs.organization_id, audit_logs.ip, audit_logs.user_agent, audit_logs.resource_type, audit_logs.resource_id, audit_logs.resource_target, audit_logs.action, audit_logs.diff, audit_logs.status_code, audit_logs.additional_fields, audit_logs.request_id, audit_logs.resource_icon,
+    users.username AS user_username,
+    users.email AS user_email,
+    users.created_at AS user_created_at,
+    users.status AS user_status,
+    users.rbac_roles AS user_roles,
+    users.avatar_url AS user_avatar_url,
+    COUNT(audit_logs.*) OVER () AS count
+FROM
+    audit_logs
+    LEFT JOIN users ON audit_logs.user_id = users.id
+    LEFT JOIN
+        -- First join on workspaces to get the initial workspace create
+        -- to workspace build 1 id. This is because the first create is
+        -- is a different audit log than subsequent starts.
+        workspaces ON
+		    audit_logs.resource_type = 'workspace' AND
+			audit_logs.resource_id = workspaces.id
+    LEFT JOIN
+	    workspace_builds ON
+            -- Get the reason from the build if the resource type
+            -- is a workspace_build
+            (
+			    audit_logs.resource_type = 'workspace_build'
+                AND audit_logs.resource_id = workspace_builds.id
+			)
+            OR
+            -- Get the reason from the build #1 if this is the first
+            -- workspace create.
+            (
+				audit_logs.resource_type = 'workspace' AND
+				audit_logs.action = 'create' AND
+				workspaces.id = workspace_builds.workspace_id AND
+				workspace_builds.build_number = 1
+			)
+WHERE
+    -- Filter resource_type
+	CASE
+		WHEN $3 :: text != '' THEN
+			resource_type = $3 :: resource_type
+		ELSE true
+	END
+	-- Filter resource_id
+	AND CASE
+		WHEN $4 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			resource_id = $4
+		ELSE true
+	END
+	-- Filter by resource_target
+	AND CASE
+		WHEN $5 :: text != '' THEN
+			lower(resource_target) = lower($5)
+		ELSE true
+	END
+	-- Filter action
+	AND CASE
+		WHEN $6 :: text != '' THEN
+			action = $6 :: audit_action
+		ELSE true
+	END
+	-- Filter by username
+	AND CASE
+		WHEN $7 :: text != '' THEN
+			users.username = $7
+		ELSE true
+	END
+	-- Filter by user_email
+	AND CASE
+		WHEN $8 :: text != '' THEN
+			users.email = $8
+		ELSE true
+	END
+	-- Filter by date_from
+	AND CASE
+		WHEN $9 :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			"time" >= $9
+		ELSE true
+	END
+	-- Filter by date_to
+	AND CASE
+		WHEN $10 :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			"time" <= $10
+		ELSE true
+	END
+    -- Filter by build_reason
+    AND CASE
+	    WHEN $11::text != '' THEN
+            workspace_builds.reason::text = $11
+        ELSE true
+    END
+	-- Filter by ip, which may be a CIDR range
+	AND CASE
+		WHEN $12 :: inet IS NOT NULL THEN
+			audit_logs.ip <<= $12 :: inet
+		ELSE true
+	END
+	-- Filter by request_id
+	AND CASE
+		WHEN $13 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			audit_logs.request_id = $13
+		ELSE true
+	END
+	-- Search the diff for free text, matching % and _ literally
+	AND CASE
+		WHEN $14 :: text != '' THEN
+			audit_logs.diff :: text ILIKE concat('%', replace(replace(replace($14 :: text, '\', '\\'), '%', '\%'), '_', '\_'), '%')
+		ELSE true
+	END
+ORDER BY
+    "time" DESC,
+    audit_logs.id DESC
+LIMIT
+    $1
+OFFSET
+    $2
+`
+
+type GetAuditLogsOffsetParams struct {
+	Limit          int32       `db:"limit" json:"limit"`
+	Offset         int32       `db:"offset" json:"offset"`
+	ResourceType   string      `db:"resource_type" json:"resource_type"`
+	ResourceID     uuid.UUID   `db:"resource_id" json:"resource_id"`
+	ResourceTarget string      `db:"resource_target" json:"resource_target"`
+	Action         string      `db:"action" json:"action"`
+	Username       string      `db:"username" json:"username"`
+	Email          string      `db:"email" json:"email"`
+	DateFrom       time.Time   `db:"date_from" json:"date_from"`
+	DateTo         time.Time   `db:"date_to" json:"date_to"`
+	BuildReason    string      `db:"build_reason" json:"build_reason"`
+	Ip             pqtype.Inet `db:"ip" json:"ip"`
+	RequestID      uuid.UUID   `db:"request_id" json:"request_id"`
+	Search         string      `db:"search" json:"search"`
+}
+
+type GetAuditLogsOffsetRow struct {
+	ID               uuid.UUID       `db:"id" json:"id"`
+	Time             time.Time       `db:"time" json:"time"`
+	UserID           uuid.UUID       `db:"user_id" json:"user_id"`
+	OrganizationID   uuid.UUID       `db:"organization_id" json:"organization_id"`
+	Ip               pqtype.Inet     `db:"ip" json:"ip"`
+	UserAgent        sql.NullString  `db:"user_agent" json:"user_agent"`
+	ResourceType     ResourceType    `db:"resource_type" json:"resource_type"`
+	ResourceID       uuid.UUID       `db:"resource_id" json:"resource_id"`
+	ResourceTarget   string          `db:"resource_target" json:"resource_target"`
+	Action           AuditAction     `db:"action" json:"action"`
+	Diff             json.RawMessage `db:"diff" json:"diff"`
+	StatusCode       int32           `db:"status_code" json:"status_code"`
+	AdditionalFields json.RawMessage `db:"additional_fields" json:"additional_fields"`
+	RequestID        uuid.UUID       `db:"request_id" json:"request_id"`
+	ResourceIcon     string          `db:"resource_icon" json:"resource_icon"`
+	UserUsername     sql.NullString  `db:"user_username" json:"user_username"`
+	UserEmail        sql.NullString  `db:"user_email" json:"user_email"`
+	UserCreatedAt    sql.NullTime    `db:"user_created_at" json:"user_created_at"`
+	UserStatus       NullUserStatus  `db:"user_status" json:"user_status"`
+	UserRoles        []string        `db:"user_roles" json:"user_roles"`
+	UserAvatarUrl    sql.NullString  `db:"user_avatar_url" json:"user_avatar_url"`
+	Count            int64           `db:"count" json:"count"`
+}
+
+// GetAuditLogsBefore retrieves `row_limit` number of audit logs before the provided
+// ID.
+func (q *sqlQuerier) GetAuditLogsOffset(ctx context.Context, arg GetAuditLogsOffsetParams) ([]GetAuditLogsOffsetRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAuditLogsOffset,
+		arg.Limit,
+		arg.Offset,
+		arg.ResourceType,
+		arg.ResourceID,
+		arg.ResourceTarget,
+		arg.Action,
+		arg.Username,
+		arg.Email,
+		arg.DateFrom,
+		arg.DateTo,
+		arg.BuildReason,
+		arg.Ip,
+		arg.RequestID,
+		arg.Search,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetAuditLogsOffsetRow
+	for rows.Next() {
+		var i GetAuditLogsOffsetRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Time,
+			&i.UserID,
+			&i.OrganizationID,
+			&i.Ip,
+			&i.UserAgent,
+			&i.ResourceType,
+			&i.ResourceID,
+			&i.ResourceTarget,
+			&i.Action,
+			&i.Diff,
+			&i.StatusCode,
+			&i.AdditionalFields,
+			&i.RequestID,
+			&i.ResourceIcon,
+			&i.UserUsername,
+			&i.UserEmail,
+			&i.UserCreatedAt,
+			&i.UserStatus,
+			pq.Array(&i.UserRoles),
+			&i.UserAvatarUrl,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertAuditLog = `-- name: InsertAuditLog :one
+INSERT INTO
+	audit_logs (
+        id,
+        "time",
+        user_id,
+        organization_id,
+        ip,
+        user_agent,
+        resource_type,
+        resource_id,
+        resource_target,
+        action,
+        diff,
+        status_code,
+        additional_fields,
+        request_id,
+        resource_icon
+    )
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id, time, user_id, organization_id, ip, user_agent, resource_type, resource_id, resource_target, action, diff, status_code, additional_fields, request_id, resource_icon
+`
+
+type InsertAuditLogParams struct {
+	ID               uuid.UUID       `db:"id" json:"id"`
+	Time             time.Time       `db:"time" json:"time"`
+	UserID           uuid.UUID       `db:"user_id" json:"user_id"`
+	OrganizationID   uuid.UUID       `db:"organization_id" json:"organization_id"`
+	Ip               pqtype.Inet     `db:"ip" json:"ip"`
+	UserAgent        sql.NullString  `db:"user_agent" json:"user_agent"`
+	ResourceType     ResourceType    `db:"resource_type" json:"resource_type"`
+	ResourceID       uuid.UUID       `db:"resource_id" json:"resource_id"`
+	ResourceTarget   string          `db:"resource_target" json:"resource_target"`
+	Action           AuditAction     `db:"action" json:"action"`
+	Diff             json.RawMessage `db:"diff" json:"diff"`
+	StatusCode       int32           `db:"status_code" json:"status_code"`
+	AdditionalFields json.RawMessage `db:"additional_fields" json:"additional_fields"`
+	RequestID        uuid.UUID       `db:"request_id" json:"request_id"`
+	ResourceIcon     string          `db:"resource_icon" json:"resource_icon"`
+}
+
+func (q *sqlQuerier) InsertAuditLog(ctx context.Context, arg InsertAuditLogParams) (AuditLog, error) {
+	row := q.db.QueryRowContext(ctx, insertAuditLog,
+		arg.ID,
+		arg.Time,
+		arg.UserID,
+		arg.OrganizationID,
+		arg.Ip,
+		arg.UserAgent,
+		arg.ResourceType,
+		arg.ResourceID,
+		arg.ResourceTarget,
+		arg.Action,
+		arg.Diff,
+		arg.StatusCode,
+		arg.AdditionalFields,
+		arg.RequestID,
+		arg.ResourceIcon,
+	)
+	var i AuditLog
+	err := row.Scan(
+		&i.ID,
+		&i.Time,
+		&i.UserID,
+		&i.OrganizationID,
+		&i.Ip,
+		&i.UserAgent,
+		&i.ResourceType,
+		&i.ResourceID,
+		&i.ResourceTarget,
+		&i.Action,
+		&i.Diff,
+		&i.StatusCode,
+		&i.AdditionalFields,
+		&i.RequestID,
+		&i.ResourceIcon,
+	)
+	return i, err
+}
+
+const deleteUnusedFiles = `-- name: DeleteUnusedFiles :many
+DELETE FROM
+	files
+WHERE
+	id IN (
+		SELECT
+			id
+		FROM
+			files
+		WHERE
+			created_at < $1 :: timestamptz
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					template_versions
+				JOIN provisioner_jobs ON provisioner_jobs.id = template_versions.job_id
+				WHERE
+					template_versions.archived = false
+					AND provisioner_jobs.file_id = files.id
+			)
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					provisioner_jobs
+				WHERE
+					provisioner_jobs.file_id = files.id
+					AND provisioner_jobs.completed_at IS NULL
+			)
+		FOR UPDATE SKIP LOCKED
+	)
+RETURNING id, hash, storage
+`
+
+type DeleteUnusedFilesRow struct {
+	ID      uuid.UUID `db:"id" json:"id"`
+	Hash    string    `db:"hash" json:"hash"`
+	Storage string    `db:"storage" json:"storage"`
+}
+
+// Deletes the files created before the cutoff that no future build can use,
+// and returns them so their contents can be deleted from the backend they're
+// stored in. A file is needed while it's referenced by the import job of a
+// template version that isn't archived, or by any provisioner job that hasn't
+// completed. Files locked by LockFileByID are being used to unarchive a
+// template version, so they're skipped.
+func (q *sqlQuerier) DeleteUnusedFiles(ctx context.Context, createdBefore time.Time) ([]DeleteUnusedFilesRow, error) {
+	rows, err := q.db.QueryContext(ctx, deleteUnusedFiles, createdBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeleteUnusedFilesRow
+	for rows.Next() {
+		var i DeleteUnusedFilesRow
+		if err := rows.Scan(&i.ID, &i.Hash, &i.Storage); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFileByHashAndCreator = `-- name: GetFileByHashAndCreator :one
+SELECT
+	hash, created_at, created_by, mimetype, data, id, storage
+FROM
+	files
+WHERE
+	hash = $1
+AND
+	created_by = $2
+LIMIT
+	1
+`
+
+type GetFileByHashAndCreatorParams struct {
+	Hash      string    `db:"hash" json:"hash"`
+	CreatedBy uuid.UUID `db:"created_by" json:"created_by"`
+}
+
+func (q *sqlQuerier) GetFileByHashAndCreator(ctx context.Context, arg GetFileByHashAndCreatorParams) (File, error) {
+	row := q.db.QueryRowContext(ctx, getFileByHashAndCreator, arg.Hash, arg.CreatedBy)
+	var i File
+	err := row.Scan(
+		&i.Hash,
+		&i.CreatedAt,
+		&i.CreatedBy,
+		&i.Mimetype,
+		&i.Data,
+		&i.ID,
+		&i.Storage,
+	)
+	return i, err
+}
+
+const getFileByID = `-- name: GetFileByID :one
+SELECT
+	hash, created_at, created_by, mimetype, data, id, storage
+FROM
+	files
+WHERE
+	id = $1
+LIMIT
+	1
+`
+
+func (q *sqlQuerier) GetFileByID(ctx context.Context, id uuid.UUID) (File, error) {
+	row := q.db.QueryRowContext(ctx, getFileByID, id)
+	var i File
+	err := row.Scan(
+		&i.Hash,
+		&i.CreatedAt,
+		&i.CreatedBy,
+		&i.Mimetype,
+		&i.Data,
+		&i.ID,
+		&i.Storage,
+	)
+	return i, err
+}
+
+const getFileHashInUse = `-- name: GetFileHashInUse :one
+SELECT
+	EXISTS (
+		SELECT
+			1
+		FROM
+			files
+		WHERE
+			hash = $1
+	) :: boolean
+`
+
+// Returns whether any file has the hash, regardless of the backend its
+// contents are stored in. Contents are only deleted from a backend once no
+// file refers to them, so files that are being migrated keep their contents.
+func (q *sqlQuerier) GetFileHashInUse(ctx context.Context, hash string) (bool, error) {
+	row := q.db.QueryRowContext(ctx, getFileHashInUse, hash)
+	var column_1 bool
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const getFileIDsByStorage = `-- name: GetFileIDsByStorage :many
+SELECT
+	id
+FROM
+	files
+WHERE
+	storage = $1
+ORDER BY
+	created_at ASC
+`
+
+func (q *sqlQuerier) GetFileIDsByStorage(ctx context.Context, storage string) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, getFileIDsByStorage, storage)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertFile = `-- name: InsertFile :one
+INSERT INTO
+	files (id, hash, created_at, created_by, mimetype, "data", storage)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7) RETURNING hash, created_at, created_by, mimetype, data, id, storage
+`
+
+type InsertFileParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	Hash      string    `db:"hash" json:"hash"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	CreatedBy uuid.UUID `db:"created_by" json:"created_by"`
+	Mimetype  string    `db:"mimetype" json:"mimetype"`
+	Data      []byte    `db:"data" json:"data"`
+	Storage   string    `db:"storage" json:"storage"`
+}
+
+func (q *sqlQuerier) InsertFile(ctx context.Context, arg InsertFileParams) (File, error) {
+	row := q.db.QueryRowContext(ctx, insertFile,
+		arg.ID,
+		arg.Hash,
+		arg.CreatedAt,
+		arg.CreatedBy,
+		arg.Mimetype,
+		arg.Data,
+		arg.Storage,
+	)
+	var i File
+	err := row.Scan(
+		&i.Hash,
+		&i.CreatedAt,
+		&i.CreatedBy,
+		&i.Mimetype,
+		&i.Data,
+		&i.ID,
+		&i.Storage,
+	)
+	return i, err
+}
+
+const lockFileByID = `-- name: LockFileByID :one
+SELECT
+	id
+FROM
+	files
+WHERE
+	id = $1
+FOR SHARE
+`
+
+// Locks a file until the end of the transaction, so it isn't deleted by
+// DeleteUnusedFiles before the template version that uses it is unarchived.
+func (q *sqlQuerier) LockFileByID(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	row := q.db.QueryRowContext(ctx, lockFileByID, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const updateFileStorageByID = `-- name: UpdateFileStorageByID :exec
+UPDATE
+	files
+SET
+	storage = $2,
+	"data" = $3
+WHERE
+	id = $1
+`
+
+type UpdateFileStorageByIDParams struct {
+	ID      uuid.UUID `db:"id" json:"id"`
+	Storage string    `db:"storage" json:"storage"`
+	Data    []byte    `db:"data" json:"data"`
+}
+
+func (q *sqlQuerier) UpdateFileStorageByID(ctx context.Context, arg UpdateFileStorageByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateFileStorageByID, arg.ID, arg.Storage, arg.Data)
+	return err
+}
+
+const getGitAuthLink = `-- name: GetGitAuthLink :one
+SELECT provider_id, user_id, created_at, updated_at, oauth_access_token, oauth_refresh_token, oauth_expiry FROM git_auth_links WHERE provider_id = $1 AND user_id = $2
+`
+
+type GetGitAuthLinkParams struct {
+	ProviderID string    `db:"provider_id" json:"provider_id"`
+	UserID     uuid.UUID `db:"user_id" json:"user_id"`
+}
+
+func (q *sqlQuerier) GetGitAuthLink(ctx context.Context, arg GetGitAuthLinkParams) (GitAuthLink, error) {
+	row := q.db.QueryRowContext(ctx, getGitAuthLink, arg.ProviderID, arg.UserID)
+	var i GitAuthLink
+	err := row.Scan(
+		&i.ProviderID,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OAuthAccessToken,
+		&i.OAuthRefreshToken,
+		&i.OAuthExpiry,
+	)
+	return i, err
+}
+
+const insertGitAuthLink = `-- name: InsertGitAuthLink :one
+INSERT INTO git_auth_links (
+    provider_id,
+    user_id,
+    created_at,
+    updated_at,
+    oauth_access_token,
+    oauth_refresh_token,
+    oauth_expiry
+) VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5,
+    $6,
+    $7
+) RETURNING provider_id, user_id, created_at, updated_at, oauth_access_token, oauth_refresh_token, oauth_expiry
+`
+
+type InsertGitAuthLinkParams struct {
+	ProviderID        string    `db:"provider_id" json:"provider_id"`
+	UserID            uuid.UUID `db:"user_id" json:"user_id"`
+	CreatedAt         time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
+	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
+	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
+}
+
+func (q *sqlQuerier) InsertGitAuthLink(ctx context.Context, arg InsertGitAuthLinkParams) (GitAuthLink, error) {
+	row := q.db.QueryRowContext(ctx, insertGitAuthLink,
+		arg.ProviderID,
+		arg.UserID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.OAuthAccessToken,
+		arg.OAuthRefreshToken,
+		arg.OAuthExpiry,
+	)
+	var i GitAuthLink
+	err := row.Scan(
+		&i.ProviderID,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OAuthAccessToken,
+		&i.OAuthRefreshToken,
+		&i.OAuthExpiry,
+	)
+	return i, err
+}
+
+const updateGitAuthLink = `-- name: UpdateGitAuthLink :one
+UPDATE git_auth_links SET
+    updated_at = $3,
+    oauth_access_token = $4,
+    oauth_refresh_token = $5,
+    oauth_expiry = $6
+WHERE provider_id = $1 AND user_id = $2 RETURNING provider_id, user_id, created_at, updated_at, oauth_access_token, oauth_refresh_token, oauth_expiry
+`
+
+type UpdateGitAuthLinkParams struct {
+	ProviderID        string    `db:"provider_id" json:"provider_id"`
+	UserID            uuid.UUID `db:"user_id" json:"user_id"`
+	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
+	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
+	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
+}
+
+func (q *sqlQuerier) UpdateGitAuthLink(ctx context.Context, arg UpdateGitAuthLinkParams) (GitAuthLink, error) {
+	row := q.db.QueryRowContext(ctx, updateGitAuthLink,
+		arg.ProviderID,
+		arg.UserID,
+		arg.UpdatedAt,
+		arg.OAuthAccessToken,
+		arg.OAuthRefreshToken,
+		arg.OAuthExpiry,
+	)
+	var i GitAuthLink
+	err := row.Scan(
+		&i.ProviderID,
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OAuthAccessToken,
+		&i.OAuthRefreshToken,
+		&i.OAuthExpiry,
+	)
+	return i, err
+}
+
+const deleteGitSSHKey = `-- name: DeleteGitSSHKey :exec
+DELETE FROM
+	gitsshkeys
+WHERE
+	user_id = $1
+`
+
+func (q *sqlQuerier) DeleteGitSSHKey(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteGitSSHKey, userID)
+	return err
+}
+
+const getGitSSHKey = `-- name: GetGitSSHKey :one
+SELECT
+	user_id, created_at, updated_at, private_key, public_key
+FROM
+	gitsshkeys
+WHERE
+	user_id = $1
+`
+
+func (q *sqlQuerier) GetGitSSHKey(ctx context.Context, userID uuid.UUID) (GitSSHKey, error) {
+	row := q.db.QueryRowContext(ctx, getGitSSHKey, userID)
+	var i GitSSHKey
+	err := row.Scan(
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PrivateKey,
+		&i.PublicKey,
+	)
+	return i, err
+}
+
+const insertGitSSHKey = `-- name: InsertGitSSHKey :one
+INSERT INTO
+	gitsshkeys (
+		user_id,
+		created_at,
+		updated_at,
+		private_key,
+		public_key
+	)
+VALUES
+	($1, $2, $3, $4, $5) RETURNING user_id, created_at, updated_at, private_key, public_key
+`
+
+type InsertGitSSHKeyParams struct {
+	UserID     uuid.UUID `db:"user_id" json:"user_id"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+	PrivateKey string    `db:"private_key" json:"private_key"`
+	PublicKey  string    `db:"public_key" json:"public_key"`
+}
+
+func (q *sqlQuerier) InsertGitSSHKey(ctx context.Context, arg InsertGitSSHKeyParams) (GitSSHKey, error) {
+	row := q.db.QueryRowContext(ctx, insertGitSSHKey,
+		arg.UserID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.PrivateKey,
+		arg.PublicKey,
+	)
+	var i GitSSHKey
+	err := row.Scan(
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PrivateKey,
+		&i.PublicKey,
+	)
+	return i, err
+}
+
+const updateGitSSHKey = `-- name: UpdateGitSSHKey :one
+UPDATE
+	gitsshkeys
+SET
+	updated_at = $2,
+	private_key = $3,
+	public_key = $4
+WHERE
+	user_id = $1
+RETURNING
+	user_id, created_at, updated_at, private_key, public_key
+`
+
+type UpdateGitSSHKeyParams struct {
+	UserID     uuid.UUID `db:"user_id" json:"user_id"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+	PrivateKey string    `db:"private_key" json:"private_key"`
+	PublicKey  string    `db:"public_key" json:"public_key"`
+}
+
+func (q *sqlQuerier) UpdateGitSSHKey(ctx context.Context, arg UpdateGitSSHKeyParams) (GitSSHKey, error) {
+	row := q.db.QueryRowContext(ctx, updateGitSSHKey,
+		arg.UserID,
+		arg.UpdatedAt,
+		arg.PrivateKey,
+		arg.PublicKey,
+	)
+	var i GitSSHKey
+	err := row.Scan(
+		&i.UserID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PrivateKey,
+		&i.PublicKey,
+	)
+	return i, err
+}
+
+const deleteGroupMemberFromGroup = `-- name: DeleteGroupMemberFromGroup :exec
+DELETE FROM
+	group_members
+WHERE
+	user_id = $1 AND
+	group_id = $2
+`
+
+type DeleteGroupMemberFromGroupParams struct {
+	UserID  uuid.UUID `db:"user_id" json:"user_id"`
+	GroupID uuid.UUID `db:"group_id" json:"group_id"`
+}
+
+func (q *sqlQuerier) DeleteGroupMemberFromGroup(ctx context.Context, arg DeleteGroupMemberFromGroupParams) error {
+	_, err := q.db.ExecContext(ctx, deleteGroupMemberFromGroup, arg.UserID, arg.GroupID)
+	return err
+}
+
+const deleteGroupMembersByOrgAndUser = `-- name: DeleteGroupMembersByOrgAndUser :exec
+DELETE FROM
+    group_members
+USING
+    group_members AS gm
+LEFT JOIN
+    groups
+ON
+    groups.id = gm.group_id
+WHERE
+    groups.organization_id = $1 AND
+    gm.user_id = $2
+`
+
+type DeleteGroupMembersByOrgAndUserParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	UserID         uuid.UUID `db:"user_id" json:"user_id"`
+}
+
+func (q *sqlQuerier) DeleteGroupMembersByOrgAndUser(ctx context.Context, arg DeleteGroupMembersByOrgAndUserParams) error {
+	_, err := q.db.ExecContext(ctx, deleteGroupMembersByOrgAndUser, arg.OrganizationID, arg.UserID)
+	return err
+}
+
+const getGroupMembers = `-- name: GetGroupMembers :many
+SELECT
+	users.id, users.email, users.username, users.hashed_password, users.created_at, users.updated_at, users.status, users.rbac_roles, users.login_type, users.avatar_url, users.deleted, users.last_seen_at
+FROM
+	users
+JOIN
+	group_members
+ON
+	users.id = group_members.user_id
+WHERE
+	group_members.group_id = $1
+AND
+	users.status = 'active'
+AND
+	users.deleted = 'false'
+`
+
+func (q *sqlQuerier) GetGroupMembers(ctx context.Context, groupID uuid.UUID) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getGroupMembers, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Username,
+			&i.HashedPassword,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.RBACRoles,
+			&i.LoginType,
+			&i.AvatarURL,
+			&i.Deleted,
+			&i.LastSeenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertGroupMember = `-- name: InsertGroupMember :exec
+INSERT INTO
+    group_members (user_id, group_id)
+VALUES
+    ($1, $2)
+`
+
+type InsertGroupMemberParams struct {
+	UserID  uuid.UUID `db:"user_id" json:"user_id"`
+	GroupID uuid.UUID `db:"group_id" json:"group_id"`
+}
+
+func (q *sqlQuerier) InsertGroupMember(ctx context.Context, arg InsertGroupMemberParams) error {
+	_, err := q.db.ExecContext(ctx, insertGroupMember, arg.UserID, arg.GroupID)
+	return err
+}
+
+const insertUserGroupsByName = `-- name: InsertUserGroupsByName :exec
+WITH groups AS (
+    SELECT
+        id
+    FROM
+        groups
+    WHERE
+        groups.organization_id = $2 AND
+        groups.name = ANY($3 :: text [])
+)
+INSERT INTO
+    group_members (user_id, group_id)
+SELECT
+    $1,
+    groups.id
+FROM
+    groups
+`
+
+type InsertUserGroupsByNameParams struct {
+	UserID         uuid.UUID `db:"user_id" json:"user_id"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	GroupNames     []string  `db:"group_names" json:"group_names"`
+}
+
+// InsertUserGroupsByName adds a user to all provided groups, if they exist.
+func (q *sqlQuerier) InsertUserGroupsByName(ctx context.Context, arg InsertUserGroupsByNameParams) error {
+	_, err := q.db.ExecContext(ctx, insertUserGroupsByName, arg.UserID, arg.OrganizationID, pq.Array(arg.GroupNames))
+	return err
+}
+
+const deleteGroupByID = `-- name: DeleteGroupByID :exec
+DELETE FROM
+	groups
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) DeleteGroupByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteGroupByID, id)
+	return err
+}
+
+const getGroupByID = `-- name: GetGroupByID :one
+SELECT
+	id, name, organization_id, avatar_url, quota_allowance
+FROM
+	groups
+WHERE
+	id = $1
+LIMIT
+	1
+`
+
+func (q *sqlQuerier) GetGroupByID(ctx context.Context, id uuid.UUID) (Group, error) {
+	row := q.db.QueryRowContext(ctx, getGroupByID, id)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OrganizationID,
+		&i.AvatarURL,
+		&i.QuotaAllowance,
+	)
+	return i, err
+}
+
+const getGroupByOrgAndName = `-- name: GetGroupByOrgAndName :one
+SELECT
+	id, name, organization_id, avatar_url, quota_allowance
+FROM
+	groups
+WHERE
+	organization_id = $1
+AND
+	name = $2
+LIMIT
+	1
+`
+
+type GetGroupByOrgAndNameParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Name           string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) GetGroupByOrgAndName(ctx context.Context, arg GetGroupByOrgAndNameParams) (Group, error) {
+	row := q.db.QueryRowContext(ctx, getGroupByOrgAndName, arg.OrganizationID, arg.Name)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OrganizationID,
+		&i.AvatarURL,
+		&i.QuotaAllowance,
+	)
+	return i, err
+}
+
+const getGroupsByOrganizationID = `-- name: GetGroupsByOrganizationID :many
+SELECT
+	id, name, organization_id, avatar_url, quota_allowance
+FROM
+	groups
+WHERE
+	organization_id = $1
+AND
+	id != $1
+`
+
+func (q *sqlQuerier) GetGroupsByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]Group, error) {
+	rows, err := q.db.QueryContext(ctx, getGroupsByOrganizationID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Group
+	for rows.Next() {
+		var i Group
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.OrganizationID,
+			&i.AvatarURL,
+			&i.QuotaAllowance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertAllUsersGroup = `-- name: InsertAllUsersGroup :one
+INSERT INTO groups (
+	id,
+	name,
+	organization_id
+)
+VALUES
+	($1, 'Everyone', $1) RETURNING id, name, organization_id, avatar_url, quota_allowance
+`
+
+// We use the organization_id as the id
+// for simplicity since all users is
+// every member of the org.
+func (q *sqlQuerier) InsertAllUsersGroup(ctx context.Context, organizationID uuid.UUID) (Group, error) {
+	row := q.db.QueryRowContext(ctx, insertAllUsersGroup, organizationID)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OrganizationID,
+		&i.AvatarURL,
+		&i.QuotaAllowance,
+	)
+	return i, err
+}
+
+const insertGroup = `-- name: InsertGroup :one
+INSERT INTO groups (
+	id,
+	name,
+	organization_id,
+	avatar_url,
+	quota_allowance
+)
+VALUES
+	($1, $2, $3, $4, $5) RETURNING id, name, organization_id, avatar_url, quota_allowance
+`
+
+type InsertGroupParams struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	Name           string    `db:"name" json:"name"`
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	AvatarURL      string    `db:"avatar_url" json:"avatar_url"`
+	QuotaAllowance int32     `db:"quota_allowance" json:"quota_allowance"`
+}
+
+func (q *sqlQuerier) InsertGroup(ctx context.Context, arg InsertGroupParams) (Group, error) {
+	row := q.db.QueryRowContext(ctx, insertGroup,
+		arg.ID,
+		arg.Name,
+		arg.OrganizationID,
+		arg.AvatarURL,
+		arg.QuotaAllowance,
+	)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OrganizationID,
+		&i.AvatarURL,
+		&i.QuotaAllowance,
+	)
+	return i, err
+}
+
+const updateGroupByID = `-- name: UpdateGroupByID :one
+UPDATE
+	groups
+SET
+	name = $1,
+	avatar_url = $2,
+	quota_allowance = $3
+WHERE
+	id = $4
+RETURNING id, name, organization_id, avatar_url, quota_allowance
+`
+
+type UpdateGroupByIDParams struct {
+	Name           string    `db:"name" json:"name"`
+	AvatarURL      string    `db:"avatar_url" json:"avatar_url"`
+	QuotaAllowance int32     `db:"quota_allowance" json:"quota_allowance"`
+	ID             uuid.UUID `db:"id" json:"id"`
+}
+
+func (q *sqlQuerier) UpdateGroupByID(ctx context.Context, arg UpdateGroupByIDParams) (Group, error) {
+	row := q.db.QueryRowContext(ctx, updateGroupByID,
+		arg.Name,
+		arg.AvatarURL,
+		arg.QuotaAllowance,
+		arg.ID,
+	)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.OrganizationID,
+		&i.AvatarURL,
+		&i.QuotaAllowance,
+	)
+	return i, err
+}
+
+const getTemplateActiveWorkspaces = `-- name: GetTemplateActiveWorkspaces :many
+SELECT
+	template_id,
+	(created_at AT TIME ZONE 'UTC')::date AS date,
+	COUNT(DISTINCT workspace_id)::bigint AS workspaces
+FROM
+	workspace_agent_stats
+WHERE
+	created_at >= $1 :: timestamptz
+GROUP BY
+	template_id, date
+ORDER BY
+	date ASC
+`
+
+type GetTemplateActiveWorkspacesRow struct {
+	TemplateID uuid.UUID `db:"template_id" json:"template_id"`
+	Date       time.Time `db:"date" json:"date"`
+	Workspaces int64     `db:"workspaces" json:"workspaces"`
+}
+
+// Returns the number of workspaces of each template that reported agent stats
+// on each day since the given time.
+func (q *sqlQuerier) GetTemplateActiveWorkspaces(ctx context.Context, startTime time.Time) ([]GetTemplateActiveWorkspacesRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateActiveWorkspaces, startTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTemplateActiveWorkspacesRow
+	for rows.Next() {
+		var i GetTemplateActiveWorkspacesRow
+		if err := rows.Scan(&i.TemplateID, &i.Date, &i.Workspaces); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTemplateBuildInsights = `-- name: GetTemplateBuildInsights :many
+SELECT
+	template_versions.template_id,
+	(workspace_builds.created_at AT TIME ZONE 'UTC')::date AS date,
+	workspace_builds.transition,
+	EXTRACT(EPOCH FROM (provisioner_jobs.completed_at - provisioner_jobs.started_at))::FLOAT AS exec_time_sec,
+	(provisioner_jobs.error IS NOT NULL AND provisioner_jobs.error != '')::boolean AS failed
+FROM
+	workspace_builds
+JOIN
+	template_versions ON workspace_builds.template_version_id = template_versions.id
+JOIN
+	provisioner_jobs ON workspace_builds.job_id = provisioner_jobs.id
+WHERE
+	template_versions.template_id IS NOT NULL
+	AND provisioner_jobs.started_at IS NOT NULL
+	AND provisioner_jobs.completed_at IS NOT NULL
+	AND provisioner_jobs.canceled_at IS NULL
+	AND workspace_builds.created_at >= $1 :: timestamptz
+ORDER BY
+	workspace_builds.created_at ASC
+`
+
+type GetTemplateBuildInsightsRow struct {
+	TemplateID  uuid.NullUUID       `db:"template_id" json:"template_id"`
+	Date        time.Time           `db:"date" json:"date"`
+	Transition  WorkspaceTransition `db:"transition" json:"transition"`
+	ExecTimeSec float64             `db:"exec_time_sec" json:"exec_time_sec"`
+	Failed      bool                `db:"failed" json:"failed"`
+}
+
+// Returns the workspace builds of every template that ran to completion since
+// the given time, with how long they took and whether they failed. Canceled
+// builds are left out.
+func (q *sqlQuerier) GetTemplateBuildInsights(ctx context.Context, startTime time.Time) ([]GetTemplateBuildInsightsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateBuildInsights, startTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTemplateBuildInsightsRow
+	for rows.Next() {
+		var i GetTemplateBuildInsightsRow
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.Date,
+			&i.Transition,
+			&i.ExecTimeSec,
+			&i.Failed,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTemplateConnectionInsights = `-- name: GetTemplateConnectionInsights :many
+SELECT
+	workspace_agent_stats.template_id,
+	(workspace_agent_stats.created_at AT TIME ZONE 'UTC')::date AS date,
+	ports.key :: text AS port,
+	SUM(ports.value :: bigint)::bigint AS connections
+FROM
+	workspace_agent_stats,
+	jsonb_each_text(workspace_agent_stats.connections_by_port) AS ports
+WHERE
+	workspace_agent_stats.created_at >= $1 :: timestamptz
+GROUP BY
+	workspace_agent_stats.template_id, date, ports.key
+ORDER BY
+	date ASC
+`
+
+type GetTemplateConnectionInsightsRow struct {
+	TemplateID  uuid.UUID `db:"template_id" json:"template_id"`
+	Date        time.Time `db:"date" json:"date"`
+	Port        string    `db:"port" json:"port"`
+	Connections int64     `db:"connections" json:"connections"`
+}
+
+// Sums the connections reported by the agents of every template on each day
+// since the given time, by the agent port they were made to.
+func (q *sqlQuerier) GetTemplateConnectionInsights(ctx context.Context, startTime time.Time) ([]GetTemplateConnectionInsightsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateConnectionInsights, startTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTemplateConnectionInsightsRow
+	for rows.Next() {
+		var i GetTemplateConnectionInsightsRow
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.Date,
+			&i.Port,
+			&i.Connections,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTemplateInsightsApps = `-- name: GetTemplateInsightsApps :many
+SELECT DISTINCT ON (workspaces.template_id, workspace_apps.slug)
+	workspaces.template_id,
+	workspace_apps.slug,
+	workspace_apps.display_name,
+	workspace_apps.icon,
+	workspace_apps.url
+FROM
+	workspace_apps
+JOIN
+	workspace_agents ON workspace_agents.id = workspace_apps.agent_id
+JOIN
+	workspace_resources ON workspace_resources.id = workspace_agents.resource_id
+JOIN
+	workspace_builds ON workspace_builds.job_id = workspace_resources.job_id
+JOIN
+	workspaces ON workspaces.id = workspace_builds.workspace_id
+WHERE
+	workspace_apps.url IS NOT NULL
+	AND NOT workspace_apps.external
+	AND workspace_builds.created_at >= $1 :: timestamptz
+ORDER BY
+	workspaces.template_id, workspace_apps.slug, workspace_apps.created_at DESC
+`
+
+type GetTemplateInsightsAppsRow struct {
+	TemplateID  uuid.UUID      `db:"template_id" json:"template_id"`
+	Slug        string         `db:"slug" json:"slug"`
+	DisplayName string         `db:"display_name" json:"display_name"`
+	Icon        string         `db:"icon" json:"icon"`
+	Url         sql.NullString `db:"url" json:"url"`
+}
+
+// Returns the apps served by the agents of every template's workspaces built
+// since the given time, so connections can be matched to apps by port. Only
+// the latest definition of each app slug is returned.
+func (q *sqlQuerier) GetTemplateInsightsApps(ctx context.Context, startTime time.Time) ([]GetTemplateInsightsAppsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateInsightsApps, startTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTemplateInsightsAppsRow
+	for rows.Next() {
+		var i GetTemplateInsightsAppsRow
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.Slug,
+			&i.DisplayName,
+			&i.Icon,
+			&i.Url,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTemplateVersionAdoption = `-- name: GetTemplateVersionAdoption :many
+WITH latest_builds AS (
+	SELECT DISTINCT ON (workspace_id)
+		workspace_id,
+		template_version_id
+	FROM
+		workspace_builds
+	ORDER BY
+		workspace_id, build_number DESC
+)
+SELECT
+	template_versions.template_id,
+	template_versions.id AS template_version_id,
+	template_versions.name,
+	COUNT(*)::bigint AS workspaces
+FROM
+	latest_builds
+JOIN
+	workspaces ON workspaces.id = latest_builds.workspace_id AND NOT workspaces.deleted
+JOIN
+	template_versions ON template_versions.id = latest_builds.template_version_id
+WHERE
+	template_versions.template_id IS NOT NULL
+GROUP BY
+	template_versions.template_id, template_versions.id, template_versions.name
+ORDER BY
+	workspaces DESC
+`
+
+type GetTemplateVersionAdoptionRow struct {
+	TemplateID        uuid.NullUUID `db:"template_id" json:"template_id"`
+	TemplateVersionID uuid.UUID     `db:"template_version_id" json:"template_version_id"`
+	Name              string        `db:"name" json:"name"`
+	Workspaces        int64         `db:"workspaces" json:"workspaces"`
+}
+
+// Returns how many non-deleted workspaces of every template have their latest
+// build on each template version.
+func (q *sqlQuerier) GetTemplateVersionAdoption(ctx context.Context) ([]GetTemplateVersionAdoptionRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionAdoption)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTemplateVersionAdoptionRow
+	for rows.Next() {
+		var i GetTemplateVersionAdoptionRow
+		if err := rows.Scan(
+			&i.TemplateID,
+			&i.TemplateVersionID,
+			&i.Name,
+			&i.Workspaces,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserActivityInsights = `-- name: GetUserActivityInsights :many
+WITH connections AS (
+	SELECT
+		user_id,
+		COUNT(DISTINCT FLOOR(EXTRACT(EPOCH FROM created_at) / $1 :: bigint))::bigint * $1 :: bigint AS connected_seconds
+	FROM
+		workspace_agent_stats
+	WHERE
+		connection_count > 0
+		AND created_at >= $2 :: timestamptz
+		AND created_at < $3 :: timestamptz
+	GROUP BY
+		user_id
+), builds AS (
+	SELECT
+		initiator_id AS user_id,
+		COUNT(*)::bigint AS builds
+	FROM
+		workspace_builds
+	WHERE
+		created_at >= $2 :: timestamptz
+		AND created_at < $3 :: timestamptz
+	GROUP BY
+		initiator_id
+), runs AS (
+	SELECT
+		workspaces.owner_id,
+		workspace_builds.transition,
+		workspace_builds.daily_cost,
+		provisioner_jobs.completed_at AS started_at,
+		provisioner_jobs.error,
+		LEAD(workspace_builds.created_at) OVER (PARTITION BY workspace_builds.workspace_id ORDER BY workspace_builds.build_number) AS stopped_at
+	FROM
+		workspace_builds
+	JOIN
+		workspaces ON workspaces.id = workspace_builds.workspace_id
+	JOIN
+		provisioner_jobs ON provisioner_jobs.id = workspace_builds.job_id
+	-- Only the builds in the window, and the latest build of each workspace
+	-- before it, can have been running in the window. Later builds only end
+	-- runs after the window, which is the same as not ending them.
+	WHERE
+		workspace_builds.created_at < $3 :: timestamptz
+		AND (
+			workspace_builds.created_at >= $2 :: timestamptz
+			OR workspace_builds.build_number = (
+				SELECT
+					MAX(earlier.build_number)
+				FROM
+					workspace_builds AS earlier
+				WHERE
+					earlier.workspace_id = workspace_builds.workspace_id
+					AND earlier.created_at < $2 :: timestamptz
+			)
+		)
+), costs AS (
+	SELECT
+		owner_id AS user_id,
+		SUM(daily_cost * EXTRACT(EPOCH FROM (
+			LEAST(COALESCE(stopped_at, NOW()), $3 :: timestamptz) - GREATEST(started_at, $2 :: timestamptz)
+		)) / 86400)::float AS cost
+	FROM
+		runs
+	WHERE
+		transition = 'start'
+		AND daily_cost > 0
+		AND started_at IS NOT NULL
+		AND (error IS NULL OR error = '')
+		AND started_at < $3 :: timestamptz
+		AND COALESCE(stopped_at, NOW()) > $2 :: timestamptz
+	GROUP BY
+		owner_id
+), owned AS (
+	SELECT
+		owner_id AS user_id,
+		COUNT(*)::bigint AS workspaces
+	FROM
+		workspaces
+	WHERE
+		NOT deleted
+	GROUP BY
+		owner_id
+)
+SELECT
+	users.id AS user_id,
+	users.username,
+	users.email,
+	users.status,
+	users.last_seen_at,
+	COALESCE(owned.workspaces, 0)::bigint AS workspaces,
+	COALESCE(builds.builds, 0)::bigint AS builds,
+	COALESCE(connections.connected_seconds, 0)::bigint AS connected_seconds,
+	COALESCE(costs.cost, 0)::float AS cost
+FROM
+	users
+LEFT JOIN
+	owned ON owned.user_id = users.id
+LEFT JOIN
+	builds ON builds.user_id = users.id
+LEFT JOIN
+	connections ON connections.user_id = users.id
+LEFT JOIN
+	costs ON costs.user_id = users.id
+WHERE
+	NOT users.deleted
+ORDER BY
+	users.username ASC
+`
+
+type GetUserActivityInsightsParams struct {
+	ReportIntervalSeconds int64     `db:"report_interval_seconds" json:"report_interval_seconds"`
+	StartTime             time.Time `db:"start_time" json:"start_time"`
+	EndTime               time.Time `db:"end_time" json:"end_time"`
+}
+
+type GetUserActivityInsightsRow struct {
+	UserID           uuid.UUID  `db:"user_id" json:"user_id"`
+	Username         string     `db:"username" json:"username"`
+	Email            string     `db:"email" json:"email"`
+	Status           UserStatus `db:"status" json:"status"`
+	LastSeenAt       time.Time  `db:"last_seen_at" json:"last_seen_at"`
+	Workspaces       int64      `db:"workspaces" json:"workspaces"`
+	Builds           int64      `db:"builds" json:"builds"`
+	ConnectedSeconds int64      `db:"connected_seconds" json:"connected_seconds"`
+	Cost             float64    `db:"cost" json:"cost"`
+}
+
+// Returns the activity of every user between the given times. Connected time
+// is counted in buckets of the agent stats report interval, so connections to
+// several workspaces at once are only counted once. The cost accrued is the
+// daily cost of each workspace prorated over the time it was running.
+func (q *sqlQuerier) GetUserActivityInsights(ctx context.Context, arg GetUserActivityInsightsParams) ([]GetUserActivityInsightsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUserActivityInsights, arg.ReportIntervalSeconds, arg.StartTime, arg.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserActivityInsightsRow
+	for rows.Next() {
+		var i GetUserActivityInsightsRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Username,
+			&i.Email,
+			&i.Status,
+			&i.LastSeenAt,
+			&i.Workspaces,
+			&i.Builds,
+			&i.ConnectedSeconds,
+			&i.Cost,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteLicense = `-- name: DeleteLicense :one
+DELETE
+FROM licenses
+WHERE id = $1
+RETURNING id
+`
+
+func (q *sqlQuerier) DeleteLicense(ctx context.Context, id int32) (int32, error) {
+	row := q.db.QueryRowContext(ctx, deleteLicense, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
+const getLicenseByID = `-- name: GetLicenseByID :one
+SELECT
+	id, uploaded_at, jwt, exp, uuid
+FROM
+	licenses
+WHERE
+	id = $1
+LIMIT
+	1
+`
+
+func (q *sqlQuerier) GetLicenseByID(ctx context.Context, id int32) (License, error) {
+	row := q.db.QueryRowContext(ctx, getLicenseByID, id)
+	var i License
+	err := row.Scan(
+		&i.ID,
+		&i.UploadedAt,
+		&i.JWT,
+		&i.Exp,
+		&i.UUID,
+	)
+	return i, err
+}
+
+const getLicenses = `-- name: GetLicenses :many
+SELECT id, uploaded_at, jwt, exp, uuid
+FROM licenses
+ORDER BY (id)
+`
+
+func (q *sqlQuerier) GetLicenses(ctx context.Context) ([]License, error) {
+	rows, err := q.db.QueryContext(ctx, getLicenses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []License
+	for rows.Next() {
+		var i License
+		if err := rows.Scan(
+			&i.ID,
+			&i.UploadedAt,
+			&i.JWT,
+			&i.Exp,
+			&i.UUID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUnexpiredLicenses = `-- name: GetUnexpiredLicenses :many
+SELECT id, uploaded_at, jwt, exp, uuid
+FROM licenses
+WHERE exp > NOW()
+ORDER BY (id)
+`
+
+func (q *sqlQuerier) GetUnexpiredLicenses(ctx context.Context) ([]License, error) {
+	rows, err := q.db.QueryContext(ctx, getUnexpiredLicenses)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []License
+	for rows.Next() {
+		var i License
+		if err := rows.Scan(
+			&i.ID,
+			&i.UploadedAt,
+			&i.JWT,
+			&i.Exp,
+			&i.UUID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertLicense = `-- name: InsertLicense :one
+INSERT INTO
+	licenses (
+	uploaded_at,
+	jwt,
+	exp,
+	uuid
+)
+VALUES
+	($1, $2, $3, $4) RETURNING id, uploaded_at, jwt, exp, uuid
+`
+
+type InsertLicenseParams struct {
+	UploadedAt time.Time `db:"uploaded_at" json:"uploaded_at"`
+	JWT        string    `db:"jwt" json:"jwt"`
+	Exp        time.Time `db:"exp" json:"exp"`
+	UUID       uuid.UUID `db:"uuid" json:"uuid"`
+}
+
+func (q *sqlQuerier) InsertLicense(ctx context.Context, arg InsertLicenseParams) (License, error) {
+	row := q.db.QueryRowContext(ctx, insertLicense,
+		arg.UploadedAt,
+		arg.JWT,
+		arg.Exp,
+		arg.UUID,
+	)
+	var i License
+	err := row.Scan(
+		&i.ID,
+		&i.UploadedAt,
+		&i.JWT,
+		&i.Exp,
+		&i.UUID,
+	)
+	return i, err
+}
+
+const acquireFileHashLock = `-- name: AcquireFileHashLock :exec
+SELECT pg_advisory_xact_lock(hashtext('files'), hashtext($1 :: text))
+`
+
+// Acquires a lock on the contents stored for a hash, which is released when
+// the transaction ends. Files are inserted, and their contents deleted, while
+// holding it, so contents aren't deleted while a file that refers to them is
+// being inserted. The two key form doesn't share IDs with TryAcquireLock.
+func (q *sqlQuerier) AcquireFileHashLock(ctx context.Context, hash string) error {
+	_, err := q.db.ExecContext(ctx, acquireFileHashLock, hash)
+	return err
+}
+
+const tryAcquireLock = `-- name: TryAcquireLock :one
+SELECT pg_try_advisory_xact_lock($1)
+`
+
+// Acquires a lock that's released when the transaction ends, without
+// blocking. Returns whether the lock was acquired. Replicas use it to
+// ensure only one of them runs a background task at a time.
+func (q *sqlQuerier) TryAcquireLock(ctx context.Context, pgTryAdvisoryXactLock int64) (bool, error) {
+	row := q.db.QueryRowContext(ctx, tryAcquireLock, pgTryAdvisoryXactLock)
+	var pg_try_advisory_xact_lock bool
+	err := row.Scan(&pg_try_advisory_xact_lock)
+	return pg_try_advisory_xact_lock, err
+}
+
+const deleteNotificationMessageByID = `-- name: DeleteNotificationMessageByID :exec
+DELETE FROM
+	notification_messages
+WHERE
+	id = $1
+`
+
+// Releases the claim on a message that could not be sent, so it is
+// retried.
+func (q *sqlQuerier) DeleteNotificationMessageByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteNotificationMessageByID, id)
+	return err
+}
+
+const deleteOldNotificationMessages = `-- name: DeleteOldNotificationMessages :exec
+DELETE FROM
+	notification_messages
+WHERE
+	created_at < $1 :: timestamptz
+`
+
+// Messages are only kept to dedupe notifications that several replicas
+// decide to send at about the same time.
+func (q *sqlQuerier) DeleteOldNotificationMessages(ctx context.Context, before time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteOldNotificationMessages, before)
+	return err
+}
+
+const getNotificationPreferencesByUserID = `-- name: GetNotificationPreferencesByUserID :many
+SELECT
+	user_id, kind, disabled, updated_at
+FROM
+	notification_preferences
+WHERE
+	user_id = $1
+ORDER BY
+	kind ASC
+`
+
+func (q *sqlQuerier) GetNotificationPreferencesByUserID(ctx context.Context, userID uuid.UUID) ([]NotificationPreference, error) {
+	rows, err := q.db.QueryContext(ctx, getNotificationPreferencesByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NotificationPreference
+	for rows.Next() {
+		var i NotificationPreference
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Kind,
+			&i.Disabled,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertNotificationMessage = `-- name: InsertNotificationMessage :one
+INSERT INTO
+	notification_messages (
+		id,
+		user_id,
+		kind,
+		dedupe_key,
+		subject,
+		created_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6)
+ON CONFLICT (dedupe_key) DO NOTHING
+RETURNING id, user_id, kind, dedupe_key, subject, created_at
+`
+
+type InsertNotificationMessageParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	Kind      string    `db:"kind" json:"kind"`
+	DedupeKey string    `db:"dedupe_key" json:"dedupe_key"`
+	Subject   string    `db:"subject" json:"subject"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Inserting a message claims it. If another replica has already claimed
+// the dedupe key, no rows are returned.
+func (q *sqlQuerier) InsertNotificationMessage(ctx context.Context, arg InsertNotificationMessageParams) (NotificationMessage, error) {
+	row := q.db.QueryRowContext(ctx, insertNotificationMessage,
+		arg.ID,
+		arg.UserID,
+		arg.Kind,
+		arg.DedupeKey,
+		arg.Subject,
+		arg.CreatedAt,
+	)
+	var i NotificationMessage
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Kind,
+		&i.DedupeKey,
+		&i.Subject,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const upsertNotificationPreference = `-- name: UpsertNotificationPreference :one
+INSERT INTO
+	notification_preferences (
+		user_id,
+		kind,
+		disabled,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4)
+ON CONFLICT (user_id, kind) DO UPDATE
+SET
+	disabled = $3,
+	updated_at = $4
+RETURNING user_id, kind, disabled, updated_at
+`
+
+type UpsertNotificationPreferenceParams struct {
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	Kind      string    `db:"kind" json:"kind"`
+	Disabled  bool      `db:"disabled" json:"disabled"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpsertNotificationPreference(ctx context.Context, arg UpsertNotificationPreferenceParams) (NotificationPreference, error) {
+	row := q.db.QueryRowContext(ctx, upsertNotificationPreference,
+		arg.UserID,
+		arg.Kind,
+		arg.Disabled,
+		arg.UpdatedAt,
+	)
+	var i NotificationPreference
+	err := row.Scan(
+		&i.UserID,
+		&i.Kind,
+		&i.Disabled,
+		&i.UpdatedAt,
+	)
 	return i, err
 }
 
-const getFileByHashAndCreator = `-- name: GetFileByHashAndCreator :one
+const getOrganizationIDsByMemberIDs = `-- name: GetOrganizationIDsByMemberIDs :many
+SELECT
+    user_id, array_agg(organization_id) :: uuid [ ] AS "organization_IDs"
+FROM
+    organization_members
+WHERE
+    user_id = ANY($1 :: uuid [ ])
+GROUP BY
+    user_id
+`
+
+type GetOrganizationIDsByMemberIDsRow struct {
+	UserID          uuid.UUID   `db:"user_id" json:"user_id"`
+	OrganizationIDs []uuid.UUID `db:"organization_IDs" json:"organization_IDs"`
+}
+
+func (q *sqlQuerier) GetOrganizationIDsByMemberIDs(ctx context.Context, ids []uuid.UUID) ([]GetOrganizationIDsByMemberIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationIDsByMemberIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetOrganizationIDsByMemberIDsRow
+	for rows.Next() {
+		var i GetOrganizationIDsByMemberIDsRow
+		if err := rows.Scan(&i.UserID, pq.Array(&i.OrganizationIDs)); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOrganizationMemberByUserID = `-- name: GetOrganizationMemberByUserID :one
 SELECT
-	hash, created_at, created_by, mimetype, data, id
+	user_id, organization_id, created_at, updated_at, roles
 FROM
-	files
+	organization_members
 WHERE
-	hash = $1
-AND
-	created_by = $2
+	organization_id = $1
+	AND user_id = $2
 LIMIT
 	1
 `
 
-type GetFileByHashAndCreatorParams struct {
-	Hash      string    `db:"hash" json:"hash"`
-	CreatedBy uuid.UUID `db:"created_by" json:"created_by"`
+type GetOrganizationMemberByUserIDParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	UserID         uuid.UUID `db:"user_id" json:"user_id"`
 }
 
-func (q *sqlQuerier) GetFileByHashAndCreator(ctx context.Context, arg GetFileByHashAndCreatorParams) (File, error) {
-	row := q.db.QueryRowContext(ctx, getFileByHashAndCreator, arg.Hash, arg.CreatedBy)
-	var i File
+func (q *sqlQuerier) GetOrganizationMemberByUserID(ctx context.Context, arg GetOrganizationMemberByUserIDParams) (OrganizationMember, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationMemberByUserID, arg.OrganizationID, arg.UserID)
+	var i OrganizationMember
 	err := row.Scan(
-		&i.Hash,
+		&i.UserID,
+		&i.OrganizationID,
 		&i.CreatedAt,
-		&i.CreatedBy,
-		&i.Mimetype,
-		&i.Data,
-		&i.ID,
+		&i.UpdatedAt,
+		pq.Array(&i.Roles),
 	)
 	return i, err
 }
 
-const getFileByID = `-- name: GetFileByID :one
+const getOrganizationMembershipsByUserID = `-- name: GetOrganizationMembershipsByUserID :many
 SELECT
-	hash, created_at, created_by, mimetype, data, id
+	user_id, organization_id, created_at, updated_at, roles
 FROM
-	files
+	organization_members
 WHERE
-	id = $1
-LIMIT
-	1
+  user_id = $1
 `
 
-func (q *sqlQuerier) GetFileByID(ctx context.Context, id uuid.UUID) (File, error) {
-	row := q.db.QueryRowContext(ctx, getFileByID, id)
-	var i File
-	err := row.Scan(
-		&i.Hash,
-		&i.CreatedAt,
-		&i.CreatedBy,
-		&i.Mimetype,
-		&i.Data,
-		&i.ID,
-	)
-	return i, err
+func (q *sqlQuerier) GetOrganizationMembershipsByUserID(ctx context.Context, userID uuid.UUID) ([]OrganizationMember, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationMembershipsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []OrganizationMember
+	for rows.Next() {
+		var i OrganizationMember
+		if err := rows.Scan(
+			&i.UserID,
+			&i.OrganizationID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			pq.Array(&i.Roles),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const insertFile = `-- name: InsertFile :one
+const insertOrganizationMember = `-- name: InsertOrganizationMember :one
 INSERT INTO
-	files (id, hash, created_at, created_by, mimetype, "data")
-VALUES
-	($1, $2, $3, $4, $5, $6) RETURNING hash, created_at, created_by, mimetype, data, id
-`
-
-type InsertFileParams struct {
-	ID        uuid.UUID `db:"id" json:"id"`
-	Hash      string    `db:"hash" json:"hash"`
-	CreatedAt time.Time `db:"created_at" json:"created_at"`
-	CreatedBy uuid.UUID `db:"created_by" json:"created_by"`
-	Mimetype  string    `db:"mimetype" json:"mimetype"`
-	Data      []byte    `db:"data" json:"data"`
-}
-
-func (q *sqlQuerier) InsertFile(ctx context.Context, arg InsertFileParams) (File, error) {
-	row := q.db.QueryRowContext(ctx, insertFile,
-		arg.ID,
-		arg.Hash,
-		arg.CreatedAt,
-		arg.CreatedBy,
-		arg.Mimetype,
-		arg.Data,
-	)
-	var i File
-	err := row.Scan(
-		&i.Hash,
-		&i.CreatedAt,
-		&i.CreatedBy,
-		&i.Mimetype,
-		&i.Data,
-		&i.ID,
-	)
-	return i, err
-}
-
-const getGitAuthLink = `-- name: GetGitAuthLink :one
-SELECT provider_id, user_id, created_at, updated_at, oauth_access_token, oauth_refresh_token, oauth_expiry FROM git_auth_links WHERE provider_id = $1 AND user_id = $2
-`
-
-type GetGitAuthLinkParams struct {
-	ProviderID string    `db:"provider_id" json:"provider_id"`
-	UserID     uuid.UUID `db:"user_id" json:"user_id"`
-}
-
-func (q *sqlQuerier) GetGitAuthLink(ctx context.Context, arg GetGitAuthLinkParams) (GitAuthLink, error) {
-	row := q.db.QueryRowContext(ctx, getGitAuthLink, arg.ProviderID, arg.UserID)
-	var i GitAuthLink
-	err := row.Scan(
-		&i.ProviderID,
-		&i.UserID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.OAuthAccessToken,
-		&i.OAuthRefreshToken,
-		&i.OAuthExpiry,
+	organization_members (
+		organization_id,
+		user_id,
+		created_at,
+		updated_at,
+		roles
 	)
-	return i, err
-}
-
-const insertGitAuthLink = `-- name: InsertGitAuthLink :one
-INSERT INTO git_auth_links (
-    provider_id,
-    user_id,
-    created_at,
-    updated_at,
-    oauth_access_token,
-    oauth_refresh_token,
-    oauth_expiry
-) VALUES (
-    $1,
-    $2,
-    $3,
-    $4,
-    $5,
-    $6,
-    $7
-) RETURNING provider_id, user_id, created_at, updated_at, oauth_access_token, oauth_refresh_token, oauth_expiry
+VALUES
+	($1, $2, $3, $4, $5) RETURNING user_id, organization_id, created_at, updated_at, roles
 `
 
-type InsertGitAuthLinkParams struct {
-	ProviderID        string    `db:"provider_id" json:"provider_id"`
-	UserID            uuid.UUID `db:"user_id" json:"user_id"`
-	CreatedAt         time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
-	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
-	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
-	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
+type InsertOrganizationMemberParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	UserID         uuid.UUID `db:"user_id" json:"user_id"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
+	Roles          []string  `db:"roles" json:"roles"`
 }
 
-func (q *sqlQuerier) InsertGitAuthLink(ctx context.Context, arg InsertGitAuthLinkParams) (GitAuthLink, error) {
-	row := q.db.QueryRowContext(ctx, insertGitAuthLink,
-		arg.ProviderID,
+func (q *sqlQuerier) InsertOrganizationMember(ctx context.Context, arg InsertOrganizationMemberParams) (OrganizationMember, error) {
+	row := q.db.QueryRowContext(ctx, insertOrganizationMember,
+		arg.OrganizationID,
 		arg.UserID,
 		arg.CreatedAt,
 		arg.UpdatedAt,
-		arg.OAuthAccessToken,
-		arg.OAuthRefreshToken,
-		arg.OAuthExpiry,
+		pq.Array(arg.Roles),
 	)
-	var i GitAuthLink
+	var i OrganizationMember
 	err := row.Scan(
-		&i.ProviderID,
 		&i.UserID,
+		&i.OrganizationID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.OAuthAccessToken,
-		&i.OAuthRefreshToken,
-		&i.OAuthExpiry,
+		pq.Array(&i.Roles),
 	)
 	return i, err
 }
 
-const updateGitAuthLink = `-- name: UpdateGitAuthLink :one
-UPDATE git_auth_links SET
-    updated_at = $3,
-    oauth_access_token = $4,
-    oauth_refresh_token = $5,
-    oauth_expiry = $6
-WHERE provider_id = $1 AND user_id = $2 RETURNING provider_id, user_id, created_at, updated_at, oauth_access_token, oauth_refresh_token, oauth_expiry
+const updateMemberRoles = `-- name: UpdateMemberRoles :one
+UPDATE
+	organization_members
+SET
+	-- Remove all duplicates from the roles.
+	roles = ARRAY(SELECT DISTINCT UNNEST($1 :: text[]))
+WHERE
+	user_id = $2
+	AND organization_id = $3
+RETURNING user_id, organization_id, created_at, updated_at, roles
 `
 
-type UpdateGitAuthLinkParams struct {
-	ProviderID        string    `db:"provider_id" json:"provider_id"`
-	UserID            uuid.UUID `db:"user_id" json:"user_id"`
-	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
-	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
-	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
-	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
+type UpdateMemberRolesParams struct {
+	GrantedRoles []string  `db:"granted_roles" json:"granted_roles"`
+	UserID       uuid.UUID `db:"user_id" json:"user_id"`
+	OrgID        uuid.UUID `db:"org_id" json:"org_id"`
 }
 
-func (q *sqlQuerier) UpdateGitAuthLink(ctx context.Context, arg UpdateGitAuthLinkParams) (GitAuthLink, error) {
-	row := q.db.QueryRowContext(ctx, updateGitAuthLink,
-		arg.ProviderID,
-		arg.UserID,
-		arg.UpdatedAt,
-		arg.OAuthAccessToken,
-		arg.OAuthRefreshToken,
-		arg.OAuthExpiry,
-	)
-	var i GitAuthLink
+func (q *sqlQuerier) UpdateMemberRoles(ctx context.Context, arg UpdateMemberRolesParams) (OrganizationMember, error) {
+	row := q.db.QueryRowContext(ctx, updateMemberRoles, pq.Array(arg.GrantedRoles), arg.UserID, arg.OrgID)
+	var i OrganizationMember
 	err := row.Scan(
-		&i.ProviderID,
 		&i.UserID,
+		&i.OrganizationID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.OAuthAccessToken,
-		&i.OAuthRefreshToken,
-		&i.OAuthExpiry,
+		pq.Array(&i.Roles),
 	)
 	return i, err
 }
 
-const deleteGitSSHKey = `-- name: DeleteGitSSHKey :exec
-DELETE FROM
-	gitsshkeys
+const getOrganizationByID = `-- name: GetOrganizationByID :one
+SELECT
+	id, name, description, created_at, updated_at
+FROM
+	organizations
 WHERE
-	user_id = $1
+	id = $1
 `
 
-func (q *sqlQuerier) DeleteGitSSHKey(ctx context.Context, userID uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteGitSSHKey, userID)
-	return err
+func (q *sqlQuerier) GetOrganizationByID(ctx context.Context, id uuid.UUID) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationByID, id)
+	var i Organization
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
 }
 
-const getGitSSHKey = `-- name: GetGitSSHKey :one
+const getOrganizationByName = `-- name: GetOrganizationByName :one
 SELECT
-	user_id, created_at, updated_at, private_key, public_key
+	id, name, description, created_at, updated_at
 FROM
-	gitsshkeys
+	organizations
 WHERE
-	user_id = $1
+	LOWER("name") = LOWER($1)
+LIMIT
+	1
 `
 
-func (q *sqlQuerier) GetGitSSHKey(ctx context.Context, userID uuid.UUID) (GitSSHKey, error) {
-	row := q.db.QueryRowContext(ctx, getGitSSHKey, userID)
-	var i GitSSHKey
+func (q *sqlQuerier) GetOrganizationByName(ctx context.Context, name string) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, getOrganizationByName, name)
+	var i Organization
 	err := row.Scan(
-		&i.UserID,
+		&i.ID,
+		&i.Name,
+		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.PrivateKey,
-		&i.PublicKey,
 	)
 	return i, err
 }
 
-const insertGitSSHKey = `-- name: InsertGitSSHKey :one
-INSERT INTO
-	gitsshkeys (
-		user_id,
-		created_at,
-		updated_at,
-		private_key,
-		public_key
-	)
-VALUES
-	($1, $2, $3, $4, $5) RETURNING user_id, created_at, updated_at, private_key, public_key
+const getOrganizations = `-- name: GetOrganizations :many
+SELECT
+	id, name, description, created_at, updated_at
+FROM
+	organizations
 `
 
-type InsertGitSSHKeyParams struct {
-	UserID     uuid.UUID `db:"user_id" json:"user_id"`
-	CreatedAt  time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
-	PrivateKey string    `db:"private_key" json:"private_key"`
-	PublicKey  string    `db:"public_key" json:"public_key"`
+func (q *sqlQuerier) GetOrganizations(ctx context.Context) ([]Organization, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizations)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Organization
+	for rows.Next() {
+		var i Organization
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-func (q *sqlQuerier) InsertGitSSHKey(ctx context.Context, arg InsertGitSSHKeyParams) (GitSSHKey, error) {
-	row := q.db.QueryRowContext(ctx, insertGitSSHKey,
-		arg.UserID,
-		arg.CreatedAt,
-		arg.UpdatedAt,
-		arg.PrivateKey,
-		arg.PublicKey,
-	)
-	var i GitSSHKey
-	err := row.Scan(
-		&i.UserID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.PrivateKey,
-		&i.PublicKey,
+const getOrganizationsByUserID = `-- name: GetOrganizationsByUserID :many
+SELECT
+	id, name, description, created_at, updated_at
+FROM
+	organizations
+WHERE
+	id = (
+		SELECT
+			organization_id
+		FROM
+			organization_members
+		WHERE
+			user_id = $1
 	)
-	return i, err
+`
+
+func (q *sqlQuerier) GetOrganizationsByUserID(ctx context.Context, userID uuid.UUID) ([]Organization, error) {
+	rows, err := q.db.QueryContext(ctx, getOrganizationsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Organization
+	for rows.Next() {
+		var i Organization
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const updateGitSSHKey = `-- name: UpdateGitSSHKey :one
-UPDATE
-	gitsshkeys
-SET
-	updated_at = $2,
-	private_key = $3,
-	public_key = $4
-WHERE
-	user_id = $1
-RETURNING
-	user_id, created_at, updated_at, private_key, public_key
+const insertOrganization = `-- name: InsertOrganization :one
+INSERT INTO
+	organizations (id, "name", description, created_at, updated_at)
+VALUES
+	($1, $2, $3, $4, $5) RETURNING id, name, description, created_at, updated_at
 `
 
-type UpdateGitSSHKeyParams struct {
-	UserID     uuid.UUID `db:"user_id" json:"user_id"`
-	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
-	PrivateKey string    `db:"private_key" json:"private_key"`
-	PublicKey  string    `db:"public_key" json:"public_key"`
+type InsertOrganizationParams struct {
+	ID          uuid.UUID `db:"id" json:"id"`
+	Name        string    `db:"name" json:"name"`
+	Description string    `db:"description" json:"description"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) UpdateGitSSHKey(ctx context.Context, arg UpdateGitSSHKeyParams) (GitSSHKey, error) {
-	row := q.db.QueryRowContext(ctx, updateGitSSHKey,
-		arg.UserID,
+func (q *sqlQuerier) InsertOrganization(ctx context.Context, arg InsertOrganizationParams) (Organization, error) {
+	row := q.db.QueryRowContext(ctx, insertOrganization,
+		arg.ID,
+		arg.Name,
+		arg.Description,
+		arg.CreatedAt,
 		arg.UpdatedAt,
-		arg.PrivateKey,
-		arg.PublicKey,
 	)
-	var i GitSSHKey
+	var i Organization
 	err := row.Scan(
-		&i.UserID,
+		&i.ID,
+		&i.Name,
+		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.PrivateKey,
-		&i.PublicKey,
 	)
 	return i, err
 }
 
-const deleteGroupMemberFromGroup = `-- name: DeleteGroupMemberFromGroup :exec
-DELETE FROM
-	group_members
-WHERE
-	user_id = $1 AND
-	group_id = $2
-`
-
-type DeleteGroupMemberFromGroupParams struct {
-	UserID  uuid.UUID `db:"user_id" json:"user_id"`
-	GroupID uuid.UUID `db:"group_id" json:"group_id"`
-}
-
-func (q *sqlQuerier) DeleteGroupMemberFromGroup(ctx context.Context, arg DeleteGroupMemberFromGroupParams) error {
-	_, err := q.db.ExecContext(ctx, deleteGroupMemberFromGroup, arg.UserID, arg.GroupID)
-	return err
-}
-
-const deleteGroupMembersByOrgAndUser = `-- name: DeleteGroupMembersByOrgAndUser :exec
-DELETE FROM
-    group_members
-USING
-    group_members AS gm
-LEFT JOIN
-    groups
-ON
-    groups.id = gm.group_id
+const getParameterSchemasByJobID = `-- name: GetParameterSchemasByJobID :many
+SELECT
+	id, created_at, job_id, name, description, default_source_scheme, default_source_value, allow_override_source, default_destination_scheme, allow_override_destination, default_refresh, redisplay_value, validation_error, validation_condition, validation_type_system, validation_value_type, index
+FROM
+	parameter_schemas
 WHERE
-    groups.organization_id = $1 AND
-    gm.user_id = $2
+	job_id = $1
+ORDER BY
+	index
 `
 
-type DeleteGroupMembersByOrgAndUserParams struct {
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-	UserID         uuid.UUID `db:"user_id" json:"user_id"`
-}
-
-func (q *sqlQuerier) DeleteGroupMembersByOrgAndUser(ctx context.Context, arg DeleteGroupMembersByOrgAndUserParams) error {
-	_, err := q.db.ExecContext(ctx, deleteGroupMembersByOrgAndUser, arg.OrganizationID, arg.UserID)
-	return err
+func (q *sqlQuerier) GetParameterSchemasByJobID(ctx context.Context, jobID uuid.UUID) ([]ParameterSchema, error) {
+	rows, err := q.db.QueryContext(ctx, getParameterSchemasByJobID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ParameterSchema
+	for rows.Next() {
+		var i ParameterSchema
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.JobID,
+			&i.Name,
+			&i.Description,
+			&i.DefaultSourceScheme,
+			&i.DefaultSourceValue,
+			&i.AllowOverrideSource,
+			&i.DefaultDestinationScheme,
+			&i.AllowOverrideDestination,
+			&i.DefaultRefresh,
+			&i.RedisplayValue,
+			&i.ValidationError,
+			&i.ValidationCondition,
+			&i.ValidationTypeSystem,
+			&i.ValidationValueType,
+			&i.Index,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const getGroupMembers = `-- name: GetGroupMembers :many
-SELECT
-	users.id, users.email, users.username, users.hashed_password, users.created_at, users.updated_at, users.status, users.rbac_roles, users.login_type, users.avatar_url, users.deleted, users.last_seen_at
-FROM
-	users
-JOIN
-	group_members
-ON
-	users.id = group_members.user_id
-WHERE
-	group_members.group_id = $1
-AND
-	users.status = 'active'
-AND
-	users.deleted = 'false'
+const getParameterSchemasCreatedAfter = `-- name: GetParameterSchemasCreatedAfter :many
+SELECT id, created_at, job_id, name, description, default_source_scheme, default_source_value, allow_override_source, default_destination_scheme, allow_override_destination, default_refresh, redisplay_value, validation_error, validation_condition, validation_type_system, validation_value_type, index FROM parameter_schemas WHERE created_at > $1
 `
 
-func (q *sqlQuerier) GetGroupMembers(ctx context.Context, groupID uuid.UUID) ([]User, error) {
-	rows, err := q.db.QueryContext(ctx, getGroupMembers, groupID)
+func (q *sqlQuerier) GetParameterSchemasCreatedAfter(ctx context.Context, createdAt time.Time) ([]ParameterSchema, error) {
+	rows, err := q.db.QueryContext(ctx, getParameterSchemasCreatedAfter, createdAt)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []User
+	var items []ParameterSchema
 	for rows.Next() {
-		var i User
+		var i ParameterSchema
 		if err := rows.Scan(
 			&i.ID,
-			&i.Email,
-			&i.Username,
-			&i.HashedPassword,
 			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.Status,
-			&i.RBACRoles,
-			&i.LoginType,
-			&i.AvatarURL,
-			&i.Deleted,
-			&i.LastSeenAt,
+			&i.JobID,
+			&i.Name,
+			&i.Description,
+			&i.DefaultSourceScheme,
+			&i.DefaultSourceValue,
+			&i.AllowOverrideSource,
+			&i.DefaultDestinationScheme,
+			&i.AllowOverrideDestination,
+			&i.DefaultRefresh,
+			&i.RedisplayValue,
+			&i.ValidationError,
+			&i.ValidationCondition,
+			&i.ValidationTypeSystem,
+			&i.ValidationValueType,
+			&i.Index,
 		); err != nil {
 			return nil, err
 		}
@@ -988,321 +3124,299 @@ func (q *sqlQuerier) GetGroupMembers(ctx context.Context, groupID uuid.UUID) ([]
 	return items, nil
 }
 
-const insertGroupMember = `-- name: InsertGroupMember :exec
+const insertParameterSchema = `-- name: InsertParameterSchema :one
 INSERT INTO
-    group_members (user_id, group_id)
+	parameter_schemas (
+		id,
+		created_at,
+		job_id,
+		"name",
+		description,
+		default_source_scheme,
+		default_source_value,
+		allow_override_source,
+		default_destination_scheme,
+		allow_override_destination,
+		default_refresh,
+		redisplay_value,
+		validation_error,
+		validation_condition,
+		validation_type_system,
+		validation_value_type,
+		index
+	)
 VALUES
-    ($1, $2)
-`
-
-type InsertGroupMemberParams struct {
-	UserID  uuid.UUID `db:"user_id" json:"user_id"`
-	GroupID uuid.UUID `db:"group_id" json:"group_id"`
-}
-
-func (q *sqlQuerier) InsertGroupMember(ctx context.Context, arg InsertGroupMemberParams) error {
-	_, err := q.db.ExecContext(ctx, insertGroupMember, arg.UserID, arg.GroupID)
-	return err
-}
-
-const insertUserGroupsByName = `-- name: InsertUserGroupsByName :exec
-WITH groups AS (
-    SELECT
-        id
-    FROM
-        groups
-    WHERE
-        groups.organization_id = $2 AND
-        groups.name = ANY($3 :: text [])
-)
-INSERT INTO
-    group_members (user_id, group_id)
-SELECT
-    $1,
-    groups.id
-FROM
-    groups
-`
-
-type InsertUserGroupsByNameParams struct {
-	UserID         uuid.UUID `db:"user_id" json:"user_id"`
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-	GroupNames     []string  `db:"group_names" json:"group_names"`
-}
-
-// InsertUserGroupsByName adds a user to all provided groups, if they exist.
-func (q *sqlQuerier) InsertUserGroupsByName(ctx context.Context, arg InsertUserGroupsByNameParams) error {
-	_, err := q.db.ExecContext(ctx, insertUserGroupsByName, arg.UserID, arg.OrganizationID, pq.Array(arg.GroupNames))
-	return err
-}
-
-const deleteGroupByID = `-- name: DeleteGroupByID :exec
-DELETE FROM
-	groups
-WHERE
-	id = $1
+	(
+		$1,
+		$2,
+		$3,
+		$4,
+		$5,
+		$6,
+		$7,
+		$8,
+		$9,
+		$10,
+		$11,
+		$12,
+		$13,
+		$14,
+		$15,
+		$16,
+		$17
+	) RETURNING id, created_at, job_id, name, description, default_source_scheme, default_source_value, allow_override_source, default_destination_scheme, allow_override_destination, default_refresh, redisplay_value, validation_error, validation_condition, validation_type_system, validation_value_type, index
 `
 
-func (q *sqlQuerier) DeleteGroupByID(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteGroupByID, id)
-	return err
+type InsertParameterSchemaParams struct {
+	ID                       uuid.UUID                  `db:"id" json:"id"`
+	CreatedAt                time.Time                  `db:"created_at" json:"created_at"`
+	JobID                    uuid.UUID                  `db:"job_id" json:"job_id"`
+	Name                     string                     `db:"name" json:"name"`
+	Description              string                     `db:"description" json:"description"`
+	DefaultSourceScheme      ParameterSourceScheme      `db:"default_source_scheme" json:"default_source_scheme"`
+	DefaultSourceValue       string                     `db:"default_source_value" json:"default_source_value"`
+	AllowOverrideSource      bool                       `db:"allow_override_source" json:"allow_override_source"`
+	DefaultDestinationScheme ParameterDestinationScheme `db:"default_destination_scheme" json:"default_destination_scheme"`
+	AllowOverrideDestination bool                       `db:"allow_override_destination" json:"allow_override_destination"`
+	DefaultRefresh           string                     `db:"default_refresh" json:"default_refresh"`
+	RedisplayValue           bool                       `db:"redisplay_value" json:"redisplay_value"`
+	ValidationError          string                     `db:"validation_error" json:"validation_error"`
+	ValidationCondition      string                     `db:"validation_condition" json:"validation_condition"`
+	ValidationTypeSystem     ParameterTypeSystem        `db:"validation_type_system" json:"validation_type_system"`
+	ValidationValueType      string                     `db:"validation_value_type" json:"validation_value_type"`
+	Index                    int32                      `db:"index" json:"index"`
 }
 
-const getGroupByID = `-- name: GetGroupByID :one
-SELECT
-	id, name, organization_id, avatar_url, quota_allowance
-FROM
-	groups
-WHERE
-	id = $1
-LIMIT
-	1
-`
-
-func (q *sqlQuerier) GetGroupByID(ctx context.Context, id uuid.UUID) (Group, error) {
-	row := q.db.QueryRowContext(ctx, getGroupByID, id)
-	var i Group
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.OrganizationID,
-		&i.AvatarURL,
-		&i.QuotaAllowance,
+func (q *sqlQuerier) InsertParameterSchema(ctx context.Context, arg InsertParameterSchemaParams) (ParameterSchema, error) {
+	row := q.db.QueryRowContext(ctx, insertParameterSchema,
+		arg.ID,
+		arg.CreatedAt,
+		arg.JobID,
+		arg.Name,
+		arg.Description,
+		arg.DefaultSourceScheme,
+		arg.DefaultSourceValue,
+		arg.AllowOverrideSource,
+		arg.DefaultDestinationScheme,
+		arg.AllowOverrideDestination,
+		arg.DefaultRefresh,
+		arg.RedisplayValue,
+		arg.ValidationError,
+		arg.ValidationCondition,
+		arg.ValidationTypeSystem,
+		arg.ValidationValueType,
+		arg.Index,
 	)
-	return i, err
-}
-
-const getGroupByOrgAndName = `-- name: GetGroupByOrgAndName :one
-SELECT
-	id, name, organization_id, avatar_url, quota_allowance
-FROM
-	groups
-WHERE
-	organization_id = $1
-AND
-	name = $2
-LIMIT
-	1
-`
-
-type GetGroupByOrgAndNameParams struct {
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-	Name           string    `db:"name" json:"name"`
-}
-
-func (q *sqlQuerier) GetGroupByOrgAndName(ctx context.Context, arg GetGroupByOrgAndNameParams) (Group, error) {
-	row := q.db.QueryRowContext(ctx, getGroupByOrgAndName, arg.OrganizationID, arg.Name)
-	var i Group
+	var i ParameterSchema
 	err := row.Scan(
 		&i.ID,
+		&i.CreatedAt,
+		&i.JobID,
 		&i.Name,
-		&i.OrganizationID,
-		&i.AvatarURL,
-		&i.QuotaAllowance,
+		&i.Description,
+		&i.DefaultSourceScheme,
+		&i.DefaultSourceValue,
+		&i.AllowOverrideSource,
+		&i.DefaultDestinationScheme,
+		&i.AllowOverrideDestination,
+		&i.DefaultRefresh,
+		&i.RedisplayValue,
+		&i.ValidationError,
+		&i.ValidationCondition,
+		&i.ValidationTypeSystem,
+		&i.ValidationValueType,
+		&i.Index,
 	)
 	return i, err
 }
 
-const getGroupsByOrganizationID = `-- name: GetGroupsByOrganizationID :many
-SELECT
-	id, name, organization_id, avatar_url, quota_allowance
-FROM
-	groups
+const deleteParameterValueByID = `-- name: DeleteParameterValueByID :exec
+DELETE FROM
+	parameter_values
 WHERE
-	organization_id = $1
-AND
-	id != $1
+	id = $1
 `
 
-func (q *sqlQuerier) GetGroupsByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]Group, error) {
-	rows, err := q.db.QueryContext(ctx, getGroupsByOrganizationID, organizationID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Group
-	for rows.Next() {
-		var i Group
-		if err := rows.Scan(
-			&i.ID,
-			&i.Name,
-			&i.OrganizationID,
-			&i.AvatarURL,
-			&i.QuotaAllowance,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *sqlQuerier) DeleteParameterValueByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteParameterValueByID, id)
+	return err
 }
 
-const insertAllUsersGroup = `-- name: InsertAllUsersGroup :one
-INSERT INTO groups (
-	id,
-	name,
-	organization_id
-)
-VALUES
-	($1, 'Everyone', $1) RETURNING id, name, organization_id, avatar_url, quota_allowance
+const getParameterValueByScopeAndName = `-- name: GetParameterValueByScopeAndName :one
+SELECT
+	id, created_at, updated_at, scope, scope_id, name, source_scheme, source_value, destination_scheme
+FROM
+	parameter_values
+WHERE
+	scope = $1
+	AND scope_id = $2
+	AND NAME = $3
+LIMIT
+	1
 `
 
-// We use the organization_id as the id
-// for simplicity since all users is
-// every member of the org.
-func (q *sqlQuerier) InsertAllUsersGroup(ctx context.Context, organizationID uuid.UUID) (Group, error) {
-	row := q.db.QueryRowContext(ctx, insertAllUsersGroup, organizationID)
-	var i Group
+type GetParameterValueByScopeAndNameParams struct {
+	Scope   ParameterScope `db:"scope" json:"scope"`
+	ScopeID uuid.UUID      `db:"scope_id" json:"scope_id"`
+	Name    string         `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) GetParameterValueByScopeAndName(ctx context.Context, arg GetParameterValueByScopeAndNameParams) (ParameterValue, error) {
+	row := q.db.QueryRowContext(ctx, getParameterValueByScopeAndName, arg.Scope, arg.ScopeID, arg.Name)
+	var i ParameterValue
 	err := row.Scan(
 		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Scope,
+		&i.ScopeID,
 		&i.Name,
-		&i.OrganizationID,
-		&i.AvatarURL,
-		&i.QuotaAllowance,
+		&i.SourceScheme,
+		&i.SourceValue,
+		&i.DestinationScheme,
 	)
 	return i, err
 }
 
-const insertGroup = `-- name: InsertGroup :one
-INSERT INTO groups (
-	id,
-	name,
-	organization_id,
-	avatar_url,
-	quota_allowance
-)
+const insertParameterValue = `-- name: InsertParameterValue :one
+INSERT INTO
+	parameter_values (
+		id,
+		"name",
+		created_at,
+		updated_at,
+		scope,
+		scope_id,
+		source_scheme,
+		source_value,
+		destination_scheme
+	)
 VALUES
-	($1, $2, $3, $4, $5) RETURNING id, name, organization_id, avatar_url, quota_allowance
+	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, updated_at, scope, scope_id, name, source_scheme, source_value, destination_scheme
 `
 
-type InsertGroupParams struct {
-	ID             uuid.UUID `db:"id" json:"id"`
-	Name           string    `db:"name" json:"name"`
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-	AvatarURL      string    `db:"avatar_url" json:"avatar_url"`
-	QuotaAllowance int32     `db:"quota_allowance" json:"quota_allowance"`
+type InsertParameterValueParams struct {
+	ID                uuid.UUID                  `db:"id" json:"id"`
+	Name              string                     `db:"name" json:"name"`
+	CreatedAt         time.Time                  `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time                  `db:"updated_at" json:"updated_at"`
+	Scope             ParameterScope             `db:"scope" json:"scope"`
+	ScopeID           uuid.UUID                  `db:"scope_id" json:"scope_id"`
+	SourceScheme      ParameterSourceScheme      `db:"source_scheme" json:"source_scheme"`
+	SourceValue       string                     `db:"source_value" json:"source_value"`
+	DestinationScheme ParameterDestinationScheme `db:"destination_scheme" json:"destination_scheme"`
 }
 
-func (q *sqlQuerier) InsertGroup(ctx context.Context, arg InsertGroupParams) (Group, error) {
-	row := q.db.QueryRowContext(ctx, insertGroup,
+func (q *sqlQuerier) InsertParameterValue(ctx context.Context, arg InsertParameterValueParams) (ParameterValue, error) {
+	row := q.db.QueryRowContext(ctx, insertParameterValue,
 		arg.ID,
 		arg.Name,
-		arg.OrganizationID,
-		arg.AvatarURL,
-		arg.QuotaAllowance,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Scope,
+		arg.ScopeID,
+		arg.SourceScheme,
+		arg.SourceValue,
+		arg.DestinationScheme,
 	)
-	var i Group
+	var i ParameterValue
 	err := row.Scan(
 		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Scope,
+		&i.ScopeID,
 		&i.Name,
-		&i.OrganizationID,
-		&i.AvatarURL,
-		&i.QuotaAllowance,
+		&i.SourceScheme,
+		&i.SourceValue,
+		&i.DestinationScheme,
 	)
 	return i, err
 }
 
-const updateGroupByID = `-- name: UpdateGroupByID :one
-UPDATE
-	groups
-SET
-	name = $1,
-	avatar_url = $2,
-	quota_allowance = $3
+const parameterValue = `-- name: ParameterValue :one
+SELECT id, created_at, updated_at, scope, scope_id, name, source_scheme, source_value, destination_scheme FROM
+	parameter_values
 WHERE
-	id = $4
-RETURNING id, name, organization_id, avatar_url, quota_allowance
+	id = $1
 `
 
-type UpdateGroupByIDParams struct {
-	Name           string    `db:"name" json:"name"`
-	AvatarURL      string    `db:"avatar_url" json:"avatar_url"`
-	QuotaAllowance int32     `db:"quota_allowance" json:"quota_allowance"`
-	ID             uuid.UUID `db:"id" json:"id"`
-}
-
-func (q *sqlQuerier) UpdateGroupByID(ctx context.Context, arg UpdateGroupByIDParams) (Group, error) {
-	row := q.db.QueryRowContext(ctx, updateGroupByID,
-		arg.Name,
-		arg.AvatarURL,
-		arg.QuotaAllowance,
-		arg.ID,
-	)
-	var i Group
+func (q *sqlQuerier) ParameterValue(ctx context.Context, id uuid.UUID) (ParameterValue, error) {
+	row := q.db.QueryRowContext(ctx, parameterValue, id)
+	var i ParameterValue
 	err := row.Scan(
 		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Scope,
+		&i.ScopeID,
 		&i.Name,
-		&i.OrganizationID,
-		&i.AvatarURL,
-		&i.QuotaAllowance,
+		&i.SourceScheme,
+		&i.SourceValue,
+		&i.DestinationScheme,
 	)
 	return i, err
 }
 
-const deleteLicense = `-- name: DeleteLicense :one
-DELETE
-FROM licenses
-WHERE id = $1
-RETURNING id
-`
-
-func (q *sqlQuerier) DeleteLicense(ctx context.Context, id int32) (int32, error) {
-	row := q.db.QueryRowContext(ctx, deleteLicense, id)
-	err := row.Scan(&id)
-	return id, err
-}
-
-const getLicenseByID = `-- name: GetLicenseByID :one
+const parameterValues = `-- name: ParameterValues :many
 SELECT
-	id, uploaded_at, jwt, exp, uuid
+	id, created_at, updated_at, scope, scope_id, name, source_scheme, source_value, destination_scheme
 FROM
-	licenses
+	parameter_values
 WHERE
-	id = $1
-LIMIT
-	1
+  	CASE
+		  WHEN cardinality($1 :: parameter_scope[]) > 0 THEN
+				  scope = ANY($1 :: parameter_scope[])
+		  ELSE true
+	END
+    AND CASE
+		WHEN cardinality($2 :: uuid[]) > 0 THEN
+			scope_id = ANY($2 :: uuid[])
+		ELSE true
+	END
+  	AND CASE
+		WHEN cardinality($3 :: uuid[]) > 0 THEN
+			id = ANY($3 :: uuid[])
+		ELSE true
+	END
+  	AND CASE
+		  WHEN cardinality($4 :: text[]) > 0 THEN
+				  "name" = ANY($4 :: text[])
+		  ELSE true
+	END
 `
 
-func (q *sqlQuerier) GetLicenseByID(ctx context.Context, id int32) (License, error) {
-	row := q.db.QueryRowContext(ctx, getLicenseByID, id)
-	var i License
-	err := row.Scan(
-		&i.ID,
-		&i.UploadedAt,
-		&i.JWT,
-		&i.Exp,
-		&i.UUID,
-	)
-	return i, err
+type ParameterValuesParams struct {
+	Scopes   []ParameterScope `db:"scopes" json:"scopes"`
+	ScopeIds []uuid.UUID      `db:"scope_ids" json:"scope_ids"`
+	IDs      []uuid.UUID      `db:"ids" json:"ids"`
+	Names    []string         `db:"names" json:"names"`
 }
 
-const getLicenses = `-- name: GetLicenses :many
-SELECT id, uploaded_at, jwt, exp, uuid
-FROM licenses
-ORDER BY (id)
-`
-
-func (q *sqlQuerier) GetLicenses(ctx context.Context) ([]License, error) {
-	rows, err := q.db.QueryContext(ctx, getLicenses)
+func (q *sqlQuerier) ParameterValues(ctx context.Context, arg ParameterValuesParams) ([]ParameterValue, error) {
+	rows, err := q.db.QueryContext(ctx, parameterValues,
+		pq.Array(arg.Scopes),
+		pq.Array(arg.ScopeIds),
+		pq.Array(arg.IDs),
+		pq.Array(arg.Names),
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []License
+	var items []ParameterValue
 	for rows.Next() {
-		var i License
+		var i ParameterValue
 		if err := rows.Scan(
 			&i.ID,
-			&i.UploadedAt,
-			&i.JWT,
-			&i.Exp,
-			&i.UUID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Scope,
+			&i.ScopeID,
+			&i.Name,
+			&i.SourceScheme,
+			&i.SourceValue,
+			&i.DestinationScheme,
 		); err != nil {
 			return nil, err
 		}
@@ -1317,28 +3431,46 @@ func (q *sqlQuerier) GetLicenses(ctx context.Context) ([]License, error) {
 	return items, nil
 }
 
-const getUnexpiredLicenses = `-- name: GetUnexpiredLicenses :many
-SELECT id, uploaded_at, jwt, exp, uuid
-FROM licenses
-WHERE exp > NOW()
-ORDER BY (id)
+const deleteOldProvisionerDaemons = `-- name: DeleteOldProvisionerDaemons :exec
+DELETE FROM
+	provisioner_daemons
+WHERE
+	COALESCE(last_seen_at, created_at) < $1 :: timestamptz
 `
 
-func (q *sqlQuerier) GetUnexpiredLicenses(ctx context.Context) ([]License, error) {
-	rows, err := q.db.QueryContext(ctx, getUnexpiredLicenses)
+// Daemons are inserted every time they connect, so the daemons that haven't
+// been seen for a while are deleted to keep the list of daemons readable.
+func (q *sqlQuerier) DeleteOldProvisionerDaemons(ctx context.Context, before time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteOldProvisionerDaemons, before)
+	return err
+}
+
+const getProvisionerDaemons = `-- name: GetProvisionerDaemons :many
+SELECT
+	id, created_at, updated_at, name, provisioners, replica_id, tags, last_seen_at, version
+FROM
+	provisioner_daemons
+`
+
+func (q *sqlQuerier) GetProvisionerDaemons(ctx context.Context) ([]ProvisionerDaemon, error) {
+	rows, err := q.db.QueryContext(ctx, getProvisionerDaemons)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []License
+	var items []ProvisionerDaemon
 	for rows.Next() {
-		var i License
+		var i ProvisionerDaemon
 		if err := rows.Scan(
 			&i.ID,
-			&i.UploadedAt,
-			&i.JWT,
-			&i.Exp,
-			&i.UUID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			pq.Array(&i.Provisioners),
+			&i.ReplicaID,
+			&i.Tags,
+			&i.LastSeenAt,
+			&i.Version,
 		); err != nil {
 			return nil, err
 		}
@@ -1353,69 +3485,181 @@ func (q *sqlQuerier) GetUnexpiredLicenses(ctx context.Context) ([]License, error
 	return items, nil
 }
 
-const insertLicense = `-- name: InsertLicense :one
+const insertProvisionerDaemon = `-- name: InsertProvisionerDaemon :one
 INSERT INTO
-	licenses (
-	uploaded_at,
-	jwt,
-	exp,
-	uuid
-)
+	provisioner_daemons (
+		id,
+		created_at,
+		"name",
+		provisioners,
+		tags,
+		last_seen_at,
+		version
+	)
 VALUES
-	($1, $2, $3, $4) RETURNING id, uploaded_at, jwt, exp, uuid
+	($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at, updated_at, name, provisioners, replica_id, tags, last_seen_at, version
 `
 
-type InsertLicenseParams struct {
-	UploadedAt time.Time `db:"uploaded_at" json:"uploaded_at"`
-	JWT        string    `db:"jwt" json:"jwt"`
-	Exp        time.Time `db:"exp" json:"exp"`
-	UUID       uuid.UUID `db:"uuid" json:"uuid"`
+type InsertProvisionerDaemonParams struct {
+	ID           uuid.UUID         `db:"id" json:"id"`
+	CreatedAt    time.Time         `db:"created_at" json:"created_at"`
+	Name         string            `db:"name" json:"name"`
+	Provisioners []ProvisionerType `db:"provisioners" json:"provisioners"`
+	Tags         dbtype.StringMap  `db:"tags" json:"tags"`
+	LastSeenAt   sql.NullTime      `db:"last_seen_at" json:"last_seen_at"`
+	Version      string            `db:"version" json:"version"`
 }
 
-func (q *sqlQuerier) InsertLicense(ctx context.Context, arg InsertLicenseParams) (License, error) {
-	row := q.db.QueryRowContext(ctx, insertLicense,
-		arg.UploadedAt,
-		arg.JWT,
-		arg.Exp,
-		arg.UUID,
+func (q *sqlQuerier) InsertProvisionerDaemon(ctx context.Context, arg InsertProvisionerDaemonParams) (ProvisionerDaemon, error) {
+	row := q.db.QueryRowContext(ctx, insertProvisionerDaemon,
+		arg.ID,
+		arg.CreatedAt,
+		arg.Name,
+		pq.Array(arg.Provisioners),
+		arg.Tags,
+		arg.LastSeenAt,
+		arg.Version,
 	)
-	var i License
+	var i ProvisionerDaemon
 	err := row.Scan(
 		&i.ID,
-		&i.UploadedAt,
-		&i.JWT,
-		&i.Exp,
-		&i.UUID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		pq.Array(&i.Provisioners),
+		&i.ReplicaID,
+		&i.Tags,
+		&i.LastSeenAt,
+		&i.Version,
 	)
 	return i, err
 }
 
-const getOrganizationIDsByMemberIDs = `-- name: GetOrganizationIDsByMemberIDs :many
+const updateProvisionerDaemonLastSeenAt = `-- name: UpdateProvisionerDaemonLastSeenAt :exec
+UPDATE
+	provisioner_daemons
+SET
+	last_seen_at = $2
+WHERE
+	id = $1
+`
+
+type UpdateProvisionerDaemonLastSeenAtParams struct {
+	ID         uuid.UUID    `db:"id" json:"id"`
+	LastSeenAt sql.NullTime `db:"last_seen_at" json:"last_seen_at"`
+}
+
+func (q *sqlQuerier) UpdateProvisionerDaemonLastSeenAt(ctx context.Context, arg UpdateProvisionerDaemonLastSeenAtParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerDaemonLastSeenAt, arg.ID, arg.LastSeenAt)
+	return err
+}
+
+const deleteArchivedTemplateVersionJobLogs = `-- name: DeleteArchivedTemplateVersionJobLogs :exec
+DELETE FROM
+	provisioner_job_logs
+WHERE
+	job_id IN (
+		SELECT
+			provisioner_jobs.id
+		FROM
+			provisioner_jobs
+		JOIN template_versions ON template_versions.job_id = provisioner_jobs.id
+			OR (
+				provisioner_jobs.type = 'template_version_dry_run'
+				AND template_versions.id = (provisioner_jobs.input ->> 'template_version_id') :: uuid
+			)
+		WHERE
+			template_versions.archived = true
+	)
+`
+
+// Deletes the logs of the import and dry-run jobs of archived template
+// versions.
+func (q *sqlQuerier) DeleteArchivedTemplateVersionJobLogs(ctx context.Context) error {
+	_, err := q.db.ExecContext(ctx, deleteArchivedTemplateVersionJobLogs)
+	return err
+}
+
+const deleteOldWorkspaceBuildJobLogs = `-- name: DeleteOldWorkspaceBuildJobLogs :execrows
+DELETE FROM
+	provisioner_job_logs
+WHERE
+	id IN (
+		SELECT
+			provisioner_job_logs.id
+		FROM
+			provisioner_job_logs
+		JOIN provisioner_jobs ON provisioner_jobs.id = provisioner_job_logs.job_id
+		JOIN workspace_builds ON workspace_builds.job_id = provisioner_jobs.id
+		WHERE
+			provisioner_jobs.completed_at < $1 :: timestamptz
+			AND workspace_builds.build_number < (
+				SELECT
+					MAX(build_number)
+				FROM
+					workspace_builds AS latest_builds
+				WHERE
+					latest_builds.workspace_id = workspace_builds.workspace_id
+			)
+		LIMIT
+			$2 :: int
+	)
+`
+
+type DeleteOldWorkspaceBuildJobLogsParams struct {
+	CompletedBefore time.Time `db:"completed_before" json:"completed_before"`
+	RowLimit        int32     `db:"row_limit" json:"row_limit"`
+}
+
+// Deletes up to a limit of the logs of workspace build jobs that completed
+// before the given time, except for the latest build of each workspace.
+// Returns how many logs were deleted, so large backlogs are deleted in batches
+// that don't hold a long transaction.
+func (q *sqlQuerier) DeleteOldWorkspaceBuildJobLogs(ctx context.Context, arg DeleteOldWorkspaceBuildJobLogsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOldWorkspaceBuildJobLogs, arg.CompletedBefore, arg.RowLimit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getProvisionerLogsByIDBetween = `-- name: GetProvisionerLogsByIDBetween :many
 SELECT
-    user_id, array_agg(organization_id) :: uuid [ ] AS "organization_IDs"
+	job_id, created_at, source, level, stage, output, id
 FROM
-    organization_members
+	provisioner_job_logs
 WHERE
-    user_id = ANY($1 :: uuid [ ])
-GROUP BY
-    user_id
+	job_id = $1
+	AND (
+		id > $2
+		OR id < $3
+	) ORDER BY id ASC
 `
 
-type GetOrganizationIDsByMemberIDsRow struct {
-	UserID          uuid.UUID   `db:"user_id" json:"user_id"`
-	OrganizationIDs []uuid.UUID `db:"organization_IDs" json:"organization_IDs"`
+type GetProvisionerLogsByIDBetweenParams struct {
+	JobID         uuid.UUID `db:"job_id" json:"job_id"`
+	CreatedAfter  int64     `db:"created_after" json:"created_after"`
+	CreatedBefore int64     `db:"created_before" json:"created_before"`
 }
 
-func (q *sqlQuerier) GetOrganizationIDsByMemberIDs(ctx context.Context, ids []uuid.UUID) ([]GetOrganizationIDsByMemberIDsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getOrganizationIDsByMemberIDs, pq.Array(ids))
+func (q *sqlQuerier) GetProvisionerLogsByIDBetween(ctx context.Context, arg GetProvisionerLogsByIDBetweenParams) ([]ProvisionerJobLog, error) {
+	rows, err := q.db.QueryContext(ctx, getProvisionerLogsByIDBetween, arg.JobID, arg.CreatedAfter, arg.CreatedBefore)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetOrganizationIDsByMemberIDsRow
+	var items []ProvisionerJobLog
 	for rows.Next() {
-		var i GetOrganizationIDsByMemberIDsRow
-		if err := rows.Scan(&i.UserID, pq.Array(&i.OrganizationIDs)); err != nil {
+		var i ProvisionerJobLog
+		if err := rows.Scan(
+			&i.JobID,
+			&i.CreatedAt,
+			&i.Source,
+			&i.Level,
+			&i.Stage,
+			&i.Output,
+			&i.ID,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -1429,60 +3673,51 @@ func (q *sqlQuerier) GetOrganizationIDsByMemberIDs(ctx context.Context, ids []uu
 	return items, nil
 }
 
-const getOrganizationMemberByUserID = `-- name: GetOrganizationMemberByUserID :one
+const insertProvisionerJobLogs = `-- name: InsertProvisionerJobLogs :many
+INSERT INTO
+	provisioner_job_logs
 SELECT
-	user_id, organization_id, created_at, updated_at, roles
-FROM
-	organization_members
-WHERE
-	organization_id = $1
-	AND user_id = $2
-LIMIT
-	1
+	$1 :: uuid AS job_id,
+	unnest($2 :: timestamptz [ ]) AS created_at,
+	unnest($3 :: log_source [ ]) AS source,
+	unnest($4 :: log_level [ ]) AS LEVEL,
+	unnest($5 :: VARCHAR(128) [ ]) AS stage,
+	unnest($6 :: VARCHAR(1024) [ ]) AS output RETURNING job_id, created_at, source, level, stage, output, id
 `
 
-type GetOrganizationMemberByUserIDParams struct {
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-	UserID         uuid.UUID `db:"user_id" json:"user_id"`
-}
-
-func (q *sqlQuerier) GetOrganizationMemberByUserID(ctx context.Context, arg GetOrganizationMemberByUserIDParams) (OrganizationMember, error) {
-	row := q.db.QueryRowContext(ctx, getOrganizationMemberByUserID, arg.OrganizationID, arg.UserID)
-	var i OrganizationMember
-	err := row.Scan(
-		&i.UserID,
-		&i.OrganizationID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		pq.Array(&i.Roles),
-	)
-	return i, err
+type InsertProvisionerJobLogsParams struct {
+	JobID     uuid.UUID   `db:"job_id" json:"job_id"`
+	CreatedAt []time.Time `db:"created_at" json:"created_at"`
+	Source    []LogSource `db:"source" json:"source"`
+	Level     []LogLevel  `db:"level" json:"level"`
+	Stage     []string    `db:"stage" json:"stage"`
+	Output    []string    `db:"output" json:"output"`
 }
 
-const getOrganizationMembershipsByUserID = `-- name: GetOrganizationMembershipsByUserID :many
-SELECT
-	user_id, organization_id, created_at, updated_at, roles
-FROM
-	organization_members
-WHERE
-  user_id = $1
-`
-
-func (q *sqlQuerier) GetOrganizationMembershipsByUserID(ctx context.Context, userID uuid.UUID) ([]OrganizationMember, error) {
-	rows, err := q.db.QueryContext(ctx, getOrganizationMembershipsByUserID, userID)
+func (q *sqlQuerier) InsertProvisionerJobLogs(ctx context.Context, arg InsertProvisionerJobLogsParams) ([]ProvisionerJobLog, error) {
+	rows, err := q.db.QueryContext(ctx, insertProvisionerJobLogs,
+		arg.JobID,
+		pq.Array(arg.CreatedAt),
+		pq.Array(arg.Source),
+		pq.Array(arg.Level),
+		pq.Array(arg.Stage),
+		pq.Array(arg.Output),
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []OrganizationMember
+	var items []ProvisionerJobLog
 	for rows.Next() {
-		var i OrganizationMember
+		var i ProvisionerJobLog
 		if err := rows.Scan(
-			&i.UserID,
-			&i.OrganizationID,
+			&i.JobID,
 			&i.CreatedAt,
-			&i.UpdatedAt,
-			pq.Array(&i.Roles),
+			&i.Source,
+			&i.Level,
+			&i.Stage,
+			&i.Output,
+			&i.ID,
 		); err != nil {
 			return nil, err
 		}
@@ -1497,145 +3732,325 @@ func (q *sqlQuerier) GetOrganizationMembershipsByUserID(ctx context.Context, use
 	return items, nil
 }
 
-const insertOrganizationMember = `-- name: InsertOrganizationMember :one
-INSERT INTO
-	organization_members (
-		organization_id,
-		user_id,
-		created_at,
-		updated_at,
-		roles
-	)
-VALUES
-	($1, $2, $3, $4, $5) RETURNING user_id, organization_id, created_at, updated_at, roles
-`
-
-type InsertOrganizationMemberParams struct {
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-	UserID         uuid.UUID `db:"user_id" json:"user_id"`
-	CreatedAt      time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time `db:"updated_at" json:"updated_at"`
-	Roles          []string  `db:"roles" json:"roles"`
-}
-
-func (q *sqlQuerier) InsertOrganizationMember(ctx context.Context, arg InsertOrganizationMemberParams) (OrganizationMember, error) {
-	row := q.db.QueryRowContext(ctx, insertOrganizationMember,
-		arg.OrganizationID,
-		arg.UserID,
-		arg.CreatedAt,
-		arg.UpdatedAt,
-		pq.Array(arg.Roles),
-	)
-	var i OrganizationMember
-	err := row.Scan(
-		&i.UserID,
-		&i.OrganizationID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		pq.Array(&i.Roles),
-	)
-	return i, err
-}
-
-const updateMemberRoles = `-- name: UpdateMemberRoles :one
+const acquireProvisionerJob = `-- name: AcquireProvisionerJob :one
 UPDATE
-	organization_members
+	provisioner_jobs
 SET
-	-- Remove all duplicates from the roles.
-	roles = ARRAY(SELECT DISTINCT UNNEST($1 :: text[]))
+	started_at = $1,
+	updated_at = $1,
+	last_heartbeat_at = $1,
+	worker_id = $2
 WHERE
-	user_id = $2
-	AND organization_id = $3
-RETURNING user_id, organization_id, created_at, updated_at, roles
+	id = (
+		SELECT
+			id
+		FROM
+			provisioner_jobs AS nested
+		WHERE
+			nested.started_at IS NULL
+			-- Ensure the caller has the correct provisioner.
+			AND nested.provisioner = ANY($3 :: provisioner_type [ ])
+			-- Ensure the caller satisfies all job tags.
+			AND nested.tags <@ $4 :: jsonb 
+			-- Daemons that authenticate with a provisioner key only run the
+			-- jobs of its organization.
+			AND CASE
+				WHEN $5 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+					nested.organization_id = $5
+				ELSE true
+			END
+		ORDER BY
+			nested.priority,
+			(
+				SELECT
+					COUNT(*)
+				FROM
+					provisioner_jobs AS running
+				WHERE
+					running.initiator_id = nested.initiator_id
+					AND running.started_at IS NOT NULL
+					AND running.completed_at IS NULL
+			),
+			(
+				SELECT
+					COUNT(*)
+				FROM
+					provisioner_jobs AS running
+				WHERE
+					running.organization_id = nested.organization_id
+					AND running.started_at IS NOT NULL
+					AND running.completed_at IS NULL
+			),
+			nested.created_at
+		FOR UPDATE
+		SKIP LOCKED
+		LIMIT
+			1
+	) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, priority, last_heartbeat_at
 `
 
-type UpdateMemberRolesParams struct {
-	GrantedRoles []string  `db:"granted_roles" json:"granted_roles"`
-	UserID       uuid.UUID `db:"user_id" json:"user_id"`
-	OrgID        uuid.UUID `db:"org_id" json:"org_id"`
+type AcquireProvisionerJobParams struct {
+	StartedAt      sql.NullTime      `db:"started_at" json:"started_at"`
+	WorkerID       uuid.NullUUID     `db:"worker_id" json:"worker_id"`
+	Types          []ProvisionerType `db:"types" json:"types"`
+	Tags           json.RawMessage   `db:"tags" json:"tags"`
+	OrganizationID uuid.UUID         `db:"organization_id" json:"organization_id"`
 }
 
-func (q *sqlQuerier) UpdateMemberRoles(ctx context.Context, arg UpdateMemberRolesParams) (OrganizationMember, error) {
-	row := q.db.QueryRowContext(ctx, updateMemberRoles, pq.Array(arg.GrantedRoles), arg.UserID, arg.OrgID)
-	var i OrganizationMember
+// Acquires the lock for a single job that isn't started, completed,
+// canceled, and that matches an array of provisioner types.
+//
+// Jobs are acquired by priority. Within a priority, jobs of the initiators and
+// organizations with the fewest running jobs are acquired first, so a single
+// user or organization can't starve the queue, and then the oldest jobs.
+//
+// SKIP LOCKED is used to jump over locked rows. This prevents
+// multiple provisioners from acquiring the same jobs. See:
+// https://www.postgresql.org/docs/9.5/sql-select.html#SQL-FOR-UPDATE-SHARE
+func (q *sqlQuerier) AcquireProvisionerJob(ctx context.Context, arg AcquireProvisionerJobParams) (ProvisionerJob, error) {
+	row := q.db.QueryRowContext(ctx, acquireProvisionerJob,
+		arg.StartedAt,
+		arg.WorkerID,
+		pq.Array(arg.Types),
+		arg.Tags,
+		arg.OrganizationID,
+	)
+	var i ProvisionerJob
 	err := row.Scan(
-		&i.UserID,
-		&i.OrganizationID,
+		&i.ID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		pq.Array(&i.Roles),
+		&i.StartedAt,
+		&i.CanceledAt,
+		&i.CompletedAt,
+		&i.Error,
+		&i.OrganizationID,
+		&i.InitiatorID,
+		&i.Provisioner,
+		&i.StorageMethod,
+		&i.Type,
+		&i.Input,
+		&i.WorkerID,
+		&i.FileID,
+		&i.Tags,
+		&i.Priority,
+		&i.LastHeartbeatAt,
 	)
 	return i, err
 }
 
-const getOrganizationByID = `-- name: GetOrganizationByID :one
+const getProvisionerJobByID = `-- name: GetProvisionerJobByID :one
 SELECT
-	id, name, description, created_at, updated_at
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, priority, last_heartbeat_at
 FROM
-	organizations
+	provisioner_jobs
 WHERE
 	id = $1
 `
 
-func (q *sqlQuerier) GetOrganizationByID(ctx context.Context, id uuid.UUID) (Organization, error) {
-	row := q.db.QueryRowContext(ctx, getOrganizationByID, id)
-	var i Organization
+func (q *sqlQuerier) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (ProvisionerJob, error) {
+	row := q.db.QueryRowContext(ctx, getProvisionerJobByID, id)
+	var i ProvisionerJob
 	err := row.Scan(
 		&i.ID,
-		&i.Name,
-		&i.Description,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.CanceledAt,
+		&i.CompletedAt,
+		&i.Error,
+		&i.OrganizationID,
+		&i.InitiatorID,
+		&i.Provisioner,
+		&i.StorageMethod,
+		&i.Type,
+		&i.Input,
+		&i.WorkerID,
+		&i.FileID,
+		&i.Tags,
+		&i.Priority,
+		&i.LastHeartbeatAt,
 	)
 	return i, err
 }
 
-const getOrganizationByName = `-- name: GetOrganizationByName :one
+const getProvisionerJobQueuePositionsByIDs = `-- name: GetProvisionerJobQueuePositionsByIDs :many
+WITH pending_jobs AS (
+	SELECT
+		id,
+		provisioner,
+		tags,
+		ROW_NUMBER() OVER (PARTITION BY provisioner, tags ORDER BY priority, created_at) AS queue_position,
+		COUNT(*) OVER (PARTITION BY provisioner, tags) AS queue_size
+	FROM
+		provisioner_jobs
+	WHERE
+		started_at IS NULL
+		AND completed_at IS NULL
+)
 SELECT
-	id, name, description, created_at, updated_at
+	pending_jobs.id,
+	pending_jobs.provisioner,
+	pending_jobs.tags,
+	pending_jobs.queue_position,
+	pending_jobs.queue_size,
+	(
+		SELECT
+			COUNT(*)
+		FROM
+			provisioner_jobs
+		WHERE
+			provisioner_jobs.provisioner = pending_jobs.provisioner
+			AND provisioner_jobs.tags = pending_jobs.tags
+			AND provisioner_jobs.started_at IS NOT NULL
+			AND provisioner_jobs.completed_at IS NULL
+	) :: bigint AS running_jobs,
+	(
+		SELECT
+			COALESCE(AVG(EXTRACT(EPOCH FROM provisioner_jobs.completed_at - provisioner_jobs.started_at) * 1000), 0)
+		FROM
+			provisioner_jobs
+		WHERE
+			provisioner_jobs.provisioner = pending_jobs.provisioner
+			AND provisioner_jobs.tags = pending_jobs.tags
+			AND provisioner_jobs.started_at IS NOT NULL
+			AND provisioner_jobs.completed_at > NOW() - INTERVAL '1 day'
+	) :: bigint AS average_duration_ms
 FROM
-	organizations
+	pending_jobs
 WHERE
-	LOWER("name") = LOWER($1)
-LIMIT
-	1
+	pending_jobs.id = ANY($1 :: uuid [ ])
+`
+
+type GetProvisionerJobQueuePositionsByIDsRow struct {
+	ID                uuid.UUID        `db:"id" json:"id"`
+	Provisioner       ProvisionerType  `db:"provisioner" json:"provisioner"`
+	Tags              dbtype.StringMap `db:"tags" json:"tags"`
+	QueuePosition     int64            `db:"queue_position" json:"queue_position"`
+	QueueSize         int64            `db:"queue_size" json:"queue_size"`
+	RunningJobs       int64            `db:"running_jobs" json:"running_jobs"`
+	AverageDurationMs int64            `db:"average_duration_ms" json:"average_duration_ms"`
+}
+
+// Returns the position of pending jobs in the queue of pending jobs with the
+// same provisioner and tags, which are acquired by the same provisioner
+// daemons. The position accounts for priorities but not for fairness between
+// users and organizations, so it's an estimate. The running jobs and average
+// duration of recent jobs in the queue are returned to estimate the wait.
+func (q *sqlQuerier) GetProvisionerJobQueuePositionsByIDs(ctx context.Context, ids []uuid.UUID) ([]GetProvisionerJobQueuePositionsByIDsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getProvisionerJobQueuePositionsByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetProvisionerJobQueuePositionsByIDsRow
+	for rows.Next() {
+		var i GetProvisionerJobQueuePositionsByIDsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Provisioner,
+			&i.Tags,
+			&i.QueuePosition,
+			&i.QueueSize,
+			&i.RunningJobs,
+			&i.AverageDurationMs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getProvisionerJobsByIDs = `-- name: GetProvisionerJobsByIDs :many
+SELECT
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, priority, last_heartbeat_at
+FROM
+	provisioner_jobs
+WHERE
+	id = ANY($1 :: uuid [ ])
 `
 
-func (q *sqlQuerier) GetOrganizationByName(ctx context.Context, name string) (Organization, error) {
-	row := q.db.QueryRowContext(ctx, getOrganizationByName, name)
-	var i Organization
-	err := row.Scan(
-		&i.ID,
-		&i.Name,
-		&i.Description,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+func (q *sqlQuerier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]ProvisionerJob, error) {
+	rows, err := q.db.QueryContext(ctx, getProvisionerJobsByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProvisionerJob
+	for rows.Next() {
+		var i ProvisionerJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.CanceledAt,
+			&i.CompletedAt,
+			&i.Error,
+			&i.OrganizationID,
+			&i.InitiatorID,
+			&i.Provisioner,
+			&i.StorageMethod,
+			&i.Type,
+			&i.Input,
+			&i.WorkerID,
+			&i.FileID,
+			&i.Tags,
+			&i.Priority,
+			&i.LastHeartbeatAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const getOrganizations = `-- name: GetOrganizations :many
-SELECT
-	id, name, description, created_at, updated_at
-FROM
-	organizations
+const getProvisionerJobsCreatedAfter = `-- name: GetProvisionerJobsCreatedAfter :many
+SELECT id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, priority, last_heartbeat_at FROM provisioner_jobs WHERE created_at > $1
 `
 
-func (q *sqlQuerier) GetOrganizations(ctx context.Context) ([]Organization, error) {
-	rows, err := q.db.QueryContext(ctx, getOrganizations)
+func (q *sqlQuerier) GetProvisionerJobsCreatedAfter(ctx context.Context, createdAt time.Time) ([]ProvisionerJob, error) {
+	rows, err := q.db.QueryContext(ctx, getProvisionerJobsCreatedAfter, createdAt)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Organization
+	var items []ProvisionerJob
 	for rows.Next() {
-		var i Organization
+		var i ProvisionerJob
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
-			&i.Description,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.CanceledAt,
+			&i.CompletedAt,
+			&i.Error,
+			&i.OrganizationID,
+			&i.InitiatorID,
+			&i.Provisioner,
+			&i.StorageMethod,
+			&i.Type,
+			&i.Input,
+			&i.WorkerID,
+			&i.FileID,
+			&i.Tags,
+			&i.Priority,
+			&i.LastHeartbeatAt,
 		); err != nil {
 			return nil, err
 		}
@@ -1650,37 +4065,44 @@ func (q *sqlQuerier) GetOrganizations(ctx context.Context) ([]Organization, erro
 	return items, nil
 }
 
-const getOrganizationsByUserID = `-- name: GetOrganizationsByUserID :many
+const getRunningProvisionerJobs = `-- name: GetRunningProvisionerJobs :many
 SELECT
-	id, name, description, created_at, updated_at
+	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, priority, last_heartbeat_at
 FROM
-	organizations
+	provisioner_jobs
 WHERE
-	id = (
-		SELECT
-			organization_id
-		FROM
-			organization_members
-		WHERE
-			user_id = $1
-	)
+	started_at IS NOT NULL
+	AND completed_at IS NULL
 `
 
-func (q *sqlQuerier) GetOrganizationsByUserID(ctx context.Context, userID uuid.UUID) ([]Organization, error) {
-	rows, err := q.db.QueryContext(ctx, getOrganizationsByUserID, userID)
+func (q *sqlQuerier) GetRunningProvisionerJobs(ctx context.Context) ([]ProvisionerJob, error) {
+	rows, err := q.db.QueryContext(ctx, getRunningProvisionerJobs)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Organization
+	var items []ProvisionerJob
 	for rows.Next() {
-		var i Organization
+		var i ProvisionerJob
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
-			&i.Description,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.StartedAt,
+			&i.CanceledAt,
+			&i.CompletedAt,
+			&i.Error,
+			&i.OrganizationID,
+			&i.InitiatorID,
+			&i.Provisioner,
+			&i.StorageMethod,
+			&i.Type,
+			&i.Input,
+			&i.WorkerID,
+			&i.FileID,
+			&i.Tags,
+			&i.Priority,
+			&i.LastHeartbeatAt,
 		); err != nil {
 			return nil, err
 		}
@@ -1695,123 +4117,265 @@ func (q *sqlQuerier) GetOrganizationsByUserID(ctx context.Context, userID uuid.U
 	return items, nil
 }
 
-const insertOrganization = `-- name: InsertOrganization :one
+const insertProvisionerJob = `-- name: InsertProvisionerJob :one
 INSERT INTO
-	organizations (id, "name", description, created_at, updated_at)
+	provisioner_jobs (
+		id,
+		created_at,
+		updated_at,
+		organization_id,
+		initiator_id,
+		provisioner,
+		storage_method,
+		file_id,
+		"type",
+		"input",
+		tags,
+		priority
+	)
 VALUES
-	($1, $2, $3, $4, $5) RETURNING id, name, description, created_at, updated_at
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags, priority, last_heartbeat_at
 `
 
-type InsertOrganizationParams struct {
-	ID          uuid.UUID `db:"id" json:"id"`
-	Name        string    `db:"name" json:"name"`
-	Description string    `db:"description" json:"description"`
-	CreatedAt   time.Time `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+type InsertProvisionerJobParams struct {
+	ID             uuid.UUID                `db:"id" json:"id"`
+	CreatedAt      time.Time                `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time                `db:"updated_at" json:"updated_at"`
+	OrganizationID uuid.UUID                `db:"organization_id" json:"organization_id"`
+	InitiatorID    uuid.UUID                `db:"initiator_id" json:"initiator_id"`
+	Provisioner    ProvisionerType          `db:"provisioner" json:"provisioner"`
+	StorageMethod  ProvisionerStorageMethod `db:"storage_method" json:"storage_method"`
+	FileID         uuid.UUID                `db:"file_id" json:"file_id"`
+	Type           ProvisionerJobType       `db:"type" json:"type"`
+	Input          json.RawMessage          `db:"input" json:"input"`
+	Tags           dbtype.StringMap         `db:"tags" json:"tags"`
+	Priority       ProvisionerJobPriority   `db:"priority" json:"priority"`
 }
 
-func (q *sqlQuerier) InsertOrganization(ctx context.Context, arg InsertOrganizationParams) (Organization, error) {
-	row := q.db.QueryRowContext(ctx, insertOrganization,
+func (q *sqlQuerier) InsertProvisionerJob(ctx context.Context, arg InsertProvisionerJobParams) (ProvisionerJob, error) {
+	row := q.db.QueryRowContext(ctx, insertProvisionerJob,
 		arg.ID,
-		arg.Name,
-		arg.Description,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		arg.OrganizationID,
+		arg.InitiatorID,
+		arg.Provisioner,
+		arg.StorageMethod,
+		arg.FileID,
+		arg.Type,
+		arg.Input,
+		arg.Tags,
+		arg.Priority,
 	)
-	var i Organization
+	var i ProvisionerJob
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.StartedAt,
+		&i.CanceledAt,
+		&i.CompletedAt,
+		&i.Error,
+		&i.OrganizationID,
+		&i.InitiatorID,
+		&i.Provisioner,
+		&i.StorageMethod,
+		&i.Type,
+		&i.Input,
+		&i.WorkerID,
+		&i.FileID,
+		&i.Tags,
+		&i.Priority,
+		&i.LastHeartbeatAt,
+	)
+	return i, err
+}
+
+const updateProvisionerJobByID = `-- name: UpdateProvisionerJobByID :exec
+UPDATE
+	provisioner_jobs
+SET
+	updated_at = $2,
+	last_heartbeat_at = $2
+WHERE
+	id = $1
+`
+
+type UpdateProvisionerJobByIDParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Records an update from the provisioner daemon running the job, which is
+// the heartbeat of the job.
+func (q *sqlQuerier) UpdateProvisionerJobByID(ctx context.Context, arg UpdateProvisionerJobByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerJobByID, arg.ID, arg.UpdatedAt)
+	return err
+}
+
+const updateProvisionerJobWithCancelByID = `-- name: UpdateProvisionerJobWithCancelByID :exec
+UPDATE
+	provisioner_jobs
+SET
+	canceled_at = $2,
+	completed_at = $3
+WHERE
+	id = $1
+`
+
+type UpdateProvisionerJobWithCancelByIDParams struct {
+	ID          uuid.UUID    `db:"id" json:"id"`
+	CanceledAt  sql.NullTime `db:"canceled_at" json:"canceled_at"`
+	CompletedAt sql.NullTime `db:"completed_at" json:"completed_at"`
+}
+
+func (q *sqlQuerier) UpdateProvisionerJobWithCancelByID(ctx context.Context, arg UpdateProvisionerJobWithCancelByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerJobWithCancelByID, arg.ID, arg.CanceledAt, arg.CompletedAt)
+	return err
+}
+
+const updateProvisionerJobWithCompleteByID = `-- name: UpdateProvisionerJobWithCompleteByID :exec
+UPDATE
+	provisioner_jobs
+SET
+	updated_at = $2,
+	completed_at = $3,
+	error = $4
+WHERE
+	id = $1
+`
+
+type UpdateProvisionerJobWithCompleteByIDParams struct {
+	ID          uuid.UUID      `db:"id" json:"id"`
+	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
+	CompletedAt sql.NullTime   `db:"completed_at" json:"completed_at"`
+	Error       sql.NullString `db:"error" json:"error"`
+}
+
+func (q *sqlQuerier) UpdateProvisionerJobWithCompleteByID(ctx context.Context, arg UpdateProvisionerJobWithCompleteByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateProvisionerJobWithCompleteByID,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.CompletedAt,
+		arg.Error,
+	)
+	return err
+}
+
+const deleteProvisionerKeyByID = `-- name: DeleteProvisionerKeyByID :exec
+DELETE FROM
+	provisioner_keys
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) DeleteProvisionerKeyByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteProvisionerKeyByID, id)
+	return err
+}
+
+const getProvisionerKeyByHashedSecret = `-- name: GetProvisionerKeyByHashedSecret :one
+SELECT
+	id, created_at, organization_id, name, hashed_secret, tags
+FROM
+	provisioner_keys
+WHERE
+	hashed_secret = $1
+`
+
+func (q *sqlQuerier) GetProvisionerKeyByHashedSecret(ctx context.Context, hashedSecret []byte) (ProvisionerKey, error) {
+	row := q.db.QueryRowContext(ctx, getProvisionerKeyByHashedSecret, hashedSecret)
+	var i ProvisionerKey
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.OrganizationID,
+		&i.Name,
+		&i.HashedSecret,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const getProvisionerKeyByID = `-- name: GetProvisionerKeyByID :one
+SELECT
+	id, created_at, organization_id, name, hashed_secret, tags
+FROM
+	provisioner_keys
+WHERE
+	id = $1
+`
+
+func (q *sqlQuerier) GetProvisionerKeyByID(ctx context.Context, id uuid.UUID) (ProvisionerKey, error) {
+	row := q.db.QueryRowContext(ctx, getProvisionerKeyByID, id)
+	var i ProvisionerKey
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.OrganizationID,
+		&i.Name,
+		&i.HashedSecret,
+		&i.Tags,
+	)
+	return i, err
+}
+
+const getProvisionerKeyByOrganizationIDAndName = `-- name: GetProvisionerKeyByOrganizationIDAndName :one
+SELECT
+	id, created_at, organization_id, name, hashed_secret, tags
+FROM
+	provisioner_keys
+WHERE
+	organization_id = $1
+	AND "name" = $2
+`
+
+type GetProvisionerKeyByOrganizationIDAndNameParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Name           string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) GetProvisionerKeyByOrganizationIDAndName(ctx context.Context, arg GetProvisionerKeyByOrganizationIDAndNameParams) (ProvisionerKey, error) {
+	row := q.db.QueryRowContext(ctx, getProvisionerKeyByOrganizationIDAndName, arg.OrganizationID, arg.Name)
+	var i ProvisionerKey
 	err := row.Scan(
 		&i.ID,
-		&i.Name,
-		&i.Description,
 		&i.CreatedAt,
-		&i.UpdatedAt,
+		&i.OrganizationID,
+		&i.Name,
+		&i.HashedSecret,
+		&i.Tags,
 	)
 	return i, err
 }
 
-const getParameterSchemasByJobID = `-- name: GetParameterSchemasByJobID :many
+const getProvisionerKeysByOrganizationID = `-- name: GetProvisionerKeysByOrganizationID :many
 SELECT
-	id, created_at, job_id, name, description, default_source_scheme, default_source_value, allow_override_source, default_destination_scheme, allow_override_destination, default_refresh, redisplay_value, validation_error, validation_condition, validation_type_system, validation_value_type, index
+	id, created_at, organization_id, name, hashed_secret, tags
 FROM
-	parameter_schemas
+	provisioner_keys
 WHERE
-	job_id = $1
+	organization_id = $1
 ORDER BY
-	index
-`
-
-func (q *sqlQuerier) GetParameterSchemasByJobID(ctx context.Context, jobID uuid.UUID) ([]ParameterSchema, error) {
-	rows, err := q.db.QueryContext(ctx, getParameterSchemasByJobID, jobID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []ParameterSchema
-	for rows.Next() {
-		var i ParameterSchema
-		if err := rows.Scan(
-			&i.ID,
-			&i.CreatedAt,
-			&i.JobID,
-			&i.Name,
-			&i.Description,
-			&i.DefaultSourceScheme,
-			&i.DefaultSourceValue,
-			&i.AllowOverrideSource,
-			&i.DefaultDestinationScheme,
-			&i.AllowOverrideDestination,
-			&i.DefaultRefresh,
-			&i.RedisplayValue,
-			&i.ValidationError,
-			&i.ValidationCondition,
-			&i.ValidationTypeSystem,
-			&i.ValidationValueType,
-			&i.Index,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
-const getParameterSchemasCreatedAfter = `-- name: GetParameterSchemasCreatedAfter :many
-SELECT id, created_at, job_id, name, description, default_source_scheme, default_source_value, allow_override_source, default_destination_scheme, allow_override_destination, default_refresh, redisplay_value, validation_error, validation_condition, validation_type_system, validation_value_type, index FROM parameter_schemas WHERE created_at > $1
+	"name" ASC
 `
 
-func (q *sqlQuerier) GetParameterSchemasCreatedAfter(ctx context.Context, createdAt time.Time) ([]ParameterSchema, error) {
-	rows, err := q.db.QueryContext(ctx, getParameterSchemasCreatedAfter, createdAt)
+func (q *sqlQuerier) GetProvisionerKeysByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]ProvisionerKey, error) {
+	rows, err := q.db.QueryContext(ctx, getProvisionerKeysByOrganizationID, organizationID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ParameterSchema
+	var items []ProvisionerKey
 	for rows.Next() {
-		var i ParameterSchema
+		var i ProvisionerKey
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
-			&i.JobID,
+			&i.OrganizationID,
 			&i.Name,
-			&i.Description,
-			&i.DefaultSourceScheme,
-			&i.DefaultSourceValue,
-			&i.AllowOverrideSource,
-			&i.DefaultDestinationScheme,
-			&i.AllowOverrideDestination,
-			&i.DefaultRefresh,
-			&i.RedisplayValue,
-			&i.ValidationError,
-			&i.ValidationCondition,
-			&i.ValidationTypeSystem,
-			&i.ValidationValueType,
-			&i.Index,
+			&i.HashedSecret,
+			&i.Tags,
 		); err != nil {
 			return nil, err
 		}
@@ -1826,299 +4390,443 @@ func (q *sqlQuerier) GetParameterSchemasCreatedAfter(ctx context.Context, create
 	return items, nil
 }
 
-const insertParameterSchema = `-- name: InsertParameterSchema :one
+const insertProvisionerKey = `-- name: InsertProvisionerKey :one
 INSERT INTO
-	parameter_schemas (
+	provisioner_keys (
 		id,
 		created_at,
-		job_id,
+		organization_id,
 		"name",
-		description,
-		default_source_scheme,
-		default_source_value,
-		allow_override_source,
-		default_destination_scheme,
-		allow_override_destination,
-		default_refresh,
-		redisplay_value,
-		validation_error,
-		validation_condition,
-		validation_type_system,
-		validation_value_type,
-		index
+		hashed_secret,
+		tags
 	)
 VALUES
-	(
-		$1,
-		$2,
-		$3,
-		$4,
-		$5,
-		$6,
-		$7,
-		$8,
-		$9,
-		$10,
-		$11,
-		$12,
-		$13,
-		$14,
-		$15,
-		$16,
-		$17
-	) RETURNING id, created_at, job_id, name, description, default_source_scheme, default_source_value, allow_override_source, default_destination_scheme, allow_override_destination, default_refresh, redisplay_value, validation_error, validation_condition, validation_type_system, validation_value_type, index
+	($1, $2, $3, $4, $5, $6) RETURNING id, created_at, organization_id, name, hashed_secret, tags
 `
 
-type InsertParameterSchemaParams struct {
-	ID                       uuid.UUID                  `db:"id" json:"id"`
-	CreatedAt                time.Time                  `db:"created_at" json:"created_at"`
-	JobID                    uuid.UUID                  `db:"job_id" json:"job_id"`
-	Name                     string                     `db:"name" json:"name"`
-	Description              string                     `db:"description" json:"description"`
-	DefaultSourceScheme      ParameterSourceScheme      `db:"default_source_scheme" json:"default_source_scheme"`
-	DefaultSourceValue       string                     `db:"default_source_value" json:"default_source_value"`
-	AllowOverrideSource      bool                       `db:"allow_override_source" json:"allow_override_source"`
-	DefaultDestinationScheme ParameterDestinationScheme `db:"default_destination_scheme" json:"default_destination_scheme"`
-	AllowOverrideDestination bool                       `db:"allow_override_destination" json:"allow_override_destination"`
-	DefaultRefresh           string                     `db:"default_refresh" json:"default_refresh"`
-	RedisplayValue           bool                       `db:"redisplay_value" json:"redisplay_value"`
-	ValidationError          string                     `db:"validation_error" json:"validation_error"`
-	ValidationCondition      string                     `db:"validation_condition" json:"validation_condition"`
-	ValidationTypeSystem     ParameterTypeSystem        `db:"validation_type_system" json:"validation_type_system"`
-	ValidationValueType      string                     `db:"validation_value_type" json:"validation_value_type"`
-	Index                    int32                      `db:"index" json:"index"`
+type InsertProvisionerKeyParams struct {
+	ID             uuid.UUID        `db:"id" json:"id"`
+	CreatedAt      time.Time        `db:"created_at" json:"created_at"`
+	OrganizationID uuid.UUID        `db:"organization_id" json:"organization_id"`
+	Name           string           `db:"name" json:"name"`
+	HashedSecret   []byte           `db:"hashed_secret" json:"hashed_secret"`
+	Tags           dbtype.StringMap `db:"tags" json:"tags"`
 }
 
-func (q *sqlQuerier) InsertParameterSchema(ctx context.Context, arg InsertParameterSchemaParams) (ParameterSchema, error) {
-	row := q.db.QueryRowContext(ctx, insertParameterSchema,
+func (q *sqlQuerier) InsertProvisionerKey(ctx context.Context, arg InsertProvisionerKeyParams) (ProvisionerKey, error) {
+	row := q.db.QueryRowContext(ctx, insertProvisionerKey,
 		arg.ID,
 		arg.CreatedAt,
-		arg.JobID,
+		arg.OrganizationID,
 		arg.Name,
-		arg.Description,
-		arg.DefaultSourceScheme,
-		arg.DefaultSourceValue,
-		arg.AllowOverrideSource,
-		arg.DefaultDestinationScheme,
-		arg.AllowOverrideDestination,
-		arg.DefaultRefresh,
-		arg.RedisplayValue,
-		arg.ValidationError,
-		arg.ValidationCondition,
-		arg.ValidationTypeSystem,
-		arg.ValidationValueType,
-		arg.Index,
+		arg.HashedSecret,
+		arg.Tags,
 	)
-	var i ParameterSchema
+	var i ProvisionerKey
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
-		&i.JobID,
+		&i.OrganizationID,
 		&i.Name,
-		&i.Description,
-		&i.DefaultSourceScheme,
-		&i.DefaultSourceValue,
-		&i.AllowOverrideSource,
-		&i.DefaultDestinationScheme,
-		&i.AllowOverrideDestination,
-		&i.DefaultRefresh,
-		&i.RedisplayValue,
-		&i.ValidationError,
-		&i.ValidationCondition,
-		&i.ValidationTypeSystem,
-		&i.ValidationValueType,
-		&i.Index,
+		&i.HashedSecret,
+		&i.Tags,
 	)
 	return i, err
 }
 
-const deleteParameterValueByID = `-- name: DeleteParameterValueByID :exec
-DELETE FROM
-	parameter_values
+const getQuotaAllowanceForUser = `-- name: GetQuotaAllowanceForUser :one
+SELECT
+	coalesce(SUM(quota_allowance), 0)::BIGINT
+FROM
+	group_members gm
+JOIN groups g ON
+	g.id = gm.group_id
 WHERE
-	id = $1
+	user_id = $1
 `
 
-func (q *sqlQuerier) DeleteParameterValueByID(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, deleteParameterValueByID, id)
+func (q *sqlQuerier) GetQuotaAllowanceForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getQuotaAllowanceForUser, userID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const getQuotaConsumedForUser = `-- name: GetQuotaConsumedForUser :one
+WITH latest_builds AS (
+SELECT
+	DISTINCT ON
+	(workspace_id) id,
+	workspace_id,
+	daily_cost
+FROM
+	workspace_builds wb
+ORDER BY
+	workspace_id,
+	created_at DESC
+)
+SELECT
+	coalesce(SUM(daily_cost), 0)::BIGINT
+FROM
+	workspaces
+JOIN latest_builds ON
+	latest_builds.workspace_id = workspaces.id
+WHERE NOT deleted AND workspaces.owner_id = $1
+`
+
+func (q *sqlQuerier) GetQuotaConsumedForUser(ctx context.Context, ownerID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getQuotaConsumedForUser, ownerID)
+	var column_1 int64
+	err := row.Scan(&column_1)
+	return column_1, err
+}
+
+const deleteReplicasUpdatedBefore = `-- name: DeleteReplicasUpdatedBefore :exec
+DELETE FROM replicas WHERE updated_at < $1
+`
+
+func (q *sqlQuerier) DeleteReplicasUpdatedBefore(ctx context.Context, updatedAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteReplicasUpdatedBefore, updatedAt)
 	return err
 }
 
-const getParameterValueByScopeAndName = `-- name: GetParameterValueByScopeAndName :one
-SELECT
-	id, created_at, updated_at, scope, scope_id, name, source_scheme, source_value, destination_scheme
-FROM
-	parameter_values
-WHERE
-	scope = $1
-	AND scope_id = $2
-	AND NAME = $3
-LIMIT
-	1
+const getReplicasUpdatedAfter = `-- name: GetReplicasUpdatedAfter :many
+SELECT id, created_at, started_at, stopped_at, updated_at, hostname, region_id, relay_address, database_latency, version, error FROM replicas WHERE updated_at > $1 AND stopped_at IS NULL
+`
+
+func (q *sqlQuerier) GetReplicasUpdatedAfter(ctx context.Context, updatedAt time.Time) ([]Replica, error) {
+	rows, err := q.db.QueryContext(ctx, getReplicasUpdatedAfter, updatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Replica
+	for rows.Next() {
+		var i Replica
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.StartedAt,
+			&i.StoppedAt,
+			&i.UpdatedAt,
+			&i.Hostname,
+			&i.RegionID,
+			&i.RelayAddress,
+			&i.DatabaseLatency,
+			&i.Version,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const insertReplica = `-- name: InsertReplica :one
+INSERT INTO replicas (
+    id,
+    created_at,
+    started_at,
+    updated_at,
+    hostname,
+    region_id,
+    relay_address,
+    version,
+    database_latency
+) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, started_at, stopped_at, updated_at, hostname, region_id, relay_address, database_latency, version, error
 `
 
-type GetParameterValueByScopeAndNameParams struct {
-	Scope   ParameterScope `db:"scope" json:"scope"`
-	ScopeID uuid.UUID      `db:"scope_id" json:"scope_id"`
-	Name    string         `db:"name" json:"name"`
+type InsertReplicaParams struct {
+	ID              uuid.UUID `db:"id" json:"id"`
+	CreatedAt       time.Time `db:"created_at" json:"created_at"`
+	StartedAt       time.Time `db:"started_at" json:"started_at"`
+	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
+	Hostname        string    `db:"hostname" json:"hostname"`
+	RegionID        int32     `db:"region_id" json:"region_id"`
+	RelayAddress    string    `db:"relay_address" json:"relay_address"`
+	Version         string    `db:"version" json:"version"`
+	DatabaseLatency int32     `db:"database_latency" json:"database_latency"`
 }
 
-func (q *sqlQuerier) GetParameterValueByScopeAndName(ctx context.Context, arg GetParameterValueByScopeAndNameParams) (ParameterValue, error) {
-	row := q.db.QueryRowContext(ctx, getParameterValueByScopeAndName, arg.Scope, arg.ScopeID, arg.Name)
-	var i ParameterValue
+func (q *sqlQuerier) InsertReplica(ctx context.Context, arg InsertReplicaParams) (Replica, error) {
+	row := q.db.QueryRowContext(ctx, insertReplica,
+		arg.ID,
+		arg.CreatedAt,
+		arg.StartedAt,
+		arg.UpdatedAt,
+		arg.Hostname,
+		arg.RegionID,
+		arg.RelayAddress,
+		arg.Version,
+		arg.DatabaseLatency,
+	)
+	var i Replica
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
+		&i.StartedAt,
+		&i.StoppedAt,
 		&i.UpdatedAt,
-		&i.Scope,
-		&i.ScopeID,
-		&i.Name,
-		&i.SourceScheme,
-		&i.SourceValue,
-		&i.DestinationScheme,
+		&i.Hostname,
+		&i.RegionID,
+		&i.RelayAddress,
+		&i.DatabaseLatency,
+		&i.Version,
+		&i.Error,
 	)
 	return i, err
 }
 
-const insertParameterValue = `-- name: InsertParameterValue :one
-INSERT INTO
-	parameter_values (
-		id,
-		"name",
-		created_at,
-		updated_at,
-		scope,
-		scope_id,
-		source_scheme,
-		source_value,
-		destination_scheme
-	)
-VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, updated_at, scope, scope_id, name, source_scheme, source_value, destination_scheme
+const updateReplica = `-- name: UpdateReplica :one
+UPDATE replicas SET
+    updated_at = $2,
+    started_at = $3,
+    stopped_at = $4,
+    relay_address = $5,
+    region_id = $6,
+    hostname = $7,
+    version = $8,
+    error = $9,
+    database_latency = $10
+WHERE id = $1 RETURNING id, created_at, started_at, stopped_at, updated_at, hostname, region_id, relay_address, database_latency, version, error
 `
 
-type InsertParameterValueParams struct {
-	ID                uuid.UUID                  `db:"id" json:"id"`
-	Name              string                     `db:"name" json:"name"`
-	CreatedAt         time.Time                  `db:"created_at" json:"created_at"`
-	UpdatedAt         time.Time                  `db:"updated_at" json:"updated_at"`
-	Scope             ParameterScope             `db:"scope" json:"scope"`
-	ScopeID           uuid.UUID                  `db:"scope_id" json:"scope_id"`
-	SourceScheme      ParameterSourceScheme      `db:"source_scheme" json:"source_scheme"`
-	SourceValue       string                     `db:"source_value" json:"source_value"`
-	DestinationScheme ParameterDestinationScheme `db:"destination_scheme" json:"destination_scheme"`
+type UpdateReplicaParams struct {
+	ID              uuid.UUID    `db:"id" json:"id"`
+	UpdatedAt       time.Time    `db:"updated_at" json:"updated_at"`
+	StartedAt       time.Time    `db:"started_at" json:"started_at"`
+	StoppedAt       sql.NullTime `db:"stopped_at" json:"stopped_at"`
+	RelayAddress    string       `db:"relay_address" json:"relay_address"`
+	RegionID        int32        `db:"region_id" json:"region_id"`
+	Hostname        string       `db:"hostname" json:"hostname"`
+	Version         string       `db:"version" json:"version"`
+	Error           string       `db:"error" json:"error"`
+	DatabaseLatency int32        `db:"database_latency" json:"database_latency"`
 }
 
-func (q *sqlQuerier) InsertParameterValue(ctx context.Context, arg InsertParameterValueParams) (ParameterValue, error) {
-	row := q.db.QueryRowContext(ctx, insertParameterValue,
+func (q *sqlQuerier) UpdateReplica(ctx context.Context, arg UpdateReplicaParams) (Replica, error) {
+	row := q.db.QueryRowContext(ctx, updateReplica,
 		arg.ID,
-		arg.Name,
-		arg.CreatedAt,
 		arg.UpdatedAt,
-		arg.Scope,
-		arg.ScopeID,
-		arg.SourceScheme,
-		arg.SourceValue,
-		arg.DestinationScheme,
+		arg.StartedAt,
+		arg.StoppedAt,
+		arg.RelayAddress,
+		arg.RegionID,
+		arg.Hostname,
+		arg.Version,
+		arg.Error,
+		arg.DatabaseLatency,
 	)
-	var i ParameterValue
+	var i Replica
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
+		&i.StartedAt,
+		&i.StoppedAt,
 		&i.UpdatedAt,
-		&i.Scope,
-		&i.ScopeID,
-		&i.Name,
-		&i.SourceScheme,
-		&i.SourceValue,
-		&i.DestinationScheme,
+		&i.Hostname,
+		&i.RegionID,
+		&i.RelayAddress,
+		&i.DatabaseLatency,
+		&i.Version,
+		&i.Error,
 	)
 	return i, err
 }
 
-const parameterValue = `-- name: ParameterValue :one
-SELECT id, created_at, updated_at, scope, scope_id, name, source_scheme, source_value, destination_scheme FROM
-	parameter_values
+const getDERPMeshKey = `-- name: GetDERPMeshKey :one
+SELECT value FROM site_configs WHERE key = 'derp_mesh_key'
+`
+
+func (q *sqlQuerier) GetDERPMeshKey(ctx context.Context) (string, error) {
+	row := q.db.QueryRowContext(ctx, getDERPMeshKey)
+	var value string
+	err := row.Scan(&value)
+	return value, err
+}
+
+const getDeploymentID = `-- name: GetDeploymentID :one
+SELECT value FROM site_configs WHERE key = 'deployment_id'
+`
+
+func (q *sqlQuerier) GetDeploymentID(ctx context.Context) (string, error) {
+	row := q.db.QueryRowContext(ctx, getDeploymentID)
+	var value string
+	err := row.Scan(&value)
+	return value, err
+}
+
+const getLastUpdateCheck = `-- name: GetLastUpdateCheck :one
+SELECT value FROM site_configs WHERE key = 'last_update_check'
+`
+
+func (q *sqlQuerier) GetLastUpdateCheck(ctx context.Context) (string, error) {
+	row := q.db.QueryRowContext(ctx, getLastUpdateCheck)
+	var value string
+	err := row.Scan(&value)
+	return value, err
+}
+
+const getLogoURL = `-- name: GetLogoURL :one
+SELECT value FROM site_configs WHERE key = 'logo_url'
+`
+
+func (q *sqlQuerier) GetLogoURL(ctx context.Context) (string, error) {
+	row := q.db.QueryRowContext(ctx, getLogoURL)
+	var value string
+	err := row.Scan(&value)
+	return value, err
+}
+
+const getServiceBanner = `-- name: GetServiceBanner :one
+SELECT value FROM site_configs WHERE key = 'service_banner'
+`
+
+func (q *sqlQuerier) GetServiceBanner(ctx context.Context) (string, error) {
+	row := q.db.QueryRowContext(ctx, getServiceBanner)
+	var value string
+	err := row.Scan(&value)
+	return value, err
+}
+
+const insertDERPMeshKey = `-- name: InsertDERPMeshKey :exec
+INSERT INTO site_configs (key, value) VALUES ('derp_mesh_key', $1)
+`
+
+func (q *sqlQuerier) InsertDERPMeshKey(ctx context.Context, value string) error {
+	_, err := q.db.ExecContext(ctx, insertDERPMeshKey, value)
+	return err
+}
+
+const insertDeploymentID = `-- name: InsertDeploymentID :exec
+INSERT INTO site_configs (key, value) VALUES ('deployment_id', $1)
+`
+
+func (q *sqlQuerier) InsertDeploymentID(ctx context.Context, value string) error {
+	_, err := q.db.ExecContext(ctx, insertDeploymentID, value)
+	return err
+}
+
+const insertOrUpdateLastUpdateCheck = `-- name: InsertOrUpdateLastUpdateCheck :exec
+INSERT INTO site_configs (key, value) VALUES ('last_update_check', $1)
+ON CONFLICT (key) DO UPDATE SET value = $1 WHERE site_configs.key = 'last_update_check'
+`
+
+func (q *sqlQuerier) InsertOrUpdateLastUpdateCheck(ctx context.Context, value string) error {
+	_, err := q.db.ExecContext(ctx, insertOrUpdateLastUpdateCheck, value)
+	return err
+}
+
+const insertOrUpdateLogoURL = `-- name: InsertOrUpdateLogoURL :exec
+INSERT INTO site_configs (key, value) VALUES ('logo_url', $1)
+ON CONFLICT (key) DO UPDATE SET value = $1 WHERE site_configs.key = 'logo_url'
+`
+
+func (q *sqlQuerier) InsertOrUpdateLogoURL(ctx context.Context, value string) error {
+	_, err := q.db.ExecContext(ctx, insertOrUpdateLogoURL, value)
+	return err
+}
+
+const insertOrUpdateServiceBanner = `-- name: InsertOrUpdateServiceBanner :exec
+INSERT INTO site_configs (key, value) VALUES ('service_banner', $1)
+ON CONFLICT (key) DO UPDATE SET value = $1 WHERE site_configs.key = 'service_banner'
+`
+
+func (q *sqlQuerier) InsertOrUpdateServiceBanner(ctx context.Context, value string) error {
+	_, err := q.db.ExecContext(ctx, insertOrUpdateServiceBanner, value)
+	return err
+}
+
+const deleteTemplateGitSyncByTemplateID = `-- name: DeleteTemplateGitSyncByTemplateID :exec
+DELETE FROM
+	template_git_syncs
 WHERE
-	id = $1
+	template_id = $1
 `
 
-func (q *sqlQuerier) ParameterValue(ctx context.Context, id uuid.UUID) (ParameterValue, error) {
-	row := q.db.QueryRowContext(ctx, parameterValue, id)
-	var i ParameterValue
+func (q *sqlQuerier) DeleteTemplateGitSyncByTemplateID(ctx context.Context, templateID uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteTemplateGitSyncByTemplateID, templateID)
+	return err
+}
+
+const getTemplateGitSyncByTemplateID = `-- name: GetTemplateGitSyncByTemplateID :one
+SELECT
+	template_id, created_at, updated_at, user_id, git_url, git_branch, git_directory, webhook_secret, commit_sha, template_version_id, status, error, checked_at, dry_run_job_id
+FROM
+	template_git_syncs
+WHERE
+	template_id = $1
+LIMIT
+	1
+`
+
+func (q *sqlQuerier) GetTemplateGitSyncByTemplateID(ctx context.Context, templateID uuid.UUID) (TemplateGitSync, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateGitSyncByTemplateID, templateID)
+	var i TemplateGitSync
 	err := row.Scan(
-		&i.ID,
+		&i.TemplateID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Scope,
-		&i.ScopeID,
-		&i.Name,
-		&i.SourceScheme,
-		&i.SourceValue,
-		&i.DestinationScheme,
+		&i.UserID,
+		&i.GitURL,
+		&i.GitBranch,
+		&i.GitDirectory,
+		&i.WebhookSecret,
+		&i.CommitSHA,
+		&i.TemplateVersionID,
+		&i.Status,
+		&i.Error,
+		&i.CheckedAt,
+		&i.DryRunJobID,
 	)
 	return i, err
 }
 
-const parameterValues = `-- name: ParameterValues :many
+const getTemplateGitSyncs = `-- name: GetTemplateGitSyncs :many
 SELECT
-	id, created_at, updated_at, scope, scope_id, name, source_scheme, source_value, destination_scheme
+	template_git_syncs.template_id, template_git_syncs.created_at, template_git_syncs.updated_at, template_git_syncs.user_id, template_git_syncs.git_url, template_git_syncs.git_branch, template_git_syncs.git_directory, template_git_syncs.webhook_secret, template_git_syncs.commit_sha, template_git_syncs.template_version_id, template_git_syncs.status, template_git_syncs.error, template_git_syncs.checked_at, template_git_syncs.dry_run_job_id
 FROM
-	parameter_values
+	template_git_syncs
+JOIN
+	templates ON templates.id = template_git_syncs.template_id
 WHERE
-  	CASE
-		  WHEN cardinality($1 :: parameter_scope[]) > 0 THEN
-				  scope = ANY($1 :: parameter_scope[])
-		  ELSE true
-	END
-    AND CASE
-		WHEN cardinality($2 :: uuid[]) > 0 THEN
-			scope_id = ANY($2 :: uuid[])
-		ELSE true
-	END
-  	AND CASE
-		WHEN cardinality($3 :: uuid[]) > 0 THEN
-			id = ANY($3 :: uuid[])
-		ELSE true
-	END
-  	AND CASE
-		  WHEN cardinality($4 :: text[]) > 0 THEN
-				  "name" = ANY($4 :: text[])
-		  ELSE true
-	END
+	NOT templates.deleted
+ORDER BY
+	template_git_syncs.created_at ASC
 `
 
-type ParameterValuesParams struct {
-	Scopes   []ParameterScope `db:"scopes" json:"scopes"`
-	ScopeIds []uuid.UUID      `db:"scope_ids" json:"scope_ids"`
-	IDs      []uuid.UUID      `db:"ids" json:"ids"`
-	Names    []string         `db:"names" json:"names"`
-}
-
-func (q *sqlQuerier) ParameterValues(ctx context.Context, arg ParameterValuesParams) ([]ParameterValue, error) {
-	rows, err := q.db.QueryContext(ctx, parameterValues,
-		pq.Array(arg.Scopes),
-		pq.Array(arg.ScopeIds),
-		pq.Array(arg.IDs),
-		pq.Array(arg.Names),
-	)
+// Returns the git syncs of templates that haven't been deleted.
+func (q *sqlQuerier) GetTemplateGitSyncs(ctx context.Context) ([]TemplateGitSync, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateGitSyncs)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ParameterValue
+	var items []TemplateGitSync
 	for rows.Next() {
-		var i ParameterValue
+		var i TemplateGitSync
 		if err := rows.Scan(
-			&i.ID,
+			&i.TemplateID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.Scope,
-			&i.ScopeID,
-			&i.Name,
-			&i.SourceScheme,
-			&i.SourceValue,
-			&i.DestinationScheme,
+			&i.UserID,
+			&i.GitURL,
+			&i.GitBranch,
+			&i.GitDirectory,
+			&i.WebhookSecret,
+			&i.CommitSHA,
+			&i.TemplateVersionID,
+			&i.Status,
+			&i.Error,
+			&i.CheckedAt,
+			&i.DryRunJobID,
 		); err != nil {
 			return nil, err
 		}
@@ -2133,30 +4841,39 @@ func (q *sqlQuerier) ParameterValues(ctx context.Context, arg ParameterValuesPar
 	return items, nil
 }
 
-const getProvisionerDaemons = `-- name: GetProvisionerDaemons :many
+const getTemplateGitSyncsByTemplateIDs = `-- name: GetTemplateGitSyncsByTemplateIDs :many
 SELECT
-	id, created_at, updated_at, name, provisioners, replica_id, tags
+	template_id, created_at, updated_at, user_id, git_url, git_branch, git_directory, webhook_secret, commit_sha, template_version_id, status, error, checked_at, dry_run_job_id
 FROM
-	provisioner_daemons
+	template_git_syncs
+WHERE
+	template_id = ANY($1 :: uuid[])
 `
 
-func (q *sqlQuerier) GetProvisionerDaemons(ctx context.Context) ([]ProvisionerDaemon, error) {
-	rows, err := q.db.QueryContext(ctx, getProvisionerDaemons)
+func (q *sqlQuerier) GetTemplateGitSyncsByTemplateIDs(ctx context.Context, ids []uuid.UUID) ([]TemplateGitSync, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateGitSyncsByTemplateIDs, pq.Array(ids))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ProvisionerDaemon
+	var items []TemplateGitSync
 	for rows.Next() {
-		var i ProvisionerDaemon
+		var i TemplateGitSync
 		if err := rows.Scan(
-			&i.ID,
+			&i.TemplateID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.Name,
-			pq.Array(&i.Provisioners),
-			&i.ReplicaID,
-			&i.Tags,
+			&i.UserID,
+			&i.GitURL,
+			&i.GitBranch,
+			&i.GitDirectory,
+			&i.WebhookSecret,
+			&i.CommitSHA,
+			&i.TemplateVersionID,
+			&i.Status,
+			&i.Error,
+			&i.CheckedAt,
+			&i.DryRunJobID,
 		); err != nil {
 			return nil, err
 		}
@@ -2171,295 +4888,416 @@ func (q *sqlQuerier) GetProvisionerDaemons(ctx context.Context) ([]ProvisionerDa
 	return items, nil
 }
 
-const insertProvisionerDaemon = `-- name: InsertProvisionerDaemon :one
-INSERT INTO
-	provisioner_daemons (
-		id,
-		created_at,
-		"name",
-		provisioners,
-		tags
+const updateTemplateGitSyncCommitByTemplateID = `-- name: UpdateTemplateGitSyncCommitByTemplateID :one
+UPDATE
+	template_git_syncs
+SET
+	commit_sha = $1,
+	checked_at = $2,
+	template_version_id = NULL,
+	dry_run_job_id = NULL,
+	status = 'pending',
+	error = ''
+WHERE
+	template_id = $3
+	AND commit_sha = $4
+	AND (
+		commit_sha != $1
+		OR (
+			template_version_id IS NULL
+			AND checked_at < $5 :: timestamptz
+		)
 	)
-VALUES
-	($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at, name, provisioners, replica_id, tags
+RETURNING template_id, created_at, updated_at, user_id, git_url, git_branch, git_directory, webhook_secret, commit_sha, template_version_id, status, error, checked_at, dry_run_job_id
 `
 
-type InsertProvisionerDaemonParams struct {
-	ID           uuid.UUID         `db:"id" json:"id"`
-	CreatedAt    time.Time         `db:"created_at" json:"created_at"`
-	Name         string            `db:"name" json:"name"`
-	Provisioners []ProvisionerType `db:"provisioners" json:"provisioners"`
-	Tags         dbtype.StringMap  `db:"tags" json:"tags"`
+type UpdateTemplateGitSyncCommitByTemplateIDParams struct {
+	CommitSHA         string       `db:"commit_sha" json:"commit_sha"`
+	CheckedAt         sql.NullTime `db:"checked_at" json:"checked_at"`
+	TemplateID        uuid.UUID    `db:"template_id" json:"template_id"`
+	PreviousCommitSHA string       `db:"previous_commit_sha" json:"previous_commit_sha"`
+	StaleBefore       time.Time    `db:"stale_before" json:"stale_before"`
 }
 
-func (q *sqlQuerier) InsertProvisionerDaemon(ctx context.Context, arg InsertProvisionerDaemonParams) (ProvisionerDaemon, error) {
-	row := q.db.QueryRowContext(ctx, insertProvisionerDaemon,
-		arg.ID,
-		arg.CreatedAt,
-		arg.Name,
-		pq.Array(arg.Provisioners),
-		arg.Tags,
+// Updating the commit claims it, so only one replica creates a version for
+// it. If another replica has already claimed it, no rows are returned. A
+// claim of the same commit without a version is stale once it was checked
+// before the cutoff, e.g. because the replica that claimed it was stopped, and
+// can be claimed again.
+func (q *sqlQuerier) UpdateTemplateGitSyncCommitByTemplateID(ctx context.Context, arg UpdateTemplateGitSyncCommitByTemplateIDParams) (TemplateGitSync, error) {
+	row := q.db.QueryRowContext(ctx, updateTemplateGitSyncCommitByTemplateID,
+		arg.CommitSHA,
+		arg.CheckedAt,
+		arg.TemplateID,
+		arg.PreviousCommitSHA,
+		arg.StaleBefore,
 	)
-	var i ProvisionerDaemon
+	var i TemplateGitSync
 	err := row.Scan(
-		&i.ID,
+		&i.TemplateID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Name,
-		pq.Array(&i.Provisioners),
-		&i.ReplicaID,
-		&i.Tags,
+		&i.UserID,
+		&i.GitURL,
+		&i.GitBranch,
+		&i.GitDirectory,
+		&i.WebhookSecret,
+		&i.CommitSHA,
+		&i.TemplateVersionID,
+		&i.Status,
+		&i.Error,
+		&i.CheckedAt,
+		&i.DryRunJobID,
 	)
 	return i, err
 }
 
-const getProvisionerLogsByIDBetween = `-- name: GetProvisionerLogsByIDBetween :many
-SELECT
-	job_id, created_at, source, level, stage, output, id
-FROM
-	provisioner_job_logs
+const updateTemplateGitSyncDryRunJobByTemplateID = `-- name: UpdateTemplateGitSyncDryRunJobByTemplateID :one
+UPDATE
+	template_git_syncs
+SET
+	dry_run_job_id = $1,
+	checked_at = $2
 WHERE
-	job_id = $1
-	AND (
-		id > $2
-		OR id < $3
-	) ORDER BY id ASC
+	template_id = $3
+	AND template_version_id = $4
+	AND dry_run_job_id IS NULL
+RETURNING template_id, created_at, updated_at, user_id, git_url, git_branch, git_directory, webhook_secret, commit_sha, template_version_id, status, error, checked_at, dry_run_job_id
+`
+
+type UpdateTemplateGitSyncDryRunJobByTemplateIDParams struct {
+	DryRunJobID       uuid.NullUUID `db:"dry_run_job_id" json:"dry_run_job_id"`
+	CheckedAt         sql.NullTime  `db:"checked_at" json:"checked_at"`
+	TemplateID        uuid.UUID     `db:"template_id" json:"template_id"`
+	TemplateVersionID uuid.NullUUID `db:"template_version_id" json:"template_version_id"`
+}
+
+// Setting the dry-run job claims the dry-run of the version, so only one
+// replica creates it. If another replica has already claimed it, or a version
+// was created for a newer commit, no rows are returned.
+func (q *sqlQuerier) UpdateTemplateGitSyncDryRunJobByTemplateID(ctx context.Context, arg UpdateTemplateGitSyncDryRunJobByTemplateIDParams) (TemplateGitSync, error) {
+	row := q.db.QueryRowContext(ctx, updateTemplateGitSyncDryRunJobByTemplateID,
+		arg.DryRunJobID,
+		arg.CheckedAt,
+		arg.TemplateID,
+		arg.TemplateVersionID,
+	)
+	var i TemplateGitSync
+	err := row.Scan(
+		&i.TemplateID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.GitURL,
+		&i.GitBranch,
+		&i.GitDirectory,
+		&i.WebhookSecret,
+		&i.CommitSHA,
+		&i.TemplateVersionID,
+		&i.Status,
+		&i.Error,
+		&i.CheckedAt,
+		&i.DryRunJobID,
+	)
+	return i, err
+}
+
+const updateTemplateGitSyncStatusByTemplateID = `-- name: UpdateTemplateGitSyncStatusByTemplateID :one
+UPDATE
+	template_git_syncs
+SET
+	commit_sha = $2,
+	template_version_id = $3,
+	status = $4,
+	error = $5,
+	checked_at = $6
+WHERE
+	template_id = $1
+RETURNING template_id, created_at, updated_at, user_id, git_url, git_branch, git_directory, webhook_secret, commit_sha, template_version_id, status, error, checked_at, dry_run_job_id
 `
 
-type GetProvisionerLogsByIDBetweenParams struct {
-	JobID         uuid.UUID `db:"job_id" json:"job_id"`
-	CreatedAfter  int64     `db:"created_after" json:"created_after"`
-	CreatedBefore int64     `db:"created_before" json:"created_before"`
+type UpdateTemplateGitSyncStatusByTemplateIDParams struct {
+	TemplateID        uuid.UUID     `db:"template_id" json:"template_id"`
+	CommitSHA         string        `db:"commit_sha" json:"commit_sha"`
+	TemplateVersionID uuid.NullUUID `db:"template_version_id" json:"template_version_id"`
+	Status            string        `db:"status" json:"status"`
+	Error             string        `db:"error" json:"error"`
+	CheckedAt         sql.NullTime  `db:"checked_at" json:"checked_at"`
 }
 
-func (q *sqlQuerier) GetProvisionerLogsByIDBetween(ctx context.Context, arg GetProvisionerLogsByIDBetweenParams) ([]ProvisionerJobLog, error) {
-	rows, err := q.db.QueryContext(ctx, getProvisionerLogsByIDBetween, arg.JobID, arg.CreatedAfter, arg.CreatedBefore)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []ProvisionerJobLog
-	for rows.Next() {
-		var i ProvisionerJobLog
-		if err := rows.Scan(
-			&i.JobID,
-			&i.CreatedAt,
-			&i.Source,
-			&i.Level,
-			&i.Stage,
-			&i.Output,
-			&i.ID,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+func (q *sqlQuerier) UpdateTemplateGitSyncStatusByTemplateID(ctx context.Context, arg UpdateTemplateGitSyncStatusByTemplateIDParams) (TemplateGitSync, error) {
+	row := q.db.QueryRowContext(ctx, updateTemplateGitSyncStatusByTemplateID,
+		arg.TemplateID,
+		arg.CommitSHA,
+		arg.TemplateVersionID,
+		arg.Status,
+		arg.Error,
+		arg.CheckedAt,
+	)
+	var i TemplateGitSync
+	err := row.Scan(
+		&i.TemplateID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.GitURL,
+		&i.GitBranch,
+		&i.GitDirectory,
+		&i.WebhookSecret,
+		&i.CommitSHA,
+		&i.TemplateVersionID,
+		&i.Status,
+		&i.Error,
+		&i.CheckedAt,
+		&i.DryRunJobID,
+	)
+	return i, err
+}
+
+const upsertTemplateGitSync = `-- name: UpsertTemplateGitSync :one
+INSERT INTO
+	template_git_syncs (
+		template_id,
+		created_at,
+		updated_at,
+		user_id,
+		git_url,
+		git_branch,
+		git_directory,
+		webhook_secret
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (template_id) DO UPDATE SET
+	updated_at = $3,
+	user_id = $4,
+	git_url = $5,
+	git_branch = $6,
+	git_directory = $7,
+	commit_sha = '',
+	template_version_id = NULL,
+	dry_run_job_id = NULL,
+	status = 'pending',
+	error = ''
+RETURNING template_id, created_at, updated_at, user_id, git_url, git_branch, git_directory, webhook_secret, commit_sha, template_version_id, status, error, checked_at, dry_run_job_id
+`
+
+type UpsertTemplateGitSyncParams struct {
+	TemplateID    uuid.UUID `db:"template_id" json:"template_id"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at" json:"updated_at"`
+	UserID        uuid.UUID `db:"user_id" json:"user_id"`
+	GitURL        string    `db:"git_url" json:"git_url"`
+	GitBranch     string    `db:"git_branch" json:"git_branch"`
+	GitDirectory  string    `db:"git_directory" json:"git_directory"`
+	WebhookSecret string    `db:"webhook_secret" json:"webhook_secret"`
+}
+
+// Changing the repository of a sync resets it, so a version is created for
+// the latest commit of the new branch. The webhook secret is kept.
+func (q *sqlQuerier) UpsertTemplateGitSync(ctx context.Context, arg UpsertTemplateGitSyncParams) (TemplateGitSync, error) {
+	row := q.db.QueryRowContext(ctx, upsertTemplateGitSync,
+		arg.TemplateID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.GitURL,
+		arg.GitBranch,
+		arg.GitDirectory,
+		arg.WebhookSecret,
+	)
+	var i TemplateGitSync
+	err := row.Scan(
+		&i.TemplateID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.GitURL,
+		&i.GitBranch,
+		&i.GitDirectory,
+		&i.WebhookSecret,
+		&i.CommitSHA,
+		&i.TemplateVersionID,
+		&i.Status,
+		&i.Error,
+		&i.CheckedAt,
+		&i.DryRunJobID,
+	)
+	return i, err
 }
 
-const insertProvisionerJobLogs = `-- name: InsertProvisionerJobLogs :many
-INSERT INTO
-	provisioner_job_logs
+const getTemplateAverageBuildTime = `-- name: GetTemplateAverageBuildTime :one
+WITH build_times AS (
 SELECT
-	$1 :: uuid AS job_id,
-	unnest($2 :: timestamptz [ ]) AS created_at,
-	unnest($3 :: log_source [ ]) AS source,
-	unnest($4 :: log_level [ ]) AS LEVEL,
-	unnest($5 :: VARCHAR(128) [ ]) AS stage,
-	unnest($6 :: VARCHAR(1024) [ ]) AS output RETURNING job_id, created_at, source, level, stage, output, id
+	EXTRACT(EPOCH FROM (pj.completed_at - pj.started_at))::FLOAT AS exec_time_sec,
+	workspace_builds.transition
+FROM
+	workspace_builds
+JOIN template_versions ON
+	workspace_builds.template_version_id = template_versions.id
+JOIN provisioner_jobs pj ON
+	workspace_builds.job_id = pj.id
+WHERE
+	template_versions.template_id = $1 AND
+		(pj.completed_at IS NOT NULL) AND (pj.started_at IS NOT NULL) AND
+		(pj.started_at > $2) AND
+		(pj.canceled_at IS NULL) AND
+		((pj.error IS NULL) OR (pj.error = ''))
+ORDER BY
+	workspace_builds.created_at DESC
+)
+SELECT
+	-- Postgres offers no clear way to DRY this short of a function or other
+	-- complexities.
+	coalesce((PERCENTILE_DISC(0.5) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'start')), -1)::FLOAT AS start_50,
+	coalesce((PERCENTILE_DISC(0.5) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'stop')), -1)::FLOAT AS stop_50,
+	coalesce((PERCENTILE_DISC(0.5) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'delete')), -1)::FLOAT AS delete_50,
+	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'start')), -1)::FLOAT AS start_95,
+	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'stop')), -1)::FLOAT AS stop_95,
+	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'delete')), -1)::FLOAT AS delete_95
+FROM build_times
 `
 
-type InsertProvisionerJobLogsParams struct {
-	JobID     uuid.UUID   `db:"job_id" json:"job_id"`
-	CreatedAt []time.Time `db:"created_at" json:"created_at"`
-	Source    []LogSource `db:"source" json:"source"`
-	Level     []LogLevel  `db:"level" json:"level"`
-	Stage     []string    `db:"stage" json:"stage"`
-	Output    []string    `db:"output" json:"output"`
+type GetTemplateAverageBuildTimeParams struct {
+	TemplateID uuid.NullUUID `db:"template_id" json:"template_id"`
+	StartTime  sql.NullTime  `db:"start_time" json:"start_time"`
 }
 
-func (q *sqlQuerier) InsertProvisionerJobLogs(ctx context.Context, arg InsertProvisionerJobLogsParams) ([]ProvisionerJobLog, error) {
-	rows, err := q.db.QueryContext(ctx, insertProvisionerJobLogs,
-		arg.JobID,
-		pq.Array(arg.CreatedAt),
-		pq.Array(arg.Source),
-		pq.Array(arg.Level),
-		pq.Array(arg.Stage),
-		pq.Array(arg.Output),
+type GetTemplateAverageBuildTimeRow struct {
+	Start50  float64 `db:"start_50" json:"start_50"`
+	Stop50   float64 `db:"stop_50" json:"stop_50"`
+	Delete50 float64 `db:"delete_50" json:"delete_50"`
+	Start95  float64 `db:"start_95" json:"start_95"`
+	Stop95   float64 `db:"stop_95" json:"stop_95"`
+	Delete95 float64 `db:"delete_95" json:"delete_95"`
+}
+
+func (q *sqlQuerier) GetTemplateAverageBuildTime(ctx context.Context, arg GetTemplateAverageBuildTimeParams) (GetTemplateAverageBuildTimeRow, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateAverageBuildTime, arg.TemplateID, arg.StartTime)
+	var i GetTemplateAverageBuildTimeRow
+	err := row.Scan(
+		&i.Start50,
+		&i.Stop50,
+		&i.Delete50,
+		&i.Start95,
+		&i.Stop95,
+		&i.Delete95,
 	)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []ProvisionerJobLog
-	for rows.Next() {
-		var i ProvisionerJobLog
-		if err := rows.Scan(
-			&i.JobID,
-			&i.CreatedAt,
-			&i.Source,
-			&i.Level,
-			&i.Stage,
-			&i.Output,
-			&i.ID,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+	return i, err
 }
 
-const acquireProvisionerJob = `-- name: AcquireProvisionerJob :one
-UPDATE
-	provisioner_jobs
-SET
-	started_at = $1,
-	updated_at = $1,
-	worker_id = $2
+const getTemplateByID = `-- name: GetTemplateByID :one
+SELECT
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, deprecated
+FROM
+	templates
 WHERE
-	id = (
-		SELECT
-			id
-		FROM
-			provisioner_jobs AS nested
-		WHERE
-			nested.started_at IS NULL
-			-- Ensure the caller has the correct provisioner.
-			AND nested.provisioner = ANY($3 :: provisioner_type [ ])
-			-- Ensure the caller satisfies all job tags.
-			AND nested.tags <@ $4 :: jsonb 
-		ORDER BY
-			nested.created_at
-		FOR UPDATE
-		SKIP LOCKED
-		LIMIT
-			1
-	) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags
+	id = $1
+LIMIT
+	1
 `
 
-type AcquireProvisionerJobParams struct {
-	StartedAt sql.NullTime      `db:"started_at" json:"started_at"`
-	WorkerID  uuid.NullUUID     `db:"worker_id" json:"worker_id"`
-	Types     []ProvisionerType `db:"types" json:"types"`
-	Tags      json.RawMessage   `db:"tags" json:"tags"`
-}
-
-// Acquires the lock for a single job that isn't started, completed,
-// canceled, and that matches an array of provisioner types.
-//
-// SKIP LOCKED is used to jump over locked rows. This prevents
-// multiple provisioners from acquiring the same jobs. See:
-// https://www.postgresql.org/docs/9.5/sql-select.html#SQL-FOR-UPDATE-SHARE
-func (q *sqlQuerier) AcquireProvisionerJob(ctx context.Context, arg AcquireProvisionerJobParams) (ProvisionerJob, error) {
-	row := q.db.QueryRowContext(ctx, acquireProvisionerJob,
-		arg.StartedAt,
-		arg.WorkerID,
-		pq.Array(arg.Types),
-		arg.Tags,
-	)
-	var i ProvisionerJob
+func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Template, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateByID, id)
+	var i Template
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.StartedAt,
-		&i.CanceledAt,
-		&i.CompletedAt,
-		&i.Error,
 		&i.OrganizationID,
-		&i.InitiatorID,
+		&i.Deleted,
+		&i.Name,
 		&i.Provisioner,
-		&i.StorageMethod,
-		&i.Type,
-		&i.Input,
-		&i.WorkerID,
-		&i.FileID,
-		&i.Tags,
+		&i.ActiveVersionID,
+		&i.Description,
+		&i.DefaultTTL,
+		&i.CreatedBy,
+		&i.Icon,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.DisplayName,
+		&i.AllowUserCancelWorkspaceJobs,
+		&i.Deprecated,
 	)
 	return i, err
 }
 
-const getProvisionerJobByID = `-- name: GetProvisionerJobByID :one
+const getTemplateByOrganizationAndName = `-- name: GetTemplateByOrganizationAndName :one
 SELECT
-	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, deprecated
 FROM
-	provisioner_jobs
+	templates
 WHERE
-	id = $1
+	organization_id = $1
+	AND deleted = $2
+	AND LOWER("name") = LOWER($3)
+LIMIT
+	1
 `
 
-func (q *sqlQuerier) GetProvisionerJobByID(ctx context.Context, id uuid.UUID) (ProvisionerJob, error) {
-	row := q.db.QueryRowContext(ctx, getProvisionerJobByID, id)
-	var i ProvisionerJob
+type GetTemplateByOrganizationAndNameParams struct {
+	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
+	Deleted        bool      `db:"deleted" json:"deleted"`
+	Name           string    `db:"name" json:"name"`
+}
+
+func (q *sqlQuerier) GetTemplateByOrganizationAndName(ctx context.Context, arg GetTemplateByOrganizationAndNameParams) (Template, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateByOrganizationAndName, arg.OrganizationID, arg.Deleted, arg.Name)
+	var i Template
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.StartedAt,
-		&i.CanceledAt,
-		&i.CompletedAt,
-		&i.Error,
 		&i.OrganizationID,
-		&i.InitiatorID,
+		&i.Deleted,
+		&i.Name,
 		&i.Provisioner,
-		&i.StorageMethod,
-		&i.Type,
-		&i.Input,
-		&i.WorkerID,
-		&i.FileID,
-		&i.Tags,
+		&i.ActiveVersionID,
+		&i.Description,
+		&i.DefaultTTL,
+		&i.CreatedBy,
+		&i.Icon,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.DisplayName,
+		&i.AllowUserCancelWorkspaceJobs,
+		&i.Deprecated,
 	)
 	return i, err
 }
 
-const getProvisionerJobsByIDs = `-- name: GetProvisionerJobsByIDs :many
-SELECT
-	id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags
-FROM
-	provisioner_jobs
-WHERE
-	id = ANY($1 :: uuid [ ])
+const getTemplates = `-- name: GetTemplates :many
+SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, deprecated FROM templates
+ORDER BY (name, id) ASC
 `
 
-func (q *sqlQuerier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUID) ([]ProvisionerJob, error) {
-	rows, err := q.db.QueryContext(ctx, getProvisionerJobsByIDs, pq.Array(ids))
+func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplates)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ProvisionerJob
+	var items []Template
 	for rows.Next() {
-		var i ProvisionerJob
+		var i Template
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.StartedAt,
-			&i.CanceledAt,
-			&i.CompletedAt,
-			&i.Error,
 			&i.OrganizationID,
-			&i.InitiatorID,
+			&i.Deleted,
+			&i.Name,
 			&i.Provisioner,
-			&i.StorageMethod,
-			&i.Type,
-			&i.Input,
-			&i.WorkerID,
-			&i.FileID,
-			&i.Tags,
+			&i.ActiveVersionID,
+			&i.Description,
+			&i.DefaultTTL,
+			&i.CreatedBy,
+			&i.Icon,
+			&i.UserACL,
+			&i.GroupACL,
+			&i.DisplayName,
+			&i.AllowUserCancelWorkspaceJobs,
+			&i.Deprecated,
 		); err != nil {
 			return nil, err
 		}
@@ -2474,36 +5312,150 @@ func (q *sqlQuerier) GetProvisionerJobsByIDs(ctx context.Context, ids []uuid.UUI
 	return items, nil
 }
 
-const getProvisionerJobsCreatedAfter = `-- name: GetProvisionerJobsCreatedAfter :many
-SELECT id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags FROM provisioner_jobs WHERE created_at > $1
+const getTemplatesWithFilter = `-- name: GetTemplatesWithFilter :many
+SELECT
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, deprecated
+FROM
+	templates
+WHERE
+	-- Optionally include deleted templates
+	templates.deleted = $1
+	-- Filter by organization_id
+	AND CASE
+		WHEN $2 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			organization_id = $2
+		ELSE true
+	END
+	-- Filter by exact name
+	AND CASE
+		WHEN $3 :: text != '' THEN
+			LOWER("name") = LOWER($3)
+		ELSE true
+	END
+	-- Filter by ids
+	AND CASE
+		WHEN array_length($4 :: uuid[], 1) > 0 THEN
+			id = ANY($4)
+		ELSE true
+	END
+	-- Filter by name, matching on substring
+	AND CASE
+		WHEN $5 :: text != '' THEN
+			"name" ILIKE '%' || $5 || '%'
+		ELSE true
+	END
+	-- Filter by provisioner
+	AND CASE
+		WHEN $6 :: text != '' THEN
+			provisioner :: text = $6
+		ELSE true
+	END
+	-- Filter by created_by
+	AND CASE
+		WHEN $7 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			created_by = $7
+		ELSE true
+	END
+	-- Filter by created_by username
+	AND CASE
+		WHEN $8 :: text != '' THEN
+			created_by = ANY(SELECT id FROM users WHERE lower(username) = lower($8) AND deleted = false)
+		ELSE true
+	END
+	-- Filter by whether the template has workspaces that are not deleted
+	AND CASE
+		WHEN $9 :: boolean IS NOT NULL THEN
+			EXISTS (
+				SELECT
+					1
+				FROM
+					workspaces
+				WHERE
+					workspaces.template_id = templates.id AND
+					workspaces.deleted = false
+			) = $9 :: boolean
+		ELSE true
+	END
+	-- Filter by whether the template is deprecated
+	AND CASE
+		WHEN $10 :: boolean IS NOT NULL THEN
+			(templates.deprecated != '') = $10 :: boolean
+		ELSE true
+	END
+	-- Search the name, display name and a full-text search of the description
+	AND CASE
+		WHEN $11 :: text != '' THEN (
+			"name" ILIKE concat('%', $11, '%')
+			OR display_name ILIKE concat('%', $11, '%')
+			OR to_tsvector('english', description) @@ plainto_tsquery('english', $11)
+		)
+		ELSE true
+	END
+  -- Authorize Filter clause will be injected below in GetAuthorizedTemplates
+  -- @authorize_filter
+ORDER BY (name, id) ASC OFFSET $12
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF($13 :: int, 0)
 `
 
-func (q *sqlQuerier) GetProvisionerJobsCreatedAfter(ctx context.Context, createdAt time.Time) ([]ProvisionerJob, error) {
-	rows, err := q.db.QueryContext(ctx, getProvisionerJobsCreatedAfter, createdAt)
+type GetTemplatesWithFilterParams struct {
+	Deleted             bool         `db:"deleted" json:"deleted"`
+	OrganizationID      uuid.UUID    `db:"organization_id" json:"organization_id"`
+	ExactName           string       `db:"exact_name" json:"exact_name"`
+	IDs                 []uuid.UUID  `db:"ids" json:"ids"`
+	FuzzyName           string       `db:"fuzzy_name" json:"fuzzy_name"`
+	Provisioner         string       `db:"provisioner" json:"provisioner"`
+	CreatedBy           uuid.UUID    `db:"created_by" json:"created_by"`
+	CreatedByUsername   string       `db:"created_by_username" json:"created_by_username"`
+	HasActiveWorkspaces sql.NullBool `db:"has_active_workspaces" json:"has_active_workspaces"`
+	Deprecated          sql.NullBool `db:"deprecated" json:"deprecated"`
+	Search              string       `db:"search" json:"search"`
+	OffsetOpt           int32        `db:"offset_opt" json:"offset_opt"`
+	LimitOpt            int32        `db:"limit_opt" json:"limit_opt"`
+}
+
+func (q *sqlQuerier) GetTemplatesWithFilter(ctx context.Context, arg GetTemplatesWithFilterParams) ([]Template, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplatesWithFilter,
+		arg.Deleted,
+		arg.OrganizationID,
+		arg.ExactName,
+		pq.Array(arg.IDs),
+		arg.FuzzyName,
+		arg.Provisioner,
+		arg.CreatedBy,
+		arg.CreatedByUsername,
+		arg.HasActiveWorkspaces,
+		arg.Deprecated,
+		arg.Search,
+		arg.OffsetOpt,
+		arg.LimitOpt,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ProvisionerJob
+	var items []Template
 	for rows.Next() {
-		var i ProvisionerJob
+		var i Template
 		if err := rows.Scan(
 			&i.ID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.StartedAt,
-			&i.CanceledAt,
-			&i.CompletedAt,
-			&i.Error,
 			&i.OrganizationID,
-			&i.InitiatorID,
+			&i.Deleted,
+			&i.Name,
 			&i.Provisioner,
-			&i.StorageMethod,
-			&i.Type,
-			&i.Input,
-			&i.WorkerID,
-			&i.FileID,
-			&i.Tags,
+			&i.ActiveVersionID,
+			&i.Description,
+			&i.DefaultTTL,
+			&i.CreatedBy,
+			&i.Icon,
+			&i.UserACL,
+			&i.GroupACL,
+			&i.DisplayName,
+			&i.AllowUserCancelWorkspaceJobs,
+			&i.Deprecated,
 		); err != nil {
 			return nil, err
 		}
@@ -2518,622 +5470,769 @@ func (q *sqlQuerier) GetProvisionerJobsCreatedAfter(ctx context.Context, created
 	return items, nil
 }
 
-const insertProvisionerJob = `-- name: InsertProvisionerJob :one
+const insertTemplate = `-- name: InsertTemplate :one
 INSERT INTO
-	provisioner_jobs (
+	templates (
 		id,
 		created_at,
 		updated_at,
 		organization_id,
-		initiator_id,
+		"name",
 		provisioner,
-		storage_method,
-		file_id,
-		"type",
-		"input",
-		tags
+		active_version_id,
+		description,
+		default_ttl,
+		created_by,
+		icon,
+		user_acl,
+		group_acl,
+		display_name,
+		allow_user_cancel_workspace_jobs
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id, created_at, updated_at, started_at, canceled_at, completed_at, error, organization_id, initiator_id, provisioner, storage_method, type, input, worker_id, file_id, tags
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, deprecated
 `
 
-type InsertProvisionerJobParams struct {
-	ID             uuid.UUID                `db:"id" json:"id"`
-	CreatedAt      time.Time                `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time                `db:"updated_at" json:"updated_at"`
-	OrganizationID uuid.UUID                `db:"organization_id" json:"organization_id"`
-	InitiatorID    uuid.UUID                `db:"initiator_id" json:"initiator_id"`
-	Provisioner    ProvisionerType          `db:"provisioner" json:"provisioner"`
-	StorageMethod  ProvisionerStorageMethod `db:"storage_method" json:"storage_method"`
-	FileID         uuid.UUID                `db:"file_id" json:"file_id"`
-	Type           ProvisionerJobType       `db:"type" json:"type"`
-	Input          json.RawMessage          `db:"input" json:"input"`
-	Tags           dbtype.StringMap         `db:"tags" json:"tags"`
+type InsertTemplateParams struct {
+	ID                           uuid.UUID       `db:"id" json:"id"`
+	CreatedAt                    time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt                    time.Time       `db:"updated_at" json:"updated_at"`
+	OrganizationID               uuid.UUID       `db:"organization_id" json:"organization_id"`
+	Name                         string          `db:"name" json:"name"`
+	Provisioner                  ProvisionerType `db:"provisioner" json:"provisioner"`
+	ActiveVersionID              uuid.UUID       `db:"active_version_id" json:"active_version_id"`
+	Description                  string          `db:"description" json:"description"`
+	DefaultTTL                   int64           `db:"default_ttl" json:"default_ttl"`
+	CreatedBy                    uuid.UUID       `db:"created_by" json:"created_by"`
+	Icon                         string          `db:"icon" json:"icon"`
+	UserACL                      TemplateACL     `db:"user_acl" json:"user_acl"`
+	GroupACL                     TemplateACL     `db:"group_acl" json:"group_acl"`
+	DisplayName                  string          `db:"display_name" json:"display_name"`
+	AllowUserCancelWorkspaceJobs bool            `db:"allow_user_cancel_workspace_jobs" json:"allow_user_cancel_workspace_jobs"`
 }
 
-func (q *sqlQuerier) InsertProvisionerJob(ctx context.Context, arg InsertProvisionerJobParams) (ProvisionerJob, error) {
-	row := q.db.QueryRowContext(ctx, insertProvisionerJob,
+func (q *sqlQuerier) InsertTemplate(ctx context.Context, arg InsertTemplateParams) (Template, error) {
+	row := q.db.QueryRowContext(ctx, insertTemplate,
 		arg.ID,
 		arg.CreatedAt,
 		arg.UpdatedAt,
 		arg.OrganizationID,
-		arg.InitiatorID,
+		arg.Name,
 		arg.Provisioner,
-		arg.StorageMethod,
-		arg.FileID,
-		arg.Type,
-		arg.Input,
-		arg.Tags,
+		arg.ActiveVersionID,
+		arg.Description,
+		arg.DefaultTTL,
+		arg.CreatedBy,
+		arg.Icon,
+		arg.UserACL,
+		arg.GroupACL,
+		arg.DisplayName,
+		arg.AllowUserCancelWorkspaceJobs,
 	)
-	var i ProvisionerJob
+	var i Template
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.StartedAt,
-		&i.CanceledAt,
-		&i.CompletedAt,
-		&i.Error,
 		&i.OrganizationID,
-		&i.InitiatorID,
+		&i.Deleted,
+		&i.Name,
 		&i.Provisioner,
-		&i.StorageMethod,
-		&i.Type,
-		&i.Input,
-		&i.WorkerID,
-		&i.FileID,
-		&i.Tags,
+		&i.ActiveVersionID,
+		&i.Description,
+		&i.DefaultTTL,
+		&i.CreatedBy,
+		&i.Icon,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.DisplayName,
+		&i.AllowUserCancelWorkspaceJobs,
+		&i.Deprecated,
 	)
 	return i, err
 }
 
-const updateProvisionerJobByID = `-- name: UpdateProvisionerJobByID :exec
-UPDATE
-	provisioner_jobs
-SET
-	updated_at = $2
-WHERE
-	id = $1
-`
-
-type UpdateProvisionerJobByIDParams struct {
-	ID        uuid.UUID `db:"id" json:"id"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
-}
-
-func (q *sqlQuerier) UpdateProvisionerJobByID(ctx context.Context, arg UpdateProvisionerJobByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateProvisionerJobByID, arg.ID, arg.UpdatedAt)
-	return err
-}
-
-const updateProvisionerJobWithCancelByID = `-- name: UpdateProvisionerJobWithCancelByID :exec
+const updateTemplateACLByID = `-- name: UpdateTemplateACLByID :one
 UPDATE
-	provisioner_jobs
+	templates
 SET
-	canceled_at = $2,
-	completed_at = $3
+	group_acl = $1,
+	user_acl = $2
 WHERE
-	id = $1
+	id = $3
+RETURNING
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, deprecated
 `
 
-type UpdateProvisionerJobWithCancelByIDParams struct {
-	ID          uuid.UUID    `db:"id" json:"id"`
-	CanceledAt  sql.NullTime `db:"canceled_at" json:"canceled_at"`
-	CompletedAt sql.NullTime `db:"completed_at" json:"completed_at"`
+type UpdateTemplateACLByIDParams struct {
+	GroupACL TemplateACL `db:"group_acl" json:"group_acl"`
+	UserACL  TemplateACL `db:"user_acl" json:"user_acl"`
+	ID       uuid.UUID   `db:"id" json:"id"`
 }
 
-func (q *sqlQuerier) UpdateProvisionerJobWithCancelByID(ctx context.Context, arg UpdateProvisionerJobWithCancelByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateProvisionerJobWithCancelByID, arg.ID, arg.CanceledAt, arg.CompletedAt)
-	return err
+func (q *sqlQuerier) UpdateTemplateACLByID(ctx context.Context, arg UpdateTemplateACLByIDParams) (Template, error) {
+	row := q.db.QueryRowContext(ctx, updateTemplateACLByID, arg.GroupACL, arg.UserACL, arg.ID)
+	var i Template
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OrganizationID,
+		&i.Deleted,
+		&i.Name,
+		&i.Provisioner,
+		&i.ActiveVersionID,
+		&i.Description,
+		&i.DefaultTTL,
+		&i.CreatedBy,
+		&i.Icon,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.DisplayName,
+		&i.AllowUserCancelWorkspaceJobs,
+		&i.Deprecated,
+	)
+	return i, err
 }
 
-const updateProvisionerJobWithCompleteByID = `-- name: UpdateProvisionerJobWithCompleteByID :exec
+const updateTemplateActiveVersionByID = `-- name: UpdateTemplateActiveVersionByID :exec
 UPDATE
-	provisioner_jobs
+	templates
 SET
-	updated_at = $2,
-	completed_at = $3,
-	error = $4
+	active_version_id = $2,
+	updated_at = $3
 WHERE
 	id = $1
 `
 
-type UpdateProvisionerJobWithCompleteByIDParams struct {
-	ID          uuid.UUID      `db:"id" json:"id"`
-	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
-	CompletedAt sql.NullTime   `db:"completed_at" json:"completed_at"`
-	Error       sql.NullString `db:"error" json:"error"`
+type UpdateTemplateActiveVersionByIDParams struct {
+	ID              uuid.UUID `db:"id" json:"id"`
+	ActiveVersionID uuid.UUID `db:"active_version_id" json:"active_version_id"`
+	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) UpdateProvisionerJobWithCompleteByID(ctx context.Context, arg UpdateProvisionerJobWithCompleteByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateProvisionerJobWithCompleteByID,
-		arg.ID,
-		arg.UpdatedAt,
-		arg.CompletedAt,
-		arg.Error,
-	)
+func (q *sqlQuerier) UpdateTemplateActiveVersionByID(ctx context.Context, arg UpdateTemplateActiveVersionByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateActiveVersionByID, arg.ID, arg.ActiveVersionID, arg.UpdatedAt)
 	return err
 }
 
-const getQuotaAllowanceForUser = `-- name: GetQuotaAllowanceForUser :one
-SELECT
-	coalesce(SUM(quota_allowance), 0)::BIGINT
-FROM
-	group_members gm
-JOIN groups g ON
-	g.id = gm.group_id
+const updateTemplateDeletedByID = `-- name: UpdateTemplateDeletedByID :exec
+UPDATE
+	templates
+SET
+	deleted = $2,
+	updated_at = $3
 WHERE
-	user_id = $1
-`
-
-func (q *sqlQuerier) GetQuotaAllowanceForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getQuotaAllowanceForUser, userID)
-	var column_1 int64
-	err := row.Scan(&column_1)
-	return column_1, err
-}
-
-const getQuotaConsumedForUser = `-- name: GetQuotaConsumedForUser :one
-WITH latest_builds AS (
-SELECT
-	DISTINCT ON
-	(workspace_id) id,
-	workspace_id,
-	daily_cost
-FROM
-	workspace_builds wb
-ORDER BY
-	workspace_id,
-	created_at DESC
-)
-SELECT
-	coalesce(SUM(daily_cost), 0)::BIGINT
-FROM
-	workspaces
-JOIN latest_builds ON
-	latest_builds.workspace_id = workspaces.id
-WHERE NOT deleted AND workspaces.owner_id = $1
+	id = $1
 `
 
-func (q *sqlQuerier) GetQuotaConsumedForUser(ctx context.Context, ownerID uuid.UUID) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getQuotaConsumedForUser, ownerID)
-	var column_1 int64
-	err := row.Scan(&column_1)
-	return column_1, err
+type UpdateTemplateDeletedByIDParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	Deleted   bool      `db:"deleted" json:"deleted"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
 }
 
-const deleteReplicasUpdatedBefore = `-- name: DeleteReplicasUpdatedBefore :exec
-DELETE FROM replicas WHERE updated_at < $1
-`
-
-func (q *sqlQuerier) DeleteReplicasUpdatedBefore(ctx context.Context, updatedAt time.Time) error {
-	_, err := q.db.ExecContext(ctx, deleteReplicasUpdatedBefore, updatedAt)
+func (q *sqlQuerier) UpdateTemplateDeletedByID(ctx context.Context, arg UpdateTemplateDeletedByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateDeletedByID, arg.ID, arg.Deleted, arg.UpdatedAt)
 	return err
 }
 
-const getReplicasUpdatedAfter = `-- name: GetReplicasUpdatedAfter :many
-SELECT id, created_at, started_at, stopped_at, updated_at, hostname, region_id, relay_address, database_latency, version, error FROM replicas WHERE updated_at > $1 AND stopped_at IS NULL
-`
-
-func (q *sqlQuerier) GetReplicasUpdatedAfter(ctx context.Context, updatedAt time.Time) ([]Replica, error) {
-	rows, err := q.db.QueryContext(ctx, getReplicasUpdatedAfter, updatedAt)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Replica
-	for rows.Next() {
-		var i Replica
-		if err := rows.Scan(
-			&i.ID,
-			&i.CreatedAt,
-			&i.StartedAt,
-			&i.StoppedAt,
-			&i.UpdatedAt,
-			&i.Hostname,
-			&i.RegionID,
-			&i.RelayAddress,
-			&i.DatabaseLatency,
-			&i.Version,
-			&i.Error,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
-const insertReplica = `-- name: InsertReplica :one
-INSERT INTO replicas (
-    id,
-    created_at,
-    started_at,
-    updated_at,
-    hostname,
-    region_id,
-    relay_address,
-    version,
-    database_latency
-) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, started_at, stopped_at, updated_at, hostname, region_id, relay_address, database_latency, version, error
+const updateTemplateDeprecatedByID = `-- name: UpdateTemplateDeprecatedByID :one
+UPDATE
+	templates
+SET
+	updated_at = $2,
+	deprecated = $3
+WHERE
+	id = $1
+RETURNING
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, deprecated
 `
 
-type InsertReplicaParams struct {
-	ID              uuid.UUID `db:"id" json:"id"`
-	CreatedAt       time.Time `db:"created_at" json:"created_at"`
-	StartedAt       time.Time `db:"started_at" json:"started_at"`
-	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
-	Hostname        string    `db:"hostname" json:"hostname"`
-	RegionID        int32     `db:"region_id" json:"region_id"`
-	RelayAddress    string    `db:"relay_address" json:"relay_address"`
-	Version         string    `db:"version" json:"version"`
-	DatabaseLatency int32     `db:"database_latency" json:"database_latency"`
+type UpdateTemplateDeprecatedByIDParams struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+	Deprecated string    `db:"deprecated" json:"deprecated"`
 }
 
-func (q *sqlQuerier) InsertReplica(ctx context.Context, arg InsertReplicaParams) (Replica, error) {
-	row := q.db.QueryRowContext(ctx, insertReplica,
-		arg.ID,
-		arg.CreatedAt,
-		arg.StartedAt,
-		arg.UpdatedAt,
-		arg.Hostname,
-		arg.RegionID,
-		arg.RelayAddress,
-		arg.Version,
-		arg.DatabaseLatency,
-	)
-	var i Replica
+func (q *sqlQuerier) UpdateTemplateDeprecatedByID(ctx context.Context, arg UpdateTemplateDeprecatedByIDParams) (Template, error) {
+	row := q.db.QueryRowContext(ctx, updateTemplateDeprecatedByID, arg.ID, arg.UpdatedAt, arg.Deprecated)
+	var i Template
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
-		&i.StartedAt,
-		&i.StoppedAt,
 		&i.UpdatedAt,
-		&i.Hostname,
-		&i.RegionID,
-		&i.RelayAddress,
-		&i.DatabaseLatency,
-		&i.Version,
-		&i.Error,
+		&i.OrganizationID,
+		&i.Deleted,
+		&i.Name,
+		&i.Provisioner,
+		&i.ActiveVersionID,
+		&i.Description,
+		&i.DefaultTTL,
+		&i.CreatedBy,
+		&i.Icon,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.DisplayName,
+		&i.AllowUserCancelWorkspaceJobs,
+		&i.Deprecated,
 	)
 	return i, err
 }
 
-const updateReplica = `-- name: UpdateReplica :one
-UPDATE replicas SET
-    updated_at = $2,
-    started_at = $3,
-    stopped_at = $4,
-    relay_address = $5,
-    region_id = $6,
-    hostname = $7,
-    version = $8,
-    error = $9,
-    database_latency = $10
-WHERE id = $1 RETURNING id, created_at, started_at, stopped_at, updated_at, hostname, region_id, relay_address, database_latency, version, error
+const updateTemplateMetaByID = `-- name: UpdateTemplateMetaByID :one
+UPDATE
+	templates
+SET
+	updated_at = $2,
+	description = $3,
+	default_ttl = $4,
+	name = $5,
+	icon = $6,
+	display_name = $7,
+	allow_user_cancel_workspace_jobs = $8
+WHERE
+	id = $1
+RETURNING
+	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs, deprecated
 `
 
-type UpdateReplicaParams struct {
-	ID              uuid.UUID    `db:"id" json:"id"`
-	UpdatedAt       time.Time    `db:"updated_at" json:"updated_at"`
-	StartedAt       time.Time    `db:"started_at" json:"started_at"`
-	StoppedAt       sql.NullTime `db:"stopped_at" json:"stopped_at"`
-	RelayAddress    string       `db:"relay_address" json:"relay_address"`
-	RegionID        int32        `db:"region_id" json:"region_id"`
-	Hostname        string       `db:"hostname" json:"hostname"`
-	Version         string       `db:"version" json:"version"`
-	Error           string       `db:"error" json:"error"`
-	DatabaseLatency int32        `db:"database_latency" json:"database_latency"`
+type UpdateTemplateMetaByIDParams struct {
+	ID                           uuid.UUID `db:"id" json:"id"`
+	UpdatedAt                    time.Time `db:"updated_at" json:"updated_at"`
+	Description                  string    `db:"description" json:"description"`
+	DefaultTTL                   int64     `db:"default_ttl" json:"default_ttl"`
+	Name                         string    `db:"name" json:"name"`
+	Icon                         string    `db:"icon" json:"icon"`
+	DisplayName                  string    `db:"display_name" json:"display_name"`
+	AllowUserCancelWorkspaceJobs bool      `db:"allow_user_cancel_workspace_jobs" json:"allow_user_cancel_workspace_jobs"`
 }
 
-func (q *sqlQuerier) UpdateReplica(ctx context.Context, arg UpdateReplicaParams) (Replica, error) {
-	row := q.db.QueryRowContext(ctx, updateReplica,
+func (q *sqlQuerier) UpdateTemplateMetaByID(ctx context.Context, arg UpdateTemplateMetaByIDParams) (Template, error) {
+	row := q.db.QueryRowContext(ctx, updateTemplateMetaByID,
 		arg.ID,
 		arg.UpdatedAt,
-		arg.StartedAt,
-		arg.StoppedAt,
-		arg.RelayAddress,
-		arg.RegionID,
-		arg.Hostname,
-		arg.Version,
-		arg.Error,
-		arg.DatabaseLatency,
+		arg.Description,
+		arg.DefaultTTL,
+		arg.Name,
+		arg.Icon,
+		arg.DisplayName,
+		arg.AllowUserCancelWorkspaceJobs,
 	)
-	var i Replica
+	var i Template
 	err := row.Scan(
 		&i.ID,
 		&i.CreatedAt,
-		&i.StartedAt,
-		&i.StoppedAt,
 		&i.UpdatedAt,
-		&i.Hostname,
-		&i.RegionID,
-		&i.RelayAddress,
-		&i.DatabaseLatency,
-		&i.Version,
-		&i.Error,
+		&i.OrganizationID,
+		&i.Deleted,
+		&i.Name,
+		&i.Provisioner,
+		&i.ActiveVersionID,
+		&i.Description,
+		&i.DefaultTTL,
+		&i.CreatedBy,
+		&i.Icon,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.DisplayName,
+		&i.AllowUserCancelWorkspaceJobs,
+		&i.Deprecated,
 	)
 	return i, err
 }
 
-const getDERPMeshKey = `-- name: GetDERPMeshKey :one
-SELECT value FROM site_configs WHERE key = 'derp_mesh_key'
-`
-
-func (q *sqlQuerier) GetDERPMeshKey(ctx context.Context) (string, error) {
-	row := q.db.QueryRowContext(ctx, getDERPMeshKey)
-	var value string
-	err := row.Scan(&value)
-	return value, err
-}
-
-const getDeploymentID = `-- name: GetDeploymentID :one
-SELECT value FROM site_configs WHERE key = 'deployment_id'
-`
-
-func (q *sqlQuerier) GetDeploymentID(ctx context.Context) (string, error) {
-	row := q.db.QueryRowContext(ctx, getDeploymentID)
-	var value string
-	err := row.Scan(&value)
-	return value, err
-}
-
-const getLastUpdateCheck = `-- name: GetLastUpdateCheck :one
-SELECT value FROM site_configs WHERE key = 'last_update_check'
-`
-
-func (q *sqlQuerier) GetLastUpdateCheck(ctx context.Context) (string, error) {
-	row := q.db.QueryRowContext(ctx, getLastUpdateCheck)
-	var value string
-	err := row.Scan(&value)
-	return value, err
-}
-
-const getLogoURL = `-- name: GetLogoURL :one
-SELECT value FROM site_configs WHERE key = 'logo_url'
-`
-
-func (q *sqlQuerier) GetLogoURL(ctx context.Context) (string, error) {
-	row := q.db.QueryRowContext(ctx, getLogoURL)
-	var value string
-	err := row.Scan(&value)
-	return value, err
-}
-
-const getServiceBanner = `-- name: GetServiceBanner :one
-SELECT value FROM site_configs WHERE key = 'service_banner'
-`
-
-func (q *sqlQuerier) GetServiceBanner(ctx context.Context) (string, error) {
-	row := q.db.QueryRowContext(ctx, getServiceBanner)
-	var value string
-	err := row.Scan(&value)
-	return value, err
-}
-
-const insertDERPMeshKey = `-- name: InsertDERPMeshKey :exec
-INSERT INTO site_configs (key, value) VALUES ('derp_mesh_key', $1)
-`
-
-func (q *sqlQuerier) InsertDERPMeshKey(ctx context.Context, value string) error {
-	_, err := q.db.ExecContext(ctx, insertDERPMeshKey, value)
-	return err
-}
-
-const insertDeploymentID = `-- name: InsertDeploymentID :exec
-INSERT INTO site_configs (key, value) VALUES ('deployment_id', $1)
+const getTemplateVersionParameters = `-- name: GetTemplateVersionParameters :many
+SELECT template_version_id, name, description, type, mutable, default_value, icon, options, validation_regex, validation_min, validation_max, validation_error, validation_monotonic FROM template_version_parameters WHERE template_version_id = $1
 `
 
-func (q *sqlQuerier) InsertDeploymentID(ctx context.Context, value string) error {
-	_, err := q.db.ExecContext(ctx, insertDeploymentID, value)
-	return err
+func (q *sqlQuerier) GetTemplateVersionParameters(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionParameter, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionParameters, templateVersionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateVersionParameter
+	for rows.Next() {
+		var i TemplateVersionParameter
+		if err := rows.Scan(
+			&i.TemplateVersionID,
+			&i.Name,
+			&i.Description,
+			&i.Type,
+			&i.Mutable,
+			&i.DefaultValue,
+			&i.Icon,
+			&i.Options,
+			&i.ValidationRegex,
+			&i.ValidationMin,
+			&i.ValidationMax,
+			&i.ValidationError,
+			&i.ValidationMonotonic,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const insertOrUpdateLastUpdateCheck = `-- name: InsertOrUpdateLastUpdateCheck :exec
-INSERT INTO site_configs (key, value) VALUES ('last_update_check', $1)
-ON CONFLICT (key) DO UPDATE SET value = $1 WHERE site_configs.key = 'last_update_check'
+const insertTemplateVersionParameter = `-- name: InsertTemplateVersionParameter :one
+INSERT INTO
+    template_version_parameters (
+        template_version_id,
+        name,
+        description,
+        type,
+        mutable,
+        default_value,
+        icon,
+        options,
+        validation_regex,
+        validation_min,
+        validation_max,
+        validation_error,
+        validation_monotonic
+    )
+VALUES
+    (
+        $1,
+        $2,
+        $3,
+        $4,
+        $5,
+        $6,
+        $7,
+        $8,
+        $9,
+        $10,
+        $11,
+        $12,
+        $13
+    ) RETURNING template_version_id, name, description, type, mutable, default_value, icon, options, validation_regex, validation_min, validation_max, validation_error, validation_monotonic
 `
 
-func (q *sqlQuerier) InsertOrUpdateLastUpdateCheck(ctx context.Context, value string) error {
-	_, err := q.db.ExecContext(ctx, insertOrUpdateLastUpdateCheck, value)
-	return err
+type InsertTemplateVersionParameterParams struct {
+	TemplateVersionID   uuid.UUID       `db:"template_version_id" json:"template_version_id"`
+	Name                string          `db:"name" json:"name"`
+	Description         string          `db:"description" json:"description"`
+	Type                string          `db:"type" json:"type"`
+	Mutable             bool            `db:"mutable" json:"mutable"`
+	DefaultValue        string          `db:"default_value" json:"default_value"`
+	Icon                string          `db:"icon" json:"icon"`
+	Options             json.RawMessage `db:"options" json:"options"`
+	ValidationRegex     string          `db:"validation_regex" json:"validation_regex"`
+	ValidationMin       int32           `db:"validation_min" json:"validation_min"`
+	ValidationMax       int32           `db:"validation_max" json:"validation_max"`
+	ValidationError     string          `db:"validation_error" json:"validation_error"`
+	ValidationMonotonic string          `db:"validation_monotonic" json:"validation_monotonic"`
 }
 
-const insertOrUpdateLogoURL = `-- name: InsertOrUpdateLogoURL :exec
-INSERT INTO site_configs (key, value) VALUES ('logo_url', $1)
-ON CONFLICT (key) DO UPDATE SET value = $1 WHERE site_configs.key = 'logo_url'
-`
-
-func (q *sqlQuerier) InsertOrUpdateLogoURL(ctx context.Context, value string) error {
-	_, err := q.db.ExecContext(ctx, insertOrUpdateLogoURL, value)
-	return err
+func (q *sqlQuerier) InsertTemplateVersionParameter(ctx context.Context, arg InsertTemplateVersionParameterParams) (TemplateVersionParameter, error) {
+	row := q.db.QueryRowContext(ctx, insertTemplateVersionParameter,
+		arg.TemplateVersionID,
+		arg.Name,
+		arg.Description,
+		arg.Type,
+		arg.Mutable,
+		arg.DefaultValue,
+		arg.Icon,
+		arg.Options,
+		arg.ValidationRegex,
+		arg.ValidationMin,
+		arg.ValidationMax,
+		arg.ValidationError,
+		arg.ValidationMonotonic,
+	)
+	var i TemplateVersionParameter
+	err := row.Scan(
+		&i.TemplateVersionID,
+		&i.Name,
+		&i.Description,
+		&i.Type,
+		&i.Mutable,
+		&i.DefaultValue,
+		&i.Icon,
+		&i.Options,
+		&i.ValidationRegex,
+		&i.ValidationMin,
+		&i.ValidationMax,
+		&i.ValidationError,
+		&i.ValidationMonotonic,
+	)
+	return i, err
 }
 
-const insertOrUpdateServiceBanner = `-- name: InsertOrUpdateServiceBanner :exec
-INSERT INTO site_configs (key, value) VALUES ('service_banner', $1)
-ON CONFLICT (key) DO UPDATE SET value = $1 WHERE site_configs.key = 'service_banner'
+const archiveUnusedTemplateVersions = `-- name: ArchiveUnusedTemplateVersions :many
+UPDATE
+	template_versions
+SET
+	archived = true,
+	updated_at = $1
+WHERE
+	template_versions.template_id = $2 :: uuid
+	AND template_versions.archived = false
+	AND CASE
+		WHEN $3 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			template_versions.id = $3 :: uuid
+		ELSE true
+	END
+	-- Templates can share their active version, so it mustn't be the active
+	-- version of any template.
+	AND NOT EXISTS (
+		SELECT
+			1
+		FROM
+			templates
+		WHERE
+			templates.active_version_id = template_versions.id
+			AND templates.deleted = false
+	)
+	AND NOT EXISTS (
+		SELECT
+			1
+		FROM
+			workspace_builds
+		JOIN workspaces ON workspaces.id = workspace_builds.workspace_id
+		WHERE
+			workspace_builds.template_version_id = template_versions.id
+			AND workspaces.deleted = false
+			AND workspace_builds.build_number = (
+				SELECT
+					MAX(latest.build_number)
+				FROM
+					workspace_builds AS latest
+				WHERE
+					latest.workspace_id = workspace_builds.workspace_id
+			)
+	)
+	AND EXISTS (
+		SELECT
+			1
+		FROM
+			provisioner_jobs
+		WHERE
+			provisioner_jobs.id = template_versions.job_id
+			AND provisioner_jobs.completed_at IS NOT NULL
+	)
+RETURNING template_versions.id
 `
 
-func (q *sqlQuerier) InsertOrUpdateServiceBanner(ctx context.Context, value string) error {
-	_, err := q.db.ExecContext(ctx, insertOrUpdateServiceBanner, value)
-	return err
+type ArchiveUnusedTemplateVersionsParams struct {
+	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+	TemplateID        uuid.UUID `db:"template_id" json:"template_id"`
+	TemplateVersionID uuid.UUID `db:"template_version_id" json:"template_version_id"`
 }
 
-const getTemplateAverageBuildTime = `-- name: GetTemplateAverageBuildTime :one
-WITH build_times AS (
+// Archives the versions of a template that no workspace uses, and returns the
+// IDs of the versions that were archived. A version is in use while it is the
+// active version of a template, or the version of the latest build of a
+// workspace that hasn't been deleted. Versions that are still importing are
+// never archived. Pass a template version ID to archive just that version.
+func (q *sqlQuerier) ArchiveUnusedTemplateVersions(ctx context.Context, arg ArchiveUnusedTemplateVersionsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, archiveUnusedTemplateVersions, arg.UpdatedAt, arg.TemplateID, arg.TemplateVersionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPreviousTemplateVersion = `-- name: GetPreviousTemplateVersion :one
 SELECT
-	EXTRACT(EPOCH FROM (pj.completed_at - pj.started_at))::FLOAT AS exec_time_sec,
-	workspace_builds.transition
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers, archived, git_url, git_ref, git_directory, git_commit_sha
 FROM
-	workspace_builds
-JOIN template_versions ON
-	workspace_builds.template_version_id = template_versions.id
-JOIN provisioner_jobs pj ON
-	workspace_builds.job_id = pj.id
+	template_versions
 WHERE
-	template_versions.template_id = $1 AND
-		(pj.completed_at IS NOT NULL) AND (pj.started_at IS NOT NULL) AND
-		(pj.started_at > $2) AND
-		(pj.canceled_at IS NULL) AND
-		((pj.error IS NULL) OR (pj.error = ''))
-ORDER BY
-	workspace_builds.created_at DESC
-)
-SELECT
-	-- Postgres offers no clear way to DRY this short of a function or other
-	-- complexities.
-	coalesce((PERCENTILE_DISC(0.5) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'start')), -1)::FLOAT AS start_50,
-	coalesce((PERCENTILE_DISC(0.5) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'stop')), -1)::FLOAT AS stop_50,
-	coalesce((PERCENTILE_DISC(0.5) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'delete')), -1)::FLOAT AS delete_50,
-	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'start')), -1)::FLOAT AS start_95,
-	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'stop')), -1)::FLOAT AS stop_95,
-	coalesce((PERCENTILE_DISC(0.95) WITHIN GROUP(ORDER BY exec_time_sec) FILTER (WHERE transition = 'delete')), -1)::FLOAT AS delete_95
-FROM build_times
+	created_at < (
+		SELECT created_at
+		FROM template_versions AS tv
+		WHERE tv.organization_id = $1 AND tv.name = $2 AND tv.template_id = $3
+	)
+	AND organization_id = $1
+	AND template_id = $3
+ORDER BY created_at DESC
+LIMIT 1
 `
 
-type GetTemplateAverageBuildTimeParams struct {
-	TemplateID uuid.NullUUID `db:"template_id" json:"template_id"`
-	StartTime  sql.NullTime  `db:"start_time" json:"start_time"`
-}
-
-type GetTemplateAverageBuildTimeRow struct {
-	Start50  float64 `db:"start_50" json:"start_50"`
-	Stop50   float64 `db:"stop_50" json:"stop_50"`
-	Delete50 float64 `db:"delete_50" json:"delete_50"`
-	Start95  float64 `db:"start_95" json:"start_95"`
-	Stop95   float64 `db:"stop_95" json:"stop_95"`
-	Delete95 float64 `db:"delete_95" json:"delete_95"`
+type GetPreviousTemplateVersionParams struct {
+	OrganizationID uuid.UUID     `db:"organization_id" json:"organization_id"`
+	Name           string        `db:"name" json:"name"`
+	TemplateID     uuid.NullUUID `db:"template_id" json:"template_id"`
 }
 
-func (q *sqlQuerier) GetTemplateAverageBuildTime(ctx context.Context, arg GetTemplateAverageBuildTimeParams) (GetTemplateAverageBuildTimeRow, error) {
-	row := q.db.QueryRowContext(ctx, getTemplateAverageBuildTime, arg.TemplateID, arg.StartTime)
-	var i GetTemplateAverageBuildTimeRow
+func (q *sqlQuerier) GetPreviousTemplateVersion(ctx context.Context, arg GetPreviousTemplateVersionParams) (TemplateVersion, error) {
+	row := q.db.QueryRowContext(ctx, getPreviousTemplateVersion, arg.OrganizationID, arg.Name, arg.TemplateID)
+	var i TemplateVersion
 	err := row.Scan(
-		&i.Start50,
-		&i.Stop50,
-		&i.Delete50,
-		&i.Start95,
-		&i.Stop95,
-		&i.Delete95,
+		&i.ID,
+		&i.TemplateID,
+		&i.OrganizationID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Readme,
+		&i.JobID,
+		&i.CreatedBy,
+		pq.Array(&i.GitAuthProviders),
+		&i.Archived,
+		&i.GitURL,
+		&i.GitRef,
+		&i.GitDirectory,
+		&i.GitCommitSHA,
 	)
 	return i, err
 }
 
-const getTemplateByID = `-- name: GetTemplateByID :one
+const getTemplateVersionByID = `-- name: GetTemplateVersionByID :one
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers, archived, git_url, git_ref, git_directory, git_commit_sha
 FROM
-	templates
+	template_versions
 WHERE
 	id = $1
-LIMIT
-	1
 `
 
-func (q *sqlQuerier) GetTemplateByID(ctx context.Context, id uuid.UUID) (Template, error) {
-	row := q.db.QueryRowContext(ctx, getTemplateByID, id)
-	var i Template
+func (q *sqlQuerier) GetTemplateVersionByID(ctx context.Context, id uuid.UUID) (TemplateVersion, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateVersionByID, id)
+	var i TemplateVersion
 	err := row.Scan(
 		&i.ID,
+		&i.TemplateID,
+		&i.OrganizationID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Name,
+		&i.Readme,
+		&i.JobID,
+		&i.CreatedBy,
+		pq.Array(&i.GitAuthProviders),
+		&i.Archived,
+		&i.GitURL,
+		&i.GitRef,
+		&i.GitDirectory,
+		&i.GitCommitSHA,
+	)
+	return i, err
+}
+
+const getTemplateVersionByJobID = `-- name: GetTemplateVersionByJobID :one
+SELECT
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers, archived, git_url, git_ref, git_directory, git_commit_sha
+FROM
+	template_versions
+WHERE
+	job_id = $1
+`
+
+func (q *sqlQuerier) GetTemplateVersionByJobID(ctx context.Context, jobID uuid.UUID) (TemplateVersion, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateVersionByJobID, jobID)
+	var i TemplateVersion
+	err := row.Scan(
+		&i.ID,
+		&i.TemplateID,
 		&i.OrganizationID,
-		&i.Deleted,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 		&i.Name,
-		&i.Provisioner,
-		&i.ActiveVersionID,
-		&i.Description,
-		&i.DefaultTTL,
+		&i.Readme,
+		&i.JobID,
 		&i.CreatedBy,
-		&i.Icon,
-		&i.UserACL,
-		&i.GroupACL,
-		&i.DisplayName,
-		&i.AllowUserCancelWorkspaceJobs,
+		pq.Array(&i.GitAuthProviders),
+		&i.Archived,
+		&i.GitURL,
+		&i.GitRef,
+		&i.GitDirectory,
+		&i.GitCommitSHA,
 	)
 	return i, err
 }
 
-const getTemplateByOrganizationAndName = `-- name: GetTemplateByOrganizationAndName :one
+const getTemplateVersionByTemplateIDAndName = `-- name: GetTemplateVersionByTemplateIDAndName :one
 SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers, archived, git_url, git_ref, git_directory, git_commit_sha
 FROM
-	templates
+	template_versions
 WHERE
-	organization_id = $1
-	AND deleted = $2
-	AND LOWER("name") = LOWER($3)
-LIMIT
-	1
+	template_id = $1
+	AND "name" = $2
 `
 
-type GetTemplateByOrganizationAndNameParams struct {
-	OrganizationID uuid.UUID `db:"organization_id" json:"organization_id"`
-	Deleted        bool      `db:"deleted" json:"deleted"`
-	Name           string    `db:"name" json:"name"`
+type GetTemplateVersionByTemplateIDAndNameParams struct {
+	TemplateID uuid.NullUUID `db:"template_id" json:"template_id"`
+	Name       string        `db:"name" json:"name"`
 }
 
-func (q *sqlQuerier) GetTemplateByOrganizationAndName(ctx context.Context, arg GetTemplateByOrganizationAndNameParams) (Template, error) {
-	row := q.db.QueryRowContext(ctx, getTemplateByOrganizationAndName, arg.OrganizationID, arg.Deleted, arg.Name)
-	var i Template
+func (q *sqlQuerier) GetTemplateVersionByTemplateIDAndName(ctx context.Context, arg GetTemplateVersionByTemplateIDAndNameParams) (TemplateVersion, error) {
+	row := q.db.QueryRowContext(ctx, getTemplateVersionByTemplateIDAndName, arg.TemplateID, arg.Name)
+	var i TemplateVersion
 	err := row.Scan(
 		&i.ID,
+		&i.TemplateID,
+		&i.OrganizationID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.OrganizationID,
-		&i.Deleted,
 		&i.Name,
-		&i.Provisioner,
-		&i.ActiveVersionID,
-		&i.Description,
-		&i.DefaultTTL,
+		&i.Readme,
+		&i.JobID,
 		&i.CreatedBy,
-		&i.Icon,
-		&i.UserACL,
-		&i.GroupACL,
-		&i.DisplayName,
-		&i.AllowUserCancelWorkspaceJobs,
+		pq.Array(&i.GitAuthProviders),
+		&i.Archived,
+		&i.GitURL,
+		&i.GitRef,
+		&i.GitDirectory,
+		&i.GitCommitSHA,
 	)
 	return i, err
 }
 
-const getTemplates = `-- name: GetTemplates :many
-SELECT id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs FROM templates
-ORDER BY (name, id) ASC
+const getTemplateVersionsByIDs = `-- name: GetTemplateVersionsByIDs :many
+SELECT
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers, archived, git_url, git_ref, git_directory, git_commit_sha
+FROM
+	template_versions
+WHERE
+	id = ANY($1 :: uuid [ ])
+`
+
+func (q *sqlQuerier) GetTemplateVersionsByIDs(ctx context.Context, ids []uuid.UUID) ([]TemplateVersion, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionsByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []TemplateVersion
+	for rows.Next() {
+		var i TemplateVersion
+		if err := rows.Scan(
+			&i.ID,
+			&i.TemplateID,
+			&i.OrganizationID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Readme,
+			&i.JobID,
+			&i.CreatedBy,
+			pq.Array(&i.GitAuthProviders),
+			&i.Archived,
+			&i.GitURL,
+			&i.GitRef,
+			&i.GitDirectory,
+			&i.GitCommitSHA,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTemplateVersionsByTemplateID = `-- name: GetTemplateVersionsByTemplateID :many
+SELECT
+	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers, archived, git_url, git_ref, git_directory, git_commit_sha
+FROM
+	template_versions
+WHERE
+	template_id = $1 :: uuid
+	AND CASE
+		-- This allows using the last element on a page as effectively a cursor.
+		-- This is an important option for scripts that need to paginate without
+		-- duplicating or missing data.
+		WHEN $2 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN (
+			-- The pagination cursor is the last ID of the previous page.
+			-- The query is ordered by the created_at field, so select all
+			-- rows after the cursor.
+			(created_at, id) > (
+				SELECT
+					created_at, id
+				FROM
+					template_versions
+				WHERE
+					id = $2
+			)
+		)
+		ELSE true
+	END
+	AND CASE
+		-- A null filter returns archived and unarchived versions alike.
+		WHEN $3 :: boolean IS NULL THEN true
+		ELSE template_versions.archived = $3 :: boolean
+	END
+ORDER BY
+    -- Deterministic and consistent ordering of all rows, even if they share
+    -- a timestamp. This is to ensure consistent pagination.
+	(created_at, id) ASC OFFSET $4
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF($5 :: int, 0)
 `
 
-func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
-	rows, err := q.db.QueryContext(ctx, getTemplates)
+type GetTemplateVersionsByTemplateIDParams struct {
+	TemplateID uuid.UUID    `db:"template_id" json:"template_id"`
+	AfterID    uuid.UUID    `db:"after_id" json:"after_id"`
+	Archived   sql.NullBool `db:"archived" json:"archived"`
+	OffsetOpt  int32        `db:"offset_opt" json:"offset_opt"`
+	LimitOpt   int32        `db:"limit_opt" json:"limit_opt"`
+}
+
+func (q *sqlQuerier) GetTemplateVersionsByTemplateID(ctx context.Context, arg GetTemplateVersionsByTemplateIDParams) ([]TemplateVersion, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionsByTemplateID,
+		arg.TemplateID,
+		arg.AfterID,
+		arg.Archived,
+		arg.OffsetOpt,
+		arg.LimitOpt,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Template
+	var items []TemplateVersion
 	for rows.Next() {
-		var i Template
+		var i TemplateVersion
 		if err := rows.Scan(
 			&i.ID,
+			&i.TemplateID,
+			&i.OrganizationID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.OrganizationID,
-			&i.Deleted,
 			&i.Name,
-			&i.Provisioner,
-			&i.ActiveVersionID,
-			&i.Description,
-			&i.DefaultTTL,
+			&i.Readme,
+			&i.JobID,
 			&i.CreatedBy,
-			&i.Icon,
-			&i.UserACL,
-			&i.GroupACL,
-			&i.DisplayName,
-			&i.AllowUserCancelWorkspaceJobs,
+			pq.Array(&i.GitAuthProviders),
+			&i.Archived,
+			&i.GitURL,
+			&i.GitRef,
+			&i.GitDirectory,
+			&i.GitCommitSHA,
 		); err != nil {
 			return nil, err
 		}
@@ -3148,75 +6247,35 @@ func (q *sqlQuerier) GetTemplates(ctx context.Context) ([]Template, error) {
 	return items, nil
 }
 
-const getTemplatesWithFilter = `-- name: GetTemplatesWithFilter :many
-SELECT
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs
-FROM
-	templates
-WHERE
-	-- Optionally include deleted templates
-	templates.deleted = $1
-	-- Filter by organization_id
-	AND CASE
-		WHEN $2 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
-			organization_id = $2
-		ELSE true
-	END
-	-- Filter by exact name
-	AND CASE
-		WHEN $3 :: text != '' THEN
-			LOWER("name") = LOWER($3)
-		ELSE true
-	END
-	-- Filter by ids
-	AND CASE
-		WHEN array_length($4 :: uuid[], 1) > 0 THEN
-			id = ANY($4)
-		ELSE true
-	END
-  -- Authorize Filter clause will be injected below in GetAuthorizedTemplates
-  -- @authorize_filter
-ORDER BY (name, id) ASC
+const getTemplateVersionsCreatedAfter = `-- name: GetTemplateVersionsCreatedAfter :many
+SELECT id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers, archived, git_url, git_ref, git_directory, git_commit_sha FROM template_versions WHERE created_at > $1
 `
 
-type GetTemplatesWithFilterParams struct {
-	Deleted        bool        `db:"deleted" json:"deleted"`
-	OrganizationID uuid.UUID   `db:"organization_id" json:"organization_id"`
-	ExactName      string      `db:"exact_name" json:"exact_name"`
-	IDs            []uuid.UUID `db:"ids" json:"ids"`
-}
-
-func (q *sqlQuerier) GetTemplatesWithFilter(ctx context.Context, arg GetTemplatesWithFilterParams) ([]Template, error) {
-	rows, err := q.db.QueryContext(ctx, getTemplatesWithFilter,
-		arg.Deleted,
-		arg.OrganizationID,
-		arg.ExactName,
-		pq.Array(arg.IDs),
-	)
+func (q *sqlQuerier) GetTemplateVersionsCreatedAfter(ctx context.Context, createdAt time.Time) ([]TemplateVersion, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionsCreatedAfter, createdAt)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Template
+	var items []TemplateVersion
 	for rows.Next() {
-		var i Template
+		var i TemplateVersion
 		if err := rows.Scan(
 			&i.ID,
+			&i.TemplateID,
+			&i.OrganizationID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.OrganizationID,
-			&i.Deleted,
 			&i.Name,
-			&i.Provisioner,
-			&i.ActiveVersionID,
-			&i.Description,
-			&i.DefaultTTL,
+			&i.Readme,
+			&i.JobID,
 			&i.CreatedBy,
-			&i.Icon,
-			&i.UserACL,
-			&i.GroupACL,
-			&i.DisplayName,
-			&i.AllowUserCancelWorkspaceJobs,
+			pq.Array(&i.GitAuthProviders),
+			&i.Archived,
+			&i.GitURL,
+			&i.GitRef,
+			&i.GitDirectory,
+			&i.GitCommitSHA,
 		); err != nil {
 			return nil, err
 		}
@@ -3231,259 +6290,185 @@ func (q *sqlQuerier) GetTemplatesWithFilter(ctx context.Context, arg GetTemplate
 	return items, nil
 }
 
-const insertTemplate = `-- name: InsertTemplate :one
+const insertTemplateVersion = `-- name: InsertTemplateVersion :one
 INSERT INTO
-	templates (
+	template_versions (
 		id,
+		template_id,
+		organization_id,
 		created_at,
 		updated_at,
-		organization_id,
 		"name",
-		provisioner,
-		active_version_id,
-		description,
-		default_ttl,
+		readme,
+		job_id,
 		created_by,
-		icon,
-		user_acl,
-		group_acl,
-		display_name,
-		allow_user_cancel_workspace_jobs
+		git_url,
+		git_ref,
+		git_directory,
+		git_commit_sha
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers, archived, git_url, git_ref, git_directory, git_commit_sha
 `
 
-type InsertTemplateParams struct {
-	ID                           uuid.UUID       `db:"id" json:"id"`
-	CreatedAt                    time.Time       `db:"created_at" json:"created_at"`
-	UpdatedAt                    time.Time       `db:"updated_at" json:"updated_at"`
-	OrganizationID               uuid.UUID       `db:"organization_id" json:"organization_id"`
-	Name                         string          `db:"name" json:"name"`
-	Provisioner                  ProvisionerType `db:"provisioner" json:"provisioner"`
-	ActiveVersionID              uuid.UUID       `db:"active_version_id" json:"active_version_id"`
-	Description                  string          `db:"description" json:"description"`
-	DefaultTTL                   int64           `db:"default_ttl" json:"default_ttl"`
-	CreatedBy                    uuid.UUID       `db:"created_by" json:"created_by"`
-	Icon                         string          `db:"icon" json:"icon"`
-	UserACL                      TemplateACL     `db:"user_acl" json:"user_acl"`
-	GroupACL                     TemplateACL     `db:"group_acl" json:"group_acl"`
-	DisplayName                  string          `db:"display_name" json:"display_name"`
-	AllowUserCancelWorkspaceJobs bool            `db:"allow_user_cancel_workspace_jobs" json:"allow_user_cancel_workspace_jobs"`
+type InsertTemplateVersionParams struct {
+	ID             uuid.UUID     `db:"id" json:"id"`
+	TemplateID     uuid.NullUUID `db:"template_id" json:"template_id"`
+	OrganizationID uuid.UUID     `db:"organization_id" json:"organization_id"`
+	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time     `db:"updated_at" json:"updated_at"`
+	Name           string        `db:"name" json:"name"`
+	Readme         string        `db:"readme" json:"readme"`
+	JobID          uuid.UUID     `db:"job_id" json:"job_id"`
+	CreatedBy      uuid.UUID     `db:"created_by" json:"created_by"`
+	GitURL         string        `db:"git_url" json:"git_url"`
+	GitRef         string        `db:"git_ref" json:"git_ref"`
+	GitDirectory   string        `db:"git_directory" json:"git_directory"`
+	GitCommitSHA   string        `db:"git_commit_sha" json:"git_commit_sha"`
 }
 
-func (q *sqlQuerier) InsertTemplate(ctx context.Context, arg InsertTemplateParams) (Template, error) {
-	row := q.db.QueryRowContext(ctx, insertTemplate,
+func (q *sqlQuerier) InsertTemplateVersion(ctx context.Context, arg InsertTemplateVersionParams) (TemplateVersion, error) {
+	row := q.db.QueryRowContext(ctx, insertTemplateVersion,
 		arg.ID,
+		arg.TemplateID,
+		arg.OrganizationID,
 		arg.CreatedAt,
 		arg.UpdatedAt,
-		arg.OrganizationID,
 		arg.Name,
-		arg.Provisioner,
-		arg.ActiveVersionID,
-		arg.Description,
-		arg.DefaultTTL,
+		arg.Readme,
+		arg.JobID,
 		arg.CreatedBy,
-		arg.Icon,
-		arg.UserACL,
-		arg.GroupACL,
-		arg.DisplayName,
-		arg.AllowUserCancelWorkspaceJobs,
+		arg.GitURL,
+		arg.GitRef,
+		arg.GitDirectory,
+		arg.GitCommitSHA,
 	)
-	var i Template
+	var i TemplateVersion
 	err := row.Scan(
 		&i.ID,
+		&i.TemplateID,
+		&i.OrganizationID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.OrganizationID,
-		&i.Deleted,
 		&i.Name,
-		&i.Provisioner,
-		&i.ActiveVersionID,
-		&i.Description,
-		&i.DefaultTTL,
+		&i.Readme,
+		&i.JobID,
 		&i.CreatedBy,
-		&i.Icon,
-		&i.UserACL,
-		&i.GroupACL,
-		&i.DisplayName,
-		&i.AllowUserCancelWorkspaceJobs,
+		pq.Array(&i.GitAuthProviders),
+		&i.Archived,
+		&i.GitURL,
+		&i.GitRef,
+		&i.GitDirectory,
+		&i.GitCommitSHA,
 	)
 	return i, err
 }
 
-const updateTemplateACLByID = `-- name: UpdateTemplateACLByID :one
+const unarchiveTemplateVersion = `-- name: UnarchiveTemplateVersion :exec
 UPDATE
-	templates
+	template_versions
 SET
-	group_acl = $1,
-	user_acl = $2
+	archived = false,
+	updated_at = $1
 WHERE
-	id = $3
-RETURNING
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs
+	id = $2
 `
 
-type UpdateTemplateACLByIDParams struct {
-	GroupACL TemplateACL `db:"group_acl" json:"group_acl"`
-	UserACL  TemplateACL `db:"user_acl" json:"user_acl"`
-	ID       uuid.UUID   `db:"id" json:"id"`
+type UnarchiveTemplateVersionParams struct {
+	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+	TemplateVersionID uuid.UUID `db:"template_version_id" json:"template_version_id"`
 }
 
-func (q *sqlQuerier) UpdateTemplateACLByID(ctx context.Context, arg UpdateTemplateACLByIDParams) (Template, error) {
-	row := q.db.QueryRowContext(ctx, updateTemplateACLByID, arg.GroupACL, arg.UserACL, arg.ID)
-	var i Template
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.OrganizationID,
-		&i.Deleted,
-		&i.Name,
-		&i.Provisioner,
-		&i.ActiveVersionID,
-		&i.Description,
-		&i.DefaultTTL,
-		&i.CreatedBy,
-		&i.Icon,
-		&i.UserACL,
-		&i.GroupACL,
-		&i.DisplayName,
-		&i.AllowUserCancelWorkspaceJobs,
-	)
-	return i, err
+func (q *sqlQuerier) UnarchiveTemplateVersion(ctx context.Context, arg UnarchiveTemplateVersionParams) error {
+	_, err := q.db.ExecContext(ctx, unarchiveTemplateVersion, arg.UpdatedAt, arg.TemplateVersionID)
+	return err
 }
 
-const updateTemplateActiveVersionByID = `-- name: UpdateTemplateActiveVersionByID :exec
+const updateTemplateVersionByID = `-- name: UpdateTemplateVersionByID :exec
 UPDATE
-	templates
+	template_versions
 SET
-	active_version_id = $2,
+	template_id = $2,
 	updated_at = $3
 WHERE
 	id = $1
 `
 
-type UpdateTemplateActiveVersionByIDParams struct {
-	ID              uuid.UUID `db:"id" json:"id"`
-	ActiveVersionID uuid.UUID `db:"active_version_id" json:"active_version_id"`
-	UpdatedAt       time.Time `db:"updated_at" json:"updated_at"`
+type UpdateTemplateVersionByIDParams struct {
+	ID         uuid.UUID     `db:"id" json:"id"`
+	TemplateID uuid.NullUUID `db:"template_id" json:"template_id"`
+	UpdatedAt  time.Time     `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) UpdateTemplateActiveVersionByID(ctx context.Context, arg UpdateTemplateActiveVersionByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateTemplateActiveVersionByID, arg.ID, arg.ActiveVersionID, arg.UpdatedAt)
+func (q *sqlQuerier) UpdateTemplateVersionByID(ctx context.Context, arg UpdateTemplateVersionByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateVersionByID, arg.ID, arg.TemplateID, arg.UpdatedAt)
 	return err
 }
 
-const updateTemplateDeletedByID = `-- name: UpdateTemplateDeletedByID :exec
+const updateTemplateVersionDescriptionByJobID = `-- name: UpdateTemplateVersionDescriptionByJobID :exec
 UPDATE
-	templates
+	template_versions
 SET
-	deleted = $2,
+	readme = $2,
 	updated_at = $3
 WHERE
-	id = $1
+	job_id = $1
 `
 
-type UpdateTemplateDeletedByIDParams struct {
-	ID        uuid.UUID `db:"id" json:"id"`
-	Deleted   bool      `db:"deleted" json:"deleted"`
+type UpdateTemplateVersionDescriptionByJobIDParams struct {
+	JobID     uuid.UUID `db:"job_id" json:"job_id"`
+	Readme    string    `db:"readme" json:"readme"`
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) UpdateTemplateDeletedByID(ctx context.Context, arg UpdateTemplateDeletedByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateTemplateDeletedByID, arg.ID, arg.Deleted, arg.UpdatedAt)
+func (q *sqlQuerier) UpdateTemplateVersionDescriptionByJobID(ctx context.Context, arg UpdateTemplateVersionDescriptionByJobIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateVersionDescriptionByJobID, arg.JobID, arg.Readme, arg.UpdatedAt)
 	return err
 }
 
-const updateTemplateMetaByID = `-- name: UpdateTemplateMetaByID :one
+const updateTemplateVersionGitAuthProvidersByJobID = `-- name: UpdateTemplateVersionGitAuthProvidersByJobID :exec
 UPDATE
-	templates
+	template_versions
 SET
-	updated_at = $2,
-	description = $3,
-	default_ttl = $4,
-	name = $5,
-	icon = $6,
-	display_name = $7,
-	allow_user_cancel_workspace_jobs = $8
+	git_auth_providers = $2,
+	updated_at = $3
 WHERE
-	id = $1
-RETURNING
-	id, created_at, updated_at, organization_id, deleted, name, provisioner, active_version_id, description, default_ttl, created_by, icon, user_acl, group_acl, display_name, allow_user_cancel_workspace_jobs
+	job_id = $1
 `
 
-type UpdateTemplateMetaByIDParams struct {
-	ID                           uuid.UUID `db:"id" json:"id"`
-	UpdatedAt                    time.Time `db:"updated_at" json:"updated_at"`
-	Description                  string    `db:"description" json:"description"`
-	DefaultTTL                   int64     `db:"default_ttl" json:"default_ttl"`
-	Name                         string    `db:"name" json:"name"`
-	Icon                         string    `db:"icon" json:"icon"`
-	DisplayName                  string    `db:"display_name" json:"display_name"`
-	AllowUserCancelWorkspaceJobs bool      `db:"allow_user_cancel_workspace_jobs" json:"allow_user_cancel_workspace_jobs"`
+type UpdateTemplateVersionGitAuthProvidersByJobIDParams struct {
+	JobID            uuid.UUID `db:"job_id" json:"job_id"`
+	GitAuthProviders []string  `db:"git_auth_providers" json:"git_auth_providers"`
+	UpdatedAt        time.Time `db:"updated_at" json:"updated_at"`
 }
 
-func (q *sqlQuerier) UpdateTemplateMetaByID(ctx context.Context, arg UpdateTemplateMetaByIDParams) (Template, error) {
-	row := q.db.QueryRowContext(ctx, updateTemplateMetaByID,
-		arg.ID,
-		arg.UpdatedAt,
-		arg.Description,
-		arg.DefaultTTL,
-		arg.Name,
-		arg.Icon,
-		arg.DisplayName,
-		arg.AllowUserCancelWorkspaceJobs,
-	)
-	var i Template
-	err := row.Scan(
-		&i.ID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.OrganizationID,
-		&i.Deleted,
-		&i.Name,
-		&i.Provisioner,
-		&i.ActiveVersionID,
-		&i.Description,
-		&i.DefaultTTL,
-		&i.CreatedBy,
-		&i.Icon,
-		&i.UserACL,
-		&i.GroupACL,
-		&i.DisplayName,
-		&i.AllowUserCancelWorkspaceJobs,
-	)
-	return i, err
+func (q *sqlQuerier) UpdateTemplateVersionGitAuthProvidersByJobID(ctx context.Context, arg UpdateTemplateVersionGitAuthProvidersByJobIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateTemplateVersionGitAuthProvidersByJobID, arg.JobID, pq.Array(arg.GitAuthProviders), arg.UpdatedAt)
+	return err
 }
 
-const getTemplateVersionParameters = `-- name: GetTemplateVersionParameters :many
-SELECT template_version_id, name, description, type, mutable, default_value, icon, options, validation_regex, validation_min, validation_max, validation_error, validation_monotonic FROM template_version_parameters WHERE template_version_id = $1
+const getTemplateVersionVariables = `-- name: GetTemplateVersionVariables :many
+SELECT template_version_id, name, description, type, value, default_value, required, sensitive FROM template_version_variables WHERE template_version_id = $1
 `
 
-func (q *sqlQuerier) GetTemplateVersionParameters(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionParameter, error) {
-	rows, err := q.db.QueryContext(ctx, getTemplateVersionParameters, templateVersionID)
+func (q *sqlQuerier) GetTemplateVersionVariables(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionVariable, error) {
+	rows, err := q.db.QueryContext(ctx, getTemplateVersionVariables, templateVersionID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []TemplateVersionParameter
+	var items []TemplateVersionVariable
 	for rows.Next() {
-		var i TemplateVersionParameter
+		var i TemplateVersionVariable
 		if err := rows.Scan(
 			&i.TemplateVersionID,
 			&i.Name,
 			&i.Description,
 			&i.Type,
-			&i.Mutable,
+			&i.Value,
 			&i.DefaultValue,
-			&i.Icon,
-			&i.Options,
-			&i.ValidationRegex,
-			&i.ValidationMin,
-			&i.ValidationMax,
-			&i.ValidationError,
-			&i.ValidationMonotonic,
+			&i.Required,
+			&i.Sensitive,
 		); err != nil {
 			return nil, err
 		}
@@ -3498,22 +6483,17 @@ func (q *sqlQuerier) GetTemplateVersionParameters(ctx context.Context, templateV
 	return items, nil
 }
 
-const insertTemplateVersionParameter = `-- name: InsertTemplateVersionParameter :one
+const insertTemplateVersionVariable = `-- name: InsertTemplateVersionVariable :one
 INSERT INTO
-    template_version_parameters (
+    template_version_variables (
         template_version_id,
         name,
         description,
         type,
-        mutable,
+        value,
         default_value,
-        icon,
-        options,
-        validation_regex,
-        validation_min,
-        validation_max,
-        validation_error,
-        validation_monotonic
+        required,
+        sensitive
     )
 VALUES
     (
@@ -3524,498 +6504,610 @@ VALUES
         $5,
         $6,
         $7,
-        $8,
-        $9,
-        $10,
-        $11,
-        $12,
-        $13
-    ) RETURNING template_version_id, name, description, type, mutable, default_value, icon, options, validation_regex, validation_min, validation_max, validation_error, validation_monotonic
+        $8
+    ) RETURNING template_version_id, name, description, type, value, default_value, required, sensitive
 `
 
-type InsertTemplateVersionParameterParams struct {
-	TemplateVersionID   uuid.UUID       `db:"template_version_id" json:"template_version_id"`
-	Name                string          `db:"name" json:"name"`
-	Description         string          `db:"description" json:"description"`
-	Type                string          `db:"type" json:"type"`
-	Mutable             bool            `db:"mutable" json:"mutable"`
-	DefaultValue        string          `db:"default_value" json:"default_value"`
-	Icon                string          `db:"icon" json:"icon"`
-	Options             json.RawMessage `db:"options" json:"options"`
-	ValidationRegex     string          `db:"validation_regex" json:"validation_regex"`
-	ValidationMin       int32           `db:"validation_min" json:"validation_min"`
-	ValidationMax       int32           `db:"validation_max" json:"validation_max"`
-	ValidationError     string          `db:"validation_error" json:"validation_error"`
-	ValidationMonotonic string          `db:"validation_monotonic" json:"validation_monotonic"`
+type InsertTemplateVersionVariableParams struct {
+	TemplateVersionID uuid.UUID `db:"template_version_id" json:"template_version_id"`
+	Name              string    `db:"name" json:"name"`
+	Description       string    `db:"description" json:"description"`
+	Type              string    `db:"type" json:"type"`
+	Value             string    `db:"value" json:"value"`
+	DefaultValue      string    `db:"default_value" json:"default_value"`
+	Required          bool      `db:"required" json:"required"`
+	Sensitive         bool      `db:"sensitive" json:"sensitive"`
 }
 
-func (q *sqlQuerier) InsertTemplateVersionParameter(ctx context.Context, arg InsertTemplateVersionParameterParams) (TemplateVersionParameter, error) {
-	row := q.db.QueryRowContext(ctx, insertTemplateVersionParameter,
+func (q *sqlQuerier) InsertTemplateVersionVariable(ctx context.Context, arg InsertTemplateVersionVariableParams) (TemplateVersionVariable, error) {
+	row := q.db.QueryRowContext(ctx, insertTemplateVersionVariable,
 		arg.TemplateVersionID,
 		arg.Name,
 		arg.Description,
 		arg.Type,
-		arg.Mutable,
+		arg.Value,
 		arg.DefaultValue,
-		arg.Icon,
-		arg.Options,
-		arg.ValidationRegex,
-		arg.ValidationMin,
-		arg.ValidationMax,
-		arg.ValidationError,
-		arg.ValidationMonotonic,
+		arg.Required,
+		arg.Sensitive,
 	)
-	var i TemplateVersionParameter
+	var i TemplateVersionVariable
 	err := row.Scan(
 		&i.TemplateVersionID,
 		&i.Name,
 		&i.Description,
 		&i.Type,
-		&i.Mutable,
+		&i.Value,
 		&i.DefaultValue,
-		&i.Icon,
-		&i.Options,
-		&i.ValidationRegex,
-		&i.ValidationMin,
-		&i.ValidationMax,
-		&i.ValidationError,
-		&i.ValidationMonotonic,
+		&i.Required,
+		&i.Sensitive,
 	)
 	return i, err
 }
 
-const getPreviousTemplateVersion = `-- name: GetPreviousTemplateVersion :one
+const getUserLinkByLinkedID = `-- name: GetUserLinkByLinkedID :one
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers
+	user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
 FROM
-	template_versions
+	user_links
 WHERE
-	created_at < (
-		SELECT created_at
-		FROM template_versions AS tv
-		WHERE tv.organization_id = $1 AND tv.name = $2 AND tv.template_id = $3
+	linked_id = $1
+`
+
+func (q *sqlQuerier) GetUserLinkByLinkedID(ctx context.Context, linkedID string) (UserLink, error) {
+	row := q.db.QueryRowContext(ctx, getUserLinkByLinkedID, linkedID)
+	var i UserLink
+	err := row.Scan(
+		&i.UserID,
+		&i.LoginType,
+		&i.LinkedID,
+		&i.OAuthAccessToken,
+		&i.OAuthRefreshToken,
+		&i.OAuthExpiry,
+	)
+	return i, err
+}
+
+const getUserLinkByUserIDLoginType = `-- name: GetUserLinkByUserIDLoginType :one
+SELECT
+	user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+FROM
+	user_links
+WHERE
+	user_id = $1 AND login_type = $2
+`
+
+type GetUserLinkByUserIDLoginTypeParams struct {
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	LoginType LoginType `db:"login_type" json:"login_type"`
+}
+
+func (q *sqlQuerier) GetUserLinkByUserIDLoginType(ctx context.Context, arg GetUserLinkByUserIDLoginTypeParams) (UserLink, error) {
+	row := q.db.QueryRowContext(ctx, getUserLinkByUserIDLoginType, arg.UserID, arg.LoginType)
+	var i UserLink
+	err := row.Scan(
+		&i.UserID,
+		&i.LoginType,
+		&i.LinkedID,
+		&i.OAuthAccessToken,
+		&i.OAuthRefreshToken,
+		&i.OAuthExpiry,
+	)
+	return i, err
+}
+
+const insertUserLink = `-- name: InsertUserLink :one
+INSERT INTO
+	user_links (
+		user_id,
+		login_type,
+		linked_id,
+		oauth_access_token,
+		oauth_refresh_token,
+		oauth_expiry
+	)
+VALUES
+	( $1, $2, $3, $4, $5, $6 ) RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+`
+
+type InsertUserLinkParams struct {
+	UserID            uuid.UUID `db:"user_id" json:"user_id"`
+	LoginType         LoginType `db:"login_type" json:"login_type"`
+	LinkedID          string    `db:"linked_id" json:"linked_id"`
+	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
+	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
+	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
+}
+
+func (q *sqlQuerier) InsertUserLink(ctx context.Context, arg InsertUserLinkParams) (UserLink, error) {
+	row := q.db.QueryRowContext(ctx, insertUserLink,
+		arg.UserID,
+		arg.LoginType,
+		arg.LinkedID,
+		arg.OAuthAccessToken,
+		arg.OAuthRefreshToken,
+		arg.OAuthExpiry,
+	)
+	var i UserLink
+	err := row.Scan(
+		&i.UserID,
+		&i.LoginType,
+		&i.LinkedID,
+		&i.OAuthAccessToken,
+		&i.OAuthRefreshToken,
+		&i.OAuthExpiry,
 	)
-	AND organization_id = $1
-	AND template_id = $3
-ORDER BY created_at DESC
-LIMIT 1
+	return i, err
+}
+
+const updateUserLink = `-- name: UpdateUserLink :one
+UPDATE
+	user_links
+SET
+	oauth_access_token = $1,
+	oauth_refresh_token = $2,
+	oauth_expiry = $3
+WHERE
+	user_id = $4 AND login_type = $5 RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
 `
 
-type GetPreviousTemplateVersionParams struct {
-	OrganizationID uuid.UUID     `db:"organization_id" json:"organization_id"`
-	Name           string        `db:"name" json:"name"`
-	TemplateID     uuid.NullUUID `db:"template_id" json:"template_id"`
+type UpdateUserLinkParams struct {
+	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
+	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
+	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
+	UserID            uuid.UUID `db:"user_id" json:"user_id"`
+	LoginType         LoginType `db:"login_type" json:"login_type"`
 }
 
-func (q *sqlQuerier) GetPreviousTemplateVersion(ctx context.Context, arg GetPreviousTemplateVersionParams) (TemplateVersion, error) {
-	row := q.db.QueryRowContext(ctx, getPreviousTemplateVersion, arg.OrganizationID, arg.Name, arg.TemplateID)
-	var i TemplateVersion
+func (q *sqlQuerier) UpdateUserLink(ctx context.Context, arg UpdateUserLinkParams) (UserLink, error) {
+	row := q.db.QueryRowContext(ctx, updateUserLink,
+		arg.OAuthAccessToken,
+		arg.OAuthRefreshToken,
+		arg.OAuthExpiry,
+		arg.UserID,
+		arg.LoginType,
+	)
+	var i UserLink
 	err := row.Scan(
-		&i.ID,
-		&i.TemplateID,
-		&i.OrganizationID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Name,
-		&i.Readme,
-		&i.JobID,
-		&i.CreatedBy,
-		pq.Array(&i.GitAuthProviders),
+		&i.UserID,
+		&i.LoginType,
+		&i.LinkedID,
+		&i.OAuthAccessToken,
+		&i.OAuthRefreshToken,
+		&i.OAuthExpiry,
 	)
 	return i, err
 }
 
-const getTemplateVersionByID = `-- name: GetTemplateVersionByID :one
-SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers
-FROM
-	template_versions
+const updateUserLinkedID = `-- name: UpdateUserLinkedID :one
+UPDATE
+	user_links
+SET
+	linked_id = $1
 WHERE
-	id = $1
+	user_id = $2 AND login_type = $3 RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
 `
 
-func (q *sqlQuerier) GetTemplateVersionByID(ctx context.Context, id uuid.UUID) (TemplateVersion, error) {
-	row := q.db.QueryRowContext(ctx, getTemplateVersionByID, id)
-	var i TemplateVersion
+type UpdateUserLinkedIDParams struct {
+	LinkedID  string    `db:"linked_id" json:"linked_id"`
+	UserID    uuid.UUID `db:"user_id" json:"user_id"`
+	LoginType LoginType `db:"login_type" json:"login_type"`
+}
+
+func (q *sqlQuerier) UpdateUserLinkedID(ctx context.Context, arg UpdateUserLinkedIDParams) (UserLink, error) {
+	row := q.db.QueryRowContext(ctx, updateUserLinkedID, arg.LinkedID, arg.UserID, arg.LoginType)
+	var i UserLink
 	err := row.Scan(
-		&i.ID,
-		&i.TemplateID,
-		&i.OrganizationID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Name,
-		&i.Readme,
-		&i.JobID,
-		&i.CreatedBy,
-		pq.Array(&i.GitAuthProviders),
+		&i.UserID,
+		&i.LoginType,
+		&i.LinkedID,
+		&i.OAuthAccessToken,
+		&i.OAuthRefreshToken,
+		&i.OAuthExpiry,
 	)
 	return i, err
 }
 
-const getTemplateVersionByJobID = `-- name: GetTemplateVersionByJobID :one
+const getActiveUserCount = `-- name: GetActiveUserCount :one
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers
+	COUNT(*)
 FROM
-	template_versions
+	users
 WHERE
-	job_id = $1
+    status = 'active'::user_status AND deleted = false
 `
 
-func (q *sqlQuerier) GetTemplateVersionByJobID(ctx context.Context, jobID uuid.UUID) (TemplateVersion, error) {
-	row := q.db.QueryRowContext(ctx, getTemplateVersionByJobID, jobID)
-	var i TemplateVersion
-	err := row.Scan(
-		&i.ID,
-		&i.TemplateID,
-		&i.OrganizationID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Name,
-		&i.Readme,
-		&i.JobID,
-		&i.CreatedBy,
-		pq.Array(&i.GitAuthProviders),
-	)
-	return i, err
+func (q *sqlQuerier) GetActiveUserCount(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getActiveUserCount)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
 }
 
-const getTemplateVersionByTemplateIDAndName = `-- name: GetTemplateVersionByTemplateIDAndName :one
+const getAuthorizationUserRoles = `-- name: GetAuthorizationUserRoles :one
 SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers
+	-- username is returned just to help for logging purposes
+	-- status is used to enforce 'suspended' users, as all roles are ignored
+	--	when suspended.
+	id, username, status,
+	-- All user roles, including their org roles.
+	array_cat(
+		-- All users are members
+		array_append(users.rbac_roles, 'member'),
+		(
+			SELECT
+				array_agg(org_roles)
+			FROM
+				organization_members,
+				-- All org_members get the org-member role for their orgs
+				unnest(
+					array_append(roles, 'organization-member:' || organization_members.organization_id::text)
+				) AS org_roles
+			WHERE
+				user_id = users.id
+		)
+	) :: text[] AS roles,
+	-- All groups the user is in.
+	(
+		SELECT
+			array_agg(
+				group_members.group_id :: text
+			)
+		FROM
+			group_members
+		WHERE
+			user_id = users.id
+	) :: text[] AS groups
 FROM
-	template_versions
+	users
 WHERE
-	template_id = $1
-	AND "name" = $2
+	id = $1
 `
 
-type GetTemplateVersionByTemplateIDAndNameParams struct {
-	TemplateID uuid.NullUUID `db:"template_id" json:"template_id"`
-	Name       string        `db:"name" json:"name"`
+type GetAuthorizationUserRolesRow struct {
+	ID       uuid.UUID  `db:"id" json:"id"`
+	Username string     `db:"username" json:"username"`
+	Status   UserStatus `db:"status" json:"status"`
+	Roles    []string   `db:"roles" json:"roles"`
+	Groups   []string   `db:"groups" json:"groups"`
 }
 
-func (q *sqlQuerier) GetTemplateVersionByTemplateIDAndName(ctx context.Context, arg GetTemplateVersionByTemplateIDAndNameParams) (TemplateVersion, error) {
-	row := q.db.QueryRowContext(ctx, getTemplateVersionByTemplateIDAndName, arg.TemplateID, arg.Name)
-	var i TemplateVersion
+// This function returns roles for authorization purposes. Implied member roles
+// are included.
+func (q *sqlQuerier) GetAuthorizationUserRoles(ctx context.Context, userID uuid.UUID) (GetAuthorizationUserRolesRow, error) {
+	row := q.db.QueryRowContext(ctx, getAuthorizationUserRoles, userID)
+	var i GetAuthorizationUserRolesRow
 	err := row.Scan(
 		&i.ID,
-		&i.TemplateID,
-		&i.OrganizationID,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Name,
-		&i.Readme,
-		&i.JobID,
-		&i.CreatedBy,
-		pq.Array(&i.GitAuthProviders),
+		&i.Username,
+		&i.Status,
+		pq.Array(&i.Roles),
+		pq.Array(&i.Groups),
 	)
 	return i, err
 }
-
-const getTemplateVersionsByIDs = `-- name: GetTemplateVersionsByIDs :many
-SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers
-FROM
-	template_versions
-WHERE
-	id = ANY($1 :: uuid [ ])
-`
-
-func (q *sqlQuerier) GetTemplateVersionsByIDs(ctx context.Context, ids []uuid.UUID) ([]TemplateVersion, error) {
-	rows, err := q.db.QueryContext(ctx, getTemplateVersionsByIDs, pq.Array(ids))
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []TemplateVersion
-	for rows.Next() {
-		var i TemplateVersion
-		if err := rows.Scan(
-			&i.ID,
-			&i.TemplateID,
-			&i.OrganizationID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.Name,
-			&i.Readme,
-			&i.JobID,
-			&i.CreatedBy,
-			pq.Array(&i.GitAuthProviders),
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
-const getTemplateVersionsByTemplateID = `-- name: GetTemplateVersionsByTemplateID :many
-SELECT
-	id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers
-FROM
-	template_versions
-WHERE
-	template_id = $1 :: uuid
-	AND CASE
-		-- This allows using the last element on a page as effectively a cursor.
-		-- This is an important option for scripts that need to paginate without
-		-- duplicating or missing data.
-		WHEN $2 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN (
-			-- The pagination cursor is the last ID of the previous page.
-			-- The query is ordered by the created_at field, so select all
-			-- rows after the cursor.
-			(created_at, id) > (
-				SELECT
-					created_at, id
-				FROM
-					template_versions
-				WHERE
-					id = $2
-			)
+
+const getFilteredUserCount = `-- name: GetFilteredUserCount :one
+SELECT
+	COUNT(*)
+FROM
+	users
+WHERE
+	users.deleted = false
+	-- Start filters
+	-- Filter by name, email or username
+	AND CASE
+		WHEN $1 :: text != '' THEN (
+			email ILIKE concat('%', $1, '%')
+			OR username ILIKE concat('%', $1, '%')
 		)
 		ELSE true
 	END
-ORDER BY
-    -- Deterministic and consistent ordering of all rows, even if they share
-    -- a timestamp. This is to ensure consistent pagination.
-	(created_at, id) ASC OFFSET $3
-LIMIT
-	-- A null limit means "no limit", so 0 means return all
-	NULLIF($4 :: int, 0)
+	-- Filter by status
+	AND CASE
+		-- @status needs to be a text because it can be empty, If it was
+		-- user_status enum, it would not.
+		WHEN cardinality($2 :: user_status[]) > 0 THEN
+			status = ANY($2 :: user_status[])
+		ELSE true
+	END
+	-- Filter by rbac_roles
+	AND CASE
+		-- @rbac_role allows filtering by rbac roles. If 'member' is included, show everyone, as everyone is a member.
+		WHEN cardinality($3 :: text[]) > 0 AND 'member' != ANY($3 :: text[])
+		THEN rbac_roles && $3 :: text[]
+		ELSE true
+	END
+	-- Authorize Filter clause will be injected below in GetAuthorizedUserCount
+	-- @authorize_filter
 `
 
-type GetTemplateVersionsByTemplateIDParams struct {
-	TemplateID uuid.UUID `db:"template_id" json:"template_id"`
-	AfterID    uuid.UUID `db:"after_id" json:"after_id"`
-	OffsetOpt  int32     `db:"offset_opt" json:"offset_opt"`
-	LimitOpt   int32     `db:"limit_opt" json:"limit_opt"`
-}
-
-func (q *sqlQuerier) GetTemplateVersionsByTemplateID(ctx context.Context, arg GetTemplateVersionsByTemplateIDParams) ([]TemplateVersion, error) {
-	rows, err := q.db.QueryContext(ctx, getTemplateVersionsByTemplateID,
-		arg.TemplateID,
-		arg.AfterID,
-		arg.OffsetOpt,
-		arg.LimitOpt,
-	)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []TemplateVersion
-	for rows.Next() {
-		var i TemplateVersion
-		if err := rows.Scan(
-			&i.ID,
-			&i.TemplateID,
-			&i.OrganizationID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.Name,
-			&i.Readme,
-			&i.JobID,
-			&i.CreatedBy,
-			pq.Array(&i.GitAuthProviders),
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+type GetFilteredUserCountParams struct {
+	Search   string       `db:"search" json:"search"`
+	Status   []UserStatus `db:"status" json:"status"`
+	RbacRole []string     `db:"rbac_role" json:"rbac_role"`
 }
 
-const getTemplateVersionsCreatedAfter = `-- name: GetTemplateVersionsCreatedAfter :many
-SELECT id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers FROM template_versions WHERE created_at > $1
-`
-
-func (q *sqlQuerier) GetTemplateVersionsCreatedAfter(ctx context.Context, createdAt time.Time) ([]TemplateVersion, error) {
-	rows, err := q.db.QueryContext(ctx, getTemplateVersionsCreatedAfter, createdAt)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []TemplateVersion
-	for rows.Next() {
-		var i TemplateVersion
-		if err := rows.Scan(
-			&i.ID,
-			&i.TemplateID,
-			&i.OrganizationID,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-			&i.Name,
-			&i.Readme,
-			&i.JobID,
-			&i.CreatedBy,
-			pq.Array(&i.GitAuthProviders),
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
+// This will never count deleted users.
+func (q *sqlQuerier) GetFilteredUserCount(ctx context.Context, arg GetFilteredUserCountParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getFilteredUserCount, arg.Search, pq.Array(arg.Status), pq.Array(arg.RbacRole))
+	var count int64
+	err := row.Scan(&count)
+	return count, err
 }
 
-const insertTemplateVersion = `-- name: InsertTemplateVersion :one
-INSERT INTO
-	template_versions (
-		id,
-		template_id,
-		organization_id,
-		created_at,
-		updated_at,
-		"name",
-		readme,
-		job_id,
-		created_by
-	)
-VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, template_id, organization_id, created_at, updated_at, name, readme, job_id, created_by, git_auth_providers
+const getUserByEmailOrUsername = `-- name: GetUserByEmailOrUsername :one
+SELECT
+	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
+FROM
+	users
+WHERE
+	(LOWER(username) = LOWER($1) OR LOWER(email) = LOWER($2)) AND
+	deleted = false
+LIMIT
+	1
 `
 
-type InsertTemplateVersionParams struct {
-	ID             uuid.UUID     `db:"id" json:"id"`
-	TemplateID     uuid.NullUUID `db:"template_id" json:"template_id"`
-	OrganizationID uuid.UUID     `db:"organization_id" json:"organization_id"`
-	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time     `db:"updated_at" json:"updated_at"`
-	Name           string        `db:"name" json:"name"`
-	Readme         string        `db:"readme" json:"readme"`
-	JobID          uuid.UUID     `db:"job_id" json:"job_id"`
-	CreatedBy      uuid.UUID     `db:"created_by" json:"created_by"`
+type GetUserByEmailOrUsernameParams struct {
+	Username string `db:"username" json:"username"`
+	Email    string `db:"email" json:"email"`
 }
 
-func (q *sqlQuerier) InsertTemplateVersion(ctx context.Context, arg InsertTemplateVersionParams) (TemplateVersion, error) {
-	row := q.db.QueryRowContext(ctx, insertTemplateVersion,
-		arg.ID,
-		arg.TemplateID,
-		arg.OrganizationID,
-		arg.CreatedAt,
-		arg.UpdatedAt,
-		arg.Name,
-		arg.Readme,
-		arg.JobID,
-		arg.CreatedBy,
-	)
-	var i TemplateVersion
+func (q *sqlQuerier) GetUserByEmailOrUsername(ctx context.Context, arg GetUserByEmailOrUsernameParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmailOrUsername, arg.Username, arg.Email)
+	var i User
 	err := row.Scan(
 		&i.ID,
-		&i.TemplateID,
-		&i.OrganizationID,
+		&i.Email,
+		&i.Username,
+		&i.HashedPassword,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Name,
-		&i.Readme,
-		&i.JobID,
-		&i.CreatedBy,
-		pq.Array(&i.GitAuthProviders),
+		&i.Status,
+		&i.RBACRoles,
+		&i.LoginType,
+		&i.AvatarURL,
+		&i.Deleted,
+		&i.LastSeenAt,
 	)
 	return i, err
 }
 
-const updateTemplateVersionByID = `-- name: UpdateTemplateVersionByID :exec
-UPDATE
-	template_versions
-SET
-	template_id = $2,
-	updated_at = $3
+const getUserByID = `-- name: GetUserByID :one
+SELECT
+	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
+FROM
+	users
 WHERE
 	id = $1
+LIMIT
+	1
 `
 
-type UpdateTemplateVersionByIDParams struct {
-	ID         uuid.UUID     `db:"id" json:"id"`
-	TemplateID uuid.NullUUID `db:"template_id" json:"template_id"`
-	UpdatedAt  time.Time     `db:"updated_at" json:"updated_at"`
-}
-
-func (q *sqlQuerier) UpdateTemplateVersionByID(ctx context.Context, arg UpdateTemplateVersionByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateTemplateVersionByID, arg.ID, arg.TemplateID, arg.UpdatedAt)
-	return err
+func (q *sqlQuerier) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.RBACRoles,
+		&i.LoginType,
+		&i.AvatarURL,
+		&i.Deleted,
+		&i.LastSeenAt,
+	)
+	return i, err
 }
 
-const updateTemplateVersionDescriptionByJobID = `-- name: UpdateTemplateVersionDescriptionByJobID :exec
-UPDATE
-	template_versions
-SET
-	readme = $2,
-	updated_at = $3
+const getUserCount = `-- name: GetUserCount :one
+SELECT
+	COUNT(*)
+FROM
+	users
 WHERE
-	job_id = $1
+	deleted = false
 `
 
-type UpdateTemplateVersionDescriptionByJobIDParams struct {
-	JobID     uuid.UUID `db:"job_id" json:"job_id"`
-	Readme    string    `db:"readme" json:"readme"`
-	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
-}
-
-func (q *sqlQuerier) UpdateTemplateVersionDescriptionByJobID(ctx context.Context, arg UpdateTemplateVersionDescriptionByJobIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateTemplateVersionDescriptionByJobID, arg.JobID, arg.Readme, arg.UpdatedAt)
-	return err
+func (q *sqlQuerier) GetUserCount(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getUserCount)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
 }
 
-const updateTemplateVersionGitAuthProvidersByJobID = `-- name: UpdateTemplateVersionGitAuthProvidersByJobID :exec
-UPDATE
-	template_versions
-SET
-	git_auth_providers = $2,
-	updated_at = $3
+const getUsers = `-- name: GetUsers :many
+SELECT
+	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, COUNT(*) OVER() AS count
+FROM
+	users
 WHERE
-	job_id = $1
+	users.deleted = false
+	AND CASE
+		-- This allows using the last element on a page as effectively a cursor.
+		-- This is an important option for scripts that need to paginate without
+		-- duplicating or missing data.
+		WHEN $1 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN (
+			-- The pagination cursor is the last ID of the previous page.
+			-- The query is ordered by the created_at field, so select all
+			-- rows after the cursor.
+			(created_at, id) > (
+				SELECT
+					created_at, id
+				FROM
+					users
+				WHERE
+					id = $1
+			)
+		)
+		ELSE true
+	END
+	-- Start filters
+	-- Filter by name, email or username
+	AND CASE
+		WHEN $2 :: text != '' THEN (
+			email ILIKE concat('%', $2, '%')
+			OR username ILIKE concat('%', $2, '%')
+		)
+		ELSE true
+	END
+	-- Filter by status
+	AND CASE
+		-- @status needs to be a text because it can be empty, If it was
+		-- user_status enum, it would not.
+		WHEN cardinality($3 :: user_status[]) > 0 THEN
+			status = ANY($3 :: user_status[])
+		ELSE true
+	END
+	-- Filter by rbac_roles
+	AND CASE
+		-- @rbac_role allows filtering by rbac roles. If 'member' is included, show everyone, as
+	    -- everyone is a member.
+		WHEN cardinality($4 :: text[]) > 0 AND 'member' != ANY($4 :: text[]) THEN
+		    rbac_roles && $4 :: text[]
+		ELSE true
+	END
+	-- Filter by login_type
+	AND CASE
+		WHEN cardinality($5 :: login_type[]) > 0 THEN
+			login_type = ANY($5 :: login_type[])
+		ELSE true
+	END
+	-- Filter by last_seen_at
+	AND CASE
+		WHEN $6 :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			last_seen_at < $6
+		ELSE true
+	END
+	-- Filter by created_at
+	AND CASE
+		WHEN $7 :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			created_at >= $7
+		ELSE true
+	END
+	-- Filter by group name. Group names are only unique within an
+	-- organization, so the group is looked up in the organization filtered
+	-- by. The members of the "Everyone" group, which shares the ID of its
+	-- organization, are the members of the organization.
+	AND CASE
+		WHEN $8 :: text != '' THEN (
+			id = ANY(
+				SELECT
+					group_members.user_id
+				FROM
+					group_members
+				JOIN
+					groups ON groups.id = group_members.group_id
+				WHERE
+					lower(groups.name) = lower($8)
+					AND groups.organization_id = $9 :: uuid
+			)
+			OR id = ANY(
+				SELECT
+					organization_members.user_id
+				FROM
+					organization_members
+				JOIN
+					groups ON groups.id = organization_members.organization_id
+				WHERE
+					lower(groups.name) = lower($8)
+					AND groups.organization_id = $9 :: uuid
+			)
+		)
+		ELSE true
+	END
+	-- Filter by organization membership
+	AND CASE
+		WHEN $9 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			id = ANY(
+				SELECT
+					organization_members.user_id
+				FROM
+					organization_members
+				WHERE
+					organization_members.organization_id = $9
+			)
+		ELSE true
+	END
+	-- End of filters
+ORDER BY
+	-- Deterministic and consistent ordering of all users, even if they share
+	-- a timestamp. This is to ensure consistent pagination.
+	(created_at, id) ASC OFFSET $10
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF($11 :: int, 0)
 `
 
-type UpdateTemplateVersionGitAuthProvidersByJobIDParams struct {
-	JobID            uuid.UUID `db:"job_id" json:"job_id"`
-	GitAuthProviders []string  `db:"git_auth_providers" json:"git_auth_providers"`
-	UpdatedAt        time.Time `db:"updated_at" json:"updated_at"`
+type GetUsersParams struct {
+	AfterID        uuid.UUID    `db:"after_id" json:"after_id"`
+	Search         string       `db:"search" json:"search"`
+	Status         []UserStatus `db:"status" json:"status"`
+	RbacRole       []string     `db:"rbac_role" json:"rbac_role"`
+	LoginType      []LoginType  `db:"login_type" json:"login_type"`
+	LastSeenBefore time.Time    `db:"last_seen_before" json:"last_seen_before"`
+	CreatedAfter   time.Time    `db:"created_after" json:"created_after"`
+	GroupName      string       `db:"group_name" json:"group_name"`
+	OrganizationID uuid.UUID    `db:"organization_id" json:"organization_id"`
+	OffsetOpt      int32        `db:"offset_opt" json:"offset_opt"`
+	LimitOpt       int32        `db:"limit_opt" json:"limit_opt"`
 }
 
-func (q *sqlQuerier) UpdateTemplateVersionGitAuthProvidersByJobID(ctx context.Context, arg UpdateTemplateVersionGitAuthProvidersByJobIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateTemplateVersionGitAuthProvidersByJobID, arg.JobID, pq.Array(arg.GitAuthProviders), arg.UpdatedAt)
-	return err
+type GetUsersRow struct {
+	ID             uuid.UUID      `db:"id" json:"id"`
+	Email          string         `db:"email" json:"email"`
+	Username       string         `db:"username" json:"username"`
+	HashedPassword []byte         `db:"hashed_password" json:"hashed_password"`
+	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time      `db:"updated_at" json:"updated_at"`
+	Status         UserStatus     `db:"status" json:"status"`
+	RBACRoles      pq.StringArray `db:"rbac_roles" json:"rbac_roles"`
+	LoginType      LoginType      `db:"login_type" json:"login_type"`
+	AvatarURL      sql.NullString `db:"avatar_url" json:"avatar_url"`
+	Deleted        bool           `db:"deleted" json:"deleted"`
+	LastSeenAt     time.Time      `db:"last_seen_at" json:"last_seen_at"`
+	Count          int64          `db:"count" json:"count"`
 }
 
-const getTemplateVersionVariables = `-- name: GetTemplateVersionVariables :many
-SELECT template_version_id, name, description, type, value, default_value, required, sensitive FROM template_version_variables WHERE template_version_id = $1
-`
-
-func (q *sqlQuerier) GetTemplateVersionVariables(ctx context.Context, templateVersionID uuid.UUID) ([]TemplateVersionVariable, error) {
-	rows, err := q.db.QueryContext(ctx, getTemplateVersionVariables, templateVersionID)
+// This will never return deleted users.
+func (q *sqlQuerier) GetUsers(ctx context.Context, arg GetUsersParams) ([]GetUsersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUsers,
+		arg.AfterID,
+		arg.Search,
+		pq.Array(arg.Status),
+		pq.Array(arg.RbacRole),
+		pq.Array(arg.LoginType),
+		arg.LastSeenBefore,
+		arg.CreatedAfter,
+		arg.GroupName,
+		arg.OrganizationID,
+		arg.OffsetOpt,
+		arg.LimitOpt,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []TemplateVersionVariable
+	var items []GetUsersRow
 	for rows.Next() {
-		var i TemplateVersionVariable
+		var i GetUsersRow
 		if err := rows.Scan(
-			&i.TemplateVersionID,
-			&i.Name,
-			&i.Description,
-			&i.Type,
-			&i.Value,
-			&i.DefaultValue,
-			&i.Required,
-			&i.Sensitive,
+			&i.ID,
+			&i.Email,
+			&i.Username,
+			&i.HashedPassword,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.RBACRoles,
+			&i.LoginType,
+			&i.AvatarURL,
+			&i.Deleted,
+			&i.LastSeenAt,
+			&i.Count,
 		); err != nil {
 			return nil, err
 		}
@@ -4030,377 +7122,243 @@ func (q *sqlQuerier) GetTemplateVersionVariables(ctx context.Context, templateVe
 	return items, nil
 }
 
-const insertTemplateVersionVariable = `-- name: InsertTemplateVersionVariable :one
-INSERT INTO
-    template_version_variables (
-        template_version_id,
-        name,
-        description,
-        type,
-        value,
-        default_value,
-        required,
-        sensitive
-    )
-VALUES
-    (
-        $1,
-        $2,
-        $3,
-        $4,
-        $5,
-        $6,
-        $7,
-        $8
-    ) RETURNING template_version_id, name, description, type, value, default_value, required, sensitive
-`
-
-type InsertTemplateVersionVariableParams struct {
-	TemplateVersionID uuid.UUID `db:"template_version_id" json:"template_version_id"`
-	Name              string    `db:"name" json:"name"`
-	Description       string    `db:"description" json:"description"`
-	Type              string    `db:"type" json:"type"`
-	Value             string    `db:"value" json:"value"`
-	DefaultValue      string    `db:"default_value" json:"default_value"`
-	Required          bool      `db:"required" json:"required"`
-	Sensitive         bool      `db:"sensitive" json:"sensitive"`
-}
-
-func (q *sqlQuerier) InsertTemplateVersionVariable(ctx context.Context, arg InsertTemplateVersionVariableParams) (TemplateVersionVariable, error) {
-	row := q.db.QueryRowContext(ctx, insertTemplateVersionVariable,
-		arg.TemplateVersionID,
-		arg.Name,
-		arg.Description,
-		arg.Type,
-		arg.Value,
-		arg.DefaultValue,
-		arg.Required,
-		arg.Sensitive,
-	)
-	var i TemplateVersionVariable
-	err := row.Scan(
-		&i.TemplateVersionID,
-		&i.Name,
-		&i.Description,
-		&i.Type,
-		&i.Value,
-		&i.DefaultValue,
-		&i.Required,
-		&i.Sensitive,
-	)
-	return i, err
-}
-
-const getUserLinkByLinkedID = `-- name: GetUserLinkByLinkedID :one
-SELECT
-	user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
-FROM
-	user_links
-WHERE
-	linked_id = $1
-`
-
-func (q *sqlQuerier) GetUserLinkByLinkedID(ctx context.Context, linkedID string) (UserLink, error) {
-	row := q.db.QueryRowContext(ctx, getUserLinkByLinkedID, linkedID)
-	var i UserLink
-	err := row.Scan(
-		&i.UserID,
-		&i.LoginType,
-		&i.LinkedID,
-		&i.OAuthAccessToken,
-		&i.OAuthRefreshToken,
-		&i.OAuthExpiry,
-	)
-	return i, err
-}
-
-const getUserLinkByUserIDLoginType = `-- name: GetUserLinkByUserIDLoginType :one
-SELECT
-	user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
-FROM
-	user_links
-WHERE
-	user_id = $1 AND login_type = $2
-`
-
-type GetUserLinkByUserIDLoginTypeParams struct {
-	UserID    uuid.UUID `db:"user_id" json:"user_id"`
-	LoginType LoginType `db:"login_type" json:"login_type"`
-}
+const getUsersByIDs = `-- name: GetUsersByIDs :many
+SELECT id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at FROM users WHERE id = ANY($1 :: uuid [ ])
+`
 
-func (q *sqlQuerier) GetUserLinkByUserIDLoginType(ctx context.Context, arg GetUserLinkByUserIDLoginTypeParams) (UserLink, error) {
-	row := q.db.QueryRowContext(ctx, getUserLinkByUserIDLoginType, arg.UserID, arg.LoginType)
-	var i UserLink
-	err := row.Scan(
-		&i.UserID,
-		&i.LoginType,
-		&i.LinkedID,
-		&i.OAuthAccessToken,
-		&i.OAuthRefreshToken,
-		&i.OAuthExpiry,
-	)
-	return i, err
+// This shouldn't check for deleted, because it's frequently used
+// to look up references to actions. eg. a user could build a workspace
+// for another user, then be deleted... we still want them to appear!
+func (q *sqlQuerier) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]User, error) {
+	rows, err := q.db.QueryContext(ctx, getUsersByIDs, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Username,
+			&i.HashedPassword,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Status,
+			&i.RBACRoles,
+			&i.LoginType,
+			&i.AvatarURL,
+			&i.Deleted,
+			&i.LastSeenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const insertUserLink = `-- name: InsertUserLink :one
+const insertUser = `-- name: InsertUser :one
 INSERT INTO
-	user_links (
-		user_id,
-		login_type,
-		linked_id,
-		oauth_access_token,
-		oauth_refresh_token,
-		oauth_expiry
+	users (
+		id,
+		email,
+		username,
+		hashed_password,
+		created_at,
+		updated_at,
+		rbac_roles,
+		login_type
 	)
 VALUES
-	( $1, $2, $3, $4, $5, $6 ) RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+	($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
 `
 
-type InsertUserLinkParams struct {
-	UserID            uuid.UUID `db:"user_id" json:"user_id"`
-	LoginType         LoginType `db:"login_type" json:"login_type"`
-	LinkedID          string    `db:"linked_id" json:"linked_id"`
-	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
-	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
-	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
+type InsertUserParams struct {
+	ID             uuid.UUID      `db:"id" json:"id"`
+	Email          string         `db:"email" json:"email"`
+	Username       string         `db:"username" json:"username"`
+	HashedPassword []byte         `db:"hashed_password" json:"hashed_password"`
+	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time      `db:"updated_at" json:"updated_at"`
+	RBACRoles      pq.StringArray `db:"rbac_roles" json:"rbac_roles"`
+	LoginType      LoginType      `db:"login_type" json:"login_type"`
 }
 
-func (q *sqlQuerier) InsertUserLink(ctx context.Context, arg InsertUserLinkParams) (UserLink, error) {
-	row := q.db.QueryRowContext(ctx, insertUserLink,
-		arg.UserID,
+func (q *sqlQuerier) InsertUser(ctx context.Context, arg InsertUserParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, insertUser,
+		arg.ID,
+		arg.Email,
+		arg.Username,
+		arg.HashedPassword,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.RBACRoles,
 		arg.LoginType,
-		arg.LinkedID,
-		arg.OAuthAccessToken,
-		arg.OAuthRefreshToken,
-		arg.OAuthExpiry,
 	)
-	var i UserLink
+	var i User
 	err := row.Scan(
-		&i.UserID,
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.RBACRoles,
 		&i.LoginType,
-		&i.LinkedID,
-		&i.OAuthAccessToken,
-		&i.OAuthRefreshToken,
-		&i.OAuthExpiry,
+		&i.AvatarURL,
+		&i.Deleted,
+		&i.LastSeenAt,
 	)
 	return i, err
 }
 
-const updateUserLink = `-- name: UpdateUserLink :one
+const updateUserDeletedByID = `-- name: UpdateUserDeletedByID :exec
 UPDATE
-	user_links
+	users
 SET
-	oauth_access_token = $1,
-	oauth_refresh_token = $2,
-	oauth_expiry = $3
+	deleted = $2
 WHERE
-	user_id = $4 AND login_type = $5 RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+	id = $1
 `
 
-type UpdateUserLinkParams struct {
-	OAuthAccessToken  string    `db:"oauth_access_token" json:"oauth_access_token"`
-	OAuthRefreshToken string    `db:"oauth_refresh_token" json:"oauth_refresh_token"`
-	OAuthExpiry       time.Time `db:"oauth_expiry" json:"oauth_expiry"`
-	UserID            uuid.UUID `db:"user_id" json:"user_id"`
-	LoginType         LoginType `db:"login_type" json:"login_type"`
+type UpdateUserDeletedByIDParams struct {
+	ID      uuid.UUID `db:"id" json:"id"`
+	Deleted bool      `db:"deleted" json:"deleted"`
 }
 
-func (q *sqlQuerier) UpdateUserLink(ctx context.Context, arg UpdateUserLinkParams) (UserLink, error) {
-	row := q.db.QueryRowContext(ctx, updateUserLink,
-		arg.OAuthAccessToken,
-		arg.OAuthRefreshToken,
-		arg.OAuthExpiry,
-		arg.UserID,
-		arg.LoginType,
-	)
-	var i UserLink
-	err := row.Scan(
-		&i.UserID,
-		&i.LoginType,
-		&i.LinkedID,
-		&i.OAuthAccessToken,
-		&i.OAuthRefreshToken,
-		&i.OAuthExpiry,
-	)
-	return i, err
+func (q *sqlQuerier) UpdateUserDeletedByID(ctx context.Context, arg UpdateUserDeletedByIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserDeletedByID, arg.ID, arg.Deleted)
+	return err
 }
 
-const updateUserLinkedID = `-- name: UpdateUserLinkedID :one
+const updateUserHashedPassword = `-- name: UpdateUserHashedPassword :exec
 UPDATE
-	user_links
+	users
 SET
-	linked_id = $1
+	hashed_password = $2
 WHERE
-	user_id = $2 AND login_type = $3 RETURNING user_id, login_type, linked_id, oauth_access_token, oauth_refresh_token, oauth_expiry
+	id = $1
 `
 
-type UpdateUserLinkedIDParams struct {
-	LinkedID  string    `db:"linked_id" json:"linked_id"`
-	UserID    uuid.UUID `db:"user_id" json:"user_id"`
-	LoginType LoginType `db:"login_type" json:"login_type"`
-}
-
-func (q *sqlQuerier) UpdateUserLinkedID(ctx context.Context, arg UpdateUserLinkedIDParams) (UserLink, error) {
-	row := q.db.QueryRowContext(ctx, updateUserLinkedID, arg.LinkedID, arg.UserID, arg.LoginType)
-	var i UserLink
-	err := row.Scan(
-		&i.UserID,
-		&i.LoginType,
-		&i.LinkedID,
-		&i.OAuthAccessToken,
-		&i.OAuthRefreshToken,
-		&i.OAuthExpiry,
-	)
-	return i, err
+type UpdateUserHashedPasswordParams struct {
+	ID             uuid.UUID `db:"id" json:"id"`
+	HashedPassword []byte    `db:"hashed_password" json:"hashed_password"`
 }
 
-const getActiveUserCount = `-- name: GetActiveUserCount :one
-SELECT
-	COUNT(*)
-FROM
-	users
-WHERE
-    status = 'active'::user_status AND deleted = false
-`
-
-func (q *sqlQuerier) GetActiveUserCount(ctx context.Context) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getActiveUserCount)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+func (q *sqlQuerier) UpdateUserHashedPassword(ctx context.Context, arg UpdateUserHashedPasswordParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserHashedPassword, arg.ID, arg.HashedPassword)
+	return err
 }
 
-const getAuthorizationUserRoles = `-- name: GetAuthorizationUserRoles :one
-SELECT
-	-- username is returned just to help for logging purposes
-	-- status is used to enforce 'suspended' users, as all roles are ignored
-	--	when suspended.
-	id, username, status,
-	-- All user roles, including their org roles.
-	array_cat(
-		-- All users are members
-		array_append(users.rbac_roles, 'member'),
-		(
-			SELECT
-				array_agg(org_roles)
-			FROM
-				organization_members,
-				-- All org_members get the org-member role for their orgs
-				unnest(
-					array_append(roles, 'organization-member:' || organization_members.organization_id::text)
-				) AS org_roles
-			WHERE
-				user_id = users.id
-		)
-	) :: text[] AS roles,
-	-- All groups the user is in.
-	(
-		SELECT
-			array_agg(
-				group_members.group_id :: text
-			)
-		FROM
-			group_members
-		WHERE
-			user_id = users.id
-	) :: text[] AS groups
-FROM
+const updateUserLastSeenAt = `-- name: UpdateUserLastSeenAt :one
+UPDATE
 	users
+SET
+	last_seen_at = $2,
+	updated_at = $3
 WHERE
-	id = $1
-`
-
-type GetAuthorizationUserRolesRow struct {
-	ID       uuid.UUID  `db:"id" json:"id"`
-	Username string     `db:"username" json:"username"`
-	Status   UserStatus `db:"status" json:"status"`
-	Roles    []string   `db:"roles" json:"roles"`
-	Groups   []string   `db:"groups" json:"groups"`
+	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
+`
+
+type UpdateUserLastSeenAtParams struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	LastSeenAt time.Time `db:"last_seen_at" json:"last_seen_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
 }
 
-// This function returns roles for authorization purposes. Implied member roles
-// are included.
-func (q *sqlQuerier) GetAuthorizationUserRoles(ctx context.Context, userID uuid.UUID) (GetAuthorizationUserRolesRow, error) {
-	row := q.db.QueryRowContext(ctx, getAuthorizationUserRoles, userID)
-	var i GetAuthorizationUserRolesRow
+func (q *sqlQuerier) UpdateUserLastSeenAt(ctx context.Context, arg UpdateUserLastSeenAtParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserLastSeenAt, arg.ID, arg.LastSeenAt, arg.UpdatedAt)
+	var i User
 	err := row.Scan(
 		&i.ID,
+		&i.Email,
 		&i.Username,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
 		&i.Status,
-		pq.Array(&i.Roles),
-		pq.Array(&i.Groups),
+		&i.RBACRoles,
+		&i.LoginType,
+		&i.AvatarURL,
+		&i.Deleted,
+		&i.LastSeenAt,
 	)
 	return i, err
 }
 
-const getFilteredUserCount = `-- name: GetFilteredUserCount :one
-SELECT
-	COUNT(*)
-FROM
+const updateUserProfile = `-- name: UpdateUserProfile :one
+UPDATE
 	users
+SET
+	email = $2,
+	username = $3,
+	avatar_url = $4,
+	updated_at = $5
 WHERE
-	users.deleted = false
-	-- Start filters
-	-- Filter by name, email or username
-	AND CASE
-		WHEN $1 :: text != '' THEN (
-			email ILIKE concat('%', $1, '%')
-			OR username ILIKE concat('%', $1, '%')
-		)
-		ELSE true
-	END
-	-- Filter by status
-	AND CASE
-		-- @status needs to be a text because it can be empty, If it was
-		-- user_status enum, it would not.
-		WHEN cardinality($2 :: user_status[]) > 0 THEN
-			status = ANY($2 :: user_status[])
-		ELSE true
-	END
-	-- Filter by rbac_roles
-	AND CASE
-		-- @rbac_role allows filtering by rbac roles. If 'member' is included, show everyone, as everyone is a member.
-		WHEN cardinality($3 :: text[]) > 0 AND 'member' != ANY($3 :: text[])
-		THEN rbac_roles && $3 :: text[]
-		ELSE true
-	END
-	-- Authorize Filter clause will be injected below in GetAuthorizedUserCount
-	-- @authorize_filter
+	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
 `
 
-type GetFilteredUserCountParams struct {
-	Search   string       `db:"search" json:"search"`
-	Status   []UserStatus `db:"status" json:"status"`
-	RbacRole []string     `db:"rbac_role" json:"rbac_role"`
+type UpdateUserProfileParams struct {
+	ID        uuid.UUID      `db:"id" json:"id"`
+	Email     string         `db:"email" json:"email"`
+	Username  string         `db:"username" json:"username"`
+	AvatarURL sql.NullString `db:"avatar_url" json:"avatar_url"`
+	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"`
 }
 
-// This will never count deleted users.
-func (q *sqlQuerier) GetFilteredUserCount(ctx context.Context, arg GetFilteredUserCountParams) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getFilteredUserCount, arg.Search, pq.Array(arg.Status), pq.Array(arg.RbacRole))
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+func (q *sqlQuerier) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserProfile,
+		arg.ID,
+		arg.Email,
+		arg.Username,
+		arg.AvatarURL,
+		arg.UpdatedAt,
+	)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.HashedPassword,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Status,
+		&i.RBACRoles,
+		&i.LoginType,
+		&i.AvatarURL,
+		&i.Deleted,
+		&i.LastSeenAt,
+	)
+	return i, err
 }
 
-const getUserByEmailOrUsername = `-- name: GetUserByEmailOrUsername :one
-SELECT
-	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
-FROM
+const updateUserRoles = `-- name: UpdateUserRoles :one
+UPDATE
 	users
+SET
+	-- Remove all duplicates from the roles.
+	rbac_roles = ARRAY(SELECT DISTINCT UNNEST($1 :: text[]))
 WHERE
-	(LOWER(username) = LOWER($1) OR LOWER(email) = LOWER($2)) AND
-	deleted = false
-LIMIT
-	1
+	id = $2
+RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
 `
 
-type GetUserByEmailOrUsernameParams struct {
-	Username string `db:"username" json:"username"`
-	Email    string `db:"email" json:"email"`
+type UpdateUserRolesParams struct {
+	GrantedRoles []string  `db:"granted_roles" json:"granted_roles"`
+	ID           uuid.UUID `db:"id" json:"id"`
 }
 
-func (q *sqlQuerier) GetUserByEmailOrUsername(ctx context.Context, arg GetUserByEmailOrUsernameParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserByEmailOrUsername, arg.Username, arg.Email)
+func (q *sqlQuerier) UpdateUserRoles(ctx context.Context, arg UpdateUserRolesParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserRoles, pq.Array(arg.GrantedRoles), arg.ID)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -4419,19 +7377,24 @@ func (q *sqlQuerier) GetUserByEmailOrUsername(ctx context.Context, arg GetUserBy
 	return i, err
 }
 
-const getUserByID = `-- name: GetUserByID :one
-SELECT
-	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
-FROM
+const updateUserStatus = `-- name: UpdateUserStatus :one
+UPDATE
 	users
+SET
+	status = $2,
+	updated_at = $3
 WHERE
-	id = $1
-LIMIT
-	1
+	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
 `
 
-func (q *sqlQuerier) GetUserByID(ctx context.Context, id uuid.UUID) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserByID, id)
+type UpdateUserStatusParams struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	Status    UserStatus `db:"status" json:"status"`
+	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+func (q *sqlQuerier) UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, updateUserStatus, arg.ID, arg.Status, arg.UpdatedAt)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -4450,139 +7413,62 @@ func (q *sqlQuerier) GetUserByID(ctx context.Context, id uuid.UUID) (User, error
 	return i, err
 }
 
-const getUserCount = `-- name: GetUserCount :one
-SELECT
-	COUNT(*)
-FROM
-	users
+const deleteOldWebhookDeliveries = `-- name: DeleteOldWebhookDeliveries :exec
+DELETE FROM
+	webhook_deliveries
 WHERE
-	deleted = false
+	created_at < $1 :: timestamptz
 `
 
-func (q *sqlQuerier) GetUserCount(ctx context.Context) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getUserCount)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+// The delivery log is only kept for debugging recent deliveries.
+func (q *sqlQuerier) DeleteOldWebhookDeliveries(ctx context.Context, before time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteOldWebhookDeliveries, before)
+	return err
 }
 
-const getUsers = `-- name: GetUsers :many
-SELECT
-	id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at, COUNT(*) OVER() AS count
-FROM
-	users
+const deleteWebhookByID = `-- name: DeleteWebhookByID :exec
+DELETE FROM
+	webhooks
 WHERE
-	users.deleted = false
-	AND CASE
-		-- This allows using the last element on a page as effectively a cursor.
-		-- This is an important option for scripts that need to paginate without
-		-- duplicating or missing data.
-		WHEN $1 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN (
-			-- The pagination cursor is the last ID of the previous page.
-			-- The query is ordered by the created_at field, so select all
-			-- rows after the cursor.
-			(created_at, id) > (
-				SELECT
-					created_at, id
-				FROM
-					users
-				WHERE
-					id = $1
-			)
-		)
-		ELSE true
-	END
-	-- Start filters
-	-- Filter by name, email or username
-	AND CASE
-		WHEN $2 :: text != '' THEN (
-			email ILIKE concat('%', $2, '%')
-			OR username ILIKE concat('%', $2, '%')
-		)
-		ELSE true
-	END
-	-- Filter by status
-	AND CASE
-		-- @status needs to be a text because it can be empty, If it was
-		-- user_status enum, it would not.
-		WHEN cardinality($3 :: user_status[]) > 0 THEN
-			status = ANY($3 :: user_status[])
-		ELSE true
-	END
-	-- Filter by rbac_roles
-	AND CASE
-		-- @rbac_role allows filtering by rbac roles. If 'member' is included, show everyone, as
-	    -- everyone is a member.
-		WHEN cardinality($4 :: text[]) > 0 AND 'member' != ANY($4 :: text[]) THEN
-		    rbac_roles && $4 :: text[]
-		ELSE true
-	END
-	-- End of filters
-ORDER BY
-	-- Deterministic and consistent ordering of all users, even if they share
-	-- a timestamp. This is to ensure consistent pagination.
-	(created_at, id) ASC OFFSET $5
-LIMIT
-	-- A null limit means "no limit", so 0 means return all
-	NULLIF($6 :: int, 0)
+	id = $1
 `
 
-type GetUsersParams struct {
-	AfterID   uuid.UUID    `db:"after_id" json:"after_id"`
-	Search    string       `db:"search" json:"search"`
-	Status    []UserStatus `db:"status" json:"status"`
-	RbacRole  []string     `db:"rbac_role" json:"rbac_role"`
-	OffsetOpt int32        `db:"offset_opt" json:"offset_opt"`
-	LimitOpt  int32        `db:"limit_opt" json:"limit_opt"`
+func (q *sqlQuerier) DeleteWebhookByID(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, deleteWebhookByID, id)
+	return err
 }
 
-type GetUsersRow struct {
-	ID             uuid.UUID      `db:"id" json:"id"`
-	Email          string         `db:"email" json:"email"`
-	Username       string         `db:"username" json:"username"`
-	HashedPassword []byte         `db:"hashed_password" json:"hashed_password"`
-	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time      `db:"updated_at" json:"updated_at"`
-	Status         UserStatus     `db:"status" json:"status"`
-	RBACRoles      pq.StringArray `db:"rbac_roles" json:"rbac_roles"`
-	LoginType      LoginType      `db:"login_type" json:"login_type"`
-	AvatarURL      sql.NullString `db:"avatar_url" json:"avatar_url"`
-	Deleted        bool           `db:"deleted" json:"deleted"`
-	LastSeenAt     time.Time      `db:"last_seen_at" json:"last_seen_at"`
-	Count          int64          `db:"count" json:"count"`
-}
+const getDeploymentWebhooks = `-- name: GetDeploymentWebhooks :many
+SELECT
+	id, organization_id, created_by, created_at, updated_at, name, url, secret, events, enabled
+FROM
+	webhooks
+WHERE
+	organization_id IS NULL
+ORDER BY
+	name ASC
+`
 
-// This will never return deleted users.
-func (q *sqlQuerier) GetUsers(ctx context.Context, arg GetUsersParams) ([]GetUsersRow, error) {
-	rows, err := q.db.QueryContext(ctx, getUsers,
-		arg.AfterID,
-		arg.Search,
-		pq.Array(arg.Status),
-		pq.Array(arg.RbacRole),
-		arg.OffsetOpt,
-		arg.LimitOpt,
-	)
+func (q *sqlQuerier) GetDeploymentWebhooks(ctx context.Context) ([]Webhook, error) {
+	rows, err := q.db.QueryContext(ctx, getDeploymentWebhooks)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetUsersRow
+	var items []Webhook
 	for rows.Next() {
-		var i GetUsersRow
+		var i Webhook
 		if err := rows.Scan(
 			&i.ID,
-			&i.Email,
-			&i.Username,
-			&i.HashedPassword,
+			&i.OrganizationID,
+			&i.CreatedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.Status,
-			&i.RBACRoles,
-			&i.LoginType,
-			&i.AvatarURL,
-			&i.Deleted,
-			&i.LastSeenAt,
-			&i.Count,
+			&i.Name,
+			&i.Url,
+			&i.Secret,
+			pq.Array(&i.Events),
+			&i.Enabled,
 		); err != nil {
 			return nil, err
 		}
@@ -4597,35 +7483,51 @@ func (q *sqlQuerier) GetUsers(ctx context.Context, arg GetUsersParams) ([]GetUse
 	return items, nil
 }
 
-const getUsersByIDs = `-- name: GetUsersByIDs :many
-SELECT id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at FROM users WHERE id = ANY($1 :: uuid [ ])
+const getEnabledWebhooksByEvent = `-- name: GetEnabledWebhooksByEvent :many
+SELECT
+	id, organization_id, created_by, created_at, updated_at, name, url, secret, events, enabled
+FROM
+	webhooks
+WHERE
+	enabled
+	AND $1 :: text = ANY(events)
+	AND (
+		organization_id IS NULL
+		OR organization_id = ANY($2 :: uuid[])
+	)
 `
 
-// This shouldn't check for deleted, because it's frequently used
-// to look up references to actions. eg. a user could build a workspace
-// for another user, then be deleted... we still want them to appear!
-func (q *sqlQuerier) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]User, error) {
-	rows, err := q.db.QueryContext(ctx, getUsersByIDs, pq.Array(ids))
+type GetEnabledWebhooksByEventParams struct {
+	Event           string      `db:"event" json:"event"`
+	OrganizationIDs []uuid.UUID `db:"organization_ids" json:"organization_ids"`
+}
+
+// Returns the webhooks subscribed to the event. Deployment-wide webhooks
+// receive events from every organization. Events about users are in every
+// organization of the user, but each webhook is only returned once.
+func (q *sqlQuerier) GetEnabledWebhooksByEvent(ctx context.Context, arg GetEnabledWebhooksByEventParams) ([]Webhook, error) {
+	rows, err := q.db.QueryContext(ctx, getEnabledWebhooksByEvent,
+		arg.Event,
+		pq.Array(arg.OrganizationIDs),
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []User
+	var items []Webhook
 	for rows.Next() {
-		var i User
+		var i Webhook
 		if err := rows.Scan(
 			&i.ID,
-			&i.Email,
-			&i.Username,
-			&i.HashedPassword,
+			&i.OrganizationID,
+			&i.CreatedBy,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.Status,
-			&i.RBACRoles,
-			&i.LoginType,
-			&i.AvatarURL,
-			&i.Deleted,
-			&i.LastSeenAt,
+			&i.Name,
+			&i.Url,
+			&i.Secret,
+			pq.Array(&i.Events),
+			&i.Enabled,
 		); err != nil {
 			return nil, err
 		}
@@ -4640,250 +7542,343 @@ func (q *sqlQuerier) GetUsersByIDs(ctx context.Context, ids []uuid.UUID) ([]User
 	return items, nil
 }
 
-const insertUser = `-- name: InsertUser :one
-INSERT INTO
-	users (
-		id,
-		email,
-		username,
-		hashed_password,
-		created_at,
-		updated_at,
-		rbac_roles,
-		login_type
-	)
-VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
+const getWebhookByID = `-- name: GetWebhookByID :one
+SELECT
+	id, organization_id, created_by, created_at, updated_at, name, url, secret, events, enabled
+FROM
+	webhooks
+WHERE
+	id = $1
+LIMIT
+	1
 `
 
-type InsertUserParams struct {
-	ID             uuid.UUID      `db:"id" json:"id"`
-	Email          string         `db:"email" json:"email"`
-	Username       string         `db:"username" json:"username"`
-	HashedPassword []byte         `db:"hashed_password" json:"hashed_password"`
-	CreatedAt      time.Time      `db:"created_at" json:"created_at"`
-	UpdatedAt      time.Time      `db:"updated_at" json:"updated_at"`
-	RBACRoles      pq.StringArray `db:"rbac_roles" json:"rbac_roles"`
-	LoginType      LoginType      `db:"login_type" json:"login_type"`
-}
-
-func (q *sqlQuerier) InsertUser(ctx context.Context, arg InsertUserParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, insertUser,
-		arg.ID,
-		arg.Email,
-		arg.Username,
-		arg.HashedPassword,
-		arg.CreatedAt,
-		arg.UpdatedAt,
-		arg.RBACRoles,
-		arg.LoginType,
-	)
-	var i User
+func (q *sqlQuerier) GetWebhookByID(ctx context.Context, id uuid.UUID) (Webhook, error) {
+	row := q.db.QueryRowContext(ctx, getWebhookByID, id)
+	var i Webhook
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
-		&i.Username,
-		&i.HashedPassword,
+		&i.OrganizationID,
+		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Status,
-		&i.RBACRoles,
-		&i.LoginType,
-		&i.AvatarURL,
-		&i.Deleted,
-		&i.LastSeenAt,
+		&i.Name,
+		&i.Url,
+		&i.Secret,
+		pq.Array(&i.Events),
+		&i.Enabled,
 	)
 	return i, err
 }
 
-const updateUserDeletedByID = `-- name: UpdateUserDeletedByID :exec
-UPDATE
-	users
-SET
-	deleted = $2
+const getWebhookDeliveriesByWebhookID = `-- name: GetWebhookDeliveriesByWebhookID :many
+SELECT
+	id, webhook_id, event_id, event, payload, attempt, created_at, delivered_at, status_code, response_body, error
+FROM
+	webhook_deliveries
 WHERE
-	id = $1
+	webhook_id = $1
+ORDER BY
+	created_at DESC
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF($2 :: int, 0)
 `
 
-type UpdateUserDeletedByIDParams struct {
-	ID      uuid.UUID `db:"id" json:"id"`
-	Deleted bool      `db:"deleted" json:"deleted"`
+type GetWebhookDeliveriesByWebhookIDParams struct {
+	WebhookID uuid.UUID `db:"webhook_id" json:"webhook_id"`
+	LimitOpt  int32     `db:"limit_opt" json:"limit_opt"`
 }
 
-func (q *sqlQuerier) UpdateUserDeletedByID(ctx context.Context, arg UpdateUserDeletedByIDParams) error {
-	_, err := q.db.ExecContext(ctx, updateUserDeletedByID, arg.ID, arg.Deleted)
-	return err
+func (q *sqlQuerier) GetWebhookDeliveriesByWebhookID(ctx context.Context, arg GetWebhookDeliveriesByWebhookIDParams) ([]WebhookDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhookDeliveriesByWebhookID,
+		arg.WebhookID,
+		arg.LimitOpt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []WebhookDelivery
+	for rows.Next() {
+		var i WebhookDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.WebhookID,
+			&i.EventID,
+			&i.Event,
+			&i.Payload,
+			&i.Attempt,
+			&i.CreatedAt,
+			&i.DeliveredAt,
+			&i.StatusCode,
+			&i.ResponseBody,
+			&i.Error,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const updateUserHashedPassword = `-- name: UpdateUserHashedPassword :exec
-UPDATE
-	users
-SET
-	hashed_password = $2
+const getWebhooksByOrganizationID = `-- name: GetWebhooksByOrganizationID :many
+SELECT
+	id, organization_id, created_by, created_at, updated_at, name, url, secret, events, enabled
+FROM
+	webhooks
 WHERE
-	id = $1
+	organization_id = $1 :: uuid
+ORDER BY
+	name ASC
 `
 
-type UpdateUserHashedPasswordParams struct {
-	ID             uuid.UUID `db:"id" json:"id"`
-	HashedPassword []byte    `db:"hashed_password" json:"hashed_password"`
-}
-
-func (q *sqlQuerier) UpdateUserHashedPassword(ctx context.Context, arg UpdateUserHashedPasswordParams) error {
-	_, err := q.db.ExecContext(ctx, updateUserHashedPassword, arg.ID, arg.HashedPassword)
-	return err
+func (q *sqlQuerier) GetWebhooksByOrganizationID(ctx context.Context, organizationID uuid.UUID) ([]Webhook, error) {
+	rows, err := q.db.QueryContext(ctx, getWebhooksByOrganizationID, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Webhook
+	for rows.Next() {
+		var i Webhook
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganizationID,
+			&i.CreatedBy,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.Secret,
+			pq.Array(&i.Events),
+			&i.Enabled,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-const updateUserLastSeenAt = `-- name: UpdateUserLastSeenAt :one
-UPDATE
-	users
-SET
-	last_seen_at = $2,
-	updated_at = $3
-WHERE
-	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
+const insertWebhook = `-- name: InsertWebhook :one
+INSERT INTO
+	webhooks (
+		id,
+		organization_id,
+		created_by,
+		created_at,
+		updated_at,
+		name,
+		url,
+		secret,
+		events,
+		enabled
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, organization_id, created_by, created_at, updated_at, name, url, secret, events, enabled
 `
 
-type UpdateUserLastSeenAtParams struct {
-	ID         uuid.UUID `db:"id" json:"id"`
-	LastSeenAt time.Time `db:"last_seen_at" json:"last_seen_at"`
-	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+type InsertWebhookParams struct {
+	ID             uuid.UUID     `db:"id" json:"id"`
+	OrganizationID uuid.NullUUID `db:"organization_id" json:"organization_id"`
+	CreatedBy      uuid.UUID     `db:"created_by" json:"created_by"`
+	CreatedAt      time.Time     `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time     `db:"updated_at" json:"updated_at"`
+	Name           string        `db:"name" json:"name"`
+	Url            string        `db:"url" json:"url"`
+	Secret         string        `db:"secret" json:"secret"`
+	Events         []string      `db:"events" json:"events"`
+	Enabled        bool          `db:"enabled" json:"enabled"`
 }
 
-func (q *sqlQuerier) UpdateUserLastSeenAt(ctx context.Context, arg UpdateUserLastSeenAtParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUserLastSeenAt, arg.ID, arg.LastSeenAt, arg.UpdatedAt)
-	var i User
+func (q *sqlQuerier) InsertWebhook(ctx context.Context, arg InsertWebhookParams) (Webhook, error) {
+	row := q.db.QueryRowContext(ctx, insertWebhook,
+		arg.ID,
+		arg.OrganizationID,
+		arg.CreatedBy,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Name,
+		arg.Url,
+		arg.Secret,
+		pq.Array(arg.Events),
+		arg.Enabled,
+	)
+	var i Webhook
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
-		&i.Username,
-		&i.HashedPassword,
+		&i.OrganizationID,
+		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Status,
-		&i.RBACRoles,
-		&i.LoginType,
-		&i.AvatarURL,
-		&i.Deleted,
-		&i.LastSeenAt,
+		&i.Name,
+		&i.Url,
+		&i.Secret,
+		pq.Array(&i.Events),
+		&i.Enabled,
 	)
 	return i, err
 }
 
-const updateUserProfile = `-- name: UpdateUserProfile :one
-UPDATE
-	users
-SET
-	email = $2,
-	username = $3,
-	avatar_url = $4,
-	updated_at = $5
-WHERE
-	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
-`
-
-type UpdateUserProfileParams struct {
-	ID        uuid.UUID      `db:"id" json:"id"`
-	Email     string         `db:"email" json:"email"`
-	Username  string         `db:"username" json:"username"`
-	AvatarURL sql.NullString `db:"avatar_url" json:"avatar_url"`
-	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"`
+const insertWebhookDelivery = `-- name: InsertWebhookDelivery :one
+INSERT INTO
+	webhook_deliveries (
+		id,
+		webhook_id,
+		event_id,
+		event,
+		payload,
+		attempt,
+		created_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (webhook_id, event_id, attempt) DO NOTHING
+RETURNING id, webhook_id, event_id, event, payload, attempt, created_at, delivered_at, status_code, response_body, error
+`
+
+type InsertWebhookDeliveryParams struct {
+	ID        uuid.UUID       `db:"id" json:"id"`
+	WebhookID uuid.UUID       `db:"webhook_id" json:"webhook_id"`
+	EventID   uuid.UUID       `db:"event_id" json:"event_id"`
+	Event     string          `db:"event" json:"event"`
+	Payload   json.RawMessage `db:"payload" json:"payload"`
+	Attempt   int32           `db:"attempt" json:"attempt"`
+	CreatedAt time.Time       `db:"created_at" json:"created_at"`
 }
 
-func (q *sqlQuerier) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUserProfile,
+// Inserting a delivery claims the attempt. If another replica has already
+// claimed it, no rows are returned.
+func (q *sqlQuerier) InsertWebhookDelivery(ctx context.Context, arg InsertWebhookDeliveryParams) (WebhookDelivery, error) {
+	row := q.db.QueryRowContext(ctx, insertWebhookDelivery,
 		arg.ID,
-		arg.Email,
-		arg.Username,
-		arg.AvatarURL,
-		arg.UpdatedAt,
+		arg.WebhookID,
+		arg.EventID,
+		arg.Event,
+		arg.Payload,
+		arg.Attempt,
+		arg.CreatedAt,
 	)
-	var i User
+	var i WebhookDelivery
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
-		&i.Username,
-		&i.HashedPassword,
+		&i.WebhookID,
+		&i.EventID,
+		&i.Event,
+		&i.Payload,
+		&i.Attempt,
 		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Status,
-		&i.RBACRoles,
-		&i.LoginType,
-		&i.AvatarURL,
-		&i.Deleted,
-		&i.LastSeenAt,
+		&i.DeliveredAt,
+		&i.StatusCode,
+		&i.ResponseBody,
+		&i.Error,
 	)
 	return i, err
 }
 
-const updateUserRoles = `-- name: UpdateUserRoles :one
+const updateWebhookByID = `-- name: UpdateWebhookByID :one
 UPDATE
-	users
+	webhooks
 SET
-	-- Remove all duplicates from the roles.
-	rbac_roles = ARRAY(SELECT DISTINCT UNNEST($1 :: text[]))
+	updated_at = $2,
+	name = $3,
+	url = $4,
+	events = $5,
+	enabled = $6
 WHERE
-	id = $2
-RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
+	id = $1
+RETURNING id, organization_id, created_by, created_at, updated_at, name, url, secret, events, enabled
 `
 
-type UpdateUserRolesParams struct {
-	GrantedRoles []string  `db:"granted_roles" json:"granted_roles"`
-	ID           uuid.UUID `db:"id" json:"id"`
+type UpdateWebhookByIDParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+	Name      string    `db:"name" json:"name"`
+	Url       string    `db:"url" json:"url"`
+	Events    []string  `db:"events" json:"events"`
+	Enabled   bool      `db:"enabled" json:"enabled"`
 }
 
-func (q *sqlQuerier) UpdateUserRoles(ctx context.Context, arg UpdateUserRolesParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUserRoles, pq.Array(arg.GrantedRoles), arg.ID)
-	var i User
+func (q *sqlQuerier) UpdateWebhookByID(ctx context.Context, arg UpdateWebhookByIDParams) (Webhook, error) {
+	row := q.db.QueryRowContext(ctx, updateWebhookByID,
+		arg.ID,
+		arg.UpdatedAt,
+		arg.Name,
+		arg.Url,
+		pq.Array(arg.Events),
+		arg.Enabled,
+	)
+	var i Webhook
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
-		&i.Username,
-		&i.HashedPassword,
+		&i.OrganizationID,
+		&i.CreatedBy,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.Status,
-		&i.RBACRoles,
-		&i.LoginType,
-		&i.AvatarURL,
-		&i.Deleted,
-		&i.LastSeenAt,
+		&i.Name,
+		&i.Url,
+		&i.Secret,
+		pq.Array(&i.Events),
+		&i.Enabled,
 	)
 	return i, err
 }
 
-const updateUserStatus = `-- name: UpdateUserStatus :one
+const updateWebhookDeliveryByID = `-- name: UpdateWebhookDeliveryByID :one
 UPDATE
-	users
+	webhook_deliveries
 SET
-	status = $2,
-	updated_at = $3
+	delivered_at = $2,
+	status_code = $3,
+	response_body = $4,
+	error = $5
 WHERE
-	id = $1 RETURNING id, email, username, hashed_password, created_at, updated_at, status, rbac_roles, login_type, avatar_url, deleted, last_seen_at
+	id = $1
+RETURNING id, webhook_id, event_id, event, payload, attempt, created_at, delivered_at, status_code, response_body, error
 `
 
-type UpdateUserStatusParams struct {
-	ID        uuid.UUID  `db:"id" json:"id"`
-	Status    UserStatus `db:"status" json:"status"`
-	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
+type UpdateWebhookDeliveryByIDParams struct {
+	ID           uuid.UUID     `db:"id" json:"id"`
+	DeliveredAt  sql.NullTime  `db:"delivered_at" json:"delivered_at"`
+	StatusCode   sql.NullInt32 `db:"status_code" json:"status_code"`
+	ResponseBody string        `db:"response_body" json:"response_body"`
+	Error        string        `db:"error" json:"error"`
 }
 
-func (q *sqlQuerier) UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (User, error) {
-	row := q.db.QueryRowContext(ctx, updateUserStatus, arg.ID, arg.Status, arg.UpdatedAt)
-	var i User
+func (q *sqlQuerier) UpdateWebhookDeliveryByID(ctx context.Context, arg UpdateWebhookDeliveryByIDParams) (WebhookDelivery, error) {
+	row := q.db.QueryRowContext(ctx, updateWebhookDeliveryByID,
+		arg.ID,
+		arg.DeliveredAt,
+		arg.StatusCode,
+		arg.ResponseBody,
+		arg.Error,
+	)
+	var i WebhookDelivery
 	err := row.Scan(
 		&i.ID,
-		&i.Email,
-		&i.Username,
-		&i.HashedPassword,
+		&i.WebhookID,
+		&i.EventID,
+		&i.Event,
+		&i.Payload,
+		&i.Attempt,
 		&i.CreatedAt,
-		&i.UpdatedAt,
-		&i.Status,
-		&i.RBACRoles,
-		&i.LoginType,
-		&i.AvatarURL,
-		&i.Deleted,
-		&i.LastSeenAt,
+		&i.DeliveredAt,
+		&i.StatusCode,
+		&i.ResponseBody,
+		&i.Error,
 	)
 	return i, err
 }
@@ -5237,6 +8232,39 @@ func (q *sqlQuerier) InsertWorkspaceAgent(ctx context.Context, arg InsertWorkspa
 	return i, err
 }
 
+const updateWorkspaceAgentAuthTokensByWorkspaceID = `-- name: UpdateWorkspaceAgentAuthTokensByWorkspaceID :exec
+UPDATE
+	workspace_agents
+SET
+	auth_token = gen_random_uuid(),
+	updated_at = $2
+WHERE
+	resource_id IN (
+		SELECT
+			workspace_resources.id
+		FROM
+			workspace_resources
+		INNER JOIN
+			workspace_builds
+		ON
+			workspace_builds.job_id = workspace_resources.job_id
+		WHERE
+			workspace_builds.workspace_id = $1
+	)
+`
+
+type UpdateWorkspaceAgentAuthTokensByWorkspaceIDParams struct {
+	WorkspaceID uuid.UUID `db:"workspace_id" json:"workspace_id"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Invalidates the tokens of the agents of every build of the workspace, e.g.
+// when it is transferred to another user.
+func (q *sqlQuerier) UpdateWorkspaceAgentAuthTokensByWorkspaceID(ctx context.Context, arg UpdateWorkspaceAgentAuthTokensByWorkspaceIDParams) error {
+	_, err := q.db.ExecContext(ctx, updateWorkspaceAgentAuthTokensByWorkspaceID, arg.WorkspaceID, arg.UpdatedAt)
+	return err
+}
+
 const updateWorkspaceAgentConnectionByID = `-- name: UpdateWorkspaceAgentConnectionByID :exec
 UPDATE
 	workspace_agents
@@ -5416,10 +8444,11 @@ INSERT INTO
 		rx_packets,
 		rx_bytes,
 		tx_packets,
-		tx_bytes
+		tx_bytes,
+		connections_by_port
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING id, created_at, user_id, agent_id, workspace_id, template_id, connections_by_proto, connection_count, rx_packets, rx_bytes, tx_packets, tx_bytes
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING id, created_at, user_id, agent_id, workspace_id, template_id, connections_by_proto, connection_count, rx_packets, rx_bytes, tx_packets, tx_bytes, connections_by_port
 `
 
 type InsertWorkspaceAgentStatParams struct {
@@ -5435,6 +8464,7 @@ type InsertWorkspaceAgentStatParams struct {
 	RxBytes            int64           `db:"rx_bytes" json:"rx_bytes"`
 	TxPackets          int64           `db:"tx_packets" json:"tx_packets"`
 	TxBytes            int64           `db:"tx_bytes" json:"tx_bytes"`
+	ConnectionsByPort  json.RawMessage `db:"connections_by_port" json:"connections_by_port"`
 }
 
 func (q *sqlQuerier) InsertWorkspaceAgentStat(ctx context.Context, arg InsertWorkspaceAgentStatParams) (WorkspaceAgentStat, error) {
@@ -5451,6 +8481,7 @@ func (q *sqlQuerier) InsertWorkspaceAgentStat(ctx context.Context, arg InsertWor
 		arg.RxBytes,
 		arg.TxPackets,
 		arg.TxBytes,
+		arg.ConnectionsByPort,
 	)
 	var i WorkspaceAgentStat
 	err := row.Scan(
@@ -5466,6 +8497,7 @@ func (q *sqlQuerier) InsertWorkspaceAgentStat(ctx context.Context, arg InsertWor
 		&i.RxBytes,
 		&i.TxPackets,
 		&i.TxBytes,
+		&i.ConnectionsByPort,
 	)
 	return i, err
 }
@@ -6623,7 +9655,7 @@ func (q *sqlQuerier) InsertWorkspaceResourceMetadata(ctx context.Context, arg In
 
 const getWorkspaceByAgentID = `-- name: GetWorkspaceByAgentID :one
 SELECT
-	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at
+	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, user_acl, group_acl, labels
 FROM
 	workspaces
 WHERE
@@ -6666,13 +9698,16 @@ func (q *sqlQuerier) GetWorkspaceByAgentID(ctx context.Context, agentID uuid.UUI
 		&i.AutostartSchedule,
 		&i.Ttl,
 		&i.LastUsedAt,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.Labels,
 	)
 	return i, err
 }
 
 const getWorkspaceByID = `-- name: GetWorkspaceByID :one
 SELECT
-	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at
+	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, user_acl, group_acl, labels
 FROM
 	workspaces
 WHERE
@@ -6696,13 +9731,16 @@ func (q *sqlQuerier) GetWorkspaceByID(ctx context.Context, id uuid.UUID) (Worksp
 		&i.AutostartSchedule,
 		&i.Ttl,
 		&i.LastUsedAt,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.Labels,
 	)
 	return i, err
 }
 
 const getWorkspaceByOwnerIDAndName = `-- name: GetWorkspaceByOwnerIDAndName :one
 SELECT
-	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at
+	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, user_acl, group_acl, labels
 FROM
 	workspaces
 WHERE
@@ -6733,13 +9771,16 @@ func (q *sqlQuerier) GetWorkspaceByOwnerIDAndName(ctx context.Context, arg GetWo
 		&i.AutostartSchedule,
 		&i.Ttl,
 		&i.LastUsedAt,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.Labels,
 	)
 	return i, err
 }
 
 const getWorkspaceByWorkspaceAppID = `-- name: GetWorkspaceByWorkspaceAppID :one
 SELECT
-	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at
+	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, user_acl, group_acl, labels
 FROM
 	workspaces
 WHERE
@@ -6789,18 +9830,23 @@ func (q *sqlQuerier) GetWorkspaceByWorkspaceAppID(ctx context.Context, workspace
 		&i.AutostartSchedule,
 		&i.Ttl,
 		&i.LastUsedAt,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.Labels,
 	)
 	return i, err
 }
 
 const getWorkspaces = `-- name: GetWorkspaces :many
 SELECT
-	workspaces.id, workspaces.created_at, workspaces.updated_at, workspaces.owner_id, workspaces.organization_id, workspaces.template_id, workspaces.deleted, workspaces.name, workspaces.autostart_schedule, workspaces.ttl, workspaces.last_used_at, COUNT(*) OVER () as count
+	workspaces.id, workspaces.created_at, workspaces.updated_at, workspaces.owner_id, workspaces.organization_id, workspaces.template_id, workspaces.deleted, workspaces.name, workspaces.autostart_schedule, workspaces.ttl, workspaces.last_used_at, workspaces.user_acl, workspaces.group_acl, workspaces.labels, COUNT(*) OVER () as count
 FROM
 	workspaces
 LEFT JOIN LATERAL (
 	SELECT
 		workspace_builds.transition,
+		workspace_builds.template_version_id,
+		workspace_builds.reason,
 		provisioner_jobs.id AS provisioner_job_id,
 		provisioner_jobs.started_at,
 		provisioner_jobs.updated_at,
@@ -6821,6 +9867,8 @@ LEFT JOIN LATERAL (
 		1
 ) latest_build ON TRUE
 WHERE
+	-- Filters listed in negated_filters only return workspaces that do not
+	-- match them.
 	-- Optionally include deleted workspaces
 	workspaces.deleted = $1
 	AND CASE
@@ -6883,46 +9931,52 @@ WHERE
 
 				ELSE
 					true
-			END
+			END != ('status' = ANY($3 :: text[]) IS TRUE)
 		ELSE true
 	END
 	-- Filter by owner_id
 	AND CASE
-		WHEN $3 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
-			owner_id = $3
+		WHEN $4 :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			(owner_id = $4) != ('owner' = ANY($3 :: text[]) IS TRUE)
 		ELSE true
 	END
 	-- Filter by owner_name
 	AND CASE
-		WHEN $4 :: text != '' THEN
-			owner_id = (SELECT id FROM users WHERE lower(username) = lower($4) AND deleted = false)
+		WHEN $5 :: text != '' THEN
+			(owner_id = ANY(SELECT id FROM users WHERE lower(username) = lower($5) AND deleted = false)) != ('owner' = ANY($3 :: text[]) IS TRUE)
 		ELSE true
 	END
 	-- Filter by template_name
 	-- There can be more than 1 template with the same name across organizations.
 	-- Use the organization filter to restrict to 1 org if needed.
 	AND CASE
-		WHEN $5 :: text != '' THEN
-			template_id = ANY(SELECT id FROM templates WHERE lower(name) = lower($5) AND deleted = false)
+		WHEN $6 :: text != '' THEN
+			(template_id = ANY(SELECT id FROM templates WHERE lower(name) = lower($6) AND deleted = false)) != ('template' = ANY($3 :: text[]) IS TRUE)
 		ELSE true
 	END
 	-- Filter by template_ids
 	AND CASE
-		WHEN array_length($6 :: uuid[], 1) > 0 THEN
-			template_id = ANY($6)
+		WHEN array_length($7 :: uuid[], 1) > 0 THEN
+			template_id = ANY($7)
 		ELSE true
 	END
 	-- Filter by name, matching on substring
 	AND CASE
-		WHEN $7 :: text != '' THEN
-			name ILIKE '%' || $7 || '%'
+		WHEN $8 :: text != '' THEN
+			(name ILIKE '%' || $8 || '%') != ('name' = ANY($3 :: text[]) IS TRUE)
+		ELSE true
+	END
+	-- Filter by labels, the workspace must have all of the given labels
+	AND CASE
+		WHEN $9 :: jsonb != '{}'::jsonb THEN
+			(labels @> $9 :: jsonb) != ('label' = ANY($3 :: text[]) IS TRUE)
 		ELSE true
 	END
 	-- Filter by agent status
 	-- has-agent: is only applicable for workspaces in "start" transition. Stopped and deleted workspaces don't have agents.
 	AND CASE
-		WHEN $8 :: text != '' THEN
-			(
+		WHEN $10 :: text != '' THEN
+			((
 				SELECT COUNT(*)
 				FROM
 					workspace_resources
@@ -6933,7 +9987,7 @@ WHERE
 				WHERE
 					workspace_resources.job_id = latest_build.provisioner_job_id AND
 					latest_build.transition = 'start'::workspace_transition AND
-					$8 = (
+					$10 = (
 						CASE
 							WHEN workspace_agents.first_connected_at IS NULL THEN
 								CASE
@@ -6944,7 +9998,7 @@ WHERE
 								END
 							WHEN workspace_agents.disconnected_at > workspace_agents.last_connected_at THEN
 								'disconnected'
-							WHEN NOW() - workspace_agents.last_connected_at > INTERVAL '1 second' * $9 :: bigint THEN
+							WHEN NOW() - workspace_agents.last_connected_at > INTERVAL '1 second' * $11 :: bigint THEN
 								'disconnected'
 							WHEN workspace_agents.last_connected_at IS NOT NULL THEN
 								'connected'
@@ -6952,7 +10006,49 @@ WHERE
 								NULL
 						END
 					)
-			) > 0
+			) > 0) != ('agent_status' = ANY($3 :: text[]) IS TRUE)
+		ELSE true
+	END
+	-- Filter by the reason of the latest build
+	AND CASE
+		WHEN $12 :: text != '' THEN
+			(latest_build.reason :: text = $12) != ('build_reason' = ANY($3 :: text[]) IS TRUE)
+		ELSE true
+	END
+	-- Filter by workspaces whose latest build is not on the active template version
+	AND CASE
+		WHEN $13 :: boolean THEN
+			(latest_build.template_version_id != (SELECT active_version_id FROM templates WHERE id = workspaces.template_id)) != ('outdated' = ANY($3 :: text[]) IS TRUE)
+		ELSE true
+	END
+	-- Filter by workspaces with at least one failed build
+	AND CASE
+		WHEN $14 :: boolean THEN
+			EXISTS (
+				SELECT
+					1
+				FROM
+					workspace_builds
+				JOIN
+					provisioner_jobs
+				ON
+					provisioner_jobs.id = workspace_builds.job_id
+				WHERE
+					workspace_builds.workspace_id = workspaces.id AND
+					provisioner_jobs.completed_at IS NOT NULL AND
+					COALESCE(provisioner_jobs.error, '') != ''
+			) != ('has_failed_build' = ANY($3 :: text[]) IS TRUE)
+		ELSE true
+	END
+	-- Filter by last used time
+	AND CASE
+		WHEN $15 :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			(last_used_at < $15) != ('last_used_before' = ANY($3 :: text[]) IS TRUE)
+		ELSE true
+	END
+	AND CASE
+		WHEN $16 :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			(last_used_at >= $16) != ('last_used_after' = ANY($3 :: text[]) IS TRUE)
 		ELSE true
 	END
 	-- Authorize Filter clause will be injected below in GetAuthorizedWorkspaces
@@ -6961,53 +10057,70 @@ ORDER BY
 	last_used_at DESC
 LIMIT
 	CASE
-		WHEN $11 :: integer > 0 THEN
-			$11
+		WHEN $18 :: integer > 0 THEN
+			$18
 	END
 OFFSET
-	$10
+	$17
 `
 
 type GetWorkspacesParams struct {
-	Deleted                               bool        `db:"deleted" json:"deleted"`
-	Status                                string      `db:"status" json:"status"`
-	OwnerID                               uuid.UUID   `db:"owner_id" json:"owner_id"`
-	OwnerUsername                         string      `db:"owner_username" json:"owner_username"`
-	TemplateName                          string      `db:"template_name" json:"template_name"`
-	TemplateIds                           []uuid.UUID `db:"template_ids" json:"template_ids"`
-	Name                                  string      `db:"name" json:"name"`
-	HasAgent                              string      `db:"has_agent" json:"has_agent"`
-	AgentInactiveDisconnectTimeoutSeconds int64       `db:"agent_inactive_disconnect_timeout_seconds" json:"agent_inactive_disconnect_timeout_seconds"`
-	Offset                                int32       `db:"offset_" json:"offset_"`
-	Limit                                 int32       `db:"limit_" json:"limit_"`
+	Deleted                               bool            `db:"deleted" json:"deleted"`
+	Status                                string          `db:"status" json:"status"`
+	NegatedFilters                        []string        `db:"negated_filters" json:"negated_filters"`
+	OwnerID                               uuid.UUID       `db:"owner_id" json:"owner_id"`
+	OwnerUsername                         string          `db:"owner_username" json:"owner_username"`
+	TemplateName                          string          `db:"template_name" json:"template_name"`
+	TemplateIds                           []uuid.UUID     `db:"template_ids" json:"template_ids"`
+	Name                                  string          `db:"name" json:"name"`
+	Labels                                json.RawMessage `db:"labels" json:"labels"`
+	HasAgent                              string          `db:"has_agent" json:"has_agent"`
+	AgentInactiveDisconnectTimeoutSeconds int64           `db:"agent_inactive_disconnect_timeout_seconds" json:"agent_inactive_disconnect_timeout_seconds"`
+	BuildReason                           string          `db:"build_reason" json:"build_reason"`
+	Outdated                              bool            `db:"outdated" json:"outdated"`
+	HasFailedBuild                        bool            `db:"has_failed_build" json:"has_failed_build"`
+	LastUsedBefore                        time.Time       `db:"last_used_before" json:"last_used_before"`
+	LastUsedAfter                         time.Time       `db:"last_used_after" json:"last_used_after"`
+	Offset                                int32           `db:"offset_" json:"offset_"`
+	Limit                                 int32           `db:"limit_" json:"limit_"`
 }
 
 type GetWorkspacesRow struct {
-	ID                uuid.UUID      `db:"id" json:"id"`
-	CreatedAt         time.Time      `db:"created_at" json:"created_at"`
-	UpdatedAt         time.Time      `db:"updated_at" json:"updated_at"`
-	OwnerID           uuid.UUID      `db:"owner_id" json:"owner_id"`
-	OrganizationID    uuid.UUID      `db:"organization_id" json:"organization_id"`
-	TemplateID        uuid.UUID      `db:"template_id" json:"template_id"`
-	Deleted           bool           `db:"deleted" json:"deleted"`
-	Name              string         `db:"name" json:"name"`
-	AutostartSchedule sql.NullString `db:"autostart_schedule" json:"autostart_schedule"`
-	Ttl               sql.NullInt64  `db:"ttl" json:"ttl"`
-	LastUsedAt        time.Time      `db:"last_used_at" json:"last_used_at"`
-	Count             int64          `db:"count" json:"count"`
+	ID                uuid.UUID       `db:"id" json:"id"`
+	CreatedAt         time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time       `db:"updated_at" json:"updated_at"`
+	OwnerID           uuid.UUID       `db:"owner_id" json:"owner_id"`
+	OrganizationID    uuid.UUID       `db:"organization_id" json:"organization_id"`
+	TemplateID        uuid.UUID       `db:"template_id" json:"template_id"`
+	Deleted           bool            `db:"deleted" json:"deleted"`
+	Name              string          `db:"name" json:"name"`
+	AutostartSchedule sql.NullString  `db:"autostart_schedule" json:"autostart_schedule"`
+	Ttl               sql.NullInt64   `db:"ttl" json:"ttl"`
+	LastUsedAt        time.Time       `db:"last_used_at" json:"last_used_at"`
+	UserACL           WorkspaceACL    `db:"user_acl" json:"user_acl"`
+	GroupACL          WorkspaceACL    `db:"group_acl" json:"group_acl"`
+	Labels            WorkspaceLabels `db:"labels" json:"labels"`
+	Count             int64           `db:"count" json:"count"`
 }
 
 func (q *sqlQuerier) GetWorkspaces(ctx context.Context, arg GetWorkspacesParams) ([]GetWorkspacesRow, error) {
 	rows, err := q.db.QueryContext(ctx, getWorkspaces,
 		arg.Deleted,
 		arg.Status,
+		pq.Array(arg.NegatedFilters),
 		arg.OwnerID,
 		arg.OwnerUsername,
 		arg.TemplateName,
 		pq.Array(arg.TemplateIds),
 		arg.Name,
+		arg.Labels,
 		arg.HasAgent,
 		arg.AgentInactiveDisconnectTimeoutSeconds,
+		arg.BuildReason,
+		arg.Outdated,
+		arg.HasFailedBuild,
+		arg.LastUsedBefore,
+		arg.LastUsedAfter,
 		arg.Offset,
 		arg.Limit,
 	)
@@ -7030,6 +10143,9 @@ func (q *sqlQuerier) GetWorkspaces(ctx context.Context, arg GetWorkspacesParams)
 			&i.AutostartSchedule,
 			&i.Ttl,
 			&i.LastUsedAt,
+			&i.UserACL,
+			&i.GroupACL,
+			&i.Labels,
 			&i.Count,
 		); err != nil {
 			return nil, err
@@ -7056,22 +10172,24 @@ INSERT INTO
 		template_id,
 		name,
 		autostart_schedule,
-		ttl
+		ttl,
+		labels
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, user_acl, group_acl, labels
 `
 
 type InsertWorkspaceParams struct {
-	ID                uuid.UUID      `db:"id" json:"id"`
-	CreatedAt         time.Time      `db:"created_at" json:"created_at"`
-	UpdatedAt         time.Time      `db:"updated_at" json:"updated_at"`
-	OwnerID           uuid.UUID      `db:"owner_id" json:"owner_id"`
-	OrganizationID    uuid.UUID      `db:"organization_id" json:"organization_id"`
-	TemplateID        uuid.UUID      `db:"template_id" json:"template_id"`
-	Name              string         `db:"name" json:"name"`
-	AutostartSchedule sql.NullString `db:"autostart_schedule" json:"autostart_schedule"`
-	Ttl               sql.NullInt64  `db:"ttl" json:"ttl"`
+	ID                uuid.UUID       `db:"id" json:"id"`
+	CreatedAt         time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time       `db:"updated_at" json:"updated_at"`
+	OwnerID           uuid.UUID       `db:"owner_id" json:"owner_id"`
+	OrganizationID    uuid.UUID       `db:"organization_id" json:"organization_id"`
+	TemplateID        uuid.UUID       `db:"template_id" json:"template_id"`
+	Name              string          `db:"name" json:"name"`
+	AutostartSchedule sql.NullString  `db:"autostart_schedule" json:"autostart_schedule"`
+	Ttl               sql.NullInt64   `db:"ttl" json:"ttl"`
+	Labels            WorkspaceLabels `db:"labels" json:"labels"`
 }
 
 func (q *sqlQuerier) InsertWorkspace(ctx context.Context, arg InsertWorkspaceParams) (Workspace, error) {
@@ -7085,6 +10203,7 @@ func (q *sqlQuerier) InsertWorkspace(ctx context.Context, arg InsertWorkspacePar
 		arg.Name,
 		arg.AutostartSchedule,
 		arg.Ttl,
+		arg.Labels,
 	)
 	var i Workspace
 	err := row.Scan(
@@ -7099,6 +10218,9 @@ func (q *sqlQuerier) InsertWorkspace(ctx context.Context, arg InsertWorkspacePar
 		&i.AutostartSchedule,
 		&i.Ttl,
 		&i.LastUsedAt,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.Labels,
 	)
 	return i, err
 }
@@ -7111,7 +10233,7 @@ SET
 WHERE
 	id = $1
 	AND deleted = false
-RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at
+RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, user_acl, group_acl, labels
 `
 
 type UpdateWorkspaceParams struct {
@@ -7134,6 +10256,49 @@ func (q *sqlQuerier) UpdateWorkspace(ctx context.Context, arg UpdateWorkspacePar
 		&i.AutostartSchedule,
 		&i.Ttl,
 		&i.LastUsedAt,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.Labels,
+	)
+	return i, err
+}
+
+const updateWorkspaceACLByID = `-- name: UpdateWorkspaceACLByID :one
+UPDATE
+	workspaces
+SET
+	group_acl = $1,
+	user_acl = $2
+WHERE
+	id = $3
+RETURNING
+	id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, user_acl, group_acl, labels
+`
+
+type UpdateWorkspaceACLByIDParams struct {
+	GroupACL WorkspaceACL `db:"group_acl" json:"group_acl"`
+	UserACL  WorkspaceACL `db:"user_acl" json:"user_acl"`
+	ID       uuid.UUID    `db:"id" json:"id"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceACLByID(ctx context.Context, arg UpdateWorkspaceACLByIDParams) (Workspace, error) {
+	row := q.db.QueryRowContext(ctx, updateWorkspaceACLByID, arg.GroupACL, arg.UserACL, arg.ID)
+	var i Workspace
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OwnerID,
+		&i.OrganizationID,
+		&i.TemplateID,
+		&i.Deleted,
+		&i.Name,
+		&i.AutostartSchedule,
+		&i.Ttl,
+		&i.LastUsedAt,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.Labels,
 	)
 	return i, err
 }
@@ -7176,6 +10341,44 @@ func (q *sqlQuerier) UpdateWorkspaceDeletedByID(ctx context.Context, arg UpdateW
 	return err
 }
 
+const updateWorkspaceLabelsByID = `-- name: UpdateWorkspaceLabelsByID :one
+UPDATE
+	workspaces
+SET
+	labels = $2
+WHERE
+	id = $1
+	AND deleted = false
+RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, user_acl, group_acl, labels
+`
+
+type UpdateWorkspaceLabelsByIDParams struct {
+	ID     uuid.UUID       `db:"id" json:"id"`
+	Labels WorkspaceLabels `db:"labels" json:"labels"`
+}
+
+func (q *sqlQuerier) UpdateWorkspaceLabelsByID(ctx context.Context, arg UpdateWorkspaceLabelsByIDParams) (Workspace, error) {
+	row := q.db.QueryRowContext(ctx, updateWorkspaceLabelsByID, arg.ID, arg.Labels)
+	var i Workspace
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OwnerID,
+		&i.OrganizationID,
+		&i.TemplateID,
+		&i.Deleted,
+		&i.Name,
+		&i.AutostartSchedule,
+		&i.Ttl,
+		&i.LastUsedAt,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.Labels,
+	)
+	return i, err
+}
+
 const updateWorkspaceLastUsedAt = `-- name: UpdateWorkspaceLastUsedAt :exec
 UPDATE
 	workspaces
@@ -7195,6 +10398,57 @@ func (q *sqlQuerier) UpdateWorkspaceLastUsedAt(ctx context.Context, arg UpdateWo
 	return err
 }
 
+const updateWorkspaceOwnerByID = `-- name: UpdateWorkspaceOwnerByID :one
+UPDATE
+	workspaces
+SET
+	owner_id = $2,
+	name = $3,
+	updated_at = $4,
+	user_acl = '{}'::jsonb,
+	group_acl = '{}'::jsonb
+WHERE
+	id = $1
+	AND deleted = false
+RETURNING id, created_at, updated_at, owner_id, organization_id, template_id, deleted, name, autostart_schedule, ttl, last_used_at, user_acl, group_acl, labels
+`
+
+type UpdateWorkspaceOwnerByIDParams struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	OwnerID   uuid.UUID `db:"owner_id" json:"owner_id"`
+	Name      string    `db:"name" json:"name"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// The workspace is no longer shared, the new owner decides who to share it
+// with.
+func (q *sqlQuerier) UpdateWorkspaceOwnerByID(ctx context.Context, arg UpdateWorkspaceOwnerByIDParams) (Workspace, error) {
+	row := q.db.QueryRowContext(ctx, updateWorkspaceOwnerByID,
+		arg.ID,
+		arg.OwnerID,
+		arg.Name,
+		arg.UpdatedAt,
+	)
+	var i Workspace
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.OwnerID,
+		&i.OrganizationID,
+		&i.TemplateID,
+		&i.Deleted,
+		&i.Name,
+		&i.AutostartSchedule,
+		&i.Ttl,
+		&i.LastUsedAt,
+		&i.UserACL,
+		&i.GroupACL,
+		&i.Labels,
+	)
+	return i, err
+}
+
 const updateWorkspaceTTL = `-- name: UpdateWorkspaceTTL :exec
 UPDATE
 	workspaces
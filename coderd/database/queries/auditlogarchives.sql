@@ -0,0 +1,27 @@
+-- name: InsertAuditLogArchive :one
+INSERT INTO
+	audit_log_archives (
+		id,
+		created_at,
+		oldest,
+		newest,
+		log_count,
+		storage,
+		hash,
+		size
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8) RETURNING *;
+
+-- GetAuditLogArchivesByTimeRange returns the archives that contain audit logs
+-- from `since` until before `until`.
+-- name: GetAuditLogArchivesByTimeRange :many
+SELECT
+	*
+FROM
+	audit_log_archives
+WHERE
+	newest >= @since :: timestamp with time zone
+	AND oldest < @until :: timestamp with time zone
+ORDER BY
+	oldest, id;
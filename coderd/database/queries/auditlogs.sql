@@ -53,7 +53,7 @@ WHERE
 	-- Filter by resource_target
 	AND CASE
 		WHEN @resource_target :: text != '' THEN
-			resource_target = @resource_target
+			lower(resource_target) = lower(@resource_target)
 		ELSE true
 	END
 	-- Filter action
@@ -92,13 +92,154 @@ WHERE
             workspace_builds.reason::text = @build_reason
         ELSE true
     END
+	-- Filter by ip, which may be a CIDR range
+	AND CASE
+		WHEN @ip :: inet IS NOT NULL THEN
+			audit_logs.ip <<= @ip :: inet
+		ELSE true
+	END
+	-- Filter by request_id
+	AND CASE
+		WHEN @request_id :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			audit_logs.request_id = @request_id
+		ELSE true
+	END
+	-- Search the diff for free text, matching % and _ literally
+	AND CASE
+		WHEN @search :: text != '' THEN
+			audit_logs.diff :: text ILIKE concat('%', replace(replace(replace(@search :: text, '\', '\\'), '%', '\%'), '_', '\_'), '%')
+		ELSE true
+	END
 ORDER BY
-    "time" DESC
+    "time" DESC,
+    audit_logs.id DESC
 LIMIT
     $1
 OFFSET
     $2;
 
+-- GetAuditLogsForExport returns `row_limit` audit logs that match the filters,
+-- newest first, older than the audit log at `before_time` with `before_id`.
+-- It's used to page through the audit logs for export, so unlike
+-- GetAuditLogsOffset it doesn't count the matching audit logs.
+-- name: GetAuditLogsForExport :many
+SELECT
+    audit_logs.*,
+    users.username AS user_username,
+    users.email AS user_email
+FROM
+    audit_logs
+    LEFT JOIN users ON audit_logs.user_id = users.id
+    LEFT JOIN
+        -- First join on workspaces to get the initial workspace create
+        -- to workspace build 1 id. This is because the first create is
+        -- is a different audit log than subsequent starts.
+        workspaces ON
+		    audit_logs.resource_type = 'workspace' AND
+			audit_logs.resource_id = workspaces.id
+    LEFT JOIN
+	    workspace_builds ON
+            -- Get the reason from the build if the resource type
+            -- is a workspace_build
+            (
+			    audit_logs.resource_type = 'workspace_build'
+                AND audit_logs.resource_id = workspace_builds.id
+			)
+            OR
+            -- Get the reason from the build #1 if this is the first
+            -- workspace create.
+            (
+				audit_logs.resource_type = 'workspace' AND
+				audit_logs.action = 'create' AND
+				workspaces.id = workspace_builds.workspace_id AND
+				workspace_builds.build_number = 1
+			)
+WHERE
+    -- Filter resource_type
+	CASE
+		WHEN @resource_type :: text != '' THEN
+			resource_type = @resource_type :: resource_type
+		ELSE true
+	END
+	-- Filter resource_id
+	AND CASE
+		WHEN @resource_id :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			resource_id = @resource_id
+		ELSE true
+	END
+	-- Filter by resource_target
+	AND CASE
+		WHEN @resource_target :: text != '' THEN
+			lower(resource_target) = lower(@resource_target)
+		ELSE true
+	END
+	-- Filter action
+	AND CASE
+		WHEN @action :: text != '' THEN
+			action = @action :: audit_action
+		ELSE true
+	END
+	-- Filter by username
+	AND CASE
+		WHEN @username :: text != '' THEN
+			users.username = @username
+		ELSE true
+	END
+	-- Filter by user_email
+	AND CASE
+		WHEN @email :: text != '' THEN
+			users.email = @email
+		ELSE true
+	END
+	-- Filter by date_from
+	AND CASE
+		WHEN @date_from :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			"time" >= @date_from
+		ELSE true
+	END
+	-- Filter by date_to
+	AND CASE
+		WHEN @date_to :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			"time" <= @date_to
+		ELSE true
+	END
+    -- Filter by build_reason
+    AND CASE
+	    WHEN @build_reason::text != '' THEN
+            workspace_builds.reason::text = @build_reason
+        ELSE true
+    END
+	-- Filter by ip, which may be a CIDR range
+	AND CASE
+		WHEN @ip :: inet IS NOT NULL THEN
+			audit_logs.ip <<= @ip :: inet
+		ELSE true
+	END
+	-- Filter by request_id
+	AND CASE
+		WHEN @request_id :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			audit_logs.request_id = @request_id
+		ELSE true
+	END
+	-- Search the diff for free text, matching % and _ literally
+	AND CASE
+		WHEN @search :: text != '' THEN
+			audit_logs.diff :: text ILIKE concat('%', replace(replace(replace(@search :: text, '\', '\\'), '%', '\%'), '_', '\_'), '%')
+		ELSE true
+	END
+	-- Page through the audit logs older than the last one of the previous
+	-- page, so new audit logs don't shift the pages like an offset would
+	AND CASE
+		WHEN @before_id :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			(audit_logs."time", audit_logs.id) < (@before_time :: timestamp with time zone, @before_id)
+		ELSE true
+	END
+ORDER BY
+    "time" DESC,
+    audit_logs.id DESC
+LIMIT
+    @row_limit :: int;
+
 -- name: InsertAuditLog :one
 INSERT INTO
 	audit_logs (
@@ -120,3 +261,41 @@ INSERT INTO
     )
 VALUES
 	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING *;
+
+-- GetAuditLogsByTimeRange returns `row_limit` audit logs before `until`, in
+-- the order they happened, after the audit log at `after_time` with
+-- `after_id`. It's used to page through the audit logs of a time range for
+-- export.
+-- name: GetAuditLogsByTimeRange :many
+SELECT
+	*
+FROM
+	audit_logs
+WHERE
+	("time", id) > (@after_time :: timestamp with time zone, @after_id :: uuid)
+	AND "time" < @until :: timestamp with time zone
+ORDER BY
+	"time", id
+LIMIT
+	@row_limit :: int;
+
+-- GetAuditLogsForPurge returns the `row_limit` oldest audit logs before
+-- `before`, locking them so replicas purging at the same time skip them.
+-- name: GetAuditLogsForPurge :many
+SELECT
+	*
+FROM
+	audit_logs
+WHERE
+	"time" < @before :: timestamp with time zone
+ORDER BY
+	"time", id
+LIMIT
+	@row_limit :: int
+FOR UPDATE SKIP LOCKED;
+
+-- name: DeleteAuditLogsByIDs :exec
+DELETE FROM
+	audit_logs
+WHERE
+	id = ANY(@ids :: uuid[]);
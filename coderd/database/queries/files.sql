@@ -23,6 +23,90 @@ LIMIT
 
 -- name: InsertFile :one
 INSERT INTO
-	files (id, hash, created_at, created_by, mimetype, "data")
+	files (id, hash, created_at, created_by, mimetype, "data", storage)
 VALUES
-	($1, $2, $3, $4, $5, $6) RETURNING *;
+	($1, $2, $3, $4, $5, $6, $7) RETURNING *;
+
+-- name: DeleteUnusedFiles :many
+-- Deletes the files created before the cutoff that no future build can use,
+-- and returns them so their contents can be deleted from the backend they're
+-- stored in. A file is needed while it's referenced by the import job of a
+-- template version that isn't archived, or by any provisioner job that hasn't
+-- completed. Files locked by LockFileByID are being used to unarchive a
+-- template version, so they're skipped.
+DELETE FROM
+	files
+WHERE
+	id IN (
+		SELECT
+			id
+		FROM
+			files
+		WHERE
+			created_at < @created_before :: timestamptz
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					template_versions
+				JOIN provisioner_jobs ON provisioner_jobs.id = template_versions.job_id
+				WHERE
+					template_versions.archived = false
+					AND provisioner_jobs.file_id = files.id
+			)
+			AND NOT EXISTS (
+				SELECT
+					1
+				FROM
+					provisioner_jobs
+				WHERE
+					provisioner_jobs.file_id = files.id
+					AND provisioner_jobs.completed_at IS NULL
+			)
+		FOR UPDATE SKIP LOCKED
+	)
+RETURNING id, hash, storage;
+
+-- name: GetFileHashInUse :one
+-- Returns whether any file has the hash, regardless of the backend its
+-- contents are stored in. Contents are only deleted from a backend once no
+-- file refers to them, so files that are being migrated keep their contents.
+SELECT
+	EXISTS (
+		SELECT
+			1
+		FROM
+			files
+		WHERE
+			hash = $1
+	) :: boolean;
+
+-- name: GetFileIDsByStorage :many
+SELECT
+	id
+FROM
+	files
+WHERE
+	storage = $1
+ORDER BY
+	created_at ASC;
+
+-- name: LockFileByID :one
+-- Locks a file until the end of the transaction, so it isn't deleted by
+-- DeleteUnusedFiles before the template version that uses it is unarchived.
+SELECT
+	id
+FROM
+	files
+WHERE
+	id = $1
+FOR SHARE;
+
+-- name: UpdateFileStorageByID :exec
+UPDATE
+	files
+SET
+	storage = $2,
+	"data" = $3
+WHERE
+	id = $1;
@@ -0,0 +1,227 @@
+-- name: GetTemplateActiveWorkspaces :many
+-- Returns the number of workspaces of each template that reported agent stats
+-- on each day since the given time.
+SELECT
+	template_id,
+	(created_at AT TIME ZONE 'UTC')::date AS date,
+	COUNT(DISTINCT workspace_id)::bigint AS workspaces
+FROM
+	workspace_agent_stats
+WHERE
+	created_at >= @start_time :: timestamptz
+GROUP BY
+	template_id, date
+ORDER BY
+	date ASC;
+
+-- name: GetTemplateBuildInsights :many
+-- Returns the workspace builds of every template that ran to completion since
+-- the given time, with how long they took and whether they failed. Canceled
+-- builds are left out.
+SELECT
+	template_versions.template_id,
+	(workspace_builds.created_at AT TIME ZONE 'UTC')::date AS date,
+	workspace_builds.transition,
+	EXTRACT(EPOCH FROM (provisioner_jobs.completed_at - provisioner_jobs.started_at))::FLOAT AS exec_time_sec,
+	(provisioner_jobs.error IS NOT NULL AND provisioner_jobs.error != '')::boolean AS failed
+FROM
+	workspace_builds
+JOIN
+	template_versions ON workspace_builds.template_version_id = template_versions.id
+JOIN
+	provisioner_jobs ON workspace_builds.job_id = provisioner_jobs.id
+WHERE
+	template_versions.template_id IS NOT NULL
+	AND provisioner_jobs.started_at IS NOT NULL
+	AND provisioner_jobs.completed_at IS NOT NULL
+	AND provisioner_jobs.canceled_at IS NULL
+	AND workspace_builds.created_at >= @start_time :: timestamptz
+ORDER BY
+	workspace_builds.created_at ASC;
+
+-- name: GetTemplateConnectionInsights :many
+-- Sums the connections reported by the agents of every template on each day
+-- since the given time, by the agent port they were made to.
+SELECT
+	workspace_agent_stats.template_id,
+	(workspace_agent_stats.created_at AT TIME ZONE 'UTC')::date AS date,
+	ports.key :: text AS port,
+	SUM(ports.value :: bigint)::bigint AS connections
+FROM
+	workspace_agent_stats,
+	jsonb_each_text(workspace_agent_stats.connections_by_port) AS ports
+WHERE
+	workspace_agent_stats.created_at >= @start_time :: timestamptz
+GROUP BY
+	workspace_agent_stats.template_id, date, ports.key
+ORDER BY
+	date ASC;
+
+-- name: GetTemplateInsightsApps :many
+-- Returns the apps served by the agents of every template's workspaces built
+-- since the given time, so connections can be matched to apps by port. Only
+-- the latest definition of each app slug is returned.
+SELECT DISTINCT ON (workspaces.template_id, workspace_apps.slug)
+	workspaces.template_id,
+	workspace_apps.slug,
+	workspace_apps.display_name,
+	workspace_apps.icon,
+	workspace_apps.url
+FROM
+	workspace_apps
+JOIN
+	workspace_agents ON workspace_agents.id = workspace_apps.agent_id
+JOIN
+	workspace_resources ON workspace_resources.id = workspace_agents.resource_id
+JOIN
+	workspace_builds ON workspace_builds.job_id = workspace_resources.job_id
+JOIN
+	workspaces ON workspaces.id = workspace_builds.workspace_id
+WHERE
+	workspace_apps.url IS NOT NULL
+	AND NOT workspace_apps.external
+	AND workspace_builds.created_at >= @start_time :: timestamptz
+ORDER BY
+	workspaces.template_id, workspace_apps.slug, workspace_apps.created_at DESC;
+
+-- name: GetTemplateVersionAdoption :many
+-- Returns how many non-deleted workspaces of every template have their latest
+-- build on each template version.
+WITH latest_builds AS (
+	SELECT DISTINCT ON (workspace_id)
+		workspace_id,
+		template_version_id
+	FROM
+		workspace_builds
+	ORDER BY
+		workspace_id, build_number DESC
+)
+SELECT
+	template_versions.template_id,
+	template_versions.id AS template_version_id,
+	template_versions.name,
+	COUNT(*)::bigint AS workspaces
+FROM
+	latest_builds
+JOIN
+	workspaces ON workspaces.id = latest_builds.workspace_id AND NOT workspaces.deleted
+JOIN
+	template_versions ON template_versions.id = latest_builds.template_version_id
+WHERE
+	template_versions.template_id IS NOT NULL
+GROUP BY
+	template_versions.template_id, template_versions.id, template_versions.name
+ORDER BY
+	workspaces DESC;
+
+-- name: GetUserActivityInsights :many
+-- Returns the activity of every user between the given times. Connected time
+-- is counted in buckets of the agent stats report interval, so connections to
+-- several workspaces at once are only counted once. The cost accrued is the
+-- daily cost of each workspace prorated over the time it was running.
+WITH connections AS (
+	SELECT
+		user_id,
+		COUNT(DISTINCT FLOOR(EXTRACT(EPOCH FROM created_at) / @report_interval_seconds :: bigint))::bigint * @report_interval_seconds :: bigint AS connected_seconds
+	FROM
+		workspace_agent_stats
+	WHERE
+		connection_count > 0
+		AND created_at >= @start_time :: timestamptz
+		AND created_at < @end_time :: timestamptz
+	GROUP BY
+		user_id
+), builds AS (
+	SELECT
+		initiator_id AS user_id,
+		COUNT(*)::bigint AS builds
+	FROM
+		workspace_builds
+	WHERE
+		created_at >= @start_time :: timestamptz
+		AND created_at < @end_time :: timestamptz
+	GROUP BY
+		initiator_id
+), runs AS (
+	SELECT
+		workspaces.owner_id,
+		workspace_builds.transition,
+		workspace_builds.daily_cost,
+		provisioner_jobs.completed_at AS started_at,
+		provisioner_jobs.error,
+		LEAD(workspace_builds.created_at) OVER (PARTITION BY workspace_builds.workspace_id ORDER BY workspace_builds.build_number) AS stopped_at
+	FROM
+		workspace_builds
+	JOIN
+		workspaces ON workspaces.id = workspace_builds.workspace_id
+	JOIN
+		provisioner_jobs ON provisioner_jobs.id = workspace_builds.job_id
+	-- Only the builds in the window, and the latest build of each workspace
+	-- before it, can have been running in the window. Later builds only end
+	-- runs after the window, which is the same as not ending them.
+	WHERE
+		workspace_builds.created_at < @end_time :: timestamptz
+		AND (
+			workspace_builds.created_at >= @start_time :: timestamptz
+			OR workspace_builds.build_number = (
+				SELECT
+					MAX(earlier.build_number)
+				FROM
+					workspace_builds AS earlier
+				WHERE
+					earlier.workspace_id = workspace_builds.workspace_id
+					AND earlier.created_at < @start_time :: timestamptz
+			)
+		)
+), costs AS (
+	SELECT
+		owner_id AS user_id,
+		SUM(daily_cost * EXTRACT(EPOCH FROM (
+			LEAST(COALESCE(stopped_at, NOW()), @end_time :: timestamptz) - GREATEST(started_at, @start_time :: timestamptz)
+		)) / 86400)::float AS cost
+	FROM
+		runs
+	WHERE
+		transition = 'start'
+		AND daily_cost > 0
+		AND started_at IS NOT NULL
+		AND (error IS NULL OR error = '')
+		AND started_at < @end_time :: timestamptz
+		AND COALESCE(stopped_at, NOW()) > @start_time :: timestamptz
+	GROUP BY
+		owner_id
+), owned AS (
+	SELECT
+		owner_id AS user_id,
+		COUNT(*)::bigint AS workspaces
+	FROM
+		workspaces
+	WHERE
+		NOT deleted
+	GROUP BY
+		owner_id
+)
+SELECT
+	users.id AS user_id,
+	users.username,
+	users.email,
+	users.status,
+	users.last_seen_at,
+	COALESCE(owned.workspaces, 0)::bigint AS workspaces,
+	COALESCE(builds.builds, 0)::bigint AS builds,
+	COALESCE(connections.connected_seconds, 0)::bigint AS connected_seconds,
+	COALESCE(costs.cost, 0)::float AS cost
+FROM
+	users
+LEFT JOIN
+	owned ON owned.user_id = users.id
+LEFT JOIN
+	builds ON builds.user_id = users.id
+LEFT JOIN
+	connections ON connections.user_id = users.id
+LEFT JOIN
+	costs ON costs.user_id = users.id
+WHERE
+	NOT users.deleted
+ORDER BY
+	users.username ASC;
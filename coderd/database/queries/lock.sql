@@ -0,0 +1,12 @@
+-- Acquires a lock on the contents stored for a hash, which is released when
+-- the transaction ends. Files are inserted, and their contents deleted, while
+-- holding it, so contents aren't deleted while a file that refers to them is
+-- being inserted. The two key form doesn't share IDs with TryAcquireLock.
+-- name: AcquireFileHashLock :exec
+SELECT pg_advisory_xact_lock(hashtext('files'), hashtext(@hash :: text));
+
+-- Acquires a lock that's released when the transaction ends, without
+-- blocking. Returns whether the lock was acquired. Replicas use it to
+-- ensure only one of them runs a background task at a time.
+-- name: TryAcquireLock :one
+SELECT pg_try_advisory_xact_lock($1);
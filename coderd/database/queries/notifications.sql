@@ -0,0 +1,58 @@
+-- name: GetNotificationPreferencesByUserID :many
+SELECT
+	*
+FROM
+	notification_preferences
+WHERE
+	user_id = $1
+ORDER BY
+	kind ASC;
+
+-- name: UpsertNotificationPreference :one
+INSERT INTO
+	notification_preferences (
+		user_id,
+		kind,
+		disabled,
+		updated_at
+	)
+VALUES
+	($1, $2, $3, $4)
+ON CONFLICT (user_id, kind) DO UPDATE
+SET
+	disabled = $3,
+	updated_at = $4
+RETURNING *;
+
+-- name: InsertNotificationMessage :one
+-- Inserting a message claims it. If another replica has already claimed
+-- the dedupe key, no rows are returned.
+INSERT INTO
+	notification_messages (
+		id,
+		user_id,
+		kind,
+		dedupe_key,
+		subject,
+		created_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6)
+ON CONFLICT (dedupe_key) DO NOTHING
+RETURNING *;
+
+-- name: DeleteNotificationMessageByID :exec
+-- Releases the claim on a message that could not be sent, so it is
+-- retried.
+DELETE FROM
+	notification_messages
+WHERE
+	id = $1;
+
+-- name: DeleteOldNotificationMessages :exec
+-- Messages are only kept to dedupe notifications that several replicas
+-- decide to send at about the same time.
+DELETE FROM
+	notification_messages
+WHERE
+	created_at < @before :: timestamptz;
@@ -11,7 +11,25 @@ INSERT INTO
 		created_at,
 		"name",
 		provisioners,
-		tags
+		tags,
+		last_seen_at,
+		version
 	)
 VALUES
-	($1, $2, $3, $4, $5) RETURNING *;
+	($1, $2, $3, $4, $5, $6, $7) RETURNING *;
+
+-- name: UpdateProvisionerDaemonLastSeenAt :exec
+UPDATE
+	provisioner_daemons
+SET
+	last_seen_at = $2
+WHERE
+	id = $1;
+
+-- name: DeleteOldProvisionerDaemons :exec
+-- Daemons are inserted every time they connect, so the daemons that haven't
+-- been seen for a while are deleted to keep the list of daemons readable.
+DELETE FROM
+	provisioner_daemons
+WHERE
+	COALESCE(last_seen_at, created_at) < @before :: timestamptz;
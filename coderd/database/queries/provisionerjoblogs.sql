@@ -20,3 +20,52 @@ SELECT
 	unnest(@level :: log_level [ ]) AS LEVEL,
 	unnest(@stage :: VARCHAR(128) [ ]) AS stage,
 	unnest(@output :: VARCHAR(1024) [ ]) AS output RETURNING *;
+
+-- name: DeleteArchivedTemplateVersionJobLogs :exec
+-- Deletes the logs of the import and dry-run jobs of archived template
+-- versions.
+DELETE FROM
+	provisioner_job_logs
+WHERE
+	job_id IN (
+		SELECT
+			provisioner_jobs.id
+		FROM
+			provisioner_jobs
+		JOIN template_versions ON template_versions.job_id = provisioner_jobs.id
+			OR (
+				provisioner_jobs.type = 'template_version_dry_run'
+				AND template_versions.id = (provisioner_jobs.input ->> 'template_version_id') :: uuid
+			)
+		WHERE
+			template_versions.archived = true
+	);
+
+-- name: DeleteOldWorkspaceBuildJobLogs :execrows
+-- Deletes up to a limit of the logs of workspace build jobs that completed
+-- before the given time, except for the latest build of each workspace.
+-- Returns how many logs were deleted, so large backlogs are deleted in batches
+-- that don't hold a long transaction.
+DELETE FROM
+	provisioner_job_logs
+WHERE
+	id IN (
+		SELECT
+			provisioner_job_logs.id
+		FROM
+			provisioner_job_logs
+		JOIN provisioner_jobs ON provisioner_jobs.id = provisioner_job_logs.job_id
+		JOIN workspace_builds ON workspace_builds.job_id = provisioner_jobs.id
+		WHERE
+			provisioner_jobs.completed_at < @completed_before :: timestamptz
+			AND workspace_builds.build_number < (
+				SELECT
+					MAX(build_number)
+				FROM
+					workspace_builds AS latest_builds
+				WHERE
+					latest_builds.workspace_id = workspace_builds.workspace_id
+			)
+		LIMIT
+			@row_limit :: int
+	);
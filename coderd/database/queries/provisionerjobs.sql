@@ -1,6 +1,10 @@
 -- Acquires the lock for a single job that isn't started, completed,
 -- canceled, and that matches an array of provisioner types.
 --
+-- Jobs are acquired by priority. Within a priority, jobs of the initiators and
+-- organizations with the fewest running jobs are acquired first, so a single
+-- user or organization can't starve the queue, and then the oldest jobs.
+--
 -- SKIP LOCKED is used to jump over locked rows. This prevents
 -- multiple provisioners from acquiring the same jobs. See:
 -- https://www.postgresql.org/docs/9.5/sql-select.html#SQL-FOR-UPDATE-SHARE
@@ -10,6 +14,7 @@ UPDATE
 SET
 	started_at = @started_at,
 	updated_at = @started_at,
+	last_heartbeat_at = @started_at,
 	worker_id = @worker_id
 WHERE
 	id = (
@@ -23,7 +28,35 @@ WHERE
 			AND nested.provisioner = ANY(@types :: provisioner_type [ ])
 			-- Ensure the caller satisfies all job tags.
 			AND nested.tags <@ @tags :: jsonb 
+			-- Daemons that authenticate with a provisioner key only run the
+			-- jobs of its organization.
+			AND CASE
+				WHEN @organization_id :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+					nested.organization_id = @organization_id
+				ELSE true
+			END
 		ORDER BY
+			nested.priority,
+			(
+				SELECT
+					COUNT(*)
+				FROM
+					provisioner_jobs AS running
+				WHERE
+					running.initiator_id = nested.initiator_id
+					AND running.started_at IS NOT NULL
+					AND running.completed_at IS NULL
+			),
+			(
+				SELECT
+					COUNT(*)
+				FROM
+					provisioner_jobs AS running
+				WHERE
+					running.organization_id = nested.organization_id
+					AND running.started_at IS NOT NULL
+					AND running.completed_at IS NULL
+			),
 			nested.created_at
 		FOR UPDATE
 		SKIP LOCKED
@@ -39,6 +72,58 @@ FROM
 WHERE
 	id = $1;
 
+-- Returns the position of pending jobs in the queue of pending jobs with the
+-- same provisioner and tags, which are acquired by the same provisioner
+-- daemons. The position accounts for priorities but not for fairness between
+-- users and organizations, so it's an estimate. The running jobs and average
+-- duration of recent jobs in the queue are returned to estimate the wait.
+-- name: GetProvisionerJobQueuePositionsByIDs :many
+WITH pending_jobs AS (
+	SELECT
+		id,
+		provisioner,
+		tags,
+		ROW_NUMBER() OVER (PARTITION BY provisioner, tags ORDER BY priority, created_at) AS queue_position,
+		COUNT(*) OVER (PARTITION BY provisioner, tags) AS queue_size
+	FROM
+		provisioner_jobs
+	WHERE
+		started_at IS NULL
+		AND completed_at IS NULL
+)
+SELECT
+	pending_jobs.id,
+	pending_jobs.provisioner,
+	pending_jobs.tags,
+	pending_jobs.queue_position,
+	pending_jobs.queue_size,
+	(
+		SELECT
+			COUNT(*)
+		FROM
+			provisioner_jobs
+		WHERE
+			provisioner_jobs.provisioner = pending_jobs.provisioner
+			AND provisioner_jobs.tags = pending_jobs.tags
+			AND provisioner_jobs.started_at IS NOT NULL
+			AND provisioner_jobs.completed_at IS NULL
+	) :: bigint AS running_jobs,
+	(
+		SELECT
+			COALESCE(AVG(EXTRACT(EPOCH FROM provisioner_jobs.completed_at - provisioner_jobs.started_at) * 1000), 0)
+		FROM
+			provisioner_jobs
+		WHERE
+			provisioner_jobs.provisioner = pending_jobs.provisioner
+			AND provisioner_jobs.tags = pending_jobs.tags
+			AND provisioner_jobs.started_at IS NOT NULL
+			AND provisioner_jobs.completed_at > NOW() - INTERVAL '1 day'
+	) :: bigint AS average_duration_ms
+FROM
+	pending_jobs
+WHERE
+	pending_jobs.id = ANY(@ids :: uuid [ ]);
+
 -- name: GetProvisionerJobsByIDs :many
 SELECT
 	*
@@ -50,6 +135,15 @@ WHERE
 -- name: GetProvisionerJobsCreatedAfter :many
 SELECT * FROM provisioner_jobs WHERE created_at > $1;
 
+-- name: GetRunningProvisionerJobs :many
+SELECT
+	*
+FROM
+	provisioner_jobs
+WHERE
+	started_at IS NOT NULL
+	AND completed_at IS NULL;
+
 -- name: InsertProvisionerJob :one
 INSERT INTO
 	provisioner_jobs (
@@ -63,16 +157,20 @@ INSERT INTO
 		file_id,
 		"type",
 		"input",
-		tags
+		tags,
+		priority
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING *;
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) RETURNING *;
 
+-- Records an update from the provisioner daemon running the job, which is
+-- the heartbeat of the job.
 -- name: UpdateProvisionerJobByID :exec
 UPDATE
 	provisioner_jobs
 SET
-	updated_at = $2
+	updated_at = $2,
+	last_heartbeat_at = $2
 WHERE
 	id = $1;
 
@@ -0,0 +1,53 @@
+-- name: InsertProvisionerKey :one
+INSERT INTO
+	provisioner_keys (
+		id,
+		created_at,
+		organization_id,
+		"name",
+		hashed_secret,
+		tags
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6) RETURNING *;
+
+-- name: GetProvisionerKeyByHashedSecret :one
+SELECT
+	*
+FROM
+	provisioner_keys
+WHERE
+	hashed_secret = $1;
+
+-- name: GetProvisionerKeyByID :one
+SELECT
+	*
+FROM
+	provisioner_keys
+WHERE
+	id = $1;
+
+-- name: GetProvisionerKeyByOrganizationIDAndName :one
+SELECT
+	*
+FROM
+	provisioner_keys
+WHERE
+	organization_id = $1
+	AND "name" = $2;
+
+-- name: GetProvisionerKeysByOrganizationID :many
+SELECT
+	*
+FROM
+	provisioner_keys
+WHERE
+	organization_id = $1
+ORDER BY
+	"name" ASC;
+
+-- name: DeleteProvisionerKeyByID :exec
+DELETE FROM
+	provisioner_keys
+WHERE
+	id = $1;
@@ -0,0 +1,120 @@
+-- name: GetTemplateGitSyncByTemplateID :one
+SELECT
+	*
+FROM
+	template_git_syncs
+WHERE
+	template_id = $1
+LIMIT
+	1;
+
+-- name: GetTemplateGitSyncs :many
+-- Returns the git syncs of templates that haven't been deleted.
+SELECT
+	template_git_syncs.*
+FROM
+	template_git_syncs
+JOIN
+	templates ON templates.id = template_git_syncs.template_id
+WHERE
+	NOT templates.deleted
+ORDER BY
+	template_git_syncs.created_at ASC;
+
+-- name: GetTemplateGitSyncsByTemplateIDs :many
+SELECT
+	*
+FROM
+	template_git_syncs
+WHERE
+	template_id = ANY(@ids :: uuid[]);
+
+-- name: UpsertTemplateGitSync :one
+-- Changing the repository of a sync resets it, so a version is created for
+-- the latest commit of the new branch. The webhook secret is kept.
+INSERT INTO
+	template_git_syncs (
+		template_id,
+		created_at,
+		updated_at,
+		user_id,
+		git_url,
+		git_branch,
+		git_directory,
+		webhook_secret
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (template_id) DO UPDATE SET
+	updated_at = $3,
+	user_id = $4,
+	git_url = $5,
+	git_branch = $6,
+	git_directory = $7,
+	commit_sha = '',
+	template_version_id = NULL,
+	dry_run_job_id = NULL,
+	status = 'pending',
+	error = ''
+RETURNING *;
+
+-- name: UpdateTemplateGitSyncCommitByTemplateID :one
+-- Updating the commit claims it, so only one replica creates a version for
+-- it. If another replica has already claimed it, no rows are returned. A
+-- claim of the same commit without a version is stale once it was checked
+-- before the cutoff, e.g. because the replica that claimed it was stopped, and
+-- can be claimed again.
+UPDATE
+	template_git_syncs
+SET
+	commit_sha = @commit_sha,
+	checked_at = @checked_at,
+	template_version_id = NULL,
+	dry_run_job_id = NULL,
+	status = 'pending',
+	error = ''
+WHERE
+	template_id = @template_id
+	AND commit_sha = @previous_commit_sha
+	AND (
+		commit_sha != @commit_sha
+		OR (
+			template_version_id IS NULL
+			AND checked_at < @stale_before :: timestamptz
+		)
+	)
+RETURNING *;
+
+-- name: UpdateTemplateGitSyncDryRunJobByTemplateID :one
+-- Setting the dry-run job claims the dry-run of the version, so only one
+-- replica creates it. If another replica has already claimed it, or a version
+-- was created for a newer commit, no rows are returned.
+UPDATE
+	template_git_syncs
+SET
+	dry_run_job_id = @dry_run_job_id,
+	checked_at = @checked_at
+WHERE
+	template_id = @template_id
+	AND template_version_id = @template_version_id
+	AND dry_run_job_id IS NULL
+RETURNING *;
+
+-- name: UpdateTemplateGitSyncStatusByTemplateID :one
+UPDATE
+	template_git_syncs
+SET
+	commit_sha = $2,
+	template_version_id = $3,
+	status = $4,
+	error = $5,
+	checked_at = $6
+WHERE
+	template_id = $1
+RETURNING *;
+
+-- name: DeleteTemplateGitSyncByTemplateID :exec
+DELETE FROM
+	template_git_syncs
+WHERE
+	template_id = $1;
@@ -34,9 +34,65 @@ WHERE
 			id = ANY(@ids)
 		ELSE true
 	END
+	-- Filter by name, matching on substring
+	AND CASE
+		WHEN @fuzzy_name :: text != '' THEN
+			"name" ILIKE '%' || @fuzzy_name || '%'
+		ELSE true
+	END
+	-- Filter by provisioner
+	AND CASE
+		WHEN @provisioner :: text != '' THEN
+			provisioner :: text = @provisioner
+		ELSE true
+	END
+	-- Filter by created_by
+	AND CASE
+		WHEN @created_by :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			created_by = @created_by
+		ELSE true
+	END
+	-- Filter by created_by username
+	AND CASE
+		WHEN @created_by_username :: text != '' THEN
+			created_by = ANY(SELECT id FROM users WHERE lower(username) = lower(@created_by_username) AND deleted = false)
+		ELSE true
+	END
+	-- Filter by whether the template has workspaces that are not deleted
+	AND CASE
+		WHEN sqlc.narg('has_active_workspaces') :: boolean IS NOT NULL THEN
+			EXISTS (
+				SELECT
+					1
+				FROM
+					workspaces
+				WHERE
+					workspaces.template_id = templates.id AND
+					workspaces.deleted = false
+			) = sqlc.narg('has_active_workspaces') :: boolean
+		ELSE true
+	END
+	-- Filter by whether the template is deprecated
+	AND CASE
+		WHEN sqlc.narg('deprecated') :: boolean IS NOT NULL THEN
+			(templates.deprecated != '') = sqlc.narg('deprecated') :: boolean
+		ELSE true
+	END
+	-- Search the name, display name and a full-text search of the description
+	AND CASE
+		WHEN @search :: text != '' THEN (
+			"name" ILIKE concat('%', @search, '%')
+			OR display_name ILIKE concat('%', @search, '%')
+			OR to_tsvector('english', description) @@ plainto_tsquery('english', @search)
+		)
+		ELSE true
+	END
   -- Authorize Filter clause will be injected below in GetAuthorizedTemplates
   -- @authorize_filter
-ORDER BY (name, id) ASC
+ORDER BY (name, id) ASC OFFSET @offset_opt
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF(@limit_opt :: int, 0)
 ;
 
 -- name: GetTemplateByOrganizationAndName :one
@@ -96,6 +152,17 @@ SET
 WHERE
 	id = $1;
 
+-- name: UpdateTemplateDeprecatedByID :one
+UPDATE
+	templates
+SET
+	updated_at = $2,
+	deprecated = $3
+WHERE
+	id = $1
+RETURNING
+	*;
+
 -- name: UpdateTemplateMetaByID :one
 UPDATE
 	templates
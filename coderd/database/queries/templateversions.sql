@@ -24,6 +24,11 @@ WHERE
 		)
 		ELSE true
 	END
+	AND CASE
+		-- A null filter returns archived and unarchived versions alike.
+		WHEN sqlc.narg('archived') :: boolean IS NULL THEN true
+		ELSE template_versions.archived = sqlc.narg('archived') :: boolean
+	END
 ORDER BY
     -- Deterministic and consistent ordering of all rows, even if they share
     -- a timestamp. This is to ensure consistent pagination.
@@ -79,10 +84,14 @@ INSERT INTO
 		"name",
 		readme,
 		job_id,
-		created_by
+		created_by,
+		git_url,
+		git_ref,
+		git_directory,
+		git_commit_sha
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING *;
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) RETURNING *;
 
 -- name: UpdateTemplateVersionByID :exec
 UPDATE
@@ -126,3 +135,71 @@ WHERE
 	AND template_id = $3
 ORDER BY created_at DESC
 LIMIT 1;
+
+-- name: ArchiveUnusedTemplateVersions :many
+-- Archives the versions of a template that no workspace uses, and returns the
+-- IDs of the versions that were archived. A version is in use while it is the
+-- active version of a template, or the version of the latest build of a
+-- workspace that hasn't been deleted. Versions that are still importing are
+-- never archived. Pass a template version ID to archive just that version.
+UPDATE
+	template_versions
+SET
+	archived = true,
+	updated_at = @updated_at
+WHERE
+	template_versions.template_id = @template_id :: uuid
+	AND template_versions.archived = false
+	AND CASE
+		WHEN @template_version_id :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			template_versions.id = @template_version_id :: uuid
+		ELSE true
+	END
+	-- Templates can share their active version, so it mustn't be the active
+	-- version of any template.
+	AND NOT EXISTS (
+		SELECT
+			1
+		FROM
+			templates
+		WHERE
+			templates.active_version_id = template_versions.id
+			AND templates.deleted = false
+	)
+	AND NOT EXISTS (
+		SELECT
+			1
+		FROM
+			workspace_builds
+		JOIN workspaces ON workspaces.id = workspace_builds.workspace_id
+		WHERE
+			workspace_builds.template_version_id = template_versions.id
+			AND workspaces.deleted = false
+			AND workspace_builds.build_number = (
+				SELECT
+					MAX(latest.build_number)
+				FROM
+					workspace_builds AS latest
+				WHERE
+					latest.workspace_id = workspace_builds.workspace_id
+			)
+	)
+	AND EXISTS (
+		SELECT
+			1
+		FROM
+			provisioner_jobs
+		WHERE
+			provisioner_jobs.id = template_versions.job_id
+			AND provisioner_jobs.completed_at IS NOT NULL
+	)
+RETURNING template_versions.id;
+
+-- name: UnarchiveTemplateVersion :exec
+UPDATE
+	template_versions
+SET
+	archived = false,
+	updated_at = @updated_at
+WHERE
+	id = @template_version_id;
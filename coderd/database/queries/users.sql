@@ -181,6 +181,68 @@ WHERE
 		    rbac_roles && @rbac_role :: text[]
 		ELSE true
 	END
+	-- Filter by login_type
+	AND CASE
+		WHEN cardinality(@login_type :: login_type[]) > 0 THEN
+			login_type = ANY(@login_type :: login_type[])
+		ELSE true
+	END
+	-- Filter by last_seen_at
+	AND CASE
+		WHEN @last_seen_before :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			last_seen_at < @last_seen_before
+		ELSE true
+	END
+	-- Filter by created_at
+	AND CASE
+		WHEN @created_after :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			created_at >= @created_after
+		ELSE true
+	END
+	-- Filter by group name. Group names are only unique within an
+	-- organization, so the group is looked up in the organization filtered
+	-- by. The members of the "Everyone" group, which shares the ID of its
+	-- organization, are the members of the organization.
+	AND CASE
+		WHEN @group_name :: text != '' THEN (
+			id = ANY(
+				SELECT
+					group_members.user_id
+				FROM
+					group_members
+				JOIN
+					groups ON groups.id = group_members.group_id
+				WHERE
+					lower(groups.name) = lower(@group_name)
+					AND groups.organization_id = @organization_id :: uuid
+			)
+			OR id = ANY(
+				SELECT
+					organization_members.user_id
+				FROM
+					organization_members
+				JOIN
+					groups ON groups.id = organization_members.organization_id
+				WHERE
+					lower(groups.name) = lower(@group_name)
+					AND groups.organization_id = @organization_id :: uuid
+			)
+		)
+		ELSE true
+	END
+	-- Filter by organization membership
+	AND CASE
+		WHEN @organization_id :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
+			id = ANY(
+				SELECT
+					organization_members.user_id
+				FROM
+					organization_members
+				WHERE
+					organization_members.organization_id = @organization_id
+			)
+		ELSE true
+	END
 	-- End of filters
 ORDER BY
 	-- Deterministic and consistent ordering of all users, even if they share
@@ -0,0 +1,131 @@
+-- name: InsertWebhook :one
+INSERT INTO
+	webhooks (
+		id,
+		organization_id,
+		created_by,
+		created_at,
+		updated_at,
+		name,
+		url,
+		secret,
+		events,
+		enabled
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING *;
+
+-- name: GetWebhookByID :one
+SELECT
+	*
+FROM
+	webhooks
+WHERE
+	id = $1
+LIMIT
+	1;
+
+-- name: GetDeploymentWebhooks :many
+SELECT
+	*
+FROM
+	webhooks
+WHERE
+	organization_id IS NULL
+ORDER BY
+	name ASC;
+
+-- name: GetWebhooksByOrganizationID :many
+SELECT
+	*
+FROM
+	webhooks
+WHERE
+	organization_id = @organization_id :: uuid
+ORDER BY
+	name ASC;
+
+-- name: GetEnabledWebhooksByEvent :many
+-- Returns the webhooks subscribed to the event. Deployment-wide webhooks
+-- receive events from every organization. Events about users are in every
+-- organization of the user, but each webhook is only returned once.
+SELECT
+	*
+FROM
+	webhooks
+WHERE
+	enabled
+	AND @event :: text = ANY(events)
+	AND (
+		organization_id IS NULL
+		OR organization_id = ANY(@organization_ids :: uuid[])
+	);
+
+-- name: UpdateWebhookByID :one
+UPDATE
+	webhooks
+SET
+	updated_at = $2,
+	name = $3,
+	url = $4,
+	events = $5,
+	enabled = $6
+WHERE
+	id = $1
+RETURNING *;
+
+-- name: DeleteWebhookByID :exec
+DELETE FROM
+	webhooks
+WHERE
+	id = $1;
+
+-- name: InsertWebhookDelivery :one
+-- Inserting a delivery claims the attempt. If another replica has already
+-- claimed it, no rows are returned.
+INSERT INTO
+	webhook_deliveries (
+		id,
+		webhook_id,
+		event_id,
+		event,
+		payload,
+		attempt,
+		created_at
+	)
+VALUES
+	($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (webhook_id, event_id, attempt) DO NOTHING
+RETURNING *;
+
+-- name: UpdateWebhookDeliveryByID :one
+UPDATE
+	webhook_deliveries
+SET
+	delivered_at = $2,
+	status_code = $3,
+	response_body = $4,
+	error = $5
+WHERE
+	id = $1
+RETURNING *;
+
+-- name: GetWebhookDeliveriesByWebhookID :many
+SELECT
+	*
+FROM
+	webhook_deliveries
+WHERE
+	webhook_id = @webhook_id
+ORDER BY
+	created_at DESC
+LIMIT
+	-- A null limit means "no limit", so 0 means return all
+	NULLIF(@limit_opt :: int, 0);
+
+-- name: DeleteOldWebhookDeliveries :exec
+-- The delivery log is only kept for debugging recent deliveries.
+DELETE FROM
+	webhook_deliveries
+WHERE
+	created_at < @before :: timestamptz;
@@ -63,6 +63,28 @@ INSERT INTO
 VALUES
 	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19) RETURNING *;
 
+-- name: UpdateWorkspaceAgentAuthTokensByWorkspaceID :exec
+-- Invalidates the tokens of the agents of every build of the workspace, e.g.
+-- when it is transferred to another user.
+UPDATE
+	workspace_agents
+SET
+	auth_token = gen_random_uuid(),
+	updated_at = $2
+WHERE
+	resource_id IN (
+		SELECT
+			workspace_resources.id
+		FROM
+			workspace_resources
+		INNER JOIN
+			workspace_builds
+		ON
+			workspace_builds.job_id = workspace_resources.job_id
+		WHERE
+			workspace_builds.workspace_id = $1
+	);
+
 -- name: UpdateWorkspaceAgentConnectionByID :exec
 UPDATE
 	workspace_agents
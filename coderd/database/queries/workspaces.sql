@@ -81,6 +81,8 @@ FROM
 LEFT JOIN LATERAL (
 	SELECT
 		workspace_builds.transition,
+		workspace_builds.template_version_id,
+		workspace_builds.reason,
 		provisioner_jobs.id AS provisioner_job_id,
 		provisioner_jobs.started_at,
 		provisioner_jobs.updated_at,
@@ -101,6 +103,8 @@ LEFT JOIN LATERAL (
 		1
 ) latest_build ON TRUE
 WHERE
+	-- Filters listed in negated_filters only return workspaces that do not
+	-- match them.
 	-- Optionally include deleted workspaces
 	workspaces.deleted = @deleted
 	AND CASE
@@ -163,19 +167,19 @@ WHERE
 
 				ELSE
 					true
-			END
+			END != ('status' = ANY(@negated_filters :: text[]) IS TRUE)
 		ELSE true
 	END
 	-- Filter by owner_id
 	AND CASE
 		WHEN @owner_id :: uuid != '00000000-0000-0000-0000-000000000000'::uuid THEN
-			owner_id = @owner_id
+			(owner_id = @owner_id) != ('owner' = ANY(@negated_filters :: text[]) IS TRUE)
 		ELSE true
 	END
 	-- Filter by owner_name
 	AND CASE
 		WHEN @owner_username :: text != '' THEN
-			owner_id = (SELECT id FROM users WHERE lower(username) = lower(@owner_username) AND deleted = false)
+			(owner_id = ANY(SELECT id FROM users WHERE lower(username) = lower(@owner_username) AND deleted = false)) != ('owner' = ANY(@negated_filters :: text[]) IS TRUE)
 		ELSE true
 	END
 	-- Filter by template_name
@@ -183,7 +187,7 @@ WHERE
 	-- Use the organization filter to restrict to 1 org if needed.
 	AND CASE
 		WHEN @template_name :: text != '' THEN
-			template_id = ANY(SELECT id FROM templates WHERE lower(name) = lower(@template_name) AND deleted = false)
+			(template_id = ANY(SELECT id FROM templates WHERE lower(name) = lower(@template_name) AND deleted = false)) != ('template' = ANY(@negated_filters :: text[]) IS TRUE)
 		ELSE true
 	END
 	-- Filter by template_ids
@@ -195,14 +199,20 @@ WHERE
 	-- Filter by name, matching on substring
 	AND CASE
 		WHEN @name :: text != '' THEN
-			name ILIKE '%' || @name || '%'
+			(name ILIKE '%' || @name || '%') != ('name' = ANY(@negated_filters :: text[]) IS TRUE)
+		ELSE true
+	END
+	-- Filter by labels, the workspace must have all of the given labels
+	AND CASE
+		WHEN @labels :: jsonb != '{}'::jsonb THEN
+			(labels @> @labels :: jsonb) != ('label' = ANY(@negated_filters :: text[]) IS TRUE)
 		ELSE true
 	END
 	-- Filter by agent status
 	-- has-agent: is only applicable for workspaces in "start" transition. Stopped and deleted workspaces don't have agents.
 	AND CASE
 		WHEN @has_agent :: text != '' THEN
-			(
+			((
 				SELECT COUNT(*)
 				FROM
 					workspace_resources
@@ -232,7 +242,49 @@ WHERE
 								NULL
 						END
 					)
-			) > 0
+			) > 0) != ('agent_status' = ANY(@negated_filters :: text[]) IS TRUE)
+		ELSE true
+	END
+	-- Filter by the reason of the latest build
+	AND CASE
+		WHEN @build_reason :: text != '' THEN
+			(latest_build.reason :: text = @build_reason) != ('build_reason' = ANY(@negated_filters :: text[]) IS TRUE)
+		ELSE true
+	END
+	-- Filter by workspaces whose latest build is not on the active template version
+	AND CASE
+		WHEN @outdated :: boolean THEN
+			(latest_build.template_version_id != (SELECT active_version_id FROM templates WHERE id = workspaces.template_id)) != ('outdated' = ANY(@negated_filters :: text[]) IS TRUE)
+		ELSE true
+	END
+	-- Filter by workspaces with at least one failed build
+	AND CASE
+		WHEN @has_failed_build :: boolean THEN
+			EXISTS (
+				SELECT
+					1
+				FROM
+					workspace_builds
+				JOIN
+					provisioner_jobs
+				ON
+					provisioner_jobs.id = workspace_builds.job_id
+				WHERE
+					workspace_builds.workspace_id = workspaces.id AND
+					provisioner_jobs.completed_at IS NOT NULL AND
+					COALESCE(provisioner_jobs.error, '') != ''
+			) != ('has_failed_build' = ANY(@negated_filters :: text[]) IS TRUE)
+		ELSE true
+	END
+	-- Filter by last used time
+	AND CASE
+		WHEN @last_used_before :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			(last_used_at < @last_used_before) != ('last_used_before' = ANY(@negated_filters :: text[]) IS TRUE)
+		ELSE true
+	END
+	AND CASE
+		WHEN @last_used_after :: timestamp with time zone != '0001-01-01 00:00:00Z' THEN
+			(last_used_at >= @last_used_after) != ('last_used_after' = ANY(@negated_filters :: text[]) IS TRUE)
 		ELSE true
 	END
 	-- Authorize Filter clause will be injected below in GetAuthorizedWorkspaces
@@ -270,10 +322,11 @@ INSERT INTO
 		template_id,
 		name,
 		autostart_schedule,
-		ttl
+		ttl,
+		labels
 	)
 VALUES
-	($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING *;
+	($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING *;
 
 -- name: UpdateWorkspaceDeletedByID :exec
 UPDATE
@@ -293,6 +346,17 @@ WHERE
 	AND deleted = false
 RETURNING *;
 
+-- name: UpdateWorkspaceACLByID :one
+UPDATE
+	workspaces
+SET
+	group_acl = $1,
+	user_acl = $2
+WHERE
+	id = $3
+RETURNING
+	*;
+
 -- name: UpdateWorkspaceAutostart :exec
 UPDATE
 	workspaces
@@ -301,6 +365,32 @@ SET
 WHERE
 	id = $1;
 
+-- name: UpdateWorkspaceLabelsByID :one
+UPDATE
+	workspaces
+SET
+	labels = $2
+WHERE
+	id = $1
+	AND deleted = false
+RETURNING *;
+
+-- name: UpdateWorkspaceOwnerByID :one
+-- The workspace is no longer shared, the new owner decides who to share it
+-- with.
+UPDATE
+	workspaces
+SET
+	owner_id = $2,
+	name = $3,
+	updated_at = $4,
+	user_acl = '{}'::jsonb,
+	group_acl = '{}'::jsonb
+WHERE
+	id = $1
+	AND deleted = false
+RETURNING *;
+
 -- name: UpdateWorkspaceTTL :exec
 UPDATE
 	workspaces
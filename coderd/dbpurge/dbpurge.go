@@ -0,0 +1,370 @@
+// Package dbpurge deletes data that is no longer needed from the database.
+package dbpurge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/filestore"
+)
+
+// UnusedFileAge is how old a file must be before it's deleted for not being
+// used. This leaves time to create a template version from a file that was
+// just uploaded.
+const UnusedFileAge = 24 * time.Hour
+
+// ProvisionerDaemonAge is how long a provisioner daemon must not have been
+// seen before it's deleted.
+const ProvisionerDaemonAge = 7 * 24 * time.Hour
+
+// WebhookDeliveryAge is how long the log of webhook deliveries is kept.
+const WebhookDeliveryAge = 30 * 24 * time.Hour
+
+// NotificationMessageAge is how long sent notifications are recorded. The
+// dedupe keys of notifications identify one-off events, like a build
+// approaching its deadline, so they aren't sent again after that.
+const NotificationMessageAge = 30 * 24 * time.Hour
+
+// DefaultInterval is how often job logs and audit logs are purged when the
+// garbage collection of archived template versions is disabled.
+const DefaultInterval = time.Hour
+
+// DefaultAuditLogBatchSize is how many audit logs are archived and deleted in
+// a transaction by default.
+const DefaultAuditLogBatchSize = 1000
+
+// DefaultJobLogBatchSize is how many job logs past the retention are deleted
+// in a transaction by default.
+const DefaultJobLogBatchSize = 10000
+
+// Options configures a Purger.
+type Options struct {
+	Database database.Store
+	// FileStore is the store the contents of deleted files are deleted from.
+	// It may be nil if contents are stored in the database.
+	FileStore filestore.Store
+	Logger    slog.Logger
+	// Interval is how often the files and provisioner job logs that are only
+	// used by archived template versions are deleted. If it's 0, they're
+	// never deleted, and everything else is purged every DefaultInterval.
+	Interval time.Duration
+	// JobLogRetention is how long the logs of workspace builds that aren't
+	// the latest build of their workspace are kept after the build completed.
+	// Logs are kept forever if 0.
+	JobLogRetention time.Duration
+	// JobLogBatchSize is how many job logs past the retention are deleted in
+	// a transaction. It defaults to DefaultJobLogBatchSize.
+	JobLogBatchSize int
+	// AuditLogRetention is how long audit logs are kept. They're kept
+	// forever if 0.
+	AuditLogRetention time.Duration
+	// AuditLogArchive is the store audit logs are archived to before they're
+	// deleted. They're deleted without being archived if it's nil.
+	AuditLogArchive filestore.Store
+	// AuditLogBatchSize is how many audit logs are archived and deleted in a
+	// transaction. It defaults to DefaultAuditLogBatchSize.
+	AuditLogBatchSize int
+}
+
+// Purger periodically deletes the files and provisioner job logs that are
+// only used by archived template versions, to reclaim database space.
+// Archived template versions whose files were deleted can't be unarchived.
+// Provisioner daemons that haven't been seen for a week are deleted too.
+// Webhook deliveries and sent notifications are always deleted after
+// WebhookDeliveryAge and NotificationMessageAge. The logs of old workspace
+// builds are deleted after the job log retention, and audit logs are
+// archived and deleted after the audit log retention. Only one replica purges
+// at a time.
+type Purger struct {
+	database          database.Store
+	fileStore         filestore.Store
+	log               slog.Logger
+	interval          time.Duration
+	jobLogRetention   time.Duration
+	jobLogBatchSize   int
+	auditLogRetention time.Duration
+	auditLogArchive   filestore.Store
+	auditLogBatchSize int
+
+	done   chan struct{}
+	cancel func()
+}
+
+// New starts purging every interval until the purger is closed.
+func New(opts Options) *Purger {
+	if opts.JobLogBatchSize <= 0 {
+		opts.JobLogBatchSize = DefaultJobLogBatchSize
+	}
+	if opts.AuditLogBatchSize <= 0 {
+		opts.AuditLogBatchSize = DefaultAuditLogBatchSize
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Purger{
+		database:          opts.Database,
+		fileStore:         opts.FileStore,
+		log:               opts.Logger,
+		interval:          opts.Interval,
+		jobLogRetention:   opts.JobLogRetention,
+		jobLogBatchSize:   opts.JobLogBatchSize,
+		auditLogRetention: opts.AuditLogRetention,
+		auditLogArchive:   opts.AuditLogArchive,
+		auditLogBatchSize: opts.AuditLogBatchSize,
+		done:              make(chan struct{}),
+		cancel:            cancel,
+	}
+	go p.run(ctx)
+	return p
+}
+
+func (p *Purger) run(ctx context.Context) {
+	defer close(p.done)
+
+	interval := p.interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		err := p.purge(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			p.log.Error(ctx, "purge", slog.Error(err))
+		} else {
+			p.log.Debug(ctx, "purged", slog.F("took", time.Since(start)))
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (p *Purger) purge(ctx context.Context) error {
+	//nolint:gocritic // This is a system service.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	var (
+		acquired bool
+		deleted  []database.DeleteUnusedFilesRow
+	)
+	err := p.database.InTx(func(tx database.Store) error {
+		var err error
+		acquired, err = tx.TryAcquireLock(ctx, database.LockIDDBPurge)
+		if err != nil {
+			return xerrors.Errorf("acquire lock: %w", err)
+		}
+		if !acquired {
+			// Another replica is purging.
+			return nil
+		}
+
+		err = tx.DeleteOldWebhookDeliveries(ctx, database.Now().Add(-WebhookDeliveryAge))
+		if err != nil {
+			return xerrors.Errorf("delete webhook deliveries: %w", err)
+		}
+		err = tx.DeleteOldNotificationMessages(ctx, database.Now().Add(-NotificationMessageAge))
+		if err != nil {
+			return xerrors.Errorf("delete notification messages: %w", err)
+		}
+		if p.interval <= 0 {
+			return nil
+		}
+		err = tx.DeleteArchivedTemplateVersionJobLogs(ctx)
+		if err != nil {
+			return xerrors.Errorf("delete job logs: %w", err)
+		}
+		deleted, err = tx.DeleteUnusedFiles(ctx, database.Now().Add(-UnusedFileAge))
+		if err != nil {
+			return xerrors.Errorf("delete files: %w", err)
+		}
+		err = tx.DeleteOldProvisionerDaemons(ctx, database.Now().Add(-ProvisionerDaemonAge))
+		if err != nil {
+			return xerrors.Errorf("delete provisioner daemons: %w", err)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return nil
+	}
+	if len(deleted) > 0 {
+		p.log.Info(ctx, "deleted unused files", slog.F("count", len(deleted)))
+	}
+	if p.jobLogRetention > 0 {
+		err := p.purgeJobLogs(ctx, database.Now().Add(-p.jobLogRetention))
+		if err != nil {
+			return xerrors.Errorf("purge job logs: %w", err)
+		}
+	}
+	if p.auditLogRetention > 0 {
+		err := p.purgeAuditLogs(ctx, database.Now().Add(-p.auditLogRetention))
+		if err != nil {
+			return xerrors.Errorf("purge audit logs: %w", err)
+		}
+	}
+	for _, file := range deleted {
+		if p.fileStore == nil || file.Storage != p.fileStore.Backend() {
+			continue
+		}
+		// Contents are shared by the files with the same hash.
+		_, err := filestore.DeleteUnused(ctx, p.database, p.fileStore, file.Hash)
+		if err != nil {
+			return xerrors.Errorf("delete contents of file %s: %w", file.ID, err)
+		}
+	}
+	return nil
+}
+
+// purgeJobLogs deletes the logs of old workspace builds that completed before
+// a time in batches, so the deletion of a large backlog doesn't hold a long
+// transaction. Each batch holds the purge lock, like the audit log batches.
+func (p *Purger) purgeJobLogs(ctx context.Context, completedBefore time.Time) error {
+	var purged int64
+	for {
+		var count int64
+		err := p.database.InTx(func(tx database.Store) error {
+			acquired, err := tx.TryAcquireLock(ctx, database.LockIDDBPurge)
+			if err != nil {
+				return xerrors.Errorf("acquire lock: %w", err)
+			}
+			if !acquired {
+				// Another replica is purging.
+				return nil
+			}
+			count, err = tx.DeleteOldWorkspaceBuildJobLogs(ctx, database.DeleteOldWorkspaceBuildJobLogsParams{
+				CompletedBefore: completedBefore,
+				RowLimit:        int32(p.jobLogBatchSize),
+			})
+			if err != nil {
+				return xerrors.Errorf("delete old workspace build job logs: %w", err)
+			}
+			return nil
+		}, nil)
+		if err != nil {
+			return err
+		}
+		purged += count
+		if count < int64(p.jobLogBatchSize) || ctx.Err() != nil {
+			break
+		}
+	}
+	if purged > 0 {
+		p.log.Info(ctx, "purged job logs", slog.F("count", purged))
+	}
+	return nil
+}
+
+// purgeAuditLogs archives and deletes the audit logs before a time in
+// batches, so the deletion of a large backlog doesn't hold a long transaction.
+// Each batch holds the purge lock, so replicas don't archive the same logs.
+func (p *Purger) purgeAuditLogs(ctx context.Context, before time.Time) error {
+	purged := 0
+	for {
+		var count int
+		err := p.database.InTx(func(tx database.Store) error {
+			acquired, err := tx.TryAcquireLock(ctx, database.LockIDDBPurge)
+			if err != nil {
+				return xerrors.Errorf("acquire lock: %w", err)
+			}
+			if !acquired {
+				// Another replica is purging.
+				return nil
+			}
+			logs, err := tx.GetAuditLogsForPurge(ctx, database.GetAuditLogsForPurgeParams{
+				Before:   before,
+				RowLimit: int32(p.auditLogBatchSize),
+			})
+			if err != nil {
+				return xerrors.Errorf("get audit logs: %w", err)
+			}
+			count = len(logs)
+			if count == 0 {
+				return nil
+			}
+			if p.auditLogArchive != nil {
+				err = p.archiveAuditLogs(ctx, tx, logs)
+				if err != nil {
+					return err
+				}
+			}
+			ids := make([]uuid.UUID, 0, len(logs))
+			for _, alog := range logs {
+				ids = append(ids, alog.ID)
+			}
+			err = tx.DeleteAuditLogsByIDs(ctx, ids)
+			if err != nil {
+				return xerrors.Errorf("delete audit logs: %w", err)
+			}
+			return nil
+		}, nil)
+		if err != nil {
+			return err
+		}
+		purged += count
+		if count < p.auditLogBatchSize || ctx.Err() != nil {
+			break
+		}
+	}
+	if purged > 0 {
+		p.log.Info(ctx, "purged audit logs",
+			slog.F("count", purged),
+			slog.F("archived", p.auditLogArchive != nil),
+		)
+	}
+	return nil
+}
+
+// archiveAuditLogs stores the audit logs in the archive store, and records
+// the archive so they can still be exported. The logs are in the order they
+// happened.
+func (p *Purger) archiveAuditLogs(ctx context.Context, tx database.Store, logs []database.AuditLog) error {
+	data, err := audit.ArchiveLogs(logs)
+	if err != nil {
+		return xerrors.Errorf("archive audit logs: %w", err)
+	}
+	hash := sha256.Sum256(data)
+	hashHex := hex.EncodeToString(hash[:])
+	err = p.auditLogArchive.Put(ctx, hashHex, data)
+	if err != nil {
+		return xerrors.Errorf("put audit log archive: %w", err)
+	}
+	_, err = tx.InsertAuditLogArchive(ctx, database.InsertAuditLogArchiveParams{
+		ID:        uuid.New(),
+		CreatedAt: database.Now(),
+		Oldest:    logs[0].Time,
+		Newest:    logs[len(logs)-1].Time,
+		LogCount:  int32(len(logs)),
+		Storage:   p.auditLogArchive.Backend(),
+		Hash:      hashHex,
+		Size:      int64(len(data)),
+	})
+	if err != nil {
+		return xerrors.Errorf("insert audit log archive: %w", err)
+	}
+	return nil
+}
+
+// Close stops purging, and waits for a purge in progress to finish.
+func (p *Purger) Close() error {
+	p.cancel()
+	<-p.done
+	return nil
+}
@@ -0,0 +1,410 @@
+package dbpurge_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbfake"
+	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/coderd/dbpurge"
+	"github.com/coder/coder/coderd/filestore"
+	"github.com/coder/coder/testutil"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestPurge(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	db := dbfake.New()
+	old := database.Now().Add(-dbpurge.UnusedFileAge - time.Hour)
+
+	unusedFile := dbgen.File(t, db, database.File{CreatedAt: old})
+	recentFile := dbgen.File(t, db, database.File{})
+	activeFile := dbgen.File(t, db, database.File{CreatedAt: old})
+	archivedFile := dbgen.File(t, db, database.File{CreatedAt: old})
+
+	activeJob := completedImportJob(ctx, t, db, activeFile.ID)
+	archivedJob := completedImportJob(ctx, t, db, archivedFile.ID)
+
+	activeVersionID := uuid.New()
+	template := dbgen.Template(t, db, database.Template{ActiveVersionID: activeVersionID})
+	_ = dbgen.TemplateVersion(t, db, database.TemplateVersion{
+		ID:         activeVersionID,
+		TemplateID: uuid.NullUUID{UUID: template.ID, Valid: true},
+		JobID:      activeJob.ID,
+	})
+	archivedVersion := dbgen.TemplateVersion(t, db, database.TemplateVersion{
+		TemplateID: uuid.NullUUID{UUID: template.ID, Valid: true},
+		JobID:      archivedJob.ID,
+	})
+	archived, err := db.ArchiveUnusedTemplateVersions(ctx, database.ArchiveUnusedTemplateVersionsParams{
+		UpdatedAt:  database.Now(),
+		TemplateID: template.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{archivedVersion.ID}, archived)
+
+	insertLog(ctx, t, db, activeJob.ID)
+	insertLog(ctx, t, db, archivedJob.ID)
+
+	_ = dbgen.ProvisionerDaemon(t, db, database.ProvisionerDaemon{
+		LastSeenAt: sql.NullTime{Time: database.Now().Add(-dbpurge.ProvisionerDaemonAge - time.Hour), Valid: true},
+	})
+	recentDaemon := dbgen.ProvisionerDaemon(t, db, database.ProvisionerDaemon{})
+
+	purger := dbpurge.New(dbpurge.Options{
+		Database: db,
+		Logger:   slogtest.Make(t, nil),
+		Interval: testutil.IntervalFast,
+	})
+	defer purger.Close()
+
+	require.Eventually(t, func() bool {
+		_, err := db.GetFileByID(ctx, archivedFile.ID)
+		return errors.Is(err, sql.ErrNoRows)
+	}, testutil.WaitShort, testutil.IntervalFast)
+
+	_, err = db.GetFileByID(ctx, unusedFile.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	_, err = db.GetFileByID(ctx, recentFile.ID)
+	require.NoError(t, err)
+	_, err = db.GetFileByID(ctx, activeFile.ID)
+	require.NoError(t, err)
+
+	logs, err := db.GetProvisionerLogsByIDBetween(ctx, database.GetProvisionerLogsByIDBetweenParams{JobID: archivedJob.ID})
+	require.NoError(t, err)
+	require.Empty(t, logs)
+	logs, err = db.GetProvisionerLogsByIDBetween(ctx, database.GetProvisionerLogsByIDBetweenParams{JobID: activeJob.ID})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+
+	// Daemons are deleted after files in the same purge.
+	require.Eventually(t, func() bool {
+		daemons, err := db.GetProvisionerDaemons(ctx)
+		return err == nil && len(daemons) == 1 && daemons[0].ID == recentDaemon.ID
+	}, testutil.WaitShort, testutil.IntervalFast)
+}
+
+func TestPurgeFileStore(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	db := dbfake.New()
+	store, err := filestore.NewFS(t.TempDir())
+	require.NoError(t, err)
+	old := database.Now().Add(-dbpurge.UnusedFileAge - time.Hour)
+
+	insert := func(data string, createdAt time.Time) database.File {
+		hash := sha256.Sum256([]byte(data))
+		file, err := filestore.Insert(ctx, db, store, database.InsertFileParams{
+			ID:        uuid.New(),
+			Hash:      hex.EncodeToString(hash[:]),
+			CreatedAt: createdAt,
+			CreatedBy: uuid.New(),
+			Mimetype:  "application/x-tar",
+			Data:      []byte(data),
+		})
+		require.NoError(t, err)
+		return file
+	}
+	unusedFile := insert("unused", old)
+	// Contents are kept while another file has the same hash.
+	sharedFile := insert("shared", old)
+	recentFile := insert("shared", database.Now())
+
+	purger := dbpurge.New(dbpurge.Options{
+		Database:  db,
+		FileStore: store,
+		Logger:    slogtest.Make(t, nil),
+		Interval:  testutil.IntervalFast,
+	})
+	defer purger.Close()
+
+	require.Eventually(t, func() bool {
+		_, err := store.Get(ctx, unusedFile.Hash)
+		return errors.Is(err, filestore.ErrNotFound)
+	}, testutil.WaitShort, testutil.IntervalFast)
+
+	_, err = db.GetFileByID(ctx, sharedFile.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+	data, err := filestore.Read(ctx, store, recentFile)
+	require.NoError(t, err)
+	require.Equal(t, "shared", string(data))
+}
+
+func TestPurgeLocked(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	db := &lockedStore{Store: dbfake.New()}
+	unusedFile := dbgen.File(t, db, database.File{CreatedAt: database.Now().Add(-dbpurge.UnusedFileAge - time.Hour)})
+
+	purger := dbpurge.New(dbpurge.Options{
+		Database: db,
+		Logger:   slogtest.Make(t, nil),
+		Interval: testutil.IntervalFast,
+	})
+	defer purger.Close()
+
+	// Another replica holds the lock, so nothing is purged.
+	require.Eventually(t, func() bool {
+		return db.attempts.Load() >= 2
+	}, testutil.WaitShort, testutil.IntervalFast)
+	_, err := db.GetFileByID(ctx, unusedFile.ID)
+	require.NoError(t, err)
+}
+
+// lockedStore is a store whose locks are always held by another replica.
+type lockedStore struct {
+	database.Store
+	attempts atomic.Int64
+}
+
+func (s *lockedStore) InTx(fn func(database.Store) error, opts *sql.TxOptions) error {
+	return s.Store.InTx(func(tx database.Store) error {
+		return fn(&lockedTx{Store: tx, parent: s})
+	}, opts)
+}
+
+type lockedTx struct {
+	database.Store
+	parent *lockedStore
+}
+
+func (tx *lockedTx) TryAcquireLock(context.Context, int64) (bool, error) {
+	tx.parent.attempts.Add(1)
+	return false, nil
+}
+
+func TestPurgeJobLogRetention(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	db := dbfake.New()
+	retention := 24 * time.Hour
+	old := database.Now().Add(-retention - time.Hour)
+
+	workspaceID := uuid.New()
+	build := func(number int32, completedAt time.Time) database.ProvisionerJob {
+		job := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+			Type: database.ProvisionerJobTypeWorkspaceBuild,
+		})
+		err := db.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
+			ID:          job.ID,
+			UpdatedAt:   completedAt,
+			CompletedAt: sql.NullTime{Time: completedAt, Valid: true},
+		})
+		require.NoError(t, err)
+		_ = dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+			WorkspaceID: workspaceID,
+			BuildNumber: number,
+			JobID:       job.ID,
+		})
+		insertLog(ctx, t, db, job.ID)
+		return job
+	}
+	oldJob := build(1, old)
+	// The logs are deleted in several batches.
+	for i := 0; i < 4; i++ {
+		insertLog(ctx, t, db, oldJob.ID)
+	}
+	recentJob := build(2, database.Now())
+	// The logs of the latest build are kept regardless of their age.
+	latestJob := build(3, old)
+
+	purger := dbpurge.New(dbpurge.Options{
+		Database:        db,
+		Logger:          slogtest.Make(t, nil),
+		JobLogRetention: retention,
+		JobLogBatchSize: 2,
+	})
+	defer purger.Close()
+
+	require.Eventually(t, func() bool {
+		logs, err := db.GetProvisionerLogsByIDBetween(ctx, database.GetProvisionerLogsByIDBetweenParams{JobID: oldJob.ID})
+		return err == nil && len(logs) == 0
+	}, testutil.WaitShort, testutil.IntervalFast)
+
+	for _, jobID := range []uuid.UUID{recentJob.ID, latestJob.ID} {
+		logs, err := db.GetProvisionerLogsByIDBetween(ctx, database.GetProvisionerLogsByIDBetweenParams{JobID: jobID})
+		require.NoError(t, err)
+		require.Len(t, logs, 1)
+	}
+}
+
+func TestPurgeAuditLogRetention(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	db := dbfake.New()
+	store, err := filestore.NewFS(t.TempDir())
+	require.NoError(t, err)
+	retention := 24 * time.Hour
+	old := database.Now().Add(-retention - time.Hour)
+
+	oldLogs := make([]database.AuditLog, 0, 5)
+	for i := 0; i < 5; i++ {
+		oldLogs = append(oldLogs, dbgen.AuditLog(t, db, database.AuditLog{
+			Time: old.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+	recentLog := dbgen.AuditLog(t, db, database.AuditLog{})
+
+	purger := dbpurge.New(dbpurge.Options{
+		Database:          db,
+		Logger:            slogtest.Make(t, nil),
+		AuditLogRetention: retention,
+		AuditLogArchive:   store,
+		AuditLogBatchSize: 2,
+	})
+	defer purger.Close()
+
+	require.Eventually(t, func() bool {
+		logs, err := db.GetAuditLogsByTimeRange(ctx, database.GetAuditLogsByTimeRangeParams{
+			Until:    database.Now().Add(time.Hour),
+			RowLimit: 100,
+		})
+		return err == nil && len(logs) == 1 && logs[0].ID == recentLog.ID
+	}, testutil.WaitShort, testutil.IntervalFast)
+
+	// The old logs are archived in batches, in the order they happened.
+	archives, err := db.GetAuditLogArchivesByTimeRange(ctx, database.GetAuditLogArchivesByTimeRangeParams{
+		Until: database.Now(),
+	})
+	require.NoError(t, err)
+	require.Len(t, archives, 3)
+	archived := make([]uuid.UUID, 0, len(oldLogs))
+	for _, archive := range archives {
+		require.Equal(t, filestore.BackendFS, archive.Storage)
+		data, err := store.Get(ctx, archive.Hash)
+		require.NoError(t, err)
+		require.EqualValues(t, len(data), archive.Size)
+		count := 0
+		err = audit.ReadArchive(bytes.NewReader(data), func(_ []byte, log audit.ExportedLog) error {
+			require.False(t, log.Time.Before(archive.Oldest))
+			require.False(t, log.Time.After(archive.Newest))
+			archived = append(archived, log.ID)
+			count++
+			return nil
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, count, archive.LogCount)
+	}
+	for i, alog := range oldLogs {
+		require.Equal(t, alog.ID, archived[i])
+	}
+}
+
+func completedImportJob(ctx context.Context, t *testing.T, db database.Store, fileID uuid.UUID) database.ProvisionerJob {
+	t.Helper()
+	job := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+		FileID: fileID,
+		Type:   database.ProvisionerJobTypeTemplateVersionImport,
+	})
+	err := db.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
+		ID:          job.ID,
+		UpdatedAt:   database.Now(),
+		CompletedAt: sql.NullTime{Time: database.Now(), Valid: true},
+	})
+	require.NoError(t, err)
+	return job
+}
+
+func insertLog(ctx context.Context, t *testing.T, db database.Store, jobID uuid.UUID) {
+	t.Helper()
+	_, err := db.InsertProvisionerJobLogs(ctx, database.InsertProvisionerJobLogsParams{
+		JobID:     jobID,
+		CreatedAt: []time.Time{database.Now()},
+		Source:    []database.LogSource{database.LogSourceProvisioner},
+		Level:     []database.LogLevel{database.LogLevelInfo},
+		Stage:     []string{"Planning"},
+		Output:    []string{"Hello"},
+	})
+	require.NoError(t, err)
+}
+
+func TestPurgeWebhookDeliveriesAndNotifications(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	db := dbfake.New()
+	webhook := dbgen.Webhook(t, db, database.Webhook{})
+	_ = dbgen.WebhookDelivery(t, db, database.WebhookDelivery{
+		WebhookID: webhook.ID,
+		CreatedAt: database.Now().Add(-dbpurge.WebhookDeliveryAge - time.Hour),
+	})
+	recent := dbgen.WebhookDelivery(t, db, database.WebhookDelivery{WebhookID: webhook.ID})
+	insertMessage := func(createdAt time.Time) database.NotificationMessage {
+		message, err := db.InsertNotificationMessage(ctx, database.InsertNotificationMessageParams{
+			ID:        uuid.New(),
+			UserID:    uuid.New(),
+			Kind:      "workspace_autostop",
+			DedupeKey: uuid.NewString(),
+			CreatedAt: createdAt,
+		})
+		require.NoError(t, err)
+		return message
+	}
+	oldMessage := insertMessage(database.Now().Add(-dbpurge.NotificationMessageAge - time.Hour))
+	recentMessage := insertMessage(database.Now())
+
+	// They're purged even when nothing else is.
+	purger := dbpurge.New(dbpurge.Options{
+		Database: db,
+		Logger:   slogtest.Make(t, nil),
+	})
+	defer purger.Close()
+
+	require.Eventually(t, func() bool {
+		deliveries, err := db.GetWebhookDeliveriesByWebhookID(ctx, database.GetWebhookDeliveriesByWebhookIDParams{
+			WebhookID: webhook.ID,
+		})
+		return err == nil && len(deliveries) == 1 && deliveries[0].ID == recent.ID
+	}, testutil.WaitShort, testutil.IntervalFast)
+
+	// The dedupe key of a purged message can be claimed again.
+	require.Eventually(t, func() bool {
+		_, err := db.InsertNotificationMessage(ctx, database.InsertNotificationMessageParams{
+			ID:        uuid.New(),
+			DedupeKey: oldMessage.DedupeKey,
+			CreatedAt: database.Now(),
+		})
+		return err == nil
+	}, testutil.WaitShort, testutil.IntervalFast)
+	_, err := db.InsertNotificationMessage(ctx, database.InsertNotificationMessageParams{
+		ID:        uuid.New(),
+		DedupeKey: recentMessage.DedupeKey,
+		CreatedAt: database.Now(),
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
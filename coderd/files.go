@@ -13,6 +13,7 @@ import (
 	"github.com/google/uuid"
 
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/filestore"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
 	"github.com/coder/coder/coderd/rbac"
@@ -85,7 +86,7 @@ func (api *API) postFile(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	id := uuid.New()
-	file, err = api.Database.InsertFile(ctx, database.InsertFileParams{
+	file, err = filestore.Insert(ctx, api.Database, api.FileStore, database.InsertFileParams{
 		ID:        id,
 		Hash:      hash,
 		CreatedBy: apiKey.UserID,
@@ -151,7 +152,16 @@ func (api *API) fileByID(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	data, err := filestore.Read(ctx, api.FileStore, file)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error reading file.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
 	rw.Header().Set("Content-Type", file.Mimetype)
 	rw.WriteHeader(http.StatusOK)
-	_, _ = rw.Write(file.Data)
+	_, _ = rw.Write(data)
 }
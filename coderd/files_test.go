@@ -10,6 +10,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/filestore"
+	"github.com/coder/coder/coderd/filestore/filestoretest"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/testutil"
 )
@@ -88,3 +90,33 @@ func TestDownload(t *testing.T) {
 		require.Equal(t, codersdk.ContentTypeTar, contentType)
 	})
 }
+
+func TestFileStore(t *testing.T) {
+	t.Parallel()
+
+	s3 := filestoretest.NewS3(t)
+	store, err := filestore.NewS3(s3.Options)
+	require.NoError(t, err)
+	client := coderdtest.New(t, &coderdtest.Options{
+		IncludeProvisionerDaemon: true,
+		FileStore:                store,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	data := make([]byte, 1024)
+	resp, err := client.Upload(ctx, codersdk.ContentTypeTar, bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, s3.Objects(), 1)
+	downloaded, _, err := client.Download(ctx, resp.ID)
+	require.NoError(t, err)
+	require.Equal(t, data, downloaded)
+
+	// Provisioners read template archives from the store.
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+	version = coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	require.Equal(t, codersdk.ProvisionerJobSucceeded, version.Job.Status)
+	require.Len(t, s3.Objects(), 2)
+}
@@ -0,0 +1,183 @@
+// Package filestore stores the contents of files, such as template source
+// archives, outside of the database so they don't bloat it and its backups.
+package filestore
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+)
+
+// Backends the contents of files can be stored in. The backend is recorded on
+// each file, so files can still be read after the deployment switches to
+// another backend until they're migrated.
+const (
+	BackendDatabase = "database"
+	BackendFS       = "fs"
+	BackendS3       = "s3"
+)
+
+// ErrNotFound is returned when no contents are stored for a hash.
+var ErrNotFound = xerrors.New("file contents not found")
+
+// Store stores the contents of files by their SHA256 hash, so contents that
+// were uploaded several times, or by several users, are only stored once.
+type Store interface {
+	// Backend is the name of the backend, which is recorded on the files
+	// stored in it.
+	Backend() string
+	// Put stores the contents, unless contents with the hash are already
+	// stored.
+	Put(ctx context.Context, hash string, data []byte) error
+	// Get returns the contents, or ErrNotFound.
+	Get(ctx context.Context, hash string) ([]byte, error)
+	// Delete deletes the contents. It's not an error if there are none.
+	Delete(ctx context.Context, hash string) error
+}
+
+// Insert inserts a file, storing its contents in the store. The contents are
+// stored in the database if the store is nil.
+func Insert(ctx context.Context, db database.Store, store Store, arg database.InsertFileParams) (database.File, error) {
+	arg.Storage = BackendDatabase
+	if store == nil {
+		return db.InsertFile(ctx, arg)
+	}
+	var file database.File
+	err := db.InTx(func(tx database.Store) error {
+		// The purge deletes the contents of a hash once no file refers to
+		// them while holding the lock, so they can't be deleted between
+		// storing them and inserting the file.
+		err := tx.AcquireFileHashLock(ctx, arg.Hash)
+		if err != nil {
+			return xerrors.Errorf("acquire file hash lock: %w", err)
+		}
+		// The contents are stored first, so a file never refers to contents
+		// that don't exist.
+		err = store.Put(ctx, arg.Hash, arg.Data)
+		if err != nil {
+			return xerrors.Errorf("put file contents: %w", err)
+		}
+		arg.Storage = store.Backend()
+		arg.Data = []byte{}
+		file, err = tx.InsertFile(ctx, arg)
+		return err
+	}, nil)
+	if err != nil {
+		return database.File{}, err
+	}
+	return file, nil
+}
+
+// DeleteUnused deletes the contents of a hash from the store unless a file
+// still refers to them, and returns whether they were deleted.
+func DeleteUnused(ctx context.Context, db database.Store, store Store, hash string) (bool, error) {
+	var deleted bool
+	err := db.InTx(func(tx database.Store) error {
+		// Holding the lock keeps files with the hash from being inserted
+		// until the contents are deleted.
+		err := tx.AcquireFileHashLock(ctx, hash)
+		if err != nil {
+			return xerrors.Errorf("acquire file hash lock: %w", err)
+		}
+		inUse, err := tx.GetFileHashInUse(ctx, hash)
+		if err != nil {
+			return xerrors.Errorf("get file hash in use: %w", err)
+		}
+		if inUse {
+			return nil
+		}
+		err = store.Delete(ctx, hash)
+		if err != nil {
+			return xerrors.Errorf("delete file contents: %w", err)
+		}
+		deleted = true
+		return nil
+	}, nil)
+	return deleted, err
+}
+
+// InsertArchive returns the file containing the template archive, inserting
+// it if the user hasn't uploaded the same archive before.
+func InsertArchive(ctx context.Context, db database.Store, store Store, userID uuid.UUID, tar []byte) (database.File, error) {
+	hashBytes := sha256.Sum256(tar)
+	hash := hex.EncodeToString(hashBytes[:])
+	file, err := db.GetFileByHashAndCreator(ctx, database.GetFileByHashAndCreatorParams{
+		Hash:      hash,
+		CreatedBy: userID,
+	})
+	if err == nil {
+		return file, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return database.File{}, xerrors.Errorf("get file by hash: %w", err)
+	}
+	file, err = Insert(ctx, db, store, database.InsertFileParams{
+		ID:        uuid.New(),
+		Hash:      hash,
+		CreatedBy: userID,
+		CreatedAt: database.Now(),
+		Mimetype:  "application/x-tar",
+		Data:      tar,
+	})
+	if err != nil {
+		return database.File{}, xerrors.Errorf("insert file: %w", err)
+	}
+	return file, nil
+}
+
+// Read returns the contents of a file from the backend they're stored in.
+func Read(ctx context.Context, store Store, file database.File) ([]byte, error) {
+	if file.Storage == BackendDatabase {
+		return file.Data, nil
+	}
+	if store == nil || store.Backend() != file.Storage {
+		return nil, xerrors.Errorf("file %s is stored in the %q backend, which isn't configured", file.ID, file.Storage)
+	}
+	data, err := store.Get(ctx, file.Hash)
+	if err != nil {
+		return nil, xerrors.Errorf("get file contents: %w", err)
+	}
+	return data, nil
+}
+
+// Migrate moves the contents of the files stored in the database to the
+// store, and returns how many files were moved. Files are moved one at a time,
+// so it can run while the deployment is serving requests.
+func Migrate(ctx context.Context, db database.Store, store Store) (int, error) {
+	ids, err := db.GetFileIDsByStorage(ctx, BackendDatabase)
+	if err != nil {
+		return 0, xerrors.Errorf("get files: %w", err)
+	}
+	moved := 0
+	for _, id := range ids {
+		file, err := db.GetFileByID(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			// The file was deleted while migrating.
+			continue
+		}
+		if err != nil {
+			return moved, xerrors.Errorf("get file %s: %w", id, err)
+		}
+		err = store.Put(ctx, file.Hash, file.Data)
+		if err != nil {
+			return moved, xerrors.Errorf("put contents of file %s: %w", id, err)
+		}
+		err = db.UpdateFileStorageByID(ctx, database.UpdateFileStorageByIDParams{
+			ID:      id,
+			Storage: store.Backend(),
+			Data:    []byte{},
+		})
+		if err != nil {
+			return moved, xerrors.Errorf("update storage of file %s: %w", id, err)
+		}
+		moved++
+	}
+	return moved, nil
+}
@@ -0,0 +1,223 @@
+package filestore_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbfake"
+	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/coderd/filestore"
+	"github.com/coder/coder/coderd/filestore/filestoretest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestStores(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name  string
+		store func(t *testing.T) filestore.Store
+	}{{
+		name: "FS",
+		store: func(t *testing.T) filestore.Store {
+			store, err := filestore.NewFS(t.TempDir())
+			require.NoError(t, err)
+			return store
+		},
+	}, {
+		name: "S3",
+		store: func(t *testing.T) filestore.Store {
+			store, err := filestore.NewS3(filestoretest.NewS3(t).Options)
+			require.NoError(t, err)
+			return store
+		},
+	}} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+			defer cancel()
+
+			store := tc.store(t)
+			data := []byte("template")
+			hash := hashOf(data)
+
+			_, err := store.Get(ctx, hash)
+			require.ErrorIs(t, err, filestore.ErrNotFound)
+
+			err = store.Put(ctx, hash, data)
+			require.NoError(t, err)
+			// Contents with the same hash are only stored once.
+			err = store.Put(ctx, hash, data)
+			require.NoError(t, err)
+			got, err := store.Get(ctx, hash)
+			require.NoError(t, err)
+			require.Equal(t, data, got)
+
+			err = store.Delete(ctx, hash)
+			require.NoError(t, err)
+			_, err = store.Get(ctx, hash)
+			require.ErrorIs(t, err, filestore.ErrNotFound)
+			err = store.Delete(ctx, hash)
+			require.NoError(t, err)
+
+			err = store.Put(ctx, "../../etc/passwd", data)
+			require.ErrorContains(t, err, "invalid hash")
+		})
+	}
+}
+
+func TestInsertAndRead(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	db := dbfake.New()
+	s3 := filestoretest.NewS3(t)
+	store, err := filestore.NewS3(s3.Options)
+	require.NoError(t, err)
+
+	data := []byte("template")
+	hash := hashOf(data)
+	// Two users upload the same contents.
+	for i := 0; i < 2; i++ {
+		file, err := filestore.Insert(ctx, db, store, database.InsertFileParams{
+			ID:        uuid.New(),
+			Hash:      hash,
+			CreatedAt: database.Now(),
+			CreatedBy: uuid.New(),
+			Mimetype:  "application/x-tar",
+			Data:      data,
+		})
+		require.NoError(t, err)
+		require.Equal(t, filestore.BackendS3, file.Storage)
+		require.Empty(t, file.Data)
+
+		got, err := filestore.Read(ctx, store, file)
+		require.NoError(t, err)
+		require.Equal(t, data, got)
+
+		// Files can't be read once their backend isn't configured.
+		_, err = filestore.Read(ctx, nil, file)
+		require.ErrorContains(t, err, "isn't configured")
+	}
+	require.Equal(t, map[string][]byte{hash: data}, s3.Objects())
+
+	file, err := filestore.Insert(ctx, db, nil, database.InsertFileParams{
+		ID:        uuid.New(),
+		Hash:      hash,
+		CreatedAt: database.Now(),
+		CreatedBy: uuid.New(),
+		Mimetype:  "application/x-tar",
+		Data:      data,
+	})
+	require.NoError(t, err)
+	require.Equal(t, filestore.BackendDatabase, file.Storage)
+	require.Equal(t, data, file.Data)
+	got, err := filestore.Read(ctx, store, file)
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+}
+
+func TestInsertArchive(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	db := dbfake.New()
+	store, err := filestore.NewFS(t.TempDir())
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	data := []byte("template")
+	file, err := filestore.InsertArchive(ctx, db, store, userID, data)
+	require.NoError(t, err)
+	require.Equal(t, hashOf(data), file.Hash)
+	require.Equal(t, "application/x-tar", file.Mimetype)
+
+	// The same archive of the same user is only inserted once.
+	again, err := filestore.InsertArchive(ctx, db, store, userID, data)
+	require.NoError(t, err)
+	require.Equal(t, file.ID, again.ID)
+	other, err := filestore.InsertArchive(ctx, db, store, uuid.New(), data)
+	require.NoError(t, err)
+	require.NotEqual(t, file.ID, other.ID)
+}
+
+func TestDeleteUnused(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	db := dbfake.New()
+	store, err := filestore.NewFS(t.TempDir())
+	require.NoError(t, err)
+
+	data := []byte("template")
+	file, err := filestore.InsertArchive(ctx, db, store, uuid.New(), data)
+	require.NoError(t, err)
+
+	// Contents aren't deleted while a file refers to them.
+	deleted, err := filestore.DeleteUnused(ctx, db, store, file.Hash)
+	require.NoError(t, err)
+	require.False(t, deleted)
+	_, err = store.Get(ctx, file.Hash)
+	require.NoError(t, err)
+
+	_, err = db.DeleteUnusedFiles(ctx, database.Now().Add(time.Hour))
+	require.NoError(t, err)
+	deleted, err = filestore.DeleteUnused(ctx, db, store, file.Hash)
+	require.NoError(t, err)
+	require.True(t, deleted)
+	_, err = store.Get(ctx, file.Hash)
+	require.ErrorIs(t, err, filestore.ErrNotFound)
+}
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitShort)
+	defer cancel()
+
+	db := dbfake.New()
+	store, err := filestore.NewFS(t.TempDir())
+	require.NoError(t, err)
+
+	first := dbgen.File(t, db, database.File{Hash: hashOf([]byte("first")), Data: []byte("first")})
+	second := dbgen.File(t, db, database.File{Hash: hashOf([]byte("second")), Data: []byte("second")})
+
+	moved, err := filestore.Migrate(ctx, db, store)
+	require.NoError(t, err)
+	require.Equal(t, 2, moved)
+
+	for _, want := range []database.File{first, second} {
+		file, err := db.GetFileByID(ctx, want.ID)
+		require.NoError(t, err)
+		require.Equal(t, filestore.BackendFS, file.Storage)
+		require.Empty(t, file.Data)
+		got, err := filestore.Read(ctx, store, file)
+		require.NoError(t, err)
+		require.Equal(t, want.Data, got)
+	}
+
+	// Files that were already moved are skipped.
+	moved, err = filestore.Migrate(ctx, db, store)
+	require.NoError(t, err)
+	require.Zero(t, moved)
+}
+
+func hashOf(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
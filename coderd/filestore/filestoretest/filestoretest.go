@@ -0,0 +1,105 @@
+// Package filestoretest provides an in-memory stand-in for an S3 compatible
+// API, such as MinIO, to test storing files in S3 against.
+package filestoretest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/coder/coder/coderd/filestore"
+)
+
+const (
+	bucket          = "coder"
+	accessKeyID     = "access-key-id"
+	secretAccessKey = "secret-access-key"
+)
+
+// S3 serves a single bucket of objects from memory. Requests must be signed
+// with its credentials, and their payload must match the signed hash.
+type S3 struct {
+	// Options connect a store to the bucket.
+	Options filestore.S3Options
+
+	mutex   sync.Mutex
+	objects map[string][]byte
+}
+
+// NewS3 starts an S3 stand-in that's closed when the test ends.
+func NewS3(t testing.TB) *S3 {
+	t.Helper()
+	s := &S3{
+		objects: make(map[string][]byte),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(srv.Close)
+	s.Options = filestore.S3Options{
+		Endpoint:        srv.URL,
+		Bucket:          bucket,
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+	}
+	return s
+}
+
+// Objects returns the contents of the bucket by object key.
+func (s *S3) Objects() map[string][]byte {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	objects := make(map[string][]byte, len(s.objects))
+	for key, data := range s.objects {
+		objects[key] = data
+	}
+	return objects
+}
+
+func (s *S3) serveHTTP(rw http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential="+accessKeyID+"/") {
+		http.Error(rw, "AccessDenied", http.StatusForbidden)
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+bucket+"/")
+	if !ok || key == "" {
+		http.Error(rw, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(rw, err.Error(), http.StatusBadRequest)
+		return
+	}
+	payloadHash := sha256.Sum256(body)
+	if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(payloadHash[:]) {
+		http.Error(rw, "XAmzContentSHA256Mismatch", http.StatusBadRequest)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	switch r.Method {
+	case http.MethodHead, http.MethodGet:
+		data, ok := s.objects[key]
+		if !ok {
+			http.Error(rw, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		rw.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_, _ = rw.Write(data)
+		}
+	case http.MethodPut:
+		s.objects[key] = body
+		rw.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		delete(s.objects, key)
+		rw.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(rw, "MethodNotAllowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,115 @@
+package filestore
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/xerrors"
+)
+
+// NewFS returns a store that keeps contents in a directory on the local
+// filesystem. The directory must be shared by all replicas of the deployment.
+func NewFS(dir string) (Store, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, xerrors.Errorf("create directory: %w", err)
+	}
+	return &fsStore{dir: dir}, nil
+}
+
+type fsStore struct {
+	dir string
+}
+
+func (*fsStore) Backend() string {
+	return BackendFS
+}
+
+func (s *fsStore) Put(_ context.Context, hash string, data []byte) error {
+	path, err := s.path(hash)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stat(path)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return xerrors.Errorf("stat: %w", err)
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0o700)
+	if err != nil {
+		return xerrors.Errorf("create directory: %w", err)
+	}
+	// Write to a temporary file that's renamed into place, so contents are
+	// never read partially written.
+	tmp, err := os.CreateTemp(filepath.Dir(path), hash+".*.tmp")
+	if err != nil {
+		return xerrors.Errorf("create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if err != nil {
+		_ = tmp.Close()
+		return xerrors.Errorf("write: %w", err)
+	}
+	err = tmp.Close()
+	if err != nil {
+		return xerrors.Errorf("close: %w", err)
+	}
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return xerrors.Errorf("rename: %w", err)
+	}
+	return nil
+}
+
+func (s *fsStore) Get(_ context.Context, hash string) ([]byte, error) {
+	path, err := s.path(hash)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("read: %w", err)
+	}
+	return data, nil
+}
+
+func (s *fsStore) Delete(_ context.Context, hash string) error {
+	path, err := s.path(hash)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return xerrors.Errorf("remove: %w", err)
+	}
+	return nil
+}
+
+// path returns the path of the contents, which are spread across
+// subdirectories by the first two characters of their hash.
+func (s *fsStore) path(hash string) (string, error) {
+	err := validateHash(hash)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, hash[:2], hash), nil
+}
+
+// validateHash ensures the hash is a hex encoded SHA256 hash, so it's safe to
+// use in paths.
+func validateHash(hash string) error {
+	decoded, err := hex.DecodeString(hash)
+	if err != nil || len(decoded) != 32 {
+		return xerrors.Errorf("invalid hash %q", hash)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package filestore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// S3Options configure a store that keeps contents in an S3 bucket.
+type S3Options struct {
+	// Endpoint is the URL of the S3 compatible API, e.g. a MinIO server.
+	// Defaults to the AWS endpoint of the region.
+	Endpoint string
+	// Region defaults to us-east-1.
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// NewS3 returns a store that keeps contents in an S3 bucket, named by their
+// hash. Buckets are addressed by path, which all S3 compatible APIs support.
+func NewS3(opts S3Options) (Store, error) {
+	if opts.Bucket == "" {
+		return nil, xerrors.New("a bucket is required")
+	}
+	if opts.Region == "" {
+		opts.Region = "us-east-1"
+	}
+	if opts.Endpoint == "" {
+		opts.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", opts.Region)
+	}
+	endpoint, err := url.Parse(opts.Endpoint)
+	if err != nil {
+		return nil, xerrors.Errorf("parse endpoint: %w", err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return nil, xerrors.Errorf("endpoint %q must be an http(s) URL", opts.Endpoint)
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	return &s3Store{
+		opts:     opts,
+		endpoint: endpoint,
+		now:      time.Now,
+	}, nil
+}
+
+type s3Store struct {
+	opts     S3Options
+	endpoint *url.URL
+	now      func() time.Time
+}
+
+func (*s3Store) Backend() string {
+	return BackendS3
+}
+
+func (s *s3Store) Put(ctx context.Context, hash string, data []byte) error {
+	res, err := s.do(ctx, http.MethodHead, hash, nil)
+	if err != nil {
+		return err
+	}
+	_ = res.Body.Close()
+	if res.StatusCode == http.StatusOK {
+		return nil
+	}
+	if res.StatusCode != http.StatusNotFound {
+		return xerrors.Errorf("head object: unexpected status %d", res.StatusCode)
+	}
+
+	res, err = s.do(ctx, http.MethodPut, hash, data)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return s3Error("put object", res)
+	}
+	return nil
+}
+
+func (s *s3Store) Get(ctx context.Context, hash string) ([]byte, error) {
+	res, err := s.do(ctx, http.MethodGet, hash, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, s3Error("get object", res)
+	}
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, xerrors.Errorf("read object: %w", err)
+	}
+	return data, nil
+}
+
+func (s *s3Store) Delete(ctx context.Context, hash string) error {
+	res, err := s.do(ctx, http.MethodDelete, hash, nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	// S3 returns 204 whether or not the object existed, but other
+	// implementations may return 404.
+	if res.StatusCode != http.StatusNoContent && res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNotFound {
+		return s3Error("delete object", res)
+	}
+	return nil
+}
+
+func (s *s3Store) do(ctx context.Context, method, hash string, body []byte) (*http.Response, error) {
+	err := validateHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	objectURL := *s.endpoint
+	objectURL.Path = strings.TrimSuffix(objectURL.Path, "/") + "/" + s.opts.Bucket + "/" + hash
+	req, err := http.NewRequestWithContext(ctx, method, objectURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, xerrors.Errorf("create request: %w", err)
+	}
+	req.ContentLength = int64(len(body))
+	payloadHash := sha256.Sum256(body)
+	s.sign(req, hex.EncodeToString(payloadHash[:]), s.now())
+	res, err := s.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, xerrors.Errorf("%s object: %w", strings.ToLower(method), err)
+	}
+	return res, nil
+}
+
+// sign signs the request with AWS Signature Version 4. All headers of the
+// request are signed.
+// See: https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func (s *s3Store) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		trimmed := make([]string, 0, len(values))
+		for _, value := range values {
+			trimmed = append(trimmed, strings.Join(strings.Fields(value), " "))
+		}
+		headers[strings.ToLower(name)] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		_, _ = canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	scope := date + "/" + s.opts.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalRequestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.opts.SecretAccessKey), date)
+	key = hmacSHA256(key, s.opts.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.opts.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func s3Error(action string, res *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+	return xerrors.Errorf("%s: unexpected status %d: %s", action, res.StatusCode, strings.TrimSpace(string(body)))
+}
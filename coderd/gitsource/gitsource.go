@@ -0,0 +1,229 @@
+// Package gitsource creates template archives from git repositories, so
+// template versions can be created without uploading a local directory.
+package gitsource
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/provisionersdk"
+)
+
+// refRegex matches branch and tag names and commit SHAs. It's stricter than
+// git itself so a ref can never be mistaken for a flag or a refspec.
+var refRegex = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._/-]*$`)
+
+// Options describe the repository to create an archive from.
+type Options struct {
+	URL string
+	// Ref is a branch, tag or commit SHA. The default branch is used when
+	// it's empty.
+	Ref string
+	// Directory is the path of the template in the repository. The root of
+	// the repository is used when it's empty.
+	Directory string
+	// Username and Password authenticate to the repository over HTTP, e.g.
+	// with a git auth token. Anonymous access is used when both are empty.
+	Username string
+	Password string
+	// Limit is the maximum size of the archive in bytes.
+	Limit int64
+}
+
+// Archive is a template archived from a git repository.
+type Archive struct {
+	Data []byte
+	// CommitSHA is the commit the ref resolved to.
+	CommitSHA string
+}
+
+// Validate returns an error if the options can't be used to clone a
+// repository. Only HTTP(S) repositories are supported, so coderd can't be
+// made to read repositories from its own filesystem or with its own SSH keys.
+func (o Options) Validate() error {
+	u, err := url.Parse(o.URL)
+	if err != nil {
+		return xerrors.Errorf("parse url: %w", err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return xerrors.Errorf("url scheme must be https or http, got %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return xerrors.New("url must have a host")
+	}
+	if u.User != nil {
+		return xerrors.New("url must not contain credentials, use git auth instead")
+	}
+	if o.Ref != "" && !refRegex.MatchString(o.Ref) {
+		return xerrors.Errorf("invalid ref %q", o.Ref)
+	}
+	if _, err := cleanDirectory(o.Directory); err != nil {
+		return err
+	}
+	return nil
+}
+
+// cleanDirectory returns the directory relative to the root of the
+// repository, and errors if it would escape it.
+func cleanDirectory(directory string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(strings.TrimPrefix(directory, "/")))
+	if clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", xerrors.Errorf("directory %q is outside of the repository", directory)
+	}
+	return clean, nil
+}
+
+// Clone fetches the ref of the repository and archives the template in its
+// directory. The git binary must be installed.
+func Clone(ctx context.Context, opts Options) (Archive, error) {
+	err := opts.Validate()
+	if err != nil {
+		return Archive{}, err
+	}
+	ref := opts.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	root, err := os.MkdirTemp("", "coder-git-source-")
+	if err != nil {
+		return Archive{}, xerrors.Errorf("create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(root)
+
+	_, err = git(ctx, root, opts, "init", "--quiet")
+	if err != nil {
+		return Archive{}, err
+	}
+	_, err = git(ctx, root, opts, "fetch", "--quiet", "--no-tags", "--depth=1", opts.URL, ref)
+	if err != nil {
+		// Dumb HTTP servers can't serve shallow fetches, so fall back to
+		// fetching the whole history.
+		_, err = git(ctx, root, opts, "fetch", "--quiet", "--no-tags", opts.URL, ref)
+		if err != nil {
+			return Archive{}, err
+		}
+	}
+	_, err = git(ctx, root, opts, "checkout", "--quiet", "--detach", "FETCH_HEAD")
+	if err != nil {
+		return Archive{}, err
+	}
+	sha, err := git(ctx, root, opts, "rev-parse", "HEAD")
+	if err != nil {
+		return Archive{}, err
+	}
+
+	directory, err := cleanDirectory(opts.Directory)
+	if err != nil {
+		return Archive{}, err
+	}
+	// The directory may be a symlink, which must not lead outside of the
+	// clone either.
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return Archive{}, xerrors.Errorf("resolve repository root: %w", err)
+	}
+	templateDir, err := filepath.EvalSymlinks(filepath.Join(root, directory))
+	if err != nil {
+		return Archive{}, xerrors.Errorf("directory %q not found in the repository", opts.Directory)
+	}
+	rel, err := filepath.Rel(resolvedRoot, templateDir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return Archive{}, xerrors.Errorf("directory %q is outside of the repository", opts.Directory)
+	}
+
+	var buf bytes.Buffer
+	err = provisionersdk.Tar(&buf, templateDir, opts.Limit)
+	if err != nil {
+		return Archive{}, xerrors.Errorf("archive %q: %w", opts.Directory, err)
+	}
+	return Archive{
+		Data:      buf.Bytes(),
+		CommitSHA: sha,
+	}, nil
+}
+
+// Resolve returns the commit SHA the ref points to in the repository,
+// without fetching it. The ref must be a branch or tag, and defaults to the
+// default branch.
+func Resolve(ctx context.Context, opts Options) (string, error) {
+	err := opts.Validate()
+	if err != nil {
+		return "", err
+	}
+	pattern := "HEAD"
+	if opts.Ref != "" {
+		pattern = opts.Ref
+	}
+	out, err := git(ctx, "", opts, "ls-remote", "--quiet", opts.URL, pattern, pattern+"^{}")
+	if err != nil {
+		return "", err
+	}
+	var resolved string
+	for _, line := range strings.Split(out, "\n") {
+		sha, name, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+		// Annotated tags are listed twice: as the tag object, and peeled to
+		// the commit it points to with a "^{}" suffix. Only the commit
+		// matches the SHA of a clone.
+		if name == "refs/tags/"+pattern+"^{}" {
+			return sha, nil
+		}
+		// ls-remote matches the end of ref names, so "main" would also
+		// match "refs/heads/feature/main".
+		if resolved == "" && (name == pattern || name == "refs/heads/"+pattern || name == "refs/tags/"+pattern) {
+			resolved = sha
+		}
+	}
+	if resolved == "" {
+		return "", xerrors.Errorf("ref %q not found in the repository", pattern)
+	}
+	return resolved, nil
+}
+
+// git runs a git command in the directory and returns its trimmed output.
+func git(ctx context.Context, dir string, opts Options, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		// Never wait for credentials on a terminal that isn't there.
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_ALLOW_PROTOCOL=http:https",
+	)
+	// Config is passed through the environment so credentials don't show
+	// up in the process list. Credential helpers of the host are disabled,
+	// so they can't be used to access other repositories.
+	config := [][2]string{{"credential.helper", ""}}
+	if opts.Username != "" || opts.Password != "" {
+		auth := base64.StdEncoding.EncodeToString([]byte(opts.Username + ":" + opts.Password))
+		config = append(config, [2]string{"http.extraHeader", "Authorization: Basic " + auth})
+	}
+	cmd.Env = append(cmd.Env, fmt.Sprintf("GIT_CONFIG_COUNT=%d", len(config)))
+	for i, kv := range config {
+		cmd.Env = append(cmd.Env,
+			fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", i, kv[0]),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", i, kv[1]),
+		)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return "", xerrors.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,118 @@
+// Package gitsourcetest serves git repositories for tests.
+package gitsourcetest
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/provisioner/echo"
+)
+
+// Repo is a bare repository served over the dumb HTTP protocol, with a
+// working copy to commit to it from.
+type Repo struct {
+	Server *httptest.Server
+
+	t    testing.TB
+	work string
+	bare string
+}
+
+// NewRepo creates an empty repository whose default branch is main. The test
+// is skipped if git isn't installed.
+func NewRepo(t testing.TB) *Repo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	r := &Repo{
+		t:    t,
+		work: t.TempDir(),
+		bare: filepath.Join(t.TempDir(), "repo.git"),
+	}
+	run(t, r.work, "init", "--quiet", "--initial-branch=main")
+	run(t, r.work, "init", "--quiet", "--bare", "--initial-branch=main", r.bare)
+
+	r.Server = httptest.NewServer(http.FileServer(http.Dir(r.bare)))
+	t.Cleanup(r.Server.Close)
+	return r
+}
+
+// Commit writes the files and pushes them to the main branch of the
+// repository. It returns the commit SHA.
+func (r *Repo) Commit(files map[string]string) string {
+	r.t.Helper()
+	for name, content := range files {
+		path := filepath.Join(r.work, filepath.FromSlash(name))
+		require.NoError(r.t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(r.t, os.WriteFile(path, []byte(content), 0o600))
+	}
+	run(r.t, r.work, "add", ".")
+	run(r.t, r.work, "-c", "user.name=Coder", "-c", "user.email=coder@example.com", "commit", "--quiet", "--allow-empty", "--message=Update templates")
+	run(r.t, r.work, "push", "--quiet", r.bare, "main")
+	run(r.t, r.bare, "update-server-info")
+	return run(r.t, r.work, "rev-parse", "HEAD")
+}
+
+// Tag creates an annotated tag of the latest commit and pushes it to the
+// repository.
+func (r *Repo) Tag(name string) {
+	r.t.Helper()
+	run(r.t, r.work, "-c", "user.name=Coder", "-c", "user.email=coder@example.com", "tag", "--annotate", "--message=Release "+name, name)
+	run(r.t, r.work, "push", "--quiet", r.bare, "refs/tags/"+name)
+	run(r.t, r.bare, "update-server-info")
+}
+
+// ServeRepo commits the files to a repository on the main branch, and serves
+// it over the dumb HTTP protocol. It returns the server and the commit SHA.
+// The test is skipped if git isn't installed.
+func ServeRepo(t testing.TB, files map[string]string) (*httptest.Server, string) {
+	t.Helper()
+	repo := NewRepo(t)
+	sha := repo.Commit(files)
+	return repo.Server, sha
+}
+
+// EchoFiles returns the files of an echo template in the directory, to
+// commit to a repository.
+func EchoFiles(t testing.TB, directory string, responses *echo.Responses) map[string]string {
+	t.Helper()
+	data, err := echo.Tar(responses)
+	require.NoError(t, err)
+	files := map[string]string{
+		path.Join(directory, "main.tf"): "",
+	}
+	reader := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return files
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		files[path.Join(directory, header.Name)] = string(content)
+	}
+}
+
+func run(t testing.TB, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+	return strings.TrimSpace(string(out))
+}
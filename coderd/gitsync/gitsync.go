@@ -0,0 +1,550 @@
+// Package gitsync keeps templates in sync with git branches. A template
+// version is created for every new commit of a template's branch, and is
+// promoted to the active version once it imports successfully and a dry-run
+// with the default parameters succeeds.
+package gitsync
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/moby/moby/pkg/namesgenerator"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/filestore"
+	"github.com/coder/coder/coderd/gitsource"
+	"github.com/coder/coder/coderd/provisionerdserver"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/webhooks"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisionersdk"
+)
+
+// ClaimTimeout is how long a replica has to create a version for the commit
+// it claimed. The commit is claimed again after that, so a replica that was
+// stopped while cloning doesn't stop the template from being synced.
+const ClaimTimeout = 10 * time.Minute
+
+// Options configure a Syncer.
+type Options struct {
+	Database database.Store
+	// Pubsub publishes the webhook events of promoted versions.
+	Pubsub database.Pubsub
+	// Auditor records promoted versions in the audit log.
+	Auditor *atomic.Pointer[audit.Auditor]
+	// FileStore stores the contents of template archives. If nil, contents
+	// are stored in the database.
+	FileStore filestore.Store
+	Logger    slog.Logger
+	// Interval is how often the branches of every synced template are
+	// checked for new commits, and imported versions are promoted.
+	Interval time.Duration
+	// Credentials sets the credentials the user clones the repository with,
+	// if they have any.
+	Credentials func(ctx context.Context, userID uuid.UUID, opts *gitsource.Options) error
+}
+
+// Syncer periodically syncs every template that has a git sync. Replicas
+// claim a commit before creating a version for it, so only one version is
+// created per commit.
+type Syncer struct {
+	opts    Options
+	trigger chan uuid.UUID
+
+	done   chan struct{}
+	cancel func()
+}
+
+// New starts syncing every interval until the syncer is closed.
+func New(opts Options) *Syncer {
+	if opts.Credentials == nil {
+		opts.Credentials = func(context.Context, uuid.UUID, *gitsource.Options) error { return nil }
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	//nolint:gocritic // The syncer records the progress of every template.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	s := &Syncer{
+		opts:    opts,
+		trigger: make(chan uuid.UUID, 64),
+		done:    make(chan struct{}),
+		cancel:  cancel,
+	}
+	go s.run(ctx)
+	return s
+}
+
+// Trigger syncs the template as soon as possible, e.g. when a push webhook
+// is received for it. Triggers are dropped if the syncer is busy.
+func (s *Syncer) Trigger(templateID uuid.UUID) {
+	select {
+	case s.trigger <- templateID:
+	default:
+	}
+}
+
+func (s *Syncer) run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			gitSyncs, err := s.opts.Database.GetTemplateGitSyncs(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				s.opts.Logger.Error(ctx, "get template git syncs", slog.Error(err))
+				continue
+			}
+			for _, gitSync := range gitSyncs {
+				s.sync(ctx, gitSync)
+			}
+		case templateID := <-s.trigger:
+			gitSync, err := s.opts.Database.GetTemplateGitSyncByTemplateID(ctx, templateID)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				if !errors.Is(err, sql.ErrNoRows) {
+					s.opts.Logger.Error(ctx, "get template git sync", slog.F("template_id", templateID), slog.Error(err))
+				}
+				continue
+			}
+			s.sync(ctx, gitSync)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sync promotes the pending version of the template, or creates a version if
+// there's a new commit on the branch. Errors are recorded on the sync.
+func (s *Syncer) sync(ctx context.Context, gitSync database.TemplateGitSync) {
+	logger := s.opts.Logger.With(slog.F("template_id", gitSync.TemplateID))
+	var err error
+	if gitSync.Status == string(codersdk.TemplateGitSyncStatusPending) && gitSync.TemplateVersionID.Valid {
+		err = s.promote(ctx, gitSync)
+	} else {
+		err = s.createVersion(ctx, gitSync)
+	}
+	if err == nil {
+		return
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	logger.Warn(ctx, "sync template with git", slog.Error(err))
+	gitSync.Error = err.Error()
+	_, err = s.updateStatus(ctx, gitSync)
+	if err != nil && ctx.Err() == nil {
+		logger.Error(ctx, "update template git sync status", slog.Error(err))
+	}
+}
+
+// promote makes the pending version the active version of the template
+// once it has imported successfully, and a dry-run with the default
+// parameters of the template succeeded.
+func (s *Syncer) promote(ctx context.Context, gitSync database.TemplateGitSync) error {
+	version, err := s.opts.Database.GetTemplateVersionByID(ctx, gitSync.TemplateVersionID.UUID)
+	if err != nil {
+		return xerrors.Errorf("get template version: %w", err)
+	}
+	job, err := s.opts.Database.GetProvisionerJobByID(ctx, version.JobID)
+	if err != nil {
+		return xerrors.Errorf("get provisioner job: %w", err)
+	}
+	if !job.CompletedAt.Valid {
+		return nil
+	}
+	if job.Error.Valid || job.CanceledAt.Valid {
+		gitSync.Status = string(codersdk.TemplateGitSyncStatusFailed)
+		gitSync.Error = "The template version failed to import: " + job.Error.String
+		if !job.Error.Valid {
+			gitSync.Error = "The template version import was canceled."
+		}
+		_, err = s.updateStatus(ctx, gitSync)
+		return err
+	}
+
+	if !gitSync.DryRunJobID.Valid {
+		return s.dryRun(ctx, gitSync, version, job)
+	}
+	dryRunJob, err := s.opts.Database.GetProvisionerJobByID(ctx, gitSync.DryRunJobID.UUID)
+	if err != nil {
+		return xerrors.Errorf("get dry-run provisioner job: %w", err)
+	}
+	if !dryRunJob.CompletedAt.Valid {
+		return nil
+	}
+	if dryRunJob.Error.Valid || dryRunJob.CanceledAt.Valid {
+		gitSync.Status = string(codersdk.TemplateGitSyncStatusFailed)
+		gitSync.Error = "The template version failed a dry-run with the default parameters: " + dryRunJob.Error.String
+		if !dryRunJob.Error.Valid {
+			gitSync.Error = "The template version dry-run was canceled."
+		}
+		_, err = s.updateStatus(ctx, gitSync)
+		return err
+	}
+
+	userCtx, err := s.actAs(ctx, gitSync.UserID)
+	if err != nil {
+		return err
+	}
+	var (
+		acquired bool
+		promoted bool
+		template database.Template
+	)
+	err = s.opts.Database.InTx(func(tx database.Store) error {
+		// Replicas promote versions one at a time, so a promotion is only
+		// audited by the replica that made it.
+		acquired, err = tx.TryAcquireLock(ctx, database.LockIDTemplateGitSyncPromote)
+		if err != nil {
+			return xerrors.Errorf("acquire lock: %w", err)
+		}
+		if !acquired {
+			return nil
+		}
+		template, err = tx.GetTemplateByID(ctx, gitSync.TemplateID)
+		if err != nil {
+			return xerrors.Errorf("get template: %w", err)
+		}
+		if template.ActiveVersionID == version.ID {
+			return nil
+		}
+		err = tx.UpdateTemplateActiveVersionByID(userCtx, database.UpdateTemplateActiveVersionByIDParams{
+			ID:              gitSync.TemplateID,
+			ActiveVersionID: version.ID,
+			UpdatedAt:       database.Now(),
+		})
+		if err != nil {
+			return xerrors.Errorf("promote template version: %w", err)
+		}
+		promoted = true
+		return nil
+	}, nil)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		// Another replica is promoting a version, try again later.
+		return nil
+	}
+	if promoted {
+		s.auditPromotion(ctx, gitSync, template, version)
+	}
+	gitSync.Status = string(codersdk.TemplateGitSyncStatusSucceeded)
+	gitSync.Error = ""
+	_, err = s.updateStatus(ctx, gitSync)
+	return err
+}
+
+// errDryRunClaimed is returned to roll back the dry-run job of a replica
+// when another replica has already created one for the version.
+var errDryRunClaimed = xerrors.New("dry-run claimed by another replica")
+
+// dryRun queues a dry-run of the imported version with the default values of
+// its parameters, so versions that can't be built aren't promoted.
+func (s *Syncer) dryRun(ctx context.Context, gitSync database.TemplateGitSync, version database.TemplateVersion, importJob database.ProvisionerJob) error {
+	userCtx, err := s.actAs(ctx, gitSync.UserID)
+	if err != nil {
+		return err
+	}
+	parameters, err := s.opts.Database.GetTemplateVersionParameters(ctx, version.ID)
+	if err != nil {
+		return xerrors.Errorf("get template version parameters: %w", err)
+	}
+	richParameterValues := make([]database.WorkspaceBuildParameter, 0, len(parameters))
+	for _, parameter := range parameters {
+		richParameterValues = append(richParameterValues, database.WorkspaceBuildParameter{
+			Name:  parameter.Name,
+			Value: parameter.DefaultValue,
+		})
+	}
+	input, err := json.Marshal(provisionerdserver.TemplateVersionDryRunJob{
+		TemplateVersionID:   version.ID,
+		WorkspaceName:       "git-sync",
+		RichParameterValues: richParameterValues,
+	})
+	if err != nil {
+		return xerrors.Errorf("marshal job input: %w", err)
+	}
+
+	err = s.opts.Database.InTx(func(tx database.Store) error {
+		job, err := tx.InsertProvisionerJob(userCtx, database.InsertProvisionerJobParams{
+			ID:             uuid.New(),
+			CreatedAt:      database.Now(),
+			UpdatedAt:      database.Now(),
+			OrganizationID: version.OrganizationID,
+			InitiatorID:    gitSync.UserID,
+			Provisioner:    importJob.Provisioner,
+			StorageMethod:  importJob.StorageMethod,
+			FileID:         importJob.FileID,
+			Type:           database.ProvisionerJobTypeTemplateVersionDryRun,
+			Input:          input,
+			Tags:           importJob.Tags,
+			Priority:       database.ProvisionerJobPriorityBackground,
+		})
+		if err != nil {
+			return xerrors.Errorf("insert provisioner job: %w", err)
+		}
+		_, err = tx.UpdateTemplateGitSyncDryRunJobByTemplateID(ctx, database.UpdateTemplateGitSyncDryRunJobByTemplateIDParams{
+			DryRunJobID:       uuid.NullUUID{UUID: job.ID, Valid: true},
+			CheckedAt:         sql.NullTime{Time: database.Now(), Valid: true},
+			TemplateID:        gitSync.TemplateID,
+			TemplateVersionID: gitSync.TemplateVersionID,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return errDryRunClaimed
+		}
+		if err != nil {
+			return xerrors.Errorf("claim dry-run: %w", err)
+		}
+		return nil
+	}, nil)
+	if errors.Is(err, errDryRunClaimed) {
+		return nil
+	}
+	return err
+}
+
+// createVersion creates a template version for the latest commit of the
+// branch, if a version hasn't been created for it yet.
+func (s *Syncer) createVersion(ctx context.Context, gitSync database.TemplateGitSync) error {
+	userCtx, err := s.actAs(ctx, gitSync.UserID)
+	if err != nil {
+		return err
+	}
+	opts := gitsource.Options{
+		URL:       gitSync.GitURL,
+		Ref:       gitSync.GitBranch,
+		Directory: gitSync.GitDirectory,
+		Limit:     provisionersdk.TemplateArchiveLimit,
+	}
+	err = s.opts.Credentials(userCtx, gitSync.UserID, &opts)
+	if err != nil {
+		return xerrors.Errorf("get git auth credentials: %w", err)
+	}
+	sha, err := gitsource.Resolve(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if sha == gitSync.CommitSHA && !claimIsStale(gitSync) {
+		// Only write to clear the error of a previous check. The error of a
+		// failed import is kept until there's a new commit.
+		if gitSync.Error != "" && gitSync.Status != string(codersdk.TemplateGitSyncStatusFailed) {
+			gitSync.Error = ""
+			_, err = s.updateStatus(ctx, gitSync)
+		}
+		return err
+	}
+
+	previous := gitSync
+	gitSync, err = s.opts.Database.UpdateTemplateGitSyncCommitByTemplateID(ctx, database.UpdateTemplateGitSyncCommitByTemplateIDParams{
+		TemplateID:        gitSync.TemplateID,
+		CommitSHA:         sha,
+		PreviousCommitSHA: gitSync.CommitSHA,
+		CheckedAt:         sql.NullTime{Time: database.Now(), Valid: true},
+		StaleBefore:       database.Now().Add(-ClaimTimeout),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		// Another replica has claimed the commit.
+		return nil
+	}
+	if err != nil {
+		return xerrors.Errorf("claim commit: %w", err)
+	}
+
+	version, err := s.insertVersion(userCtx, gitSync, opts)
+	if err != nil {
+		// Release the commit, so the next check tries again.
+		previous.Error = err.Error()
+		_, updateErr := s.updateStatus(ctx, previous)
+		if updateErr != nil {
+			return xerrors.Errorf("release commit: %w", updateErr)
+		}
+		return nil
+	}
+	gitSync.CommitSHA = version.GitCommitSHA
+	gitSync.TemplateVersionID = uuid.NullUUID{UUID: version.ID, Valid: true}
+	_, err = s.updateStatus(ctx, gitSync)
+	return err
+}
+
+// claimIsStale returns whether a version was never created for the claimed
+// commit, because the replica that claimed it stopped before it could.
+func claimIsStale(gitSync database.TemplateGitSync) bool {
+	return gitSync.CommitSHA != "" &&
+		!gitSync.TemplateVersionID.Valid &&
+		gitSync.CheckedAt.Valid &&
+		gitSync.CheckedAt.Time.Before(database.Now().Add(-ClaimTimeout))
+}
+
+// insertVersion clones the repository and creates a template version from
+// it, with the tags and variable values of the active version.
+func (s *Syncer) insertVersion(ctx context.Context, gitSync database.TemplateGitSync, opts gitsource.Options) (database.TemplateVersion, error) {
+	db := s.opts.Database
+	template, err := db.GetTemplateByID(ctx, gitSync.TemplateID)
+	if err != nil {
+		return database.TemplateVersion{}, xerrors.Errorf("get template: %w", err)
+	}
+	activeVersion, err := db.GetTemplateVersionByID(ctx, template.ActiveVersionID)
+	if err != nil {
+		return database.TemplateVersion{}, xerrors.Errorf("get active template version: %w", err)
+	}
+	activeJob, err := db.GetProvisionerJobByID(ctx, activeVersion.JobID)
+	if err != nil {
+		return database.TemplateVersion{}, xerrors.Errorf("get active template version job: %w", err)
+	}
+	variables, err := db.GetTemplateVersionVariables(ctx, activeVersion.ID)
+	if err != nil {
+		return database.TemplateVersion{}, xerrors.Errorf("get active template version variables: %w", err)
+	}
+	variableValues := make([]codersdk.VariableValue, 0, len(variables))
+	for _, variable := range variables {
+		variableValues = append(variableValues, codersdk.VariableValue{
+			Name:  variable.Name,
+			Value: variable.Value,
+		})
+	}
+	tags := make(map[string]string, len(activeJob.Tags))
+	for key, value := range activeJob.Tags {
+		tags[key] = value
+	}
+
+	archive, err := gitsource.Clone(ctx, opts)
+	if err != nil {
+		return database.TemplateVersion{}, err
+	}
+	file, err := filestore.InsertArchive(ctx, s.opts.Database, s.opts.FileStore, gitSync.UserID, archive.Data)
+	if err != nil {
+		return database.TemplateVersion{}, err
+	}
+
+	var version database.TemplateVersion
+	err = db.InTx(func(tx database.Store) error {
+		versionID := uuid.New()
+		jobInput, err := json.Marshal(provisionerdserver.TemplateVersionImportJob{
+			TemplateVersionID:  versionID,
+			UserVariableValues: variableValues,
+		})
+		if err != nil {
+			return xerrors.Errorf("marshal job input: %w", err)
+		}
+		job, err := tx.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+			ID:             uuid.New(),
+			CreatedAt:      database.Now(),
+			UpdatedAt:      database.Now(),
+			OrganizationID: template.OrganizationID,
+			InitiatorID:    gitSync.UserID,
+			Provisioner:    template.Provisioner,
+			StorageMethod:  database.ProvisionerStorageMethodFile,
+			FileID:         file.ID,
+			Type:           database.ProvisionerJobTypeTemplateVersionImport,
+			Input:          jobInput,
+			Tags:           provisionerdserver.MutateTags(gitSync.UserID, tags),
+			Priority:       database.ProvisionerJobPriorityBackground,
+		})
+		if err != nil {
+			return xerrors.Errorf("insert provisioner job: %w", err)
+		}
+		version, err = tx.InsertTemplateVersion(ctx, database.InsertTemplateVersionParams{
+			ID:             versionID,
+			TemplateID:     uuid.NullUUID{UUID: template.ID, Valid: true},
+			OrganizationID: template.OrganizationID,
+			CreatedAt:      database.Now(),
+			UpdatedAt:      database.Now(),
+			Name:           namesgenerator.GetRandomName(1),
+			JobID:          job.ID,
+			CreatedBy:      gitSync.UserID,
+			GitURL:         gitSync.GitURL,
+			GitRef:         gitSync.GitBranch,
+			GitDirectory:   gitSync.GitDirectory,
+			GitCommitSHA:   archive.CommitSHA,
+		})
+		if err != nil {
+			return xerrors.Errorf("insert template version: %w", err)
+		}
+		return nil
+	}, nil)
+	return version, err
+}
+
+// actAs returns a context that authorizes as the user. Versions are created
+// with the permissions of the user that set up the sync, so the sync stops
+// working if they can no longer edit the template.
+// auditPromotion records the promotion of a version like a promotion through
+// the API, and notifies the webhooks of the template's organization.
+func (s *Syncer) auditPromotion(ctx context.Context, gitSync database.TemplateGitSync, template database.Template, version database.TemplateVersion) {
+	newTemplate := template
+	newTemplate.ActiveVersionID = version.ID
+	audit.BuildAudit(ctx, &audit.BuildAuditParams[database.Template]{
+		Audit:  *s.opts.Auditor.Load(),
+		Log:    s.opts.Logger,
+		UserID: gitSync.UserID,
+		JobID:  version.JobID,
+		Status: http.StatusOK,
+		Action: database.AuditActionWrite,
+		Old:    template,
+		New:    newTemplate,
+	})
+
+	err := webhooks.Publish(s.opts.Pubsub, codersdk.WebhookEventTemplateVersionPromoted, template.OrganizationID, map[string]string{
+		"template_id":           template.ID.String(),
+		"template_name":         template.Name,
+		"template_version_id":   version.ID.String(),
+		"template_version_name": version.Name,
+	})
+	if err != nil {
+		s.opts.Logger.Warn(ctx, "publish webhook event", slog.F("template_id", template.ID), slog.Error(err))
+	}
+}
+
+func (s *Syncer) actAs(ctx context.Context, userID uuid.UUID) (context.Context, error) {
+	roles, err := s.opts.Database.GetAuthorizationUserRoles(ctx, userID)
+	if err != nil {
+		return nil, xerrors.Errorf("get user roles: %w", err)
+	}
+	if roles.Status != database.UserStatusActive {
+		return nil, xerrors.Errorf("the user that set up the sync is %s", roles.Status)
+	}
+	//nolint:gocritic // The syncer acts as the user that set up the sync.
+	return dbauthz.As(ctx, rbac.Subject{
+		ID:     userID.String(),
+		Roles:  rbac.RoleNames(roles.Roles),
+		Groups: roles.Groups,
+		Scope:  rbac.ScopeAll,
+	}), nil
+}
+
+func (s *Syncer) updateStatus(ctx context.Context, gitSync database.TemplateGitSync) (database.TemplateGitSync, error) {
+	return s.opts.Database.UpdateTemplateGitSyncStatusByTemplateID(ctx, database.UpdateTemplateGitSyncStatusByTemplateIDParams{
+		TemplateID:        gitSync.TemplateID,
+		CommitSHA:         gitSync.CommitSHA,
+		TemplateVersionID: gitSync.TemplateVersionID,
+		Status:            gitSync.Status,
+		Error:             gitSync.Error,
+		CheckedAt:         sql.NullTime{Time: database.Now(), Valid: true},
+	})
+}
+
+// Close stops syncing, and waits for a sync in progress to finish.
+func (s *Syncer) Close() error {
+	s.cancel()
+	<-s.done
+	return nil
+}
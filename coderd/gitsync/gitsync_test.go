@@ -0,0 +1,349 @@
+package gitsync_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbfake"
+	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/coderd/gitsource/gitsourcetest"
+	"github.com/coder/coder/coderd/gitsync"
+	"github.com/coder/coder/coderd/provisionerdserver"
+	"github.com/coder/coder/coderd/webhooks"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestSyncer(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	repo := gitsourcetest.NewRepo(t)
+	sha := repo.Commit(map[string]string{"main.tf": ""})
+
+	db := dbfake.New()
+	user := dbgen.User(t, db, database.User{})
+	template := setupTemplate(t, db, user.ID)
+	dbgen.TemplateGitSync(t, db, database.TemplateGitSync{
+		TemplateID: template.ID,
+		UserID:     user.ID,
+		GitURL:     repo.Server.URL,
+		GitBranch:  "main",
+	})
+
+	pubsub := database.NewPubsubInMemory()
+	events := make(chan codersdk.WebhookEventPayload, 8)
+	closeSubscribe, err := pubsub.Subscribe(webhooks.EventChannel, func(_ context.Context, message []byte) {
+		var event codersdk.WebhookEventPayload
+		assert.NoError(t, json.Unmarshal(message, &event))
+		events <- event
+	})
+	require.NoError(t, err)
+	defer closeSubscribe()
+	mockAuditor := audit.NewMock()
+
+	// Replicas race to sync the same commit, only one version is created.
+	for i := 0; i < 3; i++ {
+		syncer := gitsync.New(gitsync.Options{
+			Database: db,
+			Pubsub:   pubsub,
+			Auditor:  auditor(mockAuditor),
+			Logger:   slogtest.Make(t, nil),
+			Interval: testutil.IntervalFast,
+		})
+		defer syncer.Close()
+	}
+
+	var gitSync database.TemplateGitSync
+	require.Eventually(t, func() bool {
+		var err error
+		gitSync, err = db.GetTemplateGitSyncByTemplateID(ctx, template.ID)
+		require.NoError(t, err)
+		return gitSync.CommitSHA == sha && gitSync.TemplateVersionID.Valid
+	}, testutil.WaitLong, testutil.IntervalFast)
+	require.Equal(t, string(codersdk.TemplateGitSyncStatusPending), gitSync.Status)
+
+	versions, err := db.GetTemplateVersionsByTemplateID(ctx, database.GetTemplateVersionsByTemplateIDParams{
+		TemplateID: template.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+
+	version, err := db.GetTemplateVersionByID(ctx, gitSync.TemplateVersionID.UUID)
+	require.NoError(t, err)
+	require.Equal(t, sha, version.GitCommitSHA)
+	require.Equal(t, user.ID, version.CreatedBy)
+	job, err := db.GetProvisionerJobByID(ctx, version.JobID)
+	require.NoError(t, err)
+	require.Equal(t, "bar", job.Tags["foo"])
+
+	// A dry-run with the default parameters is queued once the import
+	// completes, and only one replica queues it.
+	_, err = db.InsertTemplateVersionParameter(ctx, database.InsertTemplateVersionParameterParams{
+		TemplateVersionID: version.ID,
+		Name:              "region",
+		Type:              "string",
+		DefaultValue:      "eu",
+		Options:           json.RawMessage("[]"),
+	})
+	require.NoError(t, err)
+	completeJob(ctx, t, db, version.JobID, "")
+	require.Eventually(t, func() bool {
+		gitSync, err = db.GetTemplateGitSyncByTemplateID(ctx, template.ID)
+		require.NoError(t, err)
+		return gitSync.DryRunJobID.Valid
+	}, testutil.WaitLong, testutil.IntervalFast)
+	dryRunJob, err := db.GetProvisionerJobByID(ctx, gitSync.DryRunJobID.UUID)
+	require.NoError(t, err)
+	require.Equal(t, database.ProvisionerJobTypeTemplateVersionDryRun, dryRunJob.Type)
+	require.Equal(t, "bar", dryRunJob.Tags["foo"])
+	var input provisionerdserver.TemplateVersionDryRunJob
+	require.NoError(t, json.Unmarshal(dryRunJob.Input, &input))
+	require.Equal(t, version.ID, input.TemplateVersionID)
+	require.Equal(t, []database.WorkspaceBuildParameter{{Name: "region", Value: "eu"}}, input.RichParameterValues)
+	jobs, err := db.GetProvisionerJobsCreatedAfter(ctx, job.CreatedAt.Add(-time.Second))
+	require.NoError(t, err)
+	dryRuns := 0
+	for _, job := range jobs {
+		if job.Type == database.ProvisionerJobTypeTemplateVersionDryRun {
+			dryRuns++
+		}
+	}
+	require.Equal(t, 1, dryRuns)
+	template, err = db.GetTemplateByID(ctx, template.ID)
+	require.NoError(t, err)
+	require.NotEqual(t, version.ID, template.ActiveVersionID)
+
+	// The version is promoted once the dry-run succeeds.
+	completeJob(ctx, t, db, dryRunJob.ID, "")
+	require.Eventually(t, func() bool {
+		template, err := db.GetTemplateByID(ctx, template.ID)
+		require.NoError(t, err)
+		return template.ActiveVersionID == version.ID
+	}, testutil.WaitLong, testutil.IntervalFast)
+	require.Eventually(t, func() bool {
+		gitSync, err = db.GetTemplateGitSyncByTemplateID(ctx, template.ID)
+		require.NoError(t, err)
+		return gitSync.Status == string(codersdk.TemplateGitSyncStatusSucceeded)
+	}, testutil.WaitLong, testutil.IntervalFast)
+	require.Empty(t, gitSync.Error)
+
+	// The promotion is audited and published once, like a promotion through
+	// the API.
+	require.Len(t, mockAuditor.AuditLogs, 1)
+	require.Equal(t, template.ID, mockAuditor.AuditLogs[0].ResourceID)
+	require.Equal(t, database.AuditActionWrite, mockAuditor.AuditLogs[0].Action)
+	require.Equal(t, user.ID, mockAuditor.AuditLogs[0].UserID)
+	select {
+	case event := <-events:
+		require.Equal(t, codersdk.WebhookEventTemplateVersionPromoted, event.Event)
+		require.Equal(t, version.ID.String(), event.Data["template_version_id"])
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for webhook event")
+	}
+	require.Empty(t, events)
+}
+
+func TestSyncerFailedDryRun(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	repo := gitsourcetest.NewRepo(t)
+	_ = repo.Commit(map[string]string{"main.tf": ""})
+
+	db := dbfake.New()
+	user := dbgen.User(t, db, database.User{})
+	template := setupTemplate(t, db, user.ID)
+	dbgen.TemplateGitSync(t, db, database.TemplateGitSync{
+		TemplateID: template.ID,
+		UserID:     user.ID,
+		GitURL:     repo.Server.URL,
+	})
+
+	syncer := gitsync.New(gitsync.Options{
+		Database: db,
+		Pubsub:   database.NewPubsubInMemory(),
+		Auditor:  auditor(audit.NewNop()),
+		Logger:   slogtest.Make(t, nil),
+		Interval: testutil.IntervalFast,
+	})
+	defer syncer.Close()
+
+	var gitSync database.TemplateGitSync
+	require.Eventually(t, func() bool {
+		var err error
+		gitSync, err = db.GetTemplateGitSyncByTemplateID(ctx, template.ID)
+		require.NoError(t, err)
+		return gitSync.TemplateVersionID.Valid
+	}, testutil.WaitLong, testutil.IntervalFast)
+	version, err := db.GetTemplateVersionByID(ctx, gitSync.TemplateVersionID.UUID)
+	require.NoError(t, err)
+	completeJob(ctx, t, db, version.JobID, "")
+	require.Eventually(t, func() bool {
+		gitSync, err = db.GetTemplateGitSyncByTemplateID(ctx, template.ID)
+		require.NoError(t, err)
+		return gitSync.DryRunJobID.Valid
+	}, testutil.WaitLong, testutil.IntervalFast)
+
+	// A version whose dry-run fails isn't promoted.
+	completeJob(ctx, t, db, gitSync.DryRunJobID.UUID, "missing required parameter")
+	require.Eventually(t, func() bool {
+		gitSync, err = db.GetTemplateGitSyncByTemplateID(ctx, template.ID)
+		require.NoError(t, err)
+		return gitSync.Status == string(codersdk.TemplateGitSyncStatusFailed)
+	}, testutil.WaitLong, testutil.IntervalFast)
+	require.Contains(t, gitSync.Error, "missing required parameter")
+	got, err := db.GetTemplateByID(ctx, template.ID)
+	require.NoError(t, err)
+	require.Equal(t, template.ActiveVersionID, got.ActiveVersionID)
+}
+
+func TestSyncerStaleClaim(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	repo := gitsourcetest.NewRepo(t)
+	sha := repo.Commit(map[string]string{"main.tf": ""})
+
+	db := dbfake.New()
+	user := dbgen.User(t, db, database.User{})
+	template := setupTemplate(t, db, user.ID)
+	dbgen.TemplateGitSync(t, db, database.TemplateGitSync{
+		TemplateID: template.ID,
+		UserID:     user.ID,
+		GitURL:     repo.Server.URL,
+	})
+	// A replica claimed the commit, and stopped before it created a version.
+	_, err := db.UpdateTemplateGitSyncStatusByTemplateID(ctx, database.UpdateTemplateGitSyncStatusByTemplateIDParams{
+		TemplateID: template.ID,
+		CommitSHA:  sha,
+		Status:     string(codersdk.TemplateGitSyncStatusPending),
+		CheckedAt:  sql.NullTime{Time: database.Now().Add(-gitsync.ClaimTimeout - time.Minute), Valid: true},
+	})
+	require.NoError(t, err)
+
+	syncer := gitsync.New(gitsync.Options{
+		Database: db,
+		Pubsub:   database.NewPubsubInMemory(),
+		Auditor:  auditor(audit.NewNop()),
+		Logger:   slogtest.Make(t, nil),
+		Interval: testutil.IntervalFast,
+	})
+	defer syncer.Close()
+
+	require.Eventually(t, func() bool {
+		gitSync, err := db.GetTemplateGitSyncByTemplateID(ctx, template.ID)
+		require.NoError(t, err)
+		return gitSync.CommitSHA == sha && gitSync.TemplateVersionID.Valid
+	}, testutil.WaitLong, testutil.IntervalFast)
+}
+
+func TestSyncerSuspendedUser(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	repo := gitsourcetest.NewRepo(t)
+	_ = repo.Commit(map[string]string{"main.tf": ""})
+
+	db := dbfake.New()
+	user := dbgen.User(t, db, database.User{})
+	_, err := db.UpdateUserStatus(ctx, database.UpdateUserStatusParams{
+		ID:        user.ID,
+		Status:    database.UserStatusSuspended,
+		UpdatedAt: database.Now(),
+	})
+	require.NoError(t, err)
+	template := setupTemplate(t, db, user.ID)
+	dbgen.TemplateGitSync(t, db, database.TemplateGitSync{
+		TemplateID: template.ID,
+		UserID:     user.ID,
+		GitURL:     repo.Server.URL,
+	})
+
+	syncer := gitsync.New(gitsync.Options{
+		Database: db,
+		Pubsub:   database.NewPubsubInMemory(),
+		Auditor:  auditor(audit.NewNop()),
+		Logger:   slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}),
+		Interval: testutil.IntervalFast,
+	})
+	defer syncer.Close()
+
+	require.Eventually(t, func() bool {
+		gitSync, err := db.GetTemplateGitSyncByTemplateID(ctx, template.ID)
+		require.NoError(t, err)
+		return gitSync.Error != ""
+	}, testutil.WaitLong, testutil.IntervalFast)
+
+	gitSync, err := db.GetTemplateGitSyncByTemplateID(ctx, template.ID)
+	require.NoError(t, err)
+	require.Contains(t, gitSync.Error, "suspended")
+	require.Empty(t, gitSync.CommitSHA)
+	require.False(t, gitSync.TemplateVersionID.Valid)
+}
+
+// completeJob completes the provisioner job, with the error if it isn't
+// empty.
+func auditor(a audit.Auditor) *atomic.Pointer[audit.Auditor] {
+	var ptr atomic.Pointer[audit.Auditor]
+	ptr.Store(&a)
+	return &ptr
+}
+
+func completeJob(ctx context.Context, t *testing.T, db database.Store, jobID uuid.UUID, jobError string) {
+	t.Helper()
+	err := db.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
+		ID:          jobID,
+		UpdatedAt:   database.Now(),
+		CompletedAt: sql.NullTime{Time: database.Now(), Valid: true},
+		Error:       sql.NullString{String: jobError, Valid: jobError != ""},
+	})
+	require.NoError(t, err)
+}
+
+// setupTemplate creates a template whose active version was imported with
+// tags, which versions created by the syncer inherit.
+func setupTemplate(t *testing.T, db database.Store, userID uuid.UUID) database.Template {
+	t.Helper()
+	job := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{
+		InitiatorID: userID,
+		Type:        database.ProvisionerJobTypeTemplateVersionImport,
+		Tags:        map[string]string{"foo": "bar"},
+	})
+	activeVersionID := uuid.New()
+	template := dbgen.Template(t, db, database.Template{
+		ActiveVersionID: activeVersionID,
+		CreatedBy:       userID,
+	})
+	_ = dbgen.TemplateVersion(t, db, database.TemplateVersion{
+		ID:         activeVersionID,
+		TemplateID: uuid.NullUUID{UUID: template.ID, Valid: true},
+		JobID:      job.ID,
+		CreatedBy:  userID,
+	})
+	return template
+}
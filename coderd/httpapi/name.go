@@ -13,8 +13,14 @@ var (
 	usernameReplace    = regexp.MustCompile("[^a-zA-Z0-9-]*")
 
 	templateDisplayName = regexp.MustCompile(`^[^\s](.*[^\s])?$`)
+
+	workspaceLabelKey   = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+	workspaceLabelValue = regexp.MustCompile(`^[a-z0-9_-]*$`)
 )
 
+// MaxWorkspaceLabels is the maximum number of labels a workspace may have.
+const MaxWorkspaceLabels = 64
+
 // UsernameFrom returns a best-effort username from the provided string.
 //
 // It first attempts to validate the incoming string, which will
@@ -66,3 +72,36 @@ func TemplateDisplayNameValid(str string) error {
 	}
 	return nil
 }
+
+// WorkspaceLabelsValid returns whether the input labels are valid workspace
+// labels. The rules match the strictest cloud provider (Google Cloud), so
+// templates can apply labels to resources unchanged.
+func WorkspaceLabelsValid(labels map[string]string) error {
+	if len(labels) > MaxWorkspaceLabels {
+		return xerrors.Errorf("must be <= %d labels", MaxWorkspaceLabels)
+	}
+	for key, value := range labels {
+		if err := WorkspaceLabelValid(key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WorkspaceLabelValid returns whether the input key and value are a valid
+// workspace label.
+func WorkspaceLabelValid(key, value string) error {
+	if len(key) < 1 || len(key) > 63 {
+		return xerrors.Errorf("label key %q must be between 1 and 63 characters", key)
+	}
+	if !workspaceLabelKey.MatchString(key) {
+		return xerrors.Errorf("label key %q must start with a lowercase letter and contain only lowercase letters, numbers, underscores and hyphens", key)
+	}
+	if len(value) > 63 {
+		return xerrors.Errorf("label value %q must be <= 63 characters", value)
+	}
+	if !workspaceLabelValue.MatchString(value) {
+		return xerrors.Errorf("label value %q must contain only lowercase letters, numbers, underscores and hyphens", value)
+	}
+	return nil
+}
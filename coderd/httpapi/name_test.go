@@ -1,6 +1,8 @@
 package httpapi_test
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -120,6 +122,48 @@ func TestTemplateDisplayNameValid(t *testing.T) {
 	}
 }
 
+func TestWorkspaceLabelValid(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		Key   string
+		Value string
+		Valid bool
+	}{
+		{"project", "coder", true},
+		{"cost-center", "", true},
+		{"ticket_id", "eng-1234", true},
+		{"a", "0", true},
+		{strings.Repeat("k", 63), strings.Repeat("v", 63), true},
+
+		{"", "value", false},
+		{"1key", "value", false},
+		{"-key", "value", false},
+		{"Project", "coder", false},
+		{"project", "Coder", false},
+		{"project.name", "coder", false},
+		{"project", "coder dev", false},
+		{"project", "coder/dev", false},
+		{strings.Repeat("k", 64), "value", false},
+		{"key", strings.Repeat("v", 64), false},
+	}
+	for _, testCase := range testCases {
+		testCase := testCase
+		t.Run(testCase.Key+"="+testCase.Value, func(t *testing.T) {
+			t.Parallel()
+			valid := httpapi.WorkspaceLabelValid(testCase.Key, testCase.Value)
+			require.Equal(t, testCase.Valid, valid == nil)
+		})
+	}
+
+	labels := map[string]string{}
+	for i := 0; i <= httpapi.MaxWorkspaceLabels; i++ {
+		labels[fmt.Sprintf("label%d", i)] = ""
+	}
+	require.Error(t, httpapi.WorkspaceLabelsValid(labels))
+	delete(labels, "label0")
+	require.NoError(t, httpapi.WorkspaceLabelsValid(labels))
+}
+
 func TestFrom(t *testing.T) {
 	t.Parallel()
 	testCases := []struct {
@@ -195,6 +195,38 @@ func TestParseQueryParams(t *testing.T) {
 		testQueryParams(t, expParams, parser, parser.Int)
 	})
 
+	t.Run("Boolean", func(t *testing.T) {
+		t.Parallel()
+		expParams := []queryParamTestCase[bool]{
+			{
+				QueryParam: "valid_true",
+				Value:      "true",
+				Expected:   true,
+			},
+			{
+				QueryParam: "valid_false",
+				Value:      "false",
+				Default:    true,
+				Expected:   false,
+			},
+			{
+				QueryParam: "no_value",
+				NoSet:      true,
+				Default:    true,
+				Expected:   true,
+			},
+			{
+				QueryParam:            "invalid_boolean",
+				Value:                 "bogus",
+				Expected:              false,
+				ExpectedErrorContains: "must be a valid boolean",
+			},
+		}
+
+		parser := httpapi.NewQueryParamParser()
+		testQueryParams(t, expParams, parser, parser.Boolean)
+	})
+
 	t.Run("UUIDs", func(t *testing.T) {
 		t.Parallel()
 		expParams := []queryParamTestCase[[]uuid.UUID]{
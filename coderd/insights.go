@@ -1,9 +1,14 @@
 package coderd
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/metricscache"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/codersdk"
 )
@@ -31,3 +36,134 @@ func (api *API) deploymentDAUs(rw http.ResponseWriter, r *http.Request) {
 	}
 	httpapi.Write(ctx, rw, http.StatusOK, resp)
 }
+
+// @Summary Get template insights
+// @ID get-template-insights
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Insights
+// @Param template path string true "Template ID" format(uuid)
+// @Param start_time query string false "Start of the range, rounded down to the day" format(date-time)
+// @Param end_time query string false "End of the range, rounded up to the day" format(date-time)
+// @Success 200 {object} codersdk.TemplateInsightsResponse
+// @Router /templates/{template}/insights [get]
+func (api *API) templateInsights(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+	// Insights are meant for the people managing the template.
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	parser := httpapi.NewQueryParamParser()
+	startTime := parser.Time(r.URL.Query(), time.Time{}, "start_time", time.RFC3339)
+	endTime := parser.Time(r.URL.Query(), time.Time{}, "end_time", time.RFC3339)
+	if len(parser.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: parser.Errors,
+		})
+		return
+	}
+	if !startTime.IsZero() && !endTime.IsZero() && endTime.Before(startTime) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The end time must be after the start time.",
+		})
+		return
+	}
+
+	resp, _ := api.metricsCache.TemplateInsights(template.ID, startTime, endTime)
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
+
+// @Summary Get user activity insights
+// @ID get-user-activity-insights
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Insights
+// @Param start_time query string false "Start of the range, defaults to 30 days ago" format(date-time)
+// @Param end_time query string false "End of the range, defaults to now" format(date-time)
+// @Param format query string false "Response format, csv returns text/csv" Enums(json,csv)
+// @Success 200 {object} codersdk.UserInsightsResponse
+// @Router /insights/users [get]
+func (api *API) userInsights(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceDeploymentConfig) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	now := database.Now()
+	parser := httpapi.NewQueryParamParser()
+	endTime := parser.Time(r.URL.Query(), now, "end_time", time.RFC3339)
+	startTime := parser.Time(r.URL.Query(), endTime.Add(-metricscache.InsightsRetention), "start_time", time.RFC3339)
+	format := parser.String(r.URL.Query(), "json", "format")
+	if len(parser.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: parser.Errors,
+		})
+		return
+	}
+	if format != "json" && format != "csv" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Unsupported format %q, expected \"json\" or \"csv\".", format),
+		})
+		return
+	}
+	if !endTime.After(startTime) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The end time must be after the start time.",
+		})
+		return
+	}
+
+	// Connected time is counted in report intervals, which are never shorter
+	// than a second outside of tests.
+	reportInterval := int64(api.AgentStatsRefreshInterval / time.Second)
+	if reportInterval < 1 {
+		reportInterval = 1
+	}
+	rows, err := api.Database.GetUserActivityInsights(ctx, database.GetUserActivityInsightsParams{
+		ReportIntervalSeconds: reportInterval,
+		StartTime:             startTime,
+		EndTime:               endTime,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching user activity.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	resp := codersdk.UserInsightsResponse{
+		StartTime: startTime,
+		EndTime:   endTime,
+		Users:     make([]codersdk.UserInsights, 0, len(rows)),
+	}
+	for _, row := range rows {
+		resp.Users = append(resp.Users, codersdk.UserInsights{
+			UserID:           row.UserID,
+			Username:         row.Username,
+			Email:            row.Email,
+			Status:           codersdk.UserStatus(row.Status),
+			WorkspacesOwned:  row.Workspaces,
+			Builds:           row.Builds,
+			ConnectedSeconds: row.ConnectedSeconds,
+			CostAccrued:      row.Cost,
+			LastSeenAt:       row.LastSeenAt,
+		})
+	}
+
+	if format == "csv" {
+		rw.Header().Set("Content-Type", "text/csv")
+		rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q",
+			fmt.Sprintf("users-%s-%s.csv", startTime.Format("2006-01-02"), endTime.Format("2006-01-02"))))
+		rw.WriteHeader(http.StatusOK)
+		_ = resp.WriteCSV(rw)
+		return
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, resp)
+}
@@ -2,6 +2,8 @@ package coderd_test
 
 import (
 	"context"
+	"encoding/csv"
+	"net/http"
 	"testing"
 	"time"
 
@@ -12,6 +14,8 @@ import (
 	"cdr.dev/slog/sloggers/slogtest"
 	"github.com/coder/coder/agent"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbtestutil"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/codersdk/agentsdk"
 	"github.com/coder/coder/provisioner/echo"
@@ -120,3 +124,296 @@ func TestDeploymentInsights(t *testing.T) {
 	res, err = client.Workspaces(ctx, codersdk.WorkspaceFilter{})
 	require.NoError(t, err)
 }
+
+func TestTemplateInsights(t *testing.T) {
+	t.Parallel()
+
+	client := coderdtest.New(t, &coderdtest.Options{
+		IncludeProvisionerDaemon:    true,
+		MetricsCacheRefreshInterval: time.Millisecond * 100,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	authToken := uuid.NewString()
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:         echo.ParseComplete,
+		ProvisionPlan: echo.ProvisionComplete,
+		ProvisionApply: []*proto.Provision_Response{{
+			Type: &proto.Provision_Response_Complete{
+				Complete: &proto.Provision_Complete{
+					Resources: []*proto.Resource{{
+						Name: "example",
+						Type: "aws_instance",
+						Agents: []*proto.Agent{{
+							Id: uuid.NewString(),
+							Auth: &proto.Agent_Token{
+								Token: authToken,
+							},
+							Apps: []*proto.App{{
+								Slug:        "code-server",
+								DisplayName: "code-server",
+								Url:         "http://localhost:13337",
+							}},
+						}},
+					}},
+				},
+			},
+		}},
+	})
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	agentClient := agentsdk.New(client.URL)
+	agentClient.SetSessionToken(authToken)
+	_, err := agentClient.PostStats(ctx, &agentsdk.Stats{
+		ConnectionsByProto: map[string]int64{"TCP": 7},
+		ConnectionsByPort: map[string]int64{
+			"1":     2,
+			"2":     1,
+			"4":     5,
+			"13337": 3,
+			"8000":  1,
+		},
+		ConnectionCount: 7,
+		RxBytes:         1,
+		TxBytes:         1,
+	})
+	require.NoError(t, err)
+
+	var insights codersdk.TemplateInsightsResponse
+	require.Eventually(t, func() bool {
+		insights, err = client.TemplateInsights(ctx, template.ID, codersdk.TemplateInsightsRequest{})
+		require.NoError(t, err)
+		return len(insights.Apps) > 0 && insights.Apps[0].Connections > 0
+	}, testutil.WaitShort, testutil.IntervalFast, "insights never loaded")
+
+	require.Equal(t, []codersdk.TemplateConnectionUsage{
+		{Type: codersdk.TemplateConnectionTypeSSH, Connections: 2},
+		{Type: codersdk.TemplateConnectionTypeWebTerminal, Connections: 1},
+		{Type: codersdk.TemplateConnectionTypeApp, Connections: 3},
+		{Type: codersdk.TemplateConnectionTypePortForward, Connections: 1},
+	}, insights.ConnectionTypes)
+	require.Equal(t, []codersdk.TemplateAppUsage{{
+		Slug:        "code-server",
+		DisplayName: "code-server",
+		Connections: 3,
+	}}, insights.Apps)
+
+	require.Len(t, insights.Builds, 3)
+	start := insights.Builds[0]
+	require.Equal(t, codersdk.WorkspaceTransitionStart, start.Transition)
+	require.EqualValues(t, 1, start.Total)
+	require.Zero(t, start.Failed)
+	require.NotNil(t, start.P50)
+
+	today := time.Now().UTC().Truncate(time.Hour * 24)
+	require.NotEmpty(t, insights.ActiveWorkspaces)
+	require.Equal(t, codersdk.DAUEntry{Date: today, Amount: 1}, insights.ActiveWorkspaces[len(insights.ActiveWorkspaces)-1])
+
+	require.Equal(t, []codersdk.TemplateVersionAdoption{{
+		TemplateVersionID: version.ID,
+		Name:              version.Name,
+		Active:            true,
+		Workspaces:        1,
+	}}, insights.VersionAdoption)
+
+	t.Run("Range", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		yesterday := today.Add(-time.Hour * 24)
+		insights, err := client.TemplateInsights(ctx, template.ID, codersdk.TemplateInsightsRequest{
+			StartTime: yesterday.Add(-time.Hour * 24),
+			EndTime:   yesterday.Add(time.Hour),
+		})
+		require.NoError(t, err)
+		require.Equal(t, yesterday.Add(-time.Hour*24), insights.StartTime.UTC())
+		require.Equal(t, today, insights.EndTime.UTC())
+		require.Len(t, insights.ActiveWorkspaces, 2)
+		require.EqualValues(t, 0, insights.Builds[0].Total)
+		require.EqualValues(t, 0, insights.ConnectionTypes[0].Connections)
+	})
+
+	t.Run("EndBeforeStart", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.TemplateInsights(ctx, template.ID, codersdk.TemplateInsightsRequest{
+			StartTime: today,
+			EndTime:   today.Add(-time.Hour),
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("MemberForbidden", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		_, err := member.TemplateInsights(ctx, template.ID, codersdk.TemplateInsightsRequest{})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
+
+func TestUserInsights(t *testing.T) {
+	t.Parallel()
+
+	db, pubsub := dbtestutil.NewDB(t)
+	client := coderdtest.New(t, &coderdtest.Options{
+		IncludeProvisionerDaemon: true,
+		Database:                 db,
+		Pubsub:                   pubsub,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+	authToken := uuid.NewString()
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:         echo.ParseComplete,
+		ProvisionPlan: echo.ProvisionComplete,
+		ProvisionApply: []*proto.Provision_Response{{
+			Type: &proto.Provision_Response_Complete{
+				Complete: &proto.Provision_Complete{
+					Resources: []*proto.Resource{{
+						Name: "example",
+						Type: "aws_instance",
+						Agents: []*proto.Agent{{
+							Id: uuid.NewString(),
+							Auth: &proto.Agent_Token{
+								Token: authToken,
+							},
+						}},
+					}},
+				},
+			},
+		}},
+	})
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	// Build costs are only set when quotas are enabled.
+	_, err := db.UpdateWorkspaceBuildCostByID(ctx, database.UpdateWorkspaceBuildCostByIDParams{
+		ID:        workspace.LatestBuild.ID,
+		DailyCost: 24,
+	})
+	require.NoError(t, err)
+
+	agentClient := agentsdk.New(client.URL)
+	agentClient.SetSessionToken(authToken)
+	_, err = agentClient.PostStats(ctx, &agentsdk.Stats{
+		ConnectionsByProto: map[string]int64{"TCP": 1},
+		ConnectionCount:    1,
+		RxBytes:            1,
+		TxBytes:            1,
+	})
+	require.NoError(t, err)
+
+	insights, err := client.UserInsights(ctx, codersdk.UserInsightsRequest{})
+	require.NoError(t, err)
+	require.Len(t, insights.Users, 2)
+	require.WithinDuration(t, time.Now(), insights.EndTime, time.Minute)
+	require.WithinDuration(t, time.Now().Add(-30*24*time.Hour), insights.StartTime, time.Minute)
+
+	me, err := client.User(ctx, codersdk.Me)
+	require.NoError(t, err)
+	var owner codersdk.UserInsights
+	for _, u := range insights.Users {
+		if u.UserID == me.ID {
+			owner = u
+		} else {
+			require.Zero(t, u.WorkspacesOwned)
+			require.Zero(t, u.Builds)
+			require.Zero(t, u.ConnectedSeconds)
+		}
+	}
+	require.Equal(t, me.Username, owner.Username)
+	require.EqualValues(t, 1, owner.WorkspacesOwned)
+	require.EqualValues(t, 1, owner.Builds)
+	// The default report interval is 5 minutes.
+	require.EqualValues(t, 300, owner.ConnectedSeconds)
+	// The workspace has only been running for a few seconds at 24 a day.
+	require.Greater(t, owner.CostAccrued, 0.0)
+	require.Less(t, owner.CostAccrued, 1.0)
+
+	t.Run("Range", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		end := time.Now().Add(-time.Hour)
+		insights, err := client.UserInsights(ctx, codersdk.UserInsightsRequest{
+			StartTime: end.Add(-time.Hour),
+			EndTime:   end,
+		})
+		require.NoError(t, err)
+		for _, u := range insights.Users {
+			require.Zero(t, u.Builds)
+			require.Zero(t, u.ConnectedSeconds)
+		}
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		res, err := client.Request(ctx, http.MethodGet, "/api/v2/insights/users?format=csv", nil)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		require.Equal(t, "text/csv", res.Header.Get("Content-Type"))
+		records, err := csv.NewReader(res.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		require.Equal(t, "username", records[0][0])
+		require.Equal(t, "hours_connected", records[0][5])
+	})
+
+	t.Run("EndBeforeStart", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		now := time.Now()
+		_, err := client.UserInsights(ctx, codersdk.UserInsightsRequest{
+			StartTime: now,
+			EndTime:   now.Add(-time.Hour),
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("MemberForbidden", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := member.UserInsights(ctx, codersdk.UserInsightsRequest{})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+}
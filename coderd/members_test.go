@@ -0,0 +1,45 @@
+package coderd_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestPutMemberRoles(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Audit", func(t *testing.T) {
+		t.Parallel()
+
+		auditor := audit.NewMock()
+		client := coderdtest.New(t, &coderdtest.Options{Auditor: auditor})
+		first := coderdtest.CreateFirstUser(t, client)
+		_, member := coderdtest.CreateAnotherUser(t, client, first.OrganizationID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		numLogs := len(auditor.AuditLogs)
+		_, err := client.UpdateOrganizationMemberRoles(ctx, first.OrganizationID, member.ID.String(), codersdk.UpdateRoles{
+			Roles: []string{rbac.RoleOrgAdmin(first.OrganizationID)},
+		})
+		require.NoError(t, err)
+		numLogs++ // add an audit log for the role change
+
+		require.Len(t, auditor.AuditLogs, numLogs)
+		alog := auditor.AuditLogs[numLogs-1]
+		require.Equal(t, database.AuditActionWrite, alog.Action)
+		require.Equal(t, database.ResourceTypeOrganizationMember, alog.ResourceType)
+		require.Equal(t, member.ID, alog.ResourceID)
+		require.Equal(t, member.Username, alog.ResourceTarget)
+	})
+}
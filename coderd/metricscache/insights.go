@@ -0,0 +1,343 @@
+package metricscache
+
+import (
+	"context"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+)
+
+// InsightsRetention is how far back template insights are aggregated. Agent
+// stats are deleted after 30 days, so connections can't be counted further
+// back than that anyway.
+const InsightsRetention = 30 * 24 * time.Hour
+
+const day = 24 * time.Hour
+
+var (
+	buildTransitions = []database.WorkspaceTransition{
+		database.WorkspaceTransitionStart,
+		database.WorkspaceTransitionStop,
+		database.WorkspaceTransitionDelete,
+	}
+	connectionTypes = []codersdk.TemplateConnectionType{
+		codersdk.TemplateConnectionTypeSSH,
+		codersdk.TemplateConnectionTypeWebTerminal,
+		codersdk.TemplateConnectionTypeApp,
+		codersdk.TemplateConnectionTypePortForward,
+	}
+)
+
+// templateInsights holds the insights of a template by day, so any range
+// within the retention can be served without querying the database.
+type templateInsights struct {
+	days map[time.Time]*templateInsightsDay
+	// apps are the apps of the template's recent workspaces by slug.
+	apps            map[string]database.GetTemplateInsightsAppsRow
+	versionAdoption []codersdk.TemplateVersionAdoption
+}
+
+type templateInsightsDay struct {
+	// buildTimes are the durations of successful builds in seconds. They're
+	// kept whole because percentiles can't be combined across days.
+	buildTimes       map[database.WorkspaceTransition][]float64
+	builds           map[database.WorkspaceTransition]int64
+	failedBuilds     map[database.WorkspaceTransition]int64
+	connections      map[codersdk.TemplateConnectionType]int64
+	appConnections   map[string]int64
+	activeWorkspaces int64
+}
+
+func (t *templateInsights) day(date time.Time) *templateInsightsDay {
+	d, ok := t.days[date]
+	if !ok {
+		d = &templateInsightsDay{
+			buildTimes:     make(map[database.WorkspaceTransition][]float64),
+			builds:         make(map[database.WorkspaceTransition]int64),
+			failedBuilds:   make(map[database.WorkspaceTransition]int64),
+			connections:    make(map[codersdk.TemplateConnectionType]int64),
+			appConnections: make(map[string]int64),
+		}
+		t.days[date] = d
+	}
+	return d
+}
+
+// refreshTemplateInsights aggregates the insights of every template. Each
+// query covers all templates at once, so the cost doesn't grow with the
+// number of templates.
+func (c *Cache) refreshTemplateInsights(ctx context.Context, templates []database.Template) error {
+	since := time.Now().UTC().Truncate(day).Add(-InsightsRetention)
+
+	insights := make(map[uuid.UUID]*templateInsights, len(templates))
+	get := func(templateID uuid.UUID) *templateInsights {
+		t, ok := insights[templateID]
+		if !ok {
+			t = &templateInsights{
+				days: make(map[time.Time]*templateInsightsDay),
+				apps: make(map[string]database.GetTemplateInsightsAppsRow),
+			}
+			insights[templateID] = t
+		}
+		return t
+	}
+	activeVersions := make(map[uuid.UUID]uuid.UUID, len(templates))
+	for _, template := range templates {
+		_ = get(template.ID)
+		activeVersions[template.ID] = template.ActiveVersionID
+	}
+
+	builds, err := c.database.GetTemplateBuildInsights(ctx, since)
+	if err != nil {
+		return xerrors.Errorf("get template build insights: %w", err)
+	}
+	for _, build := range builds {
+		d := get(build.TemplateID.UUID).day(build.Date)
+		d.builds[build.Transition]++
+		if build.Failed {
+			d.failedBuilds[build.Transition]++
+			continue
+		}
+		d.buildTimes[build.Transition] = append(d.buildTimes[build.Transition], build.ExecTimeSec)
+	}
+
+	apps, err := c.database.GetTemplateInsightsApps(ctx, since)
+	if err != nil {
+		return xerrors.Errorf("get template insights apps: %w", err)
+	}
+	appPorts := make(map[uuid.UUID]map[string]string)
+	for _, app := range apps {
+		get(app.TemplateID).apps[app.Slug] = app
+		port, ok := appPort(app.Url.String)
+		if !ok {
+			continue
+		}
+		if appPorts[app.TemplateID] == nil {
+			appPorts[app.TemplateID] = make(map[string]string)
+		}
+		// When apps share a port, the first one by slug is credited.
+		if _, exists := appPorts[app.TemplateID][port]; !exists {
+			appPorts[app.TemplateID][port] = app.Slug
+		}
+	}
+
+	connections, err := c.database.GetTemplateConnectionInsights(ctx, since)
+	if err != nil {
+		return xerrors.Errorf("get template connection insights: %w", err)
+	}
+	for _, conn := range connections {
+		connType, slug, ok := classifyPort(conn.Port, appPorts[conn.TemplateID])
+		if !ok {
+			continue
+		}
+		d := get(conn.TemplateID).day(conn.Date)
+		d.connections[connType] += conn.Connections
+		if slug != "" {
+			d.appConnections[slug] += conn.Connections
+		}
+	}
+
+	activeWorkspaces, err := c.database.GetTemplateActiveWorkspaces(ctx, since)
+	if err != nil {
+		return xerrors.Errorf("get template active workspaces: %w", err)
+	}
+	for _, row := range activeWorkspaces {
+		get(row.TemplateID).day(row.Date).activeWorkspaces = row.Workspaces
+	}
+
+	adoption, err := c.database.GetTemplateVersionAdoption(ctx)
+	if err != nil {
+		return xerrors.Errorf("get template version adoption: %w", err)
+	}
+	for _, row := range adoption {
+		t := get(row.TemplateID.UUID)
+		t.versionAdoption = append(t.versionAdoption, codersdk.TemplateVersionAdoption{
+			TemplateVersionID: row.TemplateVersionID,
+			Name:              row.Name,
+			Active:            activeVersions[row.TemplateID.UUID] == row.TemplateVersionID,
+			Workspaces:        row.Workspaces,
+		})
+	}
+
+	c.templateInsights.Store(&insights)
+	return nil
+}
+
+// appPort returns the port an app is served on by the agent.
+func appPort(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+	if port := u.Port(); port != "" {
+		return port, true
+	}
+	switch u.Scheme {
+	case "http":
+		return "80", true
+	case "https":
+		return "443", true
+	}
+	return "", false
+}
+
+// classifyPort returns how a connection to an agent port was made, and the
+// slug of the app it was made to if any. Ports the agent uses internally
+// aren't counted.
+func classifyPort(port string, appPorts map[string]string) (codersdk.TemplateConnectionType, string, bool) {
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return "", "", false
+	}
+	switch p {
+	case codersdk.WorkspaceAgentSSHPort:
+		return codersdk.TemplateConnectionTypeSSH, "", true
+	case codersdk.WorkspaceAgentReconnectingPTYPort:
+		return codersdk.TemplateConnectionTypeWebTerminal, "", true
+	}
+	if p < codersdk.WorkspaceAgentMinimumListeningPort {
+		return "", "", false
+	}
+	if slug, ok := appPorts[port]; ok {
+		return codersdk.TemplateConnectionTypeApp, slug, true
+	}
+	return codersdk.TemplateConnectionTypePortForward, "", true
+}
+
+// TemplateInsights returns the insights of a template for the days from
+// start up to end. The range is rounded out to whole days, and limited to
+// the retention. It returns false while the insights are loading for the
+// first time.
+func (c *Cache) TemplateInsights(id uuid.UUID, start, end time.Time) (codersdk.TemplateInsightsResponse, bool) {
+	now := time.Now().UTC()
+	if end.IsZero() || end.After(now) {
+		end = now
+	}
+	end = end.UTC()
+	if midnight := end.Truncate(day); !midnight.Equal(end) {
+		end = midnight.Add(day)
+	}
+	oldest := now.Truncate(day).Add(-InsightsRetention)
+	if start.IsZero() || start.Before(oldest) {
+		start = oldest
+	}
+	start = start.UTC().Truncate(day)
+	if start.After(end) {
+		start = end
+	}
+
+	resp := codersdk.TemplateInsightsResponse{
+		StartTime:        start,
+		EndTime:          end,
+		Builds:           make([]codersdk.TemplateBuildInsights, 0, len(buildTransitions)),
+		ConnectionTypes:  make([]codersdk.TemplateConnectionUsage, 0, len(connectionTypes)),
+		Apps:             []codersdk.TemplateAppUsage{},
+		ActiveWorkspaces: []codersdk.DAUEntry{},
+		VersionAdoption:  []codersdk.TemplateVersionAdoption{},
+	}
+
+	m := c.templateInsights.Load()
+	if m == nil {
+		// Data loading.
+		return resp, false
+	}
+	insights, ok := (*m)[id]
+	if !ok {
+		insights = &templateInsights{}
+	}
+
+	var (
+		buildTimes     = make(map[database.WorkspaceTransition][]float64)
+		builds         = make(map[database.WorkspaceTransition]int64)
+		failedBuilds   = make(map[database.WorkspaceTransition]int64)
+		connections    = make(map[codersdk.TemplateConnectionType]int64)
+		appConnections = make(map[string]int64)
+	)
+	for date := start; date.Before(end); date = date.Add(day) {
+		d, ok := insights.days[date]
+		if !ok {
+			resp.ActiveWorkspaces = append(resp.ActiveWorkspaces, codersdk.DAUEntry{Date: date})
+			continue
+		}
+		resp.ActiveWorkspaces = append(resp.ActiveWorkspaces, codersdk.DAUEntry{
+			Date:   date,
+			Amount: int(d.activeWorkspaces),
+		})
+		for transition, times := range d.buildTimes {
+			buildTimes[transition] = append(buildTimes[transition], times...)
+		}
+		for transition, count := range d.builds {
+			builds[transition] += count
+		}
+		for transition, count := range d.failedBuilds {
+			failedBuilds[transition] += count
+		}
+		for connType, count := range d.connections {
+			connections[connType] += count
+		}
+		for slug, count := range d.appConnections {
+			appConnections[slug] += count
+		}
+	}
+
+	for _, transition := range buildTransitions {
+		insight := codersdk.TemplateBuildInsights{
+			Transition: codersdk.WorkspaceTransition(transition),
+			P50:        percentileMillis(buildTimes[transition], 0.5),
+			P95:        percentileMillis(buildTimes[transition], 0.95),
+			Total:      builds[transition],
+			Failed:     failedBuilds[transition],
+		}
+		if insight.Total > 0 {
+			insight.FailureRate = float64(insight.Failed) / float64(insight.Total)
+		}
+		resp.Builds = append(resp.Builds, insight)
+	}
+	for _, connType := range connectionTypes {
+		resp.ConnectionTypes = append(resp.ConnectionTypes, codersdk.TemplateConnectionUsage{
+			Type:        connType,
+			Connections: connections[connType],
+		})
+	}
+	for slug, app := range insights.apps {
+		resp.Apps = append(resp.Apps, codersdk.TemplateAppUsage{
+			Slug:        slug,
+			DisplayName: app.DisplayName,
+			Icon:        app.Icon,
+			Connections: appConnections[slug],
+		})
+	}
+	sort.Slice(resp.Apps, func(i, j int) bool {
+		if resp.Apps[i].Connections != resp.Apps[j].Connections {
+			return resp.Apps[i].Connections > resp.Apps[j].Connections
+		}
+		return resp.Apps[i].Slug < resp.Apps[j].Slug
+	})
+	resp.VersionAdoption = append(resp.VersionAdoption, insights.versionAdoption...)
+
+	return resp, true
+}
+
+// percentileMillis returns the p-th percentile of the durations in
+// milliseconds, using the same definition as PERCENTILE_DISC in Postgres.
+func percentileMillis(seconds []float64, p float64) *int64 {
+	if len(seconds) == 0 {
+		return nil
+	}
+	sorted := append([]float64(nil), seconds...)
+	sort.Float64s(sorted)
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	ms := int64(sorted[i] * 1000)
+	return &ms
+}
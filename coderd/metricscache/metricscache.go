@@ -32,6 +32,7 @@ type Cache struct {
 	templateDAUResponses     atomic.Pointer[map[uuid.UUID]codersdk.TemplateDAUsResponse]
 	templateUniqueUsers      atomic.Pointer[map[uuid.UUID]int]
 	templateAverageBuildTime atomic.Pointer[map[uuid.UUID]database.GetTemplateAverageBuildTimeRow]
+	templateInsights         atomic.Pointer[map[uuid.UUID]*templateInsights]
 
 	done   chan struct{}
 	cancel func()
@@ -196,7 +197,7 @@ func (c *Cache) refresh(ctx context.Context) error {
 	c.templateUniqueUsers.Store(&templateUniqueUsers)
 	c.templateAverageBuildTime.Store(&templateAverageBuildTimes)
 
-	return nil
+	return c.refreshTemplateInsights(ctx, templates)
 }
 
 func (c *Cache) run(ctx context.Context) {
@@ -315,6 +315,7 @@ func TestCache_BuildTime(t *testing.T) {
 					Provisioner:   database.ProvisionerTypeEcho,
 					StorageMethod: database.ProvisionerStorageMethodFile,
 					Type:          database.ProvisionerJobTypeWorkspaceBuild,
+					Priority:      database.ProvisionerJobPriorityInteractive,
 				})
 				require.NoError(t, err)
 
@@ -373,3 +374,84 @@ func TestCache_BuildTime(t *testing.T) {
 		})
 	}
 }
+
+func TestCache_TemplateInsights(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	var (
+		db    = dbfake.New()
+		cache = metricscache.New(db, slogtest.Make(t, nil), testutil.IntervalFast)
+	)
+	defer cache.Close()
+
+	template := dbgen.Template(t, db, database.Template{})
+	version := dbgen.TemplateVersion(t, db, database.TemplateVersion{
+		TemplateID: uuid.NullUUID{UUID: template.ID, Valid: true},
+	})
+
+	now := database.Now()
+	for _, build := range []struct {
+		took   time.Duration
+		failed bool
+	}{
+		{took: 10 * time.Second},
+		{took: 30 * time.Second},
+		{took: 20 * time.Second},
+		{took: 5 * time.Second, failed: true},
+	} {
+		_, err := db.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+			ID:            uuid.New(),
+			Provisioner:   database.ProvisionerTypeEcho,
+			StorageMethod: database.ProvisionerStorageMethodFile,
+			Type:          database.ProvisionerJobTypeWorkspaceBuild,
+			Priority:      database.ProvisionerJobPriorityInteractive,
+		})
+		require.NoError(t, err)
+		job, err := db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
+			StartedAt: sql.NullTime{Time: now, Valid: true},
+			Types:     []database.ProvisionerType{database.ProvisionerTypeEcho},
+		})
+		require.NoError(t, err)
+		_ = dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+			TemplateVersionID: version.ID,
+			JobID:             job.ID,
+			Transition:        database.WorkspaceTransitionStart,
+		})
+		completed := database.UpdateProvisionerJobWithCompleteByIDParams{
+			ID:          job.ID,
+			CompletedAt: sql.NullTime{Time: now.Add(build.took), Valid: true},
+		}
+		if build.failed {
+			completed.Error = sql.NullString{String: "failed", Valid: true}
+		}
+		require.NoError(t, db.UpdateProvisionerJobWithCompleteByID(ctx, completed))
+	}
+
+	var insights codersdk.TemplateInsightsResponse
+	require.Eventually(t, func() bool {
+		var ok bool
+		insights, ok = cache.TemplateInsights(template.ID, time.Time{}, time.Time{})
+		return ok && insights.Builds[0].Total > 0
+	}, testutil.WaitShort, testutil.IntervalFast)
+
+	start := insights.Builds[0]
+	require.Equal(t, codersdk.WorkspaceTransitionStart, start.Transition)
+	require.EqualValues(t, 4, start.Total)
+	require.EqualValues(t, 1, start.Failed)
+	require.Equal(t, 0.25, start.FailureRate)
+	require.EqualValues(t, 20*1000, *start.P50)
+	require.EqualValues(t, 30*1000, *start.P95)
+	require.Nil(t, insights.Builds[1].P50)
+
+	// A range without builds has none of today's.
+	yesterday := now.UTC().Truncate(24 * time.Hour).Add(-24 * time.Hour)
+	insights, ok := cache.TemplateInsights(template.ID, yesterday, yesterday.Add(time.Hour))
+	require.True(t, ok)
+	require.Equal(t, yesterday, insights.StartTime)
+	require.Equal(t, yesterday.Add(24*time.Hour), insights.EndTime)
+	require.Zero(t, insights.Builds[0].Total)
+	require.Len(t, insights.ActiveWorkspaces, 1)
+}
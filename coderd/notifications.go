@@ -0,0 +1,133 @@
+package coderd
+
+import (
+	"net/http"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+// @Summary Get user notification preferences
+// @ID get-user-notification-preferences
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Success 200 {array} codersdk.NotificationPreference
+// @Router /users/{user}/notifications/preferences [get]
+func (api *API) notificationPreferences(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := httpmw.UserParam(r)
+
+	if !api.Authorize(r, rbac.ActionRead, user.UserDataRBACObject()) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	preferences, err := api.Database.GetNotificationPreferencesByUserID(ctx, user.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching notification preferences.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertNotificationPreferences(preferences))
+}
+
+// @Summary Update user notification preferences
+// @ID update-user-notification-preferences
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Users
+// @Param user path string true "User ID, name, or me"
+// @Param request body codersdk.UpdateNotificationPreferencesRequest true "Notification preferences"
+// @Success 200 {array} codersdk.NotificationPreference
+// @Router /users/{user}/notifications/preferences [put]
+func (api *API) putNotificationPreferences(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user := httpmw.UserParam(r)
+
+	if !api.Authorize(r, rbac.ActionUpdate, user.UserDataRBACObject()) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpdateNotificationPreferencesRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	for _, preference := range req.Preferences {
+		if !preference.Kind.Valid() {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Invalid notification kind.",
+				Validations: []codersdk.ValidationError{{
+					Field:  "preferences",
+					Detail: "Unknown notification kind " + string(preference.Kind),
+				}},
+			})
+			return
+		}
+	}
+
+	err := api.Database.InTx(func(tx database.Store) error {
+		for _, preference := range req.Preferences {
+			_, err := tx.UpsertNotificationPreference(ctx, database.UpsertNotificationPreferenceParams{
+				UserID:    user.ID,
+				Kind:      string(preference.Kind),
+				Disabled:  !preference.Enabled,
+				UpdatedAt: database.Now(),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating notification preferences.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	preferences, err := api.Database.GetNotificationPreferencesByUserID(ctx, user.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching notification preferences.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertNotificationPreferences(preferences))
+}
+
+// convertNotificationPreferences returns a preference for every kind, kinds
+// the user has not changed are enabled.
+func convertNotificationPreferences(preferences []database.NotificationPreference) []codersdk.NotificationPreference {
+	stored := make(map[codersdk.NotificationKind]database.NotificationPreference, len(preferences))
+	for _, preference := range preferences {
+		stored[codersdk.NotificationKind(preference.Kind)] = preference
+	}
+	converted := make([]codersdk.NotificationPreference, 0, len(codersdk.NotificationKinds))
+	for _, kind := range codersdk.NotificationKinds {
+		preference := codersdk.NotificationPreference{
+			Kind:    kind,
+			Enabled: true,
+		}
+		if p, ok := stored[kind]; ok {
+			updatedAt := p.UpdatedAt
+			preference.Enabled = !p.Disabled
+			preference.UpdatedAt = &updatedAt
+		}
+		converted = append(converted, preference)
+	}
+	return converted
+}
@@ -0,0 +1,266 @@
+// Package notifications emails users about events in their workspaces and
+// accounts.
+//
+// Any replica may decide to send a notification, e.g. every replica's
+// autobuild executor sees a workspace approaching its deadline. Before a
+// message is sent it is recorded under a dedupe key, and the database only
+// accepts one record per key, so each notification is sent once.
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"embed"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/codersdk"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// templates contains a "subject" and a "body" template for every
+// notification kind, parsed from templates/<kind>.tmpl.
+var templates = func() map[codersdk.NotificationKind]*template.Template {
+	parsed := make(map[codersdk.NotificationKind]*template.Template, len(codersdk.NotificationKinds))
+	for _, kind := range codersdk.NotificationKinds {
+		parsed[kind] = template.Must(template.ParseFS(templateFS, "templates/"+string(kind)+".tmpl"))
+	}
+	return parsed
+}()
+
+// Message is an email to a single recipient.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers messages, e.g. through an SMTP server.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Notification is sent to a single user.
+type Notification struct {
+	UserID uuid.UUID
+	Kind   codersdk.NotificationKind
+	// DedupeKey identifies the notification across replicas, it is sent at
+	// most once per key. If empty, the notification is always sent.
+	DedupeKey string
+	// Data is passed to the template of the kind, e.g. "workspace_name".
+	Data map[string]string
+}
+
+// Options configure a Notifier.
+type Options struct {
+	Database database.Store
+	Logger   slog.Logger
+	// Sender delivers messages. If nil, notifications are disabled.
+	Sender Sender
+	// AccessURL is used to link to the deployment in messages.
+	AccessURL *url.URL
+	// Timeout bounds sending an enqueued notification, default 30s.
+	Timeout time.Duration
+}
+
+// Notifier renders notifications and sends them to users that have not
+// opted out of their kind.
+type Notifier struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	opts   Options
+
+	// mu guards closed so no notification is enqueued after Close begins
+	// waiting.
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// New returns a Notifier. It must be closed to wait for enqueued
+// notifications.
+func New(opts Options) *Notifier {
+	if opts.AccessURL == nil {
+		opts.AccessURL = &url.URL{}
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	//nolint:gocritic // The notifier reads the preferences of every user.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	return &Notifier{
+		ctx:    ctx,
+		cancel: cancel,
+		opts:   opts,
+	}
+}
+
+// Enabled returns whether notifications are sent at all.
+func (n *Notifier) Enabled() bool {
+	return n.opts.Sender != nil
+}
+
+// Enqueue sends the notification in the background, so callers are not
+// blocked by a slow mail server. Errors are logged.
+func (n *Notifier) Enqueue(notification Notification) {
+	if !n.Enabled() {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.closed {
+		return
+	}
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		ctx, cancel := context.WithTimeout(n.ctx, n.opts.Timeout)
+		defer cancel()
+		err := n.Send(ctx, notification)
+		if err != nil && !xerrors.Is(err, context.Canceled) {
+			n.opts.Logger.Warn(ctx, "send notification",
+				slog.F("user_id", notification.UserID),
+				slog.F("kind", notification.Kind),
+				slog.Error(err),
+			)
+		}
+	}()
+}
+
+// Send renders the notification and sends it, unless the user has opted
+// out of its kind or it was already sent.
+func (n *Notifier) Send(ctx context.Context, notification Notification) error {
+	if !n.Enabled() {
+		return nil
+	}
+	//nolint:gocritic // The notifier reads the preferences of every user.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+
+	user, err := n.opts.Database.GetUserByID(ctx, notification.UserID)
+	if err != nil {
+		return xerrors.Errorf("get user: %w", err)
+	}
+	if user.Deleted {
+		return nil
+	}
+	if user.Status == database.UserStatusSuspended && !isAccountKind(notification.Kind) {
+		return nil
+	}
+	preferences, err := n.opts.Database.GetNotificationPreferencesByUserID(ctx, user.ID)
+	if err != nil {
+		return xerrors.Errorf("get notification preferences: %w", err)
+	}
+	for _, preference := range preferences {
+		if preference.Kind == string(notification.Kind) && preference.Disabled {
+			return nil
+		}
+	}
+
+	msg, err := Render(notification.Kind, TemplateData{
+		Username:  user.Username,
+		AccessURL: strings.TrimSuffix(n.opts.AccessURL.String(), "/"),
+		Data:      notification.Data,
+	})
+	if err != nil {
+		return err
+	}
+	msg.To = user.Email
+
+	dedupeKey := notification.DedupeKey
+	if dedupeKey == "" {
+		dedupeKey = uuid.NewString()
+	}
+	claimed, err := n.opts.Database.InsertNotificationMessage(ctx, database.InsertNotificationMessageParams{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Kind:      string(notification.Kind),
+		DedupeKey: string(notification.Kind) + ":" + dedupeKey,
+		Subject:   msg.Subject,
+		CreatedAt: database.Now(),
+	})
+	if xerrors.Is(err, sql.ErrNoRows) {
+		// Another replica has sent or is sending it.
+		return nil
+	}
+	if err != nil {
+		return xerrors.Errorf("insert notification message: %w", err)
+	}
+
+	err = n.opts.Sender.Send(ctx, msg)
+	if err != nil {
+		// Release the claim, so the notification is retried if the
+		// caller sends it again.
+		deleteErr := n.opts.Database.DeleteNotificationMessageByID(ctx, claimed.ID)
+		if deleteErr != nil {
+			n.opts.Logger.Warn(ctx, "delete unsent notification message", slog.F("id", claimed.ID), slog.Error(deleteErr))
+		}
+		return xerrors.Errorf("send message: %w", err)
+	}
+	return nil
+}
+
+// Close waits for enqueued notifications to be sent.
+func (n *Notifier) Close() error {
+	n.mu.Lock()
+	n.closed = true
+	n.mu.Unlock()
+	n.wg.Wait()
+	n.cancel()
+	return nil
+}
+
+// TemplateData is passed to notification templates.
+type TemplateData struct {
+	Username  string
+	AccessURL string
+	Data      map[string]string
+}
+
+// Render executes the templates of the kind. The returned message has no
+// recipient.
+func Render(kind codersdk.NotificationKind, data TemplateData) (Message, error) {
+	tmpl, ok := templates[kind]
+	if !ok {
+		return Message{}, xerrors.Errorf("unknown notification kind %q", kind)
+	}
+	var subject, body bytes.Buffer
+	err := tmpl.ExecuteTemplate(&subject, "subject", data)
+	if err != nil {
+		return Message{}, xerrors.Errorf("render subject: %w", err)
+	}
+	err = tmpl.ExecuteTemplate(&body, "body", data)
+	if err != nil {
+		return Message{}, xerrors.Errorf("render body: %w", err)
+	}
+	return Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    strings.TrimSpace(body.String()) + "\n",
+	}, nil
+}
+
+// isAccountKind returns whether the kind is about the account itself, which
+// suspended users are still told about.
+func isAccountKind(kind codersdk.NotificationKind) bool {
+	switch kind {
+	case codersdk.NotificationKindAccountCreated,
+		codersdk.NotificationKindAccountSuspended,
+		codersdk.NotificationKindAccountActivated:
+		return true
+	default:
+		return false
+	}
+}
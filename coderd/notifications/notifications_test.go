@@ -0,0 +1,274 @@
+package notifications_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbfake"
+	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/coderd/notifications"
+	"github.com/coder/coder/coderd/notifications/notificationstest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestNotifier(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Sends", func(t *testing.T) {
+		t.Parallel()
+
+		db := dbfake.New()
+		user := dbgen.User(t, db, database.User{Email: "alice@coder.com", Username: "alice"})
+		smtp := notificationstest.NewSMTPServer(t)
+		notifier := newNotifier(t, db, smtp)
+
+		ctx, _ := testutil.Context(t)
+		err := notifier.Send(ctx, notifications.Notification{
+			UserID: user.ID,
+			Kind:   codersdk.NotificationKindWorkspaceBuildFailed,
+			Data: map[string]string{
+				"workspace_name": "dev",
+				"transition":     "start",
+				"build_number":   "3",
+				"error":          "terraform apply failed",
+			},
+		})
+		require.NoError(t, err)
+
+		email := receive(t, smtp)
+		require.Equal(t, "notifications@coder.com", email.From)
+		require.Equal(t, []string{"alice@coder.com"}, email.To)
+		require.Equal(t, "Workspace dev failed to start", email.Subject)
+		require.Contains(t, email.Body, "terraform apply failed")
+		require.Contains(t, email.Body, "https://dev.coder.com/@alice/dev/builds/3")
+	})
+
+	t.Run("OptedOut", func(t *testing.T) {
+		t.Parallel()
+
+		db := dbfake.New()
+		user := dbgen.User(t, db, database.User{})
+		smtp := notificationstest.NewSMTPServer(t)
+		notifier := newNotifier(t, db, smtp)
+
+		ctx, _ := testutil.Context(t)
+		_, err := db.UpsertNotificationPreference(ctx, database.UpsertNotificationPreferenceParams{
+			UserID:    user.ID,
+			Kind:      string(codersdk.NotificationKindWorkspaceAutostop),
+			Disabled:  true,
+			UpdatedAt: database.Now(),
+		})
+		require.NoError(t, err)
+
+		err = notifier.Send(ctx, notifications.Notification{
+			UserID: user.ID,
+			Kind:   codersdk.NotificationKindWorkspaceAutostop,
+		})
+		require.NoError(t, err)
+		err = notifier.Send(ctx, notifications.Notification{
+			UserID: user.ID,
+			Kind:   codersdk.NotificationKindAccountActivated,
+		})
+		require.NoError(t, err)
+
+		// Only the kind the user did not opt out of is sent.
+		email := receive(t, smtp)
+		require.Equal(t, "Your Coder account has been activated", email.Subject)
+		require.Empty(t, smtp.Emails())
+	})
+
+	t.Run("Dedupes", func(t *testing.T) {
+		t.Parallel()
+
+		db := dbfake.New()
+		user := dbgen.User(t, db, database.User{})
+		smtp := notificationstest.NewSMTPServer(t)
+		// Two notifiers share the database like two replicas would.
+		first, second := newNotifier(t, db, smtp), newNotifier(t, db, smtp)
+
+		ctx, _ := testutil.Context(t)
+		for _, notifier := range []*notifications.Notifier{first, second} {
+			err := notifier.Send(ctx, notifications.Notification{
+				UserID:    user.ID,
+				Kind:      codersdk.NotificationKindWorkspaceAutostop,
+				DedupeKey: "build-1",
+				Data:      map[string]string{"workspace_name": "dev", "deadline": "15:04 UTC"},
+			})
+			require.NoError(t, err)
+		}
+
+		email := receive(t, smtp)
+		require.Equal(t, "Workspace dev will stop at 15:04 UTC", email.Subject)
+		require.Empty(t, smtp.Emails())
+	})
+
+	t.Run("SuspendedUser", func(t *testing.T) {
+		t.Parallel()
+
+		db := dbfake.New()
+		user := dbgen.User(t, db, database.User{})
+		smtp := notificationstest.NewSMTPServer(t)
+		notifier := newNotifier(t, db, smtp)
+
+		ctx, _ := testutil.Context(t)
+		_, err := db.UpdateUserStatus(ctx, database.UpdateUserStatusParams{
+			ID:        user.ID,
+			Status:    database.UserStatusSuspended,
+			UpdatedAt: database.Now(),
+		})
+		require.NoError(t, err)
+		err = notifier.Send(ctx, notifications.Notification{
+			UserID: user.ID,
+			Kind:   codersdk.NotificationKindWorkspaceBuildFailed,
+		})
+		require.NoError(t, err)
+		err = notifier.Send(ctx, notifications.Notification{
+			UserID: user.ID,
+			Kind:   codersdk.NotificationKindAccountSuspended,
+		})
+		require.NoError(t, err)
+
+		email := receive(t, smtp)
+		require.Equal(t, "Your Coder account has been suspended", email.Subject)
+		require.Empty(t, smtp.Emails())
+	})
+
+	t.Run("SendFailureIsRetried", func(t *testing.T) {
+		t.Parallel()
+
+		db := dbfake.New()
+		user := dbgen.User(t, db, database.User{})
+		failing := &failingSender{}
+		notifier := notifications.New(notifications.Options{
+			Database: db,
+			Logger:   slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}),
+			Sender:   failing,
+		})
+		t.Cleanup(func() { _ = notifier.Close() })
+
+		ctx, _ := testutil.Context(t)
+		notification := notifications.Notification{
+			UserID:    user.ID,
+			Kind:      codersdk.NotificationKindAccountCreated,
+			DedupeKey: user.ID.String(),
+		}
+		err := notifier.Send(ctx, notification)
+		require.Error(t, err)
+		err = notifier.Send(ctx, notification)
+		require.Error(t, err)
+		require.Equal(t, 2, failing.calls, "the claim is released when sending fails")
+	})
+
+	t.Run("Enqueue", func(t *testing.T) {
+		t.Parallel()
+
+		db := dbfake.New()
+		user := dbgen.User(t, db, database.User{})
+		smtp := notificationstest.NewSMTPServer(t)
+		notifier := newNotifier(t, db, smtp)
+
+		notifier.Enqueue(notifications.Notification{
+			UserID: user.ID,
+			Kind:   codersdk.NotificationKindAccountCreated,
+		})
+		email := receive(t, smtp)
+		require.Equal(t, "Your Coder account has been created", email.Subject)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+
+		notifier := notifications.New(notifications.Options{
+			Database: dbfake.New(),
+			Logger:   slogtest.Make(t, nil),
+		})
+		defer notifier.Close()
+		require.False(t, notifier.Enabled())
+
+		ctx, _ := testutil.Context(t)
+		err := notifier.Send(ctx, notifications.Notification{
+			Kind: codersdk.NotificationKindAccountCreated,
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	for _, kind := range codersdk.NotificationKinds {
+		kind := kind
+		t.Run(string(kind), func(t *testing.T) {
+			t.Parallel()
+
+			msg, err := notifications.Render(kind, notifications.TemplateData{
+				Username:  "alice",
+				AccessURL: "https://dev.coder.com",
+				Data: map[string]string{
+					"workspace_name": "dev",
+					"template_name":  "docker",
+				},
+			})
+			require.NoError(t, err)
+			require.NotEmpty(t, msg.Subject)
+			require.NotContains(t, msg.Subject, "\n")
+			require.Contains(t, msg.Body, "Hi alice,")
+		})
+	}
+}
+
+func newNotifier(t *testing.T, db database.Store, smtp *notificationstest.SMTPServer) *notifications.Notifier {
+	t.Helper()
+
+	sender, err := notifications.NewSMTPSender(notifications.SMTPOptions{
+		Smarthost: smtp.Addr(),
+		From:      "Coder <notifications@coder.com>",
+	})
+	require.NoError(t, err)
+	accessURL, err := url.Parse("https://dev.coder.com")
+	require.NoError(t, err)
+	notifier := notifications.New(notifications.Options{
+		Database:  db,
+		Logger:    slogtest.Make(t, nil),
+		Sender:    sender,
+		AccessURL: accessURL,
+	})
+	t.Cleanup(func() {
+		_ = notifier.Close()
+	})
+	return notifier
+}
+
+func receive(t *testing.T, smtp *notificationstest.SMTPServer) notificationstest.Email {
+	t.Helper()
+
+	select {
+	case email := <-smtp.Emails():
+		return email
+	case <-time.After(testutil.WaitShort):
+		t.Fatal("timed out waiting for email")
+		return notificationstest.Email{}
+	}
+}
+
+type failingSender struct {
+	calls int
+}
+
+func (f *failingSender) Send(context.Context, notifications.Message) error {
+	f.calls++
+	return context.DeadlineExceeded
+}
@@ -0,0 +1,124 @@
+// Package notificationstest provides a local SMTP server for tests.
+package notificationstest
+
+import (
+	"io"
+	"mime"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Email is a message received by the SMTP server.
+type Email struct {
+	From    string
+	To      []string
+	Subject string
+	Body    string
+}
+
+// SMTPServer accepts every message it is sent, without TLS or
+// authentication.
+type SMTPServer struct {
+	listener net.Listener
+	emails   chan Email
+}
+
+// NewSMTPServer starts an SMTP server that is closed when the test ends.
+func NewSMTPServer(t testing.TB) *SMTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := &SMTPServer{
+		listener: listener,
+		emails:   make(chan Email, 64),
+	}
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		_ = listener.Close()
+		<-done
+	})
+	go func() {
+		defer close(done)
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			srv.serve(conn)
+		}
+	}()
+	return srv
+}
+
+// Addr is the host:port of the server.
+func (s *SMTPServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Emails receives every message accepted by the server.
+func (s *SMTPServer) Emails() <-chan Email {
+	return s.emails
+}
+
+func (s *SMTPServer) serve(netConn net.Conn) {
+	conn := textproto.NewConn(netConn)
+	defer conn.Close()
+
+	var email Email
+	reply := func(code int, msg string) bool {
+		return conn.PrintfLine("%d %s", code, msg) == nil
+	}
+	if !reply(220, "localhost ESMTP notificationstest") {
+		return
+	}
+	for {
+		line, err := conn.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "HELO", "EHLO":
+			reply(250, "localhost")
+		case "MAIL":
+			email = Email{From: address(arg)}
+			reply(250, "OK")
+		case "RCPT":
+			email.To = append(email.To, address(arg))
+			reply(250, "OK")
+		case "DATA":
+			if !reply(354, "End data with <CR><LF>.<CR><LF>") {
+				return
+			}
+			msg, err := mail.ReadMessage(conn.DotReader())
+			if err != nil {
+				reply(554, err.Error())
+				continue
+			}
+			body, _ := io.ReadAll(msg.Body)
+			email.Subject, _ = new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+			email.Body = strings.ReplaceAll(string(body), "\r\n", "\n")
+			s.emails <- email
+			reply(250, "OK")
+		case "RSET", "NOOP":
+			reply(250, "OK")
+		case "QUIT":
+			reply(221, "Bye")
+			return
+		default:
+			reply(502, "Command not implemented")
+		}
+	}
+}
+
+// address extracts the address from "FROM:<a@b>" or "TO:<a@b>".
+func address(arg string) string {
+	_, addr, _ := strings.Cut(arg, ":")
+	return strings.Trim(strings.TrimSpace(addr), "<>")
+}
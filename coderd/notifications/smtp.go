@@ -0,0 +1,135 @@
+package notifications
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// SMTPOptions configure an SMTPSender.
+type SMTPOptions struct {
+	// Smarthost is the host:port of the SMTP server.
+	Smarthost string
+	// From is the address messages are sent from.
+	From string
+	// Username and Password authenticate with PLAIN auth if set. Go only
+	// sends credentials over TLS or to localhost.
+	Username string
+	Password string
+	// Hello is the hostname sent in the HELO/EHLO command, default
+	// "localhost".
+	Hello string
+}
+
+// SMTPSender sends messages through an SMTP server. STARTTLS is used when
+// the server supports it.
+type SMTPSender struct {
+	opts SMTPOptions
+}
+
+// NewSMTPSender validates the options and returns a sender.
+func NewSMTPSender(opts SMTPOptions) (*SMTPSender, error) {
+	if _, _, err := net.SplitHostPort(opts.Smarthost); err != nil {
+		return nil, xerrors.Errorf("smarthost must be host:port: %w", err)
+	}
+	if _, err := mail.ParseAddress(opts.From); err != nil {
+		return nil, xerrors.Errorf("parse from address: %w", err)
+	}
+	if opts.Hello == "" {
+		opts.Hello = "localhost"
+	}
+	return &SMTPSender{opts: opts}, nil
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	host, _, _ := net.SplitHostPort(s.opts.Smarthost)
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.opts.Smarthost)
+	if err != nil {
+		return xerrors.Errorf("dial smarthost: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return xerrors.Errorf("create smtp client: %w", err)
+	}
+	defer client.Close()
+
+	err = client.Hello(s.opts.Hello)
+	if err != nil {
+		return xerrors.Errorf("hello: %w", err)
+	}
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err = client.StartTLS(&tls.Config{
+			ServerName: host,
+			MinVersion: tls.VersionTLS12,
+		})
+		if err != nil {
+			return xerrors.Errorf("starttls: %w", err)
+		}
+	}
+	if s.opts.Username != "" {
+		err = client.Auth(smtp.PlainAuth("", s.opts.Username, s.opts.Password, host))
+		if err != nil {
+			return xerrors.Errorf("authenticate: %w", err)
+		}
+	}
+
+	from, err := mail.ParseAddress(s.opts.From)
+	if err != nil {
+		return xerrors.Errorf("parse from address: %w", err)
+	}
+	err = client.Mail(from.Address)
+	if err != nil {
+		return xerrors.Errorf("mail from: %w", err)
+	}
+	err = client.Rcpt(msg.To)
+	if err != nil {
+		return xerrors.Errorf("rcpt to: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return xerrors.Errorf("data: %w", err)
+	}
+	_, err = w.Write(s.format(from, msg))
+	if err != nil {
+		return xerrors.Errorf("write message: %w", err)
+	}
+	err = w.Close()
+	if err != nil {
+		return xerrors.Errorf("close message: %w", err)
+	}
+	return client.Quit()
+}
+
+// format returns the message with its headers in RFC 5322 format.
+func (s *SMTPSender) format(from *mail.Address, msg Message) []byte {
+	var b strings.Builder
+	header := func(key, value string) {
+		_, _ = fmt.Fprintf(&b, "%s: %s\r\n", key, value)
+	}
+	header("From", from.String())
+	header("To", msg.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<%s@%s>", uuid.NewString(), s.opts.Hello))
+	header("MIME-Version", "1.0")
+	header("Content-Type", `text/plain; charset="utf-8"`)
+	header("Content-Transfer-Encoding", "8bit")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,201 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/notifications"
+	"github.com/coder/coder/coderd/notifications/notificationstest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestNotificationPreferences(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Update", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+		ctx, _ := testutil.Context(t)
+
+		preferences, err := client.NotificationPreferences(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Len(t, preferences, len(codersdk.NotificationKinds))
+		for _, preference := range preferences {
+			require.True(t, preference.Enabled, "every kind is enabled by default")
+			require.Nil(t, preference.UpdatedAt)
+		}
+
+		preferences, err = client.UpdateNotificationPreferences(ctx, codersdk.Me, codersdk.UpdateNotificationPreferencesRequest{
+			Preferences: []codersdk.NotificationPreference{{
+				Kind:    codersdk.NotificationKindWorkspaceAutostop,
+				Enabled: false,
+			}},
+		})
+		require.NoError(t, err)
+		require.Len(t, preferences, len(codersdk.NotificationKinds))
+		for _, preference := range preferences {
+			require.Equal(t, preference.Kind != codersdk.NotificationKindWorkspaceAutostop, preference.Enabled, preference.Kind)
+		}
+
+		preferences, err = client.NotificationPreferences(ctx, codersdk.Me)
+		require.NoError(t, err)
+		require.Equal(t, codersdk.NotificationKindWorkspaceAutostop, preferences[0].Kind)
+		require.False(t, preferences[0].Enabled)
+		require.NotNil(t, preferences[0].UpdatedAt)
+	})
+
+	t.Run("InvalidKind", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+		ctx, _ := testutil.Context(t)
+
+		_, err := client.UpdateNotificationPreferences(ctx, codersdk.Me, codersdk.UpdateNotificationPreferencesRequest{
+			Preferences: []codersdk.NotificationPreference{{Kind: "workspace_exploded"}},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("OtherUser", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		owner := coderdtest.CreateFirstUser(t, client)
+		member, _ := coderdtest.CreateAnotherUser(t, client, owner.OrganizationID)
+		ctx, _ := testutil.Context(t)
+
+		_, err := member.UpdateNotificationPreferences(ctx, owner.UserID.String(), codersdk.UpdateNotificationPreferencesRequest{
+			Preferences: []codersdk.NotificationPreference{{Kind: codersdk.NotificationKindAccountCreated}},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
+
+func TestNotificationEmails(t *testing.T) {
+	t.Parallel()
+
+	t.Run("AccountEvents", func(t *testing.T) {
+		t.Parallel()
+
+		smtp := notificationstest.NewSMTPServer(t)
+		client := coderdtest.New(t, &coderdtest.Options{
+			NotificationSender: newSMTPSender(t, smtp),
+		})
+		owner := coderdtest.CreateFirstUser(t, client)
+		ctx, _ := testutil.Context(t)
+
+		user, err := client.CreateUser(ctx, codersdk.CreateUserRequest{
+			Email:          "bob@coder.com",
+			Username:       "bob",
+			Password:       "SomeSecurePassword!",
+			OrganizationID: owner.OrganizationID,
+		})
+		require.NoError(t, err)
+		email := receiveEmail(t, smtp)
+		require.Equal(t, []string{"bob@coder.com"}, email.To)
+		require.Equal(t, "Your Coder account has been created", email.Subject)
+
+		_, err = client.UpdateUserStatus(ctx, user.ID.String(), codersdk.UserStatusSuspended)
+		require.NoError(t, err)
+		email = receiveEmail(t, smtp)
+		require.Equal(t, "Your Coder account has been suspended", email.Subject)
+
+		_, err = client.UpdateUserStatus(ctx, user.ID.String(), codersdk.UserStatusActive)
+		require.NoError(t, err)
+		email = receiveEmail(t, smtp)
+		require.Equal(t, "Your Coder account has been activated", email.Subject)
+	})
+
+	t.Run("BuildFailed", func(t *testing.T) {
+		t.Parallel()
+
+		smtp := notificationstest.NewSMTPServer(t)
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+			NotificationSender:       newSMTPSender(t, smtp),
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse:         echo.ParseComplete,
+			ProvisionPlan: echo.ProvisionComplete,
+			ProvisionApply: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{Error: "terraform apply failed"},
+				},
+			}},
+		})
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		email := receiveEmail(t, smtp)
+		require.Equal(t, "Workspace "+workspace.Name+" failed to start", email.Subject)
+		require.Contains(t, email.Body, "terraform apply failed")
+	})
+
+	t.Run("TemplateDeprecated", func(t *testing.T) {
+		t.Parallel()
+
+		smtp := notificationstest.NewSMTPServer(t)
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+			NotificationSender:       newSMTPSender(t, smtp),
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		ctx, _ := testutil.Context(t)
+
+		_, err := client.UpdateTemplateDeprecation(ctx, template.ID, codersdk.UpdateTemplateDeprecation{
+			Deprecated: true,
+			Message:    "Use the new template.",
+		})
+		require.NoError(t, err)
+
+		email := receiveEmail(t, smtp)
+		require.Equal(t, "Template "+template.Name+" has been deprecated", email.Subject)
+		require.Contains(t, email.Body, workspace.Name)
+		require.Contains(t, email.Body, "Use the new template.")
+	})
+}
+
+func newSMTPSender(t *testing.T, smtp *notificationstest.SMTPServer) notifications.Sender {
+	t.Helper()
+
+	sender, err := notifications.NewSMTPSender(notifications.SMTPOptions{
+		Smarthost: smtp.Addr(),
+		From:      "notifications@coder.com",
+	})
+	require.NoError(t, err)
+	return sender
+}
+
+func receiveEmail(t *testing.T, smtp *notificationstest.SMTPServer) notificationstest.Email {
+	t.Helper()
+
+	select {
+	case email := <-smtp.Emails():
+		return email
+	case <-time.After(testutil.WaitLong):
+		t.Fatal("timed out waiting for email")
+		return notificationstest.Email{}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"golang.org/x/xerrors"
 
+	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
@@ -46,8 +47,19 @@ func (api *API) organization(rw http.ResponseWriter, r *http.Request) {
 // @Success 201 {object} codersdk.Organization
 // @Router /organizations [post]
 func (api *API) postOrganizations(rw http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	apiKey := httpmw.APIKey(r)
+	var (
+		ctx               = r.Context()
+		apiKey            = httpmw.APIKey(r)
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.Organization](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionCreate,
+		})
+	)
+	defer commitAudit()
+
 	// Create organization uses the organization resource without an OrgID.
 	// This means you need the site wide permission to make a new organization.
 	if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceOrganization) {
@@ -117,6 +129,8 @@ func (api *API) postOrganizations(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	aReq.New = organization
+
 	httpapi.Write(ctx, rw, http.StatusCreated, convertOrganization(organization))
 }
 
@@ -7,7 +7,9 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/testutil"
 )
@@ -98,15 +100,23 @@ func TestPostOrganizationsByUser(t *testing.T) {
 
 	t.Run("Create", func(t *testing.T) {
 		t.Parallel()
-		client := coderdtest.New(t, nil)
+		auditor := audit.NewMock()
+		client := coderdtest.New(t, &coderdtest.Options{Auditor: auditor})
 		_ = coderdtest.CreateFirstUser(t, client)
 
 		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
 		defer cancel()
 
-		_, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
+		numLogs := len(auditor.AuditLogs)
+		org, err := client.CreateOrganization(ctx, codersdk.CreateOrganizationRequest{
 			Name: "new",
 		})
 		require.NoError(t, err)
+		numLogs++ // add an audit log for organization creation
+
+		require.Len(t, auditor.AuditLogs, numLogs)
+		require.Equal(t, database.AuditActionCreate, auditor.AuditLogs[numLogs-1].Action)
+		require.Equal(t, database.ResourceTypeOrganization, auditor.AuditLogs[numLogs-1].ResourceType)
+		require.Equal(t, org.ID, auditor.AuditLogs[numLogs-1].ResourceID)
 	})
 }
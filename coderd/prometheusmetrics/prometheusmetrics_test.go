@@ -102,6 +102,7 @@ func TestWorkspaces(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeWorkspaceBuild,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = db.InsertWorkspaceBuild(context.Background(), database.InsertWorkspaceBuildParams{
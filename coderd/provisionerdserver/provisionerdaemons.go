@@ -0,0 +1,63 @@
+package provisionerdserver
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/util/slice"
+)
+
+const (
+	// DaemonHeartbeatInterval is how often the last seen time of a connected
+	// daemon is written. Daemons poll for jobs and update running jobs every
+	// few seconds, so only some of their requests write it.
+	DaemonHeartbeatInterval = 10 * time.Second
+	// DaemonOfflineAfter is how long a daemon can go without being seen
+	// before it's considered offline.
+	DaemonOfflineAfter = time.Minute
+)
+
+// DaemonOnline returns whether the daemon has been seen recently.
+func DaemonOnline(daemon database.ProvisionerDaemon, now time.Time) bool {
+	return daemon.LastSeenAt.Valid && now.Sub(daemon.LastSeenAt.Time) < DaemonOfflineAfter
+}
+
+// DaemonCanAcquire returns whether the daemon can acquire jobs with the
+// provisioner and tags, which requires it to serve the provisioner and to have
+// all of the tags.
+func DaemonCanAcquire(daemon database.ProvisionerDaemon, provisioner database.ProvisionerType, tags map[string]string) bool {
+	if !slice.Contains(daemon.Provisioners, provisioner) {
+		return false
+	}
+	for key, value := range tags {
+		if daemonValue, ok := daemon.Tags[key]; !ok || daemonValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// markSeen records that the daemon is connected, at most once every
+// DaemonHeartbeatInterval.
+func (server *Server) markSeen(ctx context.Context) {
+	now := database.Now()
+	last := server.lastSeen.Load()
+	if last != 0 && now.Sub(time.Unix(0, last)) < DaemonHeartbeatInterval {
+		return
+	}
+	if !server.lastSeen.CompareAndSwap(last, now.UnixNano()) {
+		// Another request is writing it.
+		return
+	}
+	err := server.Database.UpdateProvisionerDaemonLastSeenAt(ctx, database.UpdateProvisionerDaemonLastSeenAtParams{
+		ID:         server.ID,
+		LastSeenAt: sql.NullTime{Time: now, Valid: true},
+	})
+	if err != nil && ctx.Err() == nil {
+		server.Logger.Warn(ctx, "update provisioner daemon last seen", slog.Error(err))
+	}
+}
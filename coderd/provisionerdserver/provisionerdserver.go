@@ -27,9 +27,12 @@ import (
 	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/filestore"
+	"github.com/coder/coder/coderd/notifications"
 	"github.com/coder/coder/coderd/parameter"
 	"github.com/coder/coder/coderd/telemetry"
 	"github.com/coder/coder/coderd/util/slice"
+	"github.com/coder/coder/coderd/webhooks"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/provisioner"
 	"github.com/coder/coder/provisionerd/proto"
@@ -37,6 +40,10 @@ import (
 	sdkproto "github.com/coder/coder/provisionersdk/proto"
 )
 
+// WorkspaceLabelsVariable is the name of the template variable that is set to
+// the labels of the workspace on builds, e.g. to tag cloud resources.
+const WorkspaceLabelsVariable = "coder_workspace_labels"
+
 var (
 	lastAcquire      time.Time
 	lastAcquireMutex sync.RWMutex
@@ -49,19 +56,32 @@ type Server struct {
 	Provisioners     []database.ProvisionerType
 	GitAuthProviders []string
 	Tags             json.RawMessage
-	Database         database.Store
-	Pubsub           database.Pubsub
-	Telemetry        telemetry.Reporter
-	QuotaCommitter   *atomic.Pointer[proto.QuotaCommitter]
-	Auditor          *atomic.Pointer[audit.Auditor]
+	// OrganizationID restricts the jobs the daemon acquires to those of an
+	// organization. Jobs of any organization are acquired if it's uuid.Nil.
+	OrganizationID uuid.UUID
+	Database       database.Store
+	Pubsub         database.Pubsub
+	Telemetry      telemetry.Reporter
+	QuotaCommitter *atomic.Pointer[proto.QuotaCommitter]
+	Auditor        *atomic.Pointer[audit.Auditor]
+	// Notifier emails workspace owners about failed builds, it may be nil.
+	Notifier *notifications.Notifier
+	// FileStore stores the contents of files, they're stored in the database
+	// if it's nil.
+	FileStore filestore.Store
 
 	AcquireJobDebounce time.Duration
+
+	// lastSeen is when the daemon's last seen time was last written, in Unix
+	// nanoseconds.
+	lastSeen atomic.Int64
 }
 
 // AcquireJob queries the database to lock a job.
 func (server *Server) AcquireJob(ctx context.Context, _ *proto.Empty) (*proto.AcquiredJob, error) {
 	//nolint:gocritic // Provisionerd has specific authz rules.
 	ctx = dbauthz.AsProvisionerd(ctx)
+	server.markSeen(ctx)
 	// This prevents loads of provisioner daemons from consistently
 	// querying the database when no jobs are available.
 	//
@@ -83,8 +103,9 @@ func (server *Server) AcquireJob(ctx context.Context, _ *proto.Empty) (*proto.Ac
 			UUID:  server.ID,
 			Valid: true,
 		},
-		Types: server.Provisioners,
-		Tags:  server.Tags,
+		Types:          server.Provisioners,
+		Tags:           server.Tags,
+		OrganizationID: server.OrganizationID,
 	})
 	if errors.Is(err, sql.ErrNoRows) {
 		// The provisioner daemon assumes no jobs are available if
@@ -164,6 +185,9 @@ func (server *Server) AcquireJob(ctx context.Context, _ *proto.Empty) (*proto.Ac
 		if err != nil {
 			return nil, failJob(fmt.Sprintf("publish workspace update: %s", err))
 		}
+		if !input.DryRun {
+			server.publishBuildEvent(ctx, codersdk.WebhookEventBuildStarted, workspace, workspaceBuild, "")
+		}
 
 		// Compute parameters for the workspace to consume.
 		parameters, err := parameter.Compute(ctx, server.Database, parameter.ComputeScope{
@@ -195,15 +219,26 @@ func (server *Server) AcquireJob(ctx context.Context, _ *proto.Empty) (*proto.Ac
 		if err != nil {
 			return nil, failJob(fmt.Sprintf("get workspace build parameters: %s", err))
 		}
+		variableValues, err := workspaceVariableValues(templateVariables, workspace)
+		if err != nil {
+			return nil, failJob(fmt.Sprintf("convert workspace variable values: %s", err))
+		}
+		state := workspaceBuild.ProvisionerState
+		if input.RotateAgentTokens && job.Provisioner == database.ProvisionerTypeTerraform {
+			state, err = taintTerraformAgents(state)
+			if err != nil {
+				return nil, failJob(fmt.Sprintf("taint agents in state: %s", err))
+			}
+		}
 
 		protoJob.Type = &proto.AcquiredJob_WorkspaceBuild_{
 			WorkspaceBuild: &proto.AcquiredJob_WorkspaceBuild{
 				WorkspaceBuildId:    workspaceBuild.ID.String(),
 				WorkspaceName:       workspace.Name,
-				State:               workspaceBuild.ProvisionerState,
+				State:               state,
 				ParameterValues:     protoParameters,
 				RichParameterValues: convertRichParameterValues(workspaceBuildParameters),
-				VariableValues:      asVariableValues(templateVariables),
+				VariableValues:      variableValues,
 				Metadata: &sdkproto.Provision_Metadata{
 					CoderUrl:            server.AccessURL.String(),
 					WorkspaceTransition: transition,
@@ -288,7 +323,10 @@ func (server *Server) AcquireJob(ctx context.Context, _ *proto.Empty) (*proto.Ac
 		if err != nil {
 			return nil, failJob(fmt.Sprintf("get file by hash: %s", err))
 		}
-		protoJob.TemplateSourceArchive = file.Data
+		protoJob.TemplateSourceArchive, err = filestore.Read(ctx, server.FileStore, file)
+		if err != nil {
+			return nil, failJob(fmt.Sprintf("read file: %s", err))
+		}
 	default:
 		return nil, failJob(fmt.Sprintf("unsupported storage method: %s", job.StorageMethod))
 	}
@@ -385,6 +423,7 @@ func (server *Server) CommitQuota(ctx context.Context, request *proto.CommitQuot
 func (server *Server) UpdateJob(ctx context.Context, request *proto.UpdateJobRequest) (*proto.UpdateJobResponse, error) {
 	//nolint:gocritic // Provisionerd has specific authz rules.
 	ctx = dbauthz.AsProvisionerd(ctx)
+	server.markSeen(ctx)
 	parsedID, err := uuid.Parse(request.JobId)
 	if err != nil {
 		return nil, xerrors.Errorf("parse job id: %w", err)
@@ -400,6 +439,9 @@ func (server *Server) UpdateJob(ctx context.Context, request *proto.UpdateJobReq
 	if job.WorkerID.UUID.String() != server.ID.String() {
 		return nil, xerrors.New("you don't own this job")
 	}
+	// Jobs that were failed while the daemon was running them, e.g. because
+	// they were hung, are stopped as if they were canceled.
+	canceled := job.CanceledAt.Valid || job.CompletedAt.Valid
 	err = server.Database.UpdateProvisionerJobByID(ctx, database.UpdateProvisionerJobByIDParams{
 		ID:        parsedID,
 		UpdatedAt: database.Now(),
@@ -516,7 +558,7 @@ func (server *Server) UpdateJob(ctx context.Context, request *proto.UpdateJobReq
 		}
 
 		return &proto.UpdateJobResponse{
-			Canceled:       job.CanceledAt.Valid,
+			Canceled:       canceled,
 			VariableValues: variableValues,
 		}, nil
 	}
@@ -601,13 +643,13 @@ func (server *Server) UpdateJob(ctx context.Context, request *proto.UpdateJobReq
 		}
 
 		return &proto.UpdateJobResponse{
-			Canceled:        job.CanceledAt.Valid,
+			Canceled:        canceled,
 			ParameterValues: protoParameters,
 		}, nil
 	}
 
 	return &proto.UpdateJobResponse{
-		Canceled: job.CanceledAt.Valid,
+		Canceled: canceled,
 	}, nil
 }
 
@@ -722,6 +764,8 @@ func (server *Server) FailJob(ctx context.Context, failJob *proto.FailedJob) (*p
 					Status:           http.StatusInternalServerError,
 					AdditionalFields: wriBytes,
 				})
+				server.publishBuildEvent(ctx, codersdk.WebhookEventBuildFailed, workspace, build, failJob.Error)
+				server.notifyBuildFailed(workspace, build, failJob.Error)
 			}
 		}
 	}
@@ -1008,6 +1052,13 @@ func (server *Server) CompleteJob(ctx context.Context, completed *proto.Complete
 				Status:           http.StatusOK,
 				AdditionalFields: wriBytes,
 			})
+
+			if !input.DryRun {
+				server.publishBuildEvent(ctx, codersdk.WebhookEventBuildSucceeded, workspace, workspaceBuild, "")
+				if workspaceBuild.Transition == database.WorkspaceTransitionDelete {
+					server.publishBuildEvent(ctx, codersdk.WebhookEventWorkspaceDeleted, workspace, workspaceBuild, "")
+				}
+			}
 		}
 
 		err = server.Pubsub.Publish(codersdk.WorkspaceNotifyChannel(workspaceBuild.WorkspaceID), []byte{})
@@ -1357,6 +1408,97 @@ func convertWorkspaceTransition(transition database.WorkspaceTransition) (sdkpro
 	}
 }
 
+// publishBuildEvent notifies webhooks about a workspace build. Failing to
+// publish must not fail the job, so errors are only logged.
+// taintTerraformAgents marks every coder_agent instance in a Terraform state
+// as tainted, like "terraform taint" does, so the next apply replaces it.
+func taintTerraformAgents(state []byte) ([]byte, error) {
+	if len(state) == 0 {
+		return state, nil
+	}
+	var tfstate map[string]json.RawMessage
+	err := json.Unmarshal(state, &tfstate)
+	if err != nil {
+		return nil, xerrors.Errorf("unmarshal state: %w", err)
+	}
+	raw, ok := tfstate["resources"]
+	if !ok {
+		return state, nil
+	}
+	var resources []map[string]json.RawMessage
+	err = json.Unmarshal(raw, &resources)
+	if err != nil {
+		return nil, xerrors.Errorf("unmarshal resources: %w", err)
+	}
+	for _, resource := range resources {
+		var mode, resourceType string
+		_ = json.Unmarshal(resource["mode"], &mode)
+		_ = json.Unmarshal(resource["type"], &resourceType)
+		if mode != "managed" || resourceType != "coder_agent" {
+			continue
+		}
+		var instances []map[string]json.RawMessage
+		err = json.Unmarshal(resource["instances"], &instances)
+		if err != nil {
+			return nil, xerrors.Errorf("unmarshal instances: %w", err)
+		}
+		for _, instance := range instances {
+			instance["status"] = json.RawMessage(`"tainted"`)
+		}
+		resource["instances"], err = json.Marshal(instances)
+		if err != nil {
+			return nil, xerrors.Errorf("marshal instances: %w", err)
+		}
+	}
+	tfstate["resources"], err = json.Marshal(resources)
+	if err != nil {
+		return nil, xerrors.Errorf("marshal resources: %w", err)
+	}
+	return json.Marshal(tfstate)
+}
+
+func (server *Server) publishBuildEvent(ctx context.Context, event codersdk.WebhookEvent, workspace database.Workspace, build database.WorkspaceBuild, errorMessage string) {
+	data := map[string]string{
+		"workspace_id":   workspace.ID.String(),
+		"workspace_name": workspace.Name,
+		"owner_id":       workspace.OwnerID.String(),
+		"template_id":    workspace.TemplateID.String(),
+		"build_id":       build.ID.String(),
+		"build_number":   strconv.FormatInt(int64(build.BuildNumber), 10),
+		"transition":     string(build.Transition),
+		"reason":         string(build.Reason),
+	}
+	if errorMessage != "" {
+		data["error"] = errorMessage
+	}
+	err := webhooks.Publish(server.Pubsub, event, workspace.OrganizationID, data)
+	if err != nil {
+		server.Logger.Warn(ctx, "publish webhook event",
+			slog.F("event", event),
+			slog.F("workspace_build_id", build.ID),
+			slog.Error(err),
+		)
+	}
+}
+
+// notifyBuildFailed emails the workspace owner that the build failed.
+func (server *Server) notifyBuildFailed(workspace database.Workspace, build database.WorkspaceBuild, errorMessage string) {
+	if server.Notifier == nil {
+		return
+	}
+	server.Notifier.Enqueue(notifications.Notification{
+		UserID:    workspace.OwnerID,
+		Kind:      codersdk.NotificationKindWorkspaceBuildFailed,
+		DedupeKey: build.ID.String(),
+		Data: map[string]string{
+			"workspace_name": workspace.Name,
+			"build_number":   strconv.FormatInt(int64(build.BuildNumber), 10),
+			"transition":     string(build.Transition),
+			"error":          errorMessage,
+		},
+	})
+}
+
 func auditActionFromTransition(transition database.WorkspaceTransition) database.AuditAction {
 	switch transition {
 	case database.WorkspaceTransitionStart:
@@ -1379,6 +1521,11 @@ type TemplateVersionImportJob struct {
 type WorkspaceProvisionJob struct {
 	WorkspaceBuildID uuid.UUID `json:"workspace_build_id"`
 	DryRun           bool      `json:"dry_run"`
+	// RotateAgentTokens taints the agents in the Terraform state, so the
+	// build replaces them and their new tokens reach the instances and are
+	// kept in the state. Builds that transfer a workspace set it, so the
+	// prior owner can't run agents of the new owner.
+	RotateAgentTokens bool `json:"rotate_agent_tokens,omitempty"`
 }
 
 // TemplateVersionDryRunJob is the payload for the "template_version_dry_run" job type.
@@ -1421,6 +1568,38 @@ func asVariableValues(templateVariables []database.TemplateVersionVariable) []*s
 	return apiVariableValues
 }
 
+// workspaceVariableValues returns the template variable values for a build of
+// the workspace. Templates that declare the WorkspaceLabelsVariable get the
+// labels of the workspace as a JSON object.
+func workspaceVariableValues(templateVariables []database.TemplateVersionVariable, workspace database.Workspace) ([]*sdkproto.VariableValue, error) {
+	variableValues := asVariableValues(templateVariables)
+	for _, v := range templateVariables {
+		if v.Name != WorkspaceLabelsVariable {
+			continue
+		}
+		labels := map[string]string(workspace.Labels)
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		data, err := json.Marshal(labels)
+		if err != nil {
+			return nil, xerrors.Errorf("marshal workspace labels: %w", err)
+		}
+		value := &sdkproto.VariableValue{
+			Name:  WorkspaceLabelsVariable,
+			Value: string(data),
+		}
+		for i, variableValue := range variableValues {
+			if variableValue.Name == WorkspaceLabelsVariable {
+				variableValues = append(variableValues[:i], variableValues[i+1:]...)
+				break
+			}
+		}
+		variableValues = append(variableValues, value)
+	}
+	return variableValues, nil
+}
+
 func redactTemplateVariable(templateVariable *sdkproto.TemplateVariable) *sdkproto.TemplateVariable {
 	if templateVariable == nil {
 		return nil
@@ -58,6 +58,7 @@ func TestAcquireJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeTemplateVersionDryRun,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		job, err = srv.AcquireJob(context.Background(), nil)
@@ -71,6 +72,24 @@ func TestAcquireJob(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, &proto.AcquiredJob{}, job)
 	})
+	t.Run("MarksDaemonSeen", func(t *testing.T) {
+		t.Parallel()
+		srv := setup(t, false)
+		lastSeen := database.Now().Add(-time.Hour)
+		daemon := dbgen.ProvisionerDaemon(t, srv.Database, database.ProvisionerDaemon{
+			LastSeenAt: sql.NullTime{Time: lastSeen, Valid: true},
+		})
+		srv.ID = daemon.ID
+		require.False(t, provisionerdserver.DaemonOnline(daemon, database.Now()))
+
+		_, err := srv.AcquireJob(context.Background(), nil)
+		require.NoError(t, err)
+		daemons, err := srv.Database.GetProvisionerDaemons(context.Background())
+		require.NoError(t, err)
+		require.Len(t, daemons, 1)
+		require.True(t, daemons[0].LastSeenAt.Time.After(lastSeen))
+		require.True(t, provisionerdserver.DaemonOnline(daemons[0], database.Now()))
+	})
 	t.Run("InitiatorNotFound", func(t *testing.T) {
 		t.Parallel()
 		srv := setup(t, false)
@@ -80,11 +99,91 @@ func TestAcquireJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeTemplateVersionDryRun,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = srv.AcquireJob(context.Background(), nil)
 		require.ErrorContains(t, err, "sql: no rows in result set")
 	})
+	t.Run("Priority", func(t *testing.T) {
+		t.Parallel()
+		srv := setup(t, false)
+		now := database.Now()
+
+		background := dbgen.ProvisionerJob(t, srv.Database, database.ProvisionerJob{
+			CreatedAt: now.Add(-2 * time.Minute),
+			Priority:  database.ProvisionerJobPriorityBackground,
+		})
+		automatic := dbgen.ProvisionerJob(t, srv.Database, database.ProvisionerJob{
+			CreatedAt: now.Add(-time.Minute),
+			Priority:  database.ProvisionerJobPriorityAutomatic,
+		})
+		interactive := dbgen.ProvisionerJob(t, srv.Database, database.ProvisionerJob{
+			CreatedAt: now,
+			Priority:  database.ProvisionerJobPriorityInteractive,
+		})
+
+		for _, want := range []database.ProvisionerJob{interactive, automatic, background} {
+			job := acquireProvisionerJob(t, srv.Database)
+			require.Equal(t, want.ID, job.ID)
+		}
+	})
+	t.Run("Fairness", func(t *testing.T) {
+		t.Parallel()
+		srv := setup(t, false)
+		now := database.Now()
+		busyUser := uuid.New()
+		organization := uuid.New()
+
+		running := dbgen.ProvisionerJob(t, srv.Database, database.ProvisionerJob{
+			CreatedAt:      now.Add(-time.Hour),
+			InitiatorID:    busyUser,
+			OrganizationID: organization,
+		})
+		require.Equal(t, running.ID, acquireProvisionerJob(t, srv.Database).ID)
+
+		// The busy user queued their job first, but already has a job running.
+		busy := dbgen.ProvisionerJob(t, srv.Database, database.ProvisionerJob{
+			CreatedAt:      now.Add(-time.Minute),
+			InitiatorID:    busyUser,
+			OrganizationID: organization,
+		})
+		idle := dbgen.ProvisionerJob(t, srv.Database, database.ProvisionerJob{
+			CreatedAt:      now,
+			OrganizationID: organization,
+		})
+		require.Equal(t, idle.ID, acquireProvisionerJob(t, srv.Database).ID)
+		require.Equal(t, busy.ID, acquireProvisionerJob(t, srv.Database).ID)
+	})
+	t.Run("Organization", func(t *testing.T) {
+		t.Parallel()
+		srv := setup(t, false)
+		now := database.Now()
+		organization := uuid.New()
+
+		other := dbgen.ProvisionerJob(t, srv.Database, database.ProvisionerJob{
+			CreatedAt:      now.Add(-time.Minute),
+			OrganizationID: uuid.New(),
+		})
+		want := dbgen.ProvisionerJob(t, srv.Database, database.ProvisionerJob{
+			CreatedAt:      now,
+			OrganizationID: organization,
+		})
+		params := database.AcquireProvisionerJobParams{
+			StartedAt:      sql.NullTime{Time: now, Valid: true},
+			WorkerID:       uuid.NullUUID{UUID: uuid.New(), Valid: true},
+			Types:          []database.ProvisionerType{database.ProvisionerTypeEcho},
+			Tags:           json.RawMessage("{}"),
+			OrganizationID: organization,
+		}
+		// The job of the other organization is older, but isn't acquired.
+		job, err := srv.Database.AcquireProvisionerJob(context.Background(), params)
+		require.NoError(t, err)
+		require.Equal(t, want.ID, job.ID)
+		_, err = srv.Database.AcquireProvisionerJob(context.Background(), params)
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		require.Equal(t, other.ID, acquireProvisionerJob(t, srv.Database).ID)
+	})
 	t.Run("WorkspaceBuildJob", func(t *testing.T) {
 		t.Parallel()
 		srv := setup(t, false)
@@ -134,9 +233,16 @@ func TestAcquireJob(t *testing.T) {
 			Required:          true,
 			Sensitive:         false,
 		})
+		_ = dbgen.TemplateVersionVariable(t, srv.Database, database.TemplateVersionVariable{
+			TemplateVersionID: version.ID,
+			Name:              provisionerdserver.WorkspaceLabelsVariable,
+			Type:              "map(string)",
+			DefaultValue:      "{}",
+		})
 		workspace := dbgen.Workspace(t, srv.Database, database.Workspace{
 			TemplateID: template.ID,
 			OwnerID:    user.ID,
+			Labels:     database.WorkspaceLabels{"project": "coder"},
 		})
 		build := dbgen.WorkspaceBuild(t, srv.Database, database.WorkspaceBuild{
 			WorkspaceID:       workspace.ID,
@@ -197,6 +303,10 @@ func TestAcquireJob(t *testing.T) {
 						Name:  "second",
 						Value: "second_value",
 					},
+					{
+						Name:  provisionerdserver.WorkspaceLabelsVariable,
+						Value: `{"project":"coder"}`,
+					},
 				},
 				Metadata: &sdkproto.Provision_Metadata{
 					CoderUrl:            srv.AccessURL.String(),
@@ -215,6 +325,61 @@ func TestAcquireJob(t *testing.T) {
 
 		require.JSONEq(t, string(want), string(got))
 	})
+	t.Run("RotateAgentTokens", func(t *testing.T) {
+		t.Parallel()
+		srv := setup(t, false)
+		ctx := context.Background()
+		srv.Provisioners = []database.ProvisionerType{database.ProvisionerTypeTerraform}
+
+		user := dbgen.User(t, srv.Database, database.User{})
+		template := dbgen.Template(t, srv.Database, database.Template{
+			Provisioner: database.ProvisionerTypeTerraform,
+		})
+		version := dbgen.TemplateVersion(t, srv.Database, database.TemplateVersion{
+			TemplateID: uuid.NullUUID{
+				UUID:  template.ID,
+				Valid: true,
+			},
+			JobID: uuid.New(),
+		})
+		workspace := dbgen.Workspace(t, srv.Database, database.Workspace{
+			TemplateID: template.ID,
+			OwnerID:    user.ID,
+		})
+		build := dbgen.WorkspaceBuild(t, srv.Database, database.WorkspaceBuild{
+			WorkspaceID:       workspace.ID,
+			BuildNumber:       1,
+			JobID:             uuid.New(),
+			TemplateVersionID: version.ID,
+			Transition:        database.WorkspaceTransitionStart,
+			Reason:            database.BuildReasonInitiator,
+			ProvisionerState: []byte(`{"version":4,"resources":[` +
+				`{"mode":"managed","type":"coder_agent","name":"main","instances":[{"attributes":{"token":"secret"}}]},` +
+				`{"mode":"managed","type":"docker_container","name":"main","instances":[{"attributes":{}}]}]}`),
+		})
+		file := dbgen.File(t, srv.Database, database.File{CreatedBy: user.ID})
+		_ = dbgen.ProvisionerJob(t, srv.Database, database.ProvisionerJob{
+			ID:            build.JobID,
+			InitiatorID:   user.ID,
+			Provisioner:   database.ProvisionerTypeTerraform,
+			StorageMethod: database.ProvisionerStorageMethodFile,
+			FileID:        file.ID,
+			Type:          database.ProvisionerJobTypeWorkspaceBuild,
+			Input: must(json.Marshal(provisionerdserver.WorkspaceProvisionJob{
+				WorkspaceBuildID:  build.ID,
+				RotateAgentTokens: true,
+			})),
+		})
+
+		job, err := srv.AcquireJob(ctx, nil)
+		require.NoError(t, err)
+		workspaceBuild, ok := job.Type.(*proto.AcquiredJob_WorkspaceBuild_)
+		require.True(t, ok)
+		require.JSONEq(t, `{"version":4,"resources":[`+
+			`{"mode":"managed","type":"coder_agent","name":"main","instances":[{"attributes":{"token":"secret"},"status":"tainted"}]},`+
+			`{"mode":"managed","type":"docker_container","name":"main","instances":[{"attributes":{}}]}]}`,
+			string(workspaceBuild.WorkspaceBuild.State))
+	})
 	t.Run("TemplateVersionDryRun", func(t *testing.T) {
 		t.Parallel()
 		srv := setup(t, false)
@@ -354,6 +519,7 @@ func TestUpdateJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeTemplateVersionDryRun,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = srv.UpdateJob(ctx, &proto.UpdateJobRequest{
@@ -370,6 +536,7 @@ func TestUpdateJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeTemplateVersionDryRun,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = srv.Database.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -392,6 +559,7 @@ func TestUpdateJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			Type:          database.ProvisionerJobTypeTemplateVersionImport,
 			StorageMethod: database.ProvisionerStorageMethodFile,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = srv.Database.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -413,6 +581,27 @@ func TestUpdateJob(t *testing.T) {
 			JobId: job.String(),
 		})
 		require.NoError(t, err)
+		updated, err := srv.Database.GetProvisionerJobByID(ctx, job)
+		require.NoError(t, err)
+		require.True(t, updated.LastHeartbeatAt.Valid)
+	})
+
+	t.Run("Completed", func(t *testing.T) {
+		t.Parallel()
+		srv := setup(t, false)
+		job := setupJob(t, srv)
+		// The job was failed for being hung, and the daemon came back.
+		err := srv.Database.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
+			ID:          job,
+			CompletedAt: sql.NullTime{Time: database.Now(), Valid: true},
+			Error:       sql.NullString{String: "hung", Valid: true},
+		})
+		require.NoError(t, err)
+		res, err := srv.UpdateJob(ctx, &proto.UpdateJobRequest{
+			JobId: job.String(),
+		})
+		require.NoError(t, err)
+		require.True(t, res.Canceled)
 	})
 
 	t.Run("Logs", func(t *testing.T) {
@@ -578,6 +767,7 @@ func TestFailJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeTemplateVersionImport,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = srv.Database.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -601,6 +791,7 @@ func TestFailJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			Type:          database.ProvisionerJobTypeTemplateVersionImport,
 			StorageMethod: database.ProvisionerStorageMethodFile,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = srv.Database.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -652,6 +843,7 @@ func TestFailJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			Type:          database.ProvisionerJobTypeWorkspaceBuild,
 			StorageMethod: database.ProvisionerStorageMethodFile,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = srv.Database.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -718,6 +910,7 @@ func TestCompleteJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeWorkspaceBuild,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = srv.Database.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -748,6 +941,7 @@ func TestCompleteJob(t *testing.T) {
 			Input:         []byte(`{"template_version_id": "` + version.ID.String() + `"}`),
 			StorageMethod: database.ProvisionerStorageMethodFile,
 			Type:          database.ProvisionerJobTypeWorkspaceBuild,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = srv.Database.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -808,6 +1002,7 @@ func TestCompleteJob(t *testing.T) {
 			Input:         input,
 			Type:          database.ProvisionerJobTypeWorkspaceBuild,
 			StorageMethod: database.ProvisionerStorageMethodFile,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = srv.Database.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -862,6 +1057,7 @@ func TestCompleteJob(t *testing.T) {
 			Provisioner:   database.ProvisionerTypeEcho,
 			Type:          database.ProvisionerJobTypeTemplateVersionDryRun,
 			StorageMethod: database.ProvisionerStorageMethodFile,
+			Priority:      database.ProvisionerJobPriorityInteractive,
 		})
 		require.NoError(t, err)
 		_, err = srv.Database.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
@@ -998,6 +1194,24 @@ func setup(t *testing.T, ignoreLogErrors bool) *provisionerdserver.Server {
 	}
 }
 
+func acquireProvisionerJob(t *testing.T, db database.Store) database.ProvisionerJob {
+	t.Helper()
+	job, err := db.AcquireProvisionerJob(context.Background(), database.AcquireProvisionerJobParams{
+		StartedAt: sql.NullTime{
+			Time:  database.Now(),
+			Valid: true,
+		},
+		WorkerID: uuid.NullUUID{
+			UUID:  uuid.New(),
+			Valid: true,
+		},
+		Types: []database.ProvisionerType{database.ProvisionerTypeEcho},
+		Tags:  json.RawMessage("{}"),
+	})
+	require.NoError(t, err)
+	return job
+}
+
 func must[T any](value T, err error) T {
 	if err != nil {
 		panic(err)
@@ -9,16 +9,19 @@ import (
 	"net/http"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"golang.org/x/xerrors"
 	"nhooyr.io/websocket"
 
 	"cdr.dev/slog"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/provisionerdserver"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/codersdk"
 )
@@ -30,6 +33,8 @@ import (
 // 2. GET /logs?after=<id>&follow
 // The combination of these responses should provide all current logs
 // to the consumer, and future logs are streamed in the follow request.
+// Logs can be filtered with the level, stage and search query params, and
+// downloaded as text or NDJSON with the format query param.
 func (api *API) provisionerJobLogs(rw http.ResponseWriter, r *http.Request, job database.ProvisionerJob) {
 	var (
 		ctx       = r.Context()
@@ -45,6 +50,16 @@ func (api *API) provisionerJobLogs(rw http.ResponseWriter, r *http.Request, job
 		})
 		return
 	}
+	filter, format, ok := parseProvisionerJobLogsQuery(ctx, rw, r)
+	if !ok {
+		return
+	}
+	if format != codersdk.ProvisionerJobLogsFormatJSON && follow {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Query param \"format\" cannot be used with \"follow\".",
+		})
+		return
+	}
 
 	// if we are following logs, start the subscription before we query the database, so that we don't miss any logs
 	// between the end of our query and the start of the subscription.  We might get duplicates, so we'll keep track
@@ -123,13 +138,11 @@ func (api *API) provisionerJobLogs(rw http.ResponseWriter, r *http.Request, job
 		})
 		return
 	}
-	if logs == nil {
-		logs = []database.ProvisionerJobLog{}
-	}
+	logs = filter.apply(logs)
 
 	if !follow {
 		logger.Debug(ctx, "Finished non-follow job logs")
-		httpapi.Write(ctx, rw, http.StatusOK, convertProvisionerJobLogs(logs))
+		writeProvisionerJobLogs(ctx, rw, job, format, convertProvisionerJobLogs(logs))
 		return
 	}
 
@@ -180,7 +193,7 @@ func (api *API) provisionerJobLogs(rw http.ResponseWriter, r *http.Request, job
 			if logIdsDone[log.ID] {
 				logger.Debug(ctx, "subscribe duplicated log",
 					slog.F("stage", log.Stage))
-			} else {
+			} else if filter.match(log) {
 				logger.Debug(ctx, "subscribe encoding log",
 					slog.F("stage", log.Stage))
 				err = encoder.Encode(convertProvisionerJobLog(log))
@@ -192,6 +205,116 @@ func (api *API) provisionerJobLogs(rw http.ResponseWriter, r *http.Request, job
 	}
 }
 
+// provisionerJobLogsFilter selects the provisioner job logs that match the
+// level, stage and search query params.
+type provisionerJobLogsFilter struct {
+	// minLevel is the index of the lowest level to return in
+	// database.AllLogLevelValues.
+	minLevel int
+	stage    string
+	search   string
+}
+
+func (f provisionerJobLogsFilter) match(log database.ProvisionerJobLog) bool {
+	if logLevelIndex(log.Level) < f.minLevel {
+		return false
+	}
+	if f.stage != "" && log.Stage != f.stage {
+		return false
+	}
+	if f.search != "" && !strings.Contains(strings.ToLower(log.Output), f.search) {
+		return false
+	}
+	return true
+}
+
+func (f provisionerJobLogsFilter) apply(logs []database.ProvisionerJobLog) []database.ProvisionerJobLog {
+	filtered := make([]database.ProvisionerJobLog, 0, len(logs))
+	for _, log := range logs {
+		if f.match(log) {
+			filtered = append(filtered, log)
+		}
+	}
+	return filtered
+}
+
+func logLevelIndex(level database.LogLevel) int {
+	for i, l := range database.AllLogLevelValues() {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseProvisionerJobLogsQuery parses the filter and format query params of
+// the logs endpoints. If it returns false, an error has been written.
+func parseProvisionerJobLogsQuery(ctx context.Context, rw http.ResponseWriter, r *http.Request) (provisionerJobLogsFilter, codersdk.ProvisionerJobLogsFormat, bool) {
+	parser := httpapi.NewQueryParamParser()
+	level := parser.String(r.URL.Query(), "", "level")
+	filter := provisionerJobLogsFilter{
+		stage:  parser.String(r.URL.Query(), "", "stage"),
+		search: strings.ToLower(parser.String(r.URL.Query(), "", "search")),
+	}
+	format := codersdk.ProvisionerJobLogsFormat(parser.String(r.URL.Query(), string(codersdk.ProvisionerJobLogsFormatJSON), "format"))
+	if level != "" {
+		filter.minLevel = logLevelIndex(database.LogLevel(level))
+		if filter.minLevel < 0 {
+			parser.Errors = append(parser.Errors, codersdk.ValidationError{
+				Field:  "level",
+				Detail: fmt.Sprintf("Unsupported level %q, expected one of %q.", level, database.AllLogLevelValues()),
+			})
+		}
+	}
+	switch format {
+	case codersdk.ProvisionerJobLogsFormatJSON, codersdk.ProvisionerJobLogsFormatText, codersdk.ProvisionerJobLogsFormatNDJSON:
+	default:
+		parser.Errors = append(parser.Errors, codersdk.ValidationError{
+			Field:  "format",
+			Detail: fmt.Sprintf("Unsupported format %q, expected \"json\", \"text\" or \"ndjson\".", format),
+		})
+	}
+	if len(parser.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: parser.Errors,
+		})
+		return provisionerJobLogsFilter{}, "", false
+	}
+	return filter, format, true
+}
+
+// writeProvisionerJobLogs writes logs in the given format. Text and NDJSON
+// are written as attachments, so they can be downloaded from a browser.
+func writeProvisionerJobLogs(ctx context.Context, rw http.ResponseWriter, job database.ProvisionerJob, format codersdk.ProvisionerJobLogsFormat, logs []codersdk.ProvisionerJobLog) {
+	switch format {
+	case codersdk.ProvisionerJobLogsFormatText:
+		rw.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("job-%s-logs.txt", job.ID)))
+		rw.WriteHeader(http.StatusOK)
+		for _, log := range logs {
+			_, err := fmt.Fprintln(rw, log.Text())
+			if err != nil {
+				return
+			}
+		}
+	case codersdk.ProvisionerJobLogsFormatNDJSON:
+		rw.Header().Set("Content-Type", "application/x-ndjson")
+		rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("job-%s-logs.ndjson", job.ID)))
+		rw.WriteHeader(http.StatusOK)
+		// The encoder writes a newline after every log.
+		encoder := json.NewEncoder(rw)
+		for _, log := range logs {
+			err := encoder.Encode(log)
+			if err != nil {
+				return
+			}
+		}
+	default:
+		httpapi.Write(ctx, rw, http.StatusOK, logs)
+	}
+}
+
 func (api *API) provisionerJobResources(rw http.ResponseWriter, r *http.Request, job database.ProvisionerJob) {
 	ctx := r.Context()
 	if !job.CompletedAt.Valid {
@@ -315,6 +438,7 @@ func convertProvisionerJob(provisionerJob database.ProvisionerJob) codersdk.Prov
 		Error:     provisionerJob.Error.String,
 		FileID:    provisionerJob.FileID,
 		Tags:      provisionerJob.Tags,
+		Priority:  codersdk.ProvisionerJobPriority(provisionerJob.Priority),
 	}
 	// Applying values optional to the struct.
 	if provisionerJob.StartedAt.Valid {
@@ -334,6 +458,99 @@ func convertProvisionerJob(provisionerJob database.ProvisionerJob) codersdk.Prov
 	return job
 }
 
+// requestedProvisionerJobPriority returns the priority of a job requested by a
+// user, which defaults to interactive. Interactive is the highest priority, so
+// users can only lower the priority of their jobs.
+func requestedProvisionerJobPriority(priority codersdk.ProvisionerJobPriority) (database.ProvisionerJobPriority, bool) {
+	if priority == "" {
+		return database.ProvisionerJobPriorityInteractive, true
+	}
+	converted := database.ProvisionerJobPriority(priority)
+	return converted, converted.Valid()
+}
+
+// setProvisionerJobQueues sets the position of pending jobs in their queue,
+// and how long they're expected to wait.
+func (api *API) setProvisionerJobQueues(ctx context.Context, jobs ...*codersdk.ProvisionerJob) error {
+	jobIDs := make([]uuid.UUID, 0, len(jobs))
+	for _, job := range jobs {
+		if job.Status == codersdk.ProvisionerJobPending {
+			jobIDs = append(jobIDs, job.ID)
+		}
+	}
+	if len(jobIDs) == 0 {
+		return nil
+	}
+	rows, err := api.Database.GetProvisionerJobQueuePositionsByIDs(ctx, jobIDs)
+	if err != nil {
+		return xerrors.Errorf("get provisioner job queue positions: %w", err)
+	}
+	// Users can't read provisioner daemons, but they can see whether one is
+	// able to run their jobs.
+	//nolint:gocritic // Only whether a daemon can acquire the job is returned.
+	daemons, err := api.Database.GetProvisionerDaemons(dbauthz.AsSystemRestricted(ctx))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return xerrors.Errorf("get provisioner daemons: %w", err)
+	}
+	now := database.Now()
+	queueByJobID := make(map[uuid.UUID]*codersdk.ProvisionerJobQueue, len(rows))
+	for _, row := range rows {
+		queue := convertProvisionerJobQueue(row)
+		queue.Warning = provisionerJobQueueWarning(row, daemons, now)
+		queueByJobID[row.ID] = queue
+	}
+	for _, job := range jobs {
+		job.Queue = queueByJobID[job.ID]
+	}
+	return nil
+}
+
+func convertProvisionerJobQueue(row database.GetProvisionerJobQueuePositionsByIDsRow) *codersdk.ProvisionerJobQueue {
+	queue := &codersdk.ProvisionerJobQueue{
+		Position: int(row.QueuePosition),
+		Size:     int(row.QueueSize),
+	}
+	// Without recent jobs there's nothing to estimate the wait from.
+	if row.AverageDurationMs > 0 {
+		// The running jobs are used as the number of daemons working through
+		// the queue, since all of them will be busy while there's a queue.
+		daemons := row.RunningJobs
+		if daemons < 1 {
+			daemons = 1
+		}
+		// Each daemon finishes its running job and then its share of the
+		// jobs ahead of this one before the job is acquired.
+		rounds := (row.QueuePosition + daemons - 1) / daemons
+		wait := rounds * row.AverageDurationMs
+		queue.EstimatedWaitMillis = &wait
+	}
+	return queue
+}
+
+// provisionerJobQueueWarning explains why a pending job won't be acquired,
+// if no online provisioner daemon can acquire it.
+func provisionerJobQueueWarning(row database.GetProvisionerJobQueuePositionsByIDsRow, daemons []database.ProvisionerDaemon, now time.Time) string {
+	online := 0
+	for _, daemon := range daemons {
+		if !provisionerdserver.DaemonOnline(daemon, now) {
+			continue
+		}
+		if provisionerdserver.DaemonCanAcquire(daemon, row.Provisioner, row.Tags) {
+			return ""
+		}
+		online++
+	}
+	if online == 0 {
+		return "No provisioner daemons are online, so the job won't start until one connects."
+	}
+	tags := make([]string, 0, len(row.Tags))
+	for key, value := range row.Tags {
+		tags = append(tags, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(tags)
+	return fmt.Sprintf("None of the online provisioner daemons can run the job. It requires the %s provisioner and the tags %s.", row.Provisioner, strings.Join(tags, " "))
+}
+
 func ConvertProvisionerJobStatus(provisionerJob database.ProvisionerJob) codersdk.ProvisionerJobStatus {
 	switch {
 	case provisionerJob.CanceledAt.Valid:
@@ -2,11 +2,16 @@ package coderd_test
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/provisioner/echo"
 	"github.com/coder/coder/provisionersdk/proto"
 	"github.com/coder/coder/testutil"
@@ -121,4 +126,228 @@ func TestProvisionerJobLogs(t *testing.T) {
 		require.NoError(t, err)
 		require.Greater(t, len(logs), 1)
 	})
+
+	t.Run("Filter", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionApply: logResponses(),
+		})
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		// The echo provisioner returns the logs when planning and applying.
+		logs, err := client.WorkspaceBuildLogs(ctx, workspace.LatestBuild.ID, codersdk.ProvisionerJobLogsFilter{
+			Level: codersdk.LogLevelWarn,
+		})
+		require.NoError(t, err)
+		require.Len(t, logs, 2)
+		for _, log := range logs {
+			require.Equal(t, "failed to apply", log.Output)
+		}
+
+		logs, err = client.WorkspaceBuildLogs(ctx, workspace.LatestBuild.ID, codersdk.ProvisionerJobLogsFilter{
+			Search: "EXAMPLE",
+		})
+		require.NoError(t, err)
+		require.Len(t, logs, 2)
+		for _, log := range logs {
+			require.Equal(t, "example output", log.Output)
+		}
+
+		stage := logs[1].Stage
+		logs, err = client.WorkspaceBuildLogs(ctx, workspace.LatestBuild.ID, codersdk.ProvisionerJobLogsFilter{
+			Stage:  stage,
+			Search: "apply",
+		})
+		require.NoError(t, err)
+		require.Len(t, logs, 1)
+		require.Equal(t, stage, logs[0].Stage)
+		require.Equal(t, "failed to apply", logs[0].Output)
+
+		_, err = client.WorkspaceBuildLogs(ctx, workspace.LatestBuild.ID, codersdk.ProvisionerJobLogsFilter{
+			Level: "verbose",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse:          echo.ParseComplete,
+			ProvisionApply: logResponses(),
+		})
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		filter := codersdk.ProvisionerJobLogsFilter{Level: codersdk.LogLevelInfo}
+		logs, err := client.WorkspaceBuildLogs(ctx, workspace.LatestBuild.ID, filter)
+		require.NoError(t, err)
+		require.NotEmpty(t, logs)
+
+		body, err := client.DownloadWorkspaceBuildLogs(ctx, workspace.LatestBuild.ID, codersdk.ProvisionerJobLogsFormatText, filter)
+		require.NoError(t, err)
+		text, err := io.ReadAll(body)
+		_ = body.Close()
+		require.NoError(t, err)
+		var expected strings.Builder
+		for _, log := range logs {
+			_, _ = expected.WriteString(log.Text() + "\n")
+		}
+		require.Equal(t, expected.String(), string(text))
+		require.Contains(t, string(text), "[error] Starting workspace: failed to apply")
+
+		body, err = client.DownloadWorkspaceBuildLogs(ctx, workspace.LatestBuild.ID, codersdk.ProvisionerJobLogsFormatNDJSON, filter)
+		require.NoError(t, err)
+		defer body.Close()
+		decoder := json.NewDecoder(body)
+		for _, expected := range logs {
+			var log codersdk.ProvisionerJobLog
+			require.NoError(t, decoder.Decode(&log))
+			require.Equal(t, expected.ID, log.ID)
+			require.Equal(t, expected.Output, log.Output)
+		}
+		require.False(t, decoder.More())
+
+		_, err = client.DownloadWorkspaceBuildLogs(ctx, workspace.LatestBuild.ID, "xml", filter)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
+
+// logResponses returns provision responses that log an info and an error
+// message.
+func logResponses() []*proto.Provision_Response {
+	return []*proto.Provision_Response{{
+		Type: &proto.Provision_Response_Log{
+			Log: &proto.Log{
+				Level:  proto.LogLevel_INFO,
+				Output: "example output",
+			},
+		},
+	}, {
+		Type: &proto.Provision_Response_Log{
+			Log: &proto.Log{
+				Level:  proto.LogLevel_ERROR,
+				Output: "failed to apply",
+			},
+		},
+	}, {
+		Type: &proto.Provision_Response_Complete{
+			Complete: &proto.Provision_Complete{},
+		},
+	}}
+}
+
+func TestProvisionerJobQueue(t *testing.T) {
+	t.Parallel()
+	t.Run("TemplateVersions", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		first := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		require.Equal(t, codersdk.ProvisionerJobPriorityBackground, first.Job.Priority)
+		requireQueuePosition(t, first.Job.Queue, 1, 1)
+		require.Contains(t, first.Job.Queue.Warning, "No provisioner daemons are online")
+		second := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		requireQueuePosition(t, second.Job.Queue, 2, 2)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		first, err := client.TemplateVersion(ctx, first.ID)
+		require.NoError(t, err)
+		requireQueuePosition(t, first.Job.Queue, 1, 2)
+	})
+
+	t.Run("UnsatisfiedTags", func(t *testing.T) {
+		t.Parallel()
+		client, closer := coderdtest.NewWithProvisionerCloser(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		// The daemon is still online after it's closed, but it won't acquire
+		// the jobs.
+		require.NoError(t, closer.Close())
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		require.Empty(t, version.Job.Queue.Warning)
+		version = coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil, func(req *codersdk.CreateTemplateVersionRequest) {
+			req.ProvisionerTags = map[string]string{"os": "windows"}
+		})
+		require.Equal(t, "None of the online provisioner daemons can run the job. It requires the echo provisioner and the tags os=windows scope=organization.", version.Job.Queue.Warning)
+	})
+
+	t.Run("WorkspaceBuilds", func(t *testing.T) {
+		t.Parallel()
+		client, closer := coderdtest.NewWithProvisionerCloser(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		// Stop the daemon, so builds stay queued.
+		require.NoError(t, closer.Close())
+
+		interactive := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		require.Equal(t, codersdk.ProvisionerJobPriorityInteractive, interactive.LatestBuild.Job.Priority)
+		requireQueuePosition(t, interactive.LatestBuild.Job.Queue, 1, 1)
+		background := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(req *codersdk.CreateWorkspaceRequest) {
+			req.Priority = codersdk.ProvisionerJobPriorityBackground
+		})
+		require.Equal(t, codersdk.ProvisionerJobPriorityBackground, background.LatestBuild.Job.Priority)
+		requireQueuePosition(t, background.LatestBuild.Job.Queue, 2, 2)
+		// Interactive builds are queued before background builds.
+		another := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		requireQueuePosition(t, another.LatestBuild.Job.Queue, 2, 3)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		build, err := client.WorkspaceBuild(ctx, background.LatestBuild.ID)
+		require.NoError(t, err)
+		requireQueuePosition(t, build.Job.Queue, 3, 3)
+	})
+
+	t.Run("InvalidPriority", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.CreateWorkspace(ctx, user.OrganizationID, codersdk.Me, codersdk.CreateWorkspaceRequest{
+			TemplateID: template.ID,
+			Name:       "workspace",
+			Priority:   "urgent",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
+
+func requireQueuePosition(t *testing.T, queue *codersdk.ProvisionerJobQueue, position, size int) {
+	t.Helper()
+	require.NotNil(t, queue)
+	require.Equal(t, position, queue.Position)
+	require.Equal(t, size, queue.Size)
 }
@@ -70,6 +70,14 @@ var (
 		Type: "provisioner_daemon",
 	}
 
+	// ResourceProvisionerKey is a key external provisioner daemons in an
+	// organization authenticate with.
+	//	create/delete = create or revoke a key
+	//	read = list the keys of an organization
+	ResourceProvisionerKey = Object{
+		Type: "provisioner_key",
+	}
+
 	// ResourceOrganization CRUD. Has an org owner on all but 'create'.
 	//	create/delete = make or delete organizations
 	// 	read = view org information (Can add user owner for read)
@@ -151,6 +159,15 @@ var (
 		Type: "replicas",
 	}
 
+	// ResourceWebhook is an outbound webhook subscription. Webhooks are
+	// either site wide or scoped to an organization.
+	//	create/delete = add or remove a webhook
+	//	read = view webhooks and their delivery logs
+	//	update = change the events, URL or enabled state of a webhook
+	ResourceWebhook = Object{
+		Type: "webhook",
+	}
+
 	// ResourceDebugInfo controls access to the debug routes `/api/v2/debug/*`.
 	ResourceDebugInfo = Object{
 		Type: "debug_info",
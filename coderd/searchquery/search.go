@@ -1,13 +1,18 @@
 package searchquery
 
 import (
+	"database/sql"
+	"encoding/json"
 	"fmt"
+	"net"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
-
+	"github.com/tabbed/pqtype"
+	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/coderd/database"
@@ -15,28 +20,43 @@ import (
 	"github.com/coder/coder/codersdk"
 )
 
+const dateLayout = "2006-01-02"
+
 func AuditLogs(query string) (database.GetAuditLogsOffsetParams, []codersdk.ValidationError) {
 	// Always lowercase for all searches.
 	query = strings.ToLower(query)
 	values, errors := searchTerms(query, func(term string, values url.Values) error {
-		values.Add("resource_type", term)
+		// A bare resource type filters by resource type. Any other terms are
+		// searched for in the diffs.
+		if database.ResourceType(term).Valid() {
+			values.Add("resource_type", term)
+			return nil
+		}
+		term = strings.Trim(term, `"`)
+		if search := values.Get("search"); search != "" {
+			term = search + " " + term
+		}
+		values.Set("search", term)
 		return nil
 	})
 	if len(errors) > 0 {
 		return database.GetAuditLogsOffsetParams{}, errors
 	}
 
-	const dateLayout = "2006-01-02"
 	parser := httpapi.NewQueryParamParser()
 	filter := database.GetAuditLogsOffsetParams{
-		ResourceID:   parser.UUID(values, uuid.Nil, "resource_id"),
-		Username:     parser.String(values, "", "username"),
-		Email:        parser.String(values, "", "email"),
-		DateFrom:     parser.Time(values, time.Time{}, "date_from", dateLayout),
-		DateTo:       parser.Time(values, time.Time{}, "date_to", dateLayout),
-		ResourceType: string(httpapi.ParseCustom(parser, values, "", "resource_type", httpapi.ParseEnum[database.ResourceType])),
-		Action:       string(httpapi.ParseCustom(parser, values, "", "action", httpapi.ParseEnum[database.AuditAction])),
-		BuildReason:  string(httpapi.ParseCustom(parser, values, "", "build_reason", httpapi.ParseEnum[database.BuildReason])),
+		ResourceID:     parser.UUID(values, uuid.Nil, "resource_id"),
+		ResourceTarget: parser.String(values, "", "resource_target"),
+		Username:       parser.String(values, "", "username"),
+		Email:          parser.String(values, "", "email"),
+		DateFrom:       parser.Time(values, time.Time{}, "date_from", dateLayout),
+		DateTo:         parser.Time(values, time.Time{}, "date_to", dateLayout),
+		ResourceType:   string(httpapi.ParseCustom(parser, values, "", "resource_type", httpapi.ParseEnum[database.ResourceType])),
+		Action:         string(httpapi.ParseCustom(parser, values, "", "action", httpapi.ParseEnum[database.AuditAction])),
+		BuildReason:    string(httpapi.ParseCustom(parser, values, "", "build_reason", httpapi.ParseEnum[database.BuildReason])),
+		Ip:             httpapi.ParseCustom(parser, values, pqtype.Inet{}, "ip", parseIPOrCIDR),
+		RequestID:      parser.UUID(values, uuid.Nil, "request_id"),
+		Search:         parser.String(values, "", "search"),
 	}
 	if !filter.DateTo.IsZero() {
 		filter.DateTo = filter.DateTo.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
@@ -45,6 +65,33 @@ func AuditLogs(query string) (database.GetAuditLogsOffsetParams, []codersdk.Vali
 	return filter, parser.Errors
 }
 
+// parseIPOrCIDR parses an IP address, which matches only itself, or a CIDR
+// range.
+func parseIPOrCIDR(v string) (pqtype.Inet, error) {
+	if strings.Contains(v, "/") {
+		_, ipNet, err := net.ParseCIDR(v)
+		if err != nil {
+			return pqtype.Inet{}, xerrors.Errorf("%q is not a valid CIDR range", v)
+		}
+		return pqtype.Inet{IPNet: *ipNet, Valid: true}, nil
+	}
+
+	ip := net.ParseIP(v)
+	if ip == nil {
+		return pqtype.Inet{}, xerrors.Errorf("%q is not a valid IP address", v)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return pqtype.Inet{
+		IPNet: net.IPNet{
+			IP:   ip,
+			Mask: net.CIDRMask(len(ip)*8, len(ip)*8),
+		},
+		Valid: true,
+	}, nil
+}
+
 func Users(query string) (database.GetUsersParams, []codersdk.ValidationError) {
 	// Always lowercase for all searches.
 	query = strings.ToLower(query)
@@ -58,15 +105,67 @@ func Users(query string) (database.GetUsersParams, []codersdk.ValidationError) {
 
 	parser := httpapi.NewQueryParamParser()
 	filter := database.GetUsersParams{
-		Search:   parser.String(values, "", "search"),
-		Status:   httpapi.ParseCustomList(parser, values, []database.UserStatus{}, "status", httpapi.ParseEnum[database.UserStatus]),
-		RbacRole: parser.Strings(values, []string{}, "role"),
+		Search:         parser.String(values, "", "search"),
+		Status:         httpapi.ParseCustomList(parser, values, []database.UserStatus{}, "status", httpapi.ParseEnum[database.UserStatus]),
+		RbacRole:       parser.Strings(values, []string{}, "role"),
+		LoginType:      httpapi.ParseCustomList(parser, values, []database.LoginType{}, "login_type", httpapi.ParseEnum[database.LoginType]),
+		LastSeenBefore: parser.Time(values, time.Time{}, "last_seen_before", dateLayout),
+		CreatedAfter:   parser.Time(values, time.Time{}, "created_after", dateLayout),
+		GroupName:      parser.String(values, "", "group"),
+		OrganizationID: parser.UUID(values, uuid.Nil, "organization"),
 	}
 	parser.ErrorExcessParams(values)
+	// Group names are only unique within an organization.
+	if filter.GroupName != "" && filter.OrganizationID == uuid.Nil {
+		parser.Errors = append(parser.Errors, codersdk.ValidationError{
+			Field:  "group",
+			Detail: `Query param "group" requires the "organization" query param.`,
+		})
+	}
 	return filter, parser.Errors
 }
 
-func Workspaces(query string, page codersdk.Pagination, agentInactiveDisconnectTimeout time.Duration) (database.GetWorkspacesParams, []codersdk.ValidationError) {
+func Templates(query string) (database.GetTemplatesWithFilterParams, []codersdk.ValidationError) {
+	// Always lowercase for all searches.
+	query = strings.ToLower(query)
+	values, errors := searchTerms(query, func(term string, values url.Values) error {
+		values.Add("search", term)
+		return nil
+	}, "search")
+	if len(errors) > 0 {
+		return database.GetTemplatesWithFilterParams{}, errors
+	}
+	// Free text is searched as a whole, "foo bar" is the same as
+	// search:"foo bar".
+	if len(values["search"]) > 1 {
+		values.Set("search", strings.Join(values["search"], " "))
+	}
+
+	parser := httpapi.NewQueryParamParser()
+	filter := database.GetTemplatesWithFilterParams{
+		FuzzyName:           parser.String(values, "", "name"),
+		Provisioner:         string(httpapi.ParseCustom(parser, values, "", "provisioner", httpapi.ParseEnum[database.ProvisionerType])),
+		CreatedByUsername:   parser.String(values, "", "created_by"),
+		HasActiveWorkspaces: httpapi.ParseCustom(parser, values, sql.NullBool{}, "has_active_workspaces", parseNullBool),
+		Deprecated:          httpapi.ParseCustom(parser, values, sql.NullBool{}, "deprecated", parseNullBool),
+		Search:              parser.String(values, "", "search"),
+	}
+	parser.ErrorExcessParams(values)
+	return filter, parser.Errors
+}
+
+func parseNullBool(term string) (sql.NullBool, error) {
+	value, err := strconv.ParseBool(term)
+	if err != nil {
+		return sql.NullBool{}, xerrors.Errorf("%q is not a valid boolean", term)
+	}
+	return sql.NullBool{Bool: value, Valid: true}, nil
+}
+
+// Workspaces parses a workspace search query. Terms are ANDed together and
+// "or" splits the query into groups that are ORed together, so each group is
+// returned as its own set of params. Prefixing a term with "-" negates it.
+func Workspaces(query string, page codersdk.Pagination, agentInactiveDisconnectTimeout time.Duration) ([]database.GetWorkspacesParams, []codersdk.ValidationError) {
 	filter := database.GetWorkspacesParams{
 		AgentInactiveDisconnectTimeoutSeconds: int64(agentInactiveDisconnectTimeout.Seconds()),
 
@@ -74,13 +173,67 @@ func Workspaces(query string, page codersdk.Pagination, agentInactiveDisconnectT
 		Limit:  int32(page.Limit),
 	}
 
-	if query == "" {
-		return filter, nil
-	}
-
 	// Always lowercase for all searches.
 	query = strings.ToLower(query)
-	values, errors := searchTerms(query, func(term string, values url.Values) error {
+	elements := splitQueryParameterByDelimiter(query, ' ', true)
+	if len(elements) == 0 {
+		return []database.GetWorkspacesParams{filter}, nil
+	}
+
+	var (
+		filters []database.GetWorkspacesParams
+		group   []string
+	)
+	for i := 0; i <= len(elements); i++ {
+		if i < len(elements) && elements[i] != "or" {
+			group = append(group, elements[i])
+			continue
+		}
+		if len(group) == 0 {
+			return nil, []codersdk.ValidationError{
+				{Field: "q", Detail: `Query element "or" must be placed between search terms`},
+			}
+		}
+		groupFilter, errors := workspaceSearchGroup(filter, group)
+		if len(errors) > 0 {
+			return nil, errors
+		}
+		filters = append(filters, groupFilter)
+		group = nil
+	}
+	return filters, nil
+}
+
+// workspaceSearchGroup parses the elements of a query that are ANDed together.
+func workspaceSearchGroup(filter database.GetWorkspacesParams, elements []string) (database.GetWorkspacesParams, []codersdk.ValidationError) {
+	negated := make(map[string]bool)
+	terms := make([]string, 0, len(elements))
+	for _, element := range elements {
+		term, isNegated := strings.CutPrefix(element, "-")
+		if isNegated {
+			key := "name"
+			parts := splitQueryParameterByDelimiter(term, ':', false)
+			switch {
+			case term == "":
+				return filter, []codersdk.ValidationError{
+					{Field: "q", Detail: "Query element \"-\" must be followed by a search term"},
+				}
+			case len(parts) > 1:
+				key = parts[0]
+			case strings.Contains(term, "/"):
+				return filter, []codersdk.ValidationError{
+					{Field: "q", Detail: fmt.Sprintf("Query element %q cannot negate an owner and name together", element)},
+				}
+			}
+			if key == "has-agent" {
+				key = "agent_status"
+			}
+			negated[key] = true
+		}
+		terms = append(terms, term)
+	}
+
+	values, errors := searchTerms(strings.Join(terms, " "), func(term string, values url.Values) error {
 		// It is a workspace name, and maybe includes an owner
 		parts := splitQueryParameterByDelimiter(term, '/', false)
 		switch len(parts) {
@@ -93,10 +246,20 @@ func Workspaces(query string, page codersdk.Pagination, agentInactiveDisconnectT
 			return xerrors.Errorf("Query element %q can only contain 1 '/'", term)
 		}
 		return nil
-	})
+	}, "label")
 	if len(errors) > 0 {
 		return filter, errors
 	}
+	if len(values["label"]) > 1 {
+		if negated["label"] {
+			return filter, []codersdk.ValidationError{
+				{Field: "q", Detail: `Query parameter "label" cannot be negated when provided more than once, use "-label:a=b,c=d" instead`},
+			}
+		}
+		// Workspaces must have all labels, "label:a=b label:c=d" is the same as
+		// "label:a=b,c=d".
+		values.Set("label", strings.Join(values["label"], ","))
+	}
 
 	parser := httpapi.NewQueryParamParser()
 	filter.OwnerUsername = parser.String(values, "", "owner")
@@ -104,11 +267,89 @@ func Workspaces(query string, page codersdk.Pagination, agentInactiveDisconnectT
 	filter.Name = parser.String(values, "", "name")
 	filter.Status = string(httpapi.ParseCustom(parser, values, "", "status", httpapi.ParseEnum[database.WorkspaceStatus]))
 	filter.HasAgent = parser.String(values, "", "has-agent")
+	if agentStatus := httpapi.ParseCustom(parser, values, "", "agent_status", parseAgentStatus); agentStatus != "" {
+		if filter.HasAgent != "" {
+			parser.Errors = append(parser.Errors, codersdk.ValidationError{
+				Field:  "agent_status",
+				Detail: `Query param "agent_status" cannot be used with "has-agent"`,
+			})
+		}
+		filter.HasAgent = string(agentStatus)
+	}
+	filter.BuildReason = string(httpapi.ParseCustom(parser, values, "", "build_reason", httpapi.ParseEnum[database.BuildReason]))
+	filter.Outdated = booleanFilter(parser, values, "outdated", negated)
+	filter.HasFailedBuild = booleanFilter(parser, values, "has_failed_build", negated)
+	filter.LastUsedBefore = parser.Time(values, time.Time{}, "last_used_before", dateLayout)
+	filter.LastUsedAfter = parser.Time(values, time.Time{}, "last_used_after", dateLayout)
+	labels := httpapi.ParseCustomList(parser, values, []workspaceLabel{}, "label", parseWorkspaceLabel)
+	if len(labels) > 0 {
+		labelMap := make(map[string]string, len(labels))
+		for _, label := range labels {
+			labelMap[label.key] = label.value
+		}
+		data, err := json.Marshal(labelMap)
+		if err != nil {
+			parser.Errors = append(parser.Errors, codersdk.ValidationError{
+				Field:  "label",
+				Detail: fmt.Sprintf("Marshal labels: %s", err.Error()),
+			})
+		}
+		filter.Labels = data
+	}
 	parser.ErrorExcessParams(values)
+
+	for key, isNegated := range negated {
+		if isNegated {
+			filter.NegatedFilters = append(filter.NegatedFilters, key)
+		}
+	}
+	slices.Sort(filter.NegatedFilters)
 	return filter, parser.Errors
 }
 
-func searchTerms(query string, defaultKey func(term string, values url.Values) error) (url.Values, []codersdk.ValidationError) {
+// booleanFilter parses a boolean filter that is only applied when set.
+// "key:false" is stored as the negation of "key:true".
+func booleanFilter(parser *httpapi.QueryParamParser, values url.Values, key string, negated map[string]bool) bool {
+	value := parser.Boolean(values, true, key)
+	if values.Get(key) == "" {
+		return false
+	}
+	if !value {
+		negated[key] = !negated[key]
+	}
+	return true
+}
+
+func parseAgentStatus(term string) (codersdk.WorkspaceAgentStatus, error) {
+	status := codersdk.WorkspaceAgentStatus(term)
+	switch status {
+	case codersdk.WorkspaceAgentConnecting, codersdk.WorkspaceAgentConnected,
+		codersdk.WorkspaceAgentDisconnected, codersdk.WorkspaceAgentTimeout:
+		return status, nil
+	}
+	return "", xerrors.Errorf("%q is not a valid value", term)
+}
+
+type workspaceLabel struct {
+	key   string
+	value string
+}
+
+// parseWorkspaceLabel parses a "key=value" label filter.
+func parseWorkspaceLabel(term string) (workspaceLabel, error) {
+	key, value, ok := strings.Cut(term, "=")
+	if !ok {
+		return workspaceLabel{}, xerrors.Errorf("label %q must be in the format key=value", term)
+	}
+	if err := httpapi.WorkspaceLabelValid(key, value); err != nil {
+		return workspaceLabel{}, err
+	}
+	return workspaceLabel{key: key, value: value}, nil
+}
+
+// searchTerms splits the query into its key:value elements. Only the keys in
+// repeatable may be provided more than once.
+func searchTerms(query string, defaultKey func(term string, values url.Values) error, repeatable ...string) (url.Values, []codersdk.ValidationError) {
 	searchValues := make(url.Values)
 
 	// Because we do this in 2 passes, we want to maintain quotes on the first
@@ -147,7 +388,7 @@ func searchTerms(query string, defaultKey func(term string, values url.Values) e
 	}
 
 	for k := range searchValues {
-		if len(searchValues[k]) > 1 {
+		if len(searchValues[k]) > 1 && !slices.Contains(repeatable, k) {
 			return nil, []codersdk.ValidationError{
 				{
 					Field:  "q",
@@ -1,12 +1,16 @@
 package searchquery_test
 
 import (
+	"database/sql"
 	"fmt"
+	"net"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"github.com/tabbed/pqtype"
 
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/rbac"
@@ -17,9 +21,11 @@ import (
 func TestSearchWorkspace(t *testing.T) {
 	t.Parallel()
 	testCases := []struct {
-		Name                  string
-		Query                 string
-		Expected              database.GetWorkspacesParams
+		Name     string
+		Query    string
+		Expected database.GetWorkspacesParams
+		// ExpectedGroups is set for queries that contain "or".
+		ExpectedGroups        []database.GetWorkspacesParams
 		ExpectedErrorContains string
 	}{
 		{
@@ -115,6 +121,68 @@ func TestSearchWorkspace(t *testing.T) {
 				OwnerUsername: "foo",
 			},
 		},
+		{
+			Name:  "Labels",
+			Query: `label:project=coder LABEL:Team=Dev`,
+			Expected: database.GetWorkspacesParams{
+				Labels: []byte(`{"project":"coder","team":"dev"}`),
+			},
+		},
+		{
+			Name:  "LabelsList",
+			Query: `name:foo label:"project=coder,cost-center="`,
+			Expected: database.GetWorkspacesParams{
+				Name:   "foo",
+				Labels: []byte(`{"cost-center":"","project":"coder"}`),
+			},
+		},
+		{
+			Name:  "NewFilters",
+			Query: `outdated:true has_failed_build:true agent_status:disconnected build_reason:autostart last_used_before:2023-02-01 last_used_after:2023-01-01`,
+			Expected: database.GetWorkspacesParams{
+				Outdated:       true,
+				HasFailedBuild: true,
+				HasAgent:       "disconnected",
+				BuildReason:    "autostart",
+				LastUsedBefore: time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+				LastUsedAfter:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			Name:  "FalseBoolean",
+			Query: `outdated:false -has_failed_build:false`,
+			Expected: database.GetWorkspacesParams{
+				Outdated:       true,
+				HasFailedBuild: true,
+				NegatedFilters: []string{"outdated"},
+			},
+		},
+		{
+			Name:  "Negation",
+			Query: `-status:running -template:docker -foo -has-agent:connected owner:me`,
+			Expected: database.GetWorkspacesParams{
+				Status:         "running",
+				TemplateName:   "docker",
+				Name:           "foo",
+				HasAgent:       "connected",
+				OwnerUsername:  "me",
+				NegatedFilters: []string{"agent_status", "name", "status", "template"},
+			},
+		},
+		{
+			Name:  "Or",
+			Query: `owner:me status:running OR -outdated:true`,
+			ExpectedGroups: []database.GetWorkspacesParams{
+				{
+					OwnerUsername: "me",
+					Status:        "running",
+				},
+				{
+					Outdated:       true,
+					NegatedFilters: []string{"outdated"},
+				},
+			},
+		},
 
 		// Failures
 		{
@@ -137,6 +205,46 @@ func TestSearchWorkspace(t *testing.T) {
 			Query:                 `owner:name:extra`,
 			ExpectedErrorContains: "can only contain 1 ':'",
 		},
+		{
+			Name:                  "LabelWithoutValue",
+			Query:                 `label:project`,
+			ExpectedErrorContains: `Query param "label" has invalid values`,
+		},
+		{
+			Name:                  "InvalidLabel",
+			Query:                 `label:"project name=coder"`,
+			ExpectedErrorContains: `Query param "label" has invalid values`,
+		},
+		{
+			Name:                  "DanglingOr",
+			Query:                 `status:running or`,
+			ExpectedErrorContains: `"or" must be placed between search terms`,
+		},
+		{
+			Name:                  "DoubleOr",
+			Query:                 `status:running or or status:stopped`,
+			ExpectedErrorContains: `"or" must be placed between search terms`,
+		},
+		{
+			Name:                  "NegateOwnerName",
+			Query:                 `-foo/bar`,
+			ExpectedErrorContains: "cannot negate an owner and name together",
+		},
+		{
+			Name:                  "NegateMultipleLabels",
+			Query:                 `-label:a=b label:c=d`,
+			ExpectedErrorContains: `"label" cannot be negated`,
+		},
+		{
+			Name:                  "InvalidAgentStatus",
+			Query:                 `agent_status:sleeping`,
+			ExpectedErrorContains: `Query param "agent_status" has invalid value`,
+		},
+		{
+			Name:                  "InvalidBoolean",
+			Query:                 `outdated:maybe`,
+			ExpectedErrorContains: `must be a valid boolean`,
+		},
 		{
 			Name:                  "ExtraKeys",
 			Query:                 `foo:bar`,
@@ -158,7 +266,11 @@ func TestSearchWorkspace(t *testing.T) {
 				require.Contains(t, s.String(), c.ExpectedErrorContains)
 			} else {
 				require.Len(t, errs, 0, "expected no error")
-				require.Equal(t, c.Expected, values, "expected values")
+				expected := c.ExpectedGroups
+				if expected == nil {
+					expected = []database.GetWorkspacesParams{c.Expected}
+				}
+				require.Equal(t, expected, values, "expected values")
 			}
 		})
 	}
@@ -169,7 +281,8 @@ func TestSearchWorkspace(t *testing.T) {
 		timeout := 1337 * time.Second
 		values, errs := searchquery.Workspaces(query, codersdk.Pagination{}, timeout)
 		require.Empty(t, errs)
-		require.Equal(t, int64(timeout.Seconds()), values.AgentInactiveDisconnectTimeoutSeconds)
+		require.Len(t, values, 1)
+		require.Equal(t, int64(timeout.Seconds()), values[0].AgentInactiveDisconnectTimeoutSeconds)
 	})
 }
 
@@ -186,12 +299,74 @@ func TestSearchAudit(t *testing.T) {
 			Query:    "",
 			Expected: database.GetAuditLogsOffsetParams{},
 		},
+		{
+			Name:  "ResourceType",
+			Query: "workspace",
+			Expected: database.GetAuditLogsOffsetParams{
+				ResourceType: string(database.ResourceTypeWorkspace),
+			},
+		},
+		{
+			Name:  "FreeText",
+			Query: `workspace "my-template" autostart_schedule`,
+			Expected: database.GetAuditLogsOffsetParams{
+				ResourceType: string(database.ResourceTypeWorkspace),
+				Search:       "my-template autostart_schedule",
+			},
+		},
+		{
+			Name:  "Dates",
+			Query: "date_from:2023-01-01 date_to:2023-01-31",
+			Expected: database.GetAuditLogsOffsetParams{
+				DateFrom: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+				DateTo:   time.Date(2023, 1, 31, 23, 59, 59, 0, time.UTC),
+			},
+		},
+		{
+			Name:  "IP",
+			Query: "ip:127.0.0.1",
+			Expected: database.GetAuditLogsOffsetParams{
+				Ip: pqtype.Inet{
+					IPNet: net.IPNet{IP: net.IPv4(127, 0, 0, 1).To4(), Mask: net.CIDRMask(32, 32)},
+					Valid: true,
+				},
+			},
+		},
+		{
+			Name:  "CIDR",
+			Query: "ip:10.0.0.0/8",
+			Expected: database.GetAuditLogsOffsetParams{
+				Ip: pqtype.Inet{
+					IPNet: net.IPNet{IP: net.IPv4(10, 0, 0, 0).To4(), Mask: net.CIDRMask(8, 32)},
+					Valid: true,
+				},
+			},
+		},
+		{
+			Name:  "RequestIDBuildReasonResourceTarget",
+			Query: "request_id:b7d6b3a5-6f0a-4c59-bb26-0e4d1a3c5f52 build_reason:autostart resource_target:dev",
+			Expected: database.GetAuditLogsOffsetParams{
+				RequestID:      uuid.MustParse("b7d6b3a5-6f0a-4c59-bb26-0e4d1a3c5f52"),
+				BuildReason:    string(database.BuildReasonAutostart),
+				ResourceTarget: "dev",
+			},
+		},
 		// Failures
 		{
 			Name:                  "ExtraColon",
 			Query:                 `search:name:extra`,
 			ExpectedErrorContains: "can only contain 1 ':'",
 		},
+		{
+			Name:                  "InvalidIP",
+			Query:                 "ip:10.0.0",
+			ExpectedErrorContains: "not a valid IP address",
+		},
+		{
+			Name:                  "InvalidCIDR",
+			Query:                 "ip:10.0.0.0/33",
+			ExpectedErrorContains: "not a valid CIDR range",
+		},
 		{
 			Name:                  "ExtraKeys",
 			Query:                 `foo:bar`,
@@ -236,62 +411,69 @@ func TestSearchUsers(t *testing.T) {
 			Name:  "Empty",
 			Query: "",
 			Expected: database.GetUsersParams{
-				Status:   []database.UserStatus{},
-				RbacRole: []string{},
+				Status:    []database.UserStatus{},
+				RbacRole:  []string{},
+				LoginType: []database.LoginType{},
 			},
 		},
 		{
 			Name:  "Username",
 			Query: "user-name",
 			Expected: database.GetUsersParams{
-				Search:   "user-name",
-				Status:   []database.UserStatus{},
-				RbacRole: []string{},
+				Search:    "user-name",
+				Status:    []database.UserStatus{},
+				RbacRole:  []string{},
+				LoginType: []database.LoginType{},
 			},
 		},
 		{
 			Name:  "UsernameWithSpaces",
 			Query: "   user-name    ",
 			Expected: database.GetUsersParams{
-				Search:   "user-name",
-				Status:   []database.UserStatus{},
-				RbacRole: []string{},
+				Search:    "user-name",
+				Status:    []database.UserStatus{},
+				RbacRole:  []string{},
+				LoginType: []database.LoginType{},
 			},
 		},
 		{
 			Name:  "Username+Param",
 			Query: "usEr-name stAtus:actiVe",
 			Expected: database.GetUsersParams{
-				Search:   "user-name",
-				Status:   []database.UserStatus{database.UserStatusActive},
-				RbacRole: []string{},
+				Search:    "user-name",
+				Status:    []database.UserStatus{database.UserStatusActive},
+				RbacRole:  []string{},
+				LoginType: []database.LoginType{},
 			},
 		},
 		{
 			Name:  "OnlyParams",
 			Query: "status:acTIve sEArch:User-Name role:Owner",
 			Expected: database.GetUsersParams{
-				Search:   "user-name",
-				Status:   []database.UserStatus{database.UserStatusActive},
-				RbacRole: []string{rbac.RoleOwner()},
+				Search:    "user-name",
+				Status:    []database.UserStatus{database.UserStatusActive},
+				RbacRole:  []string{rbac.RoleOwner()},
+				LoginType: []database.LoginType{},
 			},
 		},
 		{
 			Name:  "QuotedParam",
 			Query: `status:SuSpenDeD sEArch:"User Name" role:meMber`,
 			Expected: database.GetUsersParams{
-				Search:   "user name",
-				Status:   []database.UserStatus{database.UserStatusSuspended},
-				RbacRole: []string{rbac.RoleMember()},
+				Search:    "user name",
+				Status:    []database.UserStatus{database.UserStatusSuspended},
+				RbacRole:  []string{rbac.RoleMember()},
+				LoginType: []database.LoginType{},
 			},
 		},
 		{
 			Name:  "QuotedKey",
 			Query: `"status":acTIve "sEArch":User-Name "role":Owner`,
 			Expected: database.GetUsersParams{
-				Search:   "user-name",
-				Status:   []database.UserStatus{database.UserStatusActive},
-				RbacRole: []string{rbac.RoleOwner()},
+				Search:    "user-name",
+				Status:    []database.UserStatus{database.UserStatusActive},
+				RbacRole:  []string{rbac.RoleOwner()},
+				LoginType: []database.LoginType{},
 			},
 		},
 		{
@@ -299,9 +481,24 @@ func TestSearchUsers(t *testing.T) {
 			Name:  "QuotedSpecial",
 			Query: `search:"user:name"`,
 			Expected: database.GetUsersParams{
-				Search:   "user:name",
-				Status:   []database.UserStatus{},
-				RbacRole: []string{},
+				Search:    "user:name",
+				Status:    []database.UserStatus{},
+				RbacRole:  []string{},
+				LoginType: []database.LoginType{},
+			},
+		},
+
+		{
+			Name:  "NewFilters",
+			Query: `login_type:oidc,github group:Admins organization:bb2f7c8a-79a3-4c2e-8b41-1f1a6b0f3c11 last_seen_before:2023-02-01 created_after:2023-01-01`,
+			Expected: database.GetUsersParams{
+				Status:         []database.UserStatus{},
+				RbacRole:       []string{},
+				LoginType:      []database.LoginType{database.LoginTypeOIDC, database.LoginTypeGithub},
+				GroupName:      "admins",
+				OrganizationID: uuid.MustParse("bb2f7c8a-79a3-4c2e-8b41-1f1a6b0f3c11"),
+				LastSeenBefore: time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+				CreatedAfter:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
 			},
 		},
 
@@ -316,6 +513,21 @@ func TestSearchUsers(t *testing.T) {
 			Query:                 "status:inActive",
 			ExpectedErrorContains: "has invalid values",
 		},
+		{
+			Name:                  "GroupWithoutOrganization",
+			Query:                 "group:admins",
+			ExpectedErrorContains: `requires the "organization" query param`,
+		},
+		{
+			Name:                  "InvalidLoginType",
+			Query:                 "login_type:saml",
+			ExpectedErrorContains: "has invalid values",
+		},
+		{
+			Name:                  "InvalidDate",
+			Query:                 "created_after:yesterday",
+			ExpectedErrorContains: "must be a valid date format",
+		},
 		{
 			Name:                  "ExtraKeys",
 			Query:                 `foo:bar`,
@@ -342,3 +554,73 @@ func TestSearchUsers(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchTemplates(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		Name                  string
+		Query                 string
+		Expected              database.GetTemplatesWithFilterParams
+		ExpectedErrorContains string
+	}{
+		{
+			Name:     "Empty",
+			Query:    "",
+			Expected: database.GetTemplatesWithFilterParams{},
+		},
+		{
+			Name:  "Search",
+			Query: "Docker   Image",
+			Expected: database.GetTemplatesWithFilterParams{
+				Search: "docker image",
+			},
+		},
+		{
+			Name:  "Params",
+			Query: `name:docker provisioner:terraform created_by:me has_active_workspaces:false deprecated:true`,
+			Expected: database.GetTemplatesWithFilterParams{
+				FuzzyName:           "docker",
+				Provisioner:         "terraform",
+				CreatedByUsername:   "me",
+				HasActiveWorkspaces: sql.NullBool{Bool: false, Valid: true},
+				Deprecated:          sql.NullBool{Bool: true, Valid: true},
+			},
+		},
+
+		// Failures
+		{
+			Name:                  "InvalidProvisioner",
+			Query:                 "provisioner:ansible",
+			ExpectedErrorContains: `Query param "provisioner" has invalid value`,
+		},
+		{
+			Name:                  "InvalidBoolean",
+			Query:                 "has_active_workspaces:maybe",
+			ExpectedErrorContains: `"maybe" is not a valid boolean`,
+		},
+		{
+			Name:                  "ExtraKeys",
+			Query:                 `foo:bar`,
+			ExpectedErrorContains: `Query param "foo" is not a valid query param`,
+		},
+	}
+
+	for _, c := range testCases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			t.Parallel()
+			values, errs := searchquery.Templates(c.Query)
+			if c.ExpectedErrorContains != "" {
+				require.True(t, len(errs) > 0, "expect some errors")
+				var s strings.Builder
+				for _, err := range errs {
+					_, _ = s.WriteString(fmt.Sprintf("%s: %s\n", err.Field, err.Detail))
+				}
+				require.Contains(t, s.String(), c.ExpectedErrorContains)
+			} else {
+				require.Len(t, errs, 0, "expected no error")
+				require.Equal(t, c.Expected, values, "expected values")
+			}
+		})
+	}
+}
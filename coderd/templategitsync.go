@@ -0,0 +1,304 @@
+package coderd
+
+import (
+	"crypto/hmac"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/gitsource"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/webhooks"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/cryptorand"
+	"github.com/coder/coder/provisionersdk"
+)
+
+const (
+	// gitHubSignatureHeader is sent by GitHub and Gitea with the HMAC-SHA256
+	// of the webhook payload.
+	gitHubSignatureHeader = "X-Hub-Signature-256"
+	// gitLabTokenHeader is sent by GitLab with the secret token of the
+	// webhook.
+	gitLabTokenHeader = "X-Gitlab-Token"
+)
+
+// @Summary Get template git sync by template ID
+// @ID get-template-git-sync-by-template-id
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Success 200 {object} codersdk.TemplateGitSync
+// @Router /templates/{template}/git-sync [get]
+func (api *API) templateGitSync(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		template = httpmw.TemplateParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionRead, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	gitSync, err := api.Database.GetTemplateGitSyncByTemplateID(ctx, template.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusNotFound, codersdk.Response{
+			Message: "The template isn't synced with git.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template git sync.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	converted := api.convertTemplateGitSync(gitSync)
+	converted.WebhookSecret = ""
+	httpapi.Write(ctx, rw, http.StatusOK, converted)
+}
+
+// @Summary Update template git sync by template ID
+// @Description Sets up the template to be synced with a git branch. The webhook secret is only returned by this endpoint.
+// @ID update-template-git-sync-by-template-id
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param request body codersdk.UpdateTemplateGitSyncRequest true "Git sync request"
+// @Success 200 {object} codersdk.TemplateGitSync
+// @Router /templates/{template}/git-sync [put]
+func (api *API) putTemplateGitSync(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		template = httpmw.TemplateParam(r)
+		apiKey   = httpmw.APIKey(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if api.gitSyncer == nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Template git syncs are disabled on this deployment.",
+		})
+		return
+	}
+
+	var req codersdk.UpdateTemplateGitSyncRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	opts := gitsource.Options{
+		URL:       req.URL,
+		Ref:       req.Branch,
+		Directory: req.Directory,
+		Limit:     provisionersdk.TemplateArchiveLimit,
+	}
+	err := opts.Validate()
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid git source.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	gitAuthConfig, err := api.gitSourceCredentials(ctx, apiKey.UserID, &opts)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching git auth credentials.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	// Resolve the branch up front, so a typo doesn't leave a sync that
+	// never succeeds.
+	_, err = gitsource.Resolve(ctx, opts)
+	if err != nil {
+		message := fmt.Sprintf("Failed to find the branch in %q.", req.URL)
+		if gitAuthConfig != nil && opts.Username == "" && opts.Password == "" {
+			message += fmt.Sprintf(" If the repository is private, authenticate with %q first.", gitAuthConfig.ID)
+		}
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: message,
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	secret, err := cryptorand.String(32)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error generating webhook secret.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	gitSync, err := api.Database.UpsertTemplateGitSync(ctx, database.UpsertTemplateGitSyncParams{
+		TemplateID:    template.ID,
+		CreatedAt:     database.Now(),
+		UpdatedAt:     database.Now(),
+		UserID:        apiKey.UserID,
+		GitURL:        req.URL,
+		GitBranch:     req.Branch,
+		GitDirectory:  req.Directory,
+		WebhookSecret: secret,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating template git sync.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	api.gitSyncer.Trigger(template.ID)
+
+	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplateGitSync(gitSync))
+}
+
+// @Summary Delete template git sync by template ID
+// @ID delete-template-git-sync-by-template-id
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Success 200 {object} codersdk.Response
+// @Router /templates/{template}/git-sync [delete]
+func (api *API) deleteTemplateGitSync(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		template = httpmw.TemplateParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	err := api.Database.DeleteTemplateGitSyncByTemplateID(ctx, template.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting template git sync.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: "The template is no longer synced with git.",
+	})
+}
+
+// postTemplateGitSyncWebhook syncs the template when its repository is pushed
+// to. The request is authenticated with the webhook secret of the sync, so
+// it can be sent by a git provider.
+//
+// @Summary Trigger template git sync
+// @ID trigger-template-git-sync
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param X-Hub-Signature-256 header string false "HMAC-SHA256 of the payload, signed with the webhook secret"
+// @Param X-Gitlab-Token header string false "Webhook secret"
+// @Success 202 {object} codersdk.Response
+// @Router /templates/{template}/git-sync/webhook [post]
+func (api *API) postTemplateGitSyncWebhook(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	templateID, err := uuid.Parse(chi.URLParam(r, "template"))
+	if err != nil {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	// The request isn't authenticated with an API key, so the sync is read
+	// as the system before the secret is checked.
+	//nolint:gocritic // Webhooks are authenticated with the sync's secret.
+	gitSync, err := api.Database.GetTemplateGitSyncByTemplateID(dbauthz.AsSystemRestricted(ctx), templateID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template git sync.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, 10<<20))
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Failed to read the webhook payload.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if !verifyGitSyncWebhook(r, gitSync.WebhookSecret, payload) {
+		httpapi.Write(ctx, rw, http.StatusUnauthorized, codersdk.Response{
+			Message: "The webhook signature is invalid.",
+		})
+		return
+	}
+	if api.gitSyncer != nil {
+		api.gitSyncer.Trigger(templateID)
+	}
+
+	httpapi.Write(ctx, rw, http.StatusAccepted, codersdk.Response{
+		Message: "The template will be synced.",
+	})
+}
+
+// verifyGitSyncWebhook checks the webhook was sent with the secret, either as
+// a signature of the payload or as a token.
+func verifyGitSyncWebhook(r *http.Request, secret string, payload []byte) bool {
+	if signature := r.Header.Get(gitHubSignatureHeader); signature != "" {
+		return hmac.Equal([]byte(signature), []byte(webhooks.Sign(secret, payload)))
+	}
+	if token := r.Header.Get(gitLabTokenHeader); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+	}
+	return false
+}
+
+func (api *API) convertTemplateGitSync(gitSync database.TemplateGitSync) codersdk.TemplateGitSync {
+	converted := codersdk.TemplateGitSync{
+		TemplateID:    gitSync.TemplateID,
+		URL:           gitSync.GitURL,
+		Branch:        gitSync.GitBranch,
+		Directory:     gitSync.GitDirectory,
+		UserID:        gitSync.UserID,
+		UpdatedAt:     gitSync.UpdatedAt,
+		CommitSHA:     gitSync.CommitSHA,
+		Status:        codersdk.TemplateGitSyncStatus(gitSync.Status),
+		Error:         gitSync.Error,
+		WebhookSecret: gitSync.WebhookSecret,
+		WebhookURL: api.AccessURL.ResolveReference(&url.URL{
+			Path: fmt.Sprintf("/api/v2/templates/%s/git-sync/webhook", gitSync.TemplateID),
+		}).String(),
+	}
+	if gitSync.TemplateVersionID.Valid {
+		converted.TemplateVersionID = &gitSync.TemplateVersionID.UUID
+	}
+	if gitSync.CheckedAt.Valid {
+		converted.CheckedAt = &gitSync.CheckedAt.Time
+	}
+	return converted
+}
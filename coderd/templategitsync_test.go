@@ -0,0 +1,206 @@
+package coderd_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"path"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/gitsource/gitsourcetest"
+	"github.com/coder/coder/coderd/webhooks"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestTemplateGitSync(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Sync", func(t *testing.T) {
+		t.Parallel()
+		repo := gitsourcetest.NewRepo(t)
+		firstSHA := repo.Commit(echoFiles(t, "docker", "# first"))
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+			TemplateGitSyncInterval:  testutil.IntervalFast,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.TemplateGitSync(ctx, template.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		// try a branch that doesn't exist
+		_, err = client.UpdateTemplateGitSync(ctx, template.ID, codersdk.UpdateTemplateGitSyncRequest{
+			URL:    repo.Server.URL,
+			Branch: "nope",
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		gitSync, err := client.UpdateTemplateGitSync(ctx, template.ID, codersdk.UpdateTemplateGitSyncRequest{
+			URL:       repo.Server.URL,
+			Branch:    "main",
+			Directory: "docker",
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, gitSync.WebhookSecret)
+		require.Contains(t, gitSync.WebhookURL, template.ID.String())
+		require.Equal(t, user.UserID, gitSync.UserID)
+
+		awaitGitSync(ctx, t, client, template.ID, firstSHA, codersdk.TemplateGitSyncStatusSucceeded)
+		active := activeVersion(ctx, t, client, template.ID)
+		require.NotNil(t, active.GitSource)
+		require.Equal(t, firstSHA, active.GitSource.CommitSHA)
+		require.Equal(t, "main", active.GitSource.Ref)
+		require.Equal(t, "docker", active.GitSource.Directory)
+
+		// A new commit is promoted to the active version.
+		secondSHA := repo.Commit(echoFiles(t, "docker", "# second"))
+		awaitGitSync(ctx, t, client, template.ID, secondSHA, codersdk.TemplateGitSyncStatusSucceeded)
+		require.Equal(t, secondSHA, activeVersion(ctx, t, client, template.ID).GitSource.CommitSHA)
+
+		// A commit that fails to import doesn't change the active version.
+		thirdSHA := repo.Commit(gitsourcetest.EchoFiles(t, "docker", &echo.Responses{
+			Parse: echo.ParseComplete,
+			ProvisionPlan: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{Error: "bad template"},
+				},
+			}},
+		}))
+		gitSync = awaitGitSync(ctx, t, client, template.ID, thirdSHA, codersdk.TemplateGitSyncStatusFailed)
+		require.Contains(t, gitSync.Error, "bad template")
+		require.Empty(t, gitSync.WebhookSecret)
+		require.Equal(t, secondSHA, activeVersion(ctx, t, client, template.ID).GitSource.CommitSHA)
+
+		err = client.DeleteTemplateGitSync(ctx, template.ID)
+		require.NoError(t, err)
+		_, err = client.TemplateGitSync(ctx, template.ID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("Webhook", func(t *testing.T) {
+		t.Parallel()
+		repo := gitsourcetest.NewRepo(t)
+		firstSHA := repo.Commit(echoFiles(t, "", "# first"))
+		client := coderdtest.New(t, &coderdtest.Options{
+			IncludeProvisionerDaemon: true,
+			// Only webhooks trigger a sync within the test.
+			TemplateGitSyncInterval: testutil.WaitSuperLong,
+		})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		gitSync, err := client.UpdateTemplateGitSync(ctx, template.ID, codersdk.UpdateTemplateGitSyncRequest{
+			URL: repo.Server.URL,
+		})
+		require.NoError(t, err)
+
+		sendWebhook := func(header, value string) int {
+			payload := []byte(`{"ref":"refs/heads/main"}`)
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, gitSync.WebhookURL, bytes.NewReader(payload))
+			require.NoError(t, err)
+			if header == "X-Hub-Signature-256" {
+				value = webhooks.Sign(value, payload)
+			}
+			if header != "" {
+				req.Header.Set(header, value)
+			}
+			res, err := client.HTTPClient.Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+			return res.StatusCode
+		}
+		// Setting up the sync creates a version for the latest commit, which
+		// is promoted on the next sync.
+		require.Eventually(t, func() bool {
+			_ = sendWebhook("X-Gitlab-Token", gitSync.WebhookSecret)
+			current, err := client.TemplateGitSync(ctx, template.ID)
+			return err == nil && current.CommitSHA == firstSHA &&
+				current.Status == codersdk.TemplateGitSyncStatusSucceeded
+		}, testutil.WaitLong, testutil.IntervalMedium)
+
+		require.Equal(t, http.StatusUnauthorized, sendWebhook("", ""))
+		require.Equal(t, http.StatusUnauthorized, sendWebhook("X-Gitlab-Token", "wrong"))
+		require.Equal(t, http.StatusUnauthorized, sendWebhook("X-Hub-Signature-256", "wrong"))
+
+		secondSHA := repo.Commit(echoFiles(t, "", "# second"))
+		require.Eventually(t, func() bool {
+			require.Equal(t, http.StatusAccepted, sendWebhook("X-Hub-Signature-256", gitSync.WebhookSecret))
+			current, err := client.TemplateGitSync(ctx, template.ID)
+			return err == nil && current.CommitSHA == secondSHA &&
+				current.Status == codersdk.TemplateGitSyncStatusSucceeded
+		}, testutil.WaitLong, testutil.IntervalMedium)
+		require.Equal(t, secondSHA, activeVersion(ctx, t, client, template.ID).GitSource.CommitSHA)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+		repo, _ := gitsourcetest.ServeRepo(t, map[string]string{"main.tf": ""})
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.UpdateTemplateGitSync(ctx, template.ID, codersdk.UpdateTemplateGitSyncRequest{
+			URL: repo.URL,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
+
+// awaitGitSync waits for the git sync of the template to reach the status
+// for the commit.
+func awaitGitSync(ctx context.Context, t *testing.T, client *codersdk.Client, templateID uuid.UUID, sha string, status codersdk.TemplateGitSyncStatus) codersdk.TemplateGitSync {
+	t.Helper()
+	var gitSync codersdk.TemplateGitSync
+	require.Eventually(t, func() bool {
+		var err error
+		gitSync, err = client.TemplateGitSync(ctx, templateID)
+		return err == nil && gitSync.CommitSHA == sha && gitSync.Status == status
+	}, testutil.WaitLong, testutil.IntervalFast)
+	return gitSync
+}
+
+// echoFiles returns the files of an echo template that imports successfully,
+// with the content of main.tf changed so each commit is different.
+func echoFiles(t *testing.T, directory, mainTF string) map[string]string {
+	t.Helper()
+	files := gitsourcetest.EchoFiles(t, directory, nil)
+	files[path.Join(directory, "main.tf")] = mainTF
+	return files
+}
+
+func activeVersion(ctx context.Context, t *testing.T, client *codersdk.Client, templateID uuid.UUID) codersdk.TemplateVersion {
+	t.Helper()
+	template, err := client.Template(ctx, templateID)
+	require.NoError(t, err)
+	version, err := client.TemplateVersion(ctx, template.ActiveVersionID)
+	require.NoError(t, err)
+	return version
+}
@@ -7,18 +7,23 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"golang.org/x/xerrors"
 
+	"cdr.dev/slog"
+
 	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/notifications"
 	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/searchquery"
 	"github.com/coder/coder/coderd/telemetry"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/examples"
@@ -52,7 +57,16 @@ func (api *API) template(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplate(template, createdByNameMap[template.ID.String()]))
+	gitSyncs, err := getGitSyncsByTemplateIDs(ctx, api.Database, []database.Template{template})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching git sync.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplate(template, createdByNameMap[template.ID.String()], gitSyncs[template.ID]))
 }
 
 // @Summary Delete template by ID
@@ -300,7 +314,8 @@ func (api *API) postTemplateByOrganization(rw http.ResponseWriter, r *http.Reque
 			return xerrors.Errorf("get creator name: %w", err)
 		}
 
-		template = api.convertTemplate(dbTemplate, createdByNameMap[dbTemplate.ID.String()])
+		// A new template isn't synced with git yet.
+		template = api.convertTemplate(dbTemplate, createdByNameMap[dbTemplate.ID.String()], nil)
 		return nil
 	}, nil)
 	if err != nil {
@@ -325,11 +340,43 @@ func (api *API) postTemplateByOrganization(rw http.ResponseWriter, r *http.Reque
 // @Produce json
 // @Tags Templates
 // @Param organization path string true "Organization ID" format(uuid)
+// @Param q query string false "Search query"
+// @Param limit query int false "Page limit"
+// @Param offset query int false "Page offset"
 // @Success 200 {array} codersdk.Template
 // @Router /organizations/{organization}/templates [get]
 func (api *API) templatesByOrganization(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	organization := httpmw.OrganizationParam(r)
+	apiKey := httpmw.APIKey(r)
+
+	filter, errs := searchquery.Templates(r.URL.Query().Get("q"))
+	if len(errs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid template search query.",
+			Validations: errs,
+		})
+		return
+	}
+
+	page, ok := parsePagination(rw, r)
+	if !ok {
+		return
+	}
+	if page.AfterID != uuid.Nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Query param \"after_id\" is not supported for templates, use \"offset\" instead.",
+		})
+		return
+	}
+
+	filter.OrganizationID = organization.ID
+	filter.OffsetOpt = int32(page.Offset)
+	filter.LimitOpt = int32(page.Limit)
+	if filter.CreatedByUsername == "me" {
+		filter.CreatedBy = apiKey.UserID
+		filter.CreatedByUsername = ""
+	}
 
 	prepared, err := api.HTTPAuth.AuthorizeSQLFilter(r, rbac.ActionRead, rbac.ResourceTemplate.Type)
 	if err != nil {
@@ -341,9 +388,7 @@ func (api *API) templatesByOrganization(rw http.ResponseWriter, r *http.Request)
 	}
 
 	// Filter templates based on rbac permissions
-	templates, err := api.Database.GetAuthorizedTemplates(ctx, database.GetTemplatesWithFilterParams{
-		OrganizationID: organization.ID,
-	}, prepared)
+	templates, err := api.Database.GetAuthorizedTemplates(ctx, filter, prepared)
 	if errors.Is(err, sql.ErrNoRows) {
 		err = nil
 	}
@@ -365,7 +410,16 @@ func (api *API) templatesByOrganization(rw http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplates(templates, createdByNameMap))
+	gitSyncs, err := getGitSyncsByTemplateIDs(ctx, api.Database, templates)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching git syncs.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplates(templates, createdByNameMap, gitSyncs))
 }
 
 // @Summary Get templates by organization and template name
@@ -412,7 +466,16 @@ func (api *API) templateByOrganizationAndName(rw http.ResponseWriter, r *http.Re
 		return
 	}
 
-	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplate(template, createdByNameMap[template.ID.String()]))
+	gitSyncs, err := getGitSyncsByTemplateIDs(ctx, api.Database, []database.Template{template})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching git sync.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplate(template, createdByNameMap[template.ID.String()], gitSyncs[template.ID]))
 }
 
 // @Summary Update template metadata by ID
@@ -527,7 +590,129 @@ func (api *API) patchTemplateMeta(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplate(updated, createdByNameMap[updated.ID.String()]))
+	gitSyncs, err := getGitSyncsByTemplateIDs(ctx, api.Database, []database.Template{updated})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching git sync.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplate(updated, createdByNameMap[updated.ID.String()], gitSyncs[updated.ID]))
+}
+
+// @Summary Update template deprecation by ID
+// @ID update-template-deprecation-by-id
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Param request body codersdk.UpdateTemplateDeprecation true "Deprecation request"
+// @Success 200 {object} codersdk.Template
+// @Router /templates/{template}/deprecation [put]
+func (api *API) putTemplateDeprecation(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		template          = httpmw.TemplateParam(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.Template](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+	aReq.Old = template
+
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpdateTemplateDeprecation
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	message := strings.TrimSpace(req.Message)
+	if !req.Deprecated {
+		message = ""
+	} else if message == "" {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid request to deprecate template!",
+			Validations: []codersdk.ValidationError{
+				{Field: "message", Detail: "A message is required to deprecate a template."},
+			},
+		})
+		return
+	}
+
+	updated, err := api.Database.UpdateTemplateDeprecatedByID(ctx, database.UpdateTemplateDeprecatedByIDParams{
+		ID:         template.ID,
+		UpdatedAt:  database.Now(),
+		Deprecated: message,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating template deprecation.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = updated
+
+	if template.Deprecated == "" && updated.Deprecated != "" {
+		api.notifyTemplateDeprecated(ctx, updated)
+	}
+
+	createdByNameMap, err := getCreatedByNamesByTemplateIDs(ctx, api.Database, []database.Template{updated})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching creator name.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	gitSyncs, err := getGitSyncsByTemplateIDs(ctx, api.Database, []database.Template{updated})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching git sync.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, api.convertTemplate(updated, createdByNameMap[updated.ID.String()], gitSyncs[updated.ID]))
+}
+
+// notifyTemplateDeprecated emails the owner of every workspace that uses the
+// template. Errors are logged, the template is deprecated regardless.
+func (api *API) notifyTemplateDeprecated(ctx context.Context, template database.Template) {
+	// The user deprecating the template may not be able to read every
+	// workspace that uses it.
+	// nolint:gocritic
+	workspaces, err := api.Database.GetWorkspaces(dbauthz.AsSystemRestricted(ctx), database.GetWorkspacesParams{
+		TemplateIds: []uuid.UUID{template.ID},
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		api.Logger.Warn(ctx, "fetch workspaces of deprecated template", slog.F("template_id", template.ID), slog.Error(err))
+		return
+	}
+	for _, workspace := range workspaces {
+		api.Notifier.Enqueue(notifications.Notification{
+			UserID: workspace.OwnerID,
+			Kind:   codersdk.NotificationKindTemplateDeprecated,
+			Data: map[string]string{
+				"template_name":  template.Name,
+				"workspace_name": workspace.Name,
+				"message":        template.Deprecated,
+			},
+		})
+	}
 }
 
 // @Summary Get template DAUs by ID
@@ -599,11 +784,29 @@ func getCreatedByNamesByTemplateIDs(ctx context.Context, db database.Store, temp
 	return creators, nil
 }
 
-func (api *API) convertTemplates(templates []database.Template, createdByNameMap map[string]string) []codersdk.Template {
+// getGitSyncsByTemplateIDs returns the git syncs of the templates that are
+// synced with git.
+func getGitSyncsByTemplateIDs(ctx context.Context, db database.Store, templates []database.Template) (map[uuid.UUID]*database.TemplateGitSync, error) {
+	ids := make([]uuid.UUID, 0, len(templates))
+	for _, template := range templates {
+		ids = append(ids, template.ID)
+	}
+	gitSyncs, err := db.GetTemplateGitSyncsByTemplateIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byTemplateID := make(map[uuid.UUID]*database.TemplateGitSync, len(gitSyncs))
+	for i := range gitSyncs {
+		byTemplateID[gitSyncs[i].TemplateID] = &gitSyncs[i]
+	}
+	return byTemplateID, nil
+}
+
+func (api *API) convertTemplates(templates []database.Template, createdByNameMap map[string]string, gitSyncs map[uuid.UUID]*database.TemplateGitSync) []codersdk.Template {
 	apiTemplates := make([]codersdk.Template, 0, len(templates))
 
 	for _, template := range templates {
-		apiTemplates = append(apiTemplates, api.convertTemplate(template, createdByNameMap[template.ID.String()]))
+		apiTemplates = append(apiTemplates, api.convertTemplate(template, createdByNameMap[template.ID.String()], gitSyncs[template.ID]))
 	}
 
 	// Sort templates by ActiveUserCount DESC
@@ -614,13 +817,24 @@ func (api *API) convertTemplates(templates []database.Template, createdByNameMap
 	return apiTemplates
 }
 
+// convertTemplate converts the template. The git sync is nil if the template
+// isn't synced with git.
 func (api *API) convertTemplate(
-	template database.Template, createdByName string,
+	template database.Template, createdByName string, gitSync *database.TemplateGitSync,
 ) codersdk.Template {
 	activeCount, _ := api.metricsCache.TemplateUniqueUsers(template.ID)
 
 	buildTimeStats := api.metricsCache.TemplateBuildTimeStats(template.ID)
 
+	var (
+		gitSyncStatus codersdk.TemplateGitSyncStatus
+		gitSyncError  string
+	)
+	if gitSync != nil {
+		gitSyncStatus = codersdk.TemplateGitSyncStatus(gitSync.Status)
+		gitSyncError = gitSync.Error
+	}
+
 	return codersdk.Template{
 		ID:                           template.ID,
 		CreatedAt:                    template.CreatedAt,
@@ -638,5 +852,9 @@ func (api *API) convertTemplate(
 		CreatedByID:                  template.CreatedBy,
 		CreatedByName:                createdByName,
 		AllowUserCancelWorkspaceJobs: template.AllowUserCancelWorkspaceJobs,
+		Deprecated:                   template.Deprecated != "",
+		DeprecationMessage:           template.Deprecated,
+		GitSyncStatus:                gitSyncStatus,
+		GitSyncError:                 gitSyncError,
 	}
 }
@@ -206,6 +206,81 @@ func TestTemplatesByOrganization(t *testing.T) {
 		require.NoError(t, err)
 		require.Len(t, templates, 2)
 	})
+	t.Run("Search", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		docker := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID, func(ctr *codersdk.CreateTemplateRequest) {
+			ctr.Name = "docker"
+			ctr.Description = "Develop in containers on the shared build hosts"
+		})
+		kubernetes := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID, func(ctr *codersdk.CreateTemplateRequest) {
+			ctr.Name = "kubernetes"
+			ctr.Description = "Develop in a pod of the cluster"
+		})
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, kubernetes.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		for query, expected := range map[string][]uuid.UUID{
+			"name:dock":                  {docker.ID},
+			"containers":                 {docker.ID},
+			"develop":                    {docker.ID, kubernetes.ID},
+			"has_active_workspaces:true": {kubernetes.ID},
+			"provisioner:echo created_by:me has_active_workspaces:false": {docker.ID},
+			"provisioner:terraform": {},
+		} {
+			templates, err := client.TemplatesByOrganizationWithFilter(ctx, user.OrganizationID, codersdk.TemplateFilter{
+				SearchQuery: query,
+			})
+			require.NoError(t, err, query)
+			ids := make([]uuid.UUID, 0, len(templates))
+			for _, template := range templates {
+				ids = append(ids, template.ID)
+			}
+			require.ElementsMatch(t, expected, ids, query)
+		}
+
+		_, err := client.TemplatesByOrganizationWithFilter(ctx, user.OrganizationID, codersdk.TemplateFilter{
+			SearchQuery: "provisioner:ansible",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+	t.Run("Pagination", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		for _, name := range []string{"alpha", "beta", "gamma"} {
+			name := name
+			coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID, func(ctr *codersdk.CreateTemplateRequest) {
+				ctr.Name = name
+			})
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		templates, err := client.TemplatesByOrganizationWithFilter(ctx, user.OrganizationID, codersdk.TemplateFilter{
+			Pagination: codersdk.Pagination{Offset: 1, Limit: 1},
+		})
+		require.NoError(t, err)
+		require.Len(t, templates, 1)
+		require.Equal(t, "beta", templates[0].Name)
+
+		_, err = client.TemplatesByOrganizationWithFilter(ctx, user.OrganizationID, codersdk.TemplateFilter{
+			Pagination: codersdk.Pagination{AfterID: templates[0].ID, Limit: 1},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
 }
 
 func TestTemplateByOrganizationAndName(t *testing.T) {
@@ -472,6 +547,116 @@ func TestDeleteTemplate(t *testing.T) {
 	})
 }
 
+func TestTemplateDeprecation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("RequiresMessage", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := client.UpdateTemplateDeprecation(ctx, template.ID, codersdk.UpdateTemplateDeprecation{
+			Deprecated: true,
+			Message:    "  ",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		require.Len(t, apiErr.Validations, 1)
+		require.Equal(t, "message", apiErr.Validations[0].Field)
+	})
+
+	t.Run("BlocksNewWorkspaces", func(t *testing.T) {
+		t.Parallel()
+		auditor := audit.NewMock()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true, Auditor: auditor})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		deprecated, err := client.UpdateTemplateDeprecation(ctx, template.ID, codersdk.UpdateTemplateDeprecation{
+			Deprecated: true,
+			Message:    "Use the new template.",
+		})
+		require.NoError(t, err)
+		require.True(t, deprecated.Deprecated)
+		require.Equal(t, "Use the new template.", deprecated.DeprecationMessage)
+		require.Equal(t, database.AuditActionWrite, auditor.AuditLogs[len(auditor.AuditLogs)-1].Action)
+
+		// Existing workspaces keep working, and see the message.
+		workspace, err = client.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Equal(t, "Use the new template.", workspace.TemplateDeprecationMessage)
+		build, err := client.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionStop,
+		})
+		require.NoError(t, err)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, build.ID)
+
+		_, err = client.CreateWorkspace(ctx, user.OrganizationID, codersdk.Me, codersdk.CreateWorkspaceRequest{
+			TemplateID: template.ID,
+			Name:       "new",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+		require.Equal(t, "Use the new template.", apiErr.Detail)
+
+		templates, err := client.TemplatesByOrganizationWithFilter(ctx, user.OrganizationID, codersdk.TemplateFilter{
+			SearchQuery: "deprecated:false",
+		})
+		require.NoError(t, err)
+		require.Empty(t, templates)
+		templates, err = client.TemplatesByOrganizationWithFilter(ctx, user.OrganizationID, codersdk.TemplateFilter{
+			SearchQuery: "deprecated:true",
+		})
+		require.NoError(t, err)
+		require.Len(t, templates, 1)
+
+		undeprecated, err := client.UpdateTemplateDeprecation(ctx, template.ID, codersdk.UpdateTemplateDeprecation{
+			Deprecated: false,
+		})
+		require.NoError(t, err)
+		require.False(t, undeprecated.Deprecated)
+		require.Empty(t, undeprecated.DeprecationMessage)
+
+		workspace = coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		require.Empty(t, workspace.TemplateDeprecationMessage)
+	})
+
+	t.Run("NotAllowed", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		_, err := member.UpdateTemplateDeprecation(ctx, template.ID, codersdk.UpdateTemplateDeprecation{
+			Deprecated: true,
+			Message:    "Use the new template.",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
+
 func TestTemplateMetrics(t *testing.T) {
 	t.Parallel()
 
@@ -2,9 +2,7 @@ package coderd
 
 import (
 	"context"
-	"crypto/sha256"
 	"database/sql"
-	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -19,7 +17,10 @@ import (
 
 	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/filestore"
 	"github.com/coder/coder/coderd/gitauth"
+	"github.com/coder/coder/coderd/gitsource"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
 	"github.com/coder/coder/coderd/parameter"
@@ -27,6 +28,7 @@ import (
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/examples"
+	"github.com/coder/coder/provisionersdk"
 	sdkproto "github.com/coder/coder/provisionersdk/proto"
 )
 
@@ -68,7 +70,17 @@ func (api *API) templateVersion(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateVersion(templateVersion, convertProvisionerJob(job), user))
+	apiJob := convertProvisionerJob(job)
+	err = api.setProvisionerJobQueues(ctx, &apiJob)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job queue.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertTemplateVersion(templateVersion, apiJob, user))
 }
 
 // @Summary Cancel template version by ID
@@ -134,6 +146,162 @@ func (api *API) patchCancelTemplateVersion(rw http.ResponseWriter, r *http.Reque
 	})
 }
 
+// @Summary Archive template version
+// @ID archive-template-version
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param templateversion path string true "Template version ID" format(uuid)
+// @Success 200 {object} codersdk.Response
+// @Router /templateversions/{templateversion}/archive [post]
+func (api *API) postArchiveTemplateVersion(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		templateVersion   = httpmw.TemplateVersionParam(r)
+		template          = httpmw.TemplateParam(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.TemplateVersion](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+	aReq.Old = templateVersion
+
+	if !api.Authorize(r, rbac.ActionUpdate, templateVersion.RBACObject(template)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if !templateVersion.TemplateID.Valid {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Only versions of a template can be archived.",
+		})
+		return
+	}
+	if templateVersion.Archived {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Template version is already archived.",
+		})
+		return
+	}
+
+	archived, err := api.Database.ArchiveUnusedTemplateVersions(ctx, database.ArchiveUnusedTemplateVersionsParams{
+		UpdatedAt:         database.Now(),
+		TemplateID:        template.ID,
+		TemplateVersionID: templateVersion.ID,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error archiving template version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if len(archived) == 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Template version is in use, and can't be archived.",
+			Detail:  "The active version of a template, versions used by the latest build of a workspace, and versions that are still importing can't be archived.",
+		})
+		return
+	}
+
+	newTemplateVersion := templateVersion
+	newTemplateVersion.Archived = true
+	aReq.New = newTemplateVersion
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: "Template version has been archived.",
+	})
+}
+
+// @Summary Unarchive template version
+// @ID unarchive-template-version
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param templateversion path string true "Template version ID" format(uuid)
+// @Success 200 {object} codersdk.Response
+// @Router /templateversions/{templateversion}/unarchive [post]
+func (api *API) postUnarchiveTemplateVersion(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		templateVersion   = httpmw.TemplateVersionParam(r)
+		template          = httpmw.TemplateParam(r)
+		auditor           = *api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.TemplateVersion](rw, &audit.RequestParams{
+			Audit:   auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+	aReq.Old = templateVersion
+
+	if !api.Authorize(r, rbac.ActionUpdate, templateVersion.RBACObject(template)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if !templateVersion.Archived {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Template version is not archived.",
+		})
+		return
+	}
+
+	// The files of archived versions may have been garbage collected, in
+	// which case the version can never be built again. The file is locked
+	// until the version is unarchived, so it isn't collected in between.
+	var filesDeleted bool
+	err := api.Database.InTx(func(tx database.Store) error {
+		job, err := tx.GetProvisionerJobByID(ctx, templateVersion.JobID)
+		if err != nil {
+			return xerrors.Errorf("get provisioner job: %w", err)
+		}
+		// nolint:gocritic // The file may have been uploaded by another user.
+		_, err = tx.LockFileByID(dbauthz.AsSystemRestricted(ctx), job.FileID)
+		if errors.Is(err, sql.ErrNoRows) {
+			filesDeleted = true
+			return nil
+		}
+		if err != nil {
+			return xerrors.Errorf("lock file: %w", err)
+		}
+		err = tx.UnarchiveTemplateVersion(ctx, database.UnarchiveTemplateVersionParams{
+			UpdatedAt:         database.Now(),
+			TemplateVersionID: templateVersion.ID,
+		})
+		if err != nil {
+			return xerrors.Errorf("unarchive template version: %w", err)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error unarchiving template version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if filesDeleted {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The files of this template version have been garbage collected, so it can't be unarchived.",
+			Detail:  "Push a new template version instead.",
+		})
+		return
+	}
+
+	newTemplateVersion := templateVersion
+	newTemplateVersion.Archived = false
+	aReq.New = newTemplateVersion
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: "Template version has been unarchived.",
+	})
+}
+
 // @Summary Get schema by template version
 // @ID get-schema-by-template-version
 // @Security CoderSessionToken
@@ -542,7 +710,8 @@ func (api *API) postTemplateVersionDryRun(rw http.ResponseWriter, r *http.Reques
 		Type:           database.ProvisionerJobTypeTemplateVersionDryRun,
 		Input:          input,
 		// Copy tags from the previous run.
-		Tags: job.Tags,
+		Tags:     job.Tags,
+		Priority: database.ProvisionerJobPriorityInteractive,
 	})
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -552,7 +721,17 @@ func (api *API) postTemplateVersionDryRun(rw http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	httpapi.Write(ctx, rw, http.StatusCreated, convertProvisionerJob(provisionerJob))
+	apiJob := convertProvisionerJob(provisionerJob)
+	err = api.setProvisionerJobQueues(ctx, &apiJob)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job queue.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusCreated, apiJob)
 }
 
 // @Summary Get template version dry-run by job ID
@@ -571,7 +750,17 @@ func (api *API) templateVersionDryRun(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	httpapi.Write(ctx, rw, http.StatusOK, convertProvisionerJob(job))
+	apiJob := convertProvisionerJob(job)
+	err := api.setProvisionerJobQueues(ctx, &apiJob)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job queue.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, apiJob)
 }
 
 // @Summary Get template version dry-run resources by job ID
@@ -602,6 +791,10 @@ func (api *API) templateVersionDryRunResources(rw http.ResponseWriter, r *http.R
 // @Param before query int false "Before Unix timestamp"
 // @Param after query int false "After Unix timestamp"
 // @Param follow query bool false "Follow log stream"
+// @Param level query string false "Lowest log level to return" Enums(trace,debug,info,warn,error)
+// @Param stage query string false "Only return logs of this stage"
+// @Param search query string false "Only return logs whose output contains this, ignoring case"
+// @Param format query string false "Response format, text and ndjson are returned as attachments" Enums(json,text,ndjson)
 // @Success 200 {array} codersdk.ProvisionerJobLog
 // @Router /templateversions/{templateversion}/dry-run/{jobID}/logs [get]
 func (api *API) templateVersionDryRunLogs(rw http.ResponseWriter, r *http.Request) {
@@ -749,6 +942,7 @@ func (api *API) fetchTemplateVersionDryRunJob(rw http.ResponseWriter, r *http.Re
 // @Param after_id query string false "After ID" format(uuid)
 // @Param limit query int false "Page limit"
 // @Param offset query int false "Page offset"
+// @Param include_archived query bool false "Include archived versions in the list"
 // @Success 200 {array} codersdk.TemplateVersion
 // @Router /templates/{template}/versions [get]
 func (api *API) templateVersionsByTemplate(rw http.ResponseWriter, r *http.Request) {
@@ -764,6 +958,18 @@ func (api *API) templateVersionsByTemplate(rw http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	parser := httpapi.NewQueryParamParser()
+	includeArchived := parser.Boolean(r.URL.Query(), false, "include_archived")
+	if len(parser.Errors) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Query parameters have invalid values.",
+			Validations: parser.Errors,
+		})
+		return
+	}
+	// Archived versions are hidden unless asked for.
+	archivedFilter := sql.NullBool{Bool: false, Valid: !includeArchived}
+
 	var err error
 	apiVersions := []codersdk.TemplateVersion{}
 	err = api.Database.InTx(func(store database.Store) error {
@@ -788,6 +994,7 @@ func (api *API) templateVersionsByTemplate(rw http.ResponseWriter, r *http.Reque
 		versions, err := store.GetTemplateVersionsByTemplateID(ctx, database.GetTemplateVersionsByTemplateIDParams{
 			TemplateID: template.ID,
 			AfterID:    paginationParams.AfterID,
+			Archived:   archivedFilter,
 			LimitOpt:   int32(paginationParams.Limit),
 			OffsetOpt:  int32(paginationParams.Offset),
 		})
@@ -848,6 +1055,46 @@ func (api *API) templateVersionsByTemplate(rw http.ResponseWriter, r *http.Reque
 	httpapi.Write(ctx, rw, http.StatusOK, apiVersions)
 }
 
+// postArchiveTemplateVersions archives every version of a template that no
+// workspace uses.
+//
+// @Summary Archive unused template versions by template ID
+// @ID archive-unused-template-versions-by-template-id
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Templates
+// @Param template path string true "Template ID" format(uuid)
+// @Success 200 {object} codersdk.ArchiveTemplateVersionsResponse
+// @Router /templates/{template}/versions/archive [post]
+func (api *API) postArchiveTemplateVersions(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	template := httpmw.TemplateParam(r)
+	if !api.Authorize(r, rbac.ActionUpdate, template) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	archived, err := api.Database.ArchiveUnusedTemplateVersions(ctx, database.ArchiveUnusedTemplateVersionsParams{
+		UpdatedAt:  database.Now(),
+		TemplateID: template.ID,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error archiving template versions.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if archived == nil {
+		archived = []uuid.UUID{}
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.ArchiveTemplateVersionsResponse{
+		TemplateID:  template.ID,
+		ArchivedIDs: archived,
+	})
+}
+
 // @Summary Get template version by template ID and name
 // @ID get-template-version-by-template-id-and-name
 // @Security CoderSessionToken
@@ -1140,6 +1387,12 @@ func (api *API) patchActiveTemplateVersion(rw http.ResponseWriter, r *http.Reque
 		})
 		return
 	}
+	if version.Archived {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "The provided template version is archived, and can't be promoted.",
+		})
+		return
+	}
 
 	err = api.Database.InTx(func(store database.Store) error {
 		err = store.UpdateTemplateActiveVersionByID(ctx, database.UpdateTemplateActiveVersionByIDParams{
@@ -1164,6 +1417,12 @@ func (api *API) patchActiveTemplateVersion(rw http.ResponseWriter, r *http.Reque
 	aReq.New = newTemplate
 
 	api.publishTemplateUpdate(ctx, template.ID)
+	api.publishWebhookEvent(ctx, codersdk.WebhookEventTemplateVersionPromoted, template.OrganizationID, map[string]string{
+		"template_id":           template.ID.String(),
+		"template_name":         template.Name,
+		"template_version_id":   version.ID.String(),
+		"template_version_name": version.Name,
+	})
 
 	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
 		Message: "Updated the active template version!",
@@ -1275,40 +1534,78 @@ func (api *API) postTemplateVersionsByOrganization(rw http.ResponseWriter, r *ht
 		}
 
 		// upload a copy of the template tar as a file in the database
-		hashBytes := sha256.Sum256(tar)
-		hash := hex.EncodeToString(hashBytes[:])
-		// Check if the file already exists.
-		file, err := api.Database.GetFileByHashAndCreator(ctx, database.GetFileByHashAndCreatorParams{
-			Hash:      hash,
-			CreatedBy: apiKey.UserID,
-		})
+		file, err := filestore.InsertArchive(ctx, api.Database, api.FileStore, apiKey.UserID, tar)
 		if err != nil {
-			if !errors.Is(err, sql.ErrNoRows) {
-				httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
-					Message: "Internal error fetching file.",
-					Detail:  err.Error(),
-				})
-				return
-			}
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error creating file.",
+				Detail:  err.Error(),
+			})
+			return
+		}
 
-			// If the example tar file doesn't exist, create it.
-			file, err = api.Database.InsertFile(ctx, database.InsertFileParams{
-				ID:        uuid.New(),
-				Hash:      hash,
-				CreatedBy: apiKey.UserID,
-				CreatedAt: database.Now(),
-				Mimetype:  tarMimeType,
-				Data:      tar,
+		req.FileID = file.ID
+	}
+
+	// If a git source is specified, the repository is cloned and the template
+	// is stored as a file like an uploaded one.
+	var gitSource codersdk.TemplateVersionGitSource
+	if req.GitSource != nil {
+		if req.ExampleID != "" || req.FileID != uuid.Nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "You cannot specify a git_source with an example_id or a file_id.",
 			})
-			if err != nil {
-				httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
-					Message: "Internal error creating file.",
-					Detail:  err.Error(),
-				})
-				return
+			return
+		}
+		if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceFile.WithOwner(apiKey.UserID.String())) {
+			httpapi.Forbidden(rw)
+			return
+		}
+
+		gitSource = *req.GitSource
+		opts := gitsource.Options{
+			URL:       gitSource.URL,
+			Ref:       gitSource.Ref,
+			Directory: gitSource.Directory,
+			Limit:     provisionersdk.TemplateArchiveLimit,
+		}
+		err := opts.Validate()
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Invalid git source.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		gitAuthConfig, err := api.gitSourceCredentials(ctx, apiKey.UserID, &opts)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching git auth credentials.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		archive, err := gitsource.Clone(ctx, opts)
+		if err != nil {
+			message := fmt.Sprintf("Failed to create the template from %q.", gitSource.URL)
+			if gitAuthConfig != nil && opts.Username == "" && opts.Password == "" {
+				message += fmt.Sprintf(" If the repository is private, authenticate with %q first.", gitAuthConfig.ID)
 			}
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: message,
+				Detail:  err.Error(),
+			})
+			return
 		}
+		gitSource.CommitSHA = archive.CommitSHA
 
+		file, err := filestore.InsertArchive(ctx, api.Database, api.FileStore, apiKey.UserID, archive.Data)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error creating file.",
+				Detail:  err.Error(),
+			})
+			return
+		}
 		req.FileID = file.ID
 	}
 
@@ -1422,6 +1719,9 @@ func (api *API) postTemplateVersionsByOrganization(rw http.ResponseWriter, r *ht
 			Type:           database.ProvisionerJobTypeTemplateVersionImport,
 			Input:          jobInput,
 			Tags:           tags,
+			// Imports are queued behind workspace builds, which users wait on
+			// to start working.
+			Priority: database.ProvisionerJobPriorityBackground,
 		})
 		if err != nil {
 			return xerrors.Errorf("insert provisioner job: %w", err)
@@ -1449,6 +1749,10 @@ func (api *API) postTemplateVersionsByOrganization(rw http.ResponseWriter, r *ht
 			Readme:         "",
 			JobID:          provisionerJob.ID,
 			CreatedBy:      apiKey.UserID,
+			GitURL:         gitSource.URL,
+			GitRef:         gitSource.Ref,
+			GitDirectory:   gitSource.Directory,
+			GitCommitSHA:   gitSource.CommitSHA,
 		})
 		if err != nil {
 			return xerrors.Errorf("insert template version: %w", err)
@@ -1472,7 +1776,55 @@ func (api *API) postTemplateVersionsByOrganization(rw http.ResponseWriter, r *ht
 		return
 	}
 
-	httpapi.Write(ctx, rw, http.StatusCreated, convertTemplateVersion(templateVersion, convertProvisionerJob(provisionerJob), user))
+	apiJob := convertProvisionerJob(provisionerJob)
+	err = api.setProvisionerJobQueues(ctx, &apiJob)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job queue.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusCreated, convertTemplateVersion(templateVersion, apiJob, user))
+}
+
+// gitSourceCredentials sets the credentials of the user for the repository
+// from the git auth provider its URL matches, if the user has authenticated
+// with it. The provider is returned so errors can refer to it.
+func (api *API) gitSourceCredentials(ctx context.Context, userID uuid.UUID, opts *gitsource.Options) (*gitauth.Config, error) {
+	var gitAuthConfig *gitauth.Config
+	for _, config := range api.GitAuthConfigs {
+		if config.Regex.MatchString(opts.URL) {
+			gitAuthConfig = config
+			break
+		}
+	}
+	if gitAuthConfig == nil {
+		return nil, nil
+	}
+
+	gitAuthLink, err := api.Database.GetGitAuthLink(ctx, database.GetGitAuthLinkParams{
+		ProviderID: gitAuthConfig.ID,
+		UserID:     userID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return gitAuthConfig, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("get git auth link: %w", err)
+	}
+	gitAuthLink, valid, err := refreshGitToken(ctx, api.Database, userID, gitAuthConfig, gitAuthLink)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return gitAuthConfig, nil
+	}
+	creds := formatGitAuthAccessToken(gitAuthConfig.Type, gitAuthLink.OAuthAccessToken)
+	opts.Username = creds.Username
+	opts.Password = creds.Password
+	return gitAuthConfig, nil
 }
 
 // templateVersionResources returns the workspace agent resources associated
@@ -1526,6 +1878,10 @@ func (api *API) templateVersionResources(rw http.ResponseWriter, r *http.Request
 // @Param before query int false "Before Unix timestamp"
 // @Param after query int false "After Unix timestamp"
 // @Param follow query bool false "Follow log stream"
+// @Param level query string false "Lowest log level to return" Enums(trace,debug,info,warn,error)
+// @Param stage query string false "Only return logs of this stage"
+// @Param search query string false "Only return logs whose output contains this, ignoring case"
+// @Param format query string false "Response format, text and ndjson are returned as attachments" Enums(json,text,ndjson)
 // @Success 200 {array} codersdk.ProvisionerJobLog
 // @Router /templateversions/{templateversion}/logs [get]
 func (api *API) templateVersionLogs(rw http.ResponseWriter, r *http.Request) {
@@ -1572,6 +1928,20 @@ func convertTemplateVersion(version database.TemplateVersion, job codersdk.Provi
 		Job:            job,
 		Readme:         version.Readme,
 		CreatedBy:      createdBy,
+		Archived:       version.Archived,
+		GitSource:      convertTemplateVersionGitSource(version),
+	}
+}
+
+func convertTemplateVersionGitSource(version database.TemplateVersion) *codersdk.TemplateVersionGitSource {
+	if version.GitURL == "" {
+		return nil
+	}
+	return &codersdk.TemplateVersionGitSource{
+		URL:       version.GitURL,
+		Ref:       version.GitRef,
+		Directory: version.GitDirectory,
+		CommitSHA: version.GitCommitSHA,
 	}
 }
 
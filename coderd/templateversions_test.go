@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -15,7 +16,9 @@ import (
 	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/gitauth"
+	"github.com/coder/coder/coderd/gitsource/gitsourcetest"
 	"github.com/coder/coder/coderd/provisionerdserver"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/examples"
@@ -132,6 +135,58 @@ func TestPostTemplateVersionsByOrganization(t *testing.T) {
 		require.Len(t, auditor.AuditLogs, 2)
 		assert.Equal(t, database.AuditActionCreate, auditor.AuditLogs[1].Action)
 	})
+	t.Run("GitSource", func(t *testing.T) {
+		t.Parallel()
+		repo, sha := gitsourcetest.ServeRepo(t, map[string]string{
+			"docker/main.tf": "# docker",
+		})
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		// try an unsupported scheme
+		_, err := client.CreateTemplateVersion(ctx, user.OrganizationID, codersdk.CreateTemplateVersionRequest{
+			StorageMethod: codersdk.ProvisionerStorageMethodFile,
+			GitSource:     &codersdk.TemplateVersionGitSource{URL: "file:///etc"},
+			Provisioner:   codersdk.ProvisionerTypeEcho,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		// try a git source and a file ID
+		_, err = client.CreateTemplateVersion(ctx, user.OrganizationID, codersdk.CreateTemplateVersionRequest{
+			StorageMethod: codersdk.ProvisionerStorageMethodFile,
+			GitSource:     &codersdk.TemplateVersionGitSource{URL: repo.URL},
+			FileID:        uuid.New(),
+			Provisioner:   codersdk.ProvisionerTypeEcho,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		// try a good git source
+		version, err := client.CreateTemplateVersion(ctx, user.OrganizationID, codersdk.CreateTemplateVersionRequest{
+			StorageMethod: codersdk.ProvisionerStorageMethodFile,
+			GitSource: &codersdk.TemplateVersionGitSource{
+				URL:       repo.URL,
+				Ref:       "main",
+				Directory: "docker",
+			},
+			Provisioner: codersdk.ProvisionerTypeEcho,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, version.GitSource)
+		require.Equal(t, repo.URL, version.GitSource.URL)
+		require.Equal(t, "main", version.GitSource.Ref)
+		require.Equal(t, "docker", version.GitSource.Directory)
+		require.Equal(t, sha, version.GitSource.CommitSHA)
+
+		version, err = client.TemplateVersion(ctx, version.ID)
+		require.NoError(t, err)
+		require.Equal(t, sha, version.GitSource.CommitSHA)
+	})
 	t.Run("Example", func(t *testing.T) {
 		t.Parallel()
 		client := coderdtest.New(t, nil)
@@ -621,6 +676,161 @@ func TestTemplateVersionsByTemplate(t *testing.T) {
 	})
 }
 
+func TestArchiveTemplateVersion(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ArchiveAndUnarchive", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		unused := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, unused.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		err := client.ArchiveTemplateVersion(ctx, unused.ID)
+		require.NoError(t, err)
+
+		// Archived versions are hidden unless asked for.
+		versions, err := client.TemplateVersionsByTemplate(ctx, codersdk.TemplateVersionsByTemplateRequest{
+			TemplateID: template.ID,
+		})
+		require.NoError(t, err)
+		require.Len(t, versions, 1)
+		require.Equal(t, version.ID, versions[0].ID)
+		versions, err = client.TemplateVersionsByTemplate(ctx, codersdk.TemplateVersionsByTemplateRequest{
+			TemplateID:      template.ID,
+			IncludeArchived: true,
+		})
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+
+		archived, err := client.TemplateVersion(ctx, unused.ID)
+		require.NoError(t, err)
+		require.True(t, archived.Archived)
+
+		// Archived versions can't be promoted or used for new workspaces.
+		err = client.UpdateActiveTemplateVersion(ctx, template.ID, codersdk.UpdateActiveTemplateVersion{
+			ID: unused.ID,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		_, err = client.CreateWorkspace(ctx, user.OrganizationID, codersdk.Me, codersdk.CreateWorkspaceRequest{
+			TemplateVersionID: unused.ID,
+			Name:              "archived",
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+
+		err = client.UnarchiveTemplateVersion(ctx, unused.ID)
+		require.NoError(t, err)
+		unarchived, err := client.TemplateVersion(ctx, unused.ID)
+		require.NoError(t, err)
+		require.False(t, unarchived.Archived)
+	})
+
+	t.Run("InUse", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		used := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, used.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.TemplateVersionID = used.ID
+		})
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		var apiErr *codersdk.Error
+		// The active version.
+		err := client.ArchiveTemplateVersion(ctx, version.ID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+		// The version of the latest build of a workspace.
+		err = client.ArchiveTemplateVersion(ctx, used.ID)
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("Unused", func(t *testing.T) {
+		t.Parallel()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		used := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, used.ID)
+		unused := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, unused.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.TemplateVersionID = used.ID
+		})
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		res, err := client.ArchiveUnusedTemplateVersions(ctx, template.ID)
+		require.NoError(t, err)
+		require.Equal(t, []uuid.UUID{unused.ID}, res.ArchivedIDs)
+
+		// Nothing is left to archive.
+		res, err = client.ArchiveUnusedTemplateVersions(ctx, template.ID)
+		require.NoError(t, err)
+		require.Empty(t, res.ArchivedIDs)
+	})
+
+	t.Run("UnarchiveGarbageCollected", func(t *testing.T) {
+		t.Parallel()
+		client, _, api := coderdtest.NewWithAPI(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		// Different responses upload a different file, so the file isn't
+		// shared with the active version.
+		unused := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse: echo.ParseComplete,
+			ProvisionApply: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Log{
+					Log: &proto.Log{Output: "unused"},
+				},
+			}, {
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{},
+				},
+			}},
+		}, template.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, unused.ID)
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		err := client.ArchiveTemplateVersion(ctx, unused.ID)
+		require.NoError(t, err)
+		// nolint:gocritic // Garbage collection runs as the system.
+		deleted, err := api.Database.DeleteUnusedFiles(dbauthz.AsSystemRestricted(ctx), database.Now().Add(time.Hour))
+		require.NoError(t, err)
+		require.Len(t, deleted, 1)
+
+		err = client.UnarchiveTemplateVersion(ctx, unused.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+}
+
 func TestTemplateVersionByName(t *testing.T) {
 	t.Parallel()
 	t.Run("NotFound", func(t *testing.T) {
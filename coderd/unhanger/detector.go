@@ -0,0 +1,308 @@
+// Package unhanger fails provisioner jobs that are hung because the
+// provisioner daemon running them crashed or lost its connection, and cancels
+// jobs that run for too long.
+package unhanger
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/provisionerdserver"
+	"github.com/coder/coder/codersdk"
+)
+
+// Interval is how often the detector looks for hung jobs.
+const Interval = 30 * time.Second
+
+// Provisioner daemons learn that a job was canceled the next time they send an
+// update, so they get a little longer than the force cancel interval to fail
+// a canceled job themselves.
+const forceCancelGrace = time.Minute
+
+// Options configure a Detector.
+type Options struct {
+	Database database.Store
+	Pubsub   database.Pubsub
+	Logger   slog.Logger
+	// Tick triggers a check of every running job. The detector stops when it's
+	// closed.
+	Tick <-chan time.Time
+	// Stats receives the outcome of every check, if set.
+	Stats chan<- Stats
+	// HangTimeout is how long a running job can go without a heartbeat from
+	// its provisioner daemon before it's failed. Zero disables it.
+	HangTimeout time.Duration
+	// MaxRuntime is how long a job can run before it's canceled. Zero means no
+	// limit.
+	MaxRuntime time.Duration
+	// ForceCancelInterval is how long provisioner daemons wait for a canceled
+	// job to stop gracefully before failing it. Daemons don't send heartbeats
+	// while they wait, and jobs that don't stop within it after they're
+	// canceled for running too long are failed.
+	ForceCancelInterval time.Duration
+}
+
+// Stats contains the outcome of one check.
+type Stats struct {
+	// CanceledJobIDs are the jobs that were canceled for running longer than
+	// the max runtime.
+	CanceledJobIDs []uuid.UUID
+	// FailedJobIDs are the jobs that were failed for being hung, or for not
+	// stopping after they were canceled.
+	FailedJobIDs []uuid.UUID
+	Error        error
+}
+
+// Detector periodically fails hung provisioner jobs and cancels jobs that
+// exceed the max runtime. Replicas take a lock before checking, so only one
+// of them acts on each job.
+type Detector struct {
+	opts Options
+
+	done   chan struct{}
+	cancel func()
+}
+
+// New starts checking running jobs on every tick until the detector is
+// closed.
+func New(opts Options) *Detector {
+	ctx, cancel := context.WithCancel(context.Background())
+	//nolint:gocritic // The detector acts on the jobs of every user.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	d := &Detector{
+		opts:   opts,
+		done:   make(chan struct{}),
+		cancel: cancel,
+	}
+	go d.run(ctx)
+	return d
+}
+
+func (d *Detector) run(ctx context.Context) {
+	defer close(d.done)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case t, ok := <-d.opts.Tick:
+			if !ok {
+				return
+			}
+			stats := d.check(ctx, t)
+			if stats.Error != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				d.opts.Logger.Error(ctx, "check for hung provisioner jobs", slog.Error(stats.Error))
+			}
+			if d.opts.Stats != nil {
+				select {
+				case d.opts.Stats <- stats:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+}
+
+// publish is a message to publish once the transaction that the detector
+// acted on jobs in commits.
+type publish struct {
+	channel string
+	message []byte
+}
+
+func (d *Detector) check(ctx context.Context, now time.Time) Stats {
+	var (
+		stats     Stats
+		published []publish
+	)
+	stats.Error = d.opts.Database.InTx(func(db database.Store) error {
+		acquired, err := db.TryAcquireLock(ctx, database.LockIDProvisionerJobHangDetector)
+		if err != nil {
+			return xerrors.Errorf("acquire lock: %w", err)
+		}
+		if !acquired {
+			// Another replica is checking.
+			return nil
+		}
+
+		jobs, err := db.GetRunningProvisionerJobs(ctx)
+		if err != nil {
+			return xerrors.Errorf("get running jobs: %w", err)
+		}
+		for _, job := range jobs {
+			logger := d.opts.Logger.With(slog.F("job_id", job.ID))
+			action, message := d.actionFor(job, now)
+			switch action {
+			case actionFail:
+				logger.Warn(ctx, "failing provisioner job", slog.F("reason", message))
+				msgs, err := failJob(ctx, db, job, now, message)
+				if err != nil {
+					return xerrors.Errorf("fail job %s: %w", job.ID, err)
+				}
+				published = append(published, msgs...)
+				stats.FailedJobIDs = append(stats.FailedJobIDs, job.ID)
+			case actionCancel:
+				logger.Info(ctx, "canceling provisioner job", slog.F("reason", message))
+				msgs, err := cancelJob(ctx, db, job, now, message)
+				if err != nil {
+					return xerrors.Errorf("cancel job %s: %w", job.ID, err)
+				}
+				published = append(published, msgs...)
+				stats.CanceledJobIDs = append(stats.CanceledJobIDs, job.ID)
+			}
+		}
+		return nil
+	}, nil)
+	if stats.Error != nil {
+		return Stats{Error: stats.Error}
+	}
+
+	for _, msg := range published {
+		err := d.opts.Pubsub.Publish(msg.channel, msg.message)
+		if err != nil {
+			d.opts.Logger.Warn(ctx, "publish provisioner job update", slog.F("channel", msg.channel), slog.Error(err))
+		}
+	}
+	return stats
+}
+
+type action int
+
+const (
+	actionNone action = iota
+	actionCancel
+	actionFail
+)
+
+// actionFor returns what to do with a running job, and the message that's
+// logged to the job explaining why.
+func (d *Detector) actionFor(job database.ProvisionerJob, now time.Time) (action, string) {
+	if d.opts.HangTimeout > 0 {
+		lastHeartbeat := job.StartedAt.Time
+		if job.LastHeartbeatAt.Valid {
+			lastHeartbeat = job.LastHeartbeatAt.Time
+		}
+		timeout := d.opts.HangTimeout
+		if job.CanceledAt.Valid {
+			timeout += d.opts.ForceCancelInterval
+		}
+		if now.Sub(lastHeartbeat) > timeout {
+			return actionFail, fmt.Sprintf("The job was marked as failed because its provisioner daemon didn't send an update for %s. The daemon may have crashed or lost its connection to Coder.", timeout)
+		}
+	}
+	if d.opts.MaxRuntime > 0 && now.Sub(job.StartedAt.Time) > d.opts.MaxRuntime {
+		if !job.CanceledAt.Valid {
+			return actionCancel, fmt.Sprintf("The job was canceled because it exceeded the maximum runtime of %s.", d.opts.MaxRuntime)
+		}
+		if now.Sub(job.CanceledAt.Time) > d.opts.ForceCancelInterval+forceCancelGrace {
+			return actionFail, fmt.Sprintf("The job was marked as failed because it exceeded the maximum runtime of %s and didn't stop within %s of being canceled.", d.opts.MaxRuntime, d.opts.ForceCancelInterval)
+		}
+	}
+	return actionNone, ""
+}
+
+// cancelJob cancels the job, which its provisioner daemon stops gracefully
+// the next time it sends an update.
+func cancelJob(ctx context.Context, db database.Store, job database.ProvisionerJob, now time.Time, message string) ([]publish, error) {
+	err := db.UpdateProvisionerJobWithCancelByID(ctx, database.UpdateProvisionerJobWithCancelByIDParams{
+		ID:         job.ID,
+		CanceledAt: sql.NullTime{Time: now, Valid: true},
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("update job: %w", err)
+	}
+	msg, err := insertLog(ctx, db, job, now, database.LogLevelWarn, message)
+	if err != nil {
+		return nil, err
+	}
+	return appendWorkspaceUpdate(ctx, db, job, []publish{msg})
+}
+
+// failJob marks the job as failed and ends its logs, since its provisioner
+// daemon never will.
+func failJob(ctx context.Context, db database.Store, job database.ProvisionerJob, now time.Time, message string) ([]publish, error) {
+	err := db.UpdateProvisionerJobWithCompleteByID(ctx, database.UpdateProvisionerJobWithCompleteByIDParams{
+		ID:          job.ID,
+		UpdatedAt:   now,
+		CompletedAt: sql.NullTime{Time: now, Valid: true},
+		Error:       sql.NullString{String: message, Valid: true},
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("update job: %w", err)
+	}
+	logMsg, err := insertLog(ctx, db, job, now, database.LogLevelError, message)
+	if err != nil {
+		return nil, err
+	}
+	endOfLogs, err := json.Marshal(provisionerdserver.ProvisionerJobLogsNotifyMessage{EndOfLogs: true})
+	if err != nil {
+		return nil, xerrors.Errorf("marshal end of logs: %w", err)
+	}
+	msgs := []publish{logMsg, {
+		channel: provisionerdserver.ProvisionerJobLogsNotifyChannel(job.ID),
+		message: endOfLogs,
+	}}
+	return appendWorkspaceUpdate(ctx, db, job, msgs)
+}
+
+// appendWorkspaceUpdate notifies watchers of the workspace if the job is a
+// workspace build, so they see its new status.
+func appendWorkspaceUpdate(ctx context.Context, db database.Store, job database.ProvisionerJob, msgs []publish) ([]publish, error) {
+	if job.Type != database.ProvisionerJobTypeWorkspaceBuild {
+		return msgs, nil
+	}
+	build, err := db.GetWorkspaceBuildByJobID(ctx, job.ID)
+	if err != nil {
+		return nil, xerrors.Errorf("get workspace build: %w", err)
+	}
+	return append(msgs, publish{
+		channel: codersdk.WorkspaceNotifyChannel(build.WorkspaceID),
+		message: []byte{},
+	}), nil
+}
+
+// insertLog adds a log to the job, so users know what happened to it.
+func insertLog(ctx context.Context, db database.Store, job database.ProvisionerJob, now time.Time, level database.LogLevel, message string) (publish, error) {
+	logs, err := db.InsertProvisionerJobLogs(ctx, database.InsertProvisionerJobLogsParams{
+		JobID:     job.ID,
+		CreatedAt: []time.Time{now},
+		Source:    []database.LogSource{database.LogSourceProvisionerDaemon},
+		Level:     []database.LogLevel{level},
+		Stage:     []string{"Cleaning Up"},
+		Output:    []string{message},
+	})
+	if err != nil {
+		return publish{}, xerrors.Errorf("insert job log: %w", err)
+	}
+	data, err := json.Marshal(provisionerdserver.ProvisionerJobLogsNotifyMessage{
+		CreatedAfter: logs[0].ID - 1,
+	})
+	if err != nil {
+		return publish{}, xerrors.Errorf("marshal job log: %w", err)
+	}
+	return publish{
+		channel: provisionerdserver.ProvisionerJobLogsNotifyChannel(job.ID),
+		message: data,
+	}, nil
+}
+
+// Close stops the detector, and waits for a check in progress to finish.
+func (d *Detector) Close() error {
+	d.cancel()
+	<-d.done
+	return nil
+}
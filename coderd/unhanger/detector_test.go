@@ -0,0 +1,201 @@
+package unhanger_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbfake"
+	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/coderd/provisionerdserver"
+	"github.com/coder/coder/coderd/unhanger"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestDetector(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Hung", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		db := dbfake.New()
+		pubsub := database.NewPubsubInMemory()
+		now := database.Now()
+		hung := runningJob(ctx, t, db, database.ProvisionerJobTypeWorkspaceBuild, now.Add(-time.Hour))
+		healthy := runningJob(ctx, t, db, database.ProvisionerJobTypeTemplateVersionImport, now.Add(-time.Hour))
+		err := db.UpdateProvisionerJobByID(ctx, database.UpdateProvisionerJobByIDParams{
+			ID:        healthy.ID,
+			UpdatedAt: now.Add(-time.Minute),
+		})
+		require.NoError(t, err)
+		workspace := dbgen.Workspace(t, db, database.Workspace{})
+		_ = dbgen.WorkspaceBuild(t, db, database.WorkspaceBuild{
+			WorkspaceID: workspace.ID,
+			JobID:       hung.ID,
+		})
+
+		workspaceUpdated := make(chan struct{}, 1)
+		unsubscribe, err := pubsub.Subscribe(codersdk.WorkspaceNotifyChannel(workspace.ID), func(_ context.Context, _ []byte) {
+			workspaceUpdated <- struct{}{}
+		})
+		require.NoError(t, err)
+		defer unsubscribe()
+		endOfLogs := make(chan struct{}, 2)
+		unsubscribe, err = pubsub.Subscribe(provisionerdserver.ProvisionerJobLogsNotifyChannel(hung.ID), func(_ context.Context, message []byte) {
+			var msg provisionerdserver.ProvisionerJobLogsNotifyMessage
+			if json.Unmarshal(message, &msg) == nil && msg.EndOfLogs {
+				endOfLogs <- struct{}{}
+			}
+		})
+		require.NoError(t, err)
+		defer unsubscribe()
+
+		tick, stats := start(t, db, pubsub, unhanger.Options{
+			HangTimeout:         5 * time.Minute,
+			ForceCancelInterval: 10 * time.Minute,
+		})
+		tick <- now
+		require.Equal(t, unhanger.Stats{FailedJobIDs: []uuid.UUID{hung.ID}}, <-stats)
+
+		job, err := db.GetProvisionerJobByID(ctx, hung.ID)
+		require.NoError(t, err)
+		require.True(t, job.CompletedAt.Valid)
+		require.Contains(t, job.Error.String, "didn't send an update for 5m0s")
+		logs, err := db.GetProvisionerLogsByIDBetween(ctx, database.GetProvisionerLogsByIDBetweenParams{JobID: hung.ID})
+		require.NoError(t, err)
+		require.Len(t, logs, 1)
+		require.Equal(t, database.LogLevelError, logs[0].Level)
+		require.Equal(t, job.Error.String, logs[0].Output)
+		for _, published := range []chan struct{}{workspaceUpdated, endOfLogs} {
+			select {
+			case <-published:
+			case <-ctx.Done():
+				t.Fatal("timed out waiting for the job update to be published")
+			}
+		}
+
+		job, err = db.GetProvisionerJobByID(ctx, healthy.ID)
+		require.NoError(t, err)
+		require.False(t, job.CompletedAt.Valid)
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		db := dbfake.New()
+		now := database.Now()
+		job := runningJob(ctx, t, db, database.ProvisionerJobTypeTemplateVersionImport, now.Add(-10*time.Minute))
+		err := db.UpdateProvisionerJobWithCancelByID(ctx, database.UpdateProvisionerJobWithCancelByIDParams{
+			ID:         job.ID,
+			CanceledAt: sql.NullTime{Time: now.Add(-10 * time.Minute), Valid: true},
+		})
+		require.NoError(t, err)
+
+		tick, stats := start(t, db, database.NewPubsubInMemory(), unhanger.Options{
+			HangTimeout:         5 * time.Minute,
+			ForceCancelInterval: 10 * time.Minute,
+		})
+		// Daemons don't send updates while they wait for canceled jobs to
+		// stop gracefully.
+		tick <- now
+		require.Equal(t, unhanger.Stats{}, <-stats)
+		tick <- now.Add(6 * time.Minute)
+		require.Equal(t, unhanger.Stats{FailedJobIDs: []uuid.UUID{job.ID}}, <-stats)
+	})
+
+	t.Run("MaxRuntime", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		db := dbfake.New()
+		now := database.Now()
+		long := runningJob(ctx, t, db, database.ProvisionerJobTypeTemplateVersionImport, now.Add(-2*time.Hour))
+		short := runningJob(ctx, t, db, database.ProvisionerJobTypeTemplateVersionImport, now.Add(-time.Minute))
+
+		tick, stats := start(t, db, database.NewPubsubInMemory(), unhanger.Options{
+			MaxRuntime:          time.Hour,
+			ForceCancelInterval: 10 * time.Minute,
+		})
+		tick <- now
+		require.Equal(t, unhanger.Stats{CanceledJobIDs: []uuid.UUID{long.ID}}, <-stats)
+		job, err := db.GetProvisionerJobByID(ctx, long.ID)
+		require.NoError(t, err)
+		require.True(t, job.CanceledAt.Valid)
+		require.False(t, job.CompletedAt.Valid)
+		logs, err := db.GetProvisionerLogsByIDBetween(ctx, database.GetProvisionerLogsByIDBetweenParams{JobID: long.ID})
+		require.NoError(t, err)
+		require.Len(t, logs, 1)
+		require.Contains(t, logs[0].Output, "exceeded the maximum runtime of 1h0m0s")
+
+		// The job is given time to stop gracefully.
+		tick <- now.Add(10 * time.Minute)
+		require.Equal(t, unhanger.Stats{}, <-stats)
+
+		// Then it's failed.
+		tick <- now.Add(20 * time.Minute)
+		require.Equal(t, unhanger.Stats{FailedJobIDs: []uuid.UUID{long.ID}}, <-stats)
+		job, err = db.GetProvisionerJobByID(ctx, long.ID)
+		require.NoError(t, err)
+		require.True(t, job.CompletedAt.Valid)
+		require.Contains(t, job.Error.String, "didn't stop within 10m0s of being canceled")
+
+		job, err = db.GetProvisionerJobByID(ctx, short.ID)
+		require.NoError(t, err)
+		require.False(t, job.CanceledAt.Valid)
+	})
+}
+
+// start starts a detector, and returns the channels it's ticked with and
+// reports its stats on.
+func start(t *testing.T, db database.Store, pubsub database.Pubsub, opts unhanger.Options) (chan<- time.Time, <-chan unhanger.Stats) {
+	t.Helper()
+
+	tick := make(chan time.Time)
+	stats := make(chan unhanger.Stats)
+	opts.Database = db
+	opts.Pubsub = pubsub
+	opts.Logger = slogtest.Make(t, nil)
+	opts.Tick = tick
+	opts.Stats = stats
+	detector := unhanger.New(opts)
+	t.Cleanup(func() {
+		_ = detector.Close()
+	})
+	return tick, stats
+}
+
+// runningJob inserts a job that was acquired by a provisioner daemon at
+// startedAt, and hasn't sent an update since.
+func runningJob(ctx context.Context, t *testing.T, db database.Store, jobType database.ProvisionerJobType, startedAt time.Time) database.ProvisionerJob {
+	t.Helper()
+
+	job := dbgen.ProvisionerJob(t, db, database.ProvisionerJob{Type: jobType})
+	job, err := db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
+		StartedAt: sql.NullTime{Time: startedAt, Valid: true},
+		WorkerID:  uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		Types:     []database.ProvisionerType{job.Provisioner},
+	})
+	require.NoError(t, err)
+	return job
+}
@@ -69,6 +69,10 @@ func (api *API) postLogin(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	aReq.UserID = user.ID
+	// Failed logins never create an API key, so record the user as the
+	// resource up front. Login attempts for unknown users still have a nil
+	// resource ID.
+	aReq.Old = database.APIKey{UserID: user.ID}
 
 	// If the user doesn't exist, it will be a default struct.
 	equal, err := userpassword.Compare(string(user.HashedPassword), loginWithPassword.Password)
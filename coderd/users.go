@@ -18,6 +18,7 @@ import (
 	"github.com/coder/coder/coderd/gitsshkey"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/notifications"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/coderd/searchquery"
 	"github.com/coder/coder/coderd/telemetry"
@@ -202,6 +203,12 @@ func (api *API) users(rw http.ResponseWriter, r *http.Request) {
 		Search:    params.Search,
 		Status:    params.Status,
 		RbacRole:  params.RbacRole,
+
+		LoginType:      params.LoginType,
+		LastSeenBefore: params.LastSeenBefore,
+		CreatedAfter:   params.CreatedAfter,
+		GroupName:      params.GroupName,
+		OrganizationID: params.OrganizationID,
 	})
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -366,6 +373,14 @@ func (api *API) postUser(rw http.ResponseWriter, r *http.Request) {
 
 	aReq.New = user
 
+	// Users that sign up themselves, e.g. the first user or with OIDC, are
+	// not told about an account they just created.
+	api.Notifier.Enqueue(notifications.Notification{
+		UserID:    user.ID,
+		Kind:      codersdk.NotificationKindAccountCreated,
+		DedupeKey: user.ID.String(),
+	})
+
 	// Report when users are added!
 	api.Telemetry.Report(&telemetry.Snapshot{
 		Users: []telemetry.User{telemetry.ConvertUser(user)},
@@ -654,6 +669,26 @@ func (api *API) putUserStatus(status database.UserStatus) func(rw http.ResponseW
 			return
 		}
 
+		if status == database.UserStatusSuspended && user.Status != database.UserStatusSuspended {
+			api.publishUserWebhookEvent(ctx, codersdk.WebhookEventUserSuspended, organizations, map[string]string{
+				"user_id":  suspendedUser.ID.String(),
+				"username": suspendedUser.Username,
+				"email":    suspendedUser.Email,
+			})
+		}
+		switch {
+		case status == database.UserStatusSuspended && user.Status != database.UserStatusSuspended:
+			api.Notifier.Enqueue(notifications.Notification{
+				UserID: suspendedUser.ID,
+				Kind:   codersdk.NotificationKindAccountSuspended,
+			})
+		case status == database.UserStatusActive && user.Status == database.UserStatusSuspended:
+			api.Notifier.Enqueue(notifications.Notification{
+				UserID: suspendedUser.ID,
+				Kind:   codersdk.NotificationKindAccountActivated,
+			})
+		}
+
 		httpapi.Write(ctx, rw, http.StatusOK, convertUser(suspendedUser, organizations))
 	}
 }
@@ -1034,7 +1069,7 @@ type CreateUserRequest struct {
 
 func (api *API) CreateUser(ctx context.Context, store database.Store, req CreateUserRequest) (database.User, uuid.UUID, error) {
 	var user database.User
-	return user, req.OrganizationID, store.InTx(func(tx database.Store) error {
+	err := store.InTx(func(tx database.Store) error {
 		orgRoles := make([]string, 0)
 		// If no organization is provided, create a new one for the user.
 		if req.OrganizationID == uuid.Nil {
@@ -1112,6 +1147,17 @@ func (api *API) CreateUser(ctx context.Context, store database.Store, req Create
 		}
 		return nil
 	}, nil)
+	if err != nil {
+		return user, req.OrganizationID, err
+	}
+
+	api.publishWebhookEvent(ctx, codersdk.WebhookEventUserCreated, req.OrganizationID, map[string]string{
+		"user_id":    user.ID.String(),
+		"username":   user.Username,
+		"email":      user.Email,
+		"login_type": string(user.LoginType),
+	})
+	return user, req.OrganizationID, nil
 }
 
 func convertUser(user database.User, organizationIDs []uuid.UUID) codersdk.User {
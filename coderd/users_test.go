@@ -125,7 +125,7 @@ func TestPostLogin(t *testing.T) {
 			Username: "testuser",
 			Password: "SomeSecurePassword!",
 		}
-		_, err := client.CreateFirstUser(ctx, req)
+		res, err := client.CreateFirstUser(ctx, req)
 		require.NoError(t, err)
 		_, err = client.LoginWithPassword(ctx, codersdk.LoginWithPasswordRequest{
 			Email:    req.Email,
@@ -138,6 +138,9 @@ func TestPostLogin(t *testing.T) {
 
 		require.Len(t, auditor.AuditLogs, numLogs)
 		require.Equal(t, database.AuditActionLogin, auditor.AuditLogs[numLogs-1].Action)
+		require.Equal(t, database.ResourceTypeApiKey, auditor.AuditLogs[numLogs-1].ResourceType)
+		require.Equal(t, res.UserID, auditor.AuditLogs[numLogs-1].ResourceID)
+		require.EqualValues(t, http.StatusUnauthorized, auditor.AuditLogs[numLogs-1].StatusCode)
 	})
 
 	t.Run("Suspended", func(t *testing.T) {
@@ -1223,6 +1226,60 @@ func TestUsersFilter(t *testing.T) {
 				return false
 			},
 		},
+		{
+			Name: "LoginType",
+			Filter: codersdk.UsersRequest{
+				SearchQuery: "login_type:password status:active,suspended",
+			},
+			FilterF: func(_ codersdk.UsersRequest, u codersdk.User) bool {
+				return true
+			},
+		},
+		{
+			Name: "LoginTypeNoMatch",
+			Filter: codersdk.UsersRequest{
+				SearchQuery: "login_type:oidc,github status:active,suspended",
+			},
+			FilterF: func(_ codersdk.UsersRequest, u codersdk.User) bool {
+				return false
+			},
+		},
+		{
+			Name: "EveryoneGroup",
+			Filter: codersdk.UsersRequest{
+				SearchQuery: "group:everyone organization:" + first.OrganizationID.String() + " status:active,suspended",
+			},
+			FilterF: func(_ codersdk.UsersRequest, u codersdk.User) bool {
+				return true
+			},
+		},
+		{
+			Name: "GroupInOtherOrganization",
+			Filter: codersdk.UsersRequest{
+				SearchQuery: "group:everyone organization:" + uuid.NewString() + " status:active,suspended",
+			},
+			FilterF: func(_ codersdk.UsersRequest, u codersdk.User) bool {
+				return false
+			},
+		},
+		{
+			Name: "CreatedAfterLastSeenBefore",
+			Filter: codersdk.UsersRequest{
+				SearchQuery: "created_after:2000-01-01 last_seen_before:3000-01-01 status:active,suspended",
+			},
+			FilterF: func(_ codersdk.UsersRequest, u codersdk.User) bool {
+				return true
+			},
+		},
+		{
+			Name: "CreatedAfterNoMatch",
+			Filter: codersdk.UsersRequest{
+				SearchQuery: "created_after:3000-01-01 status:active,suspended",
+			},
+			FilterF: func(_ codersdk.UsersRequest, u codersdk.User) bool {
+				return false
+			},
+		},
 	}
 
 	for _, c := range testCases {
@@ -0,0 +1,410 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/coderd/webhooks"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/cryptorand"
+)
+
+// @Summary Get deployment webhooks
+// @ID get-deployment-webhooks
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Webhooks
+// @Success 200 {array} codersdk.Webhook
+// @Router /webhooks [get]
+func (api *API) deploymentWebhooks(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceWebhook) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	hooks, err := api.Database.GetDeploymentWebhooks(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching webhooks.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertWebhooks(hooks))
+}
+
+// @Summary Get organization webhooks
+// @ID get-organization-webhooks
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Webhooks
+// @Param organization path string true "Organization ID" format(uuid)
+// @Success 200 {array} codersdk.Webhook
+// @Router /organizations/{organization}/webhooks [get]
+func (api *API) organizationWebhooks(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	org := httpmw.OrganizationParam(r)
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceWebhook.InOrg(org.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	hooks, err := api.Database.GetWebhooksByOrganizationID(ctx, org.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching webhooks.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertWebhooks(hooks))
+}
+
+// @Summary Create deployment webhook
+// @ID create-deployment-webhook
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Webhooks
+// @Param request body codersdk.CreateWebhookRequest true "Create webhook request"
+// @Success 201 {object} codersdk.Webhook
+// @Router /webhooks [post]
+func (api *API) postDeploymentWebhook(rw http.ResponseWriter, r *http.Request) {
+	api.postWebhook(rw, r, uuid.NullUUID{})
+}
+
+// @Summary Create organization webhook
+// @ID create-organization-webhook
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Webhooks
+// @Param organization path string true "Organization ID" format(uuid)
+// @Param request body codersdk.CreateWebhookRequest true "Create webhook request"
+// @Success 201 {object} codersdk.Webhook
+// @Router /organizations/{organization}/webhooks [post]
+func (api *API) postOrganizationWebhook(rw http.ResponseWriter, r *http.Request) {
+	org := httpmw.OrganizationParam(r)
+	api.postWebhook(rw, r, uuid.NullUUID{UUID: org.ID, Valid: true})
+}
+
+func (api *API) postWebhook(rw http.ResponseWriter, r *http.Request, orgID uuid.NullUUID) {
+	var (
+		ctx    = r.Context()
+		apiKey = httpmw.APIKey(r)
+		object = rbac.ResourceWebhook
+	)
+	if orgID.Valid {
+		object = object.InOrg(orgID.UUID)
+	}
+	if !api.Authorize(r, rbac.ActionCreate, object) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.CreateWebhookRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	events, ok := validateWebhookEvents(ctx, rw, req.Events)
+	if !ok {
+		return
+	}
+	if req.Secret == "" {
+		var err error
+		req.Secret, err = cryptorand.String(32)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error generating webhook secret.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+
+	now := database.Now()
+	webhook, err := api.Database.InsertWebhook(ctx, database.InsertWebhookParams{
+		ID:             uuid.New(),
+		OrganizationID: orgID,
+		CreatedBy:      apiKey.UserID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+		Name:           req.Name,
+		Url:            req.URL,
+		Secret:         req.Secret,
+		Events:         events,
+		Enabled:        true,
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating webhook.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	// The secret is only ever returned here, so the creator can configure
+	// the receiver to verify signatures.
+	res := convertWebhook(webhook)
+	res.Secret = webhook.Secret
+	httpapi.Write(ctx, rw, http.StatusCreated, res)
+}
+
+// @Summary Get webhook by ID
+// @ID get-webhook-by-id
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Webhooks
+// @Param webhook path string true "Webhook ID" format(uuid)
+// @Success 200 {object} codersdk.Webhook
+// @Router /webhooks/{webhook} [get]
+func (api *API) webhook(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	webhook := httpmw.WebhookParam(r)
+	if !api.Authorize(r, rbac.ActionRead, webhook) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertWebhook(webhook))
+}
+
+// @Summary Update webhook
+// @ID update-webhook
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Webhooks
+// @Param webhook path string true "Webhook ID" format(uuid)
+// @Param request body codersdk.UpdateWebhookRequest true "Update webhook request"
+// @Success 200 {object} codersdk.Webhook
+// @Router /webhooks/{webhook} [patch]
+func (api *API) patchWebhook(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	webhook := httpmw.WebhookParam(r)
+	if !api.Authorize(r, rbac.ActionUpdate, webhook) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpdateWebhookRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	params := database.UpdateWebhookByIDParams{
+		ID:        webhook.ID,
+		UpdatedAt: database.Now(),
+		Name:      webhook.Name,
+		Url:       webhook.Url,
+		Events:    webhook.Events,
+		Enabled:   webhook.Enabled,
+	}
+	if req.Name != "" {
+		params.Name = req.Name
+	}
+	if req.URL != "" {
+		params.Url = req.URL
+	}
+	if len(req.Events) > 0 {
+		events, ok := validateWebhookEvents(ctx, rw, req.Events)
+		if !ok {
+			return
+		}
+		params.Events = events
+	}
+	if req.Enabled != nil {
+		params.Enabled = *req.Enabled
+	}
+
+	updated, err := api.Database.UpdateWebhookByID(ctx, params)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error updating webhook.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertWebhook(updated))
+}
+
+// @Summary Delete webhook
+// @ID delete-webhook
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Webhooks
+// @Param webhook path string true "Webhook ID" format(uuid)
+// @Success 200 {object} codersdk.Response
+// @Router /webhooks/{webhook} [delete]
+func (api *API) deleteWebhook(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	webhook := httpmw.WebhookParam(r)
+	if !api.Authorize(r, rbac.ActionDelete, webhook) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	err := api.Database.DeleteWebhookByID(ctx, webhook.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting webhook.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: "Webhook has been deleted!",
+	})
+}
+
+// @Summary Get webhook deliveries
+// @ID get-webhook-deliveries
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Webhooks
+// @Param webhook path string true "Webhook ID" format(uuid)
+// @Param limit query int false "Maximum number of deliveries to return"
+// @Success 200 {array} codersdk.WebhookDelivery
+// @Router /webhooks/{webhook}/deliveries [get]
+func (api *API) webhookDeliveries(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	webhook := httpmw.WebhookParam(r)
+	if !api.Authorize(r, rbac.ActionRead, webhook) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var limit int
+	if limitQuery := r.URL.Query().Get("limit"); limitQuery != "" {
+		var err error
+		limit, err = strconv.Atoi(limitQuery)
+		if err != nil || limit < 0 {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: "Query param \"limit\" must be a non-negative integer.",
+				Validations: []codersdk.ValidationError{
+					{Field: "limit", Detail: fmt.Sprintf("invalid limit %q", limitQuery)},
+				},
+			})
+			return
+		}
+	}
+
+	deliveries, err := api.Database.GetWebhookDeliveriesByWebhookID(ctx, database.GetWebhookDeliveriesByWebhookIDParams{
+		WebhookID: webhook.ID,
+		LimitOpt:  int32(limit),
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching webhook deliveries.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	res := make([]codersdk.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		res = append(res, convertWebhookDelivery(delivery))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, res)
+}
+
+// publishWebhookEvent notifies webhooks about an event. The request that
+// caused the event has already succeeded, so errors are only logged.
+func (api *API) publishWebhookEvent(ctx context.Context, event codersdk.WebhookEvent, organizationID uuid.UUID, data map[string]string) {
+	err := webhooks.Publish(api.Pubsub, event, organizationID, data)
+	if err != nil {
+		api.Logger.Warn(ctx, "publish webhook event", slog.F("event", event), slog.Error(err))
+	}
+}
+
+// publishUserWebhookEvent notifies the webhooks of every organization of a
+// user about an event, once per webhook.
+func (api *API) publishUserWebhookEvent(ctx context.Context, event codersdk.WebhookEvent, organizationIDs []uuid.UUID, data map[string]string) {
+	err := webhooks.PublishUserEvent(api.Pubsub, event, organizationIDs, data)
+	if err != nil {
+		api.Logger.Warn(ctx, "publish webhook event", slog.F("event", event), slog.Error(err))
+	}
+}
+
+func validateWebhookEvents(ctx context.Context, rw http.ResponseWriter, events []codersdk.WebhookEvent) ([]string, bool) {
+	valid := make([]string, 0, len(events))
+	for _, event := range events {
+		if !event.Valid() {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message: fmt.Sprintf("Unknown webhook event %q.", event),
+				Validations: []codersdk.ValidationError{
+					{Field: "events", Detail: fmt.Sprintf("must be one of %v", codersdk.WebhookEvents)},
+				},
+			})
+			return nil, false
+		}
+		valid = append(valid, string(event))
+	}
+	return valid, true
+}
+
+func convertWebhooks(hooks []database.Webhook) []codersdk.Webhook {
+	converted := make([]codersdk.Webhook, 0, len(hooks))
+	for _, webhook := range hooks {
+		converted = append(converted, convertWebhook(webhook))
+	}
+	return converted
+}
+
+func convertWebhook(webhook database.Webhook) codersdk.Webhook {
+	events := make([]codersdk.WebhookEvent, 0, len(webhook.Events))
+	for _, event := range webhook.Events {
+		events = append(events, codersdk.WebhookEvent(event))
+	}
+	converted := codersdk.Webhook{
+		ID:        webhook.ID,
+		CreatedBy: webhook.CreatedBy,
+		CreatedAt: webhook.CreatedAt,
+		UpdatedAt: webhook.UpdatedAt,
+		Name:      webhook.Name,
+		URL:       webhook.Url,
+		Events:    events,
+		Enabled:   webhook.Enabled,
+	}
+	if webhook.OrganizationID.Valid {
+		converted.OrganizationID = &webhook.OrganizationID.UUID
+	}
+	return converted
+}
+
+func convertWebhookDelivery(delivery database.WebhookDelivery) codersdk.WebhookDelivery {
+	converted := codersdk.WebhookDelivery{
+		ID:           delivery.ID,
+		WebhookID:    delivery.WebhookID,
+		Event:        codersdk.WebhookEvent(delivery.Event),
+		Attempt:      int(delivery.Attempt),
+		CreatedAt:    delivery.CreatedAt,
+		StatusCode:   int(delivery.StatusCode.Int32),
+		ResponseBody: delivery.ResponseBody,
+		Error:        delivery.Error,
+	}
+	// The payload was marshaled from this type, so it always unmarshals.
+	_ = json.Unmarshal(delivery.Payload, &converted.Payload)
+	if delivery.DeliveredAt.Valid {
+		converted.DeliveredAt = &delivery.DeliveredAt.Time
+	}
+	return converted
+}
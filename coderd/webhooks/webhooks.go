@@ -0,0 +1,290 @@
+// Package webhooks delivers Coder events to outbound webhook subscriptions.
+//
+// Events are published on pubsub so that every replica's Dispatcher sees
+// them. Before sending an attempt, a Dispatcher records it in the delivery
+// log, and the database only accepts one record per attempt. The replica
+// that records the first attempt owns the event for that webhook and sends
+// any retries.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+
+	"github.com/coder/coder/buildinfo"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/codersdk"
+)
+
+// EventChannel is the pubsub channel webhook events are published on.
+const EventChannel = "webhook_events"
+
+// maxResponseBodySize limits how much of a receiver's response is kept in
+// the delivery log.
+const maxResponseBodySize = 4 << 10
+
+// Publish broadcasts an event to the webhook dispatchers of every replica.
+// Data describes the resource the event is about.
+func Publish(pubsub database.Pubsub, event codersdk.WebhookEvent, organizationID uuid.UUID, data map[string]string) error {
+	return publish(pubsub, codersdk.WebhookEventPayload{
+		ID:             uuid.New(),
+		Event:          event,
+		CreatedAt:      database.Now(),
+		OrganizationID: organizationID,
+		Data:           data,
+	})
+}
+
+// PublishUserEvent broadcasts an event about a user. Users aren't scoped to
+// an organization, so the event is sent once to every webhook of the user's
+// organizations.
+func PublishUserEvent(pubsub database.Pubsub, event codersdk.WebhookEvent, organizationIDs []uuid.UUID, data map[string]string) error {
+	return publish(pubsub, codersdk.WebhookEventPayload{
+		ID:              uuid.New(),
+		Event:           event,
+		CreatedAt:       database.Now(),
+		OrganizationIDs: organizationIDs,
+		Data:            data,
+	})
+}
+
+func publish(pubsub database.Pubsub, event codersdk.WebhookEventPayload) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return xerrors.Errorf("marshal webhook event: %w", err)
+	}
+	err = pubsub.Publish(EventChannel, payload)
+	if err != nil {
+		return xerrors.Errorf("publish webhook event: %w", err)
+	}
+	return nil
+}
+
+// Sign returns the signature of a payload that is sent in the
+// codersdk.WebhookSignatureHeader header.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Options configure a Dispatcher.
+type Options struct {
+	Database database.Store
+	Pubsub   database.Pubsub
+	Logger   slog.Logger
+	// HTTPClient sends deliveries, if omitted, http.DefaultClient will be used.
+	HTTPClient *http.Client
+	// MaxAttempts is the number of times a delivery is attempted before it
+	// is given up on, default 5.
+	MaxAttempts int
+	// RetryBackoff is the delay before the first retry. It doubles after
+	// every failed attempt, default 10s.
+	RetryBackoff time.Duration
+	// Timeout bounds a single delivery attempt, default 10s.
+	Timeout time.Duration
+}
+
+// Dispatcher delivers published events to the webhooks subscribed to them.
+type Dispatcher struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	opts   Options
+
+	unsubscribe func()
+	// mu guards closed so no delivery starts after Close begins waiting.
+	mu     sync.Mutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// New subscribes to webhook events and delivers them until closed.
+func New(opts Options) (*Dispatcher, error) {
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	if opts.MaxAttempts == 0 {
+		opts.MaxAttempts = 5
+	}
+	if opts.RetryBackoff == 0 {
+		opts.RetryBackoff = 10 * time.Second
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = 10 * time.Second
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	//nolint:gocritic // The dispatcher delivers events for every organization.
+	ctx = dbauthz.AsSystemRestricted(ctx)
+	d := &Dispatcher{
+		ctx:    ctx,
+		cancel: cancel,
+		opts:   opts,
+	}
+	unsubscribe, err := opts.Pubsub.Subscribe(EventChannel, d.handleEvent)
+	if err != nil {
+		cancel()
+		return nil, xerrors.Errorf("subscribe to webhook events: %w", err)
+	}
+	d.unsubscribe = unsubscribe
+	return d, nil
+}
+
+// Close stops receiving events and waits for in-flight deliveries. Pending
+// retries are abandoned.
+func (d *Dispatcher) Close() error {
+	d.unsubscribe()
+	d.mu.Lock()
+	d.closed = true
+	d.mu.Unlock()
+	d.cancel()
+	d.wg.Wait()
+	return nil
+}
+
+func (d *Dispatcher) handleEvent(_ context.Context, message []byte) {
+	var payload codersdk.WebhookEventPayload
+	err := json.Unmarshal(message, &payload)
+	if err != nil {
+		d.opts.Logger.Warn(d.ctx, "unmarshal webhook event", slog.Error(err))
+		return
+	}
+	organizationIDs := payload.OrganizationIDs
+	if payload.OrganizationID != uuid.Nil {
+		organizationIDs = append(organizationIDs, payload.OrganizationID)
+	}
+	webhooks, err := d.opts.Database.GetEnabledWebhooksByEvent(d.ctx, database.GetEnabledWebhooksByEventParams{
+		Event:           string(payload.Event),
+		OrganizationIDs: organizationIDs,
+	})
+	if err != nil {
+		if !xerrors.Is(err, context.Canceled) {
+			d.opts.Logger.Error(d.ctx, "get webhooks for event", slog.F("event", payload.Event), slog.Error(err))
+		}
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return
+	}
+	for _, webhook := range webhooks {
+		webhook := webhook
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			d.deliver(webhook, payload, message)
+		}()
+	}
+}
+
+// deliver sends the event to the webhook, retrying with exponential backoff
+// until it is accepted or the attempts run out.
+func (d *Dispatcher) deliver(webhook database.Webhook, payload codersdk.WebhookEventPayload, body []byte) {
+	logger := d.opts.Logger.With(
+		slog.F("webhook_id", webhook.ID),
+		slog.F("event", payload.Event),
+		slog.F("event_id", payload.ID),
+	)
+	backoff := d.opts.RetryBackoff
+	for attempt := 1; attempt <= d.opts.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-d.ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		delivery, err := d.opts.Database.InsertWebhookDelivery(d.ctx, database.InsertWebhookDeliveryParams{
+			ID:        uuid.New(),
+			WebhookID: webhook.ID,
+			EventID:   payload.ID,
+			Event:     string(payload.Event),
+			Payload:   body,
+			Attempt:   int32(attempt),
+			CreatedAt: database.Now(),
+		})
+		if xerrors.Is(err, sql.ErrNoRows) {
+			// Another replica claimed this attempt.
+			return
+		}
+		if err != nil {
+			if !xerrors.Is(err, context.Canceled) {
+				logger.Error(d.ctx, "insert webhook delivery", slog.Error(err))
+			}
+			return
+		}
+
+		statusCode, responseBody, sendErr := d.send(webhook, payload, body)
+		update := database.UpdateWebhookDeliveryByIDParams{
+			ID:           delivery.ID,
+			ResponseBody: responseBody,
+		}
+		if statusCode != 0 {
+			update.DeliveredAt = sql.NullTime{Time: database.Now(), Valid: true}
+			update.StatusCode = sql.NullInt32{Int32: int32(statusCode), Valid: true}
+		}
+		if sendErr != nil {
+			update.Error = sendErr.Error()
+		}
+		_, err = d.opts.Database.UpdateWebhookDeliveryByID(d.ctx, update)
+		if err != nil && !xerrors.Is(err, context.Canceled) {
+			logger.Error(d.ctx, "update webhook delivery", slog.Error(err))
+		}
+		if sendErr == nil {
+			return
+		}
+		logger.Debug(d.ctx, "webhook delivery failed", slog.F("attempt", attempt), slog.Error(sendErr))
+	}
+	logger.Warn(d.ctx, "webhook delivery failed, giving up", slog.F("attempts", d.opts.MaxAttempts))
+}
+
+// send makes a single delivery attempt. A non-nil error means the attempt
+// should be retried.
+func (d *Dispatcher) send(webhook database.Webhook, payload codersdk.WebhookEventPayload, body []byte) (statusCode int, responseBody string, err error) {
+	ctx, cancel := context.WithTimeout(d.ctx, d.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", xerrors.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", fmt.Sprintf("Coder-Webhooks/%s", buildinfo.Version()))
+	req.Header.Set(codersdk.WebhookEventHeader, string(payload.Event))
+	req.Header.Set(codersdk.WebhookDeliveryHeader, payload.ID.String())
+	req.Header.Set(codersdk.WebhookSignatureHeader, Sign(webhook.Secret, body))
+
+	res, err := d.opts.HTTPClient.Do(req)
+	if err != nil {
+		return 0, "", xerrors.Errorf("send request: %w", err)
+	}
+	defer res.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(res.Body, maxResponseBodySize))
+	// Postgres text columns must be valid UTF-8 without NUL bytes.
+	responseBody = strings.ReplaceAll(strings.ToValidUTF8(string(data), ""), "\x00", "")
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, responseBody, xerrors.Errorf("unexpected status code %d", res.StatusCode)
+	}
+	return res.StatusCode, responseBody, nil
+}
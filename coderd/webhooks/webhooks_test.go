@@ -0,0 +1,265 @@
+package webhooks_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"cdr.dev/slog/sloggers/slogtest"
+
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbfake"
+	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/coderd/webhooks"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Delivers", func(t *testing.T) {
+		t.Parallel()
+
+		db, pubsub := dbfake.New(), database.NewPubsubInMemory()
+		orgID := uuid.New()
+		received := make(chan *http.Request, 1)
+		bodies := make(chan []byte, 1)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			received <- r
+			bodies <- body
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+		webhook := dbgen.Webhook(t, db, database.Webhook{
+			Url:     srv.URL,
+			Events:  []string{string(codersdk.WebhookEventWorkspaceCreated)},
+			Enabled: true,
+		})
+		newDispatcher(t, db, pubsub)
+
+		err := webhooks.Publish(pubsub, codersdk.WebhookEventWorkspaceCreated, orgID, map[string]string{
+			"workspace_name": "dev",
+		})
+		require.NoError(t, err)
+
+		var (
+			req  *http.Request
+			body []byte
+		)
+		select {
+		case req = <-received:
+			body = <-bodies
+		case <-time.After(testutil.WaitShort):
+			t.Fatal("timed out waiting for delivery")
+		}
+		require.Equal(t, string(codersdk.WebhookEventWorkspaceCreated), req.Header.Get(codersdk.WebhookEventHeader))
+		require.Equal(t, webhooks.Sign(webhook.Secret, body), req.Header.Get(codersdk.WebhookSignatureHeader))
+		var payload codersdk.WebhookEventPayload
+		require.NoError(t, json.Unmarshal(body, &payload))
+		require.Equal(t, payload.ID.String(), req.Header.Get(codersdk.WebhookDeliveryHeader))
+		require.Equal(t, orgID, payload.OrganizationID)
+		require.Equal(t, "dev", payload.Data["workspace_name"])
+
+		deliveries := waitForDeliveries(t, db, webhook.ID, 1)
+		require.EqualValues(t, 1, deliveries[0].Attempt)
+		require.EqualValues(t, http.StatusNoContent, deliveries[0].StatusCode.Int32)
+		require.Empty(t, deliveries[0].Error)
+	})
+
+	t.Run("Retries", func(t *testing.T) {
+		t.Parallel()
+
+		db, pubsub := dbfake.New(), database.NewPubsubInMemory()
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				_, _ = w.Write([]byte("try again"))
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+		webhook := dbgen.Webhook(t, db, database.Webhook{
+			Url:     srv.URL,
+			Events:  []string{string(codersdk.WebhookEventBuildFailed)},
+			Enabled: true,
+		})
+		newDispatcher(t, db, pubsub)
+
+		err := webhooks.Publish(pubsub, codersdk.WebhookEventBuildFailed, uuid.New(), nil)
+		require.NoError(t, err)
+
+		deliveries := waitForDeliveries(t, db, webhook.ID, 2)
+		// Deliveries are returned newest first.
+		require.EqualValues(t, 2, deliveries[0].Attempt)
+		require.EqualValues(t, http.StatusOK, deliveries[0].StatusCode.Int32)
+		require.EqualValues(t, 1, deliveries[1].Attempt)
+		require.EqualValues(t, http.StatusBadGateway, deliveries[1].StatusCode.Int32)
+		require.Equal(t, "try again", deliveries[1].ResponseBody)
+		require.NotEmpty(t, deliveries[1].Error)
+		require.Equal(t, deliveries[0].EventID, deliveries[1].EventID)
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		t.Parallel()
+
+		db, pubsub := dbfake.New(), database.NewPubsubInMemory()
+		orgID := uuid.New()
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+		events := []string{string(codersdk.WebhookEventUserCreated)}
+		deployment := dbgen.Webhook(t, db, database.Webhook{Url: srv.URL, Events: events, Enabled: true})
+		sameOrg := dbgen.Webhook(t, db, database.Webhook{
+			Url:            srv.URL,
+			Events:         events,
+			Enabled:        true,
+			OrganizationID: uuid.NullUUID{UUID: orgID, Valid: true},
+		})
+		otherOrg := dbgen.Webhook(t, db, database.Webhook{
+			Url:            srv.URL,
+			Events:         events,
+			Enabled:        true,
+			OrganizationID: uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		})
+		disabled := dbgen.Webhook(t, db, database.Webhook{Url: srv.URL, Events: events})
+		otherEvent := dbgen.Webhook(t, db, database.Webhook{
+			Url:     srv.URL,
+			Events:  []string{string(codersdk.WebhookEventUserSuspended)},
+			Enabled: true,
+		})
+		newDispatcher(t, db, pubsub)
+
+		err := webhooks.Publish(pubsub, codersdk.WebhookEventUserCreated, orgID, nil)
+		require.NoError(t, err)
+
+		waitForDeliveries(t, db, deployment.ID, 1)
+		waitForDeliveries(t, db, sameOrg.ID, 1)
+		require.EqualValues(t, 2, calls.Load())
+		for _, id := range []uuid.UUID{otherOrg.ID, disabled.ID, otherEvent.ID} {
+			deliveries, err := db.GetWebhookDeliveriesByWebhookID(context.Background(), database.GetWebhookDeliveriesByWebhookIDParams{
+				WebhookID: id,
+			})
+			require.NoError(t, err)
+			require.Empty(t, deliveries)
+		}
+	})
+
+	t.Run("UserEvent", func(t *testing.T) {
+		t.Parallel()
+
+		db, pubsub := dbfake.New(), database.NewPubsubInMemory()
+		orgIDs := []uuid.UUID{uuid.New(), uuid.New()}
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+		events := []string{string(codersdk.WebhookEventUserSuspended)}
+		deployment := dbgen.Webhook(t, db, database.Webhook{Url: srv.URL, Events: events, Enabled: true})
+		org := dbgen.Webhook(t, db, database.Webhook{
+			Url:            srv.URL,
+			Events:         events,
+			Enabled:        true,
+			OrganizationID: uuid.NullUUID{UUID: orgIDs[1], Valid: true},
+		})
+		newDispatcher(t, db, pubsub)
+
+		err := webhooks.PublishUserEvent(pubsub, codersdk.WebhookEventUserSuspended, orgIDs, nil)
+		require.NoError(t, err)
+
+		waitForDeliveries(t, db, deployment.ID, 1)
+		waitForDeliveries(t, db, org.ID, 1)
+		// Deployment-wide webhooks get the event once, not once per
+		// organization of the user.
+		time.Sleep(testutil.IntervalMedium)
+		assert.EqualValues(t, 2, calls.Load())
+	})
+
+	t.Run("OneReplicaDelivers", func(t *testing.T) {
+		t.Parallel()
+
+		db, pubsub := dbfake.New(), database.NewPubsubInMemory()
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+		webhook := dbgen.Webhook(t, db, database.Webhook{
+			Url:     srv.URL,
+			Events:  []string{string(codersdk.WebhookEventWorkspaceDeleted)},
+			Enabled: true,
+		})
+		newDispatcher(t, db, pubsub)
+		newDispatcher(t, db, pubsub)
+
+		err := webhooks.Publish(pubsub, codersdk.WebhookEventWorkspaceDeleted, uuid.New(), nil)
+		require.NoError(t, err)
+
+		waitForDeliveries(t, db, webhook.ID, 1)
+		// Give the other replica a chance to deliver a duplicate.
+		time.Sleep(testutil.IntervalMedium)
+		assert.EqualValues(t, 1, calls.Load())
+	})
+}
+
+func newDispatcher(t *testing.T, db database.Store, pubsub database.Pubsub) *webhooks.Dispatcher {
+	t.Helper()
+
+	dispatcher, err := webhooks.New(webhooks.Options{
+		Database:     db,
+		Pubsub:       pubsub,
+		Logger:       slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}),
+		MaxAttempts:  3,
+		RetryBackoff: time.Millisecond,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = dispatcher.Close()
+	})
+	return dispatcher
+}
+
+func waitForDeliveries(t *testing.T, db database.Store, webhookID uuid.UUID, count int) []database.WebhookDelivery {
+	t.Helper()
+
+	var deliveries []database.WebhookDelivery
+	require.Eventually(t, func() bool {
+		var err error
+		deliveries, err = db.GetWebhookDeliveriesByWebhookID(context.Background(), database.GetWebhookDeliveriesByWebhookIDParams{
+			WebhookID: webhookID,
+		})
+		if err != nil || len(deliveries) != count {
+			return false
+		}
+		for _, delivery := range deliveries {
+			if !delivery.StatusCode.Valid && delivery.Error == "" {
+				return false
+			}
+		}
+		return true
+	}, testutil.WaitShort, testutil.IntervalFast)
+	return deliveries
+}
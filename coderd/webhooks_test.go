@@ -0,0 +1,189 @@
+package coderd_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/webhooks"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWebhooks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CRUD", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+		ctx, _ := testutil.Context(t)
+
+		created, err := client.CreateWebhook(ctx, codersdk.CreateWebhookRequest{
+			Name:   "builds",
+			URL:    "https://example.com/hook",
+			Events: []codersdk.WebhookEvent{codersdk.WebhookEventBuildFailed},
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, created.Secret, "a secret is generated")
+		require.Nil(t, created.OrganizationID)
+		require.True(t, created.Enabled)
+
+		webhooks, err := client.Webhooks(ctx)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		require.Empty(t, webhooks[0].Secret, "the secret is only returned on create")
+
+		enabled := false
+		updated, err := client.UpdateWebhook(ctx, created.ID, codersdk.UpdateWebhookRequest{
+			Events:  []codersdk.WebhookEvent{codersdk.WebhookEventBuildFailed, codersdk.WebhookEventBuildSucceeded},
+			Enabled: &enabled,
+		})
+		require.NoError(t, err)
+		require.Equal(t, "builds", updated.Name)
+		require.Len(t, updated.Events, 2)
+		require.False(t, updated.Enabled)
+
+		deliveries, err := client.WebhookDeliveries(ctx, created.ID, 0)
+		require.NoError(t, err)
+		require.Empty(t, deliveries)
+
+		err = client.DeleteWebhook(ctx, created.ID)
+		require.NoError(t, err)
+		_, err = client.Webhook(ctx, created.ID)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("InvalidEvent", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+		ctx, _ := testutil.Context(t)
+
+		_, err := client.CreateWebhook(ctx, codersdk.CreateWebhookRequest{
+			Name:   "bad",
+			URL:    "https://example.com/hook",
+			Events: []codersdk.WebhookEvent{"workspace.exploded"},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("Organization", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		ctx, _ := testutil.Context(t)
+
+		created, err := client.CreateOrganizationWebhook(ctx, user.OrganizationID, codersdk.CreateWebhookRequest{
+			Name:   "users",
+			URL:    "https://example.com/hook",
+			Events: []codersdk.WebhookEvent{codersdk.WebhookEventUserCreated},
+			Secret: "hunter2",
+		})
+		require.NoError(t, err)
+		require.Equal(t, "hunter2", created.Secret)
+		require.NotNil(t, created.OrganizationID)
+		require.Equal(t, user.OrganizationID, *created.OrganizationID)
+
+		webhooks, err := client.OrganizationWebhooks(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		deployment, err := client.Webhooks(ctx)
+		require.NoError(t, err)
+		require.Empty(t, deployment)
+	})
+
+	t.Run("MemberCannotCreate", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		ctx, _ := testutil.Context(t)
+
+		_, err := member.CreateOrganizationWebhook(ctx, user.OrganizationID, codersdk.CreateWebhookRequest{
+			Name:   "sneaky",
+			URL:    "https://example.com/hook",
+			Events: []codersdk.WebhookEvent{codersdk.WebhookEventUserCreated},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("DeliversWorkspaceEvents", func(t *testing.T) {
+		t.Parallel()
+
+		type delivery struct {
+			payload   codersdk.WebhookEventPayload
+			signature string
+			body      []byte
+		}
+		received := make(chan delivery, 8)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			var payload codersdk.WebhookEventPayload
+			_ = json.Unmarshal(body, &payload)
+			received <- delivery{payload: payload, signature: r.Header.Get(codersdk.WebhookSignatureHeader), body: body}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		ctx, _ := testutil.Context(t)
+
+		webhook, err := client.CreateWebhook(ctx, codersdk.CreateWebhookRequest{
+			Name:   "workspaces",
+			URL:    srv.URL,
+			Events: []codersdk.WebhookEvent{codersdk.WebhookEventWorkspaceCreated, codersdk.WebhookEventBuildSucceeded},
+		})
+		require.NoError(t, err)
+
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		events := map[codersdk.WebhookEvent]codersdk.WebhookEventPayload{}
+		for len(events) < 2 {
+			select {
+			case d := <-received:
+				require.Equal(t, webhooks.Sign(webhook.Secret, d.body), d.signature)
+				require.Equal(t, user.OrganizationID, d.payload.OrganizationID)
+				events[d.payload.Event] = d.payload
+			case <-time.After(testutil.WaitLong):
+				t.Fatal("timed out waiting for webhook deliveries")
+			}
+		}
+		require.Equal(t, workspace.ID.String(), events[codersdk.WebhookEventWorkspaceCreated].Data["workspace_id"])
+		require.Equal(t, workspace.LatestBuild.ID.String(), events[codersdk.WebhookEventBuildSucceeded].Data["build_id"])
+
+		require.Eventually(t, func() bool {
+			deliveries, err := client.WebhookDeliveries(ctx, webhook.ID, 0)
+			if err != nil || len(deliveries) != 2 {
+				return false
+			}
+			for _, delivery := range deliveries {
+				if !delivery.Succeeded() {
+					return false
+				}
+			}
+			return true
+		}, testutil.WaitShort, testutil.IntervalFast)
+	})
+}
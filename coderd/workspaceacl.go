@@ -0,0 +1,301 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+// @Summary Get workspace ACL
+// @ID get-workspace-acl
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Success 200 {object} codersdk.WorkspaceACL
+// @Router /workspaces/{workspace}/acl [get]
+func (api *API) workspaceACL(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx       = r.Context()
+		workspace = httpmw.WorkspaceParam(r)
+	)
+
+	if !api.Authorize(r, rbac.ActionRead, workspace) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	users, err := api.Database.GetWorkspaceUserRoles(ctx, workspace.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	dbGroups, err := api.Database.GetWorkspaceGroupRoles(ctx, workspace.ID)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	dbGroups, err = AuthorizeFilter(api.HTTPAuth, r, rbac.ActionRead, dbGroups)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching groups.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	userIDs := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		userIDs = append(userIDs, user.ID)
+	}
+	organizationIDsByUserID, err := api.organizationIDsByUserID(ctx, userIDs)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	acl := codersdk.WorkspaceACL{
+		Users:  make([]codersdk.WorkspaceUser, 0, len(users)),
+		Groups: make([]codersdk.WorkspaceGroup, 0, len(dbGroups)),
+	}
+	for _, user := range users {
+		acl.Users = append(acl.Users, codersdk.WorkspaceUser{
+			User: convertUser(user.User, organizationIDsByUserID[user.ID]),
+			Role: convertToWorkspaceRole(user.Actions),
+		})
+	}
+	for _, group := range dbGroups {
+		members, err := api.Database.GetGroupMembers(ctx, group.ID)
+		if err != nil {
+			httpapi.InternalServerError(rw, err)
+			return
+		}
+		acl.Groups = append(acl.Groups, codersdk.WorkspaceGroup{
+			Group: convertGroup(group.Group, members),
+			Role:  convertToWorkspaceRole(group.Actions),
+		})
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, acl)
+}
+
+// @Summary Update workspace ACL
+// @ID update-workspace-acl
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Param request body codersdk.UpdateWorkspaceACL true "Update workspace ACL request"
+// @Success 200 {object} codersdk.Response
+// @Router /workspaces/{workspace}/acl [patch]
+func (api *API) patchWorkspaceACL(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		workspace         = httpmw.WorkspaceParam(r)
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.Workspace](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+	aReq.Old = workspace
+
+	// Users the workspace is shared with may not share it further, only
+	// the owner and admins may change the ACL.
+	if !api.Authorize(r, rbac.ActionUpdate, workspace.RBACObjectNoACL()) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.UpdateWorkspaceACL
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	validErrs := validateWorkspaceACLPerms(ctx, api.Database, workspace, req.UserPerms, "user_perms", true)
+	validErrs = append(validErrs,
+		validateWorkspaceACLPerms(ctx, api.Database, workspace, req.GroupPerms, "group_perms", false)...)
+	if len(validErrs) > 0 {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid request to update workspace ACL.",
+			Validations: validErrs,
+		})
+		return
+	}
+
+	err := api.Database.InTx(func(tx database.Store) error {
+		var err error
+		workspace, err = tx.GetWorkspaceByID(ctx, workspace.ID)
+		if err != nil {
+			return xerrors.Errorf("get workspace by ID: %w", err)
+		}
+		if workspace.UserACL == nil {
+			workspace.UserACL = database.WorkspaceACL{}
+		}
+		if workspace.GroupACL == nil {
+			workspace.GroupACL = database.WorkspaceACL{}
+		}
+
+		for id, role := range req.UserPerms {
+			// An empty role implies deletion.
+			if role == codersdk.WorkspaceRoleDeleted {
+				delete(workspace.UserACL, id)
+				continue
+			}
+			workspace.UserACL[id] = convertSDKWorkspaceRole(role)
+		}
+		for id, role := range req.GroupPerms {
+			if role == codersdk.WorkspaceRoleDeleted {
+				delete(workspace.GroupACL, id)
+				continue
+			}
+			workspace.GroupACL[id] = convertSDKWorkspaceRole(role)
+		}
+
+		workspace, err = tx.UpdateWorkspaceACLByID(ctx, database.UpdateWorkspaceACLByIDParams{
+			ID:       workspace.ID,
+			UserACL:  workspace.UserACL,
+			GroupACL: workspace.GroupACL,
+		})
+		if err != nil {
+			return xerrors.Errorf("update workspace ACL by ID: %w", err)
+		}
+		return nil
+	}, nil)
+	if err != nil {
+		httpapi.InternalServerError(rw, err)
+		return
+	}
+
+	aReq.New = workspace
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: "Successfully updated workspace ACL list.",
+	})
+}
+
+// nolint:revive // isUser switches between validating user and group IDs.
+func validateWorkspaceACLPerms(ctx context.Context, db database.Store, workspace database.Workspace, perms map[string]codersdk.WorkspaceRole, field string, isUser bool) []codersdk.ValidationError {
+	var validErrs []codersdk.ValidationError
+	for k, v := range perms {
+		if convertSDKWorkspaceRole(v) == nil && v != codersdk.WorkspaceRoleDeleted {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: field, Detail: fmt.Sprintf("Role %q is not a valid workspace role.", v)})
+			continue
+		}
+
+		id, err := uuid.Parse(k)
+		if err != nil {
+			validErrs = append(validErrs, codersdk.ValidationError{Field: field, Detail: fmt.Sprintf("ID %q must be a valid UUID.", k)})
+			continue
+		}
+		if v == codersdk.WorkspaceRoleDeleted {
+			// Removing an ID that no longer exists is allowed.
+			continue
+		}
+
+		if isUser {
+			if id == workspace.OwnerID {
+				validErrs = append(validErrs, codersdk.ValidationError{Field: field, Detail: "A workspace cannot be shared with its owner."})
+				continue
+			}
+			// This could get slow if we get a ton of user perm updates.
+			_, err = db.GetUserByID(ctx, id)
+			if err != nil {
+				validErrs = append(validErrs, codersdk.ValidationError{Field: field, Detail: fmt.Sprintf("Failed to find user with ID %q: %v", k, err.Error())})
+				continue
+			}
+		} else {
+			// This could get slow if we get a ton of group perm updates.
+			group, err := db.GetGroupByID(ctx, id)
+			if err != nil {
+				validErrs = append(validErrs, codersdk.ValidationError{Field: field, Detail: fmt.Sprintf("Failed to find group with ID %q: %v", k, err.Error())})
+				continue
+			}
+			if group.OrganizationID != workspace.OrganizationID {
+				validErrs = append(validErrs, codersdk.ValidationError{Field: field, Detail: fmt.Sprintf("Group %q is not in the organization of the workspace.", group.Name)})
+				continue
+			}
+		}
+	}
+
+	return validErrs
+}
+
+// organizationIDsByUserID returns the organizations each of the users is a
+// member of.
+func (api *API) organizationIDsByUserID(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]uuid.UUID, error) {
+	organizationIDsByUserID := map[uuid.UUID][]uuid.UUID{}
+	if len(userIDs) == 0 {
+		return organizationIDsByUserID, nil
+	}
+	rows, err := api.Database.GetOrganizationIDsByMemberIDs(ctx, userIDs)
+	if err != nil && !xerrors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	for _, row := range rows {
+		organizationIDsByUserID[row.UserID] = row.OrganizationIDs
+	}
+	return organizationIDsByUserID, nil
+}
+
+func convertGroup(g database.Group, users []database.User) codersdk.Group {
+	// Every member is part of the group's organization, so there is no need
+	// to query the organizations of each member.
+	orgs := make(map[uuid.UUID][]uuid.UUID)
+	for _, user := range users {
+		orgs[user.ID] = []uuid.UUID{g.OrganizationID}
+	}
+	return codersdk.Group{
+		ID:             g.ID,
+		Name:           g.Name,
+		OrganizationID: g.OrganizationID,
+		AvatarURL:      g.AvatarURL,
+		QuotaAllowance: int(g.QuotaAllowance),
+		Members:        convertUsers(users, orgs),
+	}
+}
+
+func convertToWorkspaceRole(actions []rbac.Action) codersdk.WorkspaceRole {
+	for _, action := range actions {
+		if action == rbac.ActionUpdate || action == rbac.WildcardSymbol {
+			return codersdk.WorkspaceRoleAdmin
+		}
+	}
+	for _, action := range actions {
+		if action == rbac.ActionRead {
+			return codersdk.WorkspaceRoleUse
+		}
+	}
+	return ""
+}
+
+// convertSDKWorkspaceRole returns the actions a role grants on the workspace.
+// Both roles may also connect to the workspace, see
+// database.Workspace.ExecutionRBAC.
+func convertSDKWorkspaceRole(role codersdk.WorkspaceRole) []rbac.Action {
+	switch role {
+	case codersdk.WorkspaceRoleAdmin:
+		return []rbac.Action{rbac.ActionRead, rbac.ActionUpdate}
+	case codersdk.WorkspaceRoleUse:
+		return []rbac.Action{rbac.ActionRead}
+	}
+
+	return nil
+}
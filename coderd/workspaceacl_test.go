@@ -0,0 +1,203 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWorkspaceACL(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T, opts *coderdtest.Options) (*codersdk.Client, codersdk.CreateFirstUserResponse, codersdk.Workspace) {
+		t.Helper()
+		if opts == nil {
+			opts = &coderdtest.Options{}
+		}
+		opts.IncludeProvisionerDaemon = true
+		client := coderdtest.New(t, opts)
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		return client, user, workspace
+	}
+
+	canExecute := func(t *testing.T, client *codersdk.Client, workspace codersdk.Workspace) bool {
+		t.Helper()
+		ctx, _ := testutil.Context(t)
+		resp, err := client.AuthCheck(ctx, codersdk.AuthorizationRequest{
+			Checks: map[string]codersdk.AuthorizationCheck{
+				"ssh": {
+					Object: codersdk.AuthorizationObject{
+						ResourceType: rbac.ResourceWorkspaceExecution.Type,
+						ResourceID:   workspace.ID.String(),
+					},
+					Action: string(rbac.ActionCreate),
+				},
+			},
+		})
+		require.NoError(t, err)
+		return resp["ssh"]
+	}
+
+	t.Run("Use", func(t *testing.T) {
+		t.Parallel()
+
+		auditor := audit.NewMock()
+		client, user, workspace := setup(t, &coderdtest.Options{Auditor: auditor})
+		member, memberUser := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := member.Workspace(ctx, workspace.ID)
+		require.Error(t, err)
+		require.False(t, canExecute(t, member, workspace))
+
+		numLogs := len(auditor.AuditLogs)
+		err = client.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+			UserPerms: map[string]codersdk.WorkspaceRole{
+				memberUser.ID.String(): codersdk.WorkspaceRoleUse,
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, auditor.AuditLogs, numLogs+1)
+		assert.Equal(t, database.AuditActionWrite, auditor.AuditLogs[numLogs].Action)
+		assert.Equal(t, workspace.ID, auditor.AuditLogs[numLogs].ResourceID)
+
+		acl, err := client.WorkspaceACL(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Len(t, acl.Users, 1)
+		require.Equal(t, memberUser.ID, acl.Users[0].ID)
+		require.Equal(t, codersdk.WorkspaceRoleUse, acl.Users[0].Role)
+
+		// The member can see and connect to the workspace.
+		_, err = member.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.True(t, canExecute(t, member, workspace))
+		workspaces, err := member.Workspaces(ctx, codersdk.WorkspaceFilter{})
+		require.NoError(t, err)
+		require.Len(t, workspaces.Workspaces, 1)
+		require.Equal(t, workspace.ID, workspaces.Workspaces[0].ID)
+
+		// But may not stop it.
+		_, err = member.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionStop,
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		t.Parallel()
+
+		client, user, workspace := setup(t, nil)
+		member, memberUser := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		err := client.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+			UserPerms: map[string]codersdk.WorkspaceRole{
+				memberUser.ID.String(): codersdk.WorkspaceRoleAdmin,
+			},
+		})
+		require.NoError(t, err)
+
+		require.True(t, canExecute(t, member, workspace))
+		build, err := member.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionStop,
+		})
+		require.NoError(t, err)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, build.ID)
+
+		// Deleting the workspace is left to the owner.
+		_, err = member.CreateWorkspaceBuild(ctx, workspace.ID, codersdk.CreateWorkspaceBuildRequest{
+			Transition: codersdk.WorkspaceTransitionDelete,
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("NoReshare", func(t *testing.T) {
+		t.Parallel()
+
+		client, user, workspace := setup(t, nil)
+		member, memberUser := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		_, otherUser := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		err := client.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+			UserPerms: map[string]codersdk.WorkspaceRole{
+				memberUser.ID.String(): codersdk.WorkspaceRoleAdmin,
+			},
+		})
+		require.NoError(t, err)
+
+		err = member.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+			UserPerms: map[string]codersdk.WorkspaceRole{
+				otherUser.ID.String(): codersdk.WorkspaceRoleUse,
+			},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		t.Parallel()
+
+		client, user, workspace := setup(t, nil)
+		member, memberUser := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		ctx, _ := testutil.Context(t)
+		err := client.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+			UserPerms: map[string]codersdk.WorkspaceRole{
+				memberUser.ID.String(): codersdk.WorkspaceRoleUse,
+			},
+		})
+		require.NoError(t, err)
+		err = client.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+			UserPerms: map[string]codersdk.WorkspaceRole{
+				memberUser.ID.String(): codersdk.WorkspaceRoleDeleted,
+			},
+		})
+		require.NoError(t, err)
+
+		acl, err := client.WorkspaceACL(ctx, workspace.ID)
+		require.NoError(t, err)
+		require.Empty(t, acl.Users)
+		_, err = member.Workspace(ctx, workspace.ID)
+		require.Error(t, err)
+		require.False(t, canExecute(t, member, workspace))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		client, user, workspace := setup(t, nil)
+		_, memberUser := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+
+		for name, perms := range map[string]map[string]codersdk.WorkspaceRole{
+			"Role":    {memberUser.ID.String(): "owner"},
+			"ID":      {"bob": codersdk.WorkspaceRoleUse},
+			"Owner":   {user.UserID.String(): codersdk.WorkspaceRoleUse},
+			"Missing": {uuid.NewString(): codersdk.WorkspaceRoleUse},
+		} {
+			ctx, _ := testutil.Context(t)
+			err := client.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+				UserPerms: perms,
+			})
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr, name)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode(), name)
+		}
+	})
+}
@@ -25,6 +25,7 @@ import (
 
 	"cdr.dev/slog"
 	"github.com/coder/coder/agent"
+	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/database/dbauthz"
 	"github.com/coder/coder/coderd/gitauth"
@@ -131,6 +132,17 @@ func (api *API) workspaceAgentMetadata(rw http.ResponseWriter, r *http.Request)
 		})
 		return
 	}
+	// The agent is only allowed to read its own workspace, but shows the
+	// deprecation message of the template.
+	// nolint:gocritic
+	template, err := api.Database.GetTemplateByID(dbauthz.AsSystemRestricted(ctx), workspace.TemplateID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace template.",
+			Detail:  err.Error(),
+		})
+		return
+	}
 
 	vscodeProxyURI := strings.ReplaceAll(api.AppHostname, "*",
 		fmt.Sprintf("%s://{{port}}--%s--%s--%s",
@@ -144,15 +156,16 @@ func (api *API) workspaceAgentMetadata(rw http.ResponseWriter, r *http.Request)
 	}
 
 	httpapi.Write(ctx, rw, http.StatusOK, agentsdk.Metadata{
-		Apps:                 convertApps(dbApps),
-		DERPMap:              api.DERPMap,
-		GitAuthConfigs:       len(api.GitAuthConfigs),
-		EnvironmentVariables: apiAgent.EnvironmentVariables,
-		StartupScript:        apiAgent.StartupScript,
-		Directory:            apiAgent.Directory,
-		VSCodePortProxyURI:   vscodeProxyURI,
-		MOTDFile:             workspaceAgent.MOTDFile,
-		StartupScriptTimeout: time.Duration(apiAgent.StartupScriptTimeoutSeconds) * time.Second,
+		Apps:                       convertApps(dbApps),
+		DERPMap:                    api.DERPMap,
+		GitAuthConfigs:             len(api.GitAuthConfigs),
+		EnvironmentVariables:       apiAgent.EnvironmentVariables,
+		StartupScript:              apiAgent.StartupScript,
+		Directory:                  apiAgent.Directory,
+		VSCodePortProxyURI:         vscodeProxyURI,
+		MOTDFile:                   workspaceAgent.MOTDFile,
+		StartupScriptTimeout:       time.Duration(apiAgent.StartupScriptTimeoutSeconds) * time.Second,
+		TemplateDeprecationMessage: template.Deprecated,
 	})
 }
 
@@ -293,6 +306,8 @@ func (api *API) workspaceAgentPTY(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer ptNetConn.Close()
+	disconnect := api.auditWorkspaceConnection(r, workspace, workspaceAgent, codersdk.ConnectionTypeReconnectingPTY, r.URL.Query().Get("command"))
+	defer disconnect()
 	agent.Bicopy(ctx, wsNetConn, ptNetConn)
 }
 
@@ -759,7 +774,17 @@ func (api *API) workspaceAgentClientCoordinate(rw http.ResponseWriter, r *http.R
 	go httpapi.Heartbeat(ctx, conn)
 
 	defer conn.Close(websocket.StatusNormalClosure, "")
-	err = (*api.TailnetCoordinator.Load()).ServeClient(wsNetConn, uuid.New(), workspaceAgent.ID)
+	disconnect := api.auditWorkspaceConnection(r, workspace, workspaceAgent, codersdk.ConnectionTypeTailnet, "")
+	defer disconnect()
+	clientID := uuid.New()
+	removeClient := api.tailnetClients.add(clientID, &tailnetClient{
+		agentID:    workspaceAgent.ID,
+		userID:     httpmw.APIKey(r).UserID,
+		remoteAddr: r.RemoteAddr,
+		userAgent:  r.UserAgent(),
+	})
+	defer removeClient()
+	err = (*api.TailnetCoordinator.Load()).ServeClient(wsNetConn, clientID, workspaceAgent.ID)
 	if err != nil {
 		_ = conn.Close(websocket.StatusInternalError, err.Error())
 		return
@@ -938,6 +963,16 @@ func (api *API) workspaceAgentReportStats(rw http.ResponseWriter, r *http.Reques
 		api.Logger.Error(ctx, "marshal agent connections by proto", slog.F("workspace_agent", workspaceAgent.ID), slog.Error(err))
 		payload = json.RawMessage("{}")
 	}
+	connectionsByPort := req.ConnectionsByPort
+	if connectionsByPort == nil {
+		// Older agents don't count connections by port.
+		connectionsByPort = map[string]int64{}
+	}
+	portPayload, err := json.Marshal(connectionsByPort)
+	if err != nil {
+		api.Logger.Error(ctx, "marshal agent connections by port", slog.F("workspace_agent", workspaceAgent.ID), slog.Error(err))
+		portPayload = json.RawMessage("{}")
+	}
 
 	now := database.Now()
 	_, err = api.Database.InsertWorkspaceAgentStat(ctx, database.InsertWorkspaceAgentStatParams{
@@ -953,6 +988,7 @@ func (api *API) workspaceAgentReportStats(rw http.ResponseWriter, r *http.Reques
 		RxBytes:            req.RxBytes,
 		TxPackets:          req.TxPackets,
 		TxBytes:            req.TxBytes,
+		ConnectionsByPort:  portPayload,
 	})
 	if err != nil {
 		httpapi.InternalServerError(rw, err)
@@ -1028,6 +1064,80 @@ func (api *API) workspaceAgentReportLifecycle(rw http.ResponseWriter, r *http.Re
 	httpapi.Write(ctx, rw, http.StatusNoContent, nil)
 }
 
+// @Summary Submit workspace agent connection
+// @ID submit-workspace-agent-connection
+// @Security CoderSessionToken
+// @Accept json
+// @Tags Agents
+// @Param request body agentsdk.PostConnectionRequest true "Workspace agent connection request"
+// @Success 204 "Success"
+// @Router /workspaceagents/me/report-connection [post]
+// @x-apidocgen {"skip": true}
+func (api *API) workspaceAgentReportConnection(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	workspaceAgent := httpmw.WorkspaceAgent(r)
+	workspace, err := api.Database.GetWorkspaceByAgentID(ctx, workspaceAgent.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Failed to get workspace.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	var req agentsdk.PostConnectionRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	action := database.AuditAction(req.Action)
+	if action != database.AuditActionConnect && action != database.AuditActionDisconnect {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid connection action.",
+			Detail:  fmt.Sprintf("Invalid connection action %q, must be one of %q.", req.Action, []database.AuditAction{database.AuditActionConnect, database.AuditActionDisconnect}),
+		})
+		return
+	}
+	if req.Type != codersdk.ConnectionTypeSSH && req.Type != codersdk.ConnectionTypePortForward {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Invalid connection type.",
+			Detail:  fmt.Sprintf("Invalid connection type %q, must be one of %q.", req.Type, []codersdk.ConnectionType{codersdk.ConnectionTypeSSH, codersdk.ConnectionTypePortForward}),
+		})
+		return
+	}
+
+	fields := api.connectionAuditFields(ctx, workspace, workspaceAgent, req.Type, req.Detail)
+	if action == database.AuditActionDisconnect {
+		fields.DurationMS = req.DurationMS
+	}
+	// The agent only knows the tailnet address of the peer, which is matched
+	// with the user coordinating the connection through this replica. If the
+	// user coordinates through another replica, they're unknown, and the
+	// tailnet address is recorded.
+	params := &audit.ConnectionAuditParams{
+		Audit:        *api.Auditor.Load(),
+		Log:          api.Logger,
+		Action:       action,
+		ConnectionID: req.ID,
+		UserID:       uuid.Nil,
+		Workspace:    workspace,
+		RemoteAddr:   req.RemoteAddr,
+		Fields:       fields,
+	}
+	if addrPort, err := netip.ParseAddrPort(req.RemoteAddr); err == nil {
+		client, ok := api.tailnetClients.match(*api.TailnetCoordinator.Load(), workspaceAgent.ID, req.ID, action, addrPort.Addr())
+		if ok {
+			params.UserID = client.userID
+			params.RemoteAddr = client.remoteAddr
+			params.UserAgent = client.userAgent
+		}
+	}
+	audit.ConnectionAudit(ctx, params)
+
+	httpapi.Write(ctx, rw, http.StatusNoContent, nil)
+}
+
 // @Summary Submit workspace agent application health
 // @ID submit-workspace-agent-application-health
 // @Security CoderSessionToken
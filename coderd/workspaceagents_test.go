@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"regexp"
 	"runtime"
 	"strconv"
@@ -19,10 +20,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/oauth2"
+	"nhooyr.io/websocket"
 
 	"cdr.dev/slog"
 	"cdr.dev/slog/sloggers/slogtest"
 	"github.com/coder/coder/agent"
+	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/coderdtest"
 	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/gitauth"
@@ -30,6 +33,7 @@ import (
 	"github.com/coder/coder/codersdk/agentsdk"
 	"github.com/coder/coder/provisioner/echo"
 	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/tailnet"
 	"github.com/coder/coder/testutil"
 )
 
@@ -1281,3 +1285,157 @@ func TestWorkspaceAgent_LifecycleState(t *testing.T) {
 		}
 	})
 }
+
+func TestWorkspaceAgent_ReportConnection(t *testing.T) {
+	t.Parallel()
+
+	auditor := audit.NewMock()
+	client := coderdtest.New(t, &coderdtest.Options{
+		IncludeProvisionerDaemon: true,
+		Auditor:                  auditor,
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	authToken := uuid.NewString()
+	version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+		Parse:         echo.ParseComplete,
+		ProvisionPlan: echo.ProvisionComplete,
+		ProvisionApply: []*proto.Provision_Response{{
+			Type: &proto.Provision_Response_Complete{
+				Complete: &proto.Provision_Complete{
+					Resources: []*proto.Resource{{
+						Name: "example",
+						Type: "aws_instance",
+						Agents: []*proto.Agent{{
+							Id:   uuid.NewString(),
+							Name: "dev",
+							Auth: &proto.Agent_Token{
+								Token: authToken,
+							},
+						}},
+					}},
+				},
+			},
+		}},
+	})
+	template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+	coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+	workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+	coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+	agentClient := agentsdk.New(client.URL)
+	agentClient.SetSessionToken(authToken)
+
+	connectionLogs := func(connectionID uuid.UUID) []database.AuditLog {
+		var logs []database.AuditLog
+		for _, alog := range auditor.AuditLogs {
+			if alog.RequestID == connectionID {
+				logs = append(logs, alog)
+			}
+		}
+		return logs
+	}
+
+	ctx, _ := testutil.Context(t)
+	// Nobody coordinates a connection to the agent, so the user is unknown
+	// and the tailnet address the agent reported is recorded.
+	connectionID := uuid.New()
+	err := agentClient.PostConnection(ctx, agentsdk.PostConnectionRequest{
+		ID:         connectionID,
+		Action:     codersdk.AuditActionConnect,
+		Type:       codersdk.ConnectionTypeSSH,
+		RemoteAddr: "[fd7a:115c:a1e0::1]:2222",
+	})
+	require.NoError(t, err)
+	err = agentClient.PostConnection(ctx, agentsdk.PostConnectionRequest{
+		ID:         connectionID,
+		Action:     codersdk.AuditActionDisconnect,
+		Type:       codersdk.ConnectionTypeSSH,
+		RemoteAddr: "[fd7a:115c:a1e0::1]:2222",
+		DurationMS: 1500,
+	})
+	require.NoError(t, err)
+
+	err = agentClient.PostConnection(ctx, agentsdk.PostConnectionRequest{
+		ID:     uuid.New(),
+		Action: codersdk.AuditActionCreate,
+		Type:   codersdk.ConnectionTypeSSH,
+	})
+	require.Error(t, err)
+	err = agentClient.PostConnection(ctx, agentsdk.PostConnectionRequest{
+		ID:     uuid.New(),
+		Action: codersdk.AuditActionConnect,
+		Type:   codersdk.ConnectionTypeWorkspaceApp,
+	})
+	require.Error(t, err)
+
+	connections := connectionLogs(connectionID)
+	require.Len(t, connections, 2)
+	for _, alog := range connections {
+		require.Equal(t, uuid.Nil, alog.UserID)
+		require.Equal(t, database.ResourceTypeWorkspace, alog.ResourceType)
+		require.Equal(t, workspace.ID, alog.ResourceID)
+		require.Equal(t, workspace.Name, alog.ResourceTarget)
+		require.Equal(t, "fd7a:115c:a1e0::1", alog.Ip.IPNet.IP.String())
+	}
+	require.Equal(t, database.AuditActionConnect, connections[0].Action)
+	require.Equal(t, database.AuditActionDisconnect, connections[1].Action)
+
+	var fields codersdk.ConnectionAuditFields
+	require.NoError(t, json.Unmarshal(connections[1].AdditionalFields, &fields))
+	require.Equal(t, codersdk.ConnectionTypeSSH, fields.ConnectionType)
+	require.Equal(t, "dev", fields.AgentName)
+	require.Equal(t, workspace.Name, fields.WorkspaceName)
+	require.Equal(t, workspace.OwnerName, fields.WorkspaceOwner)
+	require.Equal(t, int64(1500), fields.DurationMS)
+
+	// A user coordinates a connection from a tailnet address, so the
+	// connections the agent reports from it are attributed to the user.
+	workspace, err = client.Workspace(ctx, workspace.ID)
+	require.NoError(t, err)
+	coordinateURL, err := client.URL.Parse(fmt.Sprintf("/api/v2/workspaceagents/%s/coordinate", workspace.LatestBuild.Resources[0].Agents[0].ID))
+	require.NoError(t, err)
+	// nolint:bodyclose
+	ws, _, err := websocket.Dial(ctx, coordinateURL.String(), &websocket.DialOptions{
+		HTTPHeader: http.Header{codersdk.SessionTokenHeader: []string{client.SessionToken()}},
+	})
+	require.NoError(t, err)
+	defer ws.Close(websocket.StatusNormalClosure, "")
+	sendNode, _ := tailnet.ServeCoordinator(websocket.NetConn(ctx, ws, websocket.MessageBinary), func([]*tailnet.Node) error {
+		return nil
+	})
+	ip := tailnet.IP()
+	sendNode(&tailnet.Node{Addresses: []netip.Prefix{netip.PrefixFrom(ip, 128)}})
+	remoteAddr := netip.AddrPortFrom(ip, 2222).String()
+
+	// The coordinator receives the node in the background.
+	require.Eventually(t, func() bool {
+		connectionID = uuid.New()
+		err := agentClient.PostConnection(ctx, agentsdk.PostConnectionRequest{
+			ID:         connectionID,
+			Action:     codersdk.AuditActionConnect,
+			Type:       codersdk.ConnectionTypeSSH,
+			RemoteAddr: remoteAddr,
+		})
+		if !assert.NoError(t, err) {
+			return false
+		}
+		logs := connectionLogs(connectionID)
+		return len(logs) == 1 && logs[0].UserID == user.UserID
+	}, testutil.WaitShort, testutil.IntervalFast)
+	err = agentClient.PostConnection(ctx, agentsdk.PostConnectionRequest{
+		ID:         connectionID,
+		Action:     codersdk.AuditActionDisconnect,
+		Type:       codersdk.ConnectionTypeSSH,
+		RemoteAddr: remoteAddr,
+		DurationMS: 1500,
+	})
+	require.NoError(t, err)
+
+	connections = connectionLogs(connectionID)
+	require.Len(t, connections, 2)
+	for _, alog := range connections {
+		require.Equal(t, user.UserID, alog.UserID)
+		// The address of the coordinate connection is recorded.
+		require.Equal(t, "127.0.0.1", alog.Ip.IPNet.IP.String())
+	}
+}
@@ -938,6 +938,8 @@ func (api *API) proxyWorkspaceApplication(proxyApp proxyApplication, rw http.Res
 	// end span so we don't get long lived trace data
 	tracing.EndHTTPSpan(r, http.StatusOK, trace.SpanFromContext(ctx))
 
+	disconnect := api.auditWorkspaceAppRequest(r, proxyApp)
+	defer disconnect()
 	proxy.ServeHTTP(rw, r)
 }
 
@@ -93,3 +93,18 @@ func TestAPIKeyEncryption(t *testing.T) {
 		})
 	})
 }
+
+func TestAppConnectionTracker(t *testing.T) {
+	t.Parallel()
+
+	tracker := newAppConnectionTracker()
+	now := time.Now()
+	require.False(t, tracker.seen("a", now))
+	require.True(t, tracker.seen("a", now.Add(time.Minute)))
+	require.False(t, tracker.seen("b", now.Add(time.Minute)))
+	// Requests keep the connection alive.
+	require.True(t, tracker.seen("a", now.Add(appConnectionAuditInterval)))
+	require.False(t, tracker.seen("a", now.Add(3*appConnectionAuditInterval)))
+	// Stale keys are swept.
+	require.NotContains(t, tracker.lastSeen, "b")
+}
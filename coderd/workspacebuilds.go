@@ -69,6 +69,15 @@ func (api *API) workspaceBuild(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = api.setProvisionerJobQueues(ctx, &apiBuild.Job)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job queue.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
 	httpapi.Write(ctx, rw, http.StatusOK, apiBuild)
 }
 
@@ -188,6 +197,19 @@ func (api *API) workspaceBuilds(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	jobs := make([]*codersdk.ProvisionerJob, 0, len(apiBuilds))
+	for i := range apiBuilds {
+		jobs = append(jobs, &apiBuilds[i].Job)
+	}
+	err = api.setProvisionerJobQueues(ctx, jobs...)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job queue.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
 	httpapi.Write(ctx, rw, http.StatusOK, apiBuilds)
 }
 
@@ -281,6 +303,15 @@ func (api *API) workspaceBuildByBuildNumber(rw http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	err = api.setProvisionerJobQueues(ctx, &apiBuild.Job)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job queue.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
 	httpapi.Write(ctx, rw, http.StatusOK, apiBuild)
 }
 
@@ -325,6 +356,18 @@ func (api *API) postWorkspaceBuilds(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	priority, ok := requestedProvisionerJobPriority(createBuild.Priority)
+	if !ok {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Priority %q is invalid.", createBuild.Priority),
+			Validations: []codersdk.ValidationError{{
+				Field:  "priority",
+				Detail: "must be interactive, automatic or background",
+			}},
+		})
+		return
+	}
+
 	if createBuild.TemplateVersionID == uuid.Nil {
 		latestBuild, latestBuildErr := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
 		if latestBuildErr != nil {
@@ -355,6 +398,17 @@ func (api *API) postWorkspaceBuilds(rw http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	// Archiving a version must never prevent deleting a workspace.
+	if templateVersion.Archived && createBuild.Transition != codersdk.WorkspaceTransitionDelete {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Template version %q is archived, and cannot be used to build workspaces.", templateVersion.Name),
+			Validations: []codersdk.ValidationError{{
+				Field:  "template_version_id",
+				Detail: "template version is archived",
+			}},
+		})
+		return
+	}
 
 	template, err := api.Database.GetTemplateByID(ctx, templateVersion.TemplateID.UUID)
 	if err != nil {
@@ -579,6 +633,7 @@ func (api *API) postWorkspaceBuilds(rw http.ResponseWriter, r *http.Request) {
 			FileID:         templateVersionJob.FileID,
 			Input:          input,
 			Tags:           tags,
+			Priority:       priority,
 		})
 		if err != nil {
 			return xerrors.Errorf("insert provisioner job: %w", err)
@@ -657,6 +712,15 @@ func (api *API) postWorkspaceBuilds(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	err = api.setProvisionerJobQueues(ctx, &apiBuild.Job)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job queue.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
 	api.publishWorkspaceUpdate(ctx, workspace.ID)
 
 	httpapi.Write(ctx, rw, http.StatusCreated, apiBuild)
@@ -845,6 +909,10 @@ func (api *API) workspaceBuildParameters(rw http.ResponseWriter, r *http.Request
 // @Param before query int false "Before Unix timestamp"
 // @Param after query int false "After Unix timestamp"
 // @Param follow query bool false "Follow log stream"
+// @Param level query string false "Lowest log level to return" Enums(trace,debug,info,warn,error)
+// @Param stage query string false "Only return logs of this stage"
+// @Param search query string false "Only return logs whose output contains this, ignoring case"
+// @Param format query string false "Response format, text and ndjson are returned as attachments" Enums(json,text,ndjson)
 // @Success 200 {array} codersdk.ProvisionerJobLog
 // @Router /workspacebuilds/{workspacebuild}/logs [get]
 func (api *API) workspaceBuildLogs(rw http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,201 @@
+package coderd
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+// @Summary Clone workspace
+// @Description The new workspace belongs to the current user and is created
+// @Description with the parameter values of the latest build and the labels
+// @Description of the source.
+// @ID clone-workspace
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Param request body codersdk.CloneWorkspaceRequest true "Clone workspace request"
+// @Success 201 {object} codersdk.Workspace
+// @Router /workspaces/{workspace}/clone [post]
+func (api *API) postWorkspaceClone(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx     = r.Context()
+		source  = httpmw.WorkspaceParam(r)
+		apiKey  = httpmw.APIKey(r)
+		auditor = api.Auditor.Load()
+	)
+
+	if !api.Authorize(r, rbac.ActionRead, source) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.CloneWorkspaceRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	if !api.Authorize(r, rbac.ActionCreate,
+		rbac.ResourceWorkspace.InOrg(source.OrganizationID).WithOwner(apiKey.UserID.String())) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	user, err := api.Database.GetUserByID(ctx, apiKey.UserID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching user.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	wriBytes, err := json.Marshal(audit.AdditionalFields{
+		WorkspaceOwner: user.Username,
+	})
+	if err != nil {
+		api.Logger.Warn(ctx, "marshal workspace owner name")
+	}
+	aReq, commitAudit := audit.InitRequest[database.Workspace](rw, &audit.RequestParams{
+		Audit:            *auditor,
+		Log:              api.Logger,
+		Request:          r,
+		Action:           database.AuditActionCreate,
+		AdditionalFields: wriBytes,
+	})
+	defer commitAudit()
+
+	organization, err := api.Database.GetOrganizationByID(ctx, source.OrganizationID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching organization.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	template, err := api.Database.GetTemplateByID(ctx, source.TemplateID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	sourceBuild, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, source.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching the latest workspace build.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	templateVersionID := template.ActiveVersionID
+	if req.PinTemplateVersion {
+		templateVersionID = sourceBuild.TemplateVersionID
+	}
+	templateVersionParameters, err := api.Database.GetTemplateVersionParameters(ctx, templateVersionID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version parameters.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	sourceParameters, err := api.Database.GetWorkspaceBuildParameters(ctx, sourceBuild.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace build parameters.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	sourceLegacyParameters, err := api.Database.ParameterValues(ctx, database.ParameterValuesParams{
+		Scopes:   []database.ParameterScope{database.ParameterScopeWorkspace},
+		ScopeIds: []uuid.UUID{source.ID},
+	})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching legacy parameters.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	// Values the template version no longer defines are dropped, the
+	// overrides are validated when the workspace is created.
+	defined := make(map[string]bool, len(templateVersionParameters))
+	for _, parameter := range templateVersionParameters {
+		defined[parameter.Name] = true
+	}
+	richParameterValues := append([]codersdk.WorkspaceBuildParameter{}, req.RichParameterValues...)
+	for _, parameter := range sourceParameters {
+		if !defined[parameter.Name] {
+			continue
+		}
+		if _, found := findWorkspaceBuildParameter(req.RichParameterValues, parameter.Name); found {
+			continue
+		}
+		richParameterValues = append(richParameterValues, codersdk.WorkspaceBuildParameter{
+			Name:  parameter.Name,
+			Value: parameter.Value,
+		})
+	}
+	parameterValues := append([]codersdk.CreateParameterRequest{}, req.ParameterValues...)
+	for _, parameter := range sourceLegacyParameters {
+		if hasParameterRequest(req.ParameterValues, parameter.Name) {
+			continue
+		}
+		parameterValues = append(parameterValues, codersdk.CreateParameterRequest{
+			Name:              parameter.Name,
+			SourceValue:       parameter.SourceValue,
+			SourceScheme:      codersdk.ParameterSourceScheme(parameter.SourceScheme),
+			DestinationScheme: codersdk.ParameterDestinationScheme(parameter.DestinationScheme),
+		})
+	}
+
+	labels := make(map[string]string, len(source.Labels)+len(req.Labels))
+	for key, value := range source.Labels {
+		labels[key] = value
+	}
+	for key, value := range req.Labels {
+		labels[key] = value
+	}
+
+	createWorkspace := codersdk.CreateWorkspaceRequest{
+		TemplateID:          template.ID,
+		Name:                req.Name,
+		TTLMillis:           convertWorkspaceTTLMillis(source.Ttl),
+		ParameterValues:     parameterValues,
+		RichParameterValues: richParameterValues,
+		Labels:              labels,
+	}
+	if req.PinTemplateVersion {
+		createWorkspace.TemplateVersionID = sourceBuild.TemplateVersionID
+	}
+	if source.AutostartSchedule.Valid {
+		createWorkspace.AutostartSchedule = &source.AutostartSchedule.String
+	}
+
+	api.createWorkspace(rw, r, aReq, organization, user, createWorkspace)
+}
+
+func hasParameterRequest(parameters []codersdk.CreateParameterRequest, name string) bool {
+	for _, parameter := range parameters {
+		if parameter.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,215 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWorkspaceClone(t *testing.T) {
+	t.Parallel()
+
+	richParameters := &echo.Responses{
+		Parse: echo.ParseComplete,
+		ProvisionPlan: []*proto.Provision_Response{{
+			Type: &proto.Provision_Response_Complete{
+				Complete: &proto.Provision_Complete{
+					Parameters: []*proto.RichParameter{
+						{Name: "region", Type: "string"},
+						{Name: "size", Type: "number", ValidationMin: 1, ValidationMax: 3},
+					},
+				},
+			},
+		}},
+		ProvisionApply: echo.ProvisionComplete,
+	}
+
+	t.Run("RichParameters", func(t *testing.T) {
+		t.Parallel()
+
+		auditor := audit.NewMock()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true, Auditor: auditor})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, richParameters)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		source := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.RichParameterValues = []codersdk.WorkspaceBuildParameter{
+				{Name: "region", Value: "eu"},
+				{Name: "size", Value: "1"},
+			}
+			cwr.Labels = map[string]string{"project": "coder", "ticket": "eng-1"}
+		})
+		coderdtest.AwaitWorkspaceBuildJob(t, client, source.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		numLogs := len(auditor.AuditLogs)
+		clone, err := client.CloneWorkspace(ctx, source.ID, codersdk.CloneWorkspaceRequest{
+			Name: "clone",
+			RichParameterValues: []codersdk.WorkspaceBuildParameter{
+				{Name: "size", Value: "3"},
+			},
+			Labels: map[string]string{"ticket": "eng-2"},
+		})
+		require.NoError(t, err)
+		require.NotEqual(t, source.ID, clone.ID)
+		require.Equal(t, map[string]string{"project": "coder", "ticket": "eng-2"}, clone.Labels)
+		require.Equal(t, "clone", clone.Name)
+		require.Equal(t, template.ID, clone.TemplateID)
+		require.GreaterOrEqual(t, len(auditor.AuditLogs), numLogs+1)
+		assert.Equal(t, database.AuditActionCreate, auditor.AuditLogs[numLogs].Action)
+		assert.Equal(t, clone.ID, auditor.AuditLogs[numLogs].ResourceID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, clone.LatestBuild.ID)
+
+		parameters, err := client.WorkspaceBuildParameters(ctx, clone.LatestBuild.ID)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []codersdk.WorkspaceBuildParameter{
+			{Name: "region", Value: "eu"},
+			{Name: "size", Value: "3"},
+		}, parameters)
+
+		// Overrides are validated like on create.
+		_, err = client.CloneWorkspace(ctx, source.ID, codersdk.CloneWorkspaceRequest{
+			Name: "invalid",
+			RichParameterValues: []codersdk.WorkspaceBuildParameter{
+				{Name: "size", Value: "4"},
+			},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("LegacyParameters", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		source := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.ParameterValues = []codersdk.CreateParameterRequest{{
+				Name:              "region",
+				SourceValue:       "eu",
+				SourceScheme:      codersdk.ParameterSourceSchemeData,
+				DestinationScheme: codersdk.ParameterDestinationSchemeProvisionerVariable,
+			}}
+		})
+		coderdtest.AwaitWorkspaceBuildJob(t, client, source.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		clone, err := client.CloneWorkspace(ctx, source.ID, codersdk.CloneWorkspaceRequest{
+			Name: "clone",
+		})
+		require.NoError(t, err)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, clone.LatestBuild.ID)
+
+		parameters, err := client.Parameters(ctx, codersdk.ParameterWorkspace, clone.ID)
+		require.NoError(t, err)
+		require.Len(t, parameters, 1)
+		require.Equal(t, "region", parameters[0].Name)
+		require.Equal(t, codersdk.ParameterDestinationSchemeProvisionerVariable, parameters[0].DestinationScheme)
+	})
+
+	t.Run("PinTemplateVersion", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		source := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, source.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		newVersion := coderdtest.UpdateTemplateVersion(t, client, user.OrganizationID, nil, template.ID)
+		coderdtest.AwaitTemplateVersionJob(t, client, newVersion.ID)
+		err := client.UpdateActiveTemplateVersion(ctx, template.ID, codersdk.UpdateActiveTemplateVersion{
+			ID: newVersion.ID,
+		})
+		require.NoError(t, err)
+
+		clone, err := client.CloneWorkspace(ctx, source.ID, codersdk.CloneWorkspaceRequest{
+			Name: "active",
+		})
+		require.NoError(t, err)
+		require.Equal(t, newVersion.ID, clone.LatestBuild.TemplateVersionID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, clone.LatestBuild.ID)
+
+		clone, err = client.CloneWorkspace(ctx, source.ID, codersdk.CloneWorkspaceRequest{
+			Name:               "pinned",
+			PinTemplateVersion: true,
+		})
+		require.NoError(t, err)
+		require.Equal(t, version.ID, clone.LatestBuild.TemplateVersionID)
+		require.True(t, clone.Outdated)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, clone.LatestBuild.ID)
+	})
+
+	t.Run("NameConflict", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		source := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, source.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.CloneWorkspace(ctx, source.ID, codersdk.CloneWorkspaceRequest{
+			Name: source.Name,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		member, memberUser := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		source := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, source.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := member.CloneWorkspace(ctx, source.ID, codersdk.CloneWorkspaceRequest{
+			Name: "clone",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+
+		// Users the workspace is shared with may clone it for themselves.
+		err = client.UpdateWorkspaceACL(ctx, source.ID, codersdk.UpdateWorkspaceACL{
+			UserPerms: map[string]codersdk.WorkspaceRole{
+				memberUser.ID.String(): codersdk.WorkspaceRoleUse,
+			},
+		})
+		require.NoError(t, err)
+		clone, err := member.CloneWorkspace(ctx, source.ID, codersdk.CloneWorkspaceRequest{
+			Name: "clone",
+		})
+		require.NoError(t, err)
+		require.Equal(t, memberUser.ID, clone.OwnerID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, clone.LatestBuild.ID)
+	})
+}
@@ -0,0 +1,223 @@
+package coderd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/tailnet"
+)
+
+// appConnectionAuditInterval is how long requests to the same app from the
+// same user and address are considered to be one connection. Every proxied
+// request would be audited otherwise.
+const appConnectionAuditInterval = time.Hour
+
+// auditWorkspaceConnection audits a connection to a workspace agent when it's
+// invoked, and returns a function that audits the disconnect, including how
+// long the connection lasted.
+func (api *API) auditWorkspaceConnection(r *http.Request, workspace database.Workspace, agent database.WorkspaceAgent, connectionType codersdk.ConnectionType, detail string) func() {
+	ctx := r.Context()
+
+	var userID uuid.UUID
+	if key, ok := httpmw.APIKeyOptional(r); ok {
+		userID = key.UserID
+	}
+	params := &audit.ConnectionAuditParams{
+		Audit:        *api.Auditor.Load(),
+		Log:          api.Logger,
+		Action:       database.AuditActionConnect,
+		ConnectionID: uuid.New(),
+		UserID:       userID,
+		Workspace:    workspace,
+		RemoteAddr:   r.RemoteAddr,
+		UserAgent:    r.UserAgent(),
+		Fields:       api.connectionAuditFields(ctx, workspace, agent, connectionType, detail),
+	}
+	audit.ConnectionAudit(ctx, params)
+
+	start := time.Now()
+	return func() {
+		params.Action = database.AuditActionDisconnect
+		params.Fields.DurationMS = time.Since(start).Milliseconds()
+		// The request context is usually canceled once the connection is
+		// closed.
+		audit.ConnectionAudit(context.Background(), params)
+	}
+}
+
+func (api *API) connectionAuditFields(ctx context.Context, workspace database.Workspace, agent database.WorkspaceAgent, connectionType codersdk.ConnectionType, detail string) codersdk.ConnectionAuditFields {
+	fields := codersdk.ConnectionAuditFields{
+		WorkspaceName:  workspace.Name,
+		AgentID:        agent.ID,
+		AgentName:      agent.Name,
+		ConnectionType: connectionType,
+		Detail:         detail,
+	}
+	// The user connecting isn't necessarily allowed to read the owner.
+	// nolint:gocritic
+	owner, err := api.Database.GetUserByID(dbauthz.AsSystemRestricted(ctx), workspace.OwnerID)
+	if err != nil {
+		api.Logger.Warn(ctx, "get workspace owner for connection audit log", slog.Error(err))
+	} else {
+		fields.WorkspaceOwner = owner.Username
+	}
+	return fields
+}
+
+// auditWorkspaceAppRequest audits a request proxied to a workspace app.
+// WebSocket and other upgraded requests are audited as a connect and a
+// disconnect, since they last as long as the returned function isn't called.
+// Other requests from the same user and address to the same app are audited
+// as one connect, until there are no requests for appConnectionAuditInterval.
+func (api *API) auditWorkspaceAppRequest(r *http.Request, proxyApp proxyApplication) func() {
+	detail := strconv.FormatUint(uint64(proxyApp.Port), 10)
+	if proxyApp.App != nil {
+		detail = proxyApp.App.Slug
+	}
+	if r.Header.Get("Upgrade") != "" {
+		return api.auditWorkspaceConnection(r, proxyApp.Workspace, proxyApp.Agent, codersdk.ConnectionTypeWorkspaceApp, detail)
+	}
+
+	var userID uuid.UUID
+	if key, ok := httpmw.APIKeyOptional(r); ok {
+		userID = key.UserID
+	}
+	key := fmt.Sprintf("%s/%s/%s/%s", userID, proxyApp.Agent.ID, detail, r.RemoteAddr)
+	if api.appConnections.seen(key, time.Now()) {
+		return func() {}
+	}
+	_ = api.auditWorkspaceConnection(r, proxyApp.Workspace, proxyApp.Agent, codersdk.ConnectionTypeWorkspaceApp, detail)
+	return func() {}
+}
+
+// appConnectionTracker remembers the app requests that were recently audited.
+type appConnectionTracker struct {
+	mu        sync.Mutex
+	lastSeen  map[string]time.Time
+	lastSweep time.Time
+}
+
+func newAppConnectionTracker() *appConnectionTracker {
+	return &appConnectionTracker{
+		lastSeen: map[string]time.Time{},
+	}
+}
+
+// seen returns whether the key was seen within appConnectionAuditInterval of
+// now, and marks it as seen at now.
+func (t *appConnectionTracker) seen(key string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if now.Sub(t.lastSweep) > appConnectionAuditInterval {
+		for k, last := range t.lastSeen {
+			if now.Sub(last) > appConnectionAuditInterval {
+				delete(t.lastSeen, k)
+			}
+		}
+		t.lastSweep = now
+	}
+
+	last, ok := t.lastSeen[key]
+	t.lastSeen[key] = now
+	return ok && now.Sub(last) <= appConnectionAuditInterval
+}
+
+// tailnetClientLinger is how long the connections reported by agents are
+// attributed to a client after it stopped coordinating, since the agent
+// reports the disconnect after the client is gone.
+const tailnetClientLinger = time.Minute
+
+// tailnetClient is a user coordinating a tailnet connection to an agent.
+type tailnetClient struct {
+	agentID    uuid.UUID
+	userID     uuid.UUID
+	remoteAddr string
+	userAgent  string
+	closedAt   time.Time
+}
+
+// tailnetClientTracker remembers the users coordinating connections to agents
+// through this replica, so the connections agents report can be attributed to
+// the user they come from. Agents only know the tailnet address of the peer.
+type tailnetClientTracker struct {
+	mu      sync.Mutex
+	clients map[uuid.UUID]*tailnetClient
+	// connections are the clients the connections reported by agents were
+	// attributed to, by connection ID.
+	connections map[uuid.UUID]*tailnetClient
+}
+
+func newTailnetClientTracker() *tailnetClientTracker {
+	return &tailnetClientTracker{
+		clients:     map[uuid.UUID]*tailnetClient{},
+		connections: map[uuid.UUID]*tailnetClient{},
+	}
+}
+
+// add tracks a client until the returned function is called.
+func (t *tailnetClientTracker) add(id uuid.UUID, client *tailnetClient) func() {
+	t.mu.Lock()
+	t.clients[id] = client
+	t.mu.Unlock()
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		now := time.Now()
+		delete(t.clients, id)
+		client.closedAt = now
+		for connectionID, c := range t.connections {
+			if !c.closedAt.IsZero() && now.Sub(c.closedAt) > tailnetClientLinger {
+				delete(t.connections, connectionID)
+			}
+		}
+	}
+}
+
+// match returns the client a connection reported by an agent comes from. The
+// client is found by the tailnet address of the peer, which is in the node the
+// client sent to the coordinator. A disconnect is attributed to the client the
+// connect was.
+func (t *tailnetClientTracker) match(coordinator tailnet.Coordinator, agentID, connectionID uuid.UUID, action database.AuditAction, addr netip.Addr) (tailnetClient, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if client, ok := t.connections[connectionID]; ok {
+		if action == database.AuditActionDisconnect {
+			delete(t.connections, connectionID)
+		}
+		return *client, true
+	}
+	for id, client := range t.clients {
+		if client.agentID != agentID {
+			continue
+		}
+		node := coordinator.Node(id)
+		if node == nil {
+			continue
+		}
+		for _, prefix := range node.Addresses {
+			if prefix.Addr() != addr {
+				continue
+			}
+			if action == database.AuditActionConnect {
+				t.connections[connectionID] = client
+			}
+			return *client, true
+		}
+	}
+	return tailnetClient{}, false
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/maps"
 	"golang.org/x/xerrors"
 
 	"cdr.dev/slog"
@@ -107,6 +108,7 @@ func (api *API) workspace(rw http.ResponseWriter, r *http.Request) {
 // @Security CoderSessionToken
 // @Produce json
 // @Tags Workspaces
+// @Param q query string false "Search query of key:value terms, terms can be negated with - and grouped with OR"
 // @Param owner query string false "Filter by owner username"
 // @Param template query string false "Filter by template name"
 // @Param name query string false "Filter with partial-match by workspace name"
@@ -124,7 +126,7 @@ func (api *API) workspaces(rw http.ResponseWriter, r *http.Request) {
 	}
 
 	queryStr := r.URL.Query().Get("q")
-	filter, errs := searchquery.Workspaces(queryStr, page, api.AgentInactiveDisconnectTimeout)
+	filters, errs := searchquery.Workspaces(queryStr, page, api.AgentInactiveDisconnectTimeout)
 	if len(errs) > 0 {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 			Message:     "Invalid workspace search query.",
@@ -133,12 +135,14 @@ func (api *API) workspaces(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if filter.OwnerUsername == "me" {
-		filter.OwnerID = apiKey.UserID
-		filter.OwnerUsername = ""
+	for i := range filters {
+		if filters[i].OwnerUsername == "me" {
+			filters[i].OwnerID = apiKey.UserID
+			filters[i].OwnerUsername = ""
+		}
 	}
 
-	// Workspaces do not have ACL columns.
+	// Workspaces shared with the user are included through the ACL columns.
 	prepared, err := api.HTTPAuth.AuthorizeSQLFilter(r, rbac.ActionRead, rbac.ResourceWorkspace.Type)
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -148,7 +152,12 @@ func (api *API) workspaces(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	workspaceRows, err := api.Database.GetAuthorizedWorkspaces(ctx, filter, prepared)
+	var workspaceRows []database.GetWorkspacesRow
+	if len(filters) == 1 {
+		workspaceRows, err = api.Database.GetAuthorizedWorkspaces(ctx, filters[0], prepared)
+	} else {
+		workspaceRows, err = api.Database.GetAuthorizedWorkspacesUnion(ctx, filters, prepared)
+	}
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
 			Message: "Internal error fetching workspaces.",
@@ -281,7 +290,6 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 	var (
 		ctx                   = r.Context()
 		organization          = httpmw.OrganizationParam(r)
-		apiKey                = httpmw.APIKey(r)
 		auditor               = api.Auditor.Load()
 		user                  = httpmw.UserParam(r)
 		workspaceResourceInfo = audit.AdditionalFields{
@@ -315,6 +323,36 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 		return
 	}
 
+	api.createWorkspace(rw, r, aReq, organization, user, createWorkspace)
+}
+
+// createWorkspace creates a workspace for the user and starts its first
+// build. The caller must have authorized creating the workspace.
+func (api *API) createWorkspace(
+	rw http.ResponseWriter,
+	r *http.Request,
+	aReq *audit.Request[database.Workspace],
+	organization database.Organization,
+	user database.User,
+	createWorkspace codersdk.CreateWorkspaceRequest,
+) {
+	var (
+		ctx    = r.Context()
+		apiKey = httpmw.APIKey(r)
+	)
+
+	priority, ok := requestedProvisionerJobPriority(createWorkspace.Priority)
+	if !ok {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Priority %q is invalid.", createWorkspace.Priority),
+			Validations: []codersdk.ValidationError{{
+				Field:  "priority",
+				Detail: "must be interactive, automatic or background",
+			}},
+		})
+		return
+	}
+
 	template, err := api.Database.GetTemplateByID(ctx, createWorkspace.TemplateID)
 	if errors.Is(err, sql.ErrNoRows) {
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
@@ -343,6 +381,13 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 		httpapi.ResourceNotFound(rw)
 		return
 	}
+	if template.Deprecated != "" {
+		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+			Message: fmt.Sprintf("Template %q has been deprecated, and cannot be used to create a new workspace.", template.Name),
+			Detail:  template.Deprecated,
+		})
+		return
+	}
 
 	if organization.ID != template.OrganizationID {
 		httpapi.Write(ctx, rw, http.StatusUnauthorized, codersdk.Response{
@@ -369,6 +414,14 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 		return
 	}
 
+	if err := httpapi.WorkspaceLabelsValid(createWorkspace.Labels); err != nil {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     "Invalid workspace labels.",
+			Validations: []codersdk.ValidationError{{Field: "labels", Detail: err.Error()}},
+		})
+		return
+	}
+
 	// TODO: This should be a system call as the actor might not be able to
 	// read other workspaces. Ideally we check the error on create and look for
 	// a postgres conflict error.
@@ -395,7 +448,21 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 		return
 	}
 
-	templateVersion, err := api.Database.GetTemplateVersionByID(ctx, template.ActiveVersionID)
+	templateVersionID := template.ActiveVersionID
+	if createWorkspace.TemplateVersionID != uuid.Nil {
+		templateVersionID = createWorkspace.TemplateVersionID
+	}
+	templateVersion, err := api.Database.GetTemplateVersionByID(ctx, templateVersionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Template version %q doesn't exist.", templateVersionID.String()),
+			Validations: []codersdk.ValidationError{{
+				Field:  "template_version_id",
+				Detail: "template version not found",
+			}},
+		})
+		return
+	}
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
 			Message: "Internal error fetching template version.",
@@ -403,6 +470,28 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 		})
 		return
 	}
+	// The active version may be shared with other templates, so only
+	// explicitly requested versions must belong to the template.
+	if templateVersionID != template.ActiveVersionID && templateVersion.TemplateID.UUID != template.ID {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Template version %q is not a version of template %q.", templateVersion.Name, template.Name),
+			Validations: []codersdk.ValidationError{{
+				Field:  "template_version_id",
+				Detail: "template version does not belong to the template",
+			}},
+		})
+		return
+	}
+	if templateVersion.Archived {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: fmt.Sprintf("Template version %q is archived, and cannot be used to create a new workspace.", templateVersion.Name),
+			Validations: []codersdk.ValidationError{{
+				Field:  "template_version_id",
+				Detail: "template version is archived",
+			}},
+		})
+		return
+	}
 
 	dbTemplateVersionParameters, err := api.Database.GetTemplateVersionParameters(ctx, templateVersion.ID)
 	if err != nil {
@@ -477,6 +566,7 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 			Name:              createWorkspace.Name,
 			AutostartSchedule: dbAutostartSchedule,
 			Ttl:               dbTTL,
+			Labels:            createWorkspace.Labels,
 		})
 		if err != nil {
 			return xerrors.Errorf("insert workspace: %w", err)
@@ -522,6 +612,7 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 			FileID:         templateVersionJob.FileID,
 			Input:          input,
 			Tags:           tags,
+			Priority:       priority,
 		})
 		if err != nil {
 			return xerrors.Errorf("insert provisioner job: %w", err)
@@ -567,6 +658,14 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 		return
 	}
 	aReq.New = workspace
+	api.publishWebhookEvent(ctx, codersdk.WebhookEventWorkspaceCreated, workspace.OrganizationID, map[string]string{
+		"workspace_id":   workspace.ID.String(),
+		"workspace_name": workspace.Name,
+		"owner_id":       workspace.OwnerID.String(),
+		"owner_name":     user.Username,
+		"template_id":    template.ID.String(),
+		"template_name":  template.Name,
+	})
 
 	initiator, err := api.Database.GetUserByID(ctx, workspaceBuild.InitiatorID)
 	if err != nil {
@@ -602,6 +701,15 @@ func (api *API) postWorkspacesByOrganization(rw http.ResponseWriter, r *http.Req
 		return
 	}
 
+	err = api.setProvisionerJobQueues(ctx, &apiBuild.Job)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job queue.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
 	httpapi.Write(ctx, rw, http.StatusCreated, convertWorkspace(
 		workspace,
 		apiBuild,
@@ -644,31 +752,52 @@ func (api *API) patchWorkspace(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Name == "" || req.Name == workspace.Name {
+	if req.Labels != nil {
+		if err := httpapi.WorkspaceLabelsValid(req.Labels); err != nil {
+			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+				Message:     "Invalid workspace labels.",
+				Validations: []codersdk.ValidationError{{Field: "labels", Detail: err.Error()}},
+			})
+			return
+		}
+	}
+
+	nameChanged := req.Name != "" && req.Name != workspace.Name
+	labelsChanged := req.Labels != nil && !maps.Equal(req.Labels, workspace.Labels)
+	if !nameChanged && !labelsChanged {
 		aReq.New = workspace
 		// Nothing changed, optionally this could be an error.
 		rw.WriteHeader(http.StatusNoContent)
 		return
 	}
 
-	// The reason we double check here is in case more fields can be
-	// patched in the future, it's enough if one changes.
-	name := workspace.Name
-	if req.Name != "" || req.Name != workspace.Name {
-		name = req.Name
-	}
-
-	newWorkspace, err := api.Database.UpdateWorkspace(ctx, database.UpdateWorkspaceParams{
-		ID:   workspace.ID,
-		Name: name,
-	})
+	newWorkspace := workspace
+	err := api.Database.InTx(func(s database.Store) error {
+		var err error
+		if nameChanged {
+			newWorkspace, err = s.UpdateWorkspace(ctx, database.UpdateWorkspaceParams{
+				ID:   workspace.ID,
+				Name: req.Name,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if labelsChanged {
+			newWorkspace, err = s.UpdateWorkspaceLabelsByID(ctx, database.UpdateWorkspaceLabelsByIDParams{
+				ID:     workspace.ID,
+				Labels: req.Labels,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil)
 	if err != nil {
-		// The query protects against updating deleted workspaces and
+		// The queries protect against updating deleted workspaces and
 		// the existence of the workspace is checked in the request,
 		// if we get ErrNoRows it means the workspace was deleted.
-		//
-		// We could do this check earlier but we'd need to start a
-		// transaction.
 		if errors.Is(err, sql.ErrNoRows) {
 			httpapi.Write(ctx, rw, http.StatusMethodNotAllowed, codersdk.Response{
 				Message: fmt.Sprintf("Workspace %q is deleted and cannot be updated.", workspace.Name),
@@ -1083,6 +1212,14 @@ func (api *API) workspaceData(ctx context.Context, workspaces []database.Workspa
 	if err != nil {
 		return workspaceData{}, xerrors.Errorf("convert workspace builds: %w", err)
 	}
+	jobs := make([]*codersdk.ProvisionerJob, 0, len(apiBuilds))
+	for i := range apiBuilds {
+		jobs = append(jobs, &apiBuilds[i].Job)
+	}
+	err = api.setProvisionerJobQueues(ctx, jobs...)
+	if err != nil {
+		return workspaceData{}, xerrors.Errorf("set provisioner job queues: %w", err)
+	}
 
 	return workspaceData{
 		templates: templates,
@@ -1150,6 +1287,11 @@ func convertWorkspace(
 		autostartSchedule = &workspace.AutostartSchedule.String
 	}
 
+	labels := map[string]string{}
+	for key, value := range workspace.Labels {
+		labels[key] = value
+	}
+
 	ttlMillis := convertWorkspaceTTLMillis(workspace.Ttl)
 	return codersdk.Workspace{
 		ID:                                   workspace.ID,
@@ -1163,11 +1305,13 @@ func convertWorkspace(
 		TemplateIcon:                         template.Icon,
 		TemplateDisplayName:                  template.DisplayName,
 		TemplateAllowUserCancelWorkspaceJobs: template.AllowUserCancelWorkspaceJobs,
+		TemplateDeprecationMessage:           template.Deprecated,
 		Outdated:                             workspaceBuild.TemplateVersionID.String() != template.ActiveVersionID.String(),
 		Name:                                 workspace.Name,
 		AutostartSchedule:                    autostartSchedule,
 		TTLMillis:                            ttlMillis,
 		LastUsedAt:                           workspace.LastUsedAt,
+		Labels:                               labels,
 	}
 }
 
@@ -0,0 +1,384 @@
+package coderd
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/provisionerdserver"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+)
+
+// @Summary Transfer workspace to another user
+// @Description The workspace is no longer shared with anyone after the
+// @Description transfer. A start build is queued so the template can apply
+// @Description the new owner.
+// @ID transfer-workspace-to-another-user
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Workspaces
+// @Param workspace path string true "Workspace ID" format(uuid)
+// @Param request body codersdk.TransferWorkspaceRequest true "Transfer workspace request"
+// @Success 200 {object} codersdk.Workspace
+// @Router /workspaces/{workspace}/transfer [post]
+func (api *API) postWorkspaceTransfer(rw http.ResponseWriter, r *http.Request) {
+	var (
+		ctx               = r.Context()
+		workspace         = httpmw.WorkspaceParam(r)
+		apiKey            = httpmw.APIKey(r)
+		auditor           = api.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.Workspace](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
+	aReq.Old = workspace
+
+	// Users the workspace is shared with may not give it away.
+	if !api.Authorize(r, rbac.ActionUpdate, workspace.RBACObjectNoACL()) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.TransferWorkspaceRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+
+	if workspace.Deleted {
+		httpapi.Write(ctx, rw, http.StatusMethodNotAllowed, codersdk.Response{
+			Message: fmt.Sprintf("Workspace %q is deleted and cannot be transferred.", workspace.Name),
+		})
+		return
+	}
+
+	newOwner, err := api.userByIDOrUsername(ctx, req.Owner)
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     fmt.Sprintf("User %q does not exist.", req.Owner),
+			Validations: []codersdk.ValidationError{{Field: "owner", Detail: "user not found"}},
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching user.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if newOwner.ID == workspace.OwnerID {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     fmt.Sprintf("Workspace %q is already owned by %q.", workspace.Name, newOwner.Username),
+			Validations: []codersdk.ValidationError{{Field: "owner", Detail: "must not be the current owner"}},
+		})
+		return
+	}
+	if newOwner.Status == database.UserStatusSuspended {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     fmt.Sprintf("User %q is suspended.", newOwner.Username),
+			Validations: []codersdk.ValidationError{{Field: "owner", Detail: "must not be suspended"}},
+		})
+		return
+	}
+
+	// The new owner receives the workspace as if it was created for them.
+	if !api.Authorize(r, rbac.ActionCreate,
+		rbac.ResourceWorkspace.InOrg(workspace.OrganizationID).WithOwner(newOwner.ID.String())) {
+		httpapi.Forbidden(rw)
+		return
+	}
+
+	_, err = api.Database.GetOrganizationMemberByUserID(ctx, database.GetOrganizationMemberByUserIDParams{
+		OrganizationID: workspace.OrganizationID,
+		UserID:         newOwner.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message:     fmt.Sprintf("User %q is not a member of the organization of the workspace.", newOwner.Username),
+			Validations: []codersdk.ValidationError{{Field: "owner", Detail: "must be a member of the organization"}},
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching organization member.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	priorBuild, err := api.Database.GetLatestWorkspaceBuildByWorkspaceID(ctx, workspace.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching the latest workspace build.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	priorJob, err := api.Database.GetProvisionerJobByID(ctx, priorBuild.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	if convertProvisionerJob(priorJob).Status.Active() {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: "A workspace build is already active.",
+		})
+		return
+	}
+
+	// The cost of the workspace is charged to the new owner from the next
+	// build, so it must fit in their allowance.
+	if api.QuotaCommitter.Load() != nil && priorBuild.DailyCost > 0 {
+		consumed, err := api.Database.GetQuotaConsumedForUser(ctx, newOwner.ID)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching quota consumption.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		budget, err := api.Database.GetQuotaAllowanceForUser(ctx, newOwner.ID)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error fetching quota allowance.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+		if consumed+int64(priorBuild.DailyCost) > budget {
+			remaining := budget - consumed
+			if remaining < 0 {
+				remaining = 0
+			}
+			httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+				Message: fmt.Sprintf("The workspace costs %d credits, %q has %d of %d credits left.",
+					priorBuild.DailyCost, newOwner.Username, remaining, budget),
+			})
+			return
+		}
+	}
+
+	name := req.Name
+	if name == "" {
+		name, err = api.availableWorkspaceName(ctx, newOwner.ID, workspace.Name)
+		if err != nil {
+			httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+				Message: "Internal error finding an available workspace name.",
+				Detail:  err.Error(),
+			})
+			return
+		}
+	}
+
+	templateVersion, err := api.Database.GetTemplateVersionByID(ctx, priorBuild.TemplateVersionID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template version.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	templateVersionJob, err := api.Database.GetProvisionerJobByID(ctx, templateVersion.JobID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner job.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	template, err := api.Database.GetTemplateByID(ctx, workspace.TemplateID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching template.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	priorParameters, err := api.Database.GetWorkspaceBuildParameters(ctx, priorBuild.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching prior workspace build parameters.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	var newWorkspace database.Workspace
+	err = api.Database.InTx(func(db database.Store) error {
+		now := database.Now()
+		newWorkspace, err = db.UpdateWorkspaceOwnerByID(ctx, database.UpdateWorkspaceOwnerByIDParams{
+			ID:        workspace.ID,
+			OwnerID:   newOwner.ID,
+			Name:      name,
+			UpdatedAt: now,
+		})
+		if err != nil {
+			return xerrors.Errorf("update workspace owner: %w", err)
+		}
+
+		// Running agents of the prior owner must not act on behalf of the
+		// new one. Their tokens are revoked now, and the start build replaces
+		// the agents, so the instances are given new tokens.
+		err = db.UpdateWorkspaceAgentAuthTokensByWorkspaceID(ctx, database.UpdateWorkspaceAgentAuthTokensByWorkspaceIDParams{
+			WorkspaceID: workspace.ID,
+			UpdatedAt:   now,
+		})
+		if err != nil {
+			return xerrors.Errorf("rotate workspace agent tokens: %w", err)
+		}
+
+		// Start the workspace, so owner data such as
+		// data.coder_workspace.me.owner is refreshed.
+		workspaceBuildID := uuid.New()
+		input, err := json.Marshal(provisionerdserver.WorkspaceProvisionJob{
+			WorkspaceBuildID:  workspaceBuildID,
+			RotateAgentTokens: true,
+		})
+		if err != nil {
+			return xerrors.Errorf("marshal provision job: %w", err)
+		}
+		provisionerJob, err := db.InsertProvisionerJob(ctx, database.InsertProvisionerJobParams{
+			ID:             uuid.New(),
+			CreatedAt:      now,
+			UpdatedAt:      now,
+			InitiatorID:    apiKey.UserID,
+			OrganizationID: template.OrganizationID,
+			Provisioner:    template.Provisioner,
+			Type:           database.ProvisionerJobTypeWorkspaceBuild,
+			StorageMethod:  templateVersionJob.StorageMethod,
+			FileID:         templateVersionJob.FileID,
+			Input:          input,
+			Tags:           provisionerdserver.MutateTags(newOwner.ID, templateVersionJob.Tags),
+			Priority:       database.ProvisionerJobPriorityInteractive,
+		})
+		if err != nil {
+			return xerrors.Errorf("insert provisioner job: %w", err)
+		}
+		_, err = db.InsertWorkspaceBuild(ctx, database.InsertWorkspaceBuildParams{
+			ID:                workspaceBuildID,
+			CreatedAt:         now,
+			UpdatedAt:         now,
+			WorkspaceID:       workspace.ID,
+			TemplateVersionID: priorBuild.TemplateVersionID,
+			BuildNumber:       priorBuild.BuildNumber + 1,
+			ProvisionerState:  priorBuild.ProvisionerState,
+			InitiatorID:       apiKey.UserID,
+			Transition:        database.WorkspaceTransitionStart,
+			JobID:             provisionerJob.ID,
+			Reason:            database.BuildReasonInitiator,
+		})
+		if err != nil {
+			return xerrors.Errorf("insert workspace build: %w", err)
+		}
+
+		names := make([]string, 0, len(priorParameters))
+		values := make([]string, 0, len(priorParameters))
+		for _, param := range priorParameters {
+			names = append(names, param.Name)
+			values = append(values, param.Value)
+		}
+		err = db.InsertWorkspaceBuildParameters(ctx, database.InsertWorkspaceBuildParametersParams{
+			WorkspaceBuildID: workspaceBuildID,
+			Name:             names,
+			Value:            values,
+		})
+		if err != nil {
+			return xerrors.Errorf("insert workspace build parameters: %w", err)
+		}
+		return nil
+	}, nil)
+	if database.IsUniqueViolation(err) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("Workspace %q already exists for %q.", name, newOwner.Username),
+			Validations: []codersdk.ValidationError{{
+				Field:  "name",
+				Detail: "This value is already in use and should be unique.",
+			}},
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error transferring workspace.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.New = newWorkspace
+
+	api.publishWorkspaceUpdate(ctx, workspace.ID)
+
+	data, err := api.workspaceData(ctx, []database.Workspace{newWorkspace})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching workspace resources.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, convertWorkspace(
+		newWorkspace,
+		data.builds[0],
+		data.templates[0],
+		findUser(newWorkspace.OwnerID, data.users),
+	))
+}
+
+// userByIDOrUsername returns sql.ErrNoRows if the user does not exist.
+func (api *API) userByIDOrUsername(ctx context.Context, query string) (database.User, error) {
+	if id, err := uuid.Parse(query); err == nil {
+		return api.Database.GetUserByID(ctx, id)
+	}
+	return api.Database.GetUserByEmailOrUsername(ctx, database.GetUserByEmailOrUsernameParams{
+		Username: query,
+	})
+}
+
+// availableWorkspaceName returns name, or name with the lowest numeric suffix
+// that the owner does not have a workspace with.
+func (api *API) availableWorkspaceName(ctx context.Context, ownerID uuid.UUID, name string) (string, error) {
+	const maxLength = 32
+	candidate := name
+	for i := 2; i < 100; i++ {
+		_, err := api.Database.GetWorkspaceByOwnerIDAndName(ctx, database.GetWorkspaceByOwnerIDAndNameParams{
+			OwnerID: ownerID,
+			Name:    candidate,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		suffix := fmt.Sprintf("-%d", i)
+		base := name
+		if len(base)+len(suffix) > maxLength {
+			base = base[:maxLength-len(suffix)]
+		}
+		candidate = strings.TrimRight(base, "-") + suffix
+	}
+	return "", xerrors.Errorf("no available name for workspace %q", name)
+}
@@ -0,0 +1,222 @@
+package coderd_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/codersdk/agentsdk"
+	"github.com/coder/coder/provisioner/echo"
+	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestWorkspaceTransfer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+
+		auditor := audit.NewMock()
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true, Auditor: auditor})
+		user := coderdtest.CreateFirstUser(t, client)
+		prevOwner, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		nextOwner, nextOwnerUser := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, prevOwner, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		numLogs := len(auditor.AuditLogs)
+		transferred, err := client.TransferWorkspace(ctx, workspace.ID, codersdk.TransferWorkspaceRequest{
+			Owner: nextOwnerUser.Username,
+		})
+		require.NoError(t, err)
+		require.Equal(t, nextOwnerUser.ID, transferred.OwnerID)
+		require.Equal(t, workspace.Name, transferred.Name)
+		require.Equal(t, workspace.LatestBuild.BuildNumber+1, transferred.LatestBuild.BuildNumber)
+		require.Equal(t, codersdk.WorkspaceTransitionStart, transferred.LatestBuild.Transition)
+		require.Equal(t, user.UserID, transferred.LatestBuild.InitiatorID)
+		require.GreaterOrEqual(t, len(auditor.AuditLogs), numLogs+1)
+		assert.Equal(t, database.AuditActionWrite, auditor.AuditLogs[numLogs].Action)
+		assert.Equal(t, workspace.ID, auditor.AuditLogs[numLogs].ResourceID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, transferred.LatestBuild.ID)
+
+		_, err = prevOwner.Workspace(ctx, workspace.ID)
+		require.Error(t, err)
+		_, err = nextOwner.Workspace(ctx, workspace.ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("NameConflict", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		nextOwner, nextOwnerUser := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		existing := coderdtest.CreateWorkspace(t, nextOwner, user.OrganizationID, template.ID, func(cwr *codersdk.CreateWorkspaceRequest) {
+			cwr.Name = workspace.Name
+		})
+		coderdtest.AwaitWorkspaceBuildJob(t, client, existing.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		transferred, err := client.TransferWorkspace(ctx, workspace.ID, codersdk.TransferWorkspaceRequest{
+			Owner: nextOwnerUser.ID.String(),
+		})
+		require.NoError(t, err)
+		require.Equal(t, workspace.Name+"-2", transferred.Name)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, transferred.LatestBuild.ID)
+
+		// An explicit name is not changed.
+		workspace = coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+		_, err = client.TransferWorkspace(ctx, workspace.ID, codersdk.TransferWorkspaceRequest{
+			Owner: nextOwnerUser.Username,
+			Name:  existing.Name,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	})
+
+	t.Run("AgentToken", func(t *testing.T) {
+		t.Parallel()
+
+		client, closer := coderdtest.NewWithProvisionerCloser(t, nil)
+		defer closer.Close()
+		user := coderdtest.CreateFirstUser(t, client)
+		_, nextOwnerUser := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		authToken := uuid.NewString()
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, &echo.Responses{
+			Parse:         echo.ParseComplete,
+			ProvisionPlan: echo.ProvisionComplete,
+			ProvisionApply: []*proto.Provision_Response{{
+				Type: &proto.Provision_Response_Complete{
+					Complete: &proto.Provision_Complete{
+						Resources: []*proto.Resource{{
+							Name: "example",
+							Type: "aws_instance",
+							Agents: []*proto.Agent{{
+								Id: uuid.NewString(),
+								Auth: &proto.Agent_Token{
+									Token: authToken,
+								},
+							}},
+						}},
+					},
+				},
+			}},
+		})
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		agentClient := agentsdk.New(client.URL)
+		agentClient.SetSessionToken(authToken)
+		_, err := agentClient.GitSSHKey(ctx)
+		require.NoError(t, err)
+
+		// Stop the provisioner so the start build stays pending.
+		require.NoError(t, closer.Close())
+		transferred, err := client.TransferWorkspace(ctx, workspace.ID, codersdk.TransferWorkspaceRequest{
+			Owner: nextOwnerUser.Username,
+		})
+		require.NoError(t, err)
+		require.Equal(t, codersdk.ProvisionerJobPending, transferred.LatestBuild.Job.Status)
+
+		_, err = agentClient.GitSSHKey(ctx)
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode())
+
+		// The workspace cannot be transferred again before the build is done.
+		_, err = client.TransferWorkspace(ctx, workspace.ID, codersdk.TransferWorkspaceRequest{
+			Owner: user.UserID.String(),
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		_, suspendedUser := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, client, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		ctx, _ := testutil.Context(t)
+		_, err := client.UpdateUserStatus(ctx, suspendedUser.Username, codersdk.UserStatusSuspended)
+		require.NoError(t, err)
+
+		for name, owner := range map[string]string{
+			"SameOwner": user.UserID.String(),
+			"Missing":   "nobody",
+			"Suspended": suspendedUser.Username,
+		} {
+			_, err := client.TransferWorkspace(ctx, workspace.ID, codersdk.TransferWorkspaceRequest{
+				Owner: owner,
+			})
+			var apiErr *codersdk.Error
+			require.ErrorAs(t, err, &apiErr, name)
+			require.Equal(t, http.StatusBadRequest, apiErr.StatusCode(), name)
+		}
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdtest.New(t, &coderdtest.Options{IncludeProvisionerDaemon: true})
+		user := coderdtest.CreateFirstUser(t, client)
+		owner, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		member, memberUser := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		version := coderdtest.CreateTemplateVersion(t, client, user.OrganizationID, nil)
+		coderdtest.AwaitTemplateVersionJob(t, client, version.ID)
+		template := coderdtest.CreateTemplate(t, client, user.OrganizationID, version.ID)
+		workspace := coderdtest.CreateWorkspace(t, owner, user.OrganizationID, template.ID)
+		coderdtest.AwaitWorkspaceBuildJob(t, client, workspace.LatestBuild.ID)
+
+		// Members may not create workspaces for other users, so they may not
+		// give theirs away either.
+		ctx, _ := testutil.Context(t)
+		_, err := owner.TransferWorkspace(ctx, workspace.ID, codersdk.TransferWorkspaceRequest{
+			Owner: memberUser.Username,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+
+		// Users the workspace is shared with may not take it.
+		err = owner.UpdateWorkspaceACL(ctx, workspace.ID, codersdk.UpdateWorkspaceACL{
+			UserPerms: map[string]codersdk.WorkspaceRole{
+				memberUser.ID.String(): codersdk.WorkspaceRoleAdmin,
+			},
+		})
+		require.NoError(t, err)
+		_, err = member.TransferWorkspace(ctx, workspace.ID, codersdk.TransferWorkspaceRequest{
+			Owner: memberUser.Username,
+		})
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+}
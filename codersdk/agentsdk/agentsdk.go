@@ -78,6 +78,9 @@ type Metadata struct {
 	StartupScriptTimeout time.Duration           `json:"startup_script_timeout"`
 	Directory            string                  `json:"directory"`
 	MOTDFile             string                  `json:"motd_file"`
+	// TemplateDeprecationMessage is shown after the MOTD when the template
+	// of the workspace is deprecated.
+	TemplateDeprecationMessage string `json:"template_deprecation_message,omitempty"`
 }
 
 // Metadata fetches metadata for the currently authenticated workspace agent.
@@ -397,7 +400,7 @@ func (c *Client) ReportStats(ctx context.Context, log slog.Logger, statsChan <-c
 	}
 
 	// Send an empty stat to get the interval.
-	postStat(&Stats{ConnectionsByProto: map[string]int64{}})
+	postStat(&Stats{ConnectionsByProto: map[string]int64{}, ConnectionsByPort: map[string]int64{}})
 
 	go func() {
 		defer close(exited)
@@ -428,6 +431,9 @@ func (c *Client) ReportStats(ctx context.Context, log slog.Logger, statsChan <-c
 type Stats struct {
 	// ConnectionsByProto is a count of connections by protocol.
 	ConnectionsByProto map[string]int64 `json:"conns_by_proto"`
+	// ConnectionsByPort is a count of connections by the agent port they
+	// were made to.
+	ConnectionsByPort map[string]int64 `json:"conns_by_port"`
 	// ConnectionCount is the number of connections received by an agent.
 	ConnectionCount int64 `json:"num_comms"`
 	// RxPackets is the number of received packets.
@@ -499,6 +505,35 @@ func (c *Client) PostStartup(ctx context.Context, req PostStartupRequest) error
 	return nil
 }
 
+// PostConnectionRequest reports the start or end of a connection to the
+// agent, so it can be audited.
+type PostConnectionRequest struct {
+	// ID identifies the connection. The disconnect of a connection must have
+	// the same ID as its connect.
+	ID         uuid.UUID               `json:"id" format:"uuid"`
+	Action     codersdk.AuditAction    `json:"action" enums:"connect,disconnect"`
+	Type       codersdk.ConnectionType `json:"type" enums:"ssh,port_forward"`
+	RemoteAddr string                  `json:"remote_addr"`
+	// Detail describes what was connected to, e.g. the address of a
+	// forwarded port.
+	Detail string `json:"detail,omitempty"`
+	// DurationMS is how long the connection lasted. It's only set on
+	// disconnects.
+	DurationMS int64 `json:"duration_ms,omitempty"`
+}
+
+func (c *Client) PostConnection(ctx context.Context, req PostConnectionRequest) error {
+	res, err := c.SDK.Request(ctx, http.MethodPost, "/api/v2/workspaceagents/me/report-connection", req)
+	if err != nil {
+		return xerrors.Errorf("agent connection post request: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusNoContent {
+		return codersdk.ReadBodyAsError(res)
+	}
+	return nil
+}
+
 type GitAuthResponse struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
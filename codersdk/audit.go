@@ -3,6 +3,7 @@ package codersdk
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/netip"
 	"strings"
@@ -14,15 +15,18 @@ import (
 type ResourceType string
 
 const (
-	ResourceTypeTemplate        ResourceType = "template"
-	ResourceTypeTemplateVersion ResourceType = "template_version"
-	ResourceTypeUser            ResourceType = "user"
-	ResourceTypeWorkspace       ResourceType = "workspace"
-	ResourceTypeWorkspaceBuild  ResourceType = "workspace_build"
-	ResourceTypeGitSSHKey       ResourceType = "git_ssh_key"
-	ResourceTypeAPIKey          ResourceType = "api_key"
-	ResourceTypeGroup           ResourceType = "group"
-	ResourceTypeLicense         ResourceType = "license"
+	ResourceTypeTemplate           ResourceType = "template"
+	ResourceTypeTemplateVersion    ResourceType = "template_version"
+	ResourceTypeUser               ResourceType = "user"
+	ResourceTypeWorkspace          ResourceType = "workspace"
+	ResourceTypeWorkspaceBuild     ResourceType = "workspace_build"
+	ResourceTypeGitSSHKey          ResourceType = "git_ssh_key"
+	ResourceTypeAPIKey             ResourceType = "api_key"
+	ResourceTypeGroup              ResourceType = "group"
+	ResourceTypeLicense            ResourceType = "license"
+	ResourceTypeOrganization       ResourceType = "organization"
+	ResourceTypeOrganizationMember ResourceType = "organization_member"
+	ResourceTypeAppearance         ResourceType = "appearance"
 )
 
 func (r ResourceType) FriendlyString() string {
@@ -47,6 +51,12 @@ func (r ResourceType) FriendlyString() string {
 		return "group"
 	case ResourceTypeLicense:
 		return "license"
+	case ResourceTypeOrganization:
+		return "organization"
+	case ResourceTypeOrganizationMember:
+		return "organization member"
+	case ResourceTypeAppearance:
+		return "appearance"
 	default:
 		return "unknown"
 	}
@@ -55,13 +65,15 @@ func (r ResourceType) FriendlyString() string {
 type AuditAction string
 
 const (
-	AuditActionCreate AuditAction = "create"
-	AuditActionWrite  AuditAction = "write"
-	AuditActionDelete AuditAction = "delete"
-	AuditActionStart  AuditAction = "start"
-	AuditActionStop   AuditAction = "stop"
-	AuditActionLogin  AuditAction = "login"
-	AuditActionLogout AuditAction = "logout"
+	AuditActionCreate     AuditAction = "create"
+	AuditActionWrite      AuditAction = "write"
+	AuditActionDelete     AuditAction = "delete"
+	AuditActionStart      AuditAction = "start"
+	AuditActionStop       AuditAction = "stop"
+	AuditActionLogin      AuditAction = "login"
+	AuditActionLogout     AuditAction = "logout"
+	AuditActionConnect    AuditAction = "connect"
+	AuditActionDisconnect AuditAction = "disconnect"
 )
 
 func (a AuditAction) Friendly() string {
@@ -80,11 +92,44 @@ func (a AuditAction) Friendly() string {
 		return "logged in"
 	case AuditActionLogout:
 		return "logged out"
+	case AuditActionConnect:
+		return "connected to"
+	case AuditActionDisconnect:
+		return "disconnected from"
 	default:
 		return "unknown"
 	}
 }
 
+// ConnectionType is the kind of workspace connection recorded by a connect or
+// disconnect audit log.
+type ConnectionType string
+
+const (
+	ConnectionTypeSSH             ConnectionType = "ssh"
+	ConnectionTypePortForward     ConnectionType = "port_forward"
+	ConnectionTypeReconnectingPTY ConnectionType = "reconnecting_pty"
+	ConnectionTypeWorkspaceApp    ConnectionType = "workspace_app"
+	ConnectionTypeTailnet         ConnectionType = "tailnet"
+)
+
+// ConnectionAuditFields are the additional fields of connect and disconnect
+// audit logs. The request ID of the audit log identifies the connection, so
+// a disconnect can be matched with its connect.
+type ConnectionAuditFields struct {
+	WorkspaceName  string         `json:"workspace_name"`
+	WorkspaceOwner string         `json:"workspace_owner"`
+	AgentID        uuid.UUID      `json:"agent_id" format:"uuid"`
+	AgentName      string         `json:"agent_name"`
+	ConnectionType ConnectionType `json:"connection_type"`
+	// Detail describes what was connected to, e.g. the slug of an app or the
+	// address of a forwarded port.
+	Detail string `json:"detail,omitempty"`
+	// DurationMS is how long the connection lasted. It's only set on
+	// disconnects.
+	DurationMS int64 `json:"duration_ms,omitempty"`
+}
+
 type AuditDiff map[string]AuditDiffField
 
 type AuditDiffField struct {
@@ -164,6 +209,49 @@ func (c *Client) AuditLogs(ctx context.Context, req AuditLogsRequest) (AuditLogR
 	return logRes, nil
 }
 
+// ExportAuditLogs returns the audit logs from since until before until as JSON
+// lines, in the order they happened. Audit logs that were archived after the
+// retention period are included. All audit logs until until are returned if
+// since is zero, and until is now if it's zero.
+func (c *Client) ExportAuditLogs(ctx context.Context, since, until time.Time) (io.ReadCloser, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/audit/export", nil, func(r *http.Request) {
+		q := r.URL.Query()
+		if !since.IsZero() {
+			q.Set("since", since.Format(time.RFC3339Nano))
+		}
+		if !until.IsZero() {
+			q.Set("until", until.Format(time.RFC3339Nano))
+		}
+		r.URL.RawQuery = q.Encode()
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, ReadBodyAsError(res)
+	}
+	return res.Body, nil
+}
+
+// AuditLogsCSV returns every audit log that matches the search query as CSV,
+// newest first.
+func (c *Client) AuditLogsCSV(ctx context.Context, searchQuery string) (io.ReadCloser, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/audit/csv", nil, func(r *http.Request) {
+		q := r.URL.Query()
+		q.Set("q", searchQuery)
+		r.URL.RawQuery = q.Encode()
+	})
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, ReadBodyAsError(res)
+	}
+	return res.Body, nil
+}
+
 // CreateTestAuditLog creates a fake audit log. Only owners of the organization
 // can perform this action. It's used for testing purposes.
 func (c *Client) CreateTestAuditLog(ctx context.Context, req CreateTestAuditLogRequest) error {
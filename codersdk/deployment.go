@@ -134,6 +134,8 @@ type DeploymentConfig struct {
 	AgentStatRefreshInterval        *DeploymentConfigField[time.Duration]   `json:"agent_stat_refresh_interval" typescript:",notnull"`
 	AgentFallbackTroubleshootingURL *DeploymentConfigField[string]          `json:"agent_fallback_troubleshooting_url" typescript:",notnull"`
 	AuditLogging                    *DeploymentConfigField[bool]            `json:"audit_logging" typescript:",notnull"`
+	AuditStreaming                  *AuditStreamingConfig                   `json:"audit_streaming" typescript:",notnull"`
+	AuditLogRetention               *AuditLogRetentionConfig                `json:"audit_log_retention" typescript:",notnull"`
 	BrowserOnly                     *DeploymentConfigField[bool]            `json:"browser_only" typescript:",notnull"`
 	SCIMAPIKey                      *DeploymentConfigField[string]          `json:"scim_api_key" typescript:",notnull"`
 	Provisioner                     *ProvisionerConfig                      `json:"provisioner" typescript:",notnull"`
@@ -148,6 +150,10 @@ type DeploymentConfig struct {
 	SessionDuration                 *DeploymentConfigField[time.Duration]   `json:"max_session_expiry" typescript:",notnull"`
 	DisableSessionExpiryRefresh     *DeploymentConfigField[bool]            `json:"disable_session_expiry_refresh" typescript:",notnull"`
 	DisablePasswordAuth             *DeploymentConfigField[bool]            `json:"disable_password_auth" typescript:",notnull"`
+	Email                           *EmailConfig                            `json:"email" typescript:",notnull"`
+	TemplateVersionGCInterval       *DeploymentConfigField[time.Duration]   `json:"template_version_gc_interval" typescript:",notnull"`
+	TemplateGitSyncInterval         *DeploymentConfigField[time.Duration]   `json:"template_git_sync_interval" typescript:",notnull"`
+	FileStorage                     *FileStorageConfig                      `json:"file_storage" typescript:",notnull"`
 
 	// DEPRECATED: Use HTTPAddress or TLS.Address instead.
 	Address *DeploymentConfigField[string] `json:"address" typescript:",notnull"`
@@ -256,6 +262,9 @@ type ProvisionerConfig struct {
 	DaemonPollInterval  *DeploymentConfigField[time.Duration] `json:"daemon_poll_interval" typescript:",notnull"`
 	DaemonPollJitter    *DeploymentConfigField[time.Duration] `json:"daemon_poll_jitter" typescript:",notnull"`
 	ForceCancelInterval *DeploymentConfigField[time.Duration] `json:"force_cancel_interval" typescript:",notnull"`
+	JobHangTimeout      *DeploymentConfigField[time.Duration] `json:"job_hang_timeout" typescript:",notnull"`
+	JobMaxRuntime       *DeploymentConfigField[time.Duration] `json:"job_max_runtime" typescript:",notnull"`
+	JobLogRetention     *DeploymentConfigField[time.Duration] `json:"job_log_retention" typescript:",notnull"`
 }
 
 type RateLimitConfig struct {
@@ -278,6 +287,44 @@ type DangerousConfig struct {
 	AllowPathAppSiteOwnerAccess *DeploymentConfigField[bool] `json:"allow_path_app_site_owner_access" typescript:",notnull"`
 }
 
+type EmailConfig struct {
+	From      *DeploymentConfigField[string] `json:"from" typescript:",notnull"`
+	Smarthost *DeploymentConfigField[string] `json:"smarthost" typescript:",notnull"`
+	Username  *DeploymentConfigField[string] `json:"username" typescript:",notnull"`
+	Password  *DeploymentConfigField[string] `json:"password" typescript:",notnull"`
+}
+
+type FileStorageConfig struct {
+	Backend           *DeploymentConfigField[string] `json:"backend" typescript:",notnull"`
+	Directory         *DeploymentConfigField[string] `json:"directory" typescript:",notnull"`
+	S3Endpoint        *DeploymentConfigField[string] `json:"s3_endpoint" typescript:",notnull"`
+	S3Region          *DeploymentConfigField[string] `json:"s3_region" typescript:",notnull"`
+	S3Bucket          *DeploymentConfigField[string] `json:"s3_bucket" typescript:",notnull"`
+	S3AccessKeyID     *DeploymentConfigField[string] `json:"s3_access_key_id" typescript:",notnull"`
+	S3SecretAccessKey *DeploymentConfigField[string] `json:"s3_secret_access_key" typescript:",notnull"`
+}
+
+type AuditStreamingConfig struct {
+	SyslogAddress        *DeploymentConfigField[string]        `json:"syslog_address" typescript:",notnull"`
+	SyslogFilter         *DeploymentConfigField[[]string]      `json:"syslog_filter" typescript:",notnull"`
+	WebhookURL           *DeploymentConfigField[string]        `json:"webhook_url" typescript:",notnull"`
+	WebhookSecret        *DeploymentConfigField[string]        `json:"webhook_secret" typescript:",notnull"`
+	WebhookBatchSize     *DeploymentConfigField[int]           `json:"webhook_batch_size" typescript:",notnull"`
+	WebhookFlushInterval *DeploymentConfigField[time.Duration] `json:"webhook_flush_interval" typescript:",notnull"`
+	WebhookFilter        *DeploymentConfigField[[]string]      `json:"webhook_filter" typescript:",notnull"`
+	FilePath             *DeploymentConfigField[string]        `json:"file_path" typescript:",notnull"`
+	FileMaxSize          *DeploymentConfigField[int]           `json:"file_max_size" typescript:",notnull"`
+	FileMaxBackups       *DeploymentConfigField[int]           `json:"file_max_backups" typescript:",notnull"`
+	FileFilter           *DeploymentConfigField[[]string]      `json:"file_filter" typescript:",notnull"`
+	BufferSize           *DeploymentConfigField[int]           `json:"buffer_size" typescript:",notnull"`
+}
+
+type AuditLogRetentionConfig struct {
+	Period    *DeploymentConfigField[time.Duration] `json:"period" typescript:",notnull"`
+	Archive   *DeploymentConfigField[bool]          `json:"archive" typescript:",notnull"`
+	BatchSize *DeploymentConfigField[int]           `json:"batch_size" typescript:",notnull"`
+}
+
 type SupportConfig struct {
 	Links *DeploymentConfigField[[]LinkConfig] `json:"links" typescript:",notnull"`
 }
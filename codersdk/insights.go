@@ -0,0 +1,208 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TemplateConnectionType is the way users connected to the workspaces of a
+// template.
+type TemplateConnectionType string
+
+const (
+	// TemplateConnectionTypeSSH includes VS Code Desktop and JetBrains
+	// Gateway, which connect over SSH.
+	TemplateConnectionTypeSSH         TemplateConnectionType = "ssh"
+	TemplateConnectionTypeWebTerminal TemplateConnectionType = "web_terminal"
+	TemplateConnectionTypeApp         TemplateConnectionType = "app"
+	TemplateConnectionTypePortForward TemplateConnectionType = "port_forward"
+)
+
+// TemplateInsightsRequest selects the days to return template insights for.
+// Insights are kept for the last 30 days.
+type TemplateInsightsRequest struct {
+	StartTime time.Time `json:"start_time" format:"date-time"`
+	EndTime   time.Time `json:"end_time" format:"date-time"`
+}
+
+// TemplateInsightsResponse contains usage and build statistics of a
+// template. They are aggregated in the background, and can be up to an hour
+// old.
+type TemplateInsightsResponse struct {
+	// StartTime and EndTime are the range the insights were computed over,
+	// after being rounded to whole days.
+	StartTime        time.Time                 `json:"start_time" format:"date-time"`
+	EndTime          time.Time                 `json:"end_time" format:"date-time"`
+	Builds           []TemplateBuildInsights   `json:"builds"`
+	ConnectionTypes  []TemplateConnectionUsage `json:"connection_types"`
+	Apps             []TemplateAppUsage        `json:"apps"`
+	ActiveWorkspaces []DAUEntry                `json:"active_workspaces"`
+	// VersionAdoption is the number of workspaces on each version of the
+	// template right now, regardless of the range.
+	VersionAdoption []TemplateVersionAdoption `json:"version_adoption"`
+}
+
+// TemplateBuildInsights contains the build times and failures of a
+// transition. Canceled builds aren't counted.
+type TemplateBuildInsights struct {
+	Transition WorkspaceTransition `json:"transition" enums:"start,stop,delete"`
+	// P50 and P95 are the build times of successful builds in milliseconds.
+	// They are null when there were no successful builds.
+	P50         *int64  `json:"p50" example:"123"`
+	P95         *int64  `json:"p95" example:"146"`
+	Total       int64   `json:"total"`
+	Failed      int64   `json:"failed"`
+	FailureRate float64 `json:"failure_rate"`
+}
+
+// TemplateConnectionUsage is the number of connections of a type, summed
+// over every stats report of the template's agents.
+type TemplateConnectionUsage struct {
+	Type        TemplateConnectionType `json:"type" enums:"ssh,web_terminal,app,port_forward"`
+	Connections int64                  `json:"connections"`
+}
+
+// TemplateAppUsage is the number of connections to an app, summed over every
+// stats report of the template's agents.
+type TemplateAppUsage struct {
+	Slug        string `json:"slug"`
+	DisplayName string `json:"display_name"`
+	Icon        string `json:"icon"`
+	Connections int64  `json:"connections"`
+}
+
+// TemplateVersionAdoption is the number of workspaces whose latest build
+// uses a template version.
+type TemplateVersionAdoption struct {
+	TemplateVersionID uuid.UUID `json:"template_version_id" format:"uuid"`
+	Name              string    `json:"name"`
+	Active            bool      `json:"active"`
+	Workspaces        int64     `json:"workspaces"`
+}
+
+// TemplateInsights returns usage and build statistics of a template.
+func (c *Client) TemplateInsights(ctx context.Context, templateID uuid.UUID, req TemplateInsightsRequest) (TemplateInsightsResponse, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/insights", templateID), nil,
+		func(r *http.Request) {
+			q := r.URL.Query()
+			if !req.StartTime.IsZero() {
+				q.Set("start_time", req.StartTime.Format(time.RFC3339))
+			}
+			if !req.EndTime.IsZero() {
+				q.Set("end_time", req.EndTime.Format(time.RFC3339))
+			}
+			r.URL.RawQuery = q.Encode()
+		},
+	)
+	if err != nil {
+		return TemplateInsightsResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateInsightsResponse{}, ReadBodyAsError(res)
+	}
+	var resp TemplateInsightsResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// UserInsightsRequest selects the range to report user activity for. Agent
+// stats are kept for 30 days, so connected time can't be reported further
+// back than that.
+type UserInsightsRequest struct {
+	StartTime time.Time `json:"start_time" format:"date-time"`
+	EndTime   time.Time `json:"end_time" format:"date-time"`
+}
+
+// UserInsightsResponse contains the activity of every user over a range.
+type UserInsightsResponse struct {
+	StartTime time.Time      `json:"start_time" format:"date-time"`
+	EndTime   time.Time      `json:"end_time" format:"date-time"`
+	Users     []UserInsights `json:"users"`
+}
+
+// UserInsights is the activity and usage of a user over a range.
+type UserInsights struct {
+	UserID   uuid.UUID  `json:"user_id" format:"uuid"`
+	Username string     `json:"username"`
+	Email    string     `json:"email"`
+	Status   UserStatus `json:"status" enums:"active,suspended"`
+	// WorkspacesOwned is the number of workspaces the user owns right now,
+	// regardless of the range.
+	WorkspacesOwned int64 `json:"workspaces_owned"`
+	// Builds is the number of workspace builds the user started, including
+	// those started on their behalf by autostart and autostop.
+	Builds int64 `json:"builds"`
+	// ConnectedSeconds is how long the user had connections open to any of
+	// their workspaces, at the granularity of the agent stats interval.
+	ConnectedSeconds int64 `json:"connected_seconds"`
+	// CostAccrued is the daily cost of the user's workspaces, prorated over
+	// the time they were running.
+	CostAccrued float64   `json:"cost_accrued"`
+	LastSeenAt  time.Time `json:"last_seen_at" format:"date-time"`
+}
+
+// WriteCSV writes the activity of every user as CSV, with a header row.
+// Connected time is written in hours.
+func (r UserInsightsResponse) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write([]string{
+		"username", "email", "status", "workspaces_owned", "builds",
+		"hours_connected", "cost_accrued", "last_seen_at",
+	})
+	if err != nil {
+		return err
+	}
+	for _, user := range r.Users {
+		err = cw.Write([]string{
+			user.Username,
+			user.Email,
+			string(user.Status),
+			strconv.FormatInt(user.WorkspacesOwned, 10),
+			strconv.FormatInt(user.Builds, 10),
+			strconv.FormatFloat(float64(user.ConnectedSeconds)/3600, 'f', 2, 64),
+			strconv.FormatFloat(user.CostAccrued, 'f', 2, 64),
+			user.LastSeenAt.Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// UserInsights returns the activity of every user.
+func (c *Client) UserInsights(ctx context.Context, req UserInsightsRequest) (UserInsightsResponse, error) {
+	res, err := c.Request(ctx, http.MethodGet, "/api/v2/insights/users", nil, req.asRequestOption())
+	if err != nil {
+		return UserInsightsResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return UserInsightsResponse{}, ReadBodyAsError(res)
+	}
+	var resp UserInsightsResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// asRequestOption returns a function that can be used in (*Client).Request.
+func (req UserInsightsRequest) asRequestOption() RequestOption {
+	return func(r *http.Request) {
+		q := r.URL.Query()
+		if !req.StartTime.IsZero() {
+			q.Set("start_time", req.StartTime.Format(time.RFC3339))
+		}
+		if !req.EndTime.IsZero() {
+			q.Set("end_time", req.EndTime.Format(time.RFC3339))
+		}
+		r.URL.RawQuery = q.Encode()
+	}
+}
@@ -0,0 +1,90 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// NotificationKind is a kind of email notification that users can opt out
+// of.
+type NotificationKind string
+
+const (
+	NotificationKindWorkspaceAutostop    NotificationKind = "workspace_autostop"
+	NotificationKindWorkspaceBuildFailed NotificationKind = "workspace_build_failed"
+	NotificationKindTemplateDeprecated   NotificationKind = "template_deprecated"
+	NotificationKindAccountCreated       NotificationKind = "account_created"
+	NotificationKindAccountSuspended     NotificationKind = "account_suspended"
+	NotificationKindAccountActivated     NotificationKind = "account_activated"
+)
+
+// NotificationKinds is every kind of notification.
+var NotificationKinds = []NotificationKind{
+	NotificationKindWorkspaceAutostop,
+	NotificationKindWorkspaceBuildFailed,
+	NotificationKindTemplateDeprecated,
+	NotificationKindAccountCreated,
+	NotificationKindAccountSuspended,
+	NotificationKindAccountActivated,
+}
+
+// Valid returns whether the kind is a known notification kind.
+func (k NotificationKind) Valid() bool {
+	for _, kind := range NotificationKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationPreference is whether a user receives a kind of notification.
+// Every kind is enabled until the user opts out.
+type NotificationPreference struct {
+	Kind      NotificationKind `json:"kind"`
+	Enabled   bool             `json:"enabled"`
+	UpdatedAt *time.Time       `json:"updated_at,omitempty" format:"date-time"`
+}
+
+type UpdateNotificationPreferencesRequest struct {
+	// Preferences only needs to contain the kinds that change.
+	Preferences []NotificationPreference `json:"preferences" validate:"required"`
+}
+
+// NotificationPreferences returns the notification preferences of the user.
+func (c *Client) NotificationPreferences(ctx context.Context, user string) ([]NotificationPreference, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/users/%s/notifications/preferences", user), nil)
+	if err != nil {
+		return nil, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+
+	var preferences []NotificationPreference
+	return preferences, json.NewDecoder(res.Body).Decode(&preferences)
+}
+
+// UpdateNotificationPreferences opts the user in or out of notifications and
+// returns all of their preferences.
+func (c *Client) UpdateNotificationPreferences(ctx context.Context, user string, req UpdateNotificationPreferencesRequest) ([]NotificationPreference, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/users/%s/notifications/preferences", user), req)
+	if err != nil {
+		return nil, xerrors.Errorf("execute request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+
+	var preferences []NotificationPreference
+	return preferences, json.NewDecoder(res.Body).Decode(&preferences)
+}
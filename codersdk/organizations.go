@@ -46,10 +46,14 @@ type CreateTemplateVersionRequest struct {
 	// TemplateID optionally associates a version with a template.
 	TemplateID      uuid.UUID                `json:"template_id,omitempty" format:"uuid"`
 	StorageMethod   ProvisionerStorageMethod `json:"storage_method" validate:"oneof=file,required" enums:"file"`
-	FileID          uuid.UUID                `json:"file_id,omitempty" validate:"required_without=ExampleID" format:"uuid"`
-	ExampleID       string                   `json:"example_id,omitempty" validate:"required_without=FileID"`
+	FileID          uuid.UUID                `json:"file_id,omitempty" validate:"required_without_all=ExampleID GitSource" format:"uuid"`
+	ExampleID       string                   `json:"example_id,omitempty" validate:"required_without_all=FileID GitSource"`
 	Provisioner     ProvisionerType          `json:"provisioner" validate:"oneof=terraform echo,required"`
 	ProvisionerTags map[string]string        `json:"tags"`
+	// GitSource creates the version from a git repository instead of a file.
+	// The repository is cloned by coderd, using the git auth of the user if
+	// it matches the URL.
+	GitSource *TemplateVersionGitSource `json:"git_source,omitempty"`
 
 	// ParameterValues allows for additional parameters to be provided
 	// during the dry-run provision stage.
@@ -96,7 +100,10 @@ type CreateTemplateRequest struct {
 
 // CreateWorkspaceRequest provides options for creating a new workspace.
 type CreateWorkspaceRequest struct {
-	TemplateID        uuid.UUID `json:"template_id" validate:"required" format:"uuid"`
+	TemplateID uuid.UUID `json:"template_id" validate:"required" format:"uuid"`
+	// TemplateVersionID creates the workspace with a version of the template
+	// other than the active one.
+	TemplateVersionID uuid.UUID `json:"template_version_id,omitempty" format:"uuid"`
 	Name              string    `json:"name" validate:"workspace_name,required"`
 	AutostartSchedule *string   `json:"autostart_schedule"`
 	TTLMillis         *int64    `json:"ttl_ms,omitempty"`
@@ -104,6 +111,12 @@ type CreateWorkspaceRequest struct {
 	// during the initial provision.
 	ParameterValues     []CreateParameterRequest  `json:"parameter_values,omitempty"`
 	RichParameterValues []WorkspaceBuildParameter `json:"rich_parameter_values,omitempty"`
+	// Labels are user defined key/value pairs to organize the workspace.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Priority of the initial build's provisioner job, which defaults to
+	// interactive. Scripts that create many workspaces should use background,
+	// so users don't wait behind them.
+	Priority ProvisionerJobPriority `json:"priority,omitempty" enums:"interactive,automatic,background"`
 }
 
 func (c *Client) Organization(ctx context.Context, id uuid.UUID) (Organization, error) {
@@ -121,10 +134,10 @@ func (c *Client) Organization(ctx context.Context, id uuid.UUID) (Organization,
 	return organization, json.NewDecoder(res.Body).Decode(&organization)
 }
 
-// ProvisionerDaemonsByOrganization returns provisioner daemons available for an organization.
-func (c *Client) ProvisionerDaemons(ctx context.Context) ([]ProvisionerDaemon, error) {
+// ProvisionerDaemons returns the provisioner daemons of an organization.
+func (c *Client) ProvisionerDaemons(ctx context.Context, organizationID uuid.UUID) ([]ProvisionerDaemon, error) {
 	res, err := c.Request(ctx, http.MethodGet,
-		"/api/v2/provisionerdaemons",
+		fmt.Sprintf("/api/v2/organizations/%s/provisionerdaemons", organizationID.String()),
 		nil,
 	)
 	if err != nil {
@@ -197,11 +210,33 @@ func (c *Client) CreateTemplate(ctx context.Context, organizationID uuid.UUID, r
 	return template, json.NewDecoder(res.Body).Decode(&template)
 }
 
+// TemplateFilter filters and paginates the templates of an organization.
+type TemplateFilter struct {
+	// SearchQuery supports a raw filter query string, e.g.
+	// "provisioner:terraform created_by:me".
+	SearchQuery string `json:"q,omitempty"`
+	Pagination
+}
+
 // TemplatesByOrganization lists all templates inside of an organization.
 func (c *Client) TemplatesByOrganization(ctx context.Context, organizationID uuid.UUID) ([]Template, error) {
+	return c.TemplatesByOrganizationWithFilter(ctx, organizationID, TemplateFilter{})
+}
+
+// TemplatesByOrganizationWithFilter lists the templates inside of an
+// organization that match the filter.
+func (c *Client) TemplatesByOrganizationWithFilter(ctx context.Context, organizationID uuid.UUID, filter TemplateFilter) ([]Template, error) {
 	res, err := c.Request(ctx, http.MethodGet,
 		fmt.Sprintf("/api/v2/organizations/%s/templates", organizationID.String()),
 		nil,
+		filter.Pagination.asRequestOption(),
+		func(r *http.Request) {
+			q := r.URL.Query()
+			if filter.SearchQuery != "" {
+				q.Set("q", filter.SearchQuery)
+			}
+			r.URL.RawQuery = q.Encode()
+		},
 	)
 	if err != nil {
 		return nil, xerrors.Errorf("execute request: %w", err)
@@ -18,6 +18,7 @@ import (
 	"golang.org/x/xerrors"
 	"nhooyr.io/websocket"
 
+	"github.com/coder/coder/buildinfo"
 	"github.com/coder/coder/provisionerd/proto"
 	"github.com/coder/coder/provisionersdk"
 )
@@ -37,6 +38,16 @@ const (
 	LogLevelError LogLevel = "error"
 )
 
+// ProvisionerDaemonStatus is whether a provisioner daemon is connected.
+type ProvisionerDaemonStatus string
+
+const (
+	ProvisionerDaemonOnline ProvisionerDaemonStatus = "online"
+	// ProvisionerDaemonOffline daemons haven't been seen recently. They may
+	// have been stopped, or lost their connection to Coder.
+	ProvisionerDaemonOffline ProvisionerDaemonStatus = "offline"
+)
+
 type ProvisionerDaemon struct {
 	ID           uuid.UUID         `json:"id" format:"uuid"`
 	CreatedAt    time.Time         `json:"created_at" format:"date-time"`
@@ -44,6 +55,13 @@ type ProvisionerDaemon struct {
 	Name         string            `json:"name"`
 	Provisioners []ProvisionerType `json:"provisioners"`
 	Tags         map[string]string `json:"tags"`
+	// LastSeenAt is when the daemon last polled for or updated a job.
+	LastSeenAt *time.Time              `json:"last_seen_at,omitempty" format:"date-time"`
+	Status     ProvisionerDaemonStatus `json:"status" enums:"online,offline"`
+	// Version is empty for daemons that don't report it.
+	Version string `json:"version"`
+	// CurrentJobID is the job the daemon is running, if any.
+	CurrentJobID *uuid.UUID `json:"current_job_id,omitempty" format:"uuid"`
 }
 
 // ProvisionerJobStatus represents the at-time state of a job.
@@ -67,6 +85,36 @@ const (
 	ProvisionerJobFailed    ProvisionerJobStatus = "failed"
 )
 
+// ProvisionerJobPriority is the order jobs are acquired in by provisioner
+// daemons.
+type ProvisionerJobPriority string
+
+const (
+	// ProvisionerJobPriorityInteractive jobs are started by users, who wait
+	// for them to complete.
+	ProvisionerJobPriorityInteractive ProvisionerJobPriority = "interactive"
+	// ProvisionerJobPriorityAutomatic jobs are started by Coder, such as
+	// autostart and autostop builds.
+	ProvisionerJobPriorityAutomatic ProvisionerJobPriority = "automatic"
+	// ProvisionerJobPriorityBackground jobs nobody waits for, such as bulk
+	// builds and template imports.
+	ProvisionerJobPriorityBackground ProvisionerJobPriority = "background"
+)
+
+// ProvisionerJobQueue is the position of a pending job in the queue of jobs
+// acquired by the same provisioner daemons.
+type ProvisionerJobQueue struct {
+	// Position starts at 1 for the next job to be acquired.
+	Position int `json:"position"`
+	Size     int `json:"size"`
+	// EstimatedWaitMillis is based on the duration of recent jobs in the
+	// queue. It's omitted if no jobs completed recently.
+	EstimatedWaitMillis *int64 `json:"estimated_wait_ms,omitempty"`
+	// Warning is set when no online provisioner daemon can acquire the job,
+	// e.g. because none of them have its tags.
+	Warning string `json:"warning,omitempty"`
+}
+
 // ProvisionerJob describes the job executed by the provisioning daemon.
 type ProvisionerJob struct {
 	ID          uuid.UUID            `json:"id" format:"uuid"`
@@ -79,6 +127,12 @@ type ProvisionerJob struct {
 	WorkerID    *uuid.UUID           `json:"worker_id,omitempty" format:"uuid"`
 	FileID      uuid.UUID            `json:"file_id" format:"uuid"`
 	Tags        map[string]string    `json:"tags"`
+	// Priority is the order the job is acquired in. Within a priority, jobs
+	// of the users and organizations with the fewest running jobs are
+	// acquired first.
+	Priority ProvisionerJobPriority `json:"priority" enums:"interactive,automatic,background"`
+	// Queue is only set while the job is pending.
+	Queue *ProvisionerJobQueue `json:"queue,omitempty"`
 }
 
 // ProvisionerJobLog represents the provisioner log entry annotated with source and level.
@@ -91,6 +145,85 @@ type ProvisionerJobLog struct {
 	Output    string    `json:"output"`
 }
 
+// Text formats the log as a line of plain text, without a trailing newline.
+func (l ProvisionerJobLog) Text() string {
+	prefix := fmt.Sprintf("%s [%s]", l.CreatedAt.UTC().Format("2006-01-02 15:04:05.000"), l.Level)
+	if l.Stage != "" {
+		prefix += " " + l.Stage + ":"
+	}
+	return prefix + " " + l.Output
+}
+
+// ProvisionerJobLogsFormat is the format provisioner job logs are returned in.
+type ProvisionerJobLogsFormat string
+
+const (
+	ProvisionerJobLogsFormatJSON ProvisionerJobLogsFormat = "json"
+	// ProvisionerJobLogsFormatText returns a line of text for every log.
+	ProvisionerJobLogsFormatText ProvisionerJobLogsFormat = "text"
+	// ProvisionerJobLogsFormatNDJSON returns a JSON object on a line for
+	// every log.
+	ProvisionerJobLogsFormatNDJSON ProvisionerJobLogsFormat = "ndjson"
+)
+
+// ProvisionerJobLogsFilter selects provisioner job logs. Empty fields match
+// every log.
+type ProvisionerJobLogsFilter struct {
+	// Level is the lowest level of the logs returned, e.g. warn returns
+	// warnings and errors.
+	Level LogLevel `json:"level,omitempty" enums:"trace,debug,info,warn,error"`
+	// Stage only returns the logs of the stage with this name.
+	Stage string `json:"stage,omitempty"`
+	// Search only returns the logs whose output contains this, ignoring case.
+	Search string `json:"search,omitempty"`
+}
+
+// asRequestOption returns a function that can be used in (*Client).Request.
+func (f ProvisionerJobLogsFilter) asRequestOption() RequestOption {
+	return func(r *http.Request) {
+		q := r.URL.Query()
+		if f.Level != "" {
+			q.Set("level", string(f.Level))
+		}
+		if f.Stage != "" {
+			q.Set("stage", f.Stage)
+		}
+		if f.Search != "" {
+			q.Set("search", f.Search)
+		}
+		r.URL.RawQuery = q.Encode()
+	}
+}
+
+// provisionerJobLogs returns the logs of a job that match the filter.
+func (c *Client) provisionerJobLogs(ctx context.Context, path string, filter ProvisionerJobLogsFilter) ([]ProvisionerJobLog, error) {
+	res, err := c.Request(ctx, http.MethodGet, path, nil, filter.asRequestOption())
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+
+	var logs []ProvisionerJobLog
+	return logs, json.NewDecoder(res.Body).Decode(&logs)
+}
+
+// downloadProvisionerJobLogs returns the logs of a job that match the filter
+// in the given format. The caller must close the returned reader.
+func (c *Client) downloadProvisionerJobLogs(ctx context.Context, path string, format ProvisionerJobLogsFormat, filter ProvisionerJobLogsFilter) (io.ReadCloser, error) {
+	res, err := c.Request(ctx, http.MethodGet, path, nil, filter.asRequestOption(), WithQueryParam("format", string(format)))
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, ReadBodyAsError(res)
+	}
+	return res.Body, nil
+}
+
 // provisionerJobLogsBefore provides log output that occurred before a time.
 // This is abstracted from a specific job type to provide consistency between
 // APIs. Logs is the only shared route between jobs.
@@ -171,35 +304,62 @@ func (c *Client) provisionerJobLogsAfter(ctx context.Context, path string, after
 	}), nil
 }
 
-// ListenProvisionerDaemon returns the gRPC service for a provisioner daemon
+// ServeProvisionerDaemonRequest is the request to serve a provisioner daemon.
+// @typescript-ignore ServeProvisionerDaemonRequest
+type ServeProvisionerDaemonRequest struct {
+	// Organization is the organization the daemon serves. It's ignored when
+	// the daemon authenticates with a provisioner key, which is bound to an
+	// organization.
+	Organization uuid.UUID
+	// Provisioners is the list of provisioner types the daemon serves.
+	Provisioners []ProvisionerType
+	// Tags are the tags the daemon requests. Daemons that authenticate with a
+	// provisioner key can only request tags the key has.
+	Tags map[string]string
+	// ProvisionerKey authenticates the daemon instead of the session token
+	// of the client when set.
+	ProvisionerKey string
+}
+
+// ServeProvisionerDaemon returns the gRPC service for a provisioner daemon
 // implementation. The context is during dial, not during the lifetime of the
 // client. Client should be closed after use.
-func (c *Client) ServeProvisionerDaemon(ctx context.Context, organization uuid.UUID, provisioners []ProvisionerType, tags map[string]string) (proto.DRPCProvisionerDaemonClient, error) {
-	serverURL, err := c.URL.Parse(fmt.Sprintf("/api/v2/organizations/%s/provisionerdaemons/serve", organization))
+func (c *Client) ServeProvisionerDaemon(ctx context.Context, req ServeProvisionerDaemonRequest) (proto.DRPCProvisionerDaemonClient, error) {
+	path := fmt.Sprintf("/api/v2/organizations/%s/provisionerdaemons/serve", req.Organization)
+	if req.ProvisionerKey != "" {
+		path = "/api/v2/provisionerdaemons/serve"
+	}
+	serverURL, err := c.URL.Parse(path)
 	if err != nil {
 		return nil, xerrors.Errorf("parse url: %w", err)
 	}
 	query := serverURL.Query()
-	for _, provisioner := range provisioners {
+	for _, provisioner := range req.Provisioners {
 		query.Add("provisioner", string(provisioner))
 	}
-	for key, value := range tags {
+	for key, value := range req.Tags {
 		query.Add("tag", fmt.Sprintf("%s=%s", key, value))
 	}
+	query.Set("version", buildinfo.Version())
 	serverURL.RawQuery = query.Encode()
-	jar, err := cookiejar.New(nil)
-	if err != nil {
-		return nil, xerrors.Errorf("create cookie jar: %w", err)
-	}
-	jar.SetCookies(serverURL, []*http.Cookie{{
-		Name:  SessionTokenCookie,
-		Value: c.SessionToken(),
-	}})
-	httpClient := &http.Client{
-		Jar: jar,
+	httpClient := &http.Client{}
+	headers := http.Header{}
+	if req.ProvisionerKey != "" {
+		headers.Set(ProvisionerKeyHeader, req.ProvisionerKey)
+	} else {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, xerrors.Errorf("create cookie jar: %w", err)
+		}
+		jar.SetCookies(serverURL, []*http.Cookie{{
+			Name:  SessionTokenCookie,
+			Value: c.SessionToken(),
+		}})
+		httpClient.Jar = jar
 	}
 	conn, res, err := websocket.Dial(ctx, serverURL.String(), &websocket.DialOptions{
 		HTTPClient: httpClient,
+		HTTPHeader: headers,
 		// Need to disable compression to avoid a data-race.
 		CompressionMode: websocket.CompressionDisabled,
 	})
@@ -0,0 +1,92 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// ProvisionerKeyHeader is the header external provisioner daemons send
+// their provisioner key in.
+const ProvisionerKeyHeader = "Coder-Provisioner-Key"
+
+// ProvisionerKey is a key external provisioner daemons authenticate with.
+// Daemons that use a key serve the organization of the key and are given the
+// tags of the key.
+type ProvisionerKey struct {
+	ID             uuid.UUID         `json:"id" format:"uuid"`
+	CreatedAt      time.Time         `json:"created_at" format:"date-time"`
+	OrganizationID uuid.UUID         `json:"organization_id" format:"uuid"`
+	Name           string            `json:"name"`
+	Tags           map[string]string `json:"tags"`
+}
+
+type CreateProvisionerKeyRequest struct {
+	Name string            `json:"name" validate:"required,username"`
+	Tags map[string]string `json:"tags"`
+}
+
+// CreateProvisionerKeyResponse contains the secret of a new provisioner key.
+// The secret is only returned once.
+type CreateProvisionerKeyResponse struct {
+	Key string `json:"key"`
+}
+
+// CreateProvisionerKey creates a provisioner key in the organization.
+func (c *Client) CreateProvisionerKey(ctx context.Context, organizationID uuid.UUID, req CreateProvisionerKeyRequest) (CreateProvisionerKeyResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost,
+		fmt.Sprintf("/api/v2/organizations/%s/provisionerkeys", organizationID.String()),
+		req,
+	)
+	if err != nil {
+		return CreateProvisionerKeyResponse{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return CreateProvisionerKeyResponse{}, ReadBodyAsError(res)
+	}
+	var resp CreateProvisionerKeyResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
+// ProvisionerKeys lists the provisioner keys of the organization.
+func (c *Client) ProvisionerKeys(ctx context.Context, organizationID uuid.UUID) ([]ProvisionerKey, error) {
+	res, err := c.Request(ctx, http.MethodGet,
+		fmt.Sprintf("/api/v2/organizations/%s/provisionerkeys", organizationID.String()),
+		nil,
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var keys []ProvisionerKey
+	return keys, json.NewDecoder(res.Body).Decode(&keys)
+}
+
+// DeleteProvisionerKey revokes the provisioner key with the name. Daemons that
+// are connected with the key are disconnected.
+func (c *Client) DeleteProvisionerKey(ctx context.Context, organizationID uuid.UUID, name string) error {
+	res, err := c.Request(ctx, http.MethodDelete,
+		fmt.Sprintf("/api/v2/organizations/%s/provisionerkeys/%s", organizationID.String(), name),
+		nil,
+	)
+	if err != nil {
+		return xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TemplateGitSyncStatus string
+
+const (
+	// TemplateGitSyncStatusPending means a version is being imported and
+	// dry-run for the last commit, or no commit has been synced yet.
+	TemplateGitSyncStatusPending TemplateGitSyncStatus = "pending"
+	// TemplateGitSyncStatusSucceeded means the version of the last commit was
+	// imported, passed a dry-run and was promoted to the active version.
+	TemplateGitSyncStatusSucceeded TemplateGitSyncStatus = "succeeded"
+	// TemplateGitSyncStatusFailed means the version of the last commit failed
+	// to import or its dry-run failed, so the active version wasn't changed.
+	TemplateGitSyncStatusFailed TemplateGitSyncStatus = "failed"
+)
+
+// TemplateGitSync keeps a template in sync with a git branch. A template
+// version is created for every new commit of the branch, and promoted to the
+// active version if it imports successfully and a dry-run with the default
+// parameters succeeds.
+type TemplateGitSync struct {
+	TemplateID uuid.UUID `json:"template_id" format:"uuid"`
+	// URL is the HTTP(S) URL of the repository.
+	URL string `json:"url"`
+	// Branch defaults to the default branch of the repository.
+	Branch string `json:"branch"`
+	// Directory is the path of the template in the repository. Defaults to
+	// the root of the repository.
+	Directory string `json:"directory"`
+	// UserID is the user that set up the sync. Versions are created with
+	// their permissions and git auth credentials.
+	UserID    uuid.UUID `json:"user_id" format:"uuid"`
+	UpdatedAt time.Time `json:"updated_at" format:"date-time"`
+	// CommitSHA is the last commit a version was created for.
+	CommitSHA         string                `json:"commit_sha"`
+	TemplateVersionID *uuid.UUID            `json:"template_version_id,omitempty" format:"uuid"`
+	Status            TemplateGitSyncStatus `json:"status" enums:"pending,succeeded,failed"`
+	// Error is the error of the last sync, empty if it succeeded.
+	Error     string     `json:"error"`
+	CheckedAt *time.Time `json:"checked_at,omitempty" format:"date-time"`
+	// WebhookURL triggers a sync when a push webhook of the repository is
+	// sent to it.
+	WebhookURL string `json:"webhook_url"`
+	// WebhookSecret verifies push webhooks. It's only returned when the sync
+	// is set up.
+	WebhookSecret string `json:"webhook_secret,omitempty"`
+}
+
+// UpdateTemplateGitSyncRequest sets up the git sync of a template.
+type UpdateTemplateGitSyncRequest struct {
+	URL       string `json:"url" validate:"required"`
+	Branch    string `json:"branch,omitempty"`
+	Directory string `json:"directory,omitempty"`
+}
+
+// TemplateGitSync returns the git sync of a template.
+func (c *Client) TemplateGitSync(ctx context.Context, templateID uuid.UUID) (TemplateGitSync, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/git-sync", templateID), nil)
+	if err != nil {
+		return TemplateGitSync{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateGitSync{}, ReadBodyAsError(res)
+	}
+	var gitSync TemplateGitSync
+	return gitSync, json.NewDecoder(res.Body).Decode(&gitSync)
+}
+
+// UpdateTemplateGitSync sets up the git sync of a template, or changes the
+// branch it's synced with.
+func (c *Client) UpdateTemplateGitSync(ctx context.Context, templateID uuid.UUID, req UpdateTemplateGitSyncRequest) (TemplateGitSync, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/templates/%s/git-sync", templateID), req)
+	if err != nil {
+		return TemplateGitSync{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return TemplateGitSync{}, ReadBodyAsError(res)
+	}
+	var gitSync TemplateGitSync
+	return gitSync, json.NewDecoder(res.Body).Decode(&gitSync)
+}
+
+// DeleteTemplateGitSync stops syncing a template with git.
+func (c *Client) DeleteTemplateGitSync(ctx context.Context, templateID uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/templates/%s/git-sync", templateID), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
@@ -32,6 +32,17 @@ type Template struct {
 	CreatedByName    string                 `json:"created_by_name"`
 
 	AllowUserCancelWorkspaceJobs bool `json:"allow_user_cancel_workspace_jobs"`
+	// Deprecated templates cannot be used to create new workspaces. The
+	// DeprecationMessage is shown to the owners of existing workspaces.
+	Deprecated         bool   `json:"deprecated"`
+	DeprecationMessage string `json:"deprecation_message"`
+	// GitSyncStatus is the status of the version created for the last commit
+	// of the git branch the template is synced with. It's empty if the
+	// template isn't synced with git.
+	GitSyncStatus TemplateGitSyncStatus `json:"git_sync_status,omitempty" enums:"pending,succeeded,failed"`
+	// GitSyncError is the error of the last sync with git, empty if it
+	// succeeded.
+	GitSyncError string `json:"git_sync_error,omitempty"`
 }
 
 type TransitionStats struct {
@@ -83,6 +94,15 @@ type UpdateTemplateMeta struct {
 	AllowUserCancelWorkspaceJobs bool   `json:"allow_user_cancel_workspace_jobs,omitempty"`
 }
 
+// UpdateTemplateDeprecation deprecates a template, or un-deprecates it if
+// Deprecated is false.
+type UpdateTemplateDeprecation struct {
+	Deprecated bool `json:"deprecated"`
+	// Message tells users why the template is deprecated and what to use
+	// instead. It is required to deprecate a template.
+	Message string `json:"message,omitempty"`
+}
+
 type TemplateExample struct {
 	ID          string   `json:"id" format:"uuid"`
 	URL         string   `json:"url"`
@@ -135,6 +155,20 @@ func (c *Client) UpdateTemplateMeta(ctx context.Context, templateID uuid.UUID, r
 	return updated, json.NewDecoder(res.Body).Decode(&updated)
 }
 
+// UpdateTemplateDeprecation deprecates or un-deprecates a template.
+func (c *Client) UpdateTemplateDeprecation(ctx context.Context, templateID uuid.UUID, req UpdateTemplateDeprecation) (Template, error) {
+	res, err := c.Request(ctx, http.MethodPut, fmt.Sprintf("/api/v2/templates/%s/deprecation", templateID), req)
+	if err != nil {
+		return Template{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return Template{}, ReadBodyAsError(res)
+	}
+	var updated Template
+	return updated, json.NewDecoder(res.Body).Decode(&updated)
+}
+
 func (c *Client) UpdateTemplateACL(ctx context.Context, templateID uuid.UUID, req UpdateTemplateACL) error {
 	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/templates/%s/acl", templateID), req)
 	if err != nil {
@@ -177,13 +211,24 @@ func (c *Client) UpdateActiveTemplateVersion(ctx context.Context, template uuid.
 // TemplateVersionsByTemplateRequest defines the request parameters for
 // TemplateVersionsByTemplate.
 type TemplateVersionsByTemplateRequest struct {
-	TemplateID uuid.UUID `json:"template_id" validate:"required" format:"uuid"`
+	TemplateID      uuid.UUID `json:"template_id" validate:"required" format:"uuid"`
+	IncludeArchived bool      `json:"include_archived"`
 	Pagination
 }
 
 // TemplateVersionsByTemplate lists versions associated with a template.
+// Archived versions are only listed if the request includes them.
 func (c *Client) TemplateVersionsByTemplate(ctx context.Context, req TemplateVersionsByTemplateRequest) ([]TemplateVersion, error) {
-	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/versions", req.TemplateID), nil, req.Pagination.asRequestOption())
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templates/%s/versions", req.TemplateID), nil,
+		req.Pagination.asRequestOption(),
+		func(r *http.Request) {
+			q := r.URL.Query()
+			if req.IncludeArchived {
+				q.Set("include_archived", "true")
+			}
+			r.URL.RawQuery = q.Encode()
+		},
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -195,6 +240,28 @@ func (c *Client) TemplateVersionsByTemplate(ctx context.Context, req TemplateVer
 	return templateVersion, json.NewDecoder(res.Body).Decode(&templateVersion)
 }
 
+// ArchiveTemplateVersionsResponse lists the template versions that were
+// archived.
+type ArchiveTemplateVersionsResponse struct {
+	TemplateID  uuid.UUID   `json:"template_id" format:"uuid"`
+	ArchivedIDs []uuid.UUID `json:"archived_ids"`
+}
+
+// ArchiveUnusedTemplateVersions archives every version of the template that
+// isn't the active version, and isn't used by the latest build of a workspace.
+func (c *Client) ArchiveUnusedTemplateVersions(ctx context.Context, templateID uuid.UUID) (ArchiveTemplateVersionsResponse, error) {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/templates/%s/versions/archive", templateID), nil)
+	if err != nil {
+		return ArchiveTemplateVersionsResponse{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return ArchiveTemplateVersionsResponse{}, ReadBodyAsError(res)
+	}
+	var resp ArchiveTemplateVersionsResponse
+	return resp, json.NewDecoder(res.Body).Decode(&resp)
+}
+
 // TemplateVersionByName returns a template version by it's friendly name.
 // This is used for path-based routing. Like: /templates/example/versions/helloworld
 func (c *Client) TemplateVersionByName(ctx context.Context, template uuid.UUID, name string) (TemplateVersion, error) {
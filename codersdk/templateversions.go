@@ -22,6 +22,23 @@ type TemplateVersion struct {
 	Job            ProvisionerJob `json:"job"`
 	Readme         string         `json:"readme"`
 	CreatedBy      User           `json:"created_by"`
+	Archived       bool           `json:"archived"`
+	// GitSource is set when the version was created from a git repository.
+	GitSource *TemplateVersionGitSource `json:"git_source,omitempty"`
+}
+
+// TemplateVersionGitSource is the git repository a template version is
+// created from.
+type TemplateVersionGitSource struct {
+	// URL is the HTTP(S) URL of the repository.
+	URL string `json:"url" validate:"required"`
+	// Ref is a branch, tag or commit SHA. Defaults to the default branch.
+	Ref string `json:"ref,omitempty"`
+	// Directory is the path of the template in the repository. Defaults to
+	// the root of the repository.
+	Directory string `json:"directory,omitempty"`
+	// CommitSHA is the commit the ref resolved to. It's set by the server.
+	CommitSHA string `json:"commit_sha,omitempty"`
 }
 
 type TemplateVersionGitAuth struct {
@@ -101,6 +118,33 @@ func (c *Client) CancelTemplateVersion(ctx context.Context, version uuid.UUID) e
 	return nil
 }
 
+// ArchiveTemplateVersion archives a template version so it can't be used for
+// new workspace builds. Versions that are in use can't be archived.
+func (c *Client) ArchiveTemplateVersion(ctx context.Context, version uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/templateversions/%s/archive", version), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// UnarchiveTemplateVersion makes an archived template version usable again.
+func (c *Client) UnarchiveTemplateVersion(ctx context.Context, version uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodPost, fmt.Sprintf("/api/v2/templateversions/%s/unarchive", version), nil)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
 // TemplateVersionParameters returns parameters a template version exposes.
 func (c *Client) TemplateVersionRichParameters(ctx context.Context, version uuid.UUID) ([]TemplateVersionParameter, error) {
 	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/templateversions/%s/rich-parameters", version), nil)
@@ -195,6 +239,19 @@ func (c *Client) TemplateVersionLogsAfter(ctx context.Context, version uuid.UUID
 	return c.provisionerJobLogsAfter(ctx, fmt.Sprintf("/api/v2/templateversions/%s/logs", version), after)
 }
 
+// TemplateVersionLogs returns the logs of a template version import that
+// match the filter.
+func (c *Client) TemplateVersionLogs(ctx context.Context, version uuid.UUID, filter ProvisionerJobLogsFilter) ([]ProvisionerJobLog, error) {
+	return c.provisionerJobLogs(ctx, fmt.Sprintf("/api/v2/templateversions/%s/logs", version), filter)
+}
+
+// DownloadTemplateVersionLogs returns the logs of a template version import
+// that match the filter as text or NDJSON. The caller must close the returned
+// reader.
+func (c *Client) DownloadTemplateVersionLogs(ctx context.Context, version uuid.UUID, format ProvisionerJobLogsFormat, filter ProvisionerJobLogsFilter) (io.ReadCloser, error) {
+	return c.downloadProvisionerJobLogs(ctx, fmt.Sprintf("/api/v2/templateversions/%s/logs", version), format, filter)
+}
+
 // CreateTemplateVersionDryRunRequest defines the request parameters for
 // CreateTemplateVersionDryRun.
 type CreateTemplateVersionDryRunRequest struct {
@@ -0,0 +1,239 @@
+package codersdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+)
+
+// WebhookEvent is an event that webhooks can subscribe to.
+type WebhookEvent string
+
+const (
+	WebhookEventBuildStarted            WebhookEvent = "build.started"
+	WebhookEventBuildSucceeded          WebhookEvent = "build.succeeded"
+	WebhookEventBuildFailed             WebhookEvent = "build.failed"
+	WebhookEventWorkspaceCreated        WebhookEvent = "workspace.created"
+	WebhookEventWorkspaceDeleted        WebhookEvent = "workspace.deleted"
+	WebhookEventTemplateVersionPromoted WebhookEvent = "template_version.promoted"
+	WebhookEventUserCreated             WebhookEvent = "user.created"
+	WebhookEventUserSuspended           WebhookEvent = "user.suspended"
+)
+
+// WebhookEvents is every event a webhook can subscribe to.
+var WebhookEvents = []WebhookEvent{
+	WebhookEventBuildStarted,
+	WebhookEventBuildSucceeded,
+	WebhookEventBuildFailed,
+	WebhookEventWorkspaceCreated,
+	WebhookEventWorkspaceDeleted,
+	WebhookEventTemplateVersionPromoted,
+	WebhookEventUserCreated,
+	WebhookEventUserSuspended,
+}
+
+// Valid returns whether the event is a known webhook event.
+func (e WebhookEvent) Valid() bool {
+	for _, event := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	// WebhookSignatureHeader contains the hex-encoded HMAC-SHA256 of the
+	// request body, keyed by the webhook secret and prefixed with "sha256=".
+	WebhookSignatureHeader = "X-Coder-Signature"
+	// WebhookEventHeader contains the event name of the delivery.
+	WebhookEventHeader = "X-Coder-Event"
+	// WebhookDeliveryHeader contains the event ID. It is the same across
+	// retries, so receivers can use it to ignore duplicate deliveries.
+	WebhookDeliveryHeader = "X-Coder-Delivery"
+)
+
+// Webhook is an outbound webhook subscription. Webhooks without an
+// organization receive events from the whole deployment.
+type Webhook struct {
+	ID             uuid.UUID      `json:"id" format:"uuid"`
+	OrganizationID *uuid.UUID     `json:"organization_id,omitempty" format:"uuid"`
+	CreatedBy      uuid.UUID      `json:"created_by" format:"uuid"`
+	CreatedAt      time.Time      `json:"created_at" format:"date-time"`
+	UpdatedAt      time.Time      `json:"updated_at" format:"date-time"`
+	Name           string         `json:"name"`
+	URL            string         `json:"url"`
+	Events         []WebhookEvent `json:"events"`
+	Enabled        bool           `json:"enabled"`
+	// Secret is only returned when the webhook is created.
+	Secret string `json:"secret,omitempty"`
+}
+
+type CreateWebhookRequest struct {
+	Name   string         `json:"name" validate:"required"`
+	URL    string         `json:"url" validate:"required,url"`
+	Events []WebhookEvent `json:"events" validate:"required,min=1"`
+	// Secret signs delivery payloads. A random secret is generated if
+	// it is omitted.
+	Secret string `json:"secret,omitempty"`
+}
+
+// UpdateWebhookRequest changes a webhook. Omitted fields are unchanged.
+type UpdateWebhookRequest struct {
+	Name    string         `json:"name,omitempty"`
+	URL     string         `json:"url,omitempty" validate:"omitempty,url"`
+	Events  []WebhookEvent `json:"events,omitempty"`
+	Enabled *bool          `json:"enabled,omitempty"`
+}
+
+// WebhookEventPayload is the JSON body sent to webhook URLs.
+type WebhookEventPayload struct {
+	ID             uuid.UUID    `json:"id" format:"uuid"`
+	Event          WebhookEvent `json:"event"`
+	CreatedAt      time.Time    `json:"created_at" format:"date-time"`
+	OrganizationID uuid.UUID    `json:"organization_id" format:"uuid"`
+	// OrganizationIDs are the organizations of the user for user events,
+	// which aren't scoped to one organization. OrganizationID is empty for
+	// them.
+	OrganizationIDs []uuid.UUID `json:"organization_ids,omitempty" format:"uuid"`
+	// Data describes the resource the event is about, e.g. "workspace_id"
+	// and "workspace_name" for workspace events.
+	Data map[string]string `json:"data"`
+}
+
+// WebhookDelivery is a single attempt to deliver an event to a webhook.
+type WebhookDelivery struct {
+	ID           uuid.UUID           `json:"id" format:"uuid"`
+	WebhookID    uuid.UUID           `json:"webhook_id" format:"uuid"`
+	Event        WebhookEvent        `json:"event"`
+	Payload      WebhookEventPayload `json:"payload"`
+	Attempt      int                 `json:"attempt"`
+	CreatedAt    time.Time           `json:"created_at" format:"date-time"`
+	DeliveredAt  *time.Time          `json:"delivered_at,omitempty" format:"date-time"`
+	StatusCode   int                 `json:"status_code,omitempty"`
+	ResponseBody string              `json:"response_body,omitempty"`
+	Error        string              `json:"error,omitempty"`
+}
+
+// Succeeded returns whether the receiver accepted the delivery.
+func (d WebhookDelivery) Succeeded() bool {
+	return d.StatusCode >= 200 && d.StatusCode < 300
+}
+
+// CreateWebhook creates a deployment-wide webhook.
+func (c *Client) CreateWebhook(ctx context.Context, req CreateWebhookRequest) (Webhook, error) {
+	return c.createWebhook(ctx, "/api/v2/webhooks", req)
+}
+
+// CreateOrganizationWebhook creates a webhook that only receives events
+// from the organization.
+func (c *Client) CreateOrganizationWebhook(ctx context.Context, orgID uuid.UUID, req CreateWebhookRequest) (Webhook, error) {
+	return c.createWebhook(ctx, fmt.Sprintf("/api/v2/organizations/%s/webhooks", orgID), req)
+}
+
+func (c *Client) createWebhook(ctx context.Context, path string, req CreateWebhookRequest) (Webhook, error) {
+	res, err := c.Request(ctx, http.MethodPost, path, req)
+	if err != nil {
+		return Webhook{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return Webhook{}, ReadBodyAsError(res)
+	}
+	var webhook Webhook
+	return webhook, json.NewDecoder(res.Body).Decode(&webhook)
+}
+
+// Webhooks returns the deployment-wide webhooks.
+func (c *Client) Webhooks(ctx context.Context) ([]Webhook, error) {
+	return c.webhooks(ctx, "/api/v2/webhooks")
+}
+
+// OrganizationWebhooks returns the webhooks of an organization.
+func (c *Client) OrganizationWebhooks(ctx context.Context, orgID uuid.UUID) ([]Webhook, error) {
+	return c.webhooks(ctx, fmt.Sprintf("/api/v2/organizations/%s/webhooks", orgID))
+}
+
+func (c *Client) webhooks(ctx context.Context, path string) ([]Webhook, error) {
+	res, err := c.Request(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var webhooks []Webhook
+	return webhooks, json.NewDecoder(res.Body).Decode(&webhooks)
+}
+
+func (c *Client) Webhook(ctx context.Context, id uuid.UUID) (Webhook, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/webhooks/%s", id), nil)
+	if err != nil {
+		return Webhook{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Webhook{}, ReadBodyAsError(res)
+	}
+	var webhook Webhook
+	return webhook, json.NewDecoder(res.Body).Decode(&webhook)
+}
+
+func (c *Client) UpdateWebhook(ctx context.Context, id uuid.UUID, req UpdateWebhookRequest) (Webhook, error) {
+	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/webhooks/%s", id), req)
+	if err != nil {
+		return Webhook{}, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Webhook{}, ReadBodyAsError(res)
+	}
+	var webhook Webhook
+	return webhook, json.NewDecoder(res.Body).Decode(&webhook)
+}
+
+func (c *Client) DeleteWebhook(ctx context.Context, id uuid.UUID) error {
+	res, err := c.Request(ctx, http.MethodDelete, fmt.Sprintf("/api/v2/webhooks/%s", id), nil)
+	if err != nil {
+		return xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
+// WebhookDeliveries returns the most recent delivery attempts of a webhook,
+// newest first. A limit of zero returns every attempt.
+func (c *Client) WebhookDeliveries(ctx context.Context, id uuid.UUID, limit int) ([]WebhookDelivery, error) {
+	var limitParam string
+	if limit > 0 {
+		limitParam = strconv.Itoa(limit)
+	}
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/webhooks/%s/deliveries", id), nil,
+		WithQueryParam("limit", limitParam),
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("make request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, ReadBodyAsError(res)
+	}
+	var deliveries []WebhookDelivery
+	return deliveries, json.NewDecoder(res.Body).Decode(&deliveries)
+}
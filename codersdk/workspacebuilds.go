@@ -139,6 +139,18 @@ func (c *Client) WorkspaceBuildLogsAfter(ctx context.Context, build uuid.UUID, a
 	return c.provisionerJobLogsAfter(ctx, fmt.Sprintf("/api/v2/workspacebuilds/%s/logs", build), after)
 }
 
+// WorkspaceBuildLogs returns the logs of a workspace build that match the
+// filter.
+func (c *Client) WorkspaceBuildLogs(ctx context.Context, build uuid.UUID, filter ProvisionerJobLogsFilter) ([]ProvisionerJobLog, error) {
+	return c.provisionerJobLogs(ctx, fmt.Sprintf("/api/v2/workspacebuilds/%s/logs", build), filter)
+}
+
+// DownloadWorkspaceBuildLogs returns the logs of a workspace build that match
+// the filter as text or NDJSON. The caller must close the returned reader.
+func (c *Client) DownloadWorkspaceBuildLogs(ctx context.Context, build uuid.UUID, format ProvisionerJobLogsFormat, filter ProvisionerJobLogsFilter) (io.ReadCloser, error) {
+	return c.downloadProvisionerJobLogs(ctx, fmt.Sprintf("/api/v2/workspacebuilds/%s/logs", build), format, filter)
+}
+
 // WorkspaceBuildState returns the provisioner state of the build.
 func (c *Client) WorkspaceBuildState(ctx context.Context, build uuid.UUID) ([]byte, error) {
 	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspacebuilds/%s/state", build), nil)
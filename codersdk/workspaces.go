@@ -27,12 +27,16 @@ type Workspace struct {
 	TemplateDisplayName                  string         `json:"template_display_name"`
 	TemplateIcon                         string         `json:"template_icon"`
 	TemplateAllowUserCancelWorkspaceJobs bool           `json:"template_allow_user_cancel_workspace_jobs"`
+	TemplateDeprecationMessage           string         `json:"template_deprecation_message,omitempty"`
 	LatestBuild                          WorkspaceBuild `json:"latest_build"`
 	Outdated                             bool           `json:"outdated"`
 	Name                                 string         `json:"name"`
 	AutostartSchedule                    *string        `json:"autostart_schedule,omitempty"`
 	TTLMillis                            *int64         `json:"ttl_ms,omitempty"`
 	LastUsedAt                           time.Time      `json:"last_used_at" format:"date-time"`
+	// Labels are user defined key/value pairs to organize workspaces. They
+	// are passed to the template as the coder_workspace_labels variable.
+	Labels map[string]string `json:"labels"`
 }
 
 type WorkspacesRequest struct {
@@ -58,6 +62,10 @@ type CreateWorkspaceBuildRequest struct {
 	// This will not delete old params not included in this list.
 	ParameterValues     []CreateParameterRequest  `json:"parameter_values,omitempty"`
 	RichParameterValues []WorkspaceBuildParameter `json:"rich_parameter_values,omitempty"`
+	// Priority of the build's provisioner job, which defaults to interactive.
+	// Scripts that queue many builds should use background, so users don't
+	// wait behind them.
+	Priority ProvisionerJobPriority `json:"priority,omitempty" enums:"interactive,automatic,background"`
 }
 
 type WorkspaceOptions struct {
@@ -187,7 +195,10 @@ func (c *Client) WatchWorkspace(ctx context.Context, id uuid.UUID) (<-chan Works
 }
 
 type UpdateWorkspaceRequest struct {
-	Name string `json:"name,omitempty" validate:"username"`
+	Name string `json:"name,omitempty" validate:"omitempty,username"`
+	// Labels replace the labels of the workspace. They are not changed when
+	// omitted, an empty object removes all labels.
+	Labels map[string]string `json:"labels"`
 }
 
 func (c *Client) UpdateWorkspace(ctx context.Context, id uuid.UUID, req UpdateWorkspaceRequest) error {
@@ -203,6 +214,64 @@ func (c *Client) UpdateWorkspace(ctx context.Context, id uuid.UUID, req UpdateWo
 	return nil
 }
 
+// CloneWorkspaceRequest is a request to create a workspace for the current
+// user with the template and parameter values of another workspace.
+type CloneWorkspaceRequest struct {
+	Name string `json:"name" validate:"workspace_name,required"`
+	// PinTemplateVersion creates the workspace with the template version of
+	// the latest build of the source workspace, instead of the active version.
+	PinTemplateVersion bool `json:"pin_template_version,omitempty"`
+	// ParameterValues override the legacy parameter values of the source
+	// workspace by name.
+	ParameterValues []CreateParameterRequest `json:"parameter_values,omitempty"`
+	// RichParameterValues override the build parameter values of the source
+	// workspace by name.
+	RichParameterValues []WorkspaceBuildParameter `json:"rich_parameter_values,omitempty"`
+	// Labels are added to the labels of the source workspace, replacing
+	// labels with the same key.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// CloneWorkspace creates a copy of the workspace for the current user.
+func (c *Client) CloneWorkspace(ctx context.Context, id uuid.UUID, req CloneWorkspaceRequest) (Workspace, error) {
+	path := fmt.Sprintf("/api/v2/workspaces/%s/clone", id.String())
+	res, err := c.Request(ctx, http.MethodPost, path, req)
+	if err != nil {
+		return Workspace{}, xerrors.Errorf("clone workspace: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusCreated {
+		return Workspace{}, ReadBodyAsError(res)
+	}
+	var workspace Workspace
+	return workspace, json.NewDecoder(res.Body).Decode(&workspace)
+}
+
+// TransferWorkspaceRequest is a request to give a workspace to another user.
+type TransferWorkspaceRequest struct {
+	// Owner is the username or ID of the new owner.
+	Owner string `json:"owner" validate:"required"`
+	// Name renames the workspace. If empty, the workspace keeps its name, or
+	// gets a numeric suffix if the new owner has a workspace with that name.
+	Name string `json:"name,omitempty" validate:"omitempty,username"`
+}
+
+// TransferWorkspace changes the owner of the workspace and starts a build to
+// apply the change.
+func (c *Client) TransferWorkspace(ctx context.Context, id uuid.UUID, req TransferWorkspaceRequest) (Workspace, error) {
+	path := fmt.Sprintf("/api/v2/workspaces/%s/transfer", id.String())
+	res, err := c.Request(ctx, http.MethodPost, path, req)
+	if err != nil {
+		return Workspace{}, xerrors.Errorf("transfer workspace: %w", err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return Workspace{}, ReadBodyAsError(res)
+	}
+	var workspace Workspace
+	return workspace, json.NewDecoder(res.Body).Decode(&workspace)
+}
+
 // UpdateWorkspaceAutostartRequest is a request to update a workspace's autostart schedule.
 type UpdateWorkspaceAutostartRequest struct {
 	Schedule *string `json:"schedule"`
@@ -263,6 +332,69 @@ func (c *Client) PutExtendWorkspace(ctx context.Context, id uuid.UUID, req PutEx
 	return nil
 }
 
+// WorkspaceRole is the access a user or group has to a workspace that is
+// shared with them.
+type WorkspaceRole string
+
+const (
+	// WorkspaceRoleAdmin can also start, stop and update the workspace.
+	WorkspaceRoleAdmin WorkspaceRole = "admin"
+	// WorkspaceRoleUse can connect to the workspace with SSH, apps and
+	// port-forwarding.
+	WorkspaceRoleUse     WorkspaceRole = "use"
+	WorkspaceRoleDeleted WorkspaceRole = ""
+)
+
+type WorkspaceACL struct {
+	Users  []WorkspaceUser  `json:"users"`
+	Groups []WorkspaceGroup `json:"group"`
+}
+
+type WorkspaceGroup struct {
+	Group
+	Role WorkspaceRole `json:"role" enums:"admin,use"`
+}
+
+type WorkspaceUser struct {
+	User
+	Role WorkspaceRole `json:"role" enums:"admin,use"`
+}
+
+// UpdateWorkspaceACL maps user and group IDs to their role. An empty role
+// stops sharing the workspace with the user or group.
+type UpdateWorkspaceACL struct {
+	UserPerms  map[string]WorkspaceRole `json:"user_perms,omitempty"`
+	GroupPerms map[string]WorkspaceRole `json:"group_perms,omitempty"`
+}
+
+// WorkspaceACL returns the users and groups the workspace is shared with.
+func (c *Client) WorkspaceACL(ctx context.Context, id uuid.UUID) (WorkspaceACL, error) {
+	res, err := c.Request(ctx, http.MethodGet, fmt.Sprintf("/api/v2/workspaces/%s/acl", id), nil)
+	if err != nil {
+		return WorkspaceACL{}, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return WorkspaceACL{}, ReadBodyAsError(res)
+	}
+	var acl WorkspaceACL
+	return acl, json.NewDecoder(res.Body).Decode(&acl)
+}
+
+// UpdateWorkspaceACL shares the workspace with users and groups, or stops
+// sharing it.
+func (c *Client) UpdateWorkspaceACL(ctx context.Context, id uuid.UUID, req UpdateWorkspaceACL) error {
+	res, err := c.Request(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/workspaces/%s/acl", id), req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return ReadBodyAsError(res)
+	}
+	return nil
+}
+
 type WorkspaceFilter struct {
 	// Owner can be "me" or a username
 	Owner string `json:"owner,omitempty" typescript:"-"`
@@ -272,6 +404,8 @@ type WorkspaceFilter struct {
 	Name string `json:"name,omitempty" typescript:"-"`
 	// Status is a workspace status, which is really the status of the latest build
 	Status string `json:"status,omitempty" typescript:"-"`
+	// Labels only return workspaces that have all of the given labels.
+	Labels map[string]string `json:"labels,omitempty" typescript:"-"`
 	// Offset is the number of workspaces to skip before returning results.
 	Offset int `json:"offset,omitempty" typescript:"-"`
 	// Limit is a limit on the number of workspaces returned.
@@ -299,6 +433,9 @@ func (f WorkspaceFilter) asRequestOption() RequestOption {
 		if f.Status != "" {
 			params = append(params, fmt.Sprintf("status:%q", f.Status))
 		}
+		for key, value := range f.Labels {
+			params = append(params, fmt.Sprintf("label:%q", key+"="+value))
+		}
 		if f.FilterQuery != "" {
 			// If custom stuff is added, just add it on here.
 			params = append(params, f.FilterQuery)
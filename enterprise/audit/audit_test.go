@@ -128,3 +128,48 @@ func (t *testBackend) Export(_ context.Context, alog database.AuditLog) error {
 	t.alogs = append(t.alogs, alog)
 	return nil
 }
+
+func TestParseFilter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	check := func(t *testing.T, filter audit.Filter, resourceType database.ResourceType, action database.AuditAction) audit.FilterDecision {
+		t.Helper()
+		alog := audittest.RandomLog()
+		alog.ResourceType = resourceType
+		alog.Action = action
+		decision, err := filter.Check(ctx, alog)
+		require.NoError(t, err)
+		return decision
+	}
+	const (
+		export = audit.FilterDecisionStore | audit.FilterDecisionExport
+		store  = audit.FilterDecisionStore
+	)
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+		filter, err := audit.ParseFilter(nil)
+		require.NoError(t, err)
+		require.Equal(t, export, check(t, filter, database.ResourceTypeUser, database.AuditActionCreate))
+	})
+
+	t.Run("Patterns", func(t *testing.T) {
+		t.Parallel()
+		filter, err := audit.ParseFilter([]string{"workspace", "user:delete", "*:write"})
+		require.NoError(t, err)
+		require.Equal(t, export, check(t, filter, database.ResourceTypeWorkspace, database.AuditActionCreate))
+		require.Equal(t, export, check(t, filter, database.ResourceTypeUser, database.AuditActionDelete))
+		require.Equal(t, store, check(t, filter, database.ResourceTypeUser, database.AuditActionCreate))
+		require.Equal(t, export, check(t, filter, database.ResourceTypeTemplate, database.AuditActionWrite))
+		require.Equal(t, store, check(t, filter, database.ResourceTypeTemplate, database.AuditActionDelete))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		t.Parallel()
+		_, err := audit.ParseFilter([]string{"spaceship"})
+		require.Error(t, err)
+		_, err = audit.ParseFilter([]string{"user:launch"})
+		require.Error(t, err)
+	})
+}
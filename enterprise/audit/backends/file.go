@@ -0,0 +1,52 @@
+package backends
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	agplaudit "github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+)
+
+// NewFile returns a backend that appends audit logs as JSON lines to a file.
+// The file is rotated once it's larger than maxSizeMB, and at most maxBackups
+// rotated files are kept. Every rotated file is kept if maxBackups is 0.
+func NewFile(path string, maxSizeMB, maxBackups int, opts StreamOptions) (*StreamBackend, error) {
+	if path == "" {
+		return nil, xerrors.New("audit log file path must be set")
+	}
+	return newStreamBackend("file", &fileSink{
+		writer: &lumberjack.Logger{
+			Filename:   path,
+			MaxSize:    maxSizeMB,
+			MaxBackups: maxBackups,
+		},
+	}, opts), nil
+}
+
+type fileSink struct {
+	writer *lumberjack.Logger
+}
+
+func (s *fileSink) send(_ context.Context, logs []database.AuditLog) error {
+	lines := make([]byte, 0, 512*len(logs))
+	for _, alog := range logs {
+		data, err := agplaudit.MarshalLog(alog)
+		if err != nil {
+			return permanentError{xerrors.Errorf("marshal audit log %s: %w", alog.ID, err)}
+		}
+		lines = append(lines, data...)
+		lines = append(lines, '\n')
+	}
+	_, err := s.writer.Write(lines)
+	if err != nil {
+		return xerrors.Errorf("write audit logs: %w", err)
+	}
+	return nil
+}
+
+func (s *fileSink) close() error {
+	return s.writer.Close()
+}
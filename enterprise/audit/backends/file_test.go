@@ -0,0 +1,51 @@
+package backends_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/enterprise/audit/audittest"
+	"github.com/coder/coder/enterprise/audit/backends"
+	cdrtestutil "github.com/coder/coder/testutil"
+)
+
+func TestFileBackend(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.log")
+	metrics := backends.NewMetrics(prometheus.NewRegistry())
+	backend, err := backends.NewFile(path, 1, 1, backends.StreamOptions{
+		Logger:  slogtest.Make(t, nil),
+		Metrics: metrics,
+	})
+	require.NoError(t, err)
+
+	first, second := audittest.RandomLog(), audittest.RandomLog()
+	require.NoError(t, backend.Export(context.Background(), first))
+	require.NoError(t, backend.Export(context.Background(), second))
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(metrics.Exported.WithLabelValues("file")) == 2
+	}, cdrtestutil.WaitShort, cdrtestutil.IntervalFast)
+	require.NoError(t, backend.Close())
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for _, alog := range []string{first.ID.String(), second.ID.String()} {
+		require.True(t, scanner.Scan())
+		var log map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &log))
+		require.Equal(t, alog, log["id"])
+	}
+	require.False(t, scanner.Scan())
+}
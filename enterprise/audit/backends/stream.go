@@ -0,0 +1,377 @@
+package backends
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/xerrors"
+
+	"cdr.dev/slog"
+	agplaudit "github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/enterprise/audit"
+)
+
+// Metrics are the delivery metrics of streaming backends, labeled by backend.
+type Metrics struct {
+	Exported *prometheus.CounterVec
+	Failures *prometheus.CounterVec
+	Dropped  *prometheus.CounterVec
+	Buffered *prometheus.GaugeVec
+	// DeadLettered counts the audit logs that were given up on and written
+	// to the dead letter file.
+	DeadLettered *prometheus.CounterVec
+}
+
+// NewMetrics registers the metrics of streaming backends.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	auto := promauto.With(reg)
+	return &Metrics{
+		Exported: auto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "audit_stream",
+			Name:      "exported_total",
+			Help:      "The number of audit logs delivered to a streaming backend.",
+		}, []string{"backend"}),
+		Failures: auto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "audit_stream",
+			Name:      "failures_total",
+			Help:      "The number of failed attempts to deliver audit logs to a streaming backend.",
+		}, []string{"backend"}),
+		Dropped: auto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "audit_stream",
+			Name:      "dropped_total",
+			Help:      "The number of audit logs dropped because the buffer of a streaming backend was full.",
+		}, []string{"backend"}),
+		Buffered: auto.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "coderd",
+			Subsystem: "audit_stream",
+			Name:      "buffered",
+			Help:      "The number of audit logs waiting to be delivered to a streaming backend.",
+		}, []string{"backend"}),
+		DeadLettered: auto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "coderd",
+			Subsystem: "audit_stream",
+			Name:      "dead_lettered_total",
+			Help:      "The number of audit logs that couldn't be delivered to a streaming backend and were written to its dead letter file.",
+		}, []string{"backend"}),
+	}
+}
+
+// StreamOptions configure a streaming backend.
+type StreamOptions struct {
+	Logger slog.Logger
+	// Metrics are shared by the streaming backends. If nil, metrics aren't
+	// registered anywhere.
+	Metrics *Metrics
+	// Filter decides which audit logs the backend exports. If nil, every
+	// audit log is exported.
+	Filter audit.Filter
+	// BatchSize is the most audit logs delivered at once, default 1.
+	BatchSize int
+	// FlushInterval is how long to wait for a batch to fill up before
+	// delivering it. Audit logs are delivered as soon as they're exported if
+	// 0.
+	FlushInterval time.Duration
+	// BufferSize is the most audit logs kept while the sink is down. Newer
+	// audit logs are dropped while the buffer is full, default 10000.
+	BufferSize int
+	// RetryBackoff is the delay before retrying a failed delivery. It doubles
+	// after every failed attempt up to a minute, default 1s.
+	RetryBackoff time.Duration
+	// MaxAttempts is how many times delivering a batch is attempted before
+	// it's given up on, default 10. Batches the sink rejects permanently are
+	// given up on right away.
+	MaxAttempts int
+	// DeadLetterPath is the file batches that are given up on are appended
+	// to as JSON lines, so they can be delivered by hand. If empty, they're
+	// logged and dropped.
+	DeadLetterPath string
+}
+
+// maxRetryBackoff bounds the delay between delivery attempts, so delivery
+// resumes soon after the sink comes back up.
+const maxRetryBackoff = time.Minute
+
+// sink delivers audit logs to a destination.
+type sink interface {
+	// send delivers a batch of audit logs. The whole batch is retried if it
+	// returns an error, unless it's a permanentError, so logs may be delivered
+	// more than once.
+	send(ctx context.Context, logs []database.AuditLog) error
+	close() error
+}
+
+// permanentError is returned by sinks when retrying can't deliver a batch,
+// e.g. because the destination rejected it.
+type permanentError struct {
+	error
+}
+
+func (e permanentError) Unwrap() error {
+	return e.error
+}
+
+// deadLetter is a line of the dead letter file.
+type deadLetter struct {
+	Backend   string            `json:"backend"`
+	Time      time.Time         `json:"time"`
+	Attempts  int               `json:"attempts"`
+	Error     string            `json:"error"`
+	AuditLogs []json.RawMessage `json:"audit_logs"`
+}
+
+// StreamBackend exports audit logs to a sink in the background, so a slow or
+// unavailable sink doesn't slow down requests. Logs that can't be delivered
+// are kept in a buffer and retried until the sink accepts them, or the
+// attempts run out and they're written to the dead letter file.
+type StreamBackend struct {
+	name string
+	sink sink
+	opts StreamOptions
+
+	mu     sync.Mutex
+	buffer []database.AuditLog
+	// notify is signaled when logs are added to the buffer.
+	notify chan struct{}
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newStreamBackend(name string, s sink, opts StreamOptions) *StreamBackend {
+	if opts.Metrics == nil {
+		opts.Metrics = NewMetrics(prometheus.NewRegistry())
+	}
+	if opts.Filter == nil {
+		opts.Filter = audit.DefaultFilter
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 10000
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = time.Second
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 10
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &StreamBackend{
+		name:   name,
+		sink:   s,
+		opts:   opts,
+		notify: make(chan struct{}, 1),
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+func (*StreamBackend) Decision() audit.FilterDecision {
+	return audit.FilterDecisionExport
+}
+
+// Export adds the audit log to the buffer if the filter of the backend allows
+// exporting it. It never blocks on the sink.
+func (b *StreamBackend) Export(ctx context.Context, alog database.AuditLog) error {
+	decision, err := b.opts.Filter.Check(ctx, alog)
+	if err != nil {
+		return err
+	}
+	if decision&audit.FilterDecisionExport == 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	if len(b.buffer) >= b.opts.BufferSize {
+		b.mu.Unlock()
+		b.opts.Metrics.Dropped.WithLabelValues(b.name).Inc()
+		b.opts.Logger.Warn(ctx, "audit stream buffer is full, dropping audit log",
+			slog.F("backend", b.name), slog.F("audit_log_id", alog.ID))
+		return nil
+	}
+	b.buffer = append(b.buffer, alog)
+	b.opts.Metrics.Buffered.WithLabelValues(b.name).Set(float64(len(b.buffer)))
+	b.mu.Unlock()
+
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Close stops delivering audit logs and closes the sink. Logs that are still
+// buffered are lost, but they're stored in the database as well.
+func (b *StreamBackend) Close() error {
+	b.cancel()
+	<-b.done
+	b.mu.Lock()
+	if len(b.buffer) > 0 {
+		b.opts.Logger.Warn(context.Background(), "closed audit stream with undelivered audit logs",
+			slog.F("backend", b.name), slog.F("count", len(b.buffer)))
+	}
+	b.mu.Unlock()
+	return b.sink.close()
+}
+
+func (b *StreamBackend) run() {
+	defer close(b.done)
+
+	backoff := b.opts.RetryBackoff
+	attempts := 0
+	for {
+		batch := b.nextBatch()
+		if batch == nil {
+			return
+		}
+		attempts++
+		err := b.sink.send(b.ctx, batch)
+		if err != nil {
+			if b.ctx.Err() != nil {
+				return
+			}
+			b.opts.Metrics.Failures.WithLabelValues(b.name).Inc()
+			if xerrors.As(err, &permanentError{}) || attempts >= b.opts.MaxAttempts {
+				b.deadLetter(batch, attempts, err)
+				b.pop(len(batch))
+				attempts = 0
+				backoff = b.opts.RetryBackoff
+				continue
+			}
+			b.opts.Logger.Warn(b.ctx, "deliver audit logs",
+				slog.F("backend", b.name), slog.F("count", len(batch)), slog.F("retry_in", backoff), slog.Error(err))
+			select {
+			case <-b.ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > maxRetryBackoff {
+				backoff = maxRetryBackoff
+			}
+			continue
+		}
+		attempts = 0
+		backoff = b.opts.RetryBackoff
+		b.pop(len(batch))
+		b.opts.Metrics.Exported.WithLabelValues(b.name).Add(float64(len(batch)))
+	}
+}
+
+// pop removes a delivered batch from the buffer. Logs are only appended while
+// the batch is delivered, so the batch is still at the front of the buffer.
+func (b *StreamBackend) pop(size int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buffer = b.buffer[size:]
+	b.opts.Metrics.Buffered.WithLabelValues(b.name).Set(float64(len(b.buffer)))
+}
+
+// deadLetter appends a batch that's given up on to the dead letter file.
+func (b *StreamBackend) deadLetter(batch []database.AuditLog, attempts int, sendErr error) {
+	logger := b.opts.Logger.With(slog.F("backend", b.name), slog.F("count", len(batch)), slog.F("attempts", attempts))
+	if b.opts.DeadLetterPath == "" {
+		b.opts.Metrics.Dropped.WithLabelValues(b.name).Add(float64(len(batch)))
+		logger.Error(b.ctx, "give up delivering audit logs, dropping them", slog.Error(sendErr))
+		return
+	}
+	err := b.writeDeadLetter(batch, attempts, sendErr)
+	if err != nil {
+		b.opts.Metrics.Dropped.WithLabelValues(b.name).Add(float64(len(batch)))
+		logger.Error(b.ctx, "write audit logs to the dead letter file, dropping them",
+			slog.F("path", b.opts.DeadLetterPath), slog.F("send_error", sendErr.Error()), slog.Error(err))
+		return
+	}
+	b.opts.Metrics.DeadLettered.WithLabelValues(b.name).Add(float64(len(batch)))
+	logger.Error(b.ctx, "give up delivering audit logs, wrote them to the dead letter file",
+		slog.F("path", b.opts.DeadLetterPath), slog.Error(sendErr))
+}
+
+func (b *StreamBackend) writeDeadLetter(batch []database.AuditLog, attempts int, sendErr error) error {
+	letter := deadLetter{
+		Backend:   b.name,
+		Time:      database.Now(),
+		Attempts:  attempts,
+		Error:     sendErr.Error(),
+		AuditLogs: make([]json.RawMessage, 0, len(batch)),
+	}
+	for _, alog := range batch {
+		data, err := agplaudit.MarshalLog(alog)
+		if err != nil {
+			return xerrors.Errorf("marshal audit log %s: %w", alog.ID, err)
+		}
+		letter.AuditLogs = append(letter.AuditLogs, data)
+	}
+	line, err := json.Marshal(letter)
+	if err != nil {
+		return xerrors.Errorf("marshal dead letter: %w", err)
+	}
+	err = os.MkdirAll(filepath.Dir(b.opts.DeadLetterPath), 0o700)
+	if err != nil {
+		return xerrors.Errorf("create dead letter directory: %w", err)
+	}
+	file, err := os.OpenFile(b.opts.DeadLetterPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return xerrors.Errorf("open dead letter file: %w", err)
+	}
+	_, err = file.Write(append(line, '\n'))
+	if err != nil {
+		_ = file.Close()
+		return xerrors.Errorf("write dead letter file: %w", err)
+	}
+	return file.Close()
+}
+
+// nextBatch waits for audit logs to deliver. It waits up to the flush interval
+// for a batch to fill up. It returns nil once the backend is closed.
+func (b *StreamBackend) nextBatch() []database.AuditLog {
+	var flush <-chan time.Time
+	for {
+		batch, full := b.peekBatch()
+		if len(batch) > 0 && (full || b.opts.FlushInterval <= 0) {
+			return batch
+		}
+		if len(batch) > 0 && flush == nil {
+			timer := time.NewTimer(b.opts.FlushInterval)
+			defer timer.Stop()
+			flush = timer.C
+		}
+		select {
+		case <-b.ctx.Done():
+			return nil
+		case <-b.notify:
+		case <-flush:
+			batch, _ = b.peekBatch()
+			return batch
+		}
+	}
+}
+
+// peekBatch copies the audit logs at the front of the buffer, and reports
+// whether they fill a batch.
+func (b *StreamBackend) peekBatch() ([]database.AuditLog, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	size := len(b.buffer)
+	if size > b.opts.BatchSize {
+		size = b.opts.BatchSize
+	}
+	batch := make([]database.AuditLog, size)
+	copy(batch, b.buffer)
+	return batch, size == b.opts.BatchSize
+}
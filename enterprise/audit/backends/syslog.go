@@ -0,0 +1,131 @@
+package backends
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	agplaudit "github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+)
+
+const (
+	// syslogFacility is the "log audit" facility of RFC 5424.
+	syslogFacility = 13
+	// syslogSeverityNotice is used for successful requests, and
+	// syslogSeverityWarning for failed ones.
+	syslogSeverityNotice  = 5
+	syslogSeverityWarning = 4
+)
+
+// NewSyslog returns a backend that sends audit logs as RFC 5424 syslog
+// messages, with the audit log as JSON in the message. The address is a URL
+// with the udp, tcp or tls scheme, e.g. "tls://siem.example.com:6514".
+// Messages sent over TCP and TLS are framed with octet counting.
+func NewSyslog(address string, opts StreamOptions) (*StreamBackend, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, xerrors.Errorf("parse syslog address: %w", err)
+	}
+	switch u.Scheme {
+	case "udp", "tcp", "tls":
+	default:
+		return nil, xerrors.Errorf("syslog address %q must have the udp, tcp or tls scheme", address)
+	}
+	if u.Port() == "" {
+		return nil, xerrors.Errorf("syslog address %q must have a port", address)
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	return newStreamBackend("syslog", &syslogSink{
+		scheme:   u.Scheme,
+		host:     u.Host,
+		hostname: hostname,
+	}, opts), nil
+}
+
+type syslogSink struct {
+	scheme   string
+	host     string
+	hostname string
+
+	// conn is only used by the goroutine of the backend.
+	conn net.Conn
+}
+
+func (s *syslogSink) send(ctx context.Context, logs []database.AuditLog) error {
+	if s.conn == nil {
+		conn, err := s.dial(ctx)
+		if err != nil {
+			return xerrors.Errorf("dial syslog: %w", err)
+		}
+		s.conn = conn
+	}
+	for _, alog := range logs {
+		msg, err := s.format(alog)
+		if err != nil {
+			return permanentError{xerrors.Errorf("format audit log %s: %w", alog.ID, err)}
+		}
+		if s.scheme != "udp" {
+			msg = append([]byte(fmt.Sprintf("%d ", len(msg))), msg...)
+		}
+		err = s.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+		if err == nil {
+			_, err = s.conn.Write(msg)
+		}
+		if err != nil {
+			// Reconnect on the next attempt.
+			_ = s.conn.Close()
+			s.conn = nil
+			return xerrors.Errorf("write syslog message: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *syslogSink) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if s.scheme == "tls" {
+		tlsDialer := &tls.Dialer{
+			NetDialer: dialer,
+			Config:    &tls.Config{MinVersion: tls.VersionTLS12},
+		}
+		return tlsDialer.DialContext(ctx, "tcp", s.host)
+	}
+	return dialer.DialContext(ctx, s.scheme, s.host)
+}
+
+// format returns the audit log as an RFC 5424 message, without framing.
+func (s *syslogSink) format(alog database.AuditLog) ([]byte, error) {
+	data, err := agplaudit.MarshalLog(alog)
+	if err != nil {
+		return nil, err
+	}
+	severity := syslogSeverityNotice
+	if alog.StatusCode >= 400 {
+		severity = syslogSeverityWarning
+	}
+	// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+	header := fmt.Sprintf("<%d>1 %s %s coder %d audit - ",
+		syslogFacility*8+severity,
+		alog.Time.UTC().Format(time.RFC3339Nano),
+		s.hostname,
+		os.Getpid(),
+	)
+	return append([]byte(header), data...), nil
+}
+
+func (s *syslogSink) close() error {
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
@@ -0,0 +1,102 @@
+package backends_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/enterprise/audit/audittest"
+	"github.com/coder/coder/enterprise/audit/backends"
+)
+
+func TestSyslogBackend(t *testing.T) {
+	t.Parallel()
+	t.Run("UDP", func(t *testing.T) {
+		t.Parallel()
+
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer conn.Close()
+
+		backend, err := backends.NewSyslog(fmt.Sprintf("udp://%s", conn.LocalAddr()), backends.StreamOptions{
+			Logger: slogtest.Make(t, nil),
+		})
+		require.NoError(t, err)
+		defer backend.Close()
+
+		alog := audittest.RandomLog()
+		require.NoError(t, backend.Export(context.Background(), alog))
+
+		buf := make([]byte, 64<<10)
+		n, _, err := conn.ReadFrom(buf)
+		require.NoError(t, err)
+		requireSyslogMessage(t, string(buf[:n]), alog.ID.String())
+	})
+
+	t.Run("TCP", func(t *testing.T) {
+		t.Parallel()
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer listener.Close()
+
+		backend, err := backends.NewSyslog(fmt.Sprintf("tcp://%s", listener.Addr()), backends.StreamOptions{
+			Logger: slogtest.Make(t, nil),
+		})
+		require.NoError(t, err)
+		defer backend.Close()
+
+		first, second := audittest.RandomLog(), audittest.RandomLog()
+		require.NoError(t, backend.Export(context.Background(), first))
+		require.NoError(t, backend.Export(context.Background(), second))
+
+		conn, err := listener.Accept()
+		require.NoError(t, err)
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		// Messages are framed with octet counting.
+		for _, alog := range []string{first.ID.String(), second.ID.String()} {
+			length, err := reader.ReadString(' ')
+			require.NoError(t, err)
+			n, err := strconv.Atoi(strings.TrimSpace(length))
+			require.NoError(t, err)
+			msg := make([]byte, n)
+			_, err = io.ReadFull(reader, msg)
+			require.NoError(t, err)
+			requireSyslogMessage(t, string(msg), alog)
+		}
+	})
+
+	t.Run("InvalidAddress", func(t *testing.T) {
+		t.Parallel()
+		_, err := backends.NewSyslog("localhost:514", backends.StreamOptions{})
+		require.Error(t, err)
+		_, err = backends.NewSyslog("udp://localhost", backends.StreamOptions{})
+		require.Error(t, err)
+	})
+}
+
+func requireSyslogMessage(t *testing.T, msg string, id string) {
+	t.Helper()
+
+	// The audit log is deleted, which succeeded, so it's logged as a notice
+	// with the log audit facility: 13*8+5.
+	require.True(t, strings.HasPrefix(msg, "<109>1 "), msg)
+	fields := strings.SplitN(msg, " ", 8)
+	require.Len(t, fields, 8)
+	require.Equal(t, "coder", fields[3])
+	require.Equal(t, "audit", fields[5])
+	require.Equal(t, "-", fields[6])
+	var log map[string]any
+	require.NoError(t, json.Unmarshal([]byte(fields[7]), &log))
+	require.Equal(t, id, log["id"])
+}
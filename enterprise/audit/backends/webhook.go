@@ -0,0 +1,93 @@
+package backends
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/coder/coder/buildinfo"
+	agplaudit "github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/webhooks"
+	"github.com/coder/coder/codersdk"
+)
+
+// NewWebhook returns a backend that POSTs batches of audit logs as a JSON
+// array to a URL. If secret isn't empty, the body is signed in the
+// codersdk.WebhookSignatureHeader header like outbound webhooks are. Batches
+// are retried until the URL responds with a 2xx status. Batches rejected with
+// a 4xx status aren't retried, except for 408 and 429.
+func NewWebhook(rawURL string, secret string, client *http.Client, opts StreamOptions) (*StreamBackend, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, xerrors.Errorf("parse webhook URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, xerrors.Errorf("webhook URL %q must have the http or https scheme", rawURL)
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return newStreamBackend("webhook", &webhookSink{
+		url:    u.String(),
+		secret: secret,
+		client: client,
+	}, opts), nil
+}
+
+type webhookSink struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func (s *webhookSink) send(ctx context.Context, logs []database.AuditLog) error {
+	body := make([]json.RawMessage, 0, len(logs))
+	for _, alog := range logs {
+		data, err := agplaudit.MarshalLog(alog)
+		if err != nil {
+			return permanentError{xerrors.Errorf("marshal audit log %s: %w", alog.ID, err)}
+		}
+		body = append(body, data)
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return xerrors.Errorf("marshal audit logs: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(payload))
+	if err != nil {
+		return xerrors.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", fmt.Sprintf("Coder-Audit/%s", buildinfo.Version()))
+	if s.secret != "" {
+		req.Header.Set(codersdk.WebhookSignatureHeader, webhooks.Sign(s.secret, payload))
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return xerrors.Errorf("send request: %w", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 4<<10))
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		err := xerrors.Errorf("unexpected status code %d", res.StatusCode)
+		if res.StatusCode >= 400 && res.StatusCode < 500 &&
+			res.StatusCode != http.StatusRequestTimeout && res.StatusCode != http.StatusTooManyRequests {
+			return permanentError{err}
+		}
+		return err
+	}
+	return nil
+}
+
+func (*webhookSink) close() error {
+	return nil
+}
@@ -0,0 +1,273 @@
+package backends_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"cdr.dev/slog/sloggers/slogtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/webhooks"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/audit"
+	"github.com/coder/coder/enterprise/audit/audittest"
+	"github.com/coder/coder/enterprise/audit/backends"
+	cdrtestutil "github.com/coder/coder/testutil"
+)
+
+func TestWebhookBackend(t *testing.T) {
+	t.Parallel()
+	t.Run("Batch", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			mu       sync.Mutex
+			received [][]map[string]any
+		)
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			if !assert.NoError(t, err) {
+				return
+			}
+			if r.Header.Get(codersdk.WebhookSignatureHeader) != webhooks.Sign("secret", body) {
+				rw.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var logs []map[string]any
+			if !assert.NoError(t, json.Unmarshal(body, &logs)) {
+				return
+			}
+			mu.Lock()
+			received = append(received, logs)
+			mu.Unlock()
+		}))
+		defer srv.Close()
+
+		backend, err := backends.NewWebhook(srv.URL, "secret", nil, backends.StreamOptions{
+			Logger:        slogtest.Make(t, nil),
+			BatchSize:     2,
+			FlushInterval: cdrtestutil.IntervalFast,
+		})
+		require.NoError(t, err)
+		defer backend.Close()
+
+		ctx := context.Background()
+		first, second, third := audittest.RandomLog(), audittest.RandomLog(), audittest.RandomLog()
+		require.NoError(t, backend.Export(ctx, first))
+		require.NoError(t, backend.Export(ctx, second))
+		require.NoError(t, backend.Export(ctx, third))
+
+		// The first two logs fill a batch, and the third is sent once the
+		// flush interval elapses.
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(received) == 2
+		}, cdrtestutil.WaitShort, cdrtestutil.IntervalFast)
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, received[0], 2)
+		require.Equal(t, first.ID.String(), received[0][0]["id"])
+		require.Equal(t, second.ID.String(), received[0][1]["id"])
+		require.Len(t, received[1], 1)
+		require.Equal(t, third.ID.String(), received[1][0]["id"])
+		require.Equal(t, "127.0.0.1", received[1][0]["ip"])
+		require.Equal(t, string(third.Action), received[1][0]["action"])
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		t.Parallel()
+
+		var (
+			down     atomic.Bool
+			received atomic.Int64
+		)
+		down.Store(true)
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			if down.Load() {
+				rw.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			var logs []map[string]any
+			if !assert.NoError(t, json.NewDecoder(r.Body).Decode(&logs)) {
+				return
+			}
+			received.Add(int64(len(logs)))
+		}))
+		defer srv.Close()
+
+		metrics := backends.NewMetrics(prometheus.NewRegistry())
+		backend, err := backends.NewWebhook(srv.URL, "", nil, backends.StreamOptions{
+			Logger:       slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}),
+			Metrics:      metrics,
+			BufferSize:   2,
+			RetryBackoff: cdrtestutil.IntervalFast,
+		})
+		require.NoError(t, err)
+		defer backend.Close()
+
+		ctx := context.Background()
+		for i := 0; i < 3; i++ {
+			require.NoError(t, backend.Export(ctx, audittest.RandomLog()))
+		}
+		// The third log doesn't fit in the buffer while the webhook is down.
+		require.Equal(t, float64(1), testutil.ToFloat64(metrics.Dropped.WithLabelValues("webhook")))
+		require.Eventually(t, func() bool {
+			return testutil.ToFloat64(metrics.Failures.WithLabelValues("webhook")) >= 2
+		}, cdrtestutil.WaitShort, cdrtestutil.IntervalFast)
+		require.Zero(t, received.Load())
+
+		down.Store(false)
+		require.Eventually(t, func() bool {
+			return received.Load() == 2
+		}, cdrtestutil.WaitShort, cdrtestutil.IntervalFast)
+		require.Eventually(t, func() bool {
+			return testutil.ToFloat64(metrics.Exported.WithLabelValues("webhook")) == 2 &&
+				testutil.ToFloat64(metrics.Buffered.WithLabelValues("webhook")) == 0
+		}, cdrtestutil.WaitShort, cdrtestutil.IntervalFast)
+	})
+
+	t.Run("DeadLetter", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts atomic.Int64
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		path := filepath.Join(t.TempDir(), "dead-letters", "webhook.jsonl")
+		metrics := backends.NewMetrics(prometheus.NewRegistry())
+		backend, err := backends.NewWebhook(srv.URL, "", nil, backends.StreamOptions{
+			Logger:         slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}),
+			Metrics:        metrics,
+			RetryBackoff:   cdrtestutil.IntervalFast,
+			MaxAttempts:    2,
+			DeadLetterPath: path,
+		})
+		require.NoError(t, err)
+		defer backend.Close()
+
+		alog := audittest.RandomLog()
+		require.NoError(t, backend.Export(context.Background(), alog))
+		require.Eventually(t, func() bool {
+			return testutil.ToFloat64(metrics.DeadLettered.WithLabelValues("webhook")) == 1
+		}, cdrtestutil.WaitShort, cdrtestutil.IntervalFast)
+		require.Equal(t, int64(2), attempts.Load())
+		require.Zero(t, testutil.ToFloat64(metrics.Buffered.WithLabelValues("webhook")))
+
+		letters := readDeadLetters(t, path)
+		require.Len(t, letters, 1)
+		require.Equal(t, "webhook", letters[0].Backend)
+		require.Equal(t, 2, letters[0].Attempts)
+		require.Contains(t, letters[0].Error, "503")
+		require.Len(t, letters[0].AuditLogs, 1)
+		require.Equal(t, alog.ID.String(), letters[0].AuditLogs[0]["id"])
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		t.Parallel()
+
+		var attempts atomic.Int64
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			rw.WriteHeader(http.StatusBadRequest)
+		}))
+		defer srv.Close()
+
+		path := filepath.Join(t.TempDir(), "webhook.jsonl")
+		metrics := backends.NewMetrics(prometheus.NewRegistry())
+		backend, err := backends.NewWebhook(srv.URL, "", nil, backends.StreamOptions{
+			Logger:         slogtest.Make(t, &slogtest.Options{IgnoreErrors: true}),
+			Metrics:        metrics,
+			RetryBackoff:   cdrtestutil.IntervalFast,
+			DeadLetterPath: path,
+		})
+		require.NoError(t, err)
+		defer backend.Close()
+
+		require.NoError(t, backend.Export(context.Background(), audittest.RandomLog()))
+		// Batches the webhook rejects aren't retried.
+		require.Eventually(t, func() bool {
+			return testutil.ToFloat64(metrics.DeadLettered.WithLabelValues("webhook")) == 1
+		}, cdrtestutil.WaitShort, cdrtestutil.IntervalFast)
+		require.Equal(t, int64(1), attempts.Load())
+		require.Len(t, readDeadLetters(t, path), 1)
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		t.Parallel()
+
+		var received atomic.Int64
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			received.Add(1)
+		}))
+		defer srv.Close()
+
+		filter, err := audit.ParseFilter([]string{"workspace"})
+		require.NoError(t, err)
+		metrics := backends.NewMetrics(prometheus.NewRegistry())
+		backend, err := backends.NewWebhook(srv.URL, "", nil, backends.StreamOptions{
+			Logger:  slogtest.Make(t, nil),
+			Metrics: metrics,
+			Filter:  filter,
+		})
+		require.NoError(t, err)
+		defer backend.Close()
+
+		ctx := context.Background()
+		// Random logs are about organizations.
+		require.NoError(t, backend.Export(ctx, audittest.RandomLog()))
+		alog := audittest.RandomLog()
+		alog.ResourceType = database.ResourceTypeWorkspace
+		require.NoError(t, backend.Export(ctx, alog))
+
+		require.Eventually(t, func() bool {
+			return testutil.ToFloat64(metrics.Exported.WithLabelValues("webhook")) == 1
+		}, cdrtestutil.WaitShort, cdrtestutil.IntervalFast)
+		require.Equal(t, int64(1), received.Load())
+	})
+
+	t.Run("InvalidURL", func(t *testing.T) {
+		t.Parallel()
+		_, err := backends.NewWebhook("ftp://example.com", "", nil, backends.StreamOptions{})
+		require.Error(t, err)
+	})
+}
+
+type deadLetter struct {
+	Backend   string           `json:"backend"`
+	Attempts  int              `json:"attempts"`
+	Error     string           `json:"error"`
+	AuditLogs []map[string]any `json:"audit_logs"`
+}
+
+func readDeadLetters(t *testing.T, path string) []deadLetter {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var letters []deadLetter
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var letter deadLetter
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &letter))
+		letters = append(letters, letter)
+	}
+	require.NoError(t, scanner.Err())
+	return letters
+}
@@ -411,6 +411,54 @@ func Test_diff(t *testing.T) {
 			},
 		},
 	})
+
+	runDiffTests(t, []diffTest{
+		{
+			name: "RolesChanged",
+			left: database.AuditableOrganizationMember{
+				OrganizationMember: database.OrganizationMember{
+					UserID:         uuid.UUID{1},
+					OrganizationID: uuid.UUID{2},
+					Roles:          []string{"organization-member:" + uuid.UUID{2}.String()},
+				},
+				Username: "colin",
+			},
+			right: database.AuditableOrganizationMember{
+				OrganizationMember: database.OrganizationMember{
+					UserID:         uuid.UUID{1},
+					OrganizationID: uuid.UUID{2},
+					UpdatedAt:      time.Now(),
+					Roles:          []string{"organization-admin:" + uuid.UUID{2}.String()},
+				},
+				Username: "colin",
+			},
+			exp: audit.Map{
+				"roles": audit.OldNew{
+					Old: []string{"organization-member:" + uuid.UUID{2}.String()},
+					New: []string{"organization-admin:" + uuid.UUID{2}.String()},
+				},
+			},
+		},
+	})
+
+	runDiffTests(t, []diffTest{
+		{
+			name: "LogoChanged",
+			left: database.AuditableAppearance{
+				DeploymentID: uuid.UUID{1},
+				LogoURL:      "https://example.com/old.png",
+			},
+			right: database.AuditableAppearance{
+				DeploymentID:  uuid.UUID{1},
+				LogoURL:       "https://example.com/new.png",
+				ServiceBanner: `{"enabled":false}`,
+			},
+			exp: audit.Map{
+				"logo_url":       audit.OldNew{Old: "https://example.com/old.png", New: "https://example.com/new.png"},
+				"service_banner": audit.OldNew{Old: "", New: `{"enabled":false}`},
+			},
+		},
+	})
 }
 
 func runDiffTests(t *testing.T, tests []diffTest) {
@@ -2,6 +2,9 @@ package audit
 
 import (
 	"context"
+	"strings"
+
+	"golang.org/x/xerrors"
 
 	"github.com/coder/coder/coderd/database"
 )
@@ -40,3 +43,44 @@ type FilterFunc func(ctx context.Context, alog database.AuditLog) (FilterDecisio
 func (f FilterFunc) Check(ctx context.Context, alog database.AuditLog) (FilterDecision, error) {
 	return f(ctx, alog)
 }
+
+// ParseFilter returns a filter that allows exporting the audit logs that match
+// any of the patterns, and storing every audit log. A pattern is a resource
+// type, optionally followed by a colon and an action, e.g. "workspace" or
+// "user:delete". Either can be "*" to match anything. Every audit log is
+// exported if there are no patterns.
+func ParseFilter(patterns []string) (Filter, error) {
+	if len(patterns) == 0 {
+		return DefaultFilter, nil
+	}
+	type pattern struct {
+		resourceType database.ResourceType
+		action       database.AuditAction
+	}
+	parsed := make([]pattern, 0, len(patterns))
+	for _, raw := range patterns {
+		resourceType, action, _ := strings.Cut(strings.TrimSpace(raw), ":")
+		if action == "" {
+			action = "*"
+		}
+		if resourceType != "*" && !database.ResourceType(resourceType).Valid() {
+			return nil, xerrors.Errorf("filter %q: unknown resource type %q", raw, resourceType)
+		}
+		if action != "*" && !database.AuditAction(action).Valid() {
+			return nil, xerrors.Errorf("filter %q: unknown action %q", raw, action)
+		}
+		parsed = append(parsed, pattern{
+			resourceType: database.ResourceType(resourceType),
+			action:       database.AuditAction(action),
+		})
+	}
+	return FilterFunc(func(_ context.Context, alog database.AuditLog) (FilterDecision, error) {
+		for _, p := range parsed {
+			if (p.resourceType == "*" || p.resourceType == alog.ResourceType) &&
+				(p.action == "*" || p.action == alog.Action) {
+				return FilterDecisionStore | FilterDecisionExport, nil
+			}
+		}
+		return FilterDecisionStore, nil
+	}), nil
+}
@@ -13,15 +13,18 @@ import (
 // AuditableResources map (below) as our documentation - generated in scripts/auditdocgen/main.go -
 // depends upon it.
 var AuditActionMap = map[string][]codersdk.AuditAction{
-	"GitSSHKey":       {codersdk.AuditActionCreate},
-	"Template":        {codersdk.AuditActionWrite, codersdk.AuditActionDelete},
-	"TemplateVersion": {codersdk.AuditActionCreate, codersdk.AuditActionWrite},
-	"User":            {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
-	"Workspace":       {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
-	"WorkspaceBuild":  {codersdk.AuditActionStart, codersdk.AuditActionStop},
-	"Group":           {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
-	"APIKey":          {codersdk.AuditActionWrite},
-	"License":         {codersdk.AuditActionCreate, codersdk.AuditActionDelete},
+	"GitSSHKey":          {codersdk.AuditActionCreate},
+	"Template":           {codersdk.AuditActionWrite, codersdk.AuditActionDelete},
+	"TemplateVersion":    {codersdk.AuditActionCreate, codersdk.AuditActionWrite},
+	"User":               {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
+	"Workspace":          {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete, codersdk.AuditActionConnect, codersdk.AuditActionDisconnect},
+	"WorkspaceBuild":     {codersdk.AuditActionStart, codersdk.AuditActionStop},
+	"Group":              {codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete},
+	"APIKey":             {codersdk.AuditActionLogin, codersdk.AuditActionLogout},
+	"License":            {codersdk.AuditActionCreate, codersdk.AuditActionDelete},
+	"Organization":       {codersdk.AuditActionCreate},
+	"OrganizationMember": {codersdk.AuditActionWrite},
+	"Appearance":         {codersdk.AuditActionWrite},
 }
 
 type Action string
@@ -70,6 +73,7 @@ var AuditableResources = auditMap(map[any]map[string]Action{
 		"group_acl":                        ActionTrack,
 		"user_acl":                         ActionTrack,
 		"allow_user_cancel_workspace_jobs": ActionTrack,
+		"deprecated":                       ActionTrack,
 	},
 	&database.TemplateVersion{}: {
 		"id":                 ActionTrack,
@@ -82,6 +86,11 @@ var AuditableResources = auditMap(map[any]map[string]Action{
 		"job_id":             ActionIgnore, // Not helpful in a diff because jobs aren't tracked in audit logs.
 		"created_by":         ActionTrack,
 		"git_auth_providers": ActionIgnore, // Not helpful because this can only change when new versions are added.
+		"archived":           ActionTrack,
+		"git_url":            ActionTrack,
+		"git_ref":            ActionTrack,
+		"git_directory":      ActionTrack,
+		"git_commit_sha":     ActionTrack,
 	},
 	&database.User{}: {
 		"id":              ActionTrack,
@@ -109,6 +118,9 @@ var AuditableResources = auditMap(map[any]map[string]Action{
 		"autostart_schedule": ActionTrack,
 		"ttl":                ActionTrack,
 		"last_used_at":       ActionIgnore,
+		"user_acl":           ActionTrack,
+		"group_acl":          ActionTrack,
+		"labels":             ActionTrack,
 	},
 	&database.WorkspaceBuild{}: {
 		"id":                  ActionIgnore,
@@ -156,6 +168,26 @@ var AuditableResources = auditMap(map[any]map[string]Action{
 		"exp":         ActionTrack,
 		"uuid":        ActionTrack,
 	},
+	&database.Organization{}: {
+		"id":          ActionTrack,
+		"name":        ActionTrack,
+		"description": ActionTrack,
+		"created_at":  ActionIgnore, // Never changes, but is implicit and not helpful in a diff.
+		"updated_at":  ActionIgnore, // Changes, but is implicit and not helpful in a diff.
+	},
+	&database.AuditableOrganizationMember{}: {
+		"user_id":         ActionTrack,
+		"organization_id": ActionIgnore, // Never changes.
+		"created_at":      ActionIgnore, // Never changes, but is implicit and not helpful in a diff.
+		"updated_at":      ActionIgnore, // Changes, but is implicit and not helpful in a diff.
+		"roles":           ActionTrack,
+		"username":        ActionTrack,
+	},
+	&database.AuditableAppearance{}: {
+		"deployment_id":  ActionIgnore, // Never changes.
+		"logo_url":       ActionTrack,
+		"service_banner": ActionTrack,
+	},
 })
 
 // auditMap converts a map of struct pointers to a map of struct names as
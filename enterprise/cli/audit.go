@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	agpl "github.com/coder/coder/cli"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+// auditDateFormat is accepted in addition to RFC 3339 times, as midnight UTC.
+const auditDateFormat = "2006-01-02"
+
+func auditLogs() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Manage audit logs",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+	cmd.AddCommand(
+		auditLogsList(),
+		auditLogsExport(),
+	)
+	return cmd
+}
+
+// auditLogTableRow is the type provided to the OutputFormatter.
+type auditLogTableRow struct {
+	// For JSON format:
+	codersdk.AuditLog `table:"-"`
+
+	// For table format:
+	Time           time.Time `json:"-" table:"time,default_sort"`
+	User           string    `json:"-" table:"user"`
+	Action         string    `json:"-" table:"action"`
+	ResourceType   string    `json:"-" table:"resource type"`
+	ResourceTarget string    `json:"-" table:"resource target"`
+	StatusCode     int32     `json:"-" table:"status code"`
+	IP             string    `json:"-" table:"ip"`
+	RequestID      string    `json:"-" table:"request id"`
+}
+
+func auditLogToRow(alog codersdk.AuditLog) auditLogTableRow {
+	row := auditLogTableRow{
+		AuditLog:       alog,
+		Time:           alog.Time,
+		Action:         string(alog.Action),
+		ResourceType:   string(alog.ResourceType),
+		ResourceTarget: alog.ResourceTarget,
+		StatusCode:     alog.StatusCode,
+		RequestID:      alog.RequestID.String(),
+	}
+	if alog.User != nil {
+		row.User = alog.User.Username
+	}
+	if alog.IP.IsValid() {
+		row.IP = alog.IP.String()
+	}
+	return row
+}
+
+func auditLogsList() *cobra.Command {
+	var (
+		search    string
+		limit     int
+		exportCSV bool
+		formatter = cliui.NewOutputFormatter(
+			cliui.TableFormat([]auditLogTableRow{}, []string{"time", "user", "action", "resource type", "resource target", "status code"}),
+			cliui.JSONFormat(),
+		)
+	)
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List audit logs",
+		Long: "List the newest audit logs that match a search query. " +
+			"The query supports the same filters as the audit page: " +
+			"resource_type, resource_id, resource_target, action, username, email, " +
+			"date_from and date_to (dates like \"2023-03-01\"), ip (an address or CIDR range), request_id and build_reason. " +
+			"Any other terms are searched for in the diffs.",
+		Example: "coder audit list --search \"action:write resource_type:template date_from:2023-03-01\"\n" +
+			"coder audit list --search \"ip:10.0.0.0/8\" --csv > audit.csv",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := agpl.CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			if exportCSV {
+				logs, err := client.AuditLogsCSV(cmd.Context(), search)
+				if err != nil {
+					return xerrors.Errorf("export audit logs: %w", err)
+				}
+				defer logs.Close()
+				_, err = io.Copy(cmd.OutOrStdout(), logs)
+				if err != nil {
+					return xerrors.Errorf("write audit logs: %w", err)
+				}
+				return nil
+			}
+
+			res, err := client.AuditLogs(cmd.Context(), codersdk.AuditLogsRequest{
+				SearchQuery: search,
+				Pagination: codersdk.Pagination{
+					Limit: limit,
+				},
+			})
+			if err != nil {
+				return xerrors.Errorf("get audit logs: %w", err)
+			}
+			if len(res.AuditLogs) == 0 {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s No audit logs found.\n", agpl.Caret)
+				return nil
+			}
+
+			rows := make([]auditLogTableRow, 0, len(res.AuditLogs))
+			for _, alog := range res.AuditLogs {
+				rows = append(rows, auditLogToRow(alog))
+			}
+			out, err := formatter.Format(cmd.Context(), rows)
+			if err != nil {
+				return xerrors.Errorf("display audit logs: %w", err)
+			}
+
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), out)
+			return nil
+		},
+	}
+	cmd.Flags().StringVarP(&search, "search", "s", "", "Search query for the audit logs.")
+	cmd.Flags().IntVarP(&limit, "limit", "l", 25, "Maximum number of audit logs to list. The newest are listed.")
+	cmd.Flags().BoolVar(&exportCSV, "csv", false, "Write every audit log that matches --search as CSV, newest first. --limit and --output are ignored.")
+	formatter.AttachFlags(cmd)
+	return cmd
+}
+
+func auditLogsExport() *cobra.Command {
+	var (
+		since  string
+		until  string
+		output string
+	)
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the audit logs of a time range as JSON lines",
+		Long: "Export the audit logs from --since until before --until as JSON lines, in the order they happened. " +
+			"Audit logs that were archived after the retention period are included. " +
+			"Times are in RFC 3339 format, e.g. \"2023-03-01T15:04:05Z\", or dates like \"2023-03-01\", which are midnight UTC.",
+		Example: "coder audit export --since 2023-01-01 --until 2023-04-01 --output audit-q1.ndjson",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sinceTime, err := parseAuditTime(since)
+			if err != nil {
+				return xerrors.Errorf("parse --since: %w", err)
+			}
+			untilTime, err := parseAuditTime(until)
+			if err != nil {
+				return xerrors.Errorf("parse --until: %w", err)
+			}
+
+			client, err := agpl.CreateClient(cmd)
+			if err != nil {
+				return err
+			}
+			logs, err := client.ExportAuditLogs(cmd.Context(), sinceTime, untilTime)
+			if err != nil {
+				return xerrors.Errorf("export audit logs: %w", err)
+			}
+			defer logs.Close()
+
+			if output == "" {
+				_, err = io.Copy(cmd.OutOrStdout(), logs)
+				if err != nil {
+					return xerrors.Errorf("write audit logs: %w", err)
+				}
+				return nil
+			}
+			file, err := os.OpenFile(output, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+			if err != nil {
+				return xerrors.Errorf("create output file: %w", err)
+			}
+			defer file.Close()
+			_, err = io.Copy(file, logs)
+			if err != nil {
+				return xerrors.Errorf("write audit logs: %w", err)
+			}
+			err = file.Close()
+			if err != nil {
+				return xerrors.Errorf("close output file: %w", err)
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Exported audit logs to %s\n", cliui.Styles.Keyword.Render(output))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "", "Export audit logs from this time. Every audit log before --until is exported if empty.")
+	cmd.Flags().StringVar(&until, "until", "", "Export audit logs before this time. Defaults to now.")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "File to write the audit logs to. It must not exist, so an export for a legal hold isn't overwritten. Audit logs are written to stdout if empty.")
+	return cmd
+}
+
+// parseAuditTime parses an RFC 3339 time or a date. The zero time is returned
+// if value is empty.
+func parseAuditTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return t, nil
+	}
+	t, err = time.Parse(auditDateFormat, value)
+	if err != nil {
+		return time.Time{}, xerrors.Errorf("%q must be an RFC 3339 time or a date like %q", value, auditDateFormat)
+	}
+	return t, nil
+}
@@ -0,0 +1,169 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/cli"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestAuditExport(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	client := coderdenttest.New(t, nil)
+	user := coderdtest.CreateFirstUser(t, client)
+	for _, date := range []string{"2023-01-15", "2023-02-15", "2023-03-15"} {
+		logTime, err := time.Parse("2006-01-02", date)
+		require.NoError(t, err)
+		err = client.CreateTestAuditLog(ctx, codersdk.CreateTestAuditLogRequest{
+			ResourceID: user.UserID,
+			Time:       logTime,
+		})
+		require.NoError(t, err)
+	}
+	times := func(data []byte) []string {
+		t.Helper()
+		var logTimes []string
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		for decoder.More() {
+			var log struct {
+				Time time.Time `json:"time"`
+			}
+			require.NoError(t, decoder.Decode(&log))
+			logTimes = append(logTimes, log.Time.UTC().Format("2006-01-02"))
+		}
+		return logTimes
+	}
+
+	t.Run("Stdout", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		cmd, root := clitest.NewWithSubcommands(t, cli.EnterpriseSubcommands(), "audit", "export", "--since", "2023-02-01", "--until", "2023-03-15T00:00:00Z")
+		clitest.SetupConfig(t, client, root)
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		require.NoError(t, cmd.ExecuteContext(ctx))
+		// --until is excluded from the range.
+		require.Equal(t, []string{"2023-02-15"}, times(out.Bytes()))
+	})
+
+	t.Run("Output", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		output := filepath.Join(t.TempDir(), "audit.ndjson")
+		cmd, root := clitest.NewWithSubcommands(t, cli.EnterpriseSubcommands(), "audit", "export", "--until", "2023-04-01", "--output", output)
+		clitest.SetupConfig(t, client, root)
+		require.NoError(t, cmd.ExecuteContext(ctx))
+		data, err := os.ReadFile(output)
+		require.NoError(t, err)
+		require.Equal(t, []string{"2023-01-15", "2023-02-15", "2023-03-15"}, times(data))
+
+		// Existing exports aren't overwritten.
+		cmd, root = clitest.NewWithSubcommands(t, cli.EnterpriseSubcommands(), "audit", "export", "--output", output)
+		clitest.SetupConfig(t, client, root)
+		require.Error(t, cmd.ExecuteContext(ctx))
+	})
+
+	t.Run("InvalidTime", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		cmd, root := clitest.NewWithSubcommands(t, cli.EnterpriseSubcommands(), "audit", "export", "--since", "yesterday")
+		clitest.SetupConfig(t, client, root)
+		require.ErrorContains(t, cmd.ExecuteContext(ctx), "RFC 3339")
+	})
+}
+
+func TestAuditList(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	client := coderdenttest.New(t, nil)
+	user := coderdtest.CreateFirstUser(t, client)
+	for _, action := range []codersdk.AuditAction{codersdk.AuditActionCreate, codersdk.AuditActionWrite, codersdk.AuditActionDelete} {
+		err := client.CreateTestAuditLog(ctx, codersdk.CreateTestAuditLogRequest{
+			Action:     action,
+			ResourceID: user.UserID,
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("Table", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		cmd, root := clitest.NewWithSubcommands(t, cli.EnterpriseSubcommands(), "audit", "list", "--search", "action:write")
+		clitest.SetupConfig(t, client, root)
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		require.NoError(t, cmd.ExecuteContext(ctx))
+		require.Contains(t, out.String(), "RESOURCE TARGET")
+		require.Contains(t, out.String(), "write")
+		require.NotContains(t, out.String(), "delete")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		cmd, root := clitest.NewWithSubcommands(t, cli.EnterpriseSubcommands(), "audit", "list", "--search", "baz", "--limit", "2", "--output", "json")
+		clitest.SetupConfig(t, client, root)
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		require.NoError(t, cmd.ExecuteContext(ctx))
+		var logs []codersdk.AuditLog
+		require.NoError(t, json.Unmarshal(out.Bytes(), &logs))
+		require.Len(t, logs, 2)
+		// The newest are listed.
+		require.Equal(t, codersdk.AuditActionDelete, logs[0].Action)
+		require.Equal(t, codersdk.AuditActionWrite, logs[1].Action)
+	})
+
+	t.Run("CSV", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+		defer cancel()
+
+		cmd, root := clitest.NewWithSubcommands(t, cli.EnterpriseSubcommands(), "audit", "list", "--search", "resource_type:user", "--limit", "1", "--csv")
+		clitest.SetupConfig(t, client, root)
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		require.NoError(t, cmd.ExecuteContext(ctx))
+		records, err := csv.NewReader(&out).ReadAll()
+		require.NoError(t, err)
+		// The header and every audit log, regardless of --limit.
+		require.Len(t, records, 4)
+		require.Equal(t, "time", records[0][0])
+	})
+}
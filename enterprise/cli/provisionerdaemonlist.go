@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	agpl "github.com/coder/coder/cli"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func provisionerDaemonList() *cobra.Command {
+	formatter := cliui.NewOutputFormatter(
+		cliui.TableFormat([]provisionerDaemonTableRow{}, []string{"name", "status", "last seen", "version", "provisioners", "tags", "current job"}),
+		cliui.JSONFormat(),
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the provisioner daemons of the organization",
+		Long:  "List the provisioner daemons of the organization. Daemons are offline when they haven't polled for jobs for a minute.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			client, err := agpl.CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create client: %w", err)
+			}
+			org, err := agpl.CurrentOrganization(cmd, client)
+			if err != nil {
+				return xerrors.Errorf("current organization: %w", err)
+			}
+
+			daemons, err := client.ProvisionerDaemons(ctx, org.ID)
+			if err != nil {
+				return xerrors.Errorf("get provisioner daemons: %w", err)
+			}
+			if len(daemons) == 0 {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s No provisioner daemons have connected to %s.\n", agpl.Caret, org.Name)
+				return nil
+			}
+
+			rows := make([]provisionerDaemonTableRow, 0, len(daemons))
+			for _, daemon := range daemons {
+				rows = append(rows, provisionerDaemonToRow(daemon, time.Now()))
+			}
+			out, err := formatter.Format(ctx, rows)
+			if err != nil {
+				return xerrors.Errorf("display provisioner daemons: %w", err)
+			}
+
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), out)
+			return nil
+		},
+	}
+
+	formatter.AttachFlags(cmd)
+	return cmd
+}
+
+type provisionerDaemonTableRow struct {
+	// For json output:
+	codersdk.ProvisionerDaemon `table:"-"`
+
+	// For table output:
+	DaemonName       string    `json:"-" table:"name,default_sort"`
+	DaemonID         uuid.UUID `json:"-" table:"id"`
+	DaemonStatus     string    `json:"-" table:"status"`
+	LastSeen         string    `json:"-" table:"last seen"`
+	DaemonVersion    string    `json:"-" table:"version"`
+	ProvisionerTypes string    `json:"-" table:"provisioners"`
+	DaemonTags       string    `json:"-" table:"tags"`
+	CurrentJob       string    `json:"-" table:"current job"`
+}
+
+func provisionerDaemonToRow(daemon codersdk.ProvisionerDaemon, now time.Time) provisionerDaemonTableRow {
+	row := provisionerDaemonTableRow{
+		ProvisionerDaemon: daemon,
+		DaemonName:        daemon.Name,
+		DaemonID:          daemon.ID,
+		DaemonStatus:      string(daemon.Status),
+		LastSeen:          "never",
+		DaemonVersion:     daemon.Version,
+	}
+	if daemon.LastSeenAt != nil {
+		row.LastSeen = fmt.Sprintf("%s ago", now.Sub(*daemon.LastSeenAt).Truncate(time.Second))
+	}
+	if row.DaemonVersion == "" {
+		row.DaemonVersion = "unknown"
+	}
+	provisioners := make([]string, 0, len(daemon.Provisioners))
+	for _, provisioner := range daemon.Provisioners {
+		provisioners = append(provisioners, string(provisioner))
+	}
+	sort.Strings(provisioners)
+	row.ProvisionerTypes = strings.Join(provisioners, ",")
+	tags := make([]string, 0, len(daemon.Tags))
+	for key, value := range daemon.Tags {
+		tags = append(tags, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(tags)
+	row.DaemonTags = strings.Join(tags, " ")
+	if daemon.CurrentJobID != nil {
+		row.CurrentJob = daemon.CurrentJobID.String()
+	}
+	return row
+}
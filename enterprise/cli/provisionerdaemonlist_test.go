@@ -0,0 +1,102 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/buildinfo"
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/cli"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/enterprise/coderd/license"
+	"github.com/coder/coder/pty/ptytest"
+)
+
+func TestProvisionerDaemonList(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Table", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		admin := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureExternalProvisionerDaemons: 1,
+			},
+		})
+		closer := coderdtest.NewExternalProvisionerDaemon(t, client, admin.OrganizationID, nil)
+		defer closer.Close()
+
+		cmd, root := clitest.NewWithSubcommands(t, cli.EnterpriseSubcommands(), "provisionerd", "list")
+		pty := ptytest.New(t)
+		cmd.SetOut(pty.Output())
+		clitest.SetupConfig(t, client, root)
+
+		err := cmd.Execute()
+		require.NoError(t, err)
+
+		matches := []string{
+			"NAME", "STATUS", "LAST SEEN", "VERSION", "PROVISIONERS", "TAGS", "CURRENT JOB",
+			"online", buildinfo.Version(), "echo", "scope=organization",
+		}
+		for _, match := range matches {
+			pty.ExpectMatch(match)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		admin := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureExternalProvisionerDaemons: 1,
+			},
+		})
+		closer := coderdtest.NewExternalProvisionerDaemon(t, client, admin.OrganizationID, nil)
+		defer closer.Close()
+
+		cmd, root := clitest.NewWithSubcommands(t, cli.EnterpriseSubcommands(), "provisionerd", "list", "--output", "json")
+		out := bytes.NewBuffer(nil)
+		cmd.SetOut(out)
+		clitest.SetupConfig(t, client, root)
+
+		err := cmd.Execute()
+		require.NoError(t, err)
+
+		var daemons []codersdk.ProvisionerDaemon
+		require.NoError(t, json.Unmarshal(out.Bytes(), &daemons))
+		require.Len(t, daemons, 1)
+		require.Equal(t, codersdk.ProvisionerDaemonOnline, daemons[0].Status)
+		require.Equal(t, buildinfo.Version(), daemons[0].Version)
+	})
+
+	t.Run("NoDaemons", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		_ = coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureExternalProvisionerDaemons: 1,
+			},
+		})
+
+		cmd, root := clitest.NewWithSubcommands(t, cli.EnterpriseSubcommands(), "provisionerd", "list")
+		pty := ptytest.New(t)
+		cmd.SetErr(pty.Output())
+		clitest.SetupConfig(t, client, root)
+
+		err := cmd.Execute()
+		require.NoError(t, err)
+
+		pty.ExpectMatch("No provisioner daemons have connected")
+	})
+}
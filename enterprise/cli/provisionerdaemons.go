@@ -7,6 +7,7 @@ import (
 	"os/signal"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 	"golang.org/x/xerrors"
 
@@ -30,7 +31,11 @@ func provisionerDaemons() *cobra.Command {
 		Use:   "provisionerd",
 		Short: "Manage provisioner daemons",
 	}
-	cmd.AddCommand(provisionerDaemonStart())
+	cmd.AddCommand(
+		provisionerDaemonStart(),
+		provisionerDaemonList(),
+		provisionerKeys(),
+	)
 
 	return cmd
 }
@@ -41,6 +46,7 @@ func provisionerDaemonStart() *cobra.Command {
 		rawTags      []string
 		pollInterval time.Duration
 		pollJitter   time.Duration
+		key          string
 	)
 	cmd := &cobra.Command{
 		Use:   "start",
@@ -52,13 +58,28 @@ func provisionerDaemonStart() *cobra.Command {
 			notifyCtx, notifyStop := signal.NotifyContext(ctx, agpl.InterruptSignals...)
 			defer notifyStop()
 
-			client, err := agpl.CreateClient(cmd)
-			if err != nil {
-				return xerrors.Errorf("create client: %w", err)
-			}
-			org, err := agpl.CurrentOrganization(cmd, client)
-			if err != nil {
-				return xerrors.Errorf("get current organization: %w", err)
+			// Daemons that use a provisioner key serve the organization of
+			// the key, and don't need a session.
+			var (
+				client *codersdk.Client
+				orgID  uuid.UUID
+				err    error
+			)
+			if key != "" {
+				client, err = agpl.CreateUnauthenticatedClient(cmd)
+				if err != nil {
+					return xerrors.Errorf("create client: %w", err)
+				}
+			} else {
+				client, err = agpl.CreateClient(cmd)
+				if err != nil {
+					return xerrors.Errorf("create client: %w", err)
+				}
+				org, err := agpl.CurrentOrganization(cmd, client)
+				if err != nil {
+					return xerrors.Errorf("get current organization: %w", err)
+				}
+				orgID = org.ID
 			}
 
 			tags, err := agpl.ParseProvisionerTags(rawTags)
@@ -109,9 +130,14 @@ func provisionerDaemonStart() *cobra.Command {
 				string(database.ProvisionerTypeTerraform): proto.NewDRPCProvisionerClient(terraformClient),
 			}
 			srv := provisionerd.New(func(ctx context.Context) (provisionerdproto.DRPCProvisionerDaemonClient, error) {
-				return client.ServeProvisionerDaemon(ctx, org.ID, []codersdk.ProvisionerType{
-					codersdk.ProvisionerTypeTerraform,
-				}, tags)
+				return client.ServeProvisionerDaemon(ctx, codersdk.ServeProvisionerDaemonRequest{
+					Organization: orgID,
+					Provisioners: []codersdk.ProvisionerType{
+						codersdk.ProvisionerTypeTerraform,
+					},
+					Tags:           tags,
+					ProvisionerKey: key,
+				})
 			}, &provisionerd.Options{
 				Logger:          logger,
 				JobPollInterval: pollInterval,
@@ -157,6 +183,8 @@ func provisionerDaemonStart() *cobra.Command {
 		"Specify the interval for which the provisioner daemon should poll for jobs.")
 	cliflag.DurationVarP(cmd.Flags(), &pollJitter, "poll-jitter", "", "CODER_PROVISIONERD_POLL_JITTER", 100*time.Millisecond,
 		"Random jitter added to the poll interval.")
+	cliflag.StringVarP(cmd.Flags(), &key, "key", "", "CODER_PROVISIONERD_KEY", "",
+		"Authenticate with a provisioner key instead of a session token. The daemon serves the organization of the key and is given its tags.")
 
 	return cmd
 }
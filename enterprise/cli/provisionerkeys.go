@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"golang.org/x/xerrors"
+
+	agpl "github.com/coder/coder/cli"
+	"github.com/coder/coder/cli/cliflag"
+	"github.com/coder/coder/cli/cliui"
+	"github.com/coder/coder/codersdk"
+)
+
+func provisionerKeys() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keys",
+		Short: "Manage the keys external provisioner daemons authenticate with",
+		Long: "Provisioner keys authenticate external provisioner daemons without a user session. " +
+			"A daemon that uses a key serves the organization of the key and is given its tags.",
+	}
+	cmd.AddCommand(
+		provisionerKeyCreate(),
+		provisionerKeyList(),
+		provisionerKeyDelete(),
+	)
+
+	return cmd
+}
+
+func provisionerKeyCreate() *cobra.Command {
+	var rawTags []string
+	cmd := &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a provisioner key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			client, err := agpl.CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create client: %w", err)
+			}
+			org, err := agpl.CurrentOrganization(cmd, client)
+			if err != nil {
+				return xerrors.Errorf("current organization: %w", err)
+			}
+			tags, err := agpl.ParseProvisionerTags(rawTags)
+			if err != nil {
+				return err
+			}
+
+			res, err := client.CreateProvisionerKey(ctx, org.ID, codersdk.CreateProvisionerKeyRequest{
+				Name: args[0],
+				Tags: tags,
+			})
+			if err != nil {
+				return xerrors.Errorf("create provisioner key: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Successfully created provisioner key %s! The key won't be shown again, "+
+				"start daemons with it using %s.\n",
+				cliui.Styles.Keyword.Render(args[0]), cliui.Styles.Code.Render("coder provisionerd start --key <key>"))
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), res.Key)
+			return nil
+		},
+	}
+
+	cliflag.StringArrayVarP(cmd.Flags(), &rawTags, "tag", "t", "", []string{},
+		"Specify the tags daemons using the key are given.")
+
+	return cmd
+}
+
+func provisionerKeyList() *cobra.Command {
+	formatter := cliui.NewOutputFormatter(
+		cliui.TableFormat([]provisionerKeyTableRow{}, []string{"name", "created at", "tags"}),
+		cliui.JSONFormat(),
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the provisioner keys of the organization",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			client, err := agpl.CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create client: %w", err)
+			}
+			org, err := agpl.CurrentOrganization(cmd, client)
+			if err != nil {
+				return xerrors.Errorf("current organization: %w", err)
+			}
+
+			keys, err := client.ProvisionerKeys(ctx, org.ID)
+			if err != nil {
+				return xerrors.Errorf("get provisioner keys: %w", err)
+			}
+			if len(keys) == 0 {
+				_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "%s No provisioner keys found in %s.\n", agpl.Caret, org.Name)
+				return nil
+			}
+
+			rows := make([]provisionerKeyTableRow, 0, len(keys))
+			for _, key := range keys {
+				rows = append(rows, provisionerKeyToRow(key))
+			}
+			out, err := formatter.Format(ctx, rows)
+			if err != nil {
+				return xerrors.Errorf("display provisioner keys: %w", err)
+			}
+
+			_, _ = fmt.Fprintln(cmd.OutOrStdout(), out)
+			return nil
+		},
+	}
+
+	formatter.AttachFlags(cmd)
+	return cmd
+}
+
+func provisionerKeyDelete() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete <name>",
+		Short: "Delete a provisioner key",
+		Long:  "Delete a provisioner key. Daemons that use the key are disconnected and can't reconnect.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			client, err := agpl.CreateClient(cmd)
+			if err != nil {
+				return xerrors.Errorf("create client: %w", err)
+			}
+			org, err := agpl.CurrentOrganization(cmd, client)
+			if err != nil {
+				return xerrors.Errorf("current organization: %w", err)
+			}
+
+			err = client.DeleteProvisionerKey(ctx, org.ID, args[0])
+			if err != nil {
+				return xerrors.Errorf("delete provisioner key: %w", err)
+			}
+
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Successfully deleted provisioner key %s!\n", cliui.Styles.Keyword.Render(args[0]))
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+type provisionerKeyTableRow struct {
+	// For json output:
+	codersdk.ProvisionerKey `table:"-"`
+
+	// For table output:
+	KeyName      string    `json:"-" table:"name,default_sort"`
+	KeyID        uuid.UUID `json:"-" table:"id"`
+	KeyCreatedAt time.Time `json:"-" table:"created at"`
+	KeyTags      string    `json:"-" table:"tags"`
+}
+
+func provisionerKeyToRow(key codersdk.ProvisionerKey) provisionerKeyTableRow {
+	tags := make([]string, 0, len(key.Tags))
+	for name, value := range key.Tags {
+		tags = append(tags, fmt.Sprintf("%s=%s", name, value))
+	}
+	sort.Strings(tags)
+	return provisionerKeyTableRow{
+		ProvisionerKey: key,
+		KeyName:        key.Name,
+		KeyID:          key.ID,
+		KeyCreatedAt:   key.CreatedAt,
+		KeyTags:        strings.Join(tags, " "),
+	}
+}
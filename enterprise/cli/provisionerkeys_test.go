@@ -0,0 +1,113 @@
+package cli_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/cli/clitest"
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/cli"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/enterprise/coderd/license"
+	"github.com/coder/coder/pty/ptytest"
+	"github.com/coder/coder/testutil"
+)
+
+func TestProvisionerKeys(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Create", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		admin := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureExternalProvisionerDaemons: 1,
+			},
+		})
+
+		cmd, root := clitest.NewWithSubcommands(t, cli.EnterpriseSubcommands(),
+			"provisionerd", "keys", "create", "builders", "--tag", "region=eu",
+		)
+		out := bytes.NewBuffer(nil)
+		cmd.SetOut(out)
+		clitest.SetupConfig(t, client, root)
+
+		err := cmd.Execute()
+		require.NoError(t, err)
+		require.NotEmpty(t, strings.TrimSpace(out.String()))
+
+		ctx, _ := testutil.Context(t)
+		keys, err := client.ProvisionerKeys(ctx, admin.OrganizationID)
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		require.Equal(t, "builders", keys[0].Name)
+		require.Equal(t, "eu", keys[0].Tags["region"])
+	})
+
+	t.Run("List", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		admin := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureExternalProvisionerDaemons: 1,
+			},
+		})
+		ctx, _ := testutil.Context(t)
+		_, err := client.CreateProvisionerKey(ctx, admin.OrganizationID, codersdk.CreateProvisionerKeyRequest{
+			Name: "builders",
+			Tags: map[string]string{"region": "eu"},
+		})
+		require.NoError(t, err)
+
+		cmd, root := clitest.NewWithSubcommands(t, cli.EnterpriseSubcommands(), "provisionerd", "keys", "list")
+		pty := ptytest.New(t)
+		cmd.SetOut(pty.Output())
+		clitest.SetupConfig(t, client, root)
+
+		err = cmd.Execute()
+		require.NoError(t, err)
+
+		matches := []string{"NAME", "CREATED AT", "TAGS", "builders", "region=eu scope=organization"}
+		for _, match := range matches {
+			pty.ExpectMatch(match)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		t.Parallel()
+
+		client := coderdenttest.New(t, nil)
+		admin := coderdtest.CreateFirstUser(t, client)
+		_ = coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureExternalProvisionerDaemons: 1,
+			},
+		})
+		ctx, _ := testutil.Context(t)
+		_, err := client.CreateProvisionerKey(ctx, admin.OrganizationID, codersdk.CreateProvisionerKeyRequest{
+			Name: "builders",
+		})
+		require.NoError(t, err)
+
+		cmd, root := clitest.NewWithSubcommands(t, cli.EnterpriseSubcommands(), "provisionerd", "keys", "delete", "builders")
+		pty := ptytest.New(t)
+		cmd.SetOut(pty.Output())
+		clitest.SetupConfig(t, client, root)
+
+		err = cmd.Execute()
+		require.NoError(t, err)
+		pty.ExpectMatch("Successfully deleted provisioner key")
+
+		keys, err := client.ProvisionerKeys(ctx, admin.OrganizationID)
+		require.NoError(t, err)
+		require.Empty(t, keys)
+	})
+}
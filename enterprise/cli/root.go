@@ -13,6 +13,7 @@ func enterpriseOnly() []*cobra.Command {
 		licenses(),
 		groups(),
 		provisionerDaemons(),
+		auditLogs(),
 	}
 }
 
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"io"
 	"net/url"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 	"golang.org/x/xerrors"
@@ -53,12 +54,35 @@ func server() *cobra.Command {
 		}
 		options.DERPServer.SetMeshKey(meshKey)
 
+		var streams []*backends.StreamBackend
 		if options.DeploymentConfig.AuditLogging.Value {
-			options.Auditor = audit.NewAuditor(audit.DefaultFilter,
+			streams, err = auditStreams(options)
+			if err != nil {
+				return nil, nil, err
+			}
+			auditBackends := []audit.Backend{
 				backends.NewPostgres(options.Database, true),
 				backends.NewSlog(options.Logger),
-			)
+			}
+			for _, stream := range streams {
+				auditBackends = append(auditBackends, stream)
+			}
+			options.Auditor = audit.NewAuditor(audit.DefaultFilter, auditBackends...)
+		}
+		closeStreams := func() {
+			for _, stream := range streams {
+				_ = stream.Close()
+			}
+		}
+
+		retention := options.DeploymentConfig.AuditLogRetention
+		if retention.Archive.Value && options.FileStore == nil {
+			closeStreams()
+			return nil, nil, xerrors.New("audit log archives are stored in the file storage, so --audit-log-retention-archive requires a file storage backend other than \"database\"")
 		}
+		options.AuditLogRetention = retention.Period.Value
+		options.AuditLogArchive = retention.Archive.Value
+		options.AuditLogPurgeBatchSize = retention.BatchSize.Value
 
 		options.TrialGenerator = trialer.New(options.Database, "https://v2-licensor.coder.com/trial", coderd.Keys)
 
@@ -75,12 +99,93 @@ func server() *cobra.Command {
 
 		api, err := coderd.New(ctx, o)
 		if err != nil {
+			closeStreams()
 			return nil, nil, err
 		}
-		return api.AGPL, api, nil
+		return api.AGPL, closeFunc(func() error {
+			err := api.Close()
+			// Close the streams after the API, so the audit logs of the last
+			// requests are streamed.
+			closeStreams()
+			return err
+		}), nil
 	})
 
 	deployment.AttachFlags(cmd.Flags(), vip, true)
 
 	return cmd
 }
+
+// auditStreams creates the audit log streaming backends that are configured.
+func auditStreams(options *agplcoderd.Options) ([]*backends.StreamBackend, error) {
+	cfg := options.DeploymentConfig.AuditStreaming
+	metrics := backends.NewMetrics(options.PrometheusRegistry)
+	streamOptions := func(name string, patterns []string) (backends.StreamOptions, error) {
+		filter, err := audit.ParseFilter(patterns)
+		if err != nil {
+			return backends.StreamOptions{}, xerrors.Errorf("audit streaming %s filter: %w", name, err)
+		}
+		return backends.StreamOptions{
+			Logger:     options.Logger.Named("audit_stream"),
+			Metrics:    metrics,
+			Filter:     filter,
+			BufferSize: cfg.BufferSize.Value,
+			// The cache directory is kept across restarts, unlike the
+			// directory of the process.
+			DeadLetterPath: filepath.Join(options.DeploymentConfig.CacheDirectory.Value, "audit-streaming", name+"-dead-letters.jsonl"),
+		}, nil
+	}
+
+	var streams []*backends.StreamBackend
+	closeAll := func() {
+		for _, stream := range streams {
+			_ = stream.Close()
+		}
+	}
+	if cfg.SyslogAddress.Value != "" {
+		opts, err := streamOptions("syslog", cfg.SyslogFilter.Value)
+		if err != nil {
+			return nil, err
+		}
+		stream, err := backends.NewSyslog(cfg.SyslogAddress.Value, opts)
+		if err != nil {
+			return nil, err
+		}
+		streams = append(streams, stream)
+	}
+	if cfg.WebhookURL.Value != "" {
+		opts, err := streamOptions("webhook", cfg.WebhookFilter.Value)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		opts.BatchSize = cfg.WebhookBatchSize.Value
+		opts.FlushInterval = cfg.WebhookFlushInterval.Value
+		stream, err := backends.NewWebhook(cfg.WebhookURL.Value, cfg.WebhookSecret.Value, nil, opts)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		streams = append(streams, stream)
+	}
+	if cfg.FilePath.Value != "" {
+		opts, err := streamOptions("file", cfg.FileFilter.Value)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		stream, err := backends.NewFile(cfg.FilePath.Value, cfg.FileMaxSize.Value, cfg.FileMaxBackups.Value, opts)
+		if err != nil {
+			closeAll()
+			return nil, err
+		}
+		streams = append(streams, stream)
+	}
+	return streams, nil
+}
+
+type closeFunc func() error
+
+func (c closeFunc) Close() error {
+	return c()
+}
@@ -1,6 +1,7 @@
 package coderd
 
 import (
+	"context"
 	"database/sql"
 	"encoding/hex"
 	"encoding/json"
@@ -8,8 +9,11 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/google/uuid"
 	"golang.org/x/xerrors"
 
+	"github.com/coder/coder/coderd/audit"
+	"github.com/coder/coder/coderd/database"
 	"github.com/coder/coder/coderd/httpapi"
 	"github.com/coder/coder/coderd/rbac"
 	"github.com/coder/coder/codersdk"
@@ -117,7 +121,17 @@ func validateHexColor(color string) error {
 // @Success 200 {object} codersdk.UpdateAppearanceConfig
 // @Router /appearance [put]
 func (api *API) putAppearance(rw http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
+	var (
+		ctx               = r.Context()
+		auditor           = api.AGPL.Auditor.Load()
+		aReq, commitAudit = audit.InitRequest[database.AuditableAppearance](rw, &audit.RequestParams{
+			Audit:   *auditor,
+			Log:     api.Logger,
+			Request: r,
+			Action:  database.AuditActionWrite,
+		})
+	)
+	defer commitAudit()
 
 	if !api.Authorize(r, rbac.ActionUpdate, rbac.ResourceDeploymentConfig) {
 		httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
@@ -126,6 +140,16 @@ func (api *API) putAppearance(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	old, err := api.auditableAppearance(ctx)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Failed to fetch appearance.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	aReq.Old = old
+
 	var appearance codersdk.UpdateAppearanceConfig
 	if !httpapi.Read(ctx, rw, r, &appearance) {
 		return
@@ -164,5 +188,38 @@ func (api *API) putAppearance(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	aReq.New = database.AuditableAppearance{
+		DeploymentID:  old.DeploymentID,
+		LogoURL:       appearance.LogoURL,
+		ServiceBanner: string(serviceBannerJSON),
+	}
+
 	httpapi.Write(r.Context(), rw, http.StatusOK, appearance)
 }
+
+// auditableAppearance returns the stored appearance configuration in the
+// form that is recorded in audit logs.
+func (api *API) auditableAppearance(ctx context.Context) (database.AuditableAppearance, error) {
+	var appearance database.AuditableAppearance
+
+	deploymentID, err := api.Database.GetDeploymentID(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return appearance, xerrors.Errorf("get deployment ID: %w", err)
+	}
+	// The deployment ID is only missing when coderd is run without the
+	// server command (e.g. in tests), in which case there is nothing to
+	// identify the resource by and the change goes unaudited.
+	appearance.DeploymentID, _ = uuid.Parse(deploymentID)
+
+	appearance.LogoURL, err = api.Database.GetLogoURL(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return appearance, xerrors.Errorf("get logo URL: %w", err)
+	}
+
+	appearance.ServiceBanner, err = api.Database.GetServiceBanner(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return appearance, xerrors.Errorf("get service banner: %w", err)
+	}
+
+	return appearance, nil
+}
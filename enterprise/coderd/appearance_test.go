@@ -6,9 +6,13 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
+	"github.com/coder/coder/coderd/audit"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbtestutil"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/enterprise/coderd"
 	"github.com/coder/coder/enterprise/coderd/coderdenttest"
@@ -74,6 +78,51 @@ func TestServiceBanners(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestUpdateAppearance(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Audit", func(t *testing.T) {
+		t.Parallel()
+
+		db, pubsub := dbtestutil.NewDB(t)
+		deploymentID := uuid.New()
+		err := db.InsertDeploymentID(context.Background(), deploymentID.String())
+		require.NoError(t, err)
+
+		auditor := audit.NewMock()
+		client := coderdenttest.New(t, &coderdenttest.Options{
+			AuditLogging: true,
+			Options: &coderdtest.Options{
+				Database: db,
+				Pubsub:   pubsub,
+				Auditor:  auditor,
+			},
+		})
+		coderdtest.CreateFirstUser(t, client)
+		coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureAppearance: 1,
+				codersdk.FeatureAuditLog:   1,
+			},
+		})
+
+		ctx, _ := testutil.Context(t)
+
+		numLogs := len(auditor.AuditLogs)
+		err = client.UpdateAppearance(ctx, codersdk.UpdateAppearanceConfig{
+			LogoURL: "https://example.com/logo.png",
+		})
+		require.NoError(t, err)
+		numLogs++
+
+		require.Len(t, auditor.AuditLogs, numLogs)
+		alog := auditor.AuditLogs[numLogs-1]
+		require.Equal(t, database.AuditActionWrite, alog.Action)
+		require.Equal(t, database.ResourceTypeAppearance, alog.ResourceType)
+		require.Equal(t, deploymentID, alog.ResourceID)
+	})
+}
+
 func TestCustomSupportLinks(t *testing.T) {
 	t.Parallel()
 
@@ -105,6 +105,22 @@ func New(ctx context.Context, options *Options) (*API, error) {
 			r.Get("/", api.provisionerDaemons)
 			r.Get("/serve", api.provisionerDaemonServe)
 		})
+		r.Route("/organizations/{organization}/provisionerkeys", func(r chi.Router) {
+			r.Use(
+				api.provisionerDaemonsEnabledMW,
+				apiKeyMiddleware,
+				httpmw.ExtractOrganizationParam(api.Database),
+			)
+			r.Get("/", api.provisionerKeys)
+			r.Post("/", api.postProvisionerKey)
+			r.Delete("/{provisionerkey}", api.deleteProvisionerKey)
+		})
+		// Daemons that connect with a provisioner key are authenticated
+		// by the key instead of a session token.
+		r.Route("/provisionerdaemons", func(r chi.Router) {
+			r.Use(api.provisionerDaemonsEnabledMW)
+			r.Get("/serve", api.provisionerKeyDaemonServe)
+		})
 		r.Route("/templates/{template}/acl", func(r chi.Router) {
 			r.Use(
 				api.templateRBACEnabledMW,
@@ -49,15 +49,26 @@ func TestAuthorizeAllEndpoints(t *testing.T) {
 	})
 	require.NoError(t, err)
 
+	_, err = client.CreateProvisionerKey(ctx, admin.OrganizationID, codersdk.CreateProvisionerKeyRequest{
+		Name: "testkey",
+	})
+	require.NoError(t, err)
+	keys, err := client.ProvisionerKeys(ctx, admin.OrganizationID)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+
 	groupObj := rbac.ResourceGroup.WithID(group.ID).InOrg(admin.OrganizationID)
+	keyObj := rbac.ResourceProvisionerKey.WithID(keys[0].ID).InOrg(admin.OrganizationID)
 	a := coderdtest.NewAuthTester(ctx, t, client, api.AGPL, admin)
 	a.URLParams["licenses/{id}"] = fmt.Sprintf("licenses/%d", lic.ID)
 	a.URLParams["groups/{group}"] = fmt.Sprintf("groups/%s", group.ID.String())
 	a.URLParams["{groupName}"] = group.Name
+	a.URLParams["{provisionerkey}"] = keys[0].Name
 
 	skipRoutes, assertRoute := coderdtest.AGPLRoutes(a)
 	skipRoutes["GET:/api/v2/organizations/{organization}/provisionerdaemons/serve"] = "This route checks for RBAC dependent on input parameters!"
 	skipRoutes["GET:/api/v2/appearance/"] = "This route is available to all users"
+	skipRoutes["GET:/api/v2/provisionerdaemons/serve"] = "This route is authenticated with a provisioner key!"
 
 	assertRoute["GET:/api/v2/entitlements"] = coderdtest.RouteCheck{
 		NoAuthorize: true,
@@ -101,6 +112,18 @@ func TestAuthorizeAllEndpoints(t *testing.T) {
 		AssertObject: rbac.ResourceProvisionerDaemon,
 		StatusCode:   http.StatusOK,
 	}
+	assertRoute["GET:/api/v2/organizations/{organization}/provisionerkeys"] = coderdtest.RouteCheck{
+		AssertAction: rbac.ActionRead,
+		AssertObject: rbac.ResourceProvisionerKey.InOrg(admin.OrganizationID),
+	}
+	assertRoute["POST:/api/v2/organizations/{organization}/provisionerkeys"] = coderdtest.RouteCheck{
+		AssertAction: rbac.ActionCreate,
+		AssertObject: rbac.ResourceProvisionerKey.InOrg(admin.OrganizationID),
+	}
+	assertRoute["DELETE:/api/v2/organizations/{organization}/provisionerkeys/{provisionerkey}"] = coderdtest.RouteCheck{
+		AssertAction: rbac.ActionDelete,
+		AssertObject: keyObj,
+	}
 	assertRoute["GET:/api/v2/groups/{group}"] = coderdtest.RouteCheck{
 		AssertAction: rbac.ActionRead,
 		AssertObject: groupObj,
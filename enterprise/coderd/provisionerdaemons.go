@@ -10,6 +10,7 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hashicorp/yamux"
@@ -79,9 +80,28 @@ func (api *API) provisionerDaemons(rw http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	jobs, err := api.Database.GetRunningProvisionerJobs(ctx)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching running provisioner jobs.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	currentJobs := make(map[uuid.UUID]uuid.UUID, len(jobs))
+	for _, job := range jobs {
+		if job.WorkerID.Valid {
+			currentJobs[job.WorkerID.UUID] = job.ID
+		}
+	}
+	now := database.Now()
 	apiDaemons := make([]codersdk.ProvisionerDaemon, 0)
 	for _, daemon := range daemons {
-		apiDaemons = append(apiDaemons, convertProvisionerDaemon(daemon))
+		apiDaemon := convertProvisionerDaemon(daemon, now)
+		if jobID, ok := currentJobs[daemon.ID]; ok {
+			apiDaemon.CurrentJobID = &jobID
+		}
+		apiDaemons = append(apiDaemons, apiDaemon)
 	}
 	httpapi.Write(ctx, rw, http.StatusOK, apiDaemons)
 }
@@ -98,6 +118,35 @@ func (api *API) provisionerDaemons(rw http.ResponseWriter, r *http.Request) {
 func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
+	provisioners, tags, ok := parseProvisionerDaemonRequest(rw, r)
+	if !ok {
+		return
+	}
+
+	// Any authenticated user can create provisioner daemons scoped
+	// for jobs that they own, but only authorized users can create
+	// globally scoped provisioners that attach to all jobs.
+	apiKey := httpmw.APIKey(r)
+	tags = provisionerdserver.MutateTags(apiKey.UserID, tags)
+
+	if tags[provisionerdserver.TagScope] == provisionerdserver.ScopeOrganization {
+		if !api.AGPL.Authorize(r, rbac.ActionCreate, rbac.ResourceProvisionerDaemon) {
+			httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+				Message: "You aren't allowed to create provisioner daemons for the organization.",
+			})
+			return
+		}
+	}
+
+	api.serveProvisionerDaemon(ctx, rw, r, provisioners, tags, uuid.Nil)
+}
+
+// parseProvisionerDaemonRequest reads the provisioners and tags a daemon
+// requests from the query parameters. It writes an error response and returns
+// false if they're invalid.
+func parseProvisionerDaemonRequest(rw http.ResponseWriter, r *http.Request) ([]database.ProvisionerType, map[string]string, bool) {
+	ctx := r.Context()
+
 	tags := map[string]string{}
 	if r.URL.Query().Has("tag") {
 		for _, tag := range r.URL.Query()["tag"] {
@@ -106,7 +155,7 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 				httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 					Message: fmt.Sprintf("Invalid format for tag %q. Key and value must be separated with =.", tag),
 				})
-				return
+				return nil, nil, false
 			}
 			tags[parts[0]] = parts[1]
 		}
@@ -115,7 +164,7 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 			Message: "The provisioner query parameter must be specified.",
 		})
-		return
+		return nil, nil, false
 	}
 
 	provisionersMap := map[codersdk.ProvisionerType]struct{}{}
@@ -129,22 +178,7 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 			httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
 				Message: fmt.Sprintf("Unknown provisioner type %q", provisioner),
 			})
-			return
-		}
-	}
-
-	// Any authenticated user can create provisioner daemons scoped
-	// for jobs that they own, but only authorized users can create
-	// globally scoped provisioners that attach to all jobs.
-	apiKey := httpmw.APIKey(r)
-	tags = provisionerdserver.MutateTags(apiKey.UserID, tags)
-
-	if tags[provisionerdserver.TagScope] == provisionerdserver.ScopeOrganization {
-		if !api.AGPL.Authorize(r, rbac.ActionCreate, rbac.ResourceProvisionerDaemon) {
-			httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
-				Message: "You aren't allowed to create provisioner daemons for the organization.",
-			})
-			return
+			return nil, nil, false
 		}
 	}
 
@@ -157,14 +191,24 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 			provisioners = append(provisioners, database.ProvisionerTypeEcho)
 		}
 	}
+	return provisioners, tags, true
+}
 
+// serveProvisionerDaemon registers a daemon with the provisioners and tags and
+// serves it until the connection or the context is closed. The daemon only
+// acquires the jobs of the organization, unless it's uuid.Nil.
+func (api *API) serveProvisionerDaemon(ctx context.Context, rw http.ResponseWriter, r *http.Request, provisioners []database.ProvisionerType, tags map[string]string, organizationID uuid.UUID) {
 	name := namesgenerator.GetRandomName(1)
+	now := database.Now()
 	daemon, err := api.Database.InsertProvisionerDaemon(ctx, database.InsertProvisionerDaemonParams{
 		ID:           uuid.New(),
-		CreatedAt:    database.Now(),
+		CreatedAt:    now,
 		Name:         name,
 		Provisioners: provisioners,
 		Tags:         tags,
+		LastSeenAt:   sql.NullTime{Time: now, Valid: true},
+		// Older daemons don't send their version.
+		Version: r.URL.Query().Get("version"),
 	})
 	if err != nil {
 		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
@@ -216,15 +260,18 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 	}
 	mux := drpcmux.New()
 	err = proto.DRPCRegisterProvisionerDaemon(mux, &provisionerdserver.Server{
-		AccessURL:    api.AccessURL,
-		ID:           daemon.ID,
-		Database:     api.Database,
-		Pubsub:       api.Pubsub,
-		Provisioners: daemon.Provisioners,
-		Telemetry:    api.Telemetry,
-		Auditor:      &api.AGPL.Auditor,
-		Logger:       api.Logger.Named(fmt.Sprintf("provisionerd-%s", daemon.Name)),
-		Tags:         rawTags,
+		AccessURL:      api.AccessURL,
+		ID:             daemon.ID,
+		Database:       api.Database,
+		Pubsub:         api.Pubsub,
+		Provisioners:   daemon.Provisioners,
+		Telemetry:      api.Telemetry,
+		Auditor:        &api.AGPL.Auditor,
+		Notifier:       api.AGPL.Notifier,
+		FileStore:      api.FileStore,
+		Logger:         api.Logger.Named(fmt.Sprintf("provisionerd-%s", daemon.Name)),
+		Tags:           rawTags,
+		OrganizationID: organizationID,
 	})
 	if err != nil {
 		_ = conn.Close(websocket.StatusInternalError, httpapi.WebsocketCloseSprintf("drpc register provisioner daemon: %s", err))
@@ -247,13 +294,21 @@ func (api *API) provisionerDaemonServe(rw http.ResponseWriter, r *http.Request)
 	_ = conn.Close(websocket.StatusGoingAway, "")
 }
 
-func convertProvisionerDaemon(daemon database.ProvisionerDaemon) codersdk.ProvisionerDaemon {
+func convertProvisionerDaemon(daemon database.ProvisionerDaemon, now time.Time) codersdk.ProvisionerDaemon {
 	result := codersdk.ProvisionerDaemon{
 		ID:        daemon.ID,
 		CreatedAt: daemon.CreatedAt,
 		UpdatedAt: daemon.UpdatedAt,
 		Name:      daemon.Name,
 		Tags:      daemon.Tags,
+		Status:    codersdk.ProvisionerDaemonOffline,
+		Version:   daemon.Version,
+	}
+	if daemon.LastSeenAt.Valid {
+		result.LastSeenAt = &daemon.LastSeenAt.Time
+	}
+	if provisionerdserver.DaemonOnline(daemon, now) {
+		result.Status = codersdk.ProvisionerDaemonOnline
 	}
 	for _, provisionerType := range daemon.Provisioners {
 		result.Provisioners = append(result.Provisioners, codersdk.ProvisionerType(provisionerType))
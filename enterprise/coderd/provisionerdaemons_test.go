@@ -3,30 +3,91 @@ package coderd_test
 import (
 	"bytes"
 	"context"
+	"database/sql"
+	"encoding/json"
 	"net/http"
 	"testing"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 
+	"github.com/coder/coder/buildinfo"
 	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbgen"
+	"github.com/coder/coder/coderd/database/dbtestutil"
 	"github.com/coder/coder/coderd/provisionerdserver"
 	"github.com/coder/coder/codersdk"
 	"github.com/coder/coder/enterprise/coderd/coderdenttest"
 	"github.com/coder/coder/enterprise/coderd/license"
 	"github.com/coder/coder/provisioner/echo"
 	"github.com/coder/coder/provisionersdk/proto"
+	"github.com/coder/coder/testutil"
 )
 
+func TestProvisionerDaemons(t *testing.T) {
+	t.Parallel()
+	db, pubsub := dbtestutil.NewDB(t)
+	client := coderdenttest.New(t, &coderdenttest.Options{
+		Options: &coderdtest.Options{
+			Database: db,
+			Pubsub:   pubsub,
+		},
+	})
+	user := coderdtest.CreateFirstUser(t, client)
+	coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+		Features: license.Features{
+			codersdk.FeatureExternalProvisionerDaemons: 1,
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), testutil.WaitLong)
+	defer cancel()
+
+	now := database.Now()
+	busy := dbgen.ProvisionerDaemon(t, db, database.ProvisionerDaemon{
+		Version: "v1.2.3",
+	})
+	offline := dbgen.ProvisionerDaemon(t, db, database.ProvisionerDaemon{
+		LastSeenAt: sql.NullTime{Time: now.Add(-2 * provisionerdserver.DaemonOfflineAfter), Valid: true},
+	})
+	_ = dbgen.ProvisionerJob(t, db, database.ProvisionerJob{})
+	job, err := db.AcquireProvisionerJob(ctx, database.AcquireProvisionerJobParams{
+		StartedAt: sql.NullTime{Time: now, Valid: true},
+		WorkerID:  uuid.NullUUID{UUID: busy.ID, Valid: true},
+		Types:     busy.Provisioners,
+		Tags:      json.RawMessage("{}"),
+	})
+	require.NoError(t, err)
+
+	daemons, err := client.ProvisionerDaemons(ctx, user.OrganizationID)
+	require.NoError(t, err)
+	require.Len(t, daemons, 2)
+	byID := map[uuid.UUID]codersdk.ProvisionerDaemon{}
+	for _, daemon := range daemons {
+		byID[daemon.ID] = daemon
+	}
+	require.Equal(t, codersdk.ProvisionerDaemonOnline, byID[busy.ID].Status)
+	require.Equal(t, "v1.2.3", byID[busy.ID].Version)
+	require.Equal(t, &job.ID, byID[busy.ID].CurrentJobID)
+	require.Equal(t, codersdk.ProvisionerDaemonOffline, byID[offline.ID].Status)
+	require.NotNil(t, byID[offline.ID].LastSeenAt)
+	require.Nil(t, byID[offline.ID].CurrentJobID)
+}
+
 func TestProvisionerDaemonServe(t *testing.T) {
 	t.Parallel()
 	t.Run("NoLicense", func(t *testing.T) {
 		t.Parallel()
 		client := coderdenttest.New(t, nil)
 		user := coderdtest.CreateFirstUser(t, client)
-		_, err := client.ServeProvisionerDaemon(context.Background(), user.OrganizationID, []codersdk.ProvisionerType{
-			codersdk.ProvisionerTypeEcho,
-		}, map[string]string{})
+		_, err := client.ServeProvisionerDaemon(context.Background(), codersdk.ServeProvisionerDaemonRequest{
+			Organization: user.OrganizationID,
+			Provisioners: []codersdk.ProvisionerType{
+				codersdk.ProvisionerTypeEcho,
+			},
+			Tags: map[string]string{},
+		})
 		require.Error(t, err)
 		var apiError *codersdk.Error
 		require.ErrorAs(t, err, &apiError)
@@ -42,11 +103,21 @@ func TestProvisionerDaemonServe(t *testing.T) {
 				codersdk.FeatureExternalProvisionerDaemons: 1,
 			},
 		})
-		srv, err := client.ServeProvisionerDaemon(context.Background(), user.OrganizationID, []codersdk.ProvisionerType{
-			codersdk.ProvisionerTypeEcho,
-		}, map[string]string{})
+		srv, err := client.ServeProvisionerDaemon(context.Background(), codersdk.ServeProvisionerDaemonRequest{
+			Organization: user.OrganizationID,
+			Provisioners: []codersdk.ProvisionerType{
+				codersdk.ProvisionerTypeEcho,
+			},
+			Tags: map[string]string{},
+		})
 		require.NoError(t, err)
 		srv.DRPCConn().Close()
+
+		daemons, err := client.ProvisionerDaemons(context.Background(), user.OrganizationID)
+		require.NoError(t, err)
+		require.Len(t, daemons, 1)
+		require.Equal(t, buildinfo.Version(), daemons[0].Version)
+		require.Equal(t, codersdk.ProvisionerDaemonOnline, daemons[0].Status)
 	})
 
 	t.Run("OrganizationNoPerms", func(t *testing.T) {
@@ -59,10 +130,14 @@ func TestProvisionerDaemonServe(t *testing.T) {
 			},
 		})
 		another, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
-		_, err := another.ServeProvisionerDaemon(context.Background(), user.OrganizationID, []codersdk.ProvisionerType{
-			codersdk.ProvisionerTypeEcho,
-		}, map[string]string{
-			provisionerdserver.TagScope: provisionerdserver.ScopeOrganization,
+		_, err := another.ServeProvisionerDaemon(context.Background(), codersdk.ServeProvisionerDaemonRequest{
+			Organization: user.OrganizationID,
+			Provisioners: []codersdk.ProvisionerType{
+				codersdk.ProvisionerTypeEcho,
+			},
+			Tags: map[string]string{
+				provisionerdserver.TagScope: provisionerdserver.ScopeOrganization,
+			},
 		})
 		require.Error(t, err)
 		var apiError *codersdk.Error
@@ -0,0 +1,278 @@
+package coderd
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"cdr.dev/slog"
+	"github.com/coder/coder/coderd/database"
+	"github.com/coder/coder/coderd/database/dbauthz"
+	"github.com/coder/coder/coderd/httpapi"
+	"github.com/coder/coder/coderd/httpmw"
+	"github.com/coder/coder/coderd/provisionerdserver"
+	"github.com/coder/coder/coderd/rbac"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/cryptorand"
+)
+
+// provisionerKeyRevokedChannel is published to when a provisioner key is
+// deleted, which disconnects the daemons that use it.
+func provisionerKeyRevokedChannel(id uuid.UUID) string {
+	return fmt.Sprintf("provisioner_key_revoked:%s", id)
+}
+
+// @Summary Create provisioner key
+// @ID create-provisioner-key
+// @Security CoderSessionToken
+// @Accept json
+// @Produce json
+// @Tags Enterprise
+// @Param organization path string true "Organization ID" format(uuid)
+// @Param request body codersdk.CreateProvisionerKeyRequest true "Create provisioner key request"
+// @Success 201 {object} codersdk.CreateProvisionerKeyResponse
+// @Router /organizations/{organization}/provisionerkeys [post]
+func (api *API) postProvisionerKey(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	org := httpmw.OrganizationParam(r)
+	if !api.Authorize(r, rbac.ActionCreate, rbac.ResourceProvisionerKey.InOrg(org.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	var req codersdk.CreateProvisionerKeyRequest
+	if !httpapi.Read(ctx, rw, r, &req) {
+		return
+	}
+	if req.Tags[provisionerdserver.TagScope] == provisionerdserver.ScopeUser {
+		httpapi.Write(ctx, rw, http.StatusBadRequest, codersdk.Response{
+			Message: "Provisioner keys can't have the user scope.",
+			Detail:  "Daemons that only run the jobs of a user should authenticate as the user instead.",
+		})
+		return
+	}
+	// Keys always serve the organization, so the scope tag is set here
+	// rather than when a daemon connects.
+	tags := provisionerdserver.MutateTags(uuid.Nil, req.Tags)
+
+	secret, err := cryptorand.String(32)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error generating provisioner key.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	hashed := sha256.Sum256([]byte(secret))
+	_, err = api.Database.InsertProvisionerKey(ctx, database.InsertProvisionerKeyParams{
+		ID:             uuid.New(),
+		CreatedAt:      database.Now(),
+		OrganizationID: org.ID,
+		Name:           req.Name,
+		HashedSecret:   hashed[:],
+		Tags:           tags,
+	})
+	if database.IsUniqueViolation(err) {
+		httpapi.Write(ctx, rw, http.StatusConflict, codersdk.Response{
+			Message: fmt.Sprintf("A provisioner key named %q already exists.", req.Name),
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error creating provisioner key.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	httpapi.Write(ctx, rw, http.StatusCreated, codersdk.CreateProvisionerKeyResponse{
+		Key: secret,
+	})
+}
+
+// @Summary List provisioner keys
+// @ID list-provisioner-keys
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Param organization path string true "Organization ID" format(uuid)
+// @Success 200 {array} codersdk.ProvisionerKey
+// @Router /organizations/{organization}/provisionerkeys [get]
+func (api *API) provisionerKeys(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	org := httpmw.OrganizationParam(r)
+	if !api.Authorize(r, rbac.ActionRead, rbac.ResourceProvisionerKey.InOrg(org.ID)) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	keys, err := api.Database.GetProvisionerKeysByOrganizationID(ctx, org.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner keys.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	apiKeys := make([]codersdk.ProvisionerKey, 0, len(keys))
+	for _, key := range keys {
+		apiKeys = append(apiKeys, convertProvisionerKey(key))
+	}
+	httpapi.Write(ctx, rw, http.StatusOK, apiKeys)
+}
+
+// @Summary Delete provisioner key
+// @ID delete-provisioner-key
+// @Security CoderSessionToken
+// @Produce json
+// @Tags Enterprise
+// @Param organization path string true "Organization ID" format(uuid)
+// @Param provisionerkey path string true "Provisioner key name"
+// @Success 200 {object} codersdk.Response
+// @Router /organizations/{organization}/provisionerkeys/{provisionerkey} [delete]
+func (api *API) deleteProvisionerKey(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	org := httpmw.OrganizationParam(r)
+
+	key, err := api.Database.GetProvisionerKeyByOrganizationIDAndName(ctx, database.GetProvisionerKeyByOrganizationIDAndNameParams{
+		OrganizationID: org.ID,
+		Name:           chi.URLParam(r, "provisionerkey"),
+	})
+	if errors.Is(err, sql.ErrNoRows) || rbac.IsUnauthorizedError(err) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner key.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	if !api.Authorize(r, rbac.ActionDelete, key) {
+		httpapi.ResourceNotFound(rw)
+		return
+	}
+
+	err = api.Database.DeleteProvisionerKeyByID(ctx, key.ID)
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error deleting provisioner key.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	err = api.Pubsub.Publish(provisionerKeyRevokedChannel(key.ID), []byte{})
+	if err != nil {
+		// Daemons using the key can't reconnect, but stay connected
+		// until they're restarted.
+		api.Logger.Warn(ctx, "publish provisioner key revoked", slog.F("key_id", key.ID), slog.Error(err))
+	}
+
+	httpapi.Write(ctx, rw, http.StatusOK, codersdk.Response{
+		Message: "Provisioner key has been deleted!",
+	})
+}
+
+// Serves the provisioner daemon protobuf API over a WebSocket to daemons that
+// authenticate with a provisioner key.
+//
+// @Summary Serve provisioner daemon with a provisioner key
+// @ID serve-provisioner-daemon-with-a-provisioner-key
+// @Tags Enterprise
+// @Param Coder-Provisioner-Key header string true "Provisioner key"
+// @Success 101
+// @Router /provisionerdaemons/serve [get]
+func (api *API) provisionerKeyDaemonServe(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	secret := r.Header.Get(codersdk.ProvisionerKeyHeader)
+	if secret == "" {
+		httpapi.Write(ctx, rw, http.StatusUnauthorized, codersdk.Response{
+			Message: fmt.Sprintf("The %s header must be specified.", codersdk.ProvisionerKeyHeader),
+		})
+		return
+	}
+	hashed := sha256.Sum256([]byte(secret))
+	//nolint:gocritic // The daemon isn't authenticated until its key is found.
+	key, err := api.Database.GetProvisionerKeyByHashedSecret(dbauthz.AsSystemRestricted(ctx), hashed[:])
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusUnauthorized, codersdk.Response{
+			Message: "The provisioner key is invalid or has been deleted.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner key.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	provisioners, tags, ok := parseProvisionerDaemonRequest(rw, r)
+	if !ok {
+		return
+	}
+	// Daemons are always served with all of the key's tags. The tags they
+	// state are only checked, so they can't claim tags the key doesn't have.
+	for name, value := range tags {
+		if keyValue, ok := key.Tags[name]; !ok || keyValue != value {
+			httpapi.Write(ctx, rw, http.StatusForbidden, codersdk.Response{
+				Message: fmt.Sprintf("The provisioner key doesn't allow the tag %s=%s.", name, value),
+			})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cancelSubscribe, err := api.Pubsub.Subscribe(provisionerKeyRevokedChannel(key.ID), func(_ context.Context, _ []byte) {
+		cancel()
+	})
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error subscribing to provisioner key events.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+	defer cancelSubscribe()
+	// The key could've been deleted before the subscription started.
+	//nolint:gocritic // The daemon isn't authenticated until its key is found.
+	_, err = api.Database.GetProvisionerKeyByHashedSecret(dbauthz.AsSystemRestricted(ctx), hashed[:])
+	if errors.Is(err, sql.ErrNoRows) {
+		httpapi.Write(ctx, rw, http.StatusUnauthorized, codersdk.Response{
+			Message: "The provisioner key is invalid or has been deleted.",
+		})
+		return
+	}
+	if err != nil {
+		httpapi.Write(ctx, rw, http.StatusInternalServerError, codersdk.Response{
+			Message: "Internal error fetching provisioner key.",
+			Detail:  err.Error(),
+		})
+		return
+	}
+
+	api.serveProvisionerDaemon(ctx, rw, r, provisioners, key.Tags, key.OrganizationID)
+}
+
+func convertProvisionerKey(key database.ProvisionerKey) codersdk.ProvisionerKey {
+	return codersdk.ProvisionerKey{
+		ID:             key.ID,
+		CreatedAt:      key.CreatedAt,
+		OrganizationID: key.OrganizationID,
+		Name:           key.Name,
+		Tags:           key.Tags,
+	}
+}
@@ -0,0 +1,195 @@
+package coderd_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/coder/coder/coderd/coderdtest"
+	"github.com/coder/coder/coderd/provisionerdserver"
+	"github.com/coder/coder/codersdk"
+	"github.com/coder/coder/enterprise/coderd/coderdenttest"
+	"github.com/coder/coder/enterprise/coderd/license"
+	provisionerdproto "github.com/coder/coder/provisionerd/proto"
+	"github.com/coder/coder/testutil"
+)
+
+func TestProvisionerKeys(t *testing.T) {
+	t.Parallel()
+
+	setup := func(t *testing.T) (*codersdk.Client, codersdk.CreateFirstUserResponse) {
+		client := coderdenttest.New(t, nil)
+		user := coderdtest.CreateFirstUser(t, client)
+		coderdenttest.AddLicense(t, client, coderdenttest.LicenseOptions{
+			Features: license.Features{
+				codersdk.FeatureExternalProvisionerDaemons: 1,
+			},
+		})
+		return client, user
+	}
+
+	t.Run("CreateAndList", func(t *testing.T) {
+		t.Parallel()
+		client, user := setup(t)
+		ctx, _ := testutil.Context(t)
+
+		res, err := client.CreateProvisionerKey(ctx, user.OrganizationID, codersdk.CreateProvisionerKeyRequest{
+			Name: "builders",
+			Tags: map[string]string{"region": "eu"},
+		})
+		require.NoError(t, err)
+		require.NotEmpty(t, res.Key)
+
+		keys, err := client.ProvisionerKeys(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		require.Equal(t, "builders", keys[0].Name)
+		require.Equal(t, user.OrganizationID, keys[0].OrganizationID)
+		require.Equal(t, map[string]string{
+			"region":                    "eu",
+			provisionerdserver.TagScope: provisionerdserver.ScopeOrganization,
+		}, keys[0].Tags)
+
+		_, err = client.CreateProvisionerKey(ctx, user.OrganizationID, codersdk.CreateProvisionerKeyRequest{
+			Name: "builders",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusConflict, apiErr.StatusCode())
+	})
+
+	t.Run("UserScope", func(t *testing.T) {
+		t.Parallel()
+		client, user := setup(t)
+		ctx, _ := testutil.Context(t)
+
+		_, err := client.CreateProvisionerKey(ctx, user.OrganizationID, codersdk.CreateProvisionerKeyRequest{
+			Name: "builders",
+			Tags: map[string]string{provisionerdserver.TagScope: provisionerdserver.ScopeUser},
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusBadRequest, apiErr.StatusCode())
+	})
+
+	t.Run("MemberCantCreate", func(t *testing.T) {
+		t.Parallel()
+		client, user := setup(t)
+		member, _ := coderdtest.CreateAnotherUser(t, client, user.OrganizationID)
+		ctx, _ := testutil.Context(t)
+
+		_, err := member.CreateProvisionerKey(ctx, user.OrganizationID, codersdk.CreateProvisionerKeyRequest{
+			Name: "builders",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusNotFound, apiErr.StatusCode())
+	})
+
+	t.Run("Serve", func(t *testing.T) {
+		t.Parallel()
+		client, user := setup(t)
+		ctx, _ := testutil.Context(t)
+
+		res, err := client.CreateProvisionerKey(ctx, user.OrganizationID, codersdk.CreateProvisionerKeyRequest{
+			Name: "builders",
+			Tags: map[string]string{"region": "eu"},
+		})
+		require.NoError(t, err)
+
+		// The key authenticates the daemon, not the session.
+		keyClient := codersdk.New(client.URL)
+		srv, err := keyClient.ServeProvisionerDaemon(ctx, codersdk.ServeProvisionerDaemonRequest{
+			Provisioners:   []codersdk.ProvisionerType{codersdk.ProvisionerTypeEcho},
+			Tags:           map[string]string{"region": "eu"},
+			ProvisionerKey: res.Key,
+		})
+		require.NoError(t, err)
+		defer srv.DRPCConn().Close()
+
+		daemons, err := client.ProvisionerDaemons(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Len(t, daemons, 1)
+		require.Equal(t, map[string]string{
+			"region":                    "eu",
+			provisionerdserver.TagScope: provisionerdserver.ScopeOrganization,
+		}, daemons[0].Tags)
+	})
+
+	t.Run("ServeTagOutsideKey", func(t *testing.T) {
+		t.Parallel()
+		client, user := setup(t)
+		ctx, _ := testutil.Context(t)
+
+		res, err := client.CreateProvisionerKey(ctx, user.OrganizationID, codersdk.CreateProvisionerKeyRequest{
+			Name: "builders",
+			Tags: map[string]string{"region": "eu"},
+		})
+		require.NoError(t, err)
+
+		_, err = codersdk.New(client.URL).ServeProvisionerDaemon(ctx, codersdk.ServeProvisionerDaemonRequest{
+			Provisioners:   []codersdk.ProvisionerType{codersdk.ProvisionerTypeEcho},
+			Tags:           map[string]string{"region": "us"},
+			ProvisionerKey: res.Key,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusForbidden, apiErr.StatusCode())
+	})
+
+	t.Run("ServeInvalidKey", func(t *testing.T) {
+		t.Parallel()
+		client, _ := setup(t)
+		ctx, _ := testutil.Context(t)
+
+		_, err := codersdk.New(client.URL).ServeProvisionerDaemon(ctx, codersdk.ServeProvisionerDaemonRequest{
+			Provisioners:   []codersdk.ProvisionerType{codersdk.ProvisionerTypeEcho},
+			ProvisionerKey: "invalid",
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode())
+	})
+
+	t.Run("DeleteDisconnects", func(t *testing.T) {
+		t.Parallel()
+		client, user := setup(t)
+		ctx, _ := testutil.Context(t)
+
+		res, err := client.CreateProvisionerKey(ctx, user.OrganizationID, codersdk.CreateProvisionerKeyRequest{
+			Name: "builders",
+		})
+		require.NoError(t, err)
+		keyClient := codersdk.New(client.URL)
+		srv, err := keyClient.ServeProvisionerDaemon(ctx, codersdk.ServeProvisionerDaemonRequest{
+			Provisioners:   []codersdk.ProvisionerType{codersdk.ProvisionerTypeEcho},
+			ProvisionerKey: res.Key,
+		})
+		require.NoError(t, err)
+		defer srv.DRPCConn().Close()
+		_, err = srv.AcquireJob(ctx, &provisionerdproto.Empty{})
+		require.NoError(t, err)
+
+		err = client.DeleteProvisionerKey(ctx, user.OrganizationID, "builders")
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			_, err := srv.AcquireJob(context.Background(), &provisionerdproto.Empty{})
+			return err != nil
+		}, testutil.WaitShort, testutil.IntervalFast)
+
+		_, err = keyClient.ServeProvisionerDaemon(ctx, codersdk.ServeProvisionerDaemonRequest{
+			Provisioners:   []codersdk.ProvisionerType{codersdk.ProvisionerTypeEcho},
+			ProvisionerKey: res.Key,
+		})
+		var apiErr *codersdk.Error
+		require.ErrorAs(t, err, &apiErr)
+		require.Equal(t, http.StatusUnauthorized, apiErr.StatusCode())
+
+		keys, err := client.ProvisionerKeys(ctx, user.OrganizationID)
+		require.NoError(t, err)
+		require.Empty(t, keys)
+	})
+}
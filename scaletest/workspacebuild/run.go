@@ -56,6 +56,10 @@ func (r *Runner) Run(ctx context.Context, _ string, logs io.Writer) error {
 		}
 		req.Name = "test-" + randName
 	}
+	if req.Priority == "" {
+		// Queue behind builds of real users.
+		req.Priority = codersdk.ProvisionerJobPriorityBackground
+	}
 
 	workspace, err := r.client.CreateWorkspace(ctx, r.cfg.OrganizationID, r.cfg.UserID, req)
 	if err != nil {
@@ -114,6 +118,7 @@ func (r *CleanupRunner) Run(ctx context.Context, _ string, logs io.Writer) error
 
 	build, err := r.client.CreateWorkspaceBuild(ctx, r.workspaceID, codersdk.CreateWorkspaceBuildRequest{
 		Transition: codersdk.WorkspaceTransitionDelete,
+		Priority:   codersdk.ProvisionerJobPriorityBackground,
 	})
 	if err != nil {
 		return xerrors.Errorf("delete workspace: %w", err)
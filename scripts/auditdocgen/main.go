@@ -117,12 +117,11 @@ func updateAuditDoc(doc []byte, auditableResourcesMap AuditableResourcesMap) ([]
 	_, _ = buffer.WriteString("|--|-----------------|\n")
 
 	for _, resourceName := range sortedResourceNames {
-		readableResourceName := resourceName
-		// AuditableGroup is really a combination of Group and GroupMember resources
-		// but we use the label 'Group' in our docs to avoid confusion.
-		if resourceName == "AuditableGroup" {
-			readableResourceName = "Group"
-		}
+		// Auditable* types wrap a database model with extra fields that are
+		// useful in a diff (e.g. AuditableGroup is really a combination of
+		// Group and GroupMember resources), so we drop the prefix in our docs
+		// to avoid confusion.
+		readableResourceName := strings.TrimPrefix(resourceName, "Auditable")
 
 		// Create a string of audit actions for each resource
 		var auditActions []string
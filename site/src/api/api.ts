@@ -67,6 +67,8 @@ export const provisioners: TypesGen.ProvisionerDaemon[] = [
     created_at: "",
     provisioners: [],
     tags: {},
+    status: "online",
+    version: "",
   },
   {
     id: "cdr-basic",
@@ -74,6 +76,8 @@ export const provisioners: TypesGen.ProvisionerDaemon[] = [
     created_at: "",
     provisioners: [],
     tags: {},
+    status: "online",
+    version: "",
   },
 ]
 
@@ -42,6 +42,12 @@ export interface AppearanceConfig {
   readonly support_links?: LinkConfig[]
 }
 
+// From codersdk/templates.go
+export interface ArchiveTemplateVersionsResponse {
+  readonly template_id: string
+  readonly archived_ids: string[]
+}
+
 // From codersdk/roles.go
 export interface AssignableRoles extends Role {
   readonly assignable: boolean
@@ -89,11 +95,34 @@ export interface AuditLogResponse {
   readonly count: number
 }
 
+// From codersdk/deployment.go
+export interface AuditLogRetentionConfig {
+  readonly period: DeploymentConfigField<number>
+  readonly archive: DeploymentConfigField<boolean>
+  readonly batch_size: DeploymentConfigField<number>
+}
+
 // From codersdk/audit.go
 export interface AuditLogsRequest extends Pagination {
   readonly q?: string
 }
 
+// From codersdk/deployment.go
+export interface AuditStreamingConfig {
+  readonly syslog_address: DeploymentConfigField<string>
+  readonly syslog_filter: DeploymentConfigField<string[]>
+  readonly webhook_url: DeploymentConfigField<string>
+  readonly webhook_secret: DeploymentConfigField<string>
+  readonly webhook_batch_size: DeploymentConfigField<number>
+  readonly webhook_flush_interval: DeploymentConfigField<number>
+  readonly webhook_filter: DeploymentConfigField<string[]>
+  readonly file_path: DeploymentConfigField<string>
+  readonly file_max_size: DeploymentConfigField<number>
+  readonly file_max_backups: DeploymentConfigField<number>
+  readonly file_filter: DeploymentConfigField<string[]>
+  readonly buffer_size: DeploymentConfigField<number>
+}
+
 // From codersdk/users.go
 export interface AuthMethod {
   readonly enabled: boolean
@@ -134,6 +163,15 @@ export interface BuildInfoResponse {
   readonly version: string
 }
 
+// From codersdk/workspaces.go
+export interface CloneWorkspaceRequest {
+  readonly name: string
+  readonly pin_template_version?: boolean
+  readonly parameter_values?: CreateParameterRequest[]
+  readonly rich_parameter_values?: WorkspaceBuildParameter[]
+  readonly labels?: Record<string, string>
+}
+
 // From codersdk/parameters.go
 export interface ComputedParameter extends Parameter {
   readonly source_value: string
@@ -141,6 +179,17 @@ export interface ComputedParameter extends Parameter {
   readonly default_source_value: boolean
 }
 
+// From codersdk/audit.go
+export interface ConnectionAuditFields {
+  readonly workspace_name: string
+  readonly workspace_owner: string
+  readonly agent_id: string
+  readonly agent_name: string
+  readonly connection_type: ConnectionType
+  readonly detail?: string
+  readonly duration_ms?: number
+}
+
 // From codersdk/users.go
 export interface CreateFirstUserRequest {
   readonly email: string
@@ -176,6 +225,17 @@ export interface CreateParameterRequest {
   readonly destination_scheme: ParameterDestinationScheme
 }
 
+// From codersdk/provisionerkeys.go
+export interface CreateProvisionerKeyRequest {
+  readonly name: string
+  readonly tags: Record<string, string>
+}
+
+// From codersdk/provisionerkeys.go
+export interface CreateProvisionerKeyResponse {
+  readonly key: string
+}
+
 // From codersdk/organizations.go
 export interface CreateTemplateRequest {
   readonly name: string
@@ -205,6 +265,7 @@ export interface CreateTemplateVersionRequest {
   readonly example_id?: string
   readonly provisioner: ProvisionerType
   readonly tags: Record<string, string>
+  readonly git_source?: TemplateVersionGitSource
   readonly parameter_values?: CreateParameterRequest[]
   readonly user_variable_values?: VariableValue[]
 }
@@ -234,6 +295,14 @@ export interface CreateUserRequest {
   readonly organization_id: string
 }
 
+// From codersdk/webhooks.go
+export interface CreateWebhookRequest {
+  readonly name: string
+  readonly url: string
+  readonly events: WebhookEvent[]
+  readonly secret?: string
+}
+
 // From codersdk/workspaces.go
 export interface CreateWorkspaceBuildRequest {
   readonly template_version_id?: string
@@ -243,16 +312,20 @@ export interface CreateWorkspaceBuildRequest {
   readonly orphan?: boolean
   readonly parameter_values?: CreateParameterRequest[]
   readonly rich_parameter_values?: WorkspaceBuildParameter[]
+  readonly priority?: ProvisionerJobPriority
 }
 
 // From codersdk/organizations.go
 export interface CreateWorkspaceRequest {
   readonly template_id: string
+  readonly template_version_id?: string
   readonly name: string
   readonly autostart_schedule?: string
   readonly ttl_ms?: number
   readonly parameter_values?: CreateParameterRequest[]
   readonly rich_parameter_values?: WorkspaceBuildParameter[]
+  readonly labels?: Record<string, string>
+  readonly priority?: ProvisionerJobPriority
 }
 
 // From codersdk/templates.go
@@ -324,6 +397,8 @@ export interface DeploymentConfig {
   readonly agent_stat_refresh_interval: DeploymentConfigField<number>
   readonly agent_fallback_troubleshooting_url: DeploymentConfigField<string>
   readonly audit_logging: DeploymentConfigField<boolean>
+  readonly audit_streaming: AuditStreamingConfig
+  readonly audit_log_retention: AuditLogRetentionConfig
   readonly browser_only: DeploymentConfigField<boolean>
   readonly scim_api_key: DeploymentConfigField<string>
   readonly provisioner: ProvisionerConfig
@@ -338,6 +413,10 @@ export interface DeploymentConfig {
   readonly max_session_expiry: DeploymentConfigField<number>
   readonly disable_session_expiry_refresh: DeploymentConfigField<boolean>
   readonly disable_password_auth: DeploymentConfigField<boolean>
+  readonly email: EmailConfig
+  readonly template_version_gc_interval: DeploymentConfigField<number>
+  readonly template_git_sync_interval: DeploymentConfigField<number>
+  readonly file_storage: FileStorageConfig
   readonly address: DeploymentConfigField<string>
   readonly experimental: DeploymentConfigField<boolean>
   readonly support: SupportConfig
@@ -361,6 +440,14 @@ export interface DeploymentDAUsResponse {
   readonly entries: DAUEntry[]
 }
 
+// From codersdk/deployment.go
+export interface EmailConfig {
+  readonly from: DeploymentConfigField<string>
+  readonly smarthost: DeploymentConfigField<string>
+  readonly username: DeploymentConfigField<string>
+  readonly password: DeploymentConfigField<string>
+}
+
 // From codersdk/deployment.go
 export interface Entitlements {
   readonly features: Record<FeatureName, Feature>
@@ -383,6 +470,17 @@ export interface Feature {
   readonly actual?: number
 }
 
+// From codersdk/deployment.go
+export interface FileStorageConfig {
+  readonly backend: DeploymentConfigField<string>
+  readonly directory: DeploymentConfigField<string>
+  readonly s3_endpoint: DeploymentConfigField<string>
+  readonly s3_region: DeploymentConfigField<string>
+  readonly s3_bucket: DeploymentConfigField<string>
+  readonly s3_access_key_id: DeploymentConfigField<string>
+  readonly s3_secret_access_key: DeploymentConfigField<string>
+}
+
 // From codersdk/apikey.go
 export interface GenerateAPIKeyResponse {
   readonly key: string
@@ -466,6 +564,13 @@ export interface LoginWithPasswordResponse {
   readonly session_token: string
 }
 
+// From codersdk/notifications.go
+export interface NotificationPreference {
+  readonly kind: NotificationKind
+  readonly enabled: boolean
+  readonly updated_at?: string
+}
+
 // From codersdk/deployment.go
 export interface OAuth2Config {
   readonly github: OAuth2GithubConfig
@@ -586,6 +691,9 @@ export interface ProvisionerConfig {
   readonly daemon_poll_interval: DeploymentConfigField<number>
   readonly daemon_poll_jitter: DeploymentConfigField<number>
   readonly force_cancel_interval: DeploymentConfigField<number>
+  readonly job_hang_timeout: DeploymentConfigField<number>
+  readonly job_max_runtime: DeploymentConfigField<number>
+  readonly job_log_retention: DeploymentConfigField<number>
 }
 
 // From codersdk/provisionerdaemons.go
@@ -596,6 +704,10 @@ export interface ProvisionerDaemon {
   readonly name: string
   readonly provisioners: ProvisionerType[]
   readonly tags: Record<string, string>
+  readonly last_seen_at?: string
+  readonly status: ProvisionerDaemonStatus
+  readonly version: string
+  readonly current_job_id?: string
 }
 
 // From codersdk/provisionerdaemons.go
@@ -610,6 +722,8 @@ export interface ProvisionerJob {
   readonly worker_id?: string
   readonly file_id: string
   readonly tags: Record<string, string>
+  readonly priority: ProvisionerJobPriority
+  readonly queue?: ProvisionerJobQueue
 }
 
 // From codersdk/provisionerdaemons.go
@@ -622,6 +736,30 @@ export interface ProvisionerJobLog {
   readonly output: string
 }
 
+// From codersdk/provisionerdaemons.go
+export interface ProvisionerJobLogsFilter {
+  readonly level?: LogLevel
+  readonly stage?: string
+  readonly search?: string
+}
+
+// From codersdk/provisionerdaemons.go
+export interface ProvisionerJobQueue {
+  readonly position: number
+  readonly size: number
+  readonly estimated_wait_ms?: number
+  readonly warning?: string
+}
+
+// From codersdk/provisionerkeys.go
+export interface ProvisionerKey {
+  readonly id: string
+  readonly created_at: string
+  readonly organization_id: string
+  readonly name: string
+  readonly tags: Record<string, string>
+}
+
 // From codersdk/workspaces.go
 export interface PutExtendWorkspaceRequest {
   readonly deadline: string
@@ -720,6 +858,10 @@ export interface Template {
   readonly created_by_id: string
   readonly created_by_name: string
   readonly allow_user_cancel_workspace_jobs: boolean
+  readonly deprecated: boolean
+  readonly deprecation_message: string
+  readonly git_sync_status?: TemplateGitSyncStatus
+  readonly git_sync_error?: string
 }
 
 // From codersdk/templates.go
@@ -728,12 +870,36 @@ export interface TemplateACL {
   readonly group: TemplateGroup[]
 }
 
+// From codersdk/insights.go
+export interface TemplateAppUsage {
+  readonly slug: string
+  readonly display_name: string
+  readonly icon: string
+  readonly connections: number
+}
+
+// From codersdk/insights.go
+export interface TemplateBuildInsights {
+  readonly transition: WorkspaceTransition
+  readonly p50?: number
+  readonly p95?: number
+  readonly total: number
+  readonly failed: number
+  readonly failure_rate: number
+}
+
 // From codersdk/templates.go
 export type TemplateBuildTimeStats = Record<
   WorkspaceTransition,
   TransitionStats
 >
 
+// From codersdk/insights.go
+export interface TemplateConnectionUsage {
+  readonly type: TemplateConnectionType
+  readonly connections: number
+}
+
 // From codersdk/templates.go
 export interface TemplateDAUsResponse {
   readonly entries: DAUEntry[]
@@ -750,11 +916,50 @@ export interface TemplateExample {
   readonly markdown: string
 }
 
+// From codersdk/organizations.go
+export interface TemplateFilter extends Pagination {
+  readonly q?: string
+}
+
+// From codersdk/templategitsync.go
+export interface TemplateGitSync {
+  readonly template_id: string
+  readonly url: string
+  readonly branch: string
+  readonly directory: string
+  readonly user_id: string
+  readonly updated_at: string
+  readonly commit_sha: string
+  readonly template_version_id?: string
+  readonly status: TemplateGitSyncStatus
+  readonly error: string
+  readonly checked_at?: string
+  readonly webhook_url: string
+  readonly webhook_secret?: string
+}
+
 // From codersdk/templates.go
 export interface TemplateGroup extends Group {
   readonly role: TemplateRole
 }
 
+// From codersdk/insights.go
+export interface TemplateInsightsRequest {
+  readonly start_time: string
+  readonly end_time: string
+}
+
+// From codersdk/insights.go
+export interface TemplateInsightsResponse {
+  readonly start_time: string
+  readonly end_time: string
+  readonly builds: TemplateBuildInsights[]
+  readonly connection_types: TemplateConnectionUsage[]
+  readonly apps: TemplateAppUsage[]
+  readonly active_workspaces: DAUEntry[]
+  readonly version_adoption: TemplateVersionAdoption[]
+}
+
 // From codersdk/templates.go
 export interface TemplateUser extends User {
   readonly role: TemplateRole
@@ -771,6 +976,16 @@ export interface TemplateVersion {
   readonly job: ProvisionerJob
   readonly readme: string
   readonly created_by: User
+  readonly archived: boolean
+  readonly git_source?: TemplateVersionGitSource
+}
+
+// From codersdk/insights.go
+export interface TemplateVersionAdoption {
+  readonly template_version_id: string
+  readonly name: string
+  readonly active: boolean
+  readonly workspaces: number
 }
 
 // From codersdk/templateversions.go
@@ -781,6 +996,14 @@ export interface TemplateVersionGitAuth {
   readonly authenticated: boolean
 }
 
+// From codersdk/templateversions.go
+export interface TemplateVersionGitSource {
+  readonly url: string
+  readonly ref?: string
+  readonly directory?: string
+  readonly commit_sha?: string
+}
+
 // From codersdk/templateversions.go
 export interface TemplateVersionParameter {
   readonly name: string
@@ -820,6 +1043,7 @@ export interface TemplateVersionVariable {
 // From codersdk/templates.go
 export interface TemplateVersionsByTemplateRequest extends Pagination {
   readonly template_id: string
+  readonly include_archived: boolean
 }
 
 // From codersdk/apikey.go
@@ -834,6 +1058,12 @@ export interface TraceConfig {
   readonly capture_logs: DeploymentConfigField<boolean>
 }
 
+// From codersdk/workspaces.go
+export interface TransferWorkspaceRequest {
+  readonly owner: string
+  readonly name?: string
+}
+
 // From codersdk/templates.go
 export interface TransitionStats {
   readonly P50?: number
@@ -858,6 +1088,11 @@ export interface UpdateCheckResponse {
   readonly url: string
 }
 
+// From codersdk/notifications.go
+export interface UpdateNotificationPreferencesRequest {
+  readonly preferences: NotificationPreference[]
+}
+
 // From codersdk/users.go
 export interface UpdateRoles {
   readonly roles: string[]
@@ -869,6 +1104,19 @@ export interface UpdateTemplateACL {
   readonly group_perms?: Record<string, TemplateRole>
 }
 
+// From codersdk/templates.go
+export interface UpdateTemplateDeprecation {
+  readonly deprecated: boolean
+  readonly message?: string
+}
+
+// From codersdk/templategitsync.go
+export interface UpdateTemplateGitSyncRequest {
+  readonly url: string
+  readonly branch?: string
+  readonly directory?: string
+}
+
 // From codersdk/templates.go
 export interface UpdateTemplateMeta {
   readonly name?: string
@@ -890,6 +1138,20 @@ export interface UpdateUserProfileRequest {
   readonly username: string
 }
 
+// From codersdk/webhooks.go
+export interface UpdateWebhookRequest {
+  readonly name?: string
+  readonly url?: string
+  readonly events?: WebhookEvent[]
+  readonly enabled?: boolean
+}
+
+// From codersdk/workspaces.go
+export interface UpdateWorkspaceACL {
+  readonly user_perms?: Record<string, WorkspaceRole>
+  readonly group_perms?: Record<string, WorkspaceRole>
+}
+
 // From codersdk/workspaces.go
 export interface UpdateWorkspaceAutostartRequest {
   readonly schedule?: string
@@ -898,6 +1160,7 @@ export interface UpdateWorkspaceAutostartRequest {
 // From codersdk/workspaces.go
 export interface UpdateWorkspaceRequest {
   readonly name?: string
+  readonly labels: Record<string, string>
 }
 
 // From codersdk/workspaces.go
@@ -923,6 +1186,32 @@ export interface User {
   readonly avatar_url: string
 }
 
+// From codersdk/insights.go
+export interface UserInsights {
+  readonly user_id: string
+  readonly username: string
+  readonly email: string
+  readonly status: UserStatus
+  readonly workspaces_owned: number
+  readonly builds: number
+  readonly connected_seconds: number
+  readonly cost_accrued: number
+  readonly last_seen_at: string
+}
+
+// From codersdk/insights.go
+export interface UserInsightsRequest {
+  readonly start_time: string
+  readonly end_time: string
+}
+
+// From codersdk/insights.go
+export interface UserInsightsResponse {
+  readonly start_time: string
+  readonly end_time: string
+  readonly users: UserInsights[]
+}
+
 // From codersdk/users.go
 export interface UserRoles {
   readonly roles: string[]
@@ -946,6 +1235,44 @@ export interface VariableValue {
   readonly value: string
 }
 
+// From codersdk/webhooks.go
+export interface Webhook {
+  readonly id: string
+  readonly organization_id?: string
+  readonly created_by: string
+  readonly created_at: string
+  readonly updated_at: string
+  readonly name: string
+  readonly url: string
+  readonly events: WebhookEvent[]
+  readonly enabled: boolean
+  readonly secret?: string
+}
+
+// From codersdk/webhooks.go
+export interface WebhookDelivery {
+  readonly id: string
+  readonly webhook_id: string
+  readonly event: WebhookEvent
+  readonly payload: WebhookEventPayload
+  readonly attempt: number
+  readonly created_at: string
+  readonly delivered_at?: string
+  readonly status_code?: number
+  readonly response_body?: string
+  readonly error?: string
+}
+
+// From codersdk/webhooks.go
+export interface WebhookEventPayload {
+  readonly id: string
+  readonly event: WebhookEvent
+  readonly created_at: string
+  readonly organization_id: string
+  readonly organization_ids?: string[]
+  readonly data: Record<string, string>
+}
+
 // From codersdk/workspaces.go
 export interface Workspace {
   readonly id: string
@@ -958,12 +1285,20 @@ export interface Workspace {
   readonly template_display_name: string
   readonly template_icon: string
   readonly template_allow_user_cancel_workspace_jobs: boolean
+  readonly template_deprecation_message?: string
   readonly latest_build: WorkspaceBuild
   readonly outdated: boolean
   readonly name: string
   readonly autostart_schedule?: string
   readonly ttl_ms?: number
   readonly last_used_at: string
+  readonly labels: Record<string, string>
+}
+
+// From codersdk/workspaces.go
+export interface WorkspaceACL {
+  readonly users: WorkspaceUser[]
+  readonly group: WorkspaceGroup[]
 }
 
 // From codersdk/workspaceagents.go
@@ -1061,6 +1396,11 @@ export interface WorkspaceFilter {
   readonly q?: string
 }
 
+// From codersdk/workspaces.go
+export interface WorkspaceGroup extends Group {
+  readonly role: WorkspaceRole
+}
+
 // From codersdk/workspaces.go
 export interface WorkspaceOptions {
   readonly include_deleted?: boolean
@@ -1094,6 +1434,11 @@ export interface WorkspaceResourceMetadata {
   readonly sensitive: boolean
 }
 
+// From codersdk/workspaces.go
+export interface WorkspaceUser extends User {
+  readonly role: WorkspaceRole
+}
+
 // From codersdk/workspaces.go
 export interface WorkspacesRequest extends Pagination {
   readonly q?: string
@@ -1111,16 +1456,20 @@ export const APIKeyScopes: APIKeyScope[] = ["all", "application_connect"]
 
 // From codersdk/audit.go
 export type AuditAction =
+  | "connect"
   | "create"
   | "delete"
+  | "disconnect"
   | "login"
   | "logout"
   | "start"
   | "stop"
   | "write"
 export const AuditActions: AuditAction[] = [
+  "connect",
   "create",
   "delete",
+  "disconnect",
   "login",
   "logout",
   "start",
@@ -1136,6 +1485,21 @@ export const BuildReasons: BuildReason[] = [
   "initiator",
 ]
 
+// From codersdk/audit.go
+export type ConnectionType =
+  | "port_forward"
+  | "reconnecting_pty"
+  | "ssh"
+  | "tailnet"
+  | "workspace_app"
+export const ConnectionTypes: ConnectionType[] = [
+  "port_forward",
+  "reconnecting_pty",
+  "ssh",
+  "tailnet",
+  "workspace_app",
+]
+
 // From codersdk/deployment.go
 export type Entitlement = "entitled" | "grace_period" | "not_entitled"
 export const Entitlements: Entitlement[] = [
@@ -1192,6 +1556,23 @@ export const LogSources: LogSource[] = ["provisioner", "provisioner_daemon"]
 export type LoginType = "github" | "oidc" | "password" | "token"
 export const LoginTypes: LoginType[] = ["github", "oidc", "password", "token"]
 
+// From codersdk/notifications.go
+export type NotificationKind =
+  | "account_activated"
+  | "account_created"
+  | "account_suspended"
+  | "template_deprecated"
+  | "workspace_autostop"
+  | "workspace_build_failed"
+export const NotificationKinds: NotificationKind[] = [
+  "account_activated",
+  "account_created",
+  "account_suspended",
+  "template_deprecated",
+  "workspace_autostop",
+  "workspace_build_failed",
+]
+
 // From codersdk/parameters.go
 export type ParameterDestinationScheme =
   | "environment_variable"
@@ -1219,6 +1600,29 @@ export const ParameterSourceSchemes: ParameterSourceScheme[] = ["data", "none"]
 export type ParameterTypeSystem = "hcl" | "none"
 export const ParameterTypeSystems: ParameterTypeSystem[] = ["hcl", "none"]
 
+// From codersdk/provisionerdaemons.go
+export type ProvisionerDaemonStatus = "offline" | "online"
+export const ProvisionerDaemonStatuses: ProvisionerDaemonStatus[] = [
+  "offline",
+  "online",
+]
+
+// From codersdk/provisionerdaemons.go
+export type ProvisionerJobLogsFormat = "json" | "ndjson" | "text"
+export const ProvisionerJobLogsFormats: ProvisionerJobLogsFormat[] = [
+  "json",
+  "ndjson",
+  "text",
+]
+
+// From codersdk/provisionerdaemons.go
+export type ProvisionerJobPriority = "automatic" | "background" | "interactive"
+export const ProvisionerJobPrioritys: ProvisionerJobPriority[] = [
+  "automatic",
+  "background",
+  "interactive",
+]
+
 // From codersdk/provisionerdaemons.go
 export type ProvisionerJobStatus =
   | "canceled"
@@ -1247,9 +1651,12 @@ export const ProvisionerTypes: ProvisionerType[] = ["echo", "terraform"]
 // From codersdk/audit.go
 export type ResourceType =
   | "api_key"
+  | "appearance"
   | "git_ssh_key"
   | "group"
   | "license"
+  | "organization"
+  | "organization_member"
   | "template"
   | "template_version"
   | "user"
@@ -1257,9 +1664,12 @@ export type ResourceType =
   | "workspace_build"
 export const ResourceTypes: ResourceType[] = [
   "api_key",
+  "appearance",
   "git_ssh_key",
   "group",
   "license",
+  "organization",
+  "organization_member",
   "template",
   "template_version",
   "user",
@@ -1275,6 +1685,27 @@ export const ServerSentEventTypes: ServerSentEventType[] = [
   "ping",
 ]
 
+// From codersdk/insights.go
+export type TemplateConnectionType =
+  | "app"
+  | "port_forward"
+  | "ssh"
+  | "web_terminal"
+export const TemplateConnectionTypes: TemplateConnectionType[] = [
+  "app",
+  "port_forward",
+  "ssh",
+  "web_terminal",
+]
+
+// From codersdk/templategitsync.go
+export type TemplateGitSyncStatus = "failed" | "pending" | "succeeded"
+export const TemplateGitSyncStatuses: TemplateGitSyncStatus[] = [
+  "failed",
+  "pending",
+  "succeeded",
+]
+
 // From codersdk/templates.go
 export type TemplateRole = "" | "admin" | "use"
 export const TemplateRoles: TemplateRole[] = ["", "admin", "use"]
@@ -1290,6 +1721,27 @@ export const ValidationMonotonicOrders: ValidationMonotonicOrder[] = [
   "increasing",
 ]
 
+// From codersdk/webhooks.go
+export type WebhookEvent =
+  | "build.failed"
+  | "build.started"
+  | "build.succeeded"
+  | "template_version.promoted"
+  | "user.created"
+  | "user.suspended"
+  | "workspace.created"
+  | "workspace.deleted"
+export const WebhookEvents: WebhookEvent[] = [
+  "build.failed",
+  "build.started",
+  "build.succeeded",
+  "template_version.promoted",
+  "user.created",
+  "user.suspended",
+  "workspace.created",
+  "workspace.deleted",
+]
+
 // From codersdk/workspaceagents.go
 export type WorkspaceAgentLifecycle =
   | "created"
@@ -1339,6 +1791,10 @@ export const WorkspaceAppSharingLevels: WorkspaceAppSharingLevel[] = [
   "public",
 ]
 
+// From codersdk/workspaces.go
+export type WorkspaceRole = "" | "admin" | "use"
+export const WorkspaceRoles: WorkspaceRole[] = ["", "admin", "use"]
+
 // From codersdk/workspacebuilds.go
 export type WorkspaceStatus =
   | "canceled"
@@ -81,7 +81,9 @@ export const TemplatePageHeader: FC<TemplatePageHeaderProps> = ({
               />
               <TemplateSettingsButton templateName={template.name} />
             </Maybe>
-            <CreateWorkspaceButton templateName={template.name} />
+            <Maybe condition={!template.deprecated}>
+              <CreateWorkspaceButton templateName={template.name} />
+            </Maybe>
           </>
         }
       >
@@ -103,6 +105,11 @@ export const TemplatePageHeader: FC<TemplatePageHeaderProps> = ({
                 {template.description}
               </PageHeaderSubtitle>
             )}
+            {template.deprecated && (
+              <PageHeaderSubtitle condensed>
+                Deprecated: {template.deprecation_message}
+              </PageHeaderSubtitle>
+            )}
           </div>
         </Stack>
       </PageHeader>
@@ -117,19 +117,23 @@ const TemplateRow: FC<{ template: Template }> = ({ template }) => {
       </TableCell>
 
       <TableCell className={styles.actionCell}>
-        <Button
-          variant="outlined"
-          size="small"
-          className={styles.actionButton}
-          startIcon={<ArrowForwardOutlined />}
-          title={`Create a workspace using the ${template.display_name} template`}
-          onClick={(e) => {
-            e.stopPropagation()
-            navigate(`/templates/${template.name}/workspace`)
-          }}
-        >
-          Use template
-        </Button>
+        {template.deprecated ? (
+          <span className={styles.secondary}>Deprecated</span>
+        ) : (
+          <Button
+            variant="outlined"
+            size="small"
+            className={styles.actionButton}
+            startIcon={<ArrowForwardOutlined />}
+            title={`Create a workspace using the ${template.display_name} template`}
+            onClick={(e) => {
+              e.stopPropagation()
+              navigate(`/templates/${template.name}/workspace`)
+            }}
+          >
+            Use template
+          </Button>
+        )}
       </TableCell>
     </TableRow>
   )
@@ -193,6 +193,8 @@ export const MockProvisioner: TypesGen.ProvisionerDaemon = {
   name: "Test Provisioner",
   provisioners: ["echo"],
   tags: {},
+  status: "online",
+  version: "v0.5.4",
 }
 
 export const MockProvisionerJob: TypesGen.ProvisionerJob = {
@@ -202,6 +204,7 @@ export const MockProvisionerJob: TypesGen.ProvisionerJob = {
   file_id: "fc0774ce-cc9e-48d4-80ae-88f7a4d4a8b0",
   completed_at: "2022-05-17T17:39:01.382927298Z",
   tags: {},
+  priority: "interactive",
 }
 
 export const MockFailedProvisionerJob: TypesGen.ProvisionerJob = {
@@ -240,6 +243,7 @@ You can add instructions here
 
 [Some link info](https://coder.com)`,
   created_by: MockUser,
+  archived: false,
 }
 
 export const MockTemplateVersion2: TypesGen.TemplateVersion = {
@@ -257,6 +261,7 @@ You can add instructions here
 
 [Some link info](https://coder.com)`,
   created_by: MockUser,
+  archived: false,
 }
 
 export const MockTemplate: TypesGen.Template = {
@@ -289,6 +294,8 @@ export const MockTemplate: TypesGen.Template = {
   created_by_name: "test_creator",
   icon: "/icon/code.svg",
   allow_user_cancel_workspace_jobs: true,
+  deprecated: false,
+  deprecation_message: "",
 }
 
 export const MockTemplateVersionFiles: TemplateVersionFiles = {
@@ -624,6 +631,7 @@ export const MockWorkspace: TypesGen.Workspace = {
   ttl_ms: 2 * 60 * 60 * 1000, // 2 hours as milliseconds
   latest_build: MockWorkspaceBuild,
   last_used_at: "",
+  labels: {},
 }
 
 export const MockStoppedWorkspace: TypesGen.Workspace = {